@@ -0,0 +1,69 @@
+package actions
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/pkg/emailtemplate"
+	"github.com/getfider/fider/app/pkg/validate"
+)
+
+var subjectErrorRegex = regexp.MustCompile(`template: ?subject`)
+
+// EditEmailTemplate is used to customize, preview and test the email template of given kind
+type EditEmailTemplate struct {
+	Kind    string `route:"kind"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *EditEmailTemplate) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil && user.IsAdministrator()
+}
+
+// Validate if current model is valid
+func (action *EditEmailTemplate) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	if !emailtemplate.IsCustomizable(action.Kind) {
+		return validate.Error(app.ErrNotFound)
+	}
+
+	result := validate.Success()
+
+	if strings.TrimSpace(action.Subject) == "" {
+		result.AddFieldFailure("subject", "Subject is required.")
+	} else if len(action.Subject) > emailtemplate.MaxSubjectLength {
+		result.AddFieldFailure("subject", "Subject must have less than 500 characters.")
+	} else if strings.ContainsAny(action.Subject, "\r\n") {
+		result.AddFieldFailure("subject", "Subject must be on a single line.")
+	}
+
+	if strings.TrimSpace(action.Body) == "" {
+		result.AddFieldFailure("body", "Body is required.")
+	}
+
+	if result.Ok {
+		if err := emailtemplate.Validate(ctx, action.Template()); err != nil {
+			// Errors of Go templates are prefixed with the name of the failing block
+			field := "body"
+			if subjectErrorRegex.MatchString(err.Error()) {
+				field = "subject"
+			}
+			result.AddFieldFailure(field, "Template is invalid: "+err.Error())
+		}
+	}
+
+	return result
+}
+
+// Template returns the email template being edited
+func (action *EditEmailTemplate) Template() *entity.EmailTemplate {
+	return &entity.EmailTemplate{
+		Kind:    action.Kind,
+		Subject: action.Subject,
+		Body:    action.Body,
+	}
+}
@@ -0,0 +1,53 @@
+package actions_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/getfider/fider/app/actions"
+	. "github.com/getfider/fider/app/pkg/assert"
+)
+
+func TestEditEmailTemplate_Valid(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.EditEmailTemplate{
+		Kind:    "new_comment",
+		Subject: "[{{ .siteName }}] {{ .title }}",
+		Body:    "<tr><td>{{ .userName }}: {{ .content }}</td></tr>",
+	}
+	result := action.Validate(context.Background(), nil)
+	ExpectSuccess(result)
+}
+
+func TestEditEmailTemplate_InvalidSubject(t *testing.T) {
+	RegisterT(t)
+
+	for _, subject := range []string{
+		"",
+		"Hello\nWorld",
+		strings.Repeat("a", 501),
+		"{{ .title ",
+		"{{ unknown .title }}",
+	} {
+		action := &actions.EditEmailTemplate{Kind: "new_post", Subject: subject, Body: "<tr><td>{{ .content }}</td></tr>"}
+		result := action.Validate(context.Background(), nil)
+		ExpectFailed(result, "subject")
+	}
+}
+
+func TestEditEmailTemplate_InvalidBody(t *testing.T) {
+	RegisterT(t)
+
+	for _, body := range []string{
+		"",
+		"{{ range .content ",
+		`{{ define "subject" }}Hacked{{ end }}`,
+		"{{ range 1000 }}{{ end }}",
+	} {
+		action := &actions.EditEmailTemplate{Kind: "new_post", Subject: "{{ .title }}", Body: body}
+		result := action.Validate(context.Background(), nil)
+		ExpectFailed(result, "body")
+	}
+}
@@ -33,6 +33,8 @@ type CreateEditOAuthConfig struct {
 	JSONUserIDPath    string           `json:"jsonUserIDPath"`
 	JSONUserNamePath  string           `json:"jsonUserNamePath"`
 	JSONUserEmailPath string           `json:"jsonUserEmailPath"`
+
+	Current *entity.OAuthConfig
 }
 
 func NewCreateEditOAuthConfig() *CreateEditOAuthConfig {
@@ -69,6 +71,7 @@ func (action *CreateEditOAuthConfig) Validate(ctx context.Context, user *entity.
 			return validate.Error(err)
 		}
 
+		action.Current = getConfig.Result
 		action.ID = getConfig.Result.ID
 		action.Logo.BlobKey = getConfig.Result.LogoBlobKey
 		if action.ClientSecret == "" {
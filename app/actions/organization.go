@@ -0,0 +1,120 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/validate"
+)
+
+// CreateEditOrganization is used to create a new organization or edit existing
+type CreateEditOrganization struct {
+	ID         int     `route:"id"`
+	ExternalID string  `json:"externalId"`
+	Name       string  `json:"name"`
+	Plan       string  `json:"plan"`
+	Segment    string  `json:"segment"`
+	MRR        float64 `json:"mrr"`
+
+	Organization *entity.Organization
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *CreateEditOrganization) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil && user.IsAdministrator()
+}
+
+// Validate if current model is valid
+func (action *CreateEditOrganization) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	result := validate.Success()
+
+	if action.ID > 0 {
+		getOrganization := &query.GetOrganizationByID{OrganizationID: action.ID}
+		if err := bus.Dispatch(ctx, getOrganization); err != nil {
+			return validate.Error(err)
+		}
+		action.Organization = getOrganization.Result
+	}
+
+	if action.Name == "" {
+		result.AddFieldFailure("name", "Name is required.")
+	} else if len(action.Name) > 100 {
+		result.AddFieldFailure("name", "Name must have less than 100 characters.")
+	}
+
+	if len(action.ExternalID) > 100 {
+		result.AddFieldFailure("externalId", "External ID must have less than 100 characters.")
+	}
+
+	if len(action.Plan) > 50 {
+		result.AddFieldFailure("plan", "Plan must have less than 50 characters.")
+	}
+
+	if len(action.Segment) > 50 {
+		result.AddFieldFailure("segment", "Segment must have less than 50 characters.")
+	}
+
+	if action.MRR < 0 {
+		result.AddFieldFailure("mrr", "MRR cannot be negative.")
+	}
+
+	return result
+}
+
+// DeleteOrganization is used to delete an existing organization
+type DeleteOrganization struct {
+	ID int `route:"id"`
+
+	Organization *entity.Organization
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *DeleteOrganization) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil && user.IsAdministrator()
+}
+
+// Validate if current model is valid
+func (action *DeleteOrganization) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	getOrganization := &query.GetOrganizationByID{OrganizationID: action.ID}
+	if err := bus.Dispatch(ctx, getOrganization); err != nil {
+		return validate.Error(err)
+	}
+
+	action.Organization = getOrganization.Result
+	return validate.Success()
+}
+
+// SetUserOrganization is used to assign a user to an organization
+type SetUserOrganization struct {
+	UserID         int `route:"userID"`
+	OrganizationID int `json:"organizationId"`
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *SetUserOrganization) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil && user.IsAdministrator()
+}
+
+// Validate if current model is valid
+func (action *SetUserOrganization) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	getUser := &query.GetUserByID{UserID: action.UserID}
+	if err := bus.Dispatch(ctx, getUser); err != nil {
+		return validate.Error(err)
+	}
+
+	if getUser.Result.Tenant.ID != user.Tenant.ID {
+		return validate.Error(app.ErrNotFound)
+	}
+
+	if action.OrganizationID > 0 {
+		getOrganization := &query.GetOrganizationByID{OrganizationID: action.OrganizationID}
+		if err := bus.Dispatch(ctx, getOrganization); err != nil {
+			return validate.Error(err)
+		}
+	}
+
+	return validate.Success()
+}
@@ -0,0 +1,95 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/webauthn"
+)
+
+func isPasskeyEnabled(ctx context.Context) bool {
+	tenant, ok := ctx.Value(app.TenantCtxKey).(*entity.Tenant)
+	return ok && tenant != nil && tenant.PasskeyMode != enum.PasskeyDisabled
+}
+
+// RegisterPasskey happens when a user registers a new passkey from their settings
+type RegisterPasskey struct {
+	Name       string                         `json:"name"`
+	Token      string                         `json:"token"`
+	Credential *webauthn.RegistrationResponse `json:"credential"`
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *RegisterPasskey) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil && isPasskeyEnabled(ctx)
+}
+
+// Validate if current model is valid
+func (action *RegisterPasskey) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	result := validate.Success()
+
+	if action.Name == "" {
+		result.AddFieldFailure("name", propertyIsRequired(ctx, "name"))
+	} else if len(action.Name) > 50 {
+		result.AddFieldFailure("name", propertyMaxStringLen(ctx, "name", 50))
+	}
+
+	if action.Token == "" || action.Credential == nil {
+		result.AddFieldFailure("credential", propertyIsRequired(ctx, "passkey"))
+	} else {
+		for _, transport := range action.Credential.Response.Transports {
+			if !webauthn.IsKnownTransport(transport) {
+				result.AddFieldFailure("credential", propertyIsInvalid(ctx, "passkey"))
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// SignInByPasskey happens when a user signs in with a previously registered passkey
+type SignInByPasskey struct {
+	Token      string                      `json:"token"`
+	Credential *webauthn.AssertionResponse `json:"credential"`
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *SignInByPasskey) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return isPasskeyEnabled(ctx)
+}
+
+// Validate if current model is valid
+func (action *SignInByPasskey) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	result := validate.Success()
+
+	if action.Token == "" || action.Credential == nil {
+		result.AddFieldFailure("credential", propertyIsRequired(ctx, "passkey"))
+	}
+
+	return result
+}
+
+// UpdateTenantPasskeySettings is the input model used to update how passkeys can be used on a tenant
+type UpdateTenantPasskeySettings struct {
+	PasskeyMode enum.PasskeyMode `json:"passkeyMode"`
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *UpdateTenantPasskeySettings) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil && user.Role == enum.RoleAdministrator
+}
+
+// Validate if current model is valid
+func (action *UpdateTenantPasskeySettings) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	result := validate.Success()
+
+	if action.PasskeyMode.String() == "" {
+		result.AddFieldFailure("passkeyMode", propertyIsInvalid(ctx, "passkeyMode"))
+	}
+
+	return result
+}
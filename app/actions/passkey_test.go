@@ -0,0 +1,104 @@
+package actions_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/webauthn"
+)
+
+func TestRegisterPasskey_Empty(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.RegisterPasskey{}
+	result := action.Validate(context.Background(), nil)
+	ExpectFailed(result, "name", "credential")
+}
+
+func TestRegisterPasskey_LongName(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.RegisterPasskey{
+		Name:       "123456789012345678901234567890123456789012345678901", // 51 chars
+		Token:      "abc",
+		Credential: &webauthn.RegistrationResponse{},
+	}
+	result := action.Validate(context.Background(), nil)
+	ExpectFailed(result, "name")
+}
+
+func TestRegisterPasskey_UnknownTransport(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.RegisterPasskey{
+		Name:       "My Phone",
+		Token:      "abc",
+		Credential: &webauthn.RegistrationResponse{},
+	}
+	action.Credential.Response.Transports = []string{"internal", "hybrid"}
+	ExpectSuccess(action.Validate(context.Background(), nil))
+
+	action.Credential.Response.Transports = []string{"internal", "a-very-long-transport-name"}
+	ExpectFailed(action.Validate(context.Background(), nil), "credential")
+}
+
+func TestRegisterPasskey_IsAuthorized(t *testing.T) {
+	RegisterT(t)
+
+	optional := context.WithValue(context.Background(), app.TenantCtxKey, &entity.Tenant{PasskeyMode: enum.PasskeyOptional})
+	disabled := context.WithValue(context.Background(), app.TenantCtxKey, &entity.Tenant{PasskeyMode: enum.PasskeyDisabled})
+
+	action := &actions.RegisterPasskey{}
+	Expect(action.IsAuthorized(optional, &entity.User{ID: 1})).IsTrue()
+	Expect(action.IsAuthorized(optional, nil)).IsFalse()
+	Expect(action.IsAuthorized(disabled, &entity.User{ID: 1})).IsFalse()
+}
+
+func TestUpdateTenantPasskeySettings_InvalidMode(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.UpdateTenantPasskeySettings{}
+	result := action.Validate(context.Background(), nil)
+	ExpectFailed(result, "passkeyMode")
+
+	action = &actions.UpdateTenantPasskeySettings{PasskeyMode: enum.PasskeyRequired}
+	result = action.Validate(context.Background(), nil)
+	ExpectSuccess(result)
+}
+
+func TestSignInByEmail_PasskeyRequired(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		role := enum.RoleVisitor
+		if q.Email == "admin@got.com" {
+			role = enum.RoleAdministrator
+		}
+		q.Result = &entity.User{ID: 1, Email: q.Email, Role: role}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.ListUserPasskeys) error {
+		q.Result = []*entity.UserPasskey{{ID: 1, UserID: q.UserID}}
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), app.TenantCtxKey, &entity.Tenant{PasskeyMode: enum.PasskeyRequired})
+
+	action := actions.NewSignInByEmail()
+	action.Email = "jon.snow@got.com"
+	ExpectSuccess(action.Validate(ctx, nil))
+	Expect(action.PasskeyRequired).IsTrue()
+
+	action = actions.NewSignInByEmail()
+	action.Email = "admin@got.com"
+	ExpectSuccess(action.Validate(ctx, nil))
+	Expect(action.PasskeyRequired).IsFalse()
+}
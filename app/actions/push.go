@@ -0,0 +1,73 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/webpush"
+)
+
+// AddPushSubscription happens when a user allows Web Push notifications on a browser
+// It mirrors the JSON representation of a PushSubscription from the Push API
+type AddPushSubscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *AddPushSubscription) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil && env.IsWebPushEnabled()
+}
+
+// Validate if current model is valid
+func (action *AddPushSubscription) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	result := validate.Success()
+
+	if action.Endpoint == "" {
+		result.AddFieldFailure("endpoint", propertyIsRequired(ctx, "endpoint"))
+	} else if len(action.Endpoint) > 2000 {
+		result.AddFieldFailure("endpoint", propertyMaxStringLen(ctx, "endpoint", 2000))
+	} else if !webpush.IsPushServiceEndpoint(action.Endpoint) {
+		result.AddFieldFailure("endpoint", propertyIsInvalid(ctx, "endpoint"))
+	}
+
+	if action.Keys.P256dh == "" {
+		result.AddFieldFailure("keys", propertyIsRequired(ctx, "p256dh"))
+	} else if len(action.Keys.P256dh) > 200 {
+		result.AddFieldFailure("keys", propertyMaxStringLen(ctx, "p256dh", 200))
+	}
+
+	if action.Keys.Auth == "" {
+		result.AddFieldFailure("keys", propertyIsRequired(ctx, "auth"))
+	} else if len(action.Keys.Auth) > 50 {
+		result.AddFieldFailure("keys", propertyMaxStringLen(ctx, "auth", 50))
+	}
+
+	return result
+}
+
+// DeletePushSubscription happens when a user disables Web Push notifications on a browser
+type DeletePushSubscription struct {
+	Endpoint string `json:"endpoint"`
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *DeletePushSubscription) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil
+}
+
+// Validate if current model is valid
+func (action *DeletePushSubscription) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	result := validate.Success()
+
+	if action.Endpoint == "" {
+		result.AddFieldFailure("endpoint", propertyIsRequired(ctx, "endpoint"))
+	}
+
+	return result
+}
@@ -0,0 +1,53 @@
+package actions_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/entity"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/env"
+)
+
+func TestAddPushSubscription_Empty(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.AddPushSubscription{}
+	result := action.Validate(context.Background(), nil)
+	ExpectFailed(result, "endpoint", "keys")
+}
+
+func TestAddPushSubscription_InvalidEndpoint(t *testing.T) {
+	RegisterT(t)
+
+	for _, endpoint := range []string{"fcm.googleapis.com/fcm/send/abc", "http://fcm.googleapis.com/fcm/send/abc", "https://", "https://push.example.com/abc", "https://10.0.0.1/abc"} {
+		action := &actions.AddPushSubscription{Endpoint: endpoint}
+		action.Keys.P256dh = "BCVxsr7N_eNgVRqvHtD0zTZsEc6"
+		action.Keys.Auth = "BTBZMqHH6r4Tts7J_aSIgg"
+		result := action.Validate(context.Background(), nil)
+		ExpectFailed(result, "endpoint")
+	}
+}
+
+func TestAddPushSubscription_Valid(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.AddPushSubscription{Endpoint: "https://fcm.googleapis.com/fcm/send/abc"}
+	action.Keys.P256dh = "BCVxsr7N_eNgVRqvHtD0zTZsEc6"
+	action.Keys.Auth = "BTBZMqHH6r4Tts7J_aSIgg"
+	result := action.Validate(context.Background(), nil)
+	ExpectSuccess(result)
+}
+
+func TestAddPushSubscription_IsAuthorized(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.AddPushSubscription{}
+	Expect(action.IsAuthorized(context.Background(), &entity.User{ID: 1})).IsFalse()
+
+	env.Config.WebPush.VAPIDPublicKey = "public"
+	env.Config.WebPush.VAPIDPrivateKey = "private"
+	Expect(action.IsAuthorized(context.Background(), &entity.User{ID: 1})).IsTrue()
+	Expect(action.IsAuthorized(context.Background(), nil)).IsFalse()
+}
@@ -0,0 +1,122 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/validate"
+)
+
+// ReportContent is used to report a post or one of its comments to the moderators
+type ReportContent struct {
+	Number    int    `route:"number"`
+	CommentID int    `route:"id"`
+	Reason    string `json:"reason"`
+	Details   string `json:"details"`
+
+	Post    *entity.Post
+	Comment *entity.Comment
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *ReportContent) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil
+}
+
+// Validate if current model is valid
+func (action *ReportContent) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	result := validate.Success()
+
+	if action.Reason == "" {
+		result.AddFieldFailure("reason", propertyIsRequired(ctx, "reason"))
+	} else if !enum.ReportReason(action.Reason).IsValid() {
+		result.AddFieldFailure("reason", propertyIsInvalid(ctx, "reason"))
+	}
+
+	if len(action.Details) > 500 {
+		result.AddFieldFailure("details", propertyMaxStringLen(ctx, "details", 500))
+	}
+
+	getPost := &query.GetPostByNumber{Number: action.Number}
+	if err := bus.Dispatch(ctx, getPost); err != nil {
+		return validate.Error(err)
+	}
+	action.Post = getPost.Result
+
+	if action.CommentID > 0 {
+		getComment := &query.GetCommentByID{CommentID: action.CommentID}
+		if err := bus.Dispatch(ctx, getComment); err != nil {
+			return validate.Error(err)
+		}
+		if getComment.Result.PostID != action.Post.ID {
+			return validate.Error(app.ErrNotFound)
+		}
+		action.Comment = getComment.Result
+	}
+
+	return result
+}
+
+// ResolveContentReports is used by moderators to close all the open reports of a post or comment
+type ResolveContentReports struct {
+	PostNumber int    `json:"postNumber"`
+	CommentID  int    `json:"commentId"`
+	Resolution string `json:"resolution"`
+
+	Post    *entity.Post
+	Comment *entity.Comment
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *ResolveContentReports) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	if user == nil || !user.IsCollaborator() {
+		return false
+	}
+
+	// Only administrators are allowed to block users
+	return enum.ReportResolution(action.Resolution) != enum.ReportAuthorBlocked || user.IsAdministrator()
+}
+
+// Validate if current model is valid
+func (action *ResolveContentReports) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	if !enum.ReportResolution(action.Resolution).IsValid() {
+		return validate.Failed(propertyIsInvalid(ctx, "resolution"))
+	}
+
+	getPost := &query.GetPostByNumber{Number: action.PostNumber}
+	if err := bus.Dispatch(ctx, getPost); err != nil {
+		return validate.Error(err)
+	}
+	action.Post = getPost.Result
+
+	if action.CommentID > 0 {
+		getComment := &query.GetCommentByID{CommentID: action.CommentID}
+		if err := bus.Dispatch(ctx, getComment); err != nil {
+			return validate.Error(err)
+		}
+		if getComment.Result.PostID != action.Post.ID {
+			return validate.Error(app.ErrNotFound)
+		}
+		action.Comment = getComment.Result
+	}
+
+	if enum.ReportResolution(action.Resolution) == enum.ReportAuthorBlocked {
+		if action.Author().IsCollaborator() {
+			return validate.Failed("Staff members cannot be blocked.")
+		}
+	}
+
+	return validate.Success()
+}
+
+// Author returns the user who wrote the reported content
+func (action *ResolveContentReports) Author() *entity.User {
+	if action.Comment != nil {
+		return action.Comment.User
+	}
+	return action.Post.User
+}
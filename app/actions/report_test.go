@@ -0,0 +1,85 @@
+package actions_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/rand"
+)
+
+var reportJonSnow = &entity.User{ID: 1, Name: "Jon Snow", Role: enum.RoleAdministrator}
+var reportSansaStark = &entity.User{ID: 2, Name: "Sansa Stark", Role: enum.RoleCollaborator}
+var reportAryaStark = &entity.User{ID: 3, Name: "Arya Stark", Role: enum.RoleVisitor}
+
+func registerReportedPost(author *entity.User) {
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = &entity.Post{ID: 10, Number: q.Number, Title: "Buy cheap stuff", User: author}
+		return nil
+	})
+}
+
+func TestReportContent_InvalidInput(t *testing.T) {
+	RegisterT(t)
+	registerReportedPost(reportAryaStark)
+
+	action := &actions.ReportContent{Number: 1}
+	ExpectFailed(action.Validate(context.Background(), reportJonSnow), "reason")
+
+	action = &actions.ReportContent{Number: 1, Reason: "boring", Details: rand.String(501)}
+	ExpectFailed(action.Validate(context.Background(), reportJonSnow), "reason", "details")
+}
+
+func TestReportContent_ValidInput(t *testing.T) {
+	RegisterT(t)
+	registerReportedPost(reportAryaStark)
+	bus.AddHandler(func(ctx context.Context, q *query.GetCommentByID) error {
+		q.Result = &entity.Comment{ID: q.CommentID, PostID: 10, Content: "Spam!", User: reportAryaStark}
+		return nil
+	})
+
+	action := &actions.ReportContent{Number: 1, CommentID: 5, Reason: "spam", Details: "Advertising"}
+	Expect(action.IsAuthorized(context.Background(), nil)).IsFalse()
+	Expect(action.IsAuthorized(context.Background(), reportSansaStark)).IsTrue()
+	ExpectSuccess(action.Validate(context.Background(), reportSansaStark))
+	Expect(action.Post.ID).Equals(10)
+	Expect(action.Comment.ID).Equals(5)
+}
+
+func TestResolveContentReports_IsAuthorized(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.ResolveContentReports{PostNumber: 1, Resolution: "hidden"}
+	Expect(action.IsAuthorized(context.Background(), reportAryaStark)).IsFalse()
+	Expect(action.IsAuthorized(context.Background(), reportSansaStark)).IsTrue()
+	Expect(action.IsAuthorized(context.Background(), reportJonSnow)).IsTrue()
+
+	action = &actions.ResolveContentReports{PostNumber: 1, Resolution: "blocked"}
+	Expect(action.IsAuthorized(context.Background(), reportSansaStark)).IsFalse()
+	Expect(action.IsAuthorized(context.Background(), reportJonSnow)).IsTrue()
+}
+
+func TestResolveContentReports_InvalidResolution(t *testing.T) {
+	RegisterT(t)
+	registerReportedPost(reportAryaStark)
+
+	action := &actions.ResolveContentReports{PostNumber: 1, Resolution: "ignored"}
+	ExpectFailed(action.Validate(context.Background(), reportJonSnow), "")
+}
+
+func TestResolveContentReports_CannotBlockStaff(t *testing.T) {
+	RegisterT(t)
+	registerReportedPost(reportSansaStark)
+
+	action := &actions.ResolveContentReports{PostNumber: 1, Resolution: "blocked"}
+	ExpectFailed(action.Validate(context.Background(), reportJonSnow), "")
+
+	action = &actions.ResolveContentReports{PostNumber: 1, Resolution: "hidden"}
+	ExpectSuccess(action.Validate(context.Background(), reportJonSnow))
+	Expect(action.Author()).Equals(reportSansaStark)
+}
@@ -0,0 +1,118 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/validate"
+)
+
+// maxSavedSearchesPerUser limits the searches that are matched against every new post and status change
+const maxSavedSearchesPerUser = 20
+
+// savedSearchViews are the views that can be followed, as they only depend on the status of posts
+var savedSearchViews = []string{"trending", "recent", "most-wanted", "most-discussed", "planned", "started", "completed", "declined", "all"}
+
+func isValidSavedSearchChannels(channels enum.NotificationChannel) bool {
+	return channels > 0 && channels <= enum.NotificationChannelWeb|enum.NotificationChannelEmail|enum.NotificationChannelPush
+}
+
+// AddSavedSearch happens when a user follows a view and tags of posts
+type AddSavedSearch struct {
+	Name     string                   `json:"name"`
+	View     string                   `json:"view"`
+	Tags     []string                 `json:"tags"`
+	Channels enum.NotificationChannel `json:"channels"`
+
+	TagList []*entity.Tag
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *AddSavedSearch) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil
+}
+
+// Validate if current model is valid
+func (action *AddSavedSearch) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	getSearches := &query.GetCurrentUserSavedSearches{}
+	if err := bus.Dispatch(ctx, getSearches); err != nil {
+		return validate.Error(err)
+	}
+	if len(getSearches.Result) >= maxSavedSearchesPerUser {
+		return validate.Failed(i18n.T(ctx, "validation.custom.maxsavedsearches", i18n.Params{"number": maxSavedSearchesPerUser}))
+	}
+
+	result := validate.Success()
+
+	if action.Name == "" {
+		result.AddFieldFailure("name", propertyIsRequired(ctx, "name"))
+	} else if len(action.Name) > 100 {
+		result.AddFieldFailure("name", propertyMaxStringLen(ctx, "name", 100))
+	}
+
+	if action.View == "" {
+		action.View = "trending"
+	}
+	isValidView := false
+	for _, view := range savedSearchViews {
+		if view == action.View {
+			isValidView = true
+		}
+	}
+	if !isValidView {
+		result.AddFieldFailure("view", propertyIsInvalid(ctx, "view"))
+	}
+
+	if len(action.Tags) > 10 {
+		result.AddFieldFailure("tags", propertyIsInvalid(ctx, "tags"))
+	} else {
+		action.TagList = make([]*entity.Tag, 0, len(action.Tags))
+		for _, slug := range action.Tags {
+			getTag := &query.GetTagBySlug{Slug: slug}
+			err := bus.Dispatch(ctx, getTag)
+			if err != nil && errors.Cause(err) != app.ErrNotFound {
+				return validate.Error(err)
+			}
+			// Private tags are only visible to staff members
+			if err != nil || (!getTag.Result.IsPublic && !user.IsCollaborator()) {
+				result.AddFieldFailure("tags", propertyIsInvalid(ctx, "tags"))
+				break
+			}
+			action.TagList = append(action.TagList, getTag.Result)
+		}
+	}
+
+	if !isValidSavedSearchChannels(action.Channels) {
+		result.AddFieldFailure("channels", propertyIsInvalid(ctx, "channels"))
+	}
+
+	return result
+}
+
+// UpdateSavedSearch happens when a user changes how a saved search is notified
+type UpdateSavedSearch struct {
+	ID       int                      `route:"id"`
+	Channels enum.NotificationChannel `json:"channels"`
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *UpdateSavedSearch) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil
+}
+
+// Validate if current model is valid
+func (action *UpdateSavedSearch) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	result := validate.Success()
+
+	if !isValidSavedSearchChannels(action.Channels) {
+		result.AddFieldFailure("channels", propertyIsInvalid(ctx, "channels"))
+	}
+
+	return result
+}
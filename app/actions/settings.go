@@ -2,6 +2,7 @@ package actions
 
 import (
 	"context"
+	"strings"
 
 	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/models/entity"
@@ -16,6 +17,9 @@ type UpdateUserSettings struct {
 	AvatarType enum.AvatarType   `json:"avatarType"`
 	Avatar     *dto.ImageUpload  `json:"avatar"`
 	Settings   map[string]string `json:"settings"`
+	Bio        string            `json:"bio"`
+	Link       string            `json:"link"`
+	Locale     string            `json:"locale"`
 }
 
 func NewUpdateUserSettings() *UpdateUserSettings {
@@ -45,6 +49,26 @@ func (action *UpdateUserSettings) Validate(ctx context.Context, user *entity.Use
 		result.AddFieldFailure("name", propertyMaxStringLen(ctx, "name", 50))
 	}
 
+	action.Bio = strings.TrimSpace(action.Bio)
+	if len(action.Bio) > 500 {
+		result.AddFieldFailure("bio", propertyMaxStringLen(ctx, "bio", 500))
+	}
+
+	action.Link = strings.TrimSpace(action.Link)
+	if action.Link != "" {
+		lower := strings.ToLower(action.Link)
+		if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+			result.AddFieldFailure("link", propertyIsInvalid(ctx, "link"))
+		} else {
+			result.AddFieldFailure("link", validate.URL(ctx, action.Link)...)
+		}
+	}
+
+	// An empty locale means the user follows the locale of the site
+	if action.Locale != "" && !i18n.IsValidLocale(action.Locale) {
+		result.AddFieldFailure("locale", propertyIsInvalid(ctx, "locale"))
+	}
+
 	action.Avatar.BlobKey = user.AvatarBlobKey
 	messages, err := validate.ImageUpload(ctx, action.Avatar, validate.ImageUploadOpts{
 		IsRequired:   action.AvatarType == enum.AvatarTypeCustom,
@@ -60,6 +84,13 @@ func (action *UpdateUserSettings) Validate(ctx context.Context, user *entity.Use
 
 	if action.Settings != nil {
 		for k, v := range action.Settings {
+			if k == enum.EmailDeliverySettingsKeyName {
+				if !enum.IsValidEmailDelivery(v) {
+					result.AddFieldFailure("settings", i18n.T(ctx, "validation.invalidvalue", i18n.Params{"name": k}, i18n.Params{"value": v}))
+				}
+				continue
+			}
+
 			ok := false
 			for _, e := range enum.AllNotificationEvents {
 				if e.UserSettingsKeyName == k {
@@ -49,7 +49,16 @@ func TestInvalidSettings(t *testing.T) {
 			"bad_name": "3",
 		},
 		{
-			enum.NotificationEventNewComment.UserSettingsKeyName: "4",
+			enum.NotificationEventNewComment.UserSettingsKeyName: "8",
+		},
+		{
+			enum.NotificationEventNewComment.UserSettingsKeyName: "-1",
+		},
+		{
+			enum.NotificationEventNewComment.UserSettingsKeyName: "web",
+		},
+		{
+			enum.EmailDeliverySettingsKeyName: "hourly",
 		},
 	} {
 		action := actions.NewUpdateUserSettings()
@@ -73,6 +82,14 @@ func TestValidSettings(t *testing.T) {
 		{
 			enum.NotificationEventNewComment.UserSettingsKeyName: enum.NotificationEventNewComment.DefaultSettingValue,
 		},
+		{
+			enum.NotificationEventNewComment.UserSettingsKeyName: enum.NotificationEventNewComment.DefaultSettingValue,
+			enum.EmailDeliverySettingsKeyName:                    string(enum.EmailDeliveryWeekly),
+		},
+		{
+			enum.NotificationEventNewPost.UserSettingsKeyName:      "4",
+			enum.NotificationEventChangeStatus.UserSettingsKeyName: "7",
+		},
 	} {
 		action := actions.NewUpdateUserSettings()
 		action.Name = "John Snow"
@@ -87,3 +104,55 @@ func TestValidSettings(t *testing.T) {
 		Expect(action.Avatar.BlobKey).Equals("jon.png")
 	}
 }
+
+func TestInvalidProfileLinks(t *testing.T) {
+	RegisterT(t)
+
+	for _, link := range []string{
+		"javascript:alert(1)",
+		"ftp://example.com",
+		"example.com",
+	} {
+		action := actions.NewUpdateUserSettings()
+		action.Name = "Jon Snow"
+		action.AvatarType = enum.AvatarTypeGravatar
+		action.Link = link
+		result := action.Validate(context.Background(), &entity.User{})
+		ExpectFailed(result, "link")
+	}
+}
+
+func TestValidProfile(t *testing.T) {
+	RegisterT(t)
+
+	action := actions.NewUpdateUserSettings()
+	action.Name = "Jon Snow"
+	action.AvatarType = enum.AvatarTypeGravatar
+	action.Bio = "  King in the North  "
+	action.Link = "https://github.com/jonsnow"
+	result := action.Validate(context.Background(), &entity.User{})
+	ExpectSuccess(result)
+	Expect(action.Bio).Equals("King in the North")
+}
+
+func TestUserLocale(t *testing.T) {
+	RegisterT(t)
+
+	for _, locale := range []string{"", "fr", "pt-BR"} {
+		action := actions.NewUpdateUserSettings()
+		action.Name = "Jon Snow"
+		action.AvatarType = enum.AvatarTypeGravatar
+		action.Locale = locale
+		result := action.Validate(context.Background(), &entity.User{})
+		ExpectSuccess(result)
+	}
+
+	for _, locale := range []string{"FR", "pt_BR", "xx"} {
+		action := actions.NewUpdateUserSettings()
+		action.Name = "Jon Snow"
+		action.AvatarType = enum.AvatarTypeGravatar
+		action.Locale = locale
+		result := action.Validate(context.Background(), &entity.User{})
+		ExpectFailed(result, "locale")
+	}
+}
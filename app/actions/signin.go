@@ -2,6 +2,7 @@ package actions
 
 import (
 	"context"
+	"strings"
 
 	"github.com/getfider/fider/app"
 
@@ -9,6 +10,9 @@ import (
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/pkg/validate"
 )
 
@@ -16,6 +20,7 @@ import (
 type SignInByEmail struct {
 	Email           string `json:"email" format:"lower"`
 	VerificationKey string
+	PasskeyRequired bool `json:"-"`
 }
 
 func NewSignInByEmail() *SignInByEmail {
@@ -51,25 +56,93 @@ func (action *SignInByEmail) Validate(ctx context.Context, user *entity.User) *v
 	messages := validate.Email(ctx, action.Email)
 	result.AddFieldFailure("email", messages...)
 
+	if result.Ok {
+		required, err := isPasskeyRequired(ctx, action.Email)
+		if err != nil {
+			return validate.Error(err)
+		}
+		action.PasskeyRequired = required
+	}
+
+	if result.Ok {
+		allowed, err := canJoinPrivateTenant(ctx, action.Email)
+		if err != nil {
+			return validate.Error(err)
+		}
+		if !allowed {
+			result.AddFieldFailure("email", i18n.T(ctx, "validation.custom.emaildomainnotallowed"))
+		}
+	}
+
 	return result
 }
 
-//GetEmail returns the email being verified
+// canJoinPrivateTenant returns false when a private tenant restricts new users to some email domains
+// and given email is neither from one of these domains nor from an existing user
+func canJoinPrivateTenant(ctx context.Context, email string) (bool, error) {
+	tenant, ok := ctx.Value(app.TenantCtxKey).(*entity.Tenant)
+	if !ok || tenant == nil || !tenant.IsPrivate || len(tenant.AllowedEmailDomains) == 0 {
+		return true, nil
+	}
+	if tenant.IsEmailDomainAllowed(email) {
+		return true, nil
+	}
+
+	getUser := &query.GetUserByEmail{Email: email}
+	err := bus.Dispatch(ctx, getUser)
+	if errors.Cause(err) == app.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// isPasskeyRequired returns true if given email belongs to a user that must sign in with a passkey
+// Administrators are always allowed to sign in by email so that they cannot get locked out
+func isPasskeyRequired(ctx context.Context, email string) (bool, error) {
+	tenant, ok := ctx.Value(app.TenantCtxKey).(*entity.Tenant)
+	if !ok || tenant == nil || tenant.PasskeyMode != enum.PasskeyRequired {
+		return false, nil
+	}
+
+	getUser := &query.GetUserByEmail{Email: email}
+	if err := bus.Dispatch(ctx, getUser); err != nil {
+		if errors.Cause(err) == app.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if getUser.Result.IsAdministrator() {
+		return false, nil
+	}
+
+	passkeys := &query.ListUserPasskeys{UserID: getUser.Result.ID}
+	if err := bus.Dispatch(ctx, passkeys); err != nil {
+		return false, err
+	}
+
+	return len(passkeys.Result) > 0, nil
+}
+
+// GetEmail returns the email being verified
 func (action *SignInByEmail) GetEmail() string {
 	return action.Email
 }
 
-//GetName returns empty for this kind of process
+// GetName returns empty for this kind of process
 func (action *SignInByEmail) GetName() string {
 	return ""
 }
 
-//GetUser returns the current user performing this action
+// GetUser returns the current user performing this action
 func (action *SignInByEmail) GetUser() *entity.User {
 	return nil
 }
 
-//GetKind returns EmailVerificationKindSignIn
+// GetKind returns EmailVerificationKindSignIn
 func (action *SignInByEmail) GetKind() enum.EmailVerificationKind {
 	return enum.EmailVerificationKindSignIn
 }
@@ -102,3 +175,30 @@ func (action *CompleteProfile) Validate(ctx context.Context, user *entity.User)
 
 	return result
 }
+
+// SignInByLDAP happens when user request to sign in with directory credentials
+type SignInByLDAP struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *SignInByLDAP) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return env.IsLDAPEnabled()
+}
+
+// Validate if current model is valid
+func (action *SignInByLDAP) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	result := validate.Success()
+
+	action.Username = strings.TrimSpace(action.Username)
+	if action.Username == "" {
+		result.AddFieldFailure("username", propertyIsRequired(ctx, "username"))
+	}
+
+	if action.Password == "" {
+		result.AddFieldFailure("password", propertyIsRequired(ctx, "password"))
+	}
+
+	return result
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"testing"
 
+	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
 	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
 )
 
 func TestSignInByEmail_EmptyEmail(t *testing.T) {
@@ -35,6 +39,34 @@ func TestSignInByEmail_ShouldHaveVerificationKey(t *testing.T) {
 	Expect(action.VerificationKey).IsNotEmpty()
 }
 
+func TestSignInByEmail_PrivateTenant_AllowedEmailDomains(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		if q.Email == "jon.snow@got.com" {
+			q.Result = &entity.User{ID: 1, Email: q.Email}
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	ctx := context.WithValue(context.Background(), app.TenantCtxKey, &entity.Tenant{
+		ID:                  1,
+		IsPrivate:           true,
+		IsEmailAuthAllowed:  true,
+		AllowedEmailDomains: []string{"nightswatch.org"},
+	})
+
+	action := actions.SignInByEmail{Email: "sam@nightswatch.org"}
+	ExpectSuccess(action.Validate(ctx, nil))
+
+	action = actions.SignInByEmail{Email: "jon.snow@got.com"}
+	ExpectSuccess(action.Validate(ctx, nil))
+
+	action = actions.SignInByEmail{Email: "arya.stark@got.com"}
+	ExpectFailed(action.Validate(ctx, nil), "email")
+}
+
 func TestCompleteProfile_EmptyNameAndKey(t *testing.T) {
 	RegisterT(t)
 
@@ -52,3 +84,20 @@ func TestCompleteProfile_LongName(t *testing.T) {
 	result := action.Validate(context.Background(), nil)
 	ExpectFailed(result, "name", "key")
 }
+
+func TestSignInByLDAP_EmptyUsernameAndPassword(t *testing.T) {
+	RegisterT(t)
+
+	action := actions.SignInByLDAP{Username: " "}
+	result := action.Validate(context.Background(), nil)
+	ExpectFailed(result, "username", "password")
+}
+
+func TestSignInByLDAP_Valid(t *testing.T) {
+	RegisterT(t)
+
+	action := actions.SignInByLDAP{Username: " jon.snow ", Password: "ghost"}
+	result := action.Validate(context.Background(), nil)
+	ExpectSuccess(result)
+	Expect(action.Username).Equals("jon.snow")
+}
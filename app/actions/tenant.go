@@ -2,6 +2,8 @@ package actions
 
 import (
 	"context"
+	"strings"
+
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
 
@@ -230,3 +232,41 @@ func (action *UpdateTenantEmailAuthAllowed) Validate(ctx context.Context, user *
 
 	return result
 }
+
+// UpdateTenantEmailDomains is the input model used to update the email domains allowed to join a private tenant
+type UpdateTenantEmailDomains struct {
+	AllowedEmailDomains []string  `json:"allowedEmailDomains"`
+	AutoJoinRole        enum.Role `json:"autoJoinRole"`
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *UpdateTenantEmailDomains) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil && user.Role == enum.RoleAdministrator
+}
+
+// Validate if current model is valid
+func (action *UpdateTenantEmailDomains) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	result := validate.Success()
+
+	domains := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, domain := range action.AllowedEmailDomains {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+		if domain == "" || seen[domain] {
+			continue
+		}
+		seen[domain] = true
+
+		messages := validate.EmailDomain(ctx, domain)
+		result.AddFieldFailure("allowedEmailDomains", messages...)
+		domains = append(domains, domain)
+	}
+	action.AllowedEmailDomains = domains
+
+	// Administrators must always be promoted explicitly
+	if action.AutoJoinRole != enum.RoleVisitor && action.AutoJoinRole != enum.RoleCollaborator {
+		result.AddFieldFailure("autoJoinRole", propertyIsInvalid(ctx, "autoJoinRole"))
+	}
+
+	return result
+}
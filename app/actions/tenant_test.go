@@ -181,3 +181,26 @@ func TestUpdateTenantSettings_ExistingTenant_WithLogo(t *testing.T) {
 	ExpectSuccess(result)
 	Expect(action.Logo.BlobKey).Equals("hello-world.png")
 }
+
+func TestUpdateTenantEmailDomains_Normalize(t *testing.T) {
+	RegisterT(t)
+
+	action := actions.UpdateTenantEmailDomains{
+		AllowedEmailDomains: []string{" GoT.com ", "@nightswatch.org", "", "got.com"},
+		AutoJoinRole:        enum.RoleCollaborator,
+	}
+	result := action.Validate(context.Background(), nil)
+	ExpectSuccess(result)
+	Expect(action.AllowedEmailDomains).Equals([]string{"got.com", "nightswatch.org"})
+}
+
+func TestUpdateTenantEmailDomains_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	action := actions.UpdateTenantEmailDomains{
+		AllowedEmailDomains: []string{"got.com", "jon.snow@got.com"},
+		AutoJoinRole:        enum.RoleAdministrator,
+	}
+	result := action.Validate(context.Background(), nil)
+	ExpectFailed(result, "allowedEmailDomains", "autoJoinRole")
+}
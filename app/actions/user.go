@@ -2,6 +2,7 @@ package actions
 
 import (
 	"context"
+	"time"
 
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models/entity"
@@ -57,6 +58,8 @@ func (action *CreateUser) Validate(ctx context.Context, user *entity.User) *vali
 type ChangeUserRole struct {
 	Role   enum.Role `route:"role"`
 	UserID int       `json:"userID"`
+
+	User *entity.User
 }
 
 // IsAuthorized returns true if current user is authorized to perform this action
@@ -88,10 +91,177 @@ func (action *ChangeUserRole) Validate(ctx context.Context, user *entity.User) *
 		}
 	} else if userByID.Result.Tenant.ID != user.Tenant.ID {
 		result.AddFieldFailure("userID", "User not found.")
+	} else {
+		action.User = userByID.Result
+	}
+	return result
+}
+
+// EraseUser is the action used by administrators to erase another user's personal data
+type EraseUser struct {
+	UserID        int  `route:"userID"`
+	DeleteContent bool `json:"deleteContent"`
+
+	User *entity.User
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *EraseUser) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil && user.IsAdministrator() && user.ID != action.UserID
+}
+
+// Validate if current model is valid
+func (action *EraseUser) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	userByID := &query.GetUserByID{UserID: action.UserID}
+	if err := bus.Dispatch(ctx, userByID); err != nil {
+		return validate.Error(err)
+	}
+
+	if userByID.Result.Tenant.ID != user.Tenant.ID {
+		return validate.Error(app.ErrNotFound)
+	}
+
+	if userByID.Result.IsAdministrator() {
+		return validate.Failed("Administrators must be demoted before they can be erased.")
+	}
+
+	action.User = userByID.Result
+	return validate.Success()
+}
+
+// SuspendUser is the action used by moderators to temporarily suspend a user or put them on read-only mode
+type SuspendUser struct {
+	UserID   int    `route:"userID"`
+	Reason   string `json:"reason"`
+	ReadOnly bool   `json:"readOnly"`
+	Days     int    `json:"days"`
+
+	User   *entity.User
+	EndsAt *time.Time
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *SuspendUser) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil && user.IsCollaborator() && user.ID != action.UserID
+}
+
+// Validate if current model is valid
+func (action *SuspendUser) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	result := validate.Success()
+
+	if action.Reason == "" {
+		result.AddFieldFailure("reason", "Reason is required.")
+	} else if len(action.Reason) > 500 {
+		result.AddFieldFailure("reason", "Reason must have less than 500 characters.")
+	}
+
+	// Zero days means the suspension lasts until it's lifted manually
+	if action.Days < 0 || action.Days > 365 {
+		result.AddFieldFailure("days", "Suspensions can last up to 365 days.")
+	} else if action.Days > 0 {
+		endsAt := time.Now().AddDate(0, 0, action.Days)
+		action.EndsAt = &endsAt
+	}
+
+	userByID := &query.GetUserByID{UserID: action.UserID}
+	if err := bus.Dispatch(ctx, userByID); err != nil {
+		return validate.Error(err)
+	}
+
+	if userByID.Result.Tenant.ID != user.Tenant.ID {
+		return validate.Error(app.ErrNotFound)
+	}
+
+	if userByID.Result.IsCollaborator() {
+		return validate.Failed("Staff members must be demoted before they can be suspended.")
 	}
+
+	action.User = userByID.Result
 	return result
 }
 
+// MergeUsers is the action used by administrators to merge a user into another
+type MergeUsers struct {
+	SourceUserID int `route:"userID"`
+	TargetUserID int `json:"targetUserId"`
+
+	Source *entity.User
+	Target *entity.User
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *MergeUsers) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil && user.IsAdministrator() && user.ID != action.SourceUserID
+}
+
+// Validate if current model is valid
+func (action *MergeUsers) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	if action.TargetUserID == 0 {
+		return validate.Failed("Target user is required.")
+	}
+
+	if action.SourceUserID == action.TargetUserID {
+		return validate.Failed("A user cannot be merged into itself.")
+	}
+
+	source := &query.GetUserByID{UserID: action.SourceUserID}
+	target := &query.GetUserByID{UserID: action.TargetUserID}
+	if err := bus.Dispatch(ctx, source, target); err != nil {
+		return validate.Error(err)
+	}
+
+	if source.Result.Tenant.ID != user.Tenant.ID || target.Result.Tenant.ID != user.Tenant.ID {
+		return validate.Error(app.ErrNotFound)
+	}
+
+	if source.Result.IsAdministrator() {
+		return validate.Failed("Administrators must be demoted before they can be merged into another user.")
+	}
+
+	if target.Result.Status != enum.UserActive {
+		return validate.Failed("Users can only be merged into an active user.")
+	}
+
+	action.Source = source.Result
+	action.Target = target.Result
+	return validate.Success()
+}
+
+// StartImpersonation is the action used by administrators to act on behalf of another user
+type StartImpersonation struct {
+	UserID int `route:"userID"`
+
+	User *entity.User
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *StartImpersonation) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil && user.IsAdministrator() && user.ID != action.UserID
+}
+
+// Validate if current model is valid
+func (action *StartImpersonation) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	userByID := &query.GetUserByID{UserID: action.UserID}
+	if err := bus.Dispatch(ctx, userByID); err != nil {
+		return validate.Error(err)
+	}
+
+	if userByID.Result.Tenant.ID != user.Tenant.ID {
+		return validate.Error(app.ErrNotFound)
+	}
+
+	if userByID.Result.IsAdministrator() {
+		return validate.Failed("Administrators cannot be impersonated.")
+	}
+
+	if userByID.Result.Status != enum.UserActive {
+		return validate.Failed("Only active users can be impersonated.")
+	}
+
+	action.User = userByID.Result
+	return validate.Success()
+}
+
 //ChangeUserEmail is the action used to change current user's email
 type ChangeUserEmail struct {
 	Email           string `json:"email" format:"lower"`
@@ -0,0 +1,199 @@
+package actions
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/pkg/validate"
+)
+
+const maxImportedUsers = 10000
+
+var importColumns = []string{"name", "email", "role", "external_id", "organization"}
+
+// ImportUsers is used to create or update users in bulk from a CSV file
+type ImportUsers struct {
+	CSV         string `json:"csv"`
+	DryRun      bool   `json:"dryRun"`
+	SendInvites bool   `json:"sendInvites"`
+	Subject     string `json:"subject"`
+	Message     string `json:"message"`
+
+	Rows []*ImportedUser
+}
+
+// ImportedUser is a row of an user import
+// Role is only set when given on the file, so that existing users keep their role otherwise
+type ImportedUser struct {
+	Row          int       `json:"row"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	Role         enum.Role `json:"role"`
+	Reference    string    `json:"externalId"`
+	Organization string    `json:"organization"`
+	Errors       []string  `json:"errors,omitempty"`
+}
+
+// IsValid returns true if given row can be imported
+func (u *ImportedUser) IsValid() bool {
+	return len(u.Errors) == 0
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (action *ImportUsers) IsAuthorized(ctx context.Context, user *entity.User) bool {
+	return user != nil && user.IsAdministrator()
+}
+
+// Validate if current model is valid
+// Problems with individual rows don't fail the validation, they are reported on each row instead
+func (action *ImportUsers) Validate(ctx context.Context, user *entity.User) *validate.Result {
+	result := validate.Success()
+
+	if action.SendInvites {
+		if action.Subject == "" {
+			result.AddFieldFailure("subject", "Subject is required.")
+		} else if len(action.Subject) > 70 {
+			result.AddFieldFailure("subject", "Subject must have less than 70 characters.")
+		}
+
+		if action.Message == "" {
+			result.AddFieldFailure("message", "Message is required.")
+		} else if !strings.Contains(action.Message, app.InvitePlaceholder) {
+			msg := fmt.Sprintf("Your message is missing the invitation link placeholder. Please add '%s' to your message.", app.InvitePlaceholder)
+			result.AddFieldFailure("message", msg)
+		}
+	}
+
+	if strings.TrimSpace(action.CSV) == "" {
+		result.AddFieldFailure("csv", "CSV file is required.")
+		return result
+	}
+
+	reader := csv.NewReader(strings.NewReader(action.CSV))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		result.AddFieldFailure("csv", "CSV file is invalid: "+err.Error())
+		return result
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for _, column := range importColumns {
+			if name == column {
+				columns[column] = i
+			}
+		}
+	}
+
+	if _, ok := columns["name"]; !ok {
+		result.AddFieldFailure("csv", "CSV file must have a 'name' column.")
+	}
+	_, hasEmail := columns["email"]
+	_, hasReference := columns["external_id"]
+	if !hasEmail && !hasReference {
+		result.AddFieldFailure("csv", "CSV file must have either an 'email' or an 'external_id' column.")
+	}
+	if !result.Ok {
+		return result
+	}
+
+	action.Rows = make([]*ImportedUser, 0)
+	emails := make(map[string]int)
+	references := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			result.AddFieldFailure("csv", "CSV file is invalid: "+err.Error())
+			return result
+		}
+
+		if len(action.Rows) == maxImportedUsers {
+			result.AddFieldFailure("csv", fmt.Sprintf("Too many users. We limit at %d users per import.", maxImportedUsers))
+			return result
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := &ImportedUser{
+			Row:          line,
+			Name:         value("name"),
+			Email:        strings.ToLower(value("email")),
+			Reference:    value("external_id"),
+			Organization: value("organization"),
+			Errors:       make([]string, 0),
+		}
+
+		if row.Name == "" && row.Email == "" && row.Reference == "" {
+			continue
+		}
+
+		if row.Name == "" {
+			row.Errors = append(row.Errors, "Name is required.")
+		} else if len(row.Name) > 100 {
+			row.Errors = append(row.Errors, "Name must have less than 100 characters.")
+		}
+
+		if row.Email == "" && row.Reference == "" {
+			row.Errors = append(row.Errors, "Either email or external ID is required.")
+		}
+
+		if row.Email != "" {
+			row.Errors = append(row.Errors, validate.Email(ctx, row.Email)...)
+			if first, ok := emails[row.Email]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("Email is already used on line %d.", first))
+			} else {
+				emails[row.Email] = row.Row
+			}
+		}
+
+		if row.Reference != "" {
+			if len(row.Reference) > 100 {
+				row.Errors = append(row.Errors, "External ID must have less than 100 characters.")
+			}
+			if first, ok := references[row.Reference]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("External ID is already used on line %d.", first))
+			} else {
+				references[row.Reference] = row.Row
+			}
+		}
+
+		if role := strings.ToLower(value("role")); role != "" {
+			_ = row.Role.UnmarshalText([]byte(role))
+			if row.Role == 0 {
+				row.Errors = append(row.Errors, fmt.Sprintf("Role '%s' is invalid. Use visitor, collaborator or administrator.", role))
+			}
+		}
+
+		if len(row.Organization) > 100 {
+			row.Errors = append(row.Errors, "Organization must have less than 100 characters.")
+		}
+
+		action.Rows = append(action.Rows, row)
+	}
+
+	if len(action.Rows) == 0 {
+		result.AddFieldFailure("csv", "CSV file has no users to import.")
+	}
+
+	return result
+}
@@ -0,0 +1,79 @@
+package actions_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	. "github.com/getfider/fider/app/pkg/assert"
+)
+
+func TestImportUsers_InvalidFile(t *testing.T) {
+	RegisterT(t)
+
+	for _, content := range []string{
+		"",
+		"email,role\n",
+		"name,role\nJon Snow,visitor\n",
+		"name,email\n",
+		"name,email\n\"Jon Snow,jon.snow@got.com\n",
+	} {
+		action := &actions.ImportUsers{CSV: content}
+		result := action.Validate(context.Background(), &entity.User{})
+		ExpectFailed(result, "csv")
+	}
+}
+
+func TestImportUsers_InvitesRequireSubjectAndMessage(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.ImportUsers{
+		CSV:         "name,email\nJon Snow,jon.snow@got.com\n",
+		SendInvites: true,
+		Message:     "Join us!",
+	}
+	result := action.Validate(context.Background(), &entity.User{})
+	ExpectFailed(result, "subject", "message")
+}
+
+func TestImportUsers_Rows(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.ImportUsers{CSV: `Name,Email,Role,External_ID,Organization
+Jon Snow,Jon.Snow@got.com,administrator,jon,Night's Watch
+Arya Stark,arya.stark@got.com,,,
+,,,,
+Sansa Stark,not-an-email,queen,,
+Bran Stark,jon.snow@got.com,visitor,jon,
+,hodor@got.com,,,
+`}
+	result := action.Validate(context.Background(), &entity.User{})
+	ExpectSuccess(result)
+	Expect(action.Rows).HasLen(5)
+
+	Expect(action.Rows[0].Row).Equals(2)
+	Expect(action.Rows[0].Name).Equals("Jon Snow")
+	Expect(action.Rows[0].Email).Equals("jon.snow@got.com")
+	Expect(action.Rows[0].Role).Equals(enum.RoleAdministrator)
+	Expect(action.Rows[0].Reference).Equals("jon")
+	Expect(action.Rows[0].Organization).Equals("Night's Watch")
+	Expect(action.Rows[0].IsValid()).IsTrue()
+
+	Expect(action.Rows[1].Row).Equals(3)
+	Expect(action.Rows[1].Role).Equals(enum.Role(0))
+	Expect(action.Rows[1].IsValid()).IsTrue()
+
+	Expect(action.Rows[2].Row).Equals(5)
+	Expect(action.Rows[2].Errors).HasLen(2)
+
+	Expect(action.Rows[3].Row).Equals(6)
+	Expect(action.Rows[3].Errors).Equals([]string{
+		"Email is already used on line 2.",
+		"External ID is already used on line 2.",
+	})
+
+	Expect(action.Rows[4].Row).Equals(7)
+	Expect(action.Rows[4].Errors).Equals([]string{"Name is required."})
+}
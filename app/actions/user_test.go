@@ -3,6 +3,7 @@ package actions_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/actions"
@@ -189,3 +190,132 @@ func TestChangeUserRole_CurrentUser(t *testing.T) {
 	result := action.Validate(context.Background(), currentUser)
 	ExpectFailed(result, "userID")
 }
+
+func TestEraseUser_Unauthorized(t *testing.T) {
+	RegisterT(t)
+
+	for _, user := range []*entity.User{
+		{ID: 1, Role: enum.RoleVisitor},
+		{ID: 1, Role: enum.RoleCollaborator},
+		{ID: 2, Role: enum.RoleAdministrator},
+	} {
+		action := actions.EraseUser{UserID: 2}
+		Expect(action.IsAuthorized(context.Background(), user)).IsFalse()
+	}
+}
+
+func TestEraseUser_InvalidUser_Tenant(t *testing.T) {
+	RegisterT(t)
+
+	targetUser := &entity.User{ID: 3, Tenant: &entity.Tenant{ID: 1}}
+	currentUser := &entity.User{ID: 1, Tenant: &entity.Tenant{ID: 2}, Role: enum.RoleAdministrator}
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		q.Result = targetUser
+		return nil
+	})
+
+	action := actions.EraseUser{UserID: targetUser.ID}
+	result := action.Validate(context.Background(), currentUser)
+	Expect(result.Err).Equals(app.ErrNotFound)
+}
+
+func TestEraseUser_Administrator(t *testing.T) {
+	RegisterT(t)
+
+	targetUser := &entity.User{ID: 3, Tenant: &entity.Tenant{ID: 1}, Role: enum.RoleAdministrator}
+	currentUser := &entity.User{ID: 1, Tenant: &entity.Tenant{ID: 1}, Role: enum.RoleAdministrator}
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		q.Result = targetUser
+		return nil
+	})
+
+	action := actions.EraseUser{UserID: targetUser.ID}
+	result := action.Validate(context.Background(), currentUser)
+	ExpectFailed(result)
+}
+
+func TestEraseUser_Valid(t *testing.T) {
+	RegisterT(t)
+
+	targetUser := &entity.User{ID: 3, Tenant: &entity.Tenant{ID: 1}, Role: enum.RoleCollaborator}
+	currentUser := &entity.User{ID: 1, Tenant: &entity.Tenant{ID: 1}, Role: enum.RoleAdministrator}
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		q.Result = targetUser
+		return nil
+	})
+
+	action := actions.EraseUser{UserID: targetUser.ID, DeleteContent: true}
+	Expect(action.IsAuthorized(context.Background(), currentUser)).IsTrue()
+	result := action.Validate(context.Background(), currentUser)
+	ExpectSuccess(result)
+	Expect(action.User).Equals(targetUser)
+}
+
+func TestSuspendUser_Unauthorized(t *testing.T) {
+	RegisterT(t)
+
+	for _, user := range []*entity.User{
+		{ID: 1, Role: enum.RoleVisitor},
+		{ID: 2, Role: enum.RoleCollaborator},
+	} {
+		action := actions.SuspendUser{UserID: 2}
+		Expect(action.IsAuthorized(context.Background(), user)).IsFalse()
+	}
+
+	action := actions.SuspendUser{UserID: 2}
+	Expect(action.IsAuthorized(context.Background(), &entity.User{ID: 1, Role: enum.RoleCollaborator})).IsTrue()
+}
+
+func TestSuspendUser_InvalidInput(t *testing.T) {
+	RegisterT(t)
+
+	targetUser := &entity.User{ID: 3, Tenant: &entity.Tenant{ID: 1}, Role: enum.RoleVisitor}
+	currentUser := &entity.User{ID: 1, Tenant: &entity.Tenant{ID: 1}, Role: enum.RoleCollaborator}
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		q.Result = targetUser
+		return nil
+	})
+
+	action := actions.SuspendUser{UserID: targetUser.ID, Days: 400}
+	ExpectFailed(action.Validate(context.Background(), currentUser), "reason", "days")
+}
+
+func TestSuspendUser_StaffMember(t *testing.T) {
+	RegisterT(t)
+
+	targetUser := &entity.User{ID: 3, Tenant: &entity.Tenant{ID: 1}, Role: enum.RoleCollaborator}
+	currentUser := &entity.User{ID: 1, Tenant: &entity.Tenant{ID: 1}, Role: enum.RoleAdministrator}
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		q.Result = targetUser
+		return nil
+	})
+
+	action := actions.SuspendUser{UserID: targetUser.ID, Reason: "Spamming", Days: 7}
+	ExpectFailed(action.Validate(context.Background(), currentUser))
+}
+
+func TestSuspendUser_Valid(t *testing.T) {
+	RegisterT(t)
+
+	targetUser := &entity.User{ID: 3, Tenant: &entity.Tenant{ID: 1}, Role: enum.RoleVisitor}
+	currentUser := &entity.User{ID: 1, Tenant: &entity.Tenant{ID: 1}, Role: enum.RoleCollaborator}
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		q.Result = targetUser
+		return nil
+	})
+
+	action := actions.SuspendUser{UserID: targetUser.ID, Reason: "Spamming", Days: 7}
+	ExpectSuccess(action.Validate(context.Background(), currentUser))
+	Expect(action.User).Equals(targetUser)
+	Expect(*action.EndsAt).TemporarilySimilar(time.Now().AddDate(0, 0, 7), time.Minute)
+
+	action = actions.SuspendUser{UserID: targetUser.ID, Reason: "Heated discussions", ReadOnly: true}
+	ExpectSuccess(action.Validate(context.Background(), currentUser))
+	Expect(action.EndsAt).IsNil()
+}
@@ -34,6 +34,27 @@ func routes(r *web.Engine) *web.Engine {
 	})
 
 	r.Use(middlewares.Secure())
+
+	// Mail services post replies as forms or raw messages, so it must be registered before CSRF protection
+	// A secret alone is enough to receive bounce and complaint notifications of Amazon SES
+	if env.IsInboundEmailEnabled() || env.Config.Email.Inbound.Secret != "" {
+		ie := r.Group()
+		{
+			ie.Use(middlewares.WebSetup())
+			ie.Post("/_api/inbound/email", webhooks.IncomingEmail())
+		}
+	}
+
+	// Mail clients unsubscribe with a plain form post when the one-click List-Unsubscribe header is used (RFC 8058)
+	// The signed token of the link is what authorizes it, so it's also registered before CSRF protection
+	us := r.Group()
+	{
+		us.Use(middlewares.WebSetup())
+		us.Use(middlewares.Tenant())
+		us.Use(middlewares.RequireTenant())
+		us.Post("/unsubscribe/:token", handlers.Unsubscribe())
+	}
+
 	r.Use(middlewares.CSRF())
 	r.Use(middlewares.Compress())
 
@@ -48,6 +69,7 @@ func routes(r *web.Engine) *web.Engine {
 	r.Use(middlewares.Session())
 
 	r.Get("/robots.txt", handlers.RobotsTXT())
+	r.Get("/service-worker.js", handlers.ServiceWorker())
 	r.Post("/_api/log-error", handlers.LogError())
 
 	r.Use(middlewares.Maintenance())
@@ -103,8 +125,18 @@ func routes(r *web.Engine) *web.Engine {
 	r.Get("/not-invited", handlers.NotInvitedPage())
 	r.Get("/signin/verify", handlers.VerifySignInKey(enum.EmailVerificationKindSignIn))
 	r.Get("/invite/verify", handlers.VerifySignInKey(enum.EmailVerificationKindUserInvitation))
+	r.Get("/sso", handlers.SingleSignOn())
+	r.Get("/unsubscribe/:token", handlers.UnsubscribePage())
 	r.Post("/_api/signin/complete", handlers.CompleteSignInProfile())
-	r.Post("/_api/signin", handlers.SignInByEmail())
+	r.Post("/_api/signin/passkey/options", handlers.PasskeySignInOptions())
+
+	signIn := r.Group()
+	{
+		signIn.Use(middlewares.RateLimit(middlewares.RateLimitSignIn))
+		signIn.Post("/_api/signin", handlers.SignInByEmail())
+		signIn.Post("/_api/signin/ldap", handlers.SignInByLDAP())
+		signIn.Post("/_api/signin/passkey", handlers.SignInByPasskey())
+	}
 
 	//Block if it's private tenant with unauthenticated user
 	r.Use(middlewares.CheckTenantPrivacy())
@@ -112,6 +144,7 @@ func routes(r *web.Engine) *web.Engine {
 	r.Get("/", handlers.Index())
 	r.Get("/posts/:number", handlers.PostDetails())
 	r.Get("/posts/:number/:slug", handlers.PostDetails())
+	r.Get("/users/:userID", handlers.UserProfile())
 
 	ui := r.Group()
 	{
@@ -123,12 +156,41 @@ func routes(r *web.Engine) *web.Engine {
 		ui.Get("/notifications/:id", handlers.ReadNotification())
 		ui.Get("/change-email/verify", handlers.VerifyChangeEmailKey())
 
-		ui.Delete("/_api/user", handlers.DeleteUser())
-		ui.Post("/_api/user/regenerate-apikey", handlers.RegenerateAPIKey())
-		ui.Post("/_api/user/settings", handlers.UpdateUserSettings())
-		ui.Post("/_api/user/change-email", handlers.ChangeUserEmail())
+		account := ui.Group()
+		{
+			// Administrators can't manage the account of the user they are impersonating
+			account.Use(middlewares.BlockImpersonation())
+
+			account.Get("/settings/export.zip", handlers.ExportMyData())
+			account.Delete("/_api/user", handlers.DeleteUser())
+			account.Post("/_api/user/regenerate-apikey", handlers.RegenerateAPIKey())
+			account.Post("/_api/user/change-email", handlers.ChangeUserEmail())
+			account.Post("/_api/user/passkeys/options", handlers.PasskeyRegistrationOptions())
+			account.Post("/_api/user/passkeys", handlers.RegisterPasskey())
+			account.Delete("/_api/user/passkeys/:id", handlers.DeletePasskey())
+		}
+
+		profile := ui.Group()
+		{
+			// Name, bio, link and avatar are shown to everyone, so they can't be changed while suspended or on read-only
+			profile.Use(middlewares.BlockSuspendedUsers(false))
+			profile.Post("/_api/user/settings", handlers.UpdateUserSettings())
+		}
+
+		preferences := ui.Group()
+		{
+			preferences.Use(middlewares.BlockSuspendedUsers(true))
+			preferences.Post("/_api/user/push-subscriptions", handlers.AddPushSubscription())
+			preferences.Delete("/_api/user/push-subscriptions", handlers.DeletePushSubscription())
+			preferences.Post("/_api/user/saved-searches", handlers.AddSavedSearch())
+			preferences.Put("/_api/user/saved-searches/:id", handlers.UpdateSavedSearch())
+			preferences.Delete("/_api/user/saved-searches/:id", handlers.DeleteSavedSearch())
+		}
+
 		ui.Post("/_api/notifications/read-all", handlers.ReadAllNotifications())
+		ui.Post("/_api/impersonation/stop", handlers.StopImpersonation())
 		ui.Get("/_api/notifications/unread/total", handlers.TotalUnreadNotifications())
+		ui.Get("/_api/events", handlers.StreamEvents())
 
 		// From this step, only Collaborators and Administrators are allowed
 		ui.Use(middlewares.IsAuthorized(enum.RoleCollaborator, enum.RoleAdministrator))
@@ -139,12 +201,15 @@ func routes(r *web.Engine) *web.Engine {
 
 		ui.Get("/admin", handlers.GeneralSettingsPage())
 		ui.Get("/admin/advanced", handlers.AdvancedSettingsPage())
-		ui.Get("/admin/privacy", handlers.Page("Privacy · Site Settings", "", "Administration/pages/PrivacySettings.page"))
+		ui.Get("/admin/privacy", handlers.PrivacySettingsPage())
 		ui.Get("/admin/invitations", handlers.Page("Invitations · Site Settings", "", "Administration/pages/Invitations.page"))
 		ui.Get("/admin/members", handlers.ManageMembers())
 		ui.Get("/admin/tags", handlers.ManageTags())
+		ui.Get("/admin/moderation", handlers.Page("Moderation · Site Settings", "", "Administration/pages/ModerationInbox.page"))
 		ui.Get("/admin/authentication", handlers.ManageAuthentication())
 		ui.Get("/_api/admin/oauth/:provider", handlers.GetOAuthConfig())
+		ui.Post("/_api/admin/users/:userID/suspension", handlers.SuspendUser())
+		ui.Delete("/_api/admin/users/:userID/suspension", handlers.LiftUserSuspension())
 
 		//From this step, only Administrators are allowed
 		ui.Use(middlewares.IsAuthorized(enum.RoleAdministrator))
@@ -153,20 +218,40 @@ func routes(r *web.Engine) *web.Engine {
 		ui.Get("/admin/export/posts.csv", handlers.ExportPostsToCSV())
 		ui.Get("/admin/export/backup.zip", handlers.ExportBackupZip())
 		ui.Get("/admin/webhooks", handlers.ManageWebhooks())
+		ui.Get("/admin/audit", handlers.ManageAuditLog())
+		ui.Get("/admin/import-users", handlers.Page("Import Users · Site Settings", "", "Administration/pages/ImportUsers.page"))
 		ui.Post("/_api/admin/webhook", handlers.CreateWebhook())
 		ui.Put("/_api/admin/webhook/:id", handlers.UpdateWebhook())
 		ui.Delete("/_api/admin/webhook/:id", handlers.DeleteWebhook())
 		ui.Get("/_api/admin/webhook/test/:id", handlers.TestWebhook())
 		ui.Post("/_api/admin/webhook/preview", handlers.PreviewWebhook())
 		ui.Get("/_api/admin/webhook/props/:type", handlers.GetWebhookProps())
+		ui.Get("/admin/email-templates", handlers.ManageEmailTemplates())
+		ui.Put("/_api/admin/email-templates/:kind", handlers.SaveEmailTemplate())
+		ui.Delete("/_api/admin/email-templates/:kind", handlers.ResetEmailTemplate())
+		ui.Post("/_api/admin/email-templates/:kind/preview", handlers.PreviewEmailTemplate())
+		ui.Post("/_api/admin/email-templates/:kind/test", handlers.SendTestEmailTemplate())
+		ui.Get("/admin/emails", handlers.EmailLog())
+		ui.Get("/_api/admin/emails", handlers.SearchEmailLog())
+		ui.Post("/_api/admin/emails/:id/resend", handlers.ResendEmail())
+		ui.Delete("/_api/admin/email-suppressions/:userID", handlers.ClearEmailSupression())
 		ui.Post("/_api/admin/settings/general", handlers.UpdateSettings())
 		ui.Post("/_api/admin/settings/advanced", handlers.UpdateAdvancedSettings())
 		ui.Post("/_api/admin/settings/privacy", handlers.UpdatePrivacy())
+		ui.Post("/_api/admin/settings/emaildomains", handlers.UpdateEmailDomains())
 		ui.Post("/_api/admin/settings/emailauth", handlers.UpdateEmailAuthAllowed())
+		ui.Post("/_api/admin/settings/passkey", handlers.UpdatePasskeySettings())
+		ui.Post("/_api/admin/settings/sso", handlers.RegenerateSSOSecret())
+		ui.Delete("/_api/admin/settings/sso", handlers.DisableSSO())
 		ui.Post("/_api/admin/oauth", handlers.SaveOAuthConfig())
 		ui.Post("/_api/admin/roles/:role/users", handlers.ChangeUserRole())
 		ui.Put("/_api/admin/users/:userID/block", handlers.BlockUser())
 		ui.Delete("/_api/admin/users/:userID/block", handlers.UnblockUser())
+		ui.Put("/_api/admin/users/:userID/profile/hidden", handlers.HideUserProfile())
+		ui.Delete("/_api/admin/users/:userID/profile/hidden", handlers.ShowUserProfile())
+		ui.Post("/_api/admin/users/:userID/erase", handlers.EraseUser())
+		ui.Post("/_api/admin/users/:userID/impersonate", handlers.StartImpersonation())
+		ui.Get("/admin/members/:userID/export.zip", handlers.ExportUserData())
 
 		if env.IsBillingEnabled() {
 			ui.Get("/admin/billing", handlers.ManageBilling())
@@ -178,29 +263,62 @@ func routes(r *web.Engine) *web.Engine {
 	// Does not require authentication
 	publicApi := r.Group()
 	{
+		publicApi.Use(middlewares.RateLimit(middlewares.RateLimitAPI))
+
 		publicApi.Get("/api/v1/posts", apiv1.SearchPosts())
 		publicApi.Get("/api/v1/tags", apiv1.ListTags())
 		publicApi.Get("/api/v1/posts/:number", apiv1.GetPost())
 		publicApi.Get("/api/v1/posts/:number/comments", apiv1.ListComments())
 		publicApi.Get("/api/v1/posts/:number/comments/:id", apiv1.GetComment())
+		publicApi.Get("/api/v1/users/:userID", apiv1.GetUser())
 	}
 
 	// Operations used to manage the content of a site
 	// Available to any authenticated user
 	membersApi := r.Group()
 	{
+		membersApi.Use(middlewares.RateLimit(middlewares.RateLimitAPI))
 		membersApi.Use(middlewares.IsAuthenticated())
 		membersApi.Use(middlewares.BlockLockedTenants())
 
-		membersApi.Post("/api/v1/posts", apiv1.CreatePost())
-		membersApi.Put("/api/v1/posts/:number", apiv1.UpdatePost())
-		membersApi.Post("/api/v1/posts/:number/comments", apiv1.PostComment())
-		membersApi.Put("/api/v1/posts/:number/comments/:id", apiv1.UpdateComment())
-		membersApi.Delete("/api/v1/posts/:number/comments/:id", apiv1.DeleteComment())
-		membersApi.Post("/api/v1/posts/:number/votes", apiv1.AddVote())
-		membersApi.Delete("/api/v1/posts/:number/votes", apiv1.RemoveVote())
-		membersApi.Post("/api/v1/posts/:number/subscription", apiv1.Subscribe())
-		membersApi.Delete("/api/v1/posts/:number/subscription", apiv1.Unsubscribe())
+		postsApi := membersApi.Group()
+		{
+			postsApi.Use(middlewares.RateLimit(middlewares.RateLimitPosts))
+			postsApi.Use(middlewares.BlockSuspendedUsers(false))
+			postsApi.Post("/api/v1/posts", apiv1.CreatePost())
+		}
+
+		commentsApi := membersApi.Group()
+		{
+			commentsApi.Use(middlewares.RateLimit(middlewares.RateLimitComments))
+			commentsApi.Use(middlewares.BlockSuspendedUsers(false))
+			commentsApi.Post("/api/v1/posts/:number/comments", apiv1.PostComment())
+		}
+
+		votesApi := membersApi.Group()
+		{
+			votesApi.Use(middlewares.RateLimit(middlewares.RateLimitVotes))
+			votesApi.Use(middlewares.BlockSuspendedUsers(true))
+			votesApi.Post("/api/v1/posts/:number/votes", apiv1.AddVote())
+			votesApi.Delete("/api/v1/posts/:number/votes", apiv1.RemoveVote())
+		}
+
+		editsApi := membersApi.Group()
+		{
+			editsApi.Use(middlewares.BlockSuspendedUsers(false))
+			editsApi.Put("/api/v1/posts/:number", apiv1.UpdatePost())
+			editsApi.Put("/api/v1/posts/:number/comments/:id", apiv1.UpdateComment())
+			editsApi.Delete("/api/v1/posts/:number/comments/:id", apiv1.DeleteComment())
+			editsApi.Post("/api/v1/posts/:number/report", apiv1.ReportContent())
+			editsApi.Post("/api/v1/posts/:number/comments/:id/report", apiv1.ReportContent())
+		}
+
+		subscriptionsApi := membersApi.Group()
+		{
+			subscriptionsApi.Use(middlewares.BlockSuspendedUsers(true))
+			subscriptionsApi.Post("/api/v1/posts/:number/subscription", apiv1.Subscribe())
+			subscriptionsApi.Delete("/api/v1/posts/:number/subscription", apiv1.Unsubscribe())
+		}
 
 		membersApi.Use(middlewares.IsAuthorized(enum.RoleCollaborator, enum.RoleAdministrator))
 		membersApi.Put("/api/v1/posts/:number/status", apiv1.SetResponse())
@@ -210,24 +328,31 @@ func routes(r *web.Engine) *web.Engine {
 	// Available to both collaborators and administrators
 	staffApi := r.Group()
 	{
+		staffApi.Use(middlewares.RateLimit(middlewares.RateLimitAPI))
 		staffApi.Use(middlewares.SetLocale("en"))
 		staffApi.Use(middlewares.IsAuthenticated())
 		staffApi.Use(middlewares.IsAuthorized(enum.RoleCollaborator, enum.RoleAdministrator))
 
 		staffApi.Get("/api/v1/users", apiv1.ListUsers())
+		staffApi.Get("/api/v1/organizations", apiv1.ListOrganizations())
 		staffApi.Get("/api/v1/posts/:number/votes", apiv1.ListVotes())
 		staffApi.Post("/api/v1/invitations/send", apiv1.SendInvites())
 		staffApi.Post("/api/v1/invitations/sample", apiv1.SendSampleInvite())
+		staffApi.Get("/api/v1/reports", apiv1.ListContentReports())
 
 		staffApi.Use(middlewares.BlockLockedTenants())
 		staffApi.Post("/api/v1/posts/:number/tags/:slug", apiv1.AssignTag())
 		staffApi.Delete("/api/v1/posts/:number/tags/:slug", apiv1.UnassignTag())
+		staffApi.Put("/api/v1/posts/:number/approve", apiv1.ApprovePost())
+		staffApi.Put("/api/v1/posts/:number/comments/:id/approve", apiv1.ApproveComment())
+		staffApi.Post("/api/v1/reports/resolve", apiv1.ResolveContentReports())
 	}
 
 	// Operations used to manage a site
 	// Only available to administrators
 	adminApi := r.Group()
 	{
+		adminApi.Use(middlewares.RateLimit(middlewares.RateLimitAPI))
 		adminApi.Use(middlewares.SetLocale("en"))
 		adminApi.Use(middlewares.IsAuthenticated())
 		adminApi.Use(middlewares.IsAuthorized(enum.RoleAdministrator))
@@ -236,9 +361,17 @@ func routes(r *web.Engine) *web.Engine {
 		adminApi.Post("/api/v1/tags", apiv1.CreateEditTag())
 		adminApi.Put("/api/v1/tags/:slug", apiv1.CreateEditTag())
 		adminApi.Delete("/api/v1/tags/:slug", apiv1.DeleteTag())
+		adminApi.Get("/api/v1/audit-logs", apiv1.SearchAuditLogs())
+		adminApi.Get("/api/v1/user-imports/:id", apiv1.GetUserImport())
+		adminApi.Post("/api/v1/organizations", apiv1.CreateEditOrganization())
+		adminApi.Put("/api/v1/organizations/:id", apiv1.CreateEditOrganization())
+		adminApi.Delete("/api/v1/organizations/:id", apiv1.DeleteOrganization())
+		adminApi.Put("/api/v1/users/:userID/organization", apiv1.SetUserOrganization())
 
 		adminApi.Use(middlewares.BlockLockedTenants())
 		adminApi.Delete("/api/v1/posts/:number", apiv1.DeletePost())
+		adminApi.Post("/api/v1/users/:userID/merge", apiv1.MergeUsers())
+		adminApi.Post("/api/v1/user-imports", apiv1.ImportUsers())
 	}
 
 	return r
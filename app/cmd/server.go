@@ -2,19 +2,25 @@ package cmd
 
 import (
 	"context"
+	"net"
 	"os"
 	"os/signal"
 	"path"
+	"strings"
 	"syscall"
 
 	"github.com/getfider/fider/app/jobs"
 	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/inbound"
 	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/realtime"
 	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/tasks"
 	"github.com/robfig/cron"
 
 	_ "github.com/getfider/fider/app/services/billing/paddle"
@@ -25,12 +31,15 @@ import (
 	_ "github.com/getfider/fider/app/services/email/mailgun"
 	_ "github.com/getfider/fider/app/services/email/smtp"
 	_ "github.com/getfider/fider/app/services/httpclient"
+	_ "github.com/getfider/fider/app/services/ldap"
 	_ "github.com/getfider/fider/app/services/log/console"
 	_ "github.com/getfider/fider/app/services/log/file"
 	_ "github.com/getfider/fider/app/services/log/sql"
 	_ "github.com/getfider/fider/app/services/oauth"
+	_ "github.com/getfider/fider/app/services/spamfilter"
 	_ "github.com/getfider/fider/app/services/sqlstore/postgres"
 	_ "github.com/getfider/fider/app/services/webhook"
+	_ "github.com/getfider/fider/app/services/webpush"
 )
 
 //RunServer starts the Fider Server
@@ -48,16 +57,56 @@ func RunServer() int {
 	copyEtcFiles(ctx)
 	startJobs(ctx)
 
+	if err := realtime.Listen(ctx); err != nil {
+		panic(err)
+	}
+
 	e := routes(web.New())
 	go e.Start(":" + env.Config.Port)
-	return listenSignals(e)
+	smtp := startSMTP(ctx, e)
+	return listenSignals(e, smtp)
+}
+
+// Starts the SMTP listener that receives replies to notifications and bounce messages
+// The returned listener is nil when the SMTP listener is disabled
+func startSMTP(ctx context.Context, e *web.Engine) net.Listener {
+	if !env.IsInboundEmailEnabled() || env.Config.Email.Inbound.SMTPPort == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", ":"+env.Config.Email.Inbound.SMTPPort)
+	if err != nil {
+		panic(errors.Wrap(err, "failed to listen for inbound emails"))
+	}
+
+	log.Infof(ctx, "Listening for inbound emails on port @{Port}", dto.Props{
+		"Port": env.Config.Email.Inbound.SMTPPort,
+	})
+
+	_, hostname, _ := strings.Cut(env.Config.Email.Inbound.Address, "@")
+	go func() {
+		err := inbound.ServeSMTP(listener, hostname, func(email *dto.InboundEmail) {
+			e.Worker().Enqueue(tasks.ReceiveEmail(email))
+		})
+		if err != nil {
+			log.Error(ctx, err)
+		}
+	}()
+
+	return listener
 }
 
 // Starts all scheduled jobs
 func startJobs(ctx context.Context) {
 	c := cron.New()
 	_ = c.AddJob(jobs.NewJob(ctx, "PurgeExpiredNotificationsJob", jobs.PurgeExpiredNotificationsJobHandler{}))
+	_ = c.AddJob(jobs.NewJob(ctx, "PurgeExpiredRateLimitsJob", jobs.PurgeExpiredRateLimitsJobHandler{}))
+	_ = c.AddJob(jobs.NewJob(ctx, "LiftExpiredSuspensionsJob", jobs.LiftExpiredSuspensionsJobHandler{}))
 	_ = c.AddJob(jobs.NewJob(ctx, "EmailSupressionJob", jobs.EmailSupressionJobHandler{}))
+	_ = c.AddJob(jobs.NewJob(ctx, "EmailOutboxJob", jobs.EmailOutboxJobHandler{}))
+	_ = c.AddJob(jobs.NewJob(ctx, "PurgeOutboxEmailsJob", jobs.PurgeOutboxEmailsJobHandler{}))
+	_ = c.AddJob(jobs.NewJob(ctx, "DailyEmailDigestJob", jobs.EmailDigestJobHandler{Delivery: enum.EmailDeliveryDaily}))
+	_ = c.AddJob(jobs.NewJob(ctx, "WeeklyEmailDigestJob", jobs.EmailDigestJobHandler{Delivery: enum.EmailDeliveryWeekly}))
 
 	if env.IsBillingEnabled() {
 		_ = c.AddJob(jobs.NewJob(ctx, "LockExpiredTenantsJob", jobs.LockExpiredTenantsJobHandler{}))
@@ -105,13 +154,17 @@ func copyEtcFiles(ctx context.Context) {
 	}
 }
 
-func listenSignals(e *web.Engine) int {
+func listenSignals(e *web.Engine, smtp net.Listener) int {
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, append([]os.Signal{syscall.SIGTERM, syscall.SIGINT}, extraSignals...)...)
 	for {
 		s := <-signals
 		switch s {
 		case syscall.SIGINT, syscall.SIGTERM:
+			realtime.Close()
+			if smtp != nil {
+				_ = smtp.Close()
+			}
 			err := e.Stop()
 			if err != nil {
 				return 1
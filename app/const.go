@@ -24,13 +24,16 @@ const (
 	GoogleProvider = "google"
 	//GitHubProvider is const for 'github'
 	GitHubProvider = "github"
+	//LDAPProvider is const for 'ldap'
+	LDAPProvider = "ldap"
 )
 
 var (
-	RequestCtxKey     = createKey("REQUEST")
-	TransactionCtxKey = createKey("TRANSACTION")
-	TenantCtxKey      = createKey("TENANT")
-	LocaleCtxKey      = createKey("LOCALE")
-	UserCtxKey        = createKey("USER")
-	LogPropsCtxKey    = createKey("LOG_PROPS")
+	RequestCtxKey      = createKey("REQUEST")
+	TransactionCtxKey  = createKey("TRANSACTION")
+	TenantCtxKey       = createKey("TENANT")
+	LocaleCtxKey       = createKey("LOCALE")
+	UserCtxKey         = createKey("USER")
+	ImpersonatorCtxKey = createKey("IMPERSONATOR")
+	LogPropsCtxKey     = createKey("LOG_PROPS")
 )
@@ -6,9 +6,11 @@ import (
 	"github.com/getfider/fider/app/actions"
 	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/web"
+	webutil "github.com/getfider/fider/app/pkg/web/util"
 )
 
 // GeneralSettingsPage is the general settings page
@@ -42,6 +44,16 @@ func UpdateSettings() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
+		tenant := c.Tenant()
+		before := entity.AuditValues{
+			"title":          tenant.Name,
+			"invitation":     tenant.Invitation,
+			"welcomeMessage": tenant.WelcomeMessage,
+			"cname":          tenant.CNAME,
+			"locale":         tenant.Locale,
+			"logoBlobKey":    tenant.LogoBlobKey,
+		}
+
 		if err := bus.Dispatch(c,
 			&cmd.UploadImage{
 				Image:  action.Logo,
@@ -59,6 +71,18 @@ func UpdateSettings() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		err := auditSettings(c, "general", before, entity.AuditValues{
+			"title":          action.Title,
+			"invitation":     action.Invitation,
+			"welcomeMessage": action.WelcomeMessage,
+			"cname":          action.CNAME,
+			"locale":         action.Locale,
+			"logoBlobKey":    action.Logo.BlobKey,
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		return c.Ok(web.Map{})
 	}
 }
@@ -71,12 +95,18 @@ func UpdateAdvancedSettings() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
+		before := entity.AuditValues{"customCSS": c.Tenant().CustomCSS}
 		if err := bus.Dispatch(c, &cmd.UpdateTenantAdvancedSettings{
 			CustomCSS: action.CustomCSS,
 		}); err != nil {
 			return c.Failure(err)
 		}
 
+		err := auditSettings(c, "advanced", before, entity.AuditValues{"customCSS": action.CustomCSS})
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		return c.Ok(web.Map{})
 	}
 }
@@ -89,6 +119,7 @@ func UpdatePrivacy() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
+		before := entity.AuditValues{"isPrivate": c.Tenant().IsPrivate}
 		updateSettings := &cmd.UpdateTenantPrivacySettings{
 			IsPrivate: action.IsPrivate,
 		}
@@ -96,6 +127,57 @@ func UpdatePrivacy() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		err := auditSettings(c, "privacy", before, entity.AuditValues{"isPrivate": action.IsPrivate})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// PrivacySettingsPage is the page used by administrators to change site privacy settings
+func PrivacySettingsPage() web.HandlerFunc {
+	return func(c *web.Context) error {
+		return c.Page(http.StatusOK, web.Props{
+			Page:  "Administration/pages/PrivacySettings.page",
+			Title: "Privacy · Site Settings",
+			Data: web.Map{
+				"allowedEmailDomains": c.Tenant().AllowedEmailDomains,
+				"autoJoinRole":        c.Tenant().AutoJoinRole,
+			},
+		})
+	}
+}
+
+// UpdateEmailDomains update current tenant's trusted email domains settings
+func UpdateEmailDomains() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.UpdateTenantEmailDomains)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		before := entity.AuditValues{
+			"allowedEmailDomains": c.Tenant().AllowedEmailDomains,
+			"autoJoinRole":        c.Tenant().AutoJoinRole,
+		}
+		updateSettings := &cmd.UpdateTenantEmailDomainSettings{
+			AllowedEmailDomains: action.AllowedEmailDomains,
+			AutoJoinRole:        action.AutoJoinRole,
+		}
+		if err := bus.Dispatch(c, updateSettings); err != nil {
+			return c.Failure(err)
+		}
+
+		err := auditSettings(c, "email_domains", before, entity.AuditValues{
+			"allowedEmailDomains": action.AllowedEmailDomains,
+			"autoJoinRole":        action.AutoJoinRole,
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		return c.Ok(web.Map{})
 	}
 }
@@ -108,6 +190,7 @@ func UpdateEmailAuthAllowed() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
+		before := entity.AuditValues{"isEmailAuthAllowed": c.Tenant().IsEmailAuthAllowed}
 		updateSettings := &cmd.UpdateTenantEmailAuthAllowedSettings{
 			IsEmailAuthAllowed: action.IsEmailAuthAllowed,
 		}
@@ -115,6 +198,11 @@ func UpdateEmailAuthAllowed() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		err := auditSettings(c, "email_auth", before, entity.AuditValues{"isEmailAuthAllowed": action.IsEmailAuthAllowed})
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		return c.Ok(web.Map{})
 	}
 }
@@ -123,7 +211,8 @@ func UpdateEmailAuthAllowed() web.HandlerFunc {
 func ManageMembers() web.HandlerFunc {
 	return func(c *web.Context) error {
 		allUsers := &query.GetAllUsers{}
-		if err := bus.Dispatch(c, allUsers); err != nil {
+		suspensions := &query.GetActiveUserSuspensions{}
+		if err := bus.Dispatch(c, allUsers, suspensions); err != nil {
 			return c.Failure(err)
 		}
 
@@ -139,7 +228,26 @@ func ManageMembers() web.HandlerFunc {
 			Page:  "Administration/pages/ManageMembers.page",
 			Title: "Manage Members · Site Settings",
 			Data: web.Map{
-				"users": allUsersWithEmail,
+				"users":       allUsersWithEmail,
+				"suspensions": suspensions.Result,
+			},
+		})
+	}
+}
+
+// ManageAuditLog is the page used by administrators to review privileged actions
+func ManageAuditLog() web.HandlerFunc {
+	return func(c *web.Context) error {
+		searchAuditLogs := &query.SearchAuditLogs{}
+		if err := bus.Dispatch(c, searchAuditLogs); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Page(http.StatusOK, web.Props{
+			Page:  "Administration/pages/AuditLog.page",
+			Title: "Audit Log · Site Settings",
+			Data: web.Map{
+				"logs": searchAuditLogs.Result,
 			},
 		})
 	}
@@ -153,11 +261,18 @@ func ManageAuthentication() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		// the SSO secret is only visible to administrators
+		ssoSecret := ""
+		if c.User().IsAdministrator() {
+			ssoSecret = c.Tenant().SSOSecret
+		}
+
 		return c.Page(http.StatusOK, web.Props{
 			Page:  "Administration/pages/ManageAuthentication.page",
 			Title: "Authentication · Site Settings",
 			Data: web.Map{
 				"providers": listProviders.Result,
+				"ssoSecret": ssoSecret,
 			},
 		})
 	}
@@ -211,6 +326,61 @@ func SaveOAuthConfig() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		auditLog := &cmd.AddAuditLog{
+			Action:     enum.AuditOAuthConfigSaved,
+			TargetType: "oauth_provider",
+			TargetID:   action.Provider,
+			After: oauthConfigAuditValues(&entity.OAuthConfig{
+				DisplayName:       action.DisplayName,
+				Status:            action.Status,
+				ClientID:          action.ClientID,
+				ClientSecret:      action.ClientSecret,
+				AuthorizeURL:      action.AuthorizeURL,
+				TokenURL:          action.TokenURL,
+				ProfileURL:        action.ProfileURL,
+				Scope:             action.Scope,
+				IsTrusted:         action.IsTrusted,
+				JSONUserIDPath:    action.JSONUserIDPath,
+				JSONUserNamePath:  action.JSONUserNamePath,
+				JSONUserEmailPath: action.JSONUserEmailPath,
+			}),
+		}
+		if action.Current != nil && action.Current.ID > 0 {
+			auditLog.Before = oauthConfigAuditValues(action.Current)
+			auditLog.After["clientSecretChanged"] = action.Current.ClientSecret != action.ClientSecret
+		}
+		if err := webutil.AddAuditLog(c, auditLog); err != nil {
+			return c.Failure(err)
+		}
+
 		return c.Ok(web.Map{})
 	}
 }
+
+// auditSettings records a change to one of the site settings sections on the audit log
+func auditSettings(c *web.Context, section string, before, after entity.AuditValues) error {
+	return webutil.AddAuditLog(c, &cmd.AddAuditLog{
+		Action:     enum.AuditSettingsUpdated,
+		TargetType: "settings",
+		TargetID:   section,
+		Before:     before,
+		After:      after,
+	})
+}
+
+// oauthConfigAuditValues returns the audited fields of an OAuth config, client secret is never recorded
+func oauthConfigAuditValues(config *entity.OAuthConfig) entity.AuditValues {
+	return entity.AuditValues{
+		"displayName":       config.DisplayName,
+		"status":            config.Status,
+		"clientID":          config.ClientID,
+		"authorizeURL":      config.AuthorizeURL,
+		"tokenURL":          config.TokenURL,
+		"profileURL":        config.ProfileURL,
+		"scope":             config.Scope,
+		"isTrusted":         config.IsTrusted,
+		"jsonUserIDPath":    config.JSONUserIDPath,
+		"jsonUserNamePath":  config.JSONUserNamePath,
+		"jsonUserEmailPath": config.JSONUserEmailPath,
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
 
 	"github.com/getfider/fider/app/models/query"
 	. "github.com/getfider/fider/app/pkg/assert"
@@ -156,12 +158,89 @@ func TestUpdatePrivacyHandler(t *testing.T) {
 	Expect(updateCmd.IsPrivate).IsTrue()
 }
 
+func TestUpdatePrivacyHandler_AuditLog(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.UpdateTenantPrivacySettings) error {
+		return nil
+	})
+
+	env.Config.HTTP.TrustedProxies = "192.0.2.1"
+	server := mock.NewServer()
+	mock.DemoTenant.IsPrivate = false
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddHeader("X-Forwarded-For", "203.0.113.7").
+		ExecutePost(
+			handlers.UpdatePrivacy(),
+			`{ "isPrivate": true }`,
+		)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(auditLog.Action).Equals(enum.AuditSettingsUpdated)
+	Expect(auditLog.TargetType).Equals("settings")
+	Expect(auditLog.TargetID).Equals("privacy")
+	Expect(auditLog.Before).Equals(entity.AuditValues{"isPrivate": false})
+	Expect(auditLog.After).Equals(entity.AuditValues{"isPrivate": true})
+	Expect(auditLog.ClientIP).Equals("203.0.113.7")
+}
+
+func TestUpdateEmailDomainsHandler(t *testing.T) {
+	RegisterT(t)
+
+	var updateCmd *cmd.UpdateTenantEmailDomainSettings
+	bus.AddHandler(func(ctx context.Context, c *cmd.UpdateTenantEmailDomainSettings) error {
+		updateCmd = c
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(
+			handlers.UpdateEmailDomains(),
+			`{ "allowedEmailDomains": ["@GoT.com", "nightswatch.org"], "autoJoinRole": "collaborator" }`,
+		)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(updateCmd.AllowedEmailDomains).Equals([]string{"got.com", "nightswatch.org"})
+	Expect(updateCmd.AutoJoinRole).Equals(enum.RoleCollaborator)
+}
+
+func TestUpdateEmailDomainsHandler_NonAdministrator(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(
+			handlers.UpdateEmailDomains(),
+			`{ "allowedEmailDomains": ["got.com"], "autoJoinRole": "visitor" }`,
+		)
+
+	Expect(code).Equals(http.StatusForbidden)
+	ExpectHandler(&cmd.UpdateTenantEmailDomainSettings{}).CalledTimes(0)
+}
+
 func TestManageMembersHandler(t *testing.T) {
 	RegisterT(t)
 
 	bus.AddHandler(func(ctx context.Context, q *query.GetAllUsers) error {
 		return nil
 	})
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveUserSuspensions) error {
+		return nil
+	})
 
 	server := mock.NewServer()
 	code, _ := server.
@@ -172,3 +251,24 @@ func TestManageMembersHandler(t *testing.T) {
 
 	Expect(code).Equals(http.StatusOK)
 }
+
+func TestManageAuditLogHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.SearchAuditLogs) error {
+		q.Result = []*entity.AuditLog{
+			{ID: 1, Actor: mock.JonSnow, Action: enum.AuditTagDeleted, TargetType: "tag", TargetID: "2"},
+		}
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, page := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecuteAsPage(handlers.ManageAuditLog())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(page.Page).Equals("Administration/pages/AuditLog.page")
+	Expect(page.Data["logs"]).HasLen(1)
+}
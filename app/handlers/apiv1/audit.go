@@ -0,0 +1,68 @@
+package apiv1
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// SearchAuditLogs returns the audit log entries of current tenant that match given filters
+func SearchAuditLogs() web.HandlerFunc {
+	return func(c *web.Context) error {
+		actorID, err := c.QueryParamAsInt("actorId")
+		if err != nil {
+			return c.BadRequest(web.Map{})
+		}
+		limit, err := c.QueryParamAsInt("limit")
+		if err != nil {
+			return c.BadRequest(web.Map{})
+		}
+		offset, err := c.QueryParamAsInt("offset")
+		if err != nil {
+			return c.BadRequest(web.Map{})
+		}
+		since, err := parseAuditTime(c.QueryParam("since"))
+		if err != nil {
+			return c.BadRequest(web.Map{})
+		}
+		until, err := parseAuditTime(c.QueryParam("until"))
+		if err != nil {
+			return c.BadRequest(web.Map{})
+		}
+
+		searchAuditLogs := &query.SearchAuditLogs{
+			Action:     enum.AuditAction(c.QueryParam("action")),
+			ActorID:    actorID,
+			TargetType: c.QueryParam("targetType"),
+			TargetID:   c.QueryParam("targetId"),
+			Since:      since,
+			Until:      until,
+			Limit:      limit,
+			Offset:     offset,
+		}
+		if err := bus.Dispatch(c, searchAuditLogs); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(searchAuditLogs.Result)
+	}
+}
+
+// parseAuditTime accepts either a full RFC 3339 timestamp or a plain date
+func parseAuditTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
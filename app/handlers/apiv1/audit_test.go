@@ -0,0 +1,57 @@
+package apiv1_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/getfider/fider/app/handlers/apiv1"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestSearchAuditLogsHandler(t *testing.T) {
+	RegisterT(t)
+
+	var searchAuditLogs *query.SearchAuditLogs
+	bus.AddHandler(func(ctx context.Context, q *query.SearchAuditLogs) error {
+		searchAuditLogs = q
+		q.Result = []*entity.AuditLog{
+			{ID: 1, Action: enum.AuditUserBlocked, TargetType: "user", TargetID: "3"},
+		}
+		return nil
+	})
+
+	server := mock.NewServer()
+	status, query := server.
+		AsUser(mock.JonSnow).
+		WithURL("http://demo.test.fider.io/api/v1/audit-logs?action=user.blocked&actorId=1&targetType=user&since=2026-10-01&limit=10&offset=20").
+		ExecuteAsJSON(apiv1.SearchAuditLogs())
+
+	Expect(status).Equals(http.StatusOK)
+	Expect(query.ArrayLength()).Equals(1)
+	Expect(searchAuditLogs.Action).Equals(enum.AuditUserBlocked)
+	Expect(searchAuditLogs.ActorID).Equals(1)
+	Expect(searchAuditLogs.TargetType).Equals("user")
+	Expect(searchAuditLogs.Since.Format("2006-01-02")).Equals("2026-10-01")
+	Expect(searchAuditLogs.Until).IsNil()
+	Expect(searchAuditLogs.Limit).Equals(10)
+	Expect(searchAuditLogs.Offset).Equals(20)
+}
+
+func TestSearchAuditLogsHandler_InvalidFilter(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	status, _ := server.
+		AsUser(mock.JonSnow).
+		WithURL("http://demo.test.fider.io/api/v1/audit-logs?since=yesterday").
+		Execute(apiv1.SearchAuditLogs())
+
+	Expect(status).Equals(http.StatusBadRequest)
+	ExpectHandler(&query.SearchAuditLogs{}).CalledTimes(0)
+}
@@ -28,8 +28,10 @@ func SendSampleInvite() web.HandlerFunc {
 				"subject": action.Subject,
 				"message": markdown.Full(action.Message),
 			})
+			// the sample is rendered as invited users will get it
+			to.Locale = c.Tenant().Locale
 
-			bus.Publish(c, &cmd.SendMail{
+			err := bus.Dispatch(c, &cmd.SendMail{
 				From:         dto.Recipient{Name: c.Tenant().Name},
 				To:           []dto.Recipient{to},
 				TemplateName: "invite_email",
@@ -37,6 +39,9 @@ func SendSampleInvite() web.HandlerFunc {
 					"logo": web.LogoURL(c),
 				},
 			})
+			if err != nil {
+				return c.Failure(err)
+			}
 		}
 
 		return c.Ok(web.Map{})
@@ -0,0 +1,82 @@
+package apiv1
+
+import (
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// ListOrganizations returns all organizations
+func ListOrganizations() web.HandlerFunc {
+	return func(c *web.Context) error {
+		q := &query.GetAllOrganizations{}
+		if err := bus.Dispatch(c, q); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(q.Result)
+	}
+}
+
+// CreateEditOrganization creates a new organization or updates an existing one
+// Organizations with an external ID are updated in place when created again, which allows syncing them from other systems
+func CreateEditOrganization() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.CreateEditOrganization)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		saveOrganization := &cmd.SaveOrganization{
+			ID:         action.ID,
+			ExternalID: action.ExternalID,
+			Name:       action.Name,
+			Plan:       action.Plan,
+			Segment:    action.Segment,
+			MRR:        action.MRR,
+		}
+		if err := bus.Dispatch(c, saveOrganization); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(saveOrganization.Result)
+	}
+}
+
+// DeleteOrganization deletes an existing organization
+func DeleteOrganization() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.DeleteOrganization)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		if err := bus.Dispatch(c, &cmd.DeleteOrganization{Organization: action.Organization}); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// SetUserOrganization assigns a user to an organization
+func SetUserOrganization() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.SetUserOrganization)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := bus.Dispatch(c, &cmd.SetUserOrganization{
+			UserID:         action.UserID,
+			OrganizationID: action.OrganizationID,
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
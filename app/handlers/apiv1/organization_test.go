@@ -0,0 +1,141 @@
+package apiv1_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers/apiv1"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestCreateOrganizationHandler_ValidRequest(t *testing.T) {
+	RegisterT(t)
+
+	var saveOrganization *cmd.SaveOrganization
+	bus.AddHandler(func(ctx context.Context, c *cmd.SaveOrganization) error {
+		saveOrganization = c
+		c.Result = &entity.Organization{ID: 1, ExternalID: c.ExternalID, Name: c.Name, Plan: c.Plan, Segment: c.Segment, MRR: c.MRR}
+		return nil
+	})
+
+	server := mock.NewServer()
+	status, _ := server.
+		AsUser(mock.JonSnow).
+		ExecutePost(
+			apiv1.CreateEditOrganization(),
+			`{ "externalId": "crm-42", "name": "Winterfell Inc.", "plan": "enterprise", "segment": "north", "mrr": 1250.50 }`,
+		)
+
+	Expect(status).Equals(http.StatusOK)
+	Expect(saveOrganization.ID).Equals(0)
+	Expect(saveOrganization.ExternalID).Equals("crm-42")
+	Expect(saveOrganization.Name).Equals("Winterfell Inc.")
+	Expect(saveOrganization.Plan).Equals("enterprise")
+	Expect(saveOrganization.Segment).Equals("north")
+	Expect(saveOrganization.MRR).Equals(1250.50)
+}
+
+func TestCreateOrganizationHandler_InvalidRequests(t *testing.T) {
+	RegisterT(t)
+
+	var testCases = []string{
+		`{ }`,
+		`{ "name": "" }`,
+		`{ "name": "Winterfell Inc.", "mrr": -10 }`,
+		`{ "name": "Winterfell Inc.", "plan": "123456789012345678901234567890123456789012345678901" }`,
+	}
+
+	for _, testCase := range testCases {
+		server := mock.NewServer()
+		status, _ := server.
+			AsUser(mock.JonSnow).
+			ExecutePost(apiv1.CreateEditOrganization(), testCase)
+
+		Expect(status).Equals(http.StatusBadRequest)
+	}
+	ExpectHandler(&cmd.SaveOrganization{}).CalledTimes(0)
+}
+
+func TestCreateOrganizationHandler_Collaborator(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	status, _ := server.
+		AsUser(mock.AryaStark).
+		ExecutePost(apiv1.CreateEditOrganization(), `{ "name": "Winterfell Inc." }`)
+
+	Expect(status).Equals(http.StatusForbidden)
+}
+
+func TestEditInvalidOrganizationHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetOrganizationByID) error {
+		return app.ErrNotFound
+	})
+
+	server := mock.NewServer()
+	status, _ := server.
+		AsUser(mock.JonSnow).
+		AddParam("id", "99").
+		ExecutePost(apiv1.CreateEditOrganization(), `{ "name": "Winterfell Inc." }`)
+
+	Expect(status).Equals(http.StatusNotFound)
+}
+
+func TestSetUserOrganizationHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		q.Result = mock.AryaStark
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetOrganizationByID) error {
+		q.Result = &entity.Organization{ID: q.OrganizationID, Name: "Winterfell Inc."}
+		return nil
+	})
+
+	var setUserOrganization *cmd.SetUserOrganization
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetUserOrganization) error {
+		setUserOrganization = c
+		return nil
+	})
+
+	server := mock.NewServer()
+	status, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", mock.AryaStark.ID).
+		ExecutePost(apiv1.SetUserOrganization(), `{ "organizationId": 3 }`)
+
+	Expect(status).Equals(http.StatusOK)
+	Expect(setUserOrganization.UserID).Equals(mock.AryaStark.ID)
+	Expect(setUserOrganization.OrganizationID).Equals(3)
+}
+
+func TestSetUserOrganizationHandler_OtherTenant(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		q.Result = &entity.User{ID: 99, Tenant: mock.AvengersTenant}
+		return nil
+	})
+
+	server := mock.NewServer()
+	status, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", 99).
+		ExecutePost(apiv1.SetUserOrganization(), `{ "organizationId": 3 }`)
+
+	Expect(status).Equals(http.StatusNotFound)
+	ExpectHandler(&cmd.SetUserOrganization{}).CalledTimes(0)
+}
@@ -1,14 +1,23 @@
 package apiv1
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/getfider/fider/app/actions"
 	"github.com/getfider/fider/app/metrics"
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/models/entity"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/validate"
 	"github.com/getfider/fider/app/pkg/web"
+	webutil "github.com/getfider/fider/app/pkg/web/util"
 	"github.com/getfider/fider/app/tasks"
 )
 
@@ -25,6 +34,9 @@ func SearchPosts() web.HandlerFunc {
 			Limit: c.QueryParam("limit"),
 			Tags:  c.QueryParamAsArray("tags"),
 		}
+		// Invalid filters are ignored, just like an invalid limit is
+		searchPosts.MinOrganizations, _ = c.QueryParamAsInt("minOrganizations")
+		searchPosts.MinRevenue, _ = strconv.ParseFloat(c.QueryParam("minRevenue"), 64)
 		if err := bus.Dispatch(c, searchPosts); err != nil {
 			return c.Failure(err)
 		}
@@ -45,11 +57,17 @@ func CreatePost() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		pendingReview, err := isSpam(c, &query.CheckSpam{Kind: "post", Title: action.Title, Content: action.Description})
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		newPost := &cmd.AddNewPost{
-			Title:       action.Title,
-			Description: action.Description,
+			Title:         action.Title,
+			Description:   action.Description,
+			PendingReview: pendingReview,
 		}
-		err := bus.Dispatch(c, newPost)
+		err = bus.Dispatch(c, newPost)
 		if err != nil {
 			return c.Failure(err)
 		}
@@ -60,14 +78,17 @@ func CreatePost() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
-		c.Enqueue(tasks.NotifyAboutNewPost(newPost.Result))
+		if !pendingReview {
+			c.Enqueue(tasks.NotifyAboutNewPost(newPost.Result))
+		}
 
 		metrics.TotalPosts.Inc()
 		return c.Ok(web.Map{
-			"id":     newPost.Result.ID,
-			"number": newPost.Result.Number,
-			"title":  newPost.Result.Title,
-			"slug":   newPost.Result.Slug,
+			"id":            newPost.Result.ID,
+			"number":        newPost.Result.Number,
+			"title":         newPost.Result.Title,
+			"slug":          newPost.Result.Slug,
+			"pendingReview": pendingReview,
 		})
 	}
 }
@@ -97,7 +118,16 @@ func UpdatePost() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
-		err := bus.Dispatch(c,
+		// Edits are not held for review, as approving them would notify subscribers about a new post once more
+		spam, err := isSpam(c, &query.CheckSpam{Kind: "post", Title: action.Title, Content: action.Description, IsEdit: true})
+		if err != nil {
+			return c.Failure(err)
+		}
+		if spam {
+			return c.HandleValidation(validate.Failed(i18n.T(c, "validation.custom.spam")))
+		}
+
+		err = bus.Dispatch(c,
 			&cmd.UploadImages{
 				Images: action.Attachments,
 				Folder: "attachments",
@@ -150,6 +180,17 @@ func SetResponse() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		err := bus.Dispatch(c, &cmd.PublishRealtimeEvent{
+			Type: enum.RealtimeStatus,
+			Data: dto.Props{
+				"postNumber": getPost.Result.Number,
+				"status":     action.Status.Name(),
+			},
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		c.Enqueue(tasks.NotifyAboutStatusChange(getPost.Result, prevStatus))
 
 		return c.Ok(web.Map{})
@@ -173,6 +214,23 @@ func DeletePost() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditPostDeleted,
+			TargetType: "post",
+			TargetID:   strconv.Itoa(action.Post.Number),
+			Before: entity.AuditValues{
+				"title":  action.Post.Title,
+				"status": action.Post.Status,
+			},
+			After: entity.AuditValues{
+				"status": enum.PostDeleted,
+				"reason": action.Text,
+			},
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		if action.Text != "" {
 			// Only send notification if user wrote a comment.
 			c.Enqueue(tasks.NotifyAboutDeletedPost(action.Post))
@@ -238,9 +296,15 @@ func PostComment() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		pendingReview, err := isSpam(c, &query.CheckSpam{Kind: "comment", Content: action.Content})
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		addNewComment := &cmd.AddNewComment{
-			Post:    getPost.Result,
-			Content: action.Content,
+			Post:          getPost.Result,
+			Content:       action.Content,
+			PendingReview: pendingReview,
 		}
 		if err := bus.Dispatch(c, addNewComment); err != nil {
 			return c.Failure(err)
@@ -254,11 +318,25 @@ func PostComment() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
-		c.Enqueue(tasks.NotifyAboutNewComment(getPost.Result, action.Content))
+		if !pendingReview {
+			err := bus.Dispatch(c, &cmd.PublishRealtimeEvent{
+				Type: enum.RealtimeComment,
+				Data: dto.Props{
+					"postNumber": getPost.Result.Number,
+					"commentId":  addNewComment.Result.ID,
+				},
+			})
+			if err != nil {
+				return c.Failure(err)
+			}
+
+			c.Enqueue(tasks.NotifyAboutNewComment(getPost.Result, action.Content))
+		}
 
 		metrics.TotalComments.Inc()
 		return c.Ok(web.Map{
-			"id": addNewComment.Result.ID,
+			"id":            addNewComment.Result.ID,
+			"pendingReview": pendingReview,
 		})
 	}
 }
@@ -271,7 +349,15 @@ func UpdateComment() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
-		err := bus.Dispatch(c,
+		spam, err := isSpam(c, &query.CheckSpam{Kind: "comment", Content: action.Content, IsEdit: true})
+		if err != nil {
+			return c.Failure(err)
+		}
+		if spam {
+			return c.HandleValidation(validate.Failed(i18n.T(c, "validation.custom.spam")))
+		}
+
+		err = bus.Dispatch(c,
 			&cmd.UploadImages{
 				Images: action.Attachments,
 				Folder: "attachments",
@@ -290,6 +376,19 @@ func UpdateComment() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		if !action.Comment.PendingReview {
+			err = bus.Dispatch(c, &cmd.PublishRealtimeEvent{
+				Type: enum.RealtimeComment,
+				Data: dto.Props{
+					"postNumber": action.Post.Number,
+					"commentId":  action.ID,
+				},
+			})
+			if err != nil {
+				return c.Failure(err)
+			}
+		}
+
 		return c.Ok(web.Map{})
 	}
 }
@@ -302,9 +401,18 @@ func DeleteComment() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
-		err := bus.Dispatch(c, &cmd.DeleteComment{
-			CommentID: action.CommentID,
-		})
+		err := bus.Dispatch(c,
+			&cmd.DeleteComment{
+				CommentID: action.CommentID,
+			},
+			&cmd.PublishRealtimeEvent{
+				Type: enum.RealtimeComment,
+				Data: dto.Props{
+					"postNumber": action.PostNumber,
+					"commentId":  action.CommentID,
+				},
+			},
+		)
 		if err != nil {
 			return c.Failure(err)
 		}
@@ -313,6 +421,70 @@ func DeleteComment() web.HandlerFunc {
 	}
 }
 
+// ApprovePost publishes a post that was flagged as spam and notifies its subscribers
+func ApprovePost() web.HandlerFunc {
+	return func(c *web.Context) error {
+		number, err := c.ParamAsInt("number")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		getPost := &query.GetPostByNumber{Number: number}
+		if err := bus.Dispatch(c, getPost); err != nil {
+			return c.Failure(err)
+		}
+
+		if !getPost.Result.PendingReview {
+			return c.Ok(web.Map{})
+		}
+
+		if err := bus.Dispatch(c, &cmd.ApprovePost{Post: getPost.Result}); err != nil {
+			return c.Failure(err)
+		}
+
+		c.Enqueue(tasks.OnBehalfOf(getPost.Result.User, tasks.NotifyAboutNewPost(getPost.Result)))
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ApproveComment publishes a comment that was flagged as spam and notifies the post subscribers
+func ApproveComment() web.HandlerFunc {
+	return func(c *web.Context) error {
+		number, err := c.ParamAsInt("number")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		id, err := c.ParamAsInt("id")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		getPost := &query.GetPostByNumber{Number: number}
+		getComment := &query.GetCommentByID{CommentID: id}
+		if err := bus.Dispatch(c, getPost, getComment); err != nil {
+			return c.Failure(err)
+		}
+
+		if getComment.Result.PostID != getPost.Result.ID {
+			return c.NotFound()
+		}
+
+		if !getComment.Result.PendingReview {
+			return c.Ok(web.Map{})
+		}
+
+		if err := bus.Dispatch(c, &cmd.ApproveComment{CommentID: getComment.Result.ID}); err != nil {
+			return c.Failure(err)
+		}
+
+		c.Enqueue(tasks.OnBehalfOf(getComment.Result.User, tasks.NotifyAboutNewComment(getPost.Result, getComment.Result.Content)))
+
+		return c.Ok(web.Map{})
+	}
+}
+
 // AddVote adds current user to given post list of votes
 func AddVote() web.HandlerFunc {
 	return func(c *web.Context) error {
@@ -389,11 +561,48 @@ func addOrRemove(c *web.Context, getCommand func(post *entity.Post, user *entity
 		return c.Failure(err)
 	}
 
-	cmd := getCommand(getPost.Result, c.User())
-	err = bus.Dispatch(c, cmd)
+	command := getCommand(getPost.Result, c.User())
+	err = bus.Dispatch(c, command)
 	if err != nil {
 		return c.Failure(err)
 	}
 
+	switch command.(type) {
+	case *cmd.AddVote, *cmd.RemoveVote:
+		err = bus.Dispatch(c, &cmd.PublishRealtimeEvent{
+			Type: enum.RealtimeVote,
+			Data: dto.Props{"postNumber": getPost.Result.Number},
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+	}
+
 	return c.Ok(web.Map{})
 }
+
+// isSpam screens new or edited content of current user, which is always published when the spam filter is disabled
+func isSpam(c *web.Context, checkSpam *query.CheckSpam) (bool, error) {
+	if !env.Config.SpamFilter.Enabled {
+		return false, nil
+	}
+
+	if err := bus.Dispatch(c, checkSpam); err != nil {
+		return false, err
+	}
+
+	if checkSpam.Result.IsSpam {
+		action := "New"
+		if checkSpam.IsEdit {
+			action = "Edited"
+		}
+		log.Warnf(c, "@{Action} @{Kind} of user @{UserID} was flagged as spam: @{Reasons}", dto.Props{
+			"Action":  action,
+			"Kind":    checkSpam.Kind,
+			"UserID":  c.User().ID,
+			"Reasons": strings.Join(checkSpam.Result.Reasons, " "),
+		})
+	}
+
+	return checkSpam.Result.IsSpam, nil
+}
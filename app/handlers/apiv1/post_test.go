@@ -52,6 +52,44 @@ func TestCreatePostHandler(t *testing.T) {
 	Expect(newPost.Description).Equals("")
 }
 
+func TestCreatePostHandler_FlaggedAsSpam(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+
+	bus.AddHandler(func(ctx context.Context, q *query.CheckSpam) error {
+		Expect(q.Kind).Equals("post")
+		Expect(q.Title).Equals("Buy cheap watches")
+		q.Result.IsSpam = true
+		q.Result.Reasons = []string{"Contains blocked word 'cheap watches'."}
+		return nil
+	})
+
+	var newPost *cmd.AddNewPost
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewPost) error {
+		newPost = c
+		c.Result = &entity.Post{ID: 1, Number: 1, Title: c.Title, PendingReview: c.PendingReview}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostBySlug) error {
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetAttachments) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddVote) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.UploadImages) error { return nil })
+
+	code, json := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePostAsJSON(apiv1.CreatePost(), `{ "title": "Buy cheap watches" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(newPost.PendingReview).IsTrue()
+	Expect(json.Bool("pendingReview")).IsTrue()
+}
+
 func TestCreatePostHandler_WithoutTitle(t *testing.T) {
 	RegisterT(t)
 
@@ -311,7 +349,15 @@ func TestSetResponseHandler(t *testing.T) {
 		return nil
 	})
 
-	code, _ := mock.NewServer().
+	server := mock.NewServer()
+
+	var publishEvent *cmd.PublishRealtimeEvent
+	bus.AddHandler(func(ctx context.Context, c *cmd.PublishRealtimeEvent) error {
+		publishEvent = c
+		return nil
+	})
+
+	code, _ := server.
 		OnTenant(mock.DemoTenant).
 		AsUser(mock.JonSnow).
 		AddParam("number", post.Number).
@@ -321,6 +367,9 @@ func TestSetResponseHandler(t *testing.T) {
 	Expect(setResponse.Post).Equals(post)
 	Expect(setResponse.Status).Equals(enum.PostCompleted)
 	Expect(setResponse.Text).Equals("Done!")
+	Expect(publishEvent.Type).Equals(enum.RealtimeStatus)
+	Expect(publishEvent.Data["postNumber"]).Equals(post.Number)
+	Expect(publishEvent.Data["status"]).Equals("completed")
 }
 
 func TestSetResponseHandler_Unauthorized(t *testing.T) {
@@ -429,7 +478,15 @@ func TestAddVoteHandler(t *testing.T) {
 		return nil
 	})
 
-	code, _ := mock.NewServer().
+	server := mock.NewServer()
+
+	var publishEvent *cmd.PublishRealtimeEvent
+	bus.AddHandler(func(ctx context.Context, c *cmd.PublishRealtimeEvent) error {
+		publishEvent = c
+		return nil
+	})
+
+	code, _ := server.
 		OnTenant(mock.DemoTenant).
 		AsUser(mock.AryaStark).
 		AddParam("number", post.Number).
@@ -438,6 +495,9 @@ func TestAddVoteHandler(t *testing.T) {
 	Expect(code).Equals(http.StatusOK)
 	Expect(addVote.Post).Equals(post)
 	Expect(addVote.User).Equals(mock.AryaStark)
+	Expect(publishEvent.UserID).Equals(0)
+	Expect(publishEvent.Type).Equals(enum.RealtimeVote)
+	Expect(publishEvent.Data["postNumber"]).Equals(post.Number)
 }
 
 func TestAddVoteHandler_InvalidPost(t *testing.T) {
@@ -544,6 +604,150 @@ func TestPostCommentHandler(t *testing.T) {
 	Expect(newComment.Content).Equals("This is a comment!")
 }
 
+func TestPostCommentHandler_FlaggedAsSpam(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+
+	bus.AddHandler(func(ctx context.Context, q *query.CheckSpam) error {
+		Expect(q.Kind).Equals("comment")
+		Expect(q.Content).Equals("Visit http://spam.example.com")
+		q.Result.IsSpam = true
+		q.Result.Reasons = []string{"Flagged by spam checker."}
+		return nil
+	})
+
+	post := &entity.Post{ID: 1, Number: 1, Title: "The Post #1", Description: "The Description #1"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	var newComment *cmd.AddNewComment
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewComment) error {
+		newComment = c
+		c.Result = &entity.Comment{ID: 1, Content: c.Content, PendingReview: c.PendingReview}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetAttachments) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.UploadImages) error { return nil })
+
+	code, json := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("number", post.Number).
+		ExecutePostAsJSON(apiv1.PostComment(), `{ "content": "Visit http://spam.example.com" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(newComment.PendingReview).IsTrue()
+	Expect(json.Bool("pendingReview")).IsTrue()
+}
+
+func TestApprovePostHandler(t *testing.T) {
+	RegisterT(t)
+
+	post := &entity.Post{ID: 1, Number: 1, Title: "The Post #1", PendingReview: true}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	var approved *cmd.ApprovePost
+	bus.AddHandler(func(ctx context.Context, c *cmd.ApprovePost) error {
+		approved = c
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("number", post.Number).
+		ExecutePost(apiv1.ApprovePost(), `{}`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(approved.Post).Equals(post)
+}
+
+func TestApprovePostHandler_AlreadyPublished(t *testing.T) {
+	RegisterT(t)
+
+	post := &entity.Post{ID: 1, Number: 1, Title: "The Post #1"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+	bus.AddHandler(func(ctx context.Context, c *cmd.ApprovePost) error { return nil })
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("number", post.Number).
+		ExecutePost(apiv1.ApprovePost(), `{}`)
+
+	Expect(code).Equals(http.StatusOK)
+	ExpectHandler(&cmd.ApprovePost{}).CalledTimes(0)
+}
+
+func TestApproveCommentHandler(t *testing.T) {
+	RegisterT(t)
+
+	post := &entity.Post{ID: 1, Number: 1, Title: "The Post #1"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	comment := &entity.Comment{ID: 5, PostID: 1, Content: "Visit http://spam.example.com", PendingReview: true}
+	bus.AddHandler(func(ctx context.Context, q *query.GetCommentByID) error {
+		q.Result = comment
+		return nil
+	})
+
+	var approved *cmd.ApproveComment
+	bus.AddHandler(func(ctx context.Context, c *cmd.ApproveComment) error {
+		approved = c
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("number", post.Number).
+		AddParam("id", comment.ID).
+		ExecutePost(apiv1.ApproveComment(), `{}`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(approved.CommentID).Equals(comment.ID)
+}
+
+func TestApproveCommentHandler_CommentOfAnotherPost(t *testing.T) {
+	RegisterT(t)
+
+	post := &entity.Post{ID: 1, Number: 1, Title: "The Post #1"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	comment := &entity.Comment{ID: 5, PostID: 2, Content: "Visit http://spam.example.com", PendingReview: true}
+	bus.AddHandler(func(ctx context.Context, q *query.GetCommentByID) error {
+		q.Result = comment
+		return nil
+	})
+	bus.AddHandler(func(ctx context.Context, c *cmd.ApproveComment) error { return nil })
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("number", post.Number).
+		AddParam("id", comment.ID).
+		ExecutePost(apiv1.ApproveComment(), `{}`)
+
+	Expect(code).Equals(http.StatusNotFound)
+	ExpectHandler(&cmd.ApproveComment{}).CalledTimes(0)
+}
+
 func TestPostCommentHandler_WithoutContent(t *testing.T) {
 	RegisterT(t)
 
@@ -600,6 +804,45 @@ func TestUpdateCommentHandler_Authorized(t *testing.T) {
 	Expect(updateComment.Content).Equals("My first comment has been edited")
 }
 
+func TestUpdateCommentHandler_FlaggedAsSpam(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+
+	bus.AddHandler(func(ctx context.Context, q *query.CheckSpam) error {
+		Expect(q.Kind).Equals("comment")
+		Expect(q.IsEdit).IsTrue()
+		q.Result.IsSpam = true
+		q.Result.Reasons = []string{"Contains blocked word 'casino'."}
+		return nil
+	})
+
+	post := &entity.Post{ID: 1, Number: 1, Title: "The Post #1", Description: "The Description #1"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	comment := &entity.Comment{ID: 5, PostID: 1, Content: "Old comment text", User: mock.AryaStark}
+	bus.AddHandler(func(ctx context.Context, q *query.GetCommentByID) error {
+		q.Result = comment
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetAttachments) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.UpdateComment) error { return nil })
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("number", post.Number).
+		AddParam("id", comment.ID).
+		ExecutePost(apiv1.UpdateComment(), `{ "content": "Visit my casino" }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	ExpectHandler(&cmd.UpdateComment{}).CalledTimes(0)
+}
+
 func TestUpdateCommentHandler_Unauthorized(t *testing.T) {
 	RegisterT(t)
 
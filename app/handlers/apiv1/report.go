@@ -0,0 +1,127 @@
+package apiv1
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/web"
+	webutil "github.com/getfider/fider/app/pkg/web/util"
+	"github.com/getfider/fider/app/tasks"
+)
+
+// ReportContent reports a post or comment to the moderators
+func ReportContent() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.ReportContent)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := bus.Dispatch(c, &cmd.AddContentReport{
+			Post:      action.Post,
+			CommentID: action.CommentID,
+			Reason:    enum.ReportReason(action.Reason),
+			Details:   action.Details,
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ListContentReports returns all posts and comments with open reports
+func ListContentReports() web.HandlerFunc {
+	return func(c *web.Context) error {
+		getReports := &query.GetOpenContentReports{}
+		if err := bus.Dispatch(c, getReports); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(getReports.Result)
+	}
+}
+
+// ResolveContentReports closes the open reports of a post or comment and applies the moderator decision
+func ResolveContentReports() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.ResolveContentReports)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		resolution := enum.ReportResolution(action.Resolution)
+		if err := applyReportResolution(c, action, resolution); err != nil {
+			return c.Failure(err)
+		}
+
+		resolve := &cmd.ResolveContentReports{
+			PostID:     action.Post.ID,
+			CommentID:  action.CommentID,
+			Resolution: resolution,
+		}
+		if err := bus.Dispatch(c, resolve); err != nil {
+			return c.Failure(err)
+		}
+
+		targetType, targetID := "post", strconv.Itoa(action.Post.Number)
+		if action.Comment != nil {
+			targetType, targetID = "comment", fmt.Sprintf("%d#%d", action.Post.Number, action.Comment.ID)
+		}
+
+		err := webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditContentReportsResolved,
+			TargetType: targetType,
+			TargetID:   targetID,
+			Before:     entity.AuditValues{"reports": len(resolve.Result)},
+			After:      entity.AuditValues{"resolution": resolution},
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		if len(resolve.Result) > 0 {
+			c.Enqueue(tasks.NotifyReportersAboutResolution(resolve.Result, action.Post, action.Comment != nil, resolution))
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+func applyReportResolution(c *web.Context, action *actions.ResolveContentReports, resolution enum.ReportResolution) error {
+	switch resolution {
+	case enum.ReportHidden:
+		if action.Comment != nil {
+			return bus.Dispatch(c, &cmd.HideComment{CommentID: action.Comment.ID})
+		}
+		return bus.Dispatch(c, &cmd.HidePost{Post: action.Post})
+	case enum.ReportDeleted:
+		if action.Comment != nil {
+			return bus.Dispatch(c, &cmd.DeleteComment{CommentID: action.Comment.ID})
+		}
+		return bus.Dispatch(c, &cmd.SetPostResponse{
+			Post:   action.Post,
+			Status: enum.PostDeleted,
+		})
+	case enum.ReportAuthorBlocked:
+		author := action.Author()
+		if err := bus.Dispatch(c, &cmd.BlockUser{UserID: author.ID}); err != nil {
+			return err
+		}
+		return webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditUserBlocked,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(author.ID),
+			Before:     entity.AuditValues{"status": author.Status},
+			After:      entity.AuditValues{"status": enum.UserBlocked},
+		})
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package apiv1_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/getfider/fider/app/handlers/apiv1"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestReportContentHandler(t *testing.T) {
+	RegisterT(t)
+
+	post := &entity.Post{ID: 1, Number: 1, Title: "Buy cheap stuff", User: mock.JonSnow}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	var report *cmd.AddContentReport
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddContentReport) error {
+		report = c
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("number", post.Number).
+		ExecutePost(apiv1.ReportContent(), `{ "reason": "spam", "details": "Advertising" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(report.Post).Equals(post)
+	Expect(report.CommentID).Equals(0)
+	Expect(report.Reason).Equals(enum.ReportSpam)
+	Expect(report.Details).Equals("Advertising")
+}
+
+func TestReportContentHandler_InvalidReason(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = &entity.Post{ID: 1, Number: 1}
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("number", 1).
+		ExecutePost(apiv1.ReportContent(), `{ "reason": "boring" }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
+
+func TestResolveContentReportsHandler_HideComment(t *testing.T) {
+	RegisterT(t)
+
+	post := &entity.Post{ID: 1, Number: 1, Title: "The Post #1", User: mock.JonSnow}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetCommentByID) error {
+		q.Result = &entity.Comment{ID: q.CommentID, PostID: 1, Content: "You are all idiots", User: mock.AryaStark}
+		return nil
+	})
+
+	var hidden *cmd.HideComment
+	bus.AddHandler(func(ctx context.Context, c *cmd.HideComment) error {
+		hidden = c
+		return nil
+	})
+
+	var resolved *cmd.ResolveContentReports
+	bus.AddHandler(func(ctx context.Context, c *cmd.ResolveContentReports) error {
+		resolved = c
+		c.Result = []*entity.User{mock.JonSnow}
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(apiv1.ResolveContentReports(), `{ "postNumber": 1, "commentId": 5, "resolution": "hidden" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(hidden.CommentID).Equals(5)
+	Expect(resolved.PostID).Equals(post.ID)
+	Expect(resolved.CommentID).Equals(5)
+	Expect(resolved.Resolution).Equals(enum.ReportHidden)
+}
+
+func TestResolveContentReportsHandler_CommentOfAnotherPost(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = &entity.Post{ID: 1, Number: 1, Title: "The Post #1", User: mock.JonSnow}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetCommentByID) error {
+		q.Result = &entity.Comment{ID: q.CommentID, PostID: 2, Content: "Nice!", User: mock.JonSnow}
+		return nil
+	})
+
+	var hidden *cmd.HideComment
+	bus.AddHandler(func(ctx context.Context, c *cmd.HideComment) error {
+		hidden = c
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(apiv1.ResolveContentReports(), `{ "postNumber": 1, "commentId": 5, "resolution": "hidden" }`)
+
+	Expect(code).Equals(http.StatusNotFound)
+	Expect(hidden).IsNil()
+}
+
+func TestResolveContentReportsHandler_BlockAuthor(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = &entity.Post{ID: 1, Number: 1, Title: "Buy cheap stuff", User: mock.AryaStark}
+		return nil
+	})
+
+	var blocked *cmd.BlockUser
+	bus.AddHandler(func(ctx context.Context, c *cmd.BlockUser) error {
+		blocked = c
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.ResolveContentReports) error {
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(apiv1.ResolveContentReports(), `{ "postNumber": 1, "resolution": "blocked" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(blocked.UserID).Equals(mock.AryaStark.ID)
+}
+
+func TestResolveContentReportsHandler_VisitorIsForbidden(t *testing.T) {
+	RegisterT(t)
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(apiv1.ResolveContentReports(), `{ "postNumber": 1, "resolution": "dismissed" }`)
+
+	Expect(code).Equals(http.StatusForbidden)
+}
@@ -1,11 +1,18 @@
 package apiv1
 
 import (
+	"slices"
+	"strconv"
+
 	"github.com/getfider/fider/app/actions"
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/web"
+	webutil "github.com/getfider/fider/app/pkg/web/util"
+	"github.com/getfider/fider/app/tasks"
 )
 
 // ListTags returns all tags
@@ -28,11 +35,17 @@ func AssignTag() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
+		alreadyAssigned := slices.Contains(action.Post.Tags, action.Tag.Slug)
 		err := bus.Dispatch(c, &cmd.AssignTag{Tag: action.Tag, Post: action.Post})
 		if err != nil {
 			return c.Failure(err)
 		}
 
+		// Saved searches that include this tag might match the post now
+		if !alreadyAssigned && !action.Post.PendingReview && !action.Post.IsHidden {
+			c.Enqueue(tasks.OnBehalfOf(action.Post.User, tasks.NotifyAboutTaggedPost(action.Post, action.Tag)))
+		}
+
 		return c.Ok(web.Map{})
 	}
 }
@@ -72,6 +85,17 @@ func CreateEditTag() web.HandlerFunc {
 			if err := bus.Dispatch(c, updateTag); err != nil {
 				return c.Failure(err)
 			}
+
+			err := webutil.AddAuditLog(c, &cmd.AddAuditLog{
+				Action:     enum.AuditTagUpdated,
+				TargetType: "tag",
+				TargetID:   strconv.Itoa(action.Tag.ID),
+				Before:     tagAuditValues(action.Tag),
+				After:      tagAuditValues(updateTag.Result),
+			})
+			if err != nil {
+				return c.Failure(err)
+			}
 			return c.Ok(updateTag.Result)
 		}
 
@@ -83,6 +107,16 @@ func CreateEditTag() web.HandlerFunc {
 		if err := bus.Dispatch(c, addNewTag); err != nil {
 			return c.Failure(err)
 		}
+
+		err := webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditTagCreated,
+			TargetType: "tag",
+			TargetID:   strconv.Itoa(addNewTag.Result.ID),
+			After:      tagAuditValues(addNewTag.Result),
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
 		return c.Ok(addNewTag.Result)
 	}
 }
@@ -100,6 +134,25 @@ func DeleteTag() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditTagDeleted,
+			TargetType: "tag",
+			TargetID:   strconv.Itoa(action.Tag.ID),
+			Before:     tagAuditValues(action.Tag),
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		return c.Ok(web.Map{})
 	}
 }
+
+func tagAuditValues(tag *entity.Tag) entity.AuditValues {
+	return entity.AuditValues{
+		"name":     tag.Name,
+		"slug":     tag.Slug,
+		"color":    tag.Color,
+		"isPublic": tag.IsPublic,
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/getfider/fider/app/handlers/apiv1"
 	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/bus"
@@ -26,6 +27,7 @@ func TestCreateTagHandler_ValidRequests(t *testing.T) {
 	var addNewTag *cmd.AddNewTag
 	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewTag) error {
 		addNewTag = c
+		c.Result = &entity.Tag{ID: 1, Name: c.Name, Slug: "feature-request", Color: c.Color, IsPublic: c.IsPublic}
 		return nil
 	})
 
@@ -136,11 +138,18 @@ func TestEditExistingTagHandler(t *testing.T) {
 	var updateTag *cmd.UpdateTag
 	bus.AddHandler(func(ctx context.Context, c *cmd.UpdateTag) error {
 		updateTag = c
+		c.Result = &entity.Tag{ID: c.TagID, Name: c.Name, Slug: "feature-request", Color: c.Color, IsPublic: c.IsPublic}
 		return nil
 	})
 
 	server := mock.NewServer()
 
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
 	status, _ := server.
 		AsUser(mock.JonSnow).
 		AddParam("slug", "bug").
@@ -154,6 +163,11 @@ func TestEditExistingTagHandler(t *testing.T) {
 	Expect(updateTag.Name).Equals("Feature Request")
 	Expect(updateTag.Color).Equals("000000")
 	Expect(updateTag.IsPublic).IsTrue()
+
+	Expect(auditLog.Action).Equals(enum.AuditTagUpdated)
+	Expect(auditLog.TargetID).Equals("5")
+	Expect(auditLog.Before["name"]).Equals("Bug")
+	Expect(auditLog.After["name"]).Equals("Feature Request")
 }
 
 func TestDeleteInvalidTagHandler(t *testing.T) {
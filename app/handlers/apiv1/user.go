@@ -1,6 +1,8 @@
 package apiv1
 
 import (
+	"strconv"
+
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/actions"
 	"github.com/getfider/fider/app/models/cmd"
@@ -10,6 +12,8 @@ import (
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/errors"
 	"github.com/getfider/fider/app/pkg/web"
+	webutil "github.com/getfider/fider/app/pkg/web/util"
+	"github.com/getfider/fider/app/tasks"
 )
 
 // ListUsers returns all registered users
@@ -23,6 +27,41 @@ func ListUsers() web.HandlerFunc {
 	}
 }
 
+// GetUser returns the public profile of given user along with their recent activity
+func GetUser() web.HandlerFunc {
+	return func(c *web.Context) error {
+		userID, err := c.ParamAsInt("userID")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		getProfile := &query.GetUserProfile{UserID: userID}
+		if err := bus.Dispatch(c, getProfile); err != nil {
+			return c.Failure(err)
+		}
+
+		if !getProfile.Result.IsVisibleTo(c.User()) {
+			return c.NotFound()
+		}
+
+		limit, _ := c.QueryParamAsInt("limit")
+		getActivity := &query.GetUserActivity{UserID: userID, Limit: limit}
+		if err := bus.Dispatch(c, getActivity); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{
+			"user":     getProfile.Result.User,
+			"bio":      getProfile.Result.Bio,
+			"link":     getProfile.Result.Link,
+			"isHidden": getProfile.Result.IsHidden,
+			"posts":    getActivity.Result.Posts,
+			"comments": getActivity.Result.Comments,
+			"votes":    getActivity.Result.Votes,
+		})
+	}
+}
+
 // CreateUser is used to create new users
 func CreateUser() web.HandlerFunc {
 	return func(c *web.Context) error {
@@ -73,3 +112,109 @@ func CreateUser() web.HandlerFunc {
 		})
 	}
 }
+
+// ImportUsers creates or updates users in bulk from a CSV file
+// On a dry run, rows are only validated and returned so that they can be reviewed before importing
+func ImportUsers() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.ImportUsers)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		valid := 0
+		for _, row := range action.Rows {
+			if row.IsValid() {
+				valid++
+			}
+		}
+
+		if action.DryRun {
+			return c.Ok(web.Map{
+				"total":   len(action.Rows),
+				"valid":   valid,
+				"invalid": len(action.Rows) - valid,
+				"rows":    action.Rows,
+			})
+		}
+
+		createImport := &cmd.CreateUserImport{Total: len(action.Rows)}
+		if err := bus.Dispatch(c, createImport); err != nil {
+			return c.Failure(err)
+		}
+
+		err := webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditUsersImported,
+			TargetType: "user_import",
+			TargetID:   strconv.Itoa(createImport.Result.ID),
+			After: entity.AuditValues{
+				"total":       len(action.Rows),
+				"valid":       valid,
+				"sendInvites": action.SendInvites,
+			},
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		c.Enqueue(tasks.ImportUsers(createImport.Result, action))
+
+		return c.Ok(createImport.Result)
+	}
+}
+
+// GetUserImport returns the progress of given user import
+func GetUserImport() web.HandlerFunc {
+	return func(c *web.Context) error {
+		importID, err := c.ParamAsInt("id")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		getImport := &query.GetUserImportByID{ImportID: importID}
+		if err := bus.Dispatch(c, getImport); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(getImport.Result)
+	}
+}
+
+// MergeUsers moves everything owned by a user into another one and then deletes it
+func MergeUsers() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.MergeUsers)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := bus.Dispatch(c, &cmd.MergeUsers{
+			SourceUserID: action.Source.ID,
+			TargetUserID: action.Target.ID,
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditUserMerged,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(action.Source.ID),
+			Before: entity.AuditValues{
+				"role":   action.Source.Role,
+				"status": action.Source.Status,
+			},
+			After: entity.AuditValues{
+				"status":       enum.UserDeleted,
+				"targetUserId": action.Target.ID,
+			},
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{
+			"id": action.Target.ID,
+		})
+	}
+}
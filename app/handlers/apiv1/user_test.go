@@ -39,6 +39,57 @@ func TestListUsersHandler(t *testing.T) {
 	Expect(query.ArrayLength()).Equals(2)
 }
 
+func TestGetUserHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserProfile) error {
+		q.Result = &entity.UserProfile{User: mock.AryaStark, Bio: "A girl has no name", Link: "https://braavos.com"}
+		return nil
+	})
+
+	var getActivity *query.GetUserActivity
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserActivity) error {
+		getActivity = q
+		q.Result = &entity.UserActivity{
+			Posts:    []*entity.Post{{ID: 1, Number: 1, Title: "Add dark mode"}},
+			Comments: []*entity.UserComment{},
+			Votes:    []*entity.Post{},
+		}
+		return nil
+	})
+
+	status, query := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AddParam("userID", mock.AryaStark.ID).
+		WithURL("http://demo.test.fider.io/api/v1/users/2?limit=10").
+		ExecuteAsJSON(apiv1.GetUser())
+
+	Expect(status).Equals(http.StatusOK)
+	Expect(getActivity.Limit).Equals(10)
+	Expect(query.Int32("user.id")).Equals(mock.AryaStark.ID)
+	Expect(query.String("bio")).Equals("A girl has no name")
+	Expect(query.String("link")).Equals("https://braavos.com")
+	Expect(query.String("posts[0].title")).Equals("Add dark mode")
+	Expect(query.Contains("votes")).IsTrue()
+}
+
+func TestGetUserHandler_HiddenProfile(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserProfile) error {
+		q.Result = &entity.UserProfile{User: mock.JonSnow, IsHidden: true}
+		return nil
+	})
+
+	status, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("userID", mock.JonSnow.ID).
+		ExecuteAsJSON(apiv1.GetUser())
+
+	Expect(status).Equals(http.StatusNotFound)
+}
+
 func TestCreateUser_ExistingEmail(t *testing.T) {
 	RegisterT(t)
 
@@ -186,3 +237,185 @@ func TestCreateUser_NewUser(t *testing.T) {
 	theOtherUserID := query.Int32("id")
 	Expect(theOtherUserID).Equals(userID)
 }
+
+func TestMergeUsersHandler(t *testing.T) {
+	RegisterT(t)
+
+	aryaWorkAccount := &entity.User{ID: 3, Name: "Arya Stark", Tenant: mock.DemoTenant, Role: enum.RoleVisitor, Status: enum.UserActive}
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		if q.UserID == aryaWorkAccount.ID {
+			q.Result = aryaWorkAccount
+			return nil
+		}
+		q.Result = mock.AryaStark
+		return nil
+	})
+
+	var mergeUsers *cmd.MergeUsers
+	bus.AddHandler(func(ctx context.Context, c *cmd.MergeUsers) error {
+		mergeUsers = c
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	status, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", aryaWorkAccount.ID).
+		ExecutePostAsJSON(apiv1.MergeUsers(), `{ "targetUserId": 2 }`)
+
+	Expect(status).Equals(http.StatusOK)
+	Expect(query.Int32("id")).Equals(mock.AryaStark.ID)
+	Expect(mergeUsers.SourceUserID).Equals(aryaWorkAccount.ID)
+	Expect(mergeUsers.TargetUserID).Equals(mock.AryaStark.ID)
+	Expect(auditLog.Action).Equals(enum.AuditUserMerged)
+	Expect(auditLog.TargetID).Equals("3")
+	Expect(auditLog.After["targetUserId"]).Equals(mock.AryaStark.ID)
+}
+
+func TestMergeUsersHandler_InvalidRequests(t *testing.T) {
+	RegisterT(t)
+
+	deleted := &entity.User{ID: 4, Tenant: mock.DemoTenant, Role: enum.RoleVisitor, Status: enum.UserDeleted}
+	otherTenant := &entity.User{ID: 5, Tenant: mock.AvengersTenant, Role: enum.RoleVisitor, Status: enum.UserActive}
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		for _, user := range []*entity.User{mock.JonSnow, mock.AryaStark, deleted, otherTenant} {
+			if user.ID == q.UserID {
+				q.Result = user
+				return nil
+			}
+		}
+		return app.ErrNotFound
+	})
+
+	var testCases = []struct {
+		sourceUserID int
+		body         string
+		status       int
+	}{
+		{mock.AryaStark.ID, `{ }`, http.StatusBadRequest},
+		{mock.AryaStark.ID, `{ "targetUserId": 2 }`, http.StatusBadRequest},
+		{mock.AryaStark.ID, `{ "targetUserId": 4 }`, http.StatusBadRequest},
+		{mock.AryaStark.ID, `{ "targetUserId": 5 }`, http.StatusNotFound},
+		{mock.AryaStark.ID, `{ "targetUserId": 99 }`, http.StatusNotFound},
+		{mock.JonSnow.ID, `{ "targetUserId": 2 }`, http.StatusForbidden},
+	}
+
+	for _, testCase := range testCases {
+		server := mock.NewServer()
+		status, _ := server.
+			OnTenant(mock.DemoTenant).
+			AsUser(mock.JonSnow).
+			AddParam("userID", testCase.sourceUserID).
+			ExecutePostAsJSON(apiv1.MergeUsers(), testCase.body)
+
+		Expect(status).Equals(testCase.status)
+	}
+	ExpectHandler(&cmd.MergeUsers{}).CalledTimes(0)
+}
+
+func TestImportUsersHandler_DryRun(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	status, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePostAsJSON(apiv1.ImportUsers(), `{
+			"dryRun": true,
+			"csv": "name,email\nJon Snow,jon.snow@got.com\nArya Stark,invalid\n"
+		}`)
+
+	Expect(status).Equals(http.StatusOK)
+	Expect(query.Int32("total")).Equals(2)
+	Expect(query.Int32("valid")).Equals(1)
+	Expect(query.Int32("invalid")).Equals(1)
+	Expect(query.String("rows[1].name")).Equals("Arya Stark")
+	Expect(query.Int32("rows[1].row")).Equals(3)
+	Expect(query.Contains("rows[1].errors")).IsTrue()
+	ExpectHandler(&cmd.CreateUserImport{}).CalledTimes(0)
+}
+
+func TestImportUsersHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.CreateUserImport) error {
+		c.Result = &entity.UserImport{ID: 7, Status: enum.UserImportRunning, Total: c.Total}
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	status, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePostAsJSON(apiv1.ImportUsers(), `{
+			"csv": "name,email\nJon Snow,jon.snow@got.com\nArya Stark,invalid\n"
+		}`)
+
+	Expect(status).Equals(http.StatusOK)
+	Expect(query.Int32("id")).Equals(7)
+	Expect(query.String("status")).Equals("running")
+	Expect(query.Int32("total")).Equals(2)
+	Expect(auditLog.Action).Equals(enum.AuditUsersImported)
+	Expect(auditLog.TargetID).Equals("7")
+	Expect(auditLog.After["valid"]).Equals(1)
+}
+
+func TestImportUsersHandler_InvalidFile(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	status, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePostAsJSON(apiv1.ImportUsers(), `{ "csv": "email\njon.snow@got.com\n" }`)
+
+	Expect(status).Equals(http.StatusBadRequest)
+	ExpectHandler(&cmd.CreateUserImport{}).CalledTimes(0)
+}
+
+func TestGetUserImportHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserImportByID) error {
+		if q.ImportID == 7 {
+			q.Result = &entity.UserImport{ID: 7, Status: enum.UserImportCompleted, Total: 2, Processed: 2, Created: 1, Failed: 1}
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	server := mock.NewServer()
+	status, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", 7).
+		ExecuteAsJSON(apiv1.GetUserImport())
+
+	Expect(status).Equals(http.StatusOK)
+	Expect(query.String("status")).Equals("completed")
+	Expect(query.Int32("created")).Equals(1)
+	Expect(query.Int32("failed")).Equals(1)
+
+	status, _ = mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", 8).
+		ExecuteAsJSON(apiv1.GetUserImport())
+
+	Expect(status).Equals(http.StatusNotFound)
+}
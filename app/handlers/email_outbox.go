@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/web"
+	webutil "github.com/getfider/fider/app/pkg/web/util"
+)
+
+// EmailLog is the page used by administrators to inspect the delivery of emails and the supressed addresses
+func EmailLog() web.HandlerFunc {
+	return func(c *web.Context) error {
+		searchEmails := &query.SearchOutboxEmails{}
+		if err := bus.Dispatch(c, searchEmails); err != nil {
+			return c.Failure(err)
+		}
+
+		getSupressedEmails := &query.GetSupressedEmails{}
+		if err := bus.Dispatch(c, getSupressedEmails); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Page(http.StatusOK, web.Props{
+			Page:  "Administration/pages/EmailLog.page",
+			Title: "Email Log · Site Settings",
+			Data: web.Map{
+				"emails":       searchEmails.Result,
+				"suppressions": getSupressedEmails.Result,
+			},
+		})
+	}
+}
+
+// SearchEmailLog returns the most recent emails, optionally filtered by status
+func SearchEmailLog() web.HandlerFunc {
+	return func(c *web.Context) error {
+		offset, err := c.QueryParamAsInt("offset")
+		if err != nil {
+			return c.BadRequest(web.Map{})
+		}
+
+		searchEmails := &query.SearchOutboxEmails{Offset: offset}
+		if err := searchEmails.Status.UnmarshalText([]byte(c.QueryParam("status"))); err != nil {
+			return c.BadRequest(web.Map{})
+		}
+
+		if err := bus.Dispatch(c, searchEmails); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(searchEmails.Result)
+	}
+}
+
+// ResendEmail queues a failed email to be sent again
+func ResendEmail() web.HandlerFunc {
+	return func(c *web.Context) error {
+		id, err := c.ParamAsInt("id")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		if err := bus.Dispatch(c, &cmd.ResendEmail{ID: int64(id)}); err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return c.NotFound()
+			}
+			return c.Failure(err)
+		}
+
+		err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditEmailResent,
+			TargetType: "email",
+			TargetID:   strconv.Itoa(id),
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ClearEmailSupression allows emails to be sent again to a user whose address bounced or complained
+func ClearEmailSupression() web.HandlerFunc {
+	return func(c *web.Context) error {
+		userID, err := c.ParamAsInt("userID")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		if err := bus.Dispatch(c, &cmd.ClearEmailSupression{UserID: userID}); err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return c.NotFound()
+			}
+			return c.Failure(err)
+		}
+
+		err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditEmailSupressionCleared,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(userID),
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
@@ -0,0 +1,161 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestEmailLogHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.SearchOutboxEmails) error {
+		q.Result = []*entity.OutboxEmail{
+			{ID: 2, TemplateName: "new_post", ToAddress: "arya.stark@got.com", Status: enum.EmailFailed},
+			{ID: 1, TemplateName: "new_post", ToAddress: "jon.snow@got.com", Status: enum.EmailSent},
+		}
+		return nil
+	})
+	bus.AddHandler(func(ctx context.Context, q *query.GetSupressedEmails) error {
+		q.Result = []*entity.SupressedEmail{
+			{UserID: 3, Name: "Sansa Stark", Email: "sansa.stark@got.com", SupressedAt: time.Now()},
+		}
+		return nil
+	})
+
+	code, page := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecuteAsPage(handlers.EmailLog())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(page.Page).Equals("Administration/pages/EmailLog.page")
+	Expect(page.Data["emails"]).HasLen(2)
+	Expect(page.Data["suppressions"]).HasLen(1)
+}
+
+func TestSearchEmailLogHandler(t *testing.T) {
+	RegisterT(t)
+
+	var search *query.SearchOutboxEmails
+	bus.AddHandler(func(ctx context.Context, q *query.SearchOutboxEmails) error {
+		search = q
+		q.Result = []*entity.OutboxEmail{}
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithURL("http://demo.test.fider.io/_api/admin/emails?status=failed&offset=50").
+		Execute(handlers.SearchEmailLog())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(search.Status).Equals(enum.EmailFailed)
+	Expect(search.Offset).Equals(50)
+}
+
+func TestResendEmailHandler(t *testing.T) {
+	RegisterT(t)
+
+	var resend *cmd.ResendEmail
+	bus.AddHandler(func(ctx context.Context, c *cmd.ResendEmail) error {
+		resend = c
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", "42").
+		ExecutePost(handlers.ResendEmail(), "")
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(resend.ID).Equals(int64(42))
+	Expect(auditLog.Action).Equals(enum.AuditEmailResent)
+	Expect(auditLog.TargetType).Equals("email")
+	Expect(auditLog.TargetID).Equals("42")
+}
+
+func TestResendEmailHandler_NotFailed(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.ResendEmail) error {
+		return app.ErrNotFound
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", "42").
+		ExecutePost(handlers.ResendEmail(), "")
+
+	Expect(code).Equals(http.StatusNotFound)
+	ExpectHandler(&cmd.AddAuditLog{}).CalledTimes(0)
+}
+
+func TestClearEmailSupressionHandler(t *testing.T) {
+	RegisterT(t)
+
+	var clear *cmd.ClearEmailSupression
+	bus.AddHandler(func(ctx context.Context, c *cmd.ClearEmailSupression) error {
+		clear = c
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", "3").
+		ExecutePost(handlers.ClearEmailSupression(), "")
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(clear.UserID).Equals(3)
+	Expect(auditLog.Action).Equals(enum.AuditEmailSupressionCleared)
+	Expect(auditLog.TargetType).Equals("user")
+	Expect(auditLog.TargetID).Equals("3")
+}
+
+func TestClearEmailSupressionHandler_NotSupressed(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.ClearEmailSupression) error {
+		return app.ErrNotFound
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", "3").
+		ExecutePost(handlers.ClearEmailSupression(), "")
+
+	Expect(code).Equals(http.StatusNotFound)
+	ExpectHandler(&cmd.AddAuditLog{}).CalledTimes(0)
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/emailtemplate"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/web"
+	webutil "github.com/getfider/fider/app/pkg/web/util"
+)
+
+// ManageEmailTemplates is the page used by administrators to customize email templates
+func ManageEmailTemplates() web.HandlerFunc {
+	return func(c *web.Context) error {
+		listTemplates := &query.ListEmailTemplates{}
+		if err := bus.Dispatch(c, listTemplates); err != nil {
+			return c.Failure(err)
+		}
+
+		defaults := make(map[string]*entity.EmailTemplate, len(emailtemplate.Kinds))
+		for _, kind := range emailtemplate.Kinds {
+			template, err := emailtemplate.Default(kind.Name)
+			if err != nil {
+				return c.Failure(err)
+			}
+			defaults[kind.Name] = template
+		}
+
+		return c.Page(http.StatusOK, web.Props{
+			Page:  "Administration/pages/ManageEmailTemplates.page",
+			Title: "Email Templates · Site Settings",
+			Data: web.Map{
+				"kinds":     emailtemplate.Kinds,
+				"defaults":  defaults,
+				"templates": listTemplates.Result,
+			},
+		})
+	}
+}
+
+// SaveEmailTemplate customizes the email template of given kind
+func SaveEmailTemplate() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := &actions.EditEmailTemplate{}
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		before, err := getCustomEmailTemplate(c, action.Kind)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		err = bus.Dispatch(c, &cmd.SaveEmailTemplate{
+			Kind:    action.Kind,
+			Subject: action.Subject,
+			Body:    action.Body,
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditEmailTemplateUpdated,
+			TargetType: "email_template",
+			TargetID:   action.Kind,
+			Before:     emailTemplateAuditValues(before),
+			After:      emailTemplateAuditValues(action.Template()),
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ResetEmailTemplate removes the customization of given kind, so that the default template is used again
+func ResetEmailTemplate() web.HandlerFunc {
+	return func(c *web.Context) error {
+		kind := c.Param("kind")
+		if !emailtemplate.IsCustomizable(kind) {
+			return c.NotFound()
+		}
+
+		before, err := getCustomEmailTemplate(c, kind)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if before == nil {
+			return c.Ok(web.Map{})
+		}
+
+		if err := bus.Dispatch(c, &cmd.DeleteEmailTemplate{Kind: kind}); err != nil {
+			return c.Failure(err)
+		}
+
+		err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditEmailTemplateReset,
+			TargetType: "email_template",
+			TargetID:   kind,
+			Before:     emailTemplateAuditValues(before),
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// PreviewEmailTemplate renders an email template with sample data
+func PreviewEmailTemplate() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := &actions.EditEmailTemplate{}
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		tmpl, err := emailtemplate.Parse(action.Template())
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		props := emailtemplate.SampleProps(c, action.Kind, web.BaseURL(c), web.LogoURL(c))
+		subject, body, err := emailtemplate.Render(c, tmpl, props)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{
+			"subject": subject,
+			"body":    body,
+		})
+	}
+}
+
+// SendTestEmailTemplate sends an email template with sample data to current user
+func SendTestEmailTemplate() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := &actions.EditEmailTemplate{}
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := bus.Dispatch(c, &cmd.SendMail{
+			From:         dto.Recipient{Name: c.Tenant().Name},
+			To:           []dto.Recipient{dto.NewRecipient(c.User().Name, c.User().Email, dto.Props{})},
+			TemplateName: action.Kind,
+			Props:        emailtemplate.SampleProps(c, action.Kind, web.BaseURL(c), web.LogoURL(c)),
+			Template:     action.Template(),
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+func getCustomEmailTemplate(c *web.Context, kind string) (*entity.EmailTemplate, error) {
+	getTemplate := &query.GetEmailTemplate{Kind: kind}
+	if err := bus.Dispatch(c, getTemplate); err != nil {
+		if errors.Cause(err) == app.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return getTemplate.Result, nil
+}
+
+func emailTemplateAuditValues(template *entity.EmailTemplate) entity.AuditValues {
+	if template == nil {
+		return nil
+	}
+	return entity.AuditValues{
+		"subject": template.Subject,
+		"body":    template.Body,
+	}
+}
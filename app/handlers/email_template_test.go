@@ -0,0 +1,184 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestManageEmailTemplatesHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.ListEmailTemplates) error {
+		q.Result = []*entity.EmailTemplate{
+			{Kind: "new_post", Subject: "New post: {{ .title }}", Body: "{{ .content }}"},
+		}
+		return nil
+	})
+
+	code, page := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecuteAsPage(handlers.ManageEmailTemplates())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(page.Page).Equals("Administration/pages/ManageEmailTemplates.page")
+	Expect(page.Data["templates"]).HasLen(1)
+	Expect(page.Data["defaults"]).HasLen(8)
+}
+
+func TestSaveEmailTemplateHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetEmailTemplate) error {
+		return app.ErrNotFound
+	})
+
+	var saveTemplate *cmd.SaveEmailTemplate
+	bus.AddHandler(func(ctx context.Context, c *cmd.SaveEmailTemplate) error {
+		saveTemplate = c
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("kind", "new_post").
+		ExecutePost(handlers.SaveEmailTemplate(), `{ "subject": "New post: {{ .title }}", "body": "<tr><td>{{ .content }}</td></tr>" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(saveTemplate.Kind).Equals("new_post")
+	Expect(saveTemplate.Subject).Equals("New post: {{ .title }}")
+	Expect(saveTemplate.Body).Equals("<tr><td>{{ .content }}</td></tr>")
+	Expect(auditLog.Action).Equals(enum.AuditEmailTemplateUpdated)
+	Expect(auditLog.TargetID).Equals("new_post")
+	Expect(auditLog.Before).IsNil()
+	Expect(auditLog.After["subject"]).Equals("New post: {{ .title }}")
+}
+
+func TestSaveEmailTemplateHandler_InvalidTemplate(t *testing.T) {
+	RegisterT(t)
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("kind", "new_post").
+		ExecutePost(handlers.SaveEmailTemplate(), `{ "subject": "New post: {{ .title }}", "body": "{{ template \"subject\" . }}" }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	ExpectHandler(&cmd.SaveEmailTemplate{}).CalledTimes(0)
+}
+
+func TestSaveEmailTemplateHandler_NotCustomizable(t *testing.T) {
+	RegisterT(t)
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("kind", "signup_email").
+		ExecutePost(handlers.SaveEmailTemplate(), `{ "subject": "Hello", "body": "World" }`)
+
+	Expect(code).Equals(http.StatusNotFound)
+	ExpectHandler(&cmd.SaveEmailTemplate{}).CalledTimes(0)
+}
+
+func TestSaveEmailTemplateHandler_NonAdministrator(t *testing.T) {
+	RegisterT(t)
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("kind", "new_post").
+		ExecutePost(handlers.SaveEmailTemplate(), `{ "subject": "Hello", "body": "World" }`)
+
+	Expect(code).Equals(http.StatusForbidden)
+	ExpectHandler(&cmd.SaveEmailTemplate{}).CalledTimes(0)
+}
+
+func TestResetEmailTemplateHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetEmailTemplate) error {
+		q.Result = &entity.EmailTemplate{Kind: q.Kind, Subject: "Hello", Body: "World"}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.DeleteEmailTemplate) error {
+		Expect(c.Kind).Equals("new_comment")
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("kind", "new_comment").
+		Execute(handlers.ResetEmailTemplate())
+
+	Expect(code).Equals(http.StatusOK)
+	ExpectHandler(&cmd.DeleteEmailTemplate{}).CalledOnce()
+	Expect(auditLog.Action).Equals(enum.AuditEmailTemplateReset)
+	Expect(auditLog.Before["body"]).Equals("World")
+}
+
+func TestPreviewEmailTemplateHandler(t *testing.T) {
+	RegisterT(t)
+
+	code, query := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("kind", "new_post").
+		ExecutePostAsJSON(handlers.PreviewEmailTemplate(), `{ "subject": "[{{ .siteName }}] {{ .title }}", "body": "<tr><td>{{ .userName }}</td></tr>" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(query.String("subject")).Equals("[Demonstration] Add dark mode")
+	Expect(query.String("body")).ContainsSubstring("<tr><td>Jon Snow</td></tr>")
+}
+
+func TestSendTestEmailTemplateHandler(t *testing.T) {
+	RegisterT(t)
+
+	var sendMail *cmd.SendMail
+	bus.AddHandler(func(ctx context.Context, c *cmd.SendMail) error {
+		sendMail = c
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("kind", "signin_email").
+		ExecutePost(handlers.SendTestEmailTemplate(), `{ "subject": "Sign in to {{ .siteName }}", "body": "<tr><td>{{ .link | html }}</td></tr>" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(sendMail.TemplateName).Equals("signin_email")
+	Expect(sendMail.To).HasLen(1)
+	Expect(sendMail.To[0].Address).Equals(mock.JonSnow.Email)
+	Expect(sendMail.Template.Subject).Equals("Sign in to {{ .siteName }}")
+	Expect(sendMail.Props["siteName"]).Equals("Demonstration")
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/getfider/fider/app/pkg/realtime"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// heartbeatInterval keeps idle streams from being closed by proxies and load balancers
+var heartbeatInterval = 25 * time.Second
+
+// StreamEvents sends notification, comment, vote and status events of current tenant to the browser as Server-Sent Events
+func StreamEvents() web.HandlerFunc {
+	return func(c *web.Context) error {
+		// Streams are long lived, so the database connection is released before it starts
+		if err := c.Commit(); err != nil {
+			return c.Failure(err)
+		}
+
+		events, unsubscribe := realtime.Subscribe(c.Tenant().ID, c.User().ID)
+		defer unsubscribe()
+
+		rc := http.NewResponseController(c.Response.Writer)
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return c.Failure(err)
+		}
+
+		c.Response.Header().Set("Content-Type", "text/event-stream")
+		c.Response.Header().Set("Cache-Control", "no-cache")
+		c.Response.Header().Set("Connection", "keep-alive")
+		c.Response.Header().Set("X-Accel-Buffering", "no")
+		c.Response.WriteHeader(http.StatusOK)
+		_ = rc.Flush()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-c.Done():
+				return nil
+			case event, ok := <-events:
+				if !ok {
+					return nil
+				}
+
+				data, err := json.Marshal(event.Data)
+				if err != nil {
+					return c.Failure(err)
+				}
+				_, err = fmt.Fprintf(&c.Response, "event: %s\ndata: %s\n\n", event.Type, data)
+				if err != nil {
+					return nil
+				}
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(&c.Response, ": ping\n\n"); err != nil {
+					return nil
+				}
+			}
+
+			if err := rc.Flush(); err != nil {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/realtime"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+func TestStreamEventsHandler(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	server.Use(func(next web.HandlerFunc) web.HandlerFunc {
+		return func(c *web.Context) error {
+			ctx, cancel := context.WithCancel(c.Context)
+			c.Context = ctx
+
+			// the stream ends when the browser disconnects
+			go func() {
+				for i := 0; i < 10; i++ {
+					realtime.Broadcast(realtime.Event{TenantID: mock.DemoTenant.ID, UserID: mock.AryaStark.ID, Type: enum.RealtimeNotification, Data: dto.Props{"id": 1}})
+					realtime.Broadcast(realtime.Event{TenantID: mock.DemoTenant.ID, UserID: mock.JonSnow.ID, Type: enum.RealtimeNotification, Data: dto.Props{"id": 2}})
+					time.Sleep(10 * time.Millisecond)
+				}
+				cancel()
+			}()
+
+			return next(c)
+		}
+	})
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		Execute(handlers.StreamEvents())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Header().Get("Content-Type")).Equals("text/event-stream")
+	Expect(response.Body.String()).ContainsSubstring("event: notification\ndata: {\"id\":1}\n\n")
+	Expect(strings.Contains(response.Body.String(), `"id":2`)).IsFalse()
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/models/entity"
-	"github.com/getfider/fider/app/models/enum"
 
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
@@ -104,8 +103,7 @@ func OAuthToken() web.HandlerFunc {
 		}
 		if err != nil {
 			if errors.Cause(err) == app.ErrNotFound {
-				isTrusted := isTrustedOAuthProvider(c, provider)
-				if c.Tenant().IsPrivate && !isTrusted {
+				if c.Tenant().IsPrivate && !canJoinByOAuth(c, provider, oauthUser.Result.Email) {
 					return c.Redirect("/not-invited")
 				}
 
@@ -113,7 +111,8 @@ func OAuthToken() web.HandlerFunc {
 					Name:   oauthUser.Result.Name,
 					Tenant: c.Tenant(),
 					Email:  oauthUser.Result.Email,
-					Role:   enum.RoleVisitor,
+					Role:   newUserRole(c.Tenant(), oauthUser.Result.Email),
+					Locale: newUserLocale(c),
 					Providers: []*entity.UserProvider{
 						{
 							UID:  oauthUser.Result.ID,
@@ -144,6 +143,15 @@ func OAuthToken() web.HandlerFunc {
 	}
 }
 
+// canJoinByOAuth returns true if a new user can join current private tenant through given provider
+// When trusted email domains are configured, they take precedence over trusted providers
+func canJoinByOAuth(c *web.Context, provider, email string) bool {
+	if len(c.Tenant().AllowedEmailDomains) > 0 {
+		return c.Tenant().IsEmailDomainAllowed(email)
+	}
+	return isTrustedOAuthProvider(c, provider)
+}
+
 func isTrustedOAuthProvider(ctx context.Context, provider string) bool {
 	customOAuthConfigByProvider := &query.GetCustomOAuthConfigByProvider{Provider: provider}
 	err := bus.Dispatch(ctx, customOAuthConfigByProvider)
@@ -626,6 +626,101 @@ func TestOAuthTokenHandler_NewUser_PrivateSite_UsingTrustedProvider(t *testing.T
 	})
 }
 
+func TestOAuthTokenHandler_NewUser_PrivateSite_AllowedEmailDomain(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	mock.AvengersTenant.IsPrivate = true
+	mock.AvengersTenant.AllowedEmailDomains = []string{"facebook.com"}
+	mock.AvengersTenant.AutoJoinRole = enum.RoleCollaborator
+
+	var newUser *entity.User
+	bus.AddHandler(func(ctx context.Context, c *cmd.RegisterUser) error {
+		c.User.ID = 999
+		newUser = c.User
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByProvider) error {
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetOAuthProfile) error {
+		q.Result = &dto.OAuthUserProfile{
+			ID:    "FB456",
+			Name:  "Some Facebook Guy",
+			Email: "some.guy@facebook.com",
+		}
+		return nil
+	})
+
+	code, response := server.
+		WithURL("http://feedback.theavengers.com/oauth/facebook/token?code=456&identifier=MY_SESSION_ID&redirect=/").
+		OnTenant(mock.AvengersTenant).
+		AddParam("provider", app.FacebookProvider).
+		AddCookie(web.CookieSessionName, "MY_SESSION_ID").
+		Use(middlewares.Session()).
+		Execute(handlers.OAuthToken())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("/")
+	Expect(newUser.Role).Equals(enum.RoleCollaborator)
+	ExpectFiderAuthCookie(response, newUser)
+}
+
+func TestOAuthTokenHandler_NewUser_PrivateSite_TrustedProvider_OtherEmailDomain(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	mock.AvengersTenant.IsPrivate = true
+	mock.AvengersTenant.AllowedEmailDomains = []string{"theavengers.com"}
+
+	providerCode := "_jd72hfjv"
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByProvider) error {
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetCustomOAuthConfigByProvider) error {
+		q.Result = &entity.OAuthConfig{
+			Provider:    providerCode,
+			DisplayName: "Microsoft AD",
+			IsTrusted:   true,
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetOAuthProfile) error {
+		q.Result = &dto.OAuthUserProfile{
+			ID:    "1234-5678",
+			Name:  "Mark Doe",
+			Email: "mark.doe@microsoft.com",
+		}
+		return nil
+	})
+
+	code, response := server.
+		WithURL("http://feedback.theavengers.com/oauth/"+providerCode+"/token?code=000111&identifier=MY_SESSION_ID&redirect=/").
+		OnTenant(mock.AvengersTenant).
+		AddParam("provider", providerCode).
+		AddCookie(web.CookieSessionName, "MY_SESSION_ID").
+		Use(middlewares.Session()).
+		Execute(handlers.OAuthToken())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("/not-invited")
+	ExpectHandler(&cmd.RegisterUser{}).CalledTimes(0)
+	ExpectFiderAuthCookie(response, nil)
+}
+
 func TestOAuthTokenHandler_InvalidIdentifier(t *testing.T) {
 	RegisterT(t)
 	server := mock.NewServer()
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/web"
+	webutil "github.com/getfider/fider/app/pkg/web/util"
+	"github.com/getfider/fider/app/pkg/webauthn"
+)
+
+// passkeyTokenLifetime is how long a passkey challenge can be answered
+const passkeyTokenLifetime = 5 * time.Minute
+
+func passkeyConfig(c *web.Context) webauthn.Config {
+	return webauthn.Config{
+		RPID:   c.Request.URL.Hostname(),
+		RPName: c.Tenant().Name,
+		Origin: c.BaseURL(),
+	}
+}
+
+func newPasskeyToken(c *web.Context, challenge string, userID int) (string, error) {
+	return jwt.Encode(jwt.WebAuthnClaims{
+		Challenge:  challenge,
+		Identifier: c.SessionID(),
+		UserID:     userID,
+		Metadata: jwt.Metadata{
+			ExpiresAt: jwt.Time(time.Now().Add(passkeyTokenLifetime)),
+		},
+	})
+}
+
+func decodePasskeyToken(c *web.Context, token string, userID int) *jwt.WebAuthnClaims {
+	claims, err := jwt.DecodeWebAuthnClaims(token)
+	if err != nil || claims.Identifier != c.SessionID() || claims.UserID != userID {
+		return nil
+	}
+	return claims
+}
+
+// usePasskeyChallenge records the challenge of given token for as long as it can be answered, so that each challenge is verified only once
+// It returns false when the challenge was already used
+func usePasskeyChallenge(c *web.Context, claims *jwt.WebAuthnClaims) (bool, error) {
+	hit := &cmd.HitRateLimit{
+		Key:    fmt.Sprintf("%d:passkey:%x", c.Tenant().ID, sha256.Sum256([]byte(claims.Challenge))),
+		Window: passkeyTokenLifetime,
+	}
+	if err := bus.Dispatch(c, hit); err != nil {
+		return false, err
+	}
+	return hit.Result.Hits == 1, nil
+}
+
+func invalidPasskey(c *web.Context) error {
+	return c.HandleValidation(validate.Failed(i18n.T(c, "validation.custom.invalidpasskey")))
+}
+
+// PasskeyRegistrationOptions returns the options used by the browser to create a new passkey for current user
+func PasskeyRegistrationOptions() web.HandlerFunc {
+	return func(c *web.Context) error {
+		if c.Tenant().PasskeyMode == enum.PasskeyDisabled {
+			return c.NotFound()
+		}
+
+		passkeys := &query.ListUserPasskeys{UserID: c.User().ID}
+		if err := bus.Dispatch(c, passkeys); err != nil {
+			return c.Failure(err)
+		}
+
+		excludeIDs := make([]string, len(passkeys.Result))
+		for i, passkey := range passkeys.Result {
+			excludeIDs[i] = passkey.CredentialID
+		}
+
+		challenge := webauthn.NewChallenge()
+		token, err := newPasskeyToken(c, challenge, c.User().ID)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		user := webauthn.UserEntity{
+			ID:          webauthn.EncodeUserHandle(strconv.Itoa(c.User().ID)),
+			Name:        c.User().Email,
+			DisplayName: c.User().Name,
+		}
+		if user.Name == "" {
+			user.Name = c.User().Name
+		}
+
+		return c.Ok(web.Map{
+			"options": passkeyConfig(c).NewCreationOptions(challenge, user, excludeIDs),
+			"token":   token,
+		})
+	}
+}
+
+// RegisterPasskey verifies and stores a new passkey for current user
+func RegisterPasskey() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.RegisterPasskey)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		claims := decodePasskeyToken(c, action.Token, c.User().ID)
+		if claims == nil {
+			return invalidPasskey(c)
+		}
+
+		firstUse, err := usePasskeyChallenge(c, claims)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if !firstUse {
+			return invalidPasskey(c)
+		}
+
+		credential, err := passkeyConfig(c).VerifyRegistration(claims.Challenge, action.Credential)
+		if err != nil {
+			log.Warnf(c, "Failed to register passkey: @{Error}", dto.Props{
+				"Error": err.Error(),
+			})
+			return invalidPasskey(c)
+		}
+
+		passkey := &entity.UserPasskey{
+			UserID:       c.User().ID,
+			Name:         action.Name,
+			CredentialID: credential.ID,
+			PublicKey:    credential.PublicKey,
+			SignCount:    credential.SignCount,
+			Transports:   action.Credential.Response.Transports,
+		}
+		if err := bus.Dispatch(c, &cmd.AddUserPasskey{Passkey: passkey}); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(passkey)
+	}
+}
+
+// DeletePasskey removes a passkey from current user
+func DeletePasskey() web.HandlerFunc {
+	return func(c *web.Context) error {
+		id, err := c.ParamAsInt("id")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		if err := bus.Dispatch(c, &cmd.DeleteCurrentUserPasskey{ID: id}); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// PasskeySignInOptions returns the options used by the browser to sign in with a passkey
+func PasskeySignInOptions() web.HandlerFunc {
+	return func(c *web.Context) error {
+		if c.Tenant().PasskeyMode == enum.PasskeyDisabled {
+			return c.NotFound()
+		}
+
+		challenge := webauthn.NewChallenge()
+		token, err := newPasskeyToken(c, challenge, 0)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{
+			"options": passkeyConfig(c).NewRequestOptions(challenge, nil),
+			"token":   token,
+		})
+	}
+}
+
+// SignInByPasskey verifies a passkey assertion and sign in its owner
+func SignInByPasskey() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.SignInByPasskey)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		claims := decodePasskeyToken(c, action.Token, 0)
+		if claims == nil {
+			return invalidPasskey(c)
+		}
+
+		firstUse, err := usePasskeyChallenge(c, claims)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if !firstUse {
+			return invalidPasskey(c)
+		}
+
+		getPasskey := &query.GetPasskeyByCredentialID{CredentialID: action.Credential.ID}
+		if err := bus.Dispatch(c, getPasskey); err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return invalidPasskey(c)
+			}
+			return c.Failure(err)
+		}
+
+		passkey := getPasskey.Result
+		signCount, err := passkeyConfig(c).VerifyAssertion(claims.Challenge, action.Credential, &webauthn.Credential{
+			ID:        passkey.CredentialID,
+			PublicKey: passkey.PublicKey,
+			SignCount: passkey.SignCount,
+		})
+		if err != nil {
+			log.Warnf(c, "Failed to verify passkey '@{PasskeyID}': @{Error}", dto.Props{
+				"PasskeyID": passkey.ID,
+				"Error":     err.Error(),
+			})
+			return invalidPasskey(c)
+		}
+
+		getUser := &query.GetUserByID{UserID: passkey.UserID}
+		if err := bus.Dispatch(c, getUser); err != nil {
+			return c.Failure(err)
+		}
+
+		user := getUser.Result
+		if user.Status == enum.UserBlocked || user.Tenant.ID != c.Tenant().ID {
+			return invalidPasskey(c)
+		}
+
+		if err := bus.Dispatch(c, &cmd.SetPasskeyAsUsed{ID: passkey.ID, SignCount: signCount}); err != nil {
+			return c.Failure(err)
+		}
+
+		webutil.AddAuthUserCookie(c, user)
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// UpdatePasskeySettings update current tenant's passkey settings
+func UpdatePasskeySettings() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.UpdateTenantPasskeySettings)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		before := entity.AuditValues{"passkeyMode": c.Tenant().PasskeyMode}
+		updateSettings := &cmd.UpdateTenantPasskeySettings{
+			PasskeyMode: action.PasskeyMode,
+		}
+		if err := bus.Dispatch(c, updateSettings); err != nil {
+			return c.Failure(err)
+		}
+
+		err := auditSettings(c, "passkeys", before, entity.AuditValues{"passkeyMode": action.PasskeyMode})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
@@ -0,0 +1,189 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestPasskeyRegistrationOptionsHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.ListUserPasskeys) error {
+		q.Result = []*entity.UserPasskey{
+			{ID: 1, UserID: q.UserID, Name: "My Phone", CredentialID: "cred1"},
+		}
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, json := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePostAsJSON(handlers.PasskeyRegistrationOptions(), `{}`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(json.String("options.rp.name")).Equals("Demonstration")
+	Expect(json.String("options.user.name")).Equals("jon.snow@got.com")
+	Expect(json.String("options.excludeCredentials[0].id")).Equals("cred1")
+
+	claims, err := jwt.DecodeWebAuthnClaims(json.String("token"))
+	Expect(err).IsNil()
+	Expect(claims.UserID).Equals(mock.JonSnow.ID)
+	Expect(claims.Challenge).Equals(json.String("options.challenge"))
+}
+
+func TestPasskeyRegistrationOptionsHandler_Disabled(t *testing.T) {
+	RegisterT(t)
+
+	tenant := *mock.DemoTenant
+	tenant.PasskeyMode = enum.PasskeyDisabled
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(&tenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.PasskeyRegistrationOptions(), `{}`)
+
+	Expect(code).Equals(http.StatusNotFound)
+}
+
+func TestRegisterPasskeyHandler_InvalidToken(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.RegisterPasskey(), `{ "name": "My Phone", "token": "invalid", "credential": { "id": "abc", "type": "public-key" } }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	ExpectHandler(&cmd.AddUserPasskey{}).CalledTimes(0)
+}
+
+func TestRegisterPasskeyHandler_TokenFromAnotherUser(t *testing.T) {
+	RegisterT(t)
+
+	token, _ := jwt.Encode(jwt.WebAuthnClaims{
+		Challenge: "abc",
+		UserID:    mock.AryaStark.ID,
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.RegisterPasskey(), `{ "name": "My Phone", "token": "`+token+`", "credential": { "id": "abc", "type": "public-key" } }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	ExpectHandler(&cmd.AddUserPasskey{}).CalledTimes(0)
+}
+
+func TestDeletePasskeyHandler(t *testing.T) {
+	RegisterT(t)
+
+	var deleteCmd *cmd.DeleteCurrentUserPasskey
+	bus.AddHandler(func(ctx context.Context, c *cmd.DeleteCurrentUserPasskey) error {
+		deleteCmd = c
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", 4).
+		Execute(handlers.DeletePasskey())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(deleteCmd.ID).Equals(4)
+}
+
+func TestPasskeySignInOptionsHandler(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	code, json := server.
+		OnTenant(mock.DemoTenant).
+		ExecutePostAsJSON(handlers.PasskeySignInOptions(), `{}`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(json.String("options.challenge")).HasLen(43)
+	Expect(json.Contains("options.allowCredentials")).IsTrue()
+
+	claims, err := jwt.DecodeWebAuthnClaims(json.String("token"))
+	Expect(err).IsNil()
+	Expect(claims.UserID).Equals(0)
+}
+
+func TestSignInByPasskeyHandler_UnknownCredential(t *testing.T) {
+	RegisterT(t)
+	mockRateLimitHits()
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetPasskeyByCredentialID) error {
+		return app.ErrNotFound
+	})
+
+	token, _ := jwt.Encode(jwt.WebAuthnClaims{Challenge: "abc"})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		ExecutePost(handlers.SignInByPasskey(), `{ "token": "`+token+`", "credential": { "id": "abc", "type": "public-key" } }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	ExpectHandler(&cmd.SetPasskeyAsUsed{}).CalledTimes(0)
+}
+
+func TestUpdatePasskeySettingsHandler(t *testing.T) {
+	RegisterT(t)
+
+	var updateCmd *cmd.UpdateTenantPasskeySettings
+	bus.AddHandler(func(ctx context.Context, c *cmd.UpdateTenantPasskeySettings) error {
+		updateCmd = c
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.UpdatePasskeySettings(), `{ "passkeyMode": "required" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(updateCmd.PasskeyMode).Equals(enum.PasskeyRequired)
+}
+
+func TestSignInByPasskeyHandler_ReusedChallenge(t *testing.T) {
+	RegisterT(t)
+	mockRateLimitHits()
+
+	lookups := 0
+	bus.AddHandler(func(ctx context.Context, q *query.GetPasskeyByCredentialID) error {
+		lookups++
+		return app.ErrNotFound
+	})
+
+	token, _ := jwt.Encode(jwt.WebAuthnClaims{Challenge: "abc"})
+
+	for i := 0; i < 2; i++ {
+		code, _ := mock.NewServer().
+			OnTenant(mock.DemoTenant).
+			ExecutePost(handlers.SignInByPasskey(), `{ "token": "`+token+`", "credential": { "id": "abc", "type": "public-key" } }`)
+		Expect(code).Equals(http.StatusBadRequest)
+	}
+
+	Expect(lookups).Equals(1)
+	ExpectHandler(&cmd.SetPasskeyAsUsed{}).CalledTimes(0)
+}
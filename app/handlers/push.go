@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// AddPushSubscription registers current browser to receive Web Push notifications
+func AddPushSubscription() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.AddPushSubscription)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := bus.Dispatch(c, &cmd.AddPushSubscription{
+			Endpoint: action.Endpoint,
+			P256dh:   action.Keys.P256dh,
+			Auth:     action.Keys.Auth,
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// DeletePushSubscription stops sending Web Push notifications to current browser
+func DeletePushSubscription() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.DeletePushSubscription)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		if err := bus.Dispatch(c, &cmd.DeletePushSubscription{UserID: c.User().ID, Endpoint: action.Endpoint}); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ServiceWorker returns the script that displays Web Push notifications
+// It's served from the root so that it controls every page of the site
+func ServiceWorker() web.HandlerFunc {
+	return func(c *web.Context) error {
+		bytes, err := os.ReadFile(env.Path("./service-worker.js"))
+		if err != nil {
+			return c.NotFound()
+		}
+		c.Response.Header().Set("Cache-Control", "no-cache")
+		return c.Blob(http.StatusOK, "application/javascript; charset=utf-8", bytes)
+	}
+}
@@ -0,0 +1,69 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models/cmd"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestAddPushSubscriptionHandler(t *testing.T) {
+	RegisterT(t)
+	env.Config.WebPush.VAPIDPublicKey = "public"
+	env.Config.WebPush.VAPIDPrivateKey = "private"
+
+	var addCmd *cmd.AddPushSubscription
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddPushSubscription) error {
+		addCmd = c
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.AddPushSubscription(), `{ "endpoint": "https://fcm.googleapis.com/fcm/send/abc", "keys": { "p256dh": "BCVxsr7N", "auth": "BTBZMqHH" } }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(addCmd.Endpoint).Equals("https://fcm.googleapis.com/fcm/send/abc")
+	Expect(addCmd.P256dh).Equals("BCVxsr7N")
+	Expect(addCmd.Auth).Equals("BTBZMqHH")
+}
+
+func TestAddPushSubscriptionHandler_Disabled(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.AddPushSubscription(), `{ "endpoint": "https://fcm.googleapis.com/fcm/send/abc", "keys": { "p256dh": "BCVxsr7N", "auth": "BTBZMqHH" } }`)
+
+	Expect(code).Equals(http.StatusForbidden)
+}
+
+func TestDeletePushSubscriptionHandler(t *testing.T) {
+	RegisterT(t)
+
+	var deleteCmd *cmd.DeletePushSubscription
+	bus.AddHandler(func(ctx context.Context, c *cmd.DeletePushSubscription) error {
+		deleteCmd = c
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.DeletePushSubscription(), `{ "endpoint": "https://fcm.googleapis.com/fcm/send/abc" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(deleteCmd.Endpoint).Equals("https://fcm.googleapis.com/fcm/send/abc")
+	Expect(deleteCmd.UserID).Equals(mock.JonSnow.ID)
+}
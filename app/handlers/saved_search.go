@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// AddSavedSearch follows a view and tags of posts to be notified about posts matching them
+func AddSavedSearch() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.AddSavedSearch)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		addSavedSearch := &cmd.AddSavedSearch{
+			Name:     action.Name,
+			View:     action.View,
+			Tags:     action.TagList,
+			Channels: action.Channels,
+		}
+		if err := bus.Dispatch(c, addSavedSearch); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(addSavedSearch.Result)
+	}
+}
+
+// UpdateSavedSearch changes the channels used to notify about a saved search
+func UpdateSavedSearch() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.UpdateSavedSearch)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := bus.Dispatch(c, &cmd.UpdateSavedSearch{
+			ID:       action.ID,
+			Channels: action.Channels,
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// DeleteSavedSearch stops following a saved search
+func DeleteSavedSearch() web.HandlerFunc {
+	return func(c *web.Context) error {
+		id, err := c.ParamAsInt("id")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		if err := bus.Dispatch(c, &cmd.DeleteSavedSearch{ID: id}); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
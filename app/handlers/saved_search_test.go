@@ -0,0 +1,156 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func setupSavedSearchTags() {
+	bus.AddHandler(func(ctx context.Context, q *query.GetCurrentUserSavedSearches) error {
+		q.Result = []*entity.SavedSearch{}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetTagBySlug) error {
+		switch q.Slug {
+		case "bug":
+			q.Result = &entity.Tag{ID: 1, Name: "Bug", Slug: "bug", IsPublic: true}
+		case "internal":
+			q.Result = &entity.Tag{ID: 2, Name: "Internal", Slug: "internal", IsPublic: false}
+		default:
+			return app.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func TestAddSavedSearchHandler(t *testing.T) {
+	RegisterT(t)
+	setupSavedSearchTags()
+
+	var addCmd *cmd.AddSavedSearch
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddSavedSearch) error {
+		addCmd = c
+		c.Result = &entity.SavedSearch{ID: 1, Name: c.Name, View: c.View, Tags: []string{"bug"}, Channels: c.Channels}
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.AddSavedSearch(), `{ "name": "Bugs", "tags": ["bug"], "channels": 3 }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(addCmd.Name).Equals("Bugs")
+	Expect(addCmd.View).Equals("trending")
+	Expect(addCmd.Tags).HasLen(1)
+	Expect(addCmd.Tags[0].ID).Equals(1)
+	Expect(addCmd.Channels).Equals(enum.NotificationChannelWeb | enum.NotificationChannelEmail)
+}
+
+func TestAddSavedSearchHandler_Invalid(t *testing.T) {
+	RegisterT(t)
+	setupSavedSearchTags()
+
+	server := mock.NewServer()
+	for _, input := range []string{
+		`{ "name": "", "tags": ["bug"], "channels": 1 }`,
+		`{ "name": "Bugs", "view": "my-votes", "channels": 1 }`,
+		`{ "name": "Bugs", "tags": ["unknown"], "channels": 1 }`,
+		`{ "name": "Bugs", "tags": ["internal"], "channels": 1 }`,
+		`{ "name": "Bugs", "tags": ["bug"], "channels": 0 }`,
+		`{ "name": "Bugs", "tags": ["bug"], "channels": 8 }`,
+	} {
+		code, _ := server.
+			OnTenant(mock.DemoTenant).
+			AsUser(mock.AryaStark).
+			ExecutePost(handlers.AddSavedSearch(), input)
+
+		Expect(code).Equals(http.StatusBadRequest)
+	}
+}
+
+func TestAddSavedSearchHandler_TooManySearches(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetCurrentUserSavedSearches) error {
+		q.Result = make([]*entity.SavedSearch, 20)
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.AddSavedSearch(), `{ "name": "Bugs", "channels": 1 }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
+
+func TestAddSavedSearchHandler_PrivateTagAsCollaborator(t *testing.T) {
+	RegisterT(t)
+	setupSavedSearchTags()
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddSavedSearch) error {
+		c.Result = &entity.SavedSearch{ID: 1}
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.AddSavedSearch(), `{ "name": "Internal", "view": "all", "tags": ["internal"], "channels": 1 }`)
+
+	Expect(code).Equals(http.StatusOK)
+}
+
+func TestUpdateSavedSearchHandler(t *testing.T) {
+	RegisterT(t)
+
+	var updateCmd *cmd.UpdateSavedSearch
+	bus.AddHandler(func(ctx context.Context, c *cmd.UpdateSavedSearch) error {
+		updateCmd = c
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("id", 4).
+		ExecutePost(handlers.UpdateSavedSearch(), `{ "channels": 4 }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(updateCmd.ID).Equals(4)
+	Expect(updateCmd.Channels).Equals(enum.NotificationChannelPush)
+}
+
+func TestDeleteSavedSearchHandler_NotFound(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.DeleteSavedSearch) error {
+		return app.ErrNotFound
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("id", 4).
+		ExecutePost(handlers.DeleteSavedSearch(), ``)
+
+	Expect(code).Equals(http.StatusNotFound)
+}
@@ -2,18 +2,22 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/getfider/fider/app/models/entity"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/pkg/backup"
 	"github.com/getfider/fider/app/pkg/bus"
 
 	"github.com/getfider/fider/app/tasks"
 
 	"github.com/getfider/fider/app/actions"
 	"github.com/getfider/fider/app/pkg/web"
+	webutil "github.com/getfider/fider/app/pkg/web/util"
 )
 
 // ChangeUserEmail register the intent of changing user email
@@ -77,11 +81,29 @@ func UserSettings() web.HandlerFunc {
 			return err
 		}
 
+		passkeys := &query.ListUserPasskeys{UserID: c.User().ID}
+		if err := bus.Dispatch(c, passkeys); err != nil {
+			return err
+		}
+
+		profile := &query.GetUserProfile{UserID: c.User().ID}
+		if err := bus.Dispatch(c, profile); err != nil {
+			return err
+		}
+
+		savedSearches := &query.GetCurrentUserSavedSearches{}
+		if err := bus.Dispatch(c, savedSearches); err != nil {
+			return err
+		}
+
 		return c.Page(http.StatusOK, web.Props{
 			Page:  "MySettings/MySettings.page",
 			Title: "Settings",
 			Data: web.Map{
-				"userSettings": settings.Result,
+				"userSettings":  settings.Result,
+				"passkeys":      passkeys.Result,
+				"profile":       profile.Result,
+				"savedSearches": savedSearches.Result,
 			},
 		})
 	}
@@ -104,6 +126,9 @@ func UpdateUserSettings() web.HandlerFunc {
 				Name:       action.Name,
 				Avatar:     action.Avatar,
 				AvatarType: action.AvatarType,
+				Bio:        action.Bio,
+				Link:       action.Link,
+				Locale:     action.Locale,
 			},
 			&cmd.UpdateCurrentUserSettings{
 				Settings: action.Settings,
@@ -133,6 +158,19 @@ func ChangeUserRole() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		auditLog := &cmd.AddAuditLog{
+			Action:     enum.AuditUserRoleChanged,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(action.UserID),
+			After:      entity.AuditValues{"role": action.Role},
+		}
+		if action.User != nil {
+			auditLog.Before = entity.AuditValues{"role": action.User.Role}
+		}
+		if err := webutil.AddAuditLog(c, auditLog); err != nil {
+			return c.Failure(err)
+		}
+
 		return c.Ok(web.Map{})
 	}
 }
@@ -149,6 +187,18 @@ func DeleteUser() web.HandlerFunc {
 	}
 }
 
+// ExportMyData returns a Zip file with all the data tied to current user
+func ExportMyData() web.HandlerFunc {
+	return func(c *web.Context) error {
+		file, err := backup.CreateUserArchive(c, c.User())
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Attachment("my-data.zip", "application/zip", file.Bytes())
+	}
+}
+
 // RegenerateAPIKey regenerates current user's API Key
 func RegenerateAPIKey() web.HandlerFunc {
 	return func(c *web.Context) error {
@@ -29,6 +29,19 @@ func TestSettingsHandler(t *testing.T) {
 		return nil
 	})
 
+	bus.AddHandler(func(ctx context.Context, q *query.ListUserPasskeys) error {
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserProfile) error {
+		q.Result = &entity.UserProfile{User: mock.JonSnow, Bio: "King in the North"}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetCurrentUserSavedSearches) error {
+		return nil
+	})
+
 	server := mock.NewServer()
 	code, _ := server.
 		AsUser(mock.JonSnow).
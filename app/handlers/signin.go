@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/getfider/fider/app/models/cmd"
@@ -13,6 +14,8 @@ import (
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/validate"
 	"github.com/getfider/fider/app/pkg/web"
 	webutil "github.com/getfider/fider/app/pkg/web/util"
 	"github.com/getfider/fider/app/tasks"
@@ -52,6 +55,11 @@ func SignInByEmail() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
+		// Respond as usual so that this doesn't reveal which emails are protected by a passkey
+		if action.PasskeyRequired {
+			return c.Ok(web.Map{})
+		}
+
 		err := bus.Dispatch(c, &cmd.SaveVerificationKey{
 			Key:      action.VerificationKey,
 			Duration: 30 * time.Minute,
@@ -80,7 +88,7 @@ func VerifySignInKey(kind enum.EmailVerificationKind) web.HandlerFunc {
 		err = bus.Dispatch(c, userByEmail)
 		if err != nil {
 			if errors.Cause(err) == app.ErrNotFound {
-				if kind == enum.EmailVerificationKindSignIn && c.Tenant().IsPrivate {
+				if kind == enum.EmailVerificationKindSignIn && c.Tenant().IsPrivate && !c.Tenant().IsEmailDomainAllowed(result.Email) {
 					return NotInvitedPage()(c)
 				}
 
@@ -126,11 +134,16 @@ func CompleteSignInProfile() web.HandlerFunc {
 			return c.BadRequest(web.Map{})
 		}
 
+		if action.Kind == enum.EmailVerificationKindSignIn && c.Tenant().IsPrivate && !c.Tenant().IsEmailDomainAllowed(result.Email) {
+			return c.Forbidden()
+		}
+
 		user := &entity.User{
 			Name:   action.Name,
 			Email:  result.Email,
 			Tenant: c.Tenant(),
-			Role:   enum.RoleVisitor,
+			Role:   newUserRole(c.Tenant(), result.Email),
+			Locale: newUserLocale(c),
 		}
 		err = bus.Dispatch(c, &cmd.RegisterUser{User: user})
 		if err != nil {
@@ -148,6 +161,21 @@ func CompleteSignInProfile() web.HandlerFunc {
 	}
 }
 
+// newUserRole returns the role given to a user joining current tenant with given email
+// Private tenants can automatically grant a different role to users of their trusted email domains
+func newUserRole(tenant *entity.Tenant, email string) enum.Role {
+	if tenant.IsPrivate && tenant.AutoJoinRole != 0 && tenant.IsEmailDomainAllowed(email) {
+		return tenant.AutoJoinRole
+	}
+	return enum.RoleVisitor
+}
+
+// newUserLocale returns the preferred locale of a user joining from the current browser
+// It's empty when none of the browser languages is supported, so the user follows the locale of the tenant
+func newUserLocale(c *web.Context) string {
+	return i18n.DetectLocale(c.Request.GetHeader("Accept-Language"))
+}
+
 // SignOut remove auth cookies
 func SignOut() web.HandlerFunc {
 	return func(c *web.Context) error {
@@ -155,3 +183,96 @@ func SignOut() web.HandlerFunc {
 		return c.Redirect(c.QueryParam("redirect"))
 	}
 }
+
+// SignInByLDAP authenticates the user against the configured directory server
+// Users are provisioned on their first sign in and their role is kept in sync with directory groups
+func SignInByLDAP() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.SignInByLDAP)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		ldapUser := &query.GetLDAPProfile{Username: action.Username, Password: action.Password}
+		if err := bus.Dispatch(c, ldapUser); err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return c.HandleValidation(validate.Failed(i18n.T(c, "validation.custom.invalidldapcredentials")))
+			}
+			return c.Failure(err)
+		}
+
+		profile := ldapUser.Result
+		userByProvider := &query.GetUserByProvider{Provider: app.LDAPProvider, UID: profile.ID}
+		err := bus.Dispatch(c, userByProvider)
+		user := userByProvider.Result
+
+		if errors.Cause(err) == app.ErrNotFound && profile.Email != "" {
+			userByEmail := &query.GetUserByEmail{Email: profile.Email}
+			err = bus.Dispatch(c, userByEmail)
+			user = userByEmail.Result
+		}
+
+		if err != nil {
+			if errors.Cause(err) != app.ErrNotFound {
+				return c.Failure(err)
+			}
+
+			user = &entity.User{
+				Name:   profile.Name,
+				Tenant: c.Tenant(),
+				Email:  profile.Email,
+				Role:   enum.RoleVisitor,
+				Locale: newUserLocale(c),
+				Providers: []*entity.UserProvider{
+					{
+						UID:  profile.ID,
+						Name: app.LDAPProvider,
+					},
+				},
+			}
+			if profile.Role != 0 {
+				user.Role = profile.Role
+			}
+
+			if err = bus.Dispatch(c, &cmd.RegisterUser{User: user}); err != nil {
+				return c.Failure(err)
+			}
+		} else {
+			if user.Status == enum.UserBlocked {
+				return c.HandleValidation(validate.Failed(i18n.T(c, "validation.custom.invalidldapcredentials")))
+			}
+
+			if !user.HasProvider(app.LDAPProvider) {
+				if err = bus.Dispatch(c, &cmd.RegisterUserProvider{
+					UserID:       user.ID,
+					ProviderName: app.LDAPProvider,
+					ProviderUID:  profile.ID,
+				}); err != nil {
+					return c.Failure(err)
+				}
+			}
+
+			// administrators are never demoted by the directory, as it could leave the site without one
+			if profile.Role != 0 && profile.Role != user.Role && user.Role != enum.RoleAdministrator {
+				if err = bus.Dispatch(c, &cmd.ChangeUserRole{UserID: user.ID, Role: profile.Role}); err != nil {
+					return c.Failure(err)
+				}
+
+				if err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+					Action:     enum.AuditUserRoleChanged,
+					TargetType: "user",
+					TargetID:   strconv.Itoa(user.ID),
+					Before:     entity.AuditValues{"role": user.Role},
+					After:      entity.AuditValues{"role": profile.Role},
+				}); err != nil {
+					return c.Failure(err)
+				}
+				user.Role = profile.Role
+			}
+		}
+
+		webutil.AddAuthUserCookie(c, user)
+
+		return c.Ok(web.Map{})
+	}
+}
@@ -13,11 +13,13 @@ import (
 
 	"github.com/getfider/fider/app/handlers"
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/models/entity"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/jwt"
 	"github.com/getfider/fider/app/pkg/mock"
 	"github.com/getfider/fider/app/pkg/web"
@@ -55,6 +57,30 @@ func TestSignInByEmailHandler_WithEmail(t *testing.T) {
 	Expect(saveKeyCmd.Request.GetName()).Equals("")
 }
 
+func TestSignInByEmailHandler_PasskeyRequired(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		q.Result = &entity.User{ID: 2, Email: q.Email, Role: enum.RoleVisitor}
+		return nil
+	})
+	bus.AddHandler(func(ctx context.Context, q *query.ListUserPasskeys) error {
+		q.Result = []*entity.UserPasskey{{ID: 1, UserID: q.UserID}}
+		return nil
+	})
+
+	tenant := *mock.DemoTenant
+	tenant.PasskeyMode = enum.PasskeyRequired
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(&tenant).
+		ExecutePost(handlers.SignInByEmail(), `{ "email": "arya.stark@got.com" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	ExpectHandler(&cmd.SaveVerificationKey{}).CalledTimes(0)
+}
+
 func TestVerifySignInKeyHandler_UnknownKey(t *testing.T) {
 	RegisterT(t)
 
@@ -587,6 +613,7 @@ func TestCompleteSignInProfileHandler_CorrectKey(t *testing.T) {
 	code, response := server.
 		OnTenant(mock.DemoTenant).
 		WithURL("http://demo.test.fider.io/signin/complete").
+		AddHeader("Accept-Language", "fr-FR,fr;q=0.9,en;q=0.8").
 		ExecutePost(handlers.CompleteSignInProfile(), fmt.Sprintf(`
 		{
 			"name": "Hot Pie",
@@ -595,6 +622,7 @@ func TestCompleteSignInProfileHandler_CorrectKey(t *testing.T) {
 		}`, enum.EmailVerificationKindSignIn, key))
 
 	Expect(code).Equals(http.StatusOK)
+	Expect(newUser.Locale).Equals("fr")
 	ExpectHandler(&cmd.SetKeyAsVerified{}).CalledOnce()
 	ExpectHandler(&query.GetVerificationByKey{}).CalledOnce()
 	ExpectHandler(&query.GetUserByEmail{}).CalledOnce()
@@ -642,6 +670,254 @@ func TestSignInPageHandler_PrivateTenant_UnauthenticatedUser(t *testing.T) {
 	Expect(code).Equals(http.StatusOK)
 }
 
+func TestVerifySignInKeyHandler_PrivateTenant_SignInRequest_AllowedEmailDomain(t *testing.T) {
+	RegisterT(t)
+
+	key := "1234567890"
+	bus.AddHandler(func(ctx context.Context, q *query.GetVerificationByKey) error {
+		expiresAt := time.Now().Add(5 * time.Minute)
+		q.Result = &entity.EmailVerification{
+			Key:       q.Key,
+			Kind:      q.Kind,
+			ExpiresAt: expiresAt,
+			Email:     "hot.pie@got.com",
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		return app.ErrNotFound
+	})
+
+	server := mock.NewServer()
+	mock.DemoTenant.IsPrivate = true
+	mock.DemoTenant.AllowedEmailDomains = []string{"got.com"}
+
+	code, page := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/signin/verify?k=" + key).
+		ExecuteAsPage(handlers.VerifySignInKey(enum.EmailVerificationKindSignIn))
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(page.Page).Equals("SignIn/CompleteSignInProfile.page")
+}
+
+func TestCompleteSignInProfileHandler_PrivateTenant(t *testing.T) {
+	RegisterT(t)
+
+	key := "1234567890"
+	bus.AddHandler(func(ctx context.Context, q *query.GetVerificationByKey) error {
+		expiresAt := time.Now().Add(5 * time.Minute)
+		q.Result = &entity.EmailVerification{
+			Key:       q.Key,
+			Kind:      q.Kind,
+			ExpiresAt: expiresAt,
+			Email:     "hot.pie@got.com",
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		return app.ErrNotFound
+	})
+
+	var newUser *entity.User
+	bus.AddHandler(func(ctx context.Context, c *cmd.RegisterUser) error {
+		c.User.ID = 10
+		newUser = c.User
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetKeyAsVerified) error {
+		return nil
+	})
+
+	body := fmt.Sprintf(`{ "name": "Hot Pie", "kind": %d, "key": "%s" }`, enum.EmailVerificationKindSignIn, key)
+
+	server := mock.NewServer()
+	mock.DemoTenant.IsPrivate = true
+	mock.DemoTenant.AllowedEmailDomains = []string{"nightswatch.org"}
+	mock.DemoTenant.AutoJoinRole = enum.RoleCollaborator
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/signin/complete").
+		ExecutePost(handlers.CompleteSignInProfile(), body)
+
+	Expect(code).Equals(http.StatusForbidden)
+	ExpectHandler(&cmd.RegisterUser{}).CalledTimes(0)
+
+	server = mock.NewServer()
+	mock.DemoTenant.IsPrivate = true
+	mock.DemoTenant.AllowedEmailDomains = []string{"nightswatch.org", "got.com"}
+	mock.DemoTenant.AutoJoinRole = enum.RoleCollaborator
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/signin/complete").
+		ExecutePost(handlers.CompleteSignInProfile(), body)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(newUser.Role).Equals(enum.RoleCollaborator)
+	ExpectFiderAuthCookie(response, newUser)
+}
+
+func TestSignInByLDAPHandler_Disabled(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		ExecutePost(handlers.SignInByLDAP(), `{ "username": "jon.snow", "password": "ghost" }`)
+
+	Expect(code).Equals(http.StatusForbidden)
+	ExpectHandler(&query.GetLDAPProfile{}).CalledTimes(0)
+}
+
+func TestSignInByLDAPHandler_InvalidCredentials(t *testing.T) {
+	RegisterT(t)
+	env.Config.LDAP.URL = "ldap://localhost"
+	defer func() { env.Config.LDAP.URL = "" }()
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetLDAPProfile) error {
+		return app.ErrNotFound
+	})
+
+	server := mock.NewSingleTenantServer()
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		ExecutePost(handlers.SignInByLDAP(), `{ "username": "jon.snow", "password": "wrong" }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	ExpectFiderAuthCookie(response, nil)
+}
+
+func TestSignInByLDAPHandler_NewUser(t *testing.T) {
+	RegisterT(t)
+	env.Config.LDAP.URL = "ldap://localhost"
+	defer func() { env.Config.LDAP.URL = "" }()
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetLDAPProfile) error {
+		Expect(q.Username).Equals("hot.pie")
+		Expect(q.Password).Equals("bread")
+		q.Result = &dto.LDAPUserProfile{ID: "hot.pie", Name: "Hot Pie", Email: "hot.pie@got.com"}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByProvider) error {
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		return app.ErrNotFound
+	})
+
+	var newUser *entity.User
+	bus.AddHandler(func(ctx context.Context, c *cmd.RegisterUser) error {
+		c.User.ID = 10
+		newUser = c.User
+		return nil
+	})
+
+	server := mock.NewSingleTenantServer()
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		ExecutePost(handlers.SignInByLDAP(), `{ "username": "hot.pie", "password": "bread" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(newUser.Name).Equals("Hot Pie")
+	Expect(newUser.Email).Equals("hot.pie@got.com")
+	Expect(newUser.Role).Equals(enum.RoleVisitor)
+	Expect(newUser.Providers[0].Name).Equals(app.LDAPProvider)
+	Expect(newUser.Providers[0].UID).Equals("hot.pie")
+	ExpectFiderAuthCookie(response, newUser)
+}
+
+func TestSignInByLDAPHandler_ExistingUser_SyncRole(t *testing.T) {
+	RegisterT(t)
+	env.Config.LDAP.URL = "ldap://localhost"
+	defer func() { env.Config.LDAP.URL = "" }()
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetLDAPProfile) error {
+		q.Result = &dto.LDAPUserProfile{ID: "arya.stark", Name: "Arya Stark", Email: mock.AryaStark.Email, Role: enum.RoleCollaborator}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByProvider) error {
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		user := *mock.AryaStark
+		q.Result = &user
+		return nil
+	})
+
+	var registerProvider *cmd.RegisterUserProvider
+	bus.AddHandler(func(ctx context.Context, c *cmd.RegisterUserProvider) error {
+		registerProvider = c
+		return nil
+	})
+
+	var changeRole *cmd.ChangeUserRole
+	bus.AddHandler(func(ctx context.Context, c *cmd.ChangeUserRole) error {
+		changeRole = c
+		return nil
+	})
+
+	server := mock.NewSingleTenantServer()
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		ExecutePost(handlers.SignInByLDAP(), `{ "username": "arya.stark", "password": "needle" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(registerProvider.UserID).Equals(mock.AryaStark.ID)
+	Expect(registerProvider.ProviderName).Equals(app.LDAPProvider)
+	Expect(registerProvider.ProviderUID).Equals("arya.stark")
+	Expect(changeRole.UserID).Equals(mock.AryaStark.ID)
+	Expect(changeRole.Role).Equals(enum.RoleCollaborator)
+	Expect(auditLog.Action).Equals(enum.AuditUserRoleChanged)
+	Expect(auditLog.TargetID).Equals("2")
+	Expect(auditLog.Before).Equals(entity.AuditValues{"role": enum.RoleVisitor})
+	Expect(auditLog.After).Equals(entity.AuditValues{"role": enum.RoleCollaborator})
+	ExpectHandler(&cmd.RegisterUser{}).CalledTimes(0)
+}
+
+func TestSignInByLDAPHandler_ExistingAdministrator_KeepsRole(t *testing.T) {
+	RegisterT(t)
+	env.Config.LDAP.URL = "ldap://localhost"
+	defer func() { env.Config.LDAP.URL = "" }()
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetLDAPProfile) error {
+		q.Result = &dto.LDAPUserProfile{ID: "jon.snow", Name: "Jon Snow", Email: mock.JonSnow.Email, Role: enum.RoleVisitor}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByProvider) error {
+		user := *mock.JonSnow
+		user.Providers = []*entity.UserProvider{{Name: app.LDAPProvider, UID: "jon.snow"}}
+		q.Result = &user
+		return nil
+	})
+
+	server := mock.NewSingleTenantServer()
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		ExecutePost(handlers.SignInByLDAP(), `{ "username": "jon.snow", "password": "ghost" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	ExpectHandler(&cmd.ChangeUserRole{}).CalledTimes(0)
+	ExpectHandler(&cmd.AddAuditLog{}).CalledTimes(0)
+	ExpectFiderAuthCookie(response, mock.JonSnow)
+}
+
 func ExpectFiderAuthCookie(response *httptest.ResponseRecorder, expected *entity.User) {
 	cookies := response.Header()["Set-Cookie"]
 	if expected == nil {
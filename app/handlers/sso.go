@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/web"
+	webutil "github.com/getfider/fider/app/pkg/web/util"
+)
+
+// SingleSignOn signs in the user described by a token signed with the tenant's SSO secret
+// Users are matched by external ID and then by email. New users are created and existing ones are updated
+func SingleSignOn() web.HandlerFunc {
+	return func(c *web.Context) error {
+		c.Response.Header().Add("X-Robots-Tag", "noindex")
+
+		if !c.Tenant().IsSSOEnabled() {
+			return c.NotFound()
+		}
+
+		claims, err := jwt.DecodeSSOClaims(c.QueryParam("token"), c.Tenant().SSOSecret)
+		if err != nil {
+			log.Warnf(c, "Invalid SSO token: @{Error}", dto.Props{"Error": err.Error()})
+			return c.Forbidden()
+		}
+
+		if problems := validateSSOClaims(c, claims); len(problems) > 0 {
+			log.Warnf(c, "Invalid SSO token claims: @{Problems}", dto.Props{"Problems": strings.Join(problems, " ")})
+			return c.Forbidden()
+		}
+
+		firstUse, err := useSSOToken(c, claims)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if !firstUse {
+			log.Warnf(c, "SSO token @{TokenID} was already used", dto.Props{"TokenID": claims.ID})
+			return c.Forbidden()
+		}
+
+		user, err := findSSOUser(c, claims)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		if user == nil {
+			user = &entity.User{
+				Name:   claims.Name,
+				Tenant: c.Tenant(),
+				Email:  claims.Email,
+				Role:   newUserRole(c.Tenant(), claims.Email),
+				Locale: newUserLocale(c),
+			}
+			if claims.ExternalID != "" {
+				user.Providers = []*entity.UserProvider{{Name: "reference", UID: claims.ExternalID}}
+			}
+
+			if err = bus.Dispatch(c, &cmd.RegisterUser{User: user}); err != nil {
+				return c.Failure(err)
+			}
+		} else {
+			if user.Status == enum.UserBlocked {
+				return c.Forbidden()
+			}
+
+			if err = updateSSOUser(c, user, claims); err != nil {
+				return c.Failure(err)
+			}
+		}
+
+		if err = setSSOOrganization(c, user, claims); err != nil {
+			return c.Failure(err)
+		}
+
+		webutil.AddAuthUserCookie(c, user)
+
+		return c.Redirect(ssoRedirect(c.QueryParam("redirect")))
+	}
+}
+
+func validateSSOClaims(c *web.Context, claims *jwt.SSOClaims) []string {
+	claims.Name = strings.TrimSpace(claims.Name)
+	claims.Email = strings.ToLower(strings.TrimSpace(claims.Email))
+	claims.ExternalID = strings.TrimSpace(claims.ExternalID)
+	claims.Organization = strings.TrimSpace(claims.Organization)
+	claims.OrganizationID = strings.TrimSpace(claims.OrganizationID)
+
+	problems := make([]string, 0)
+	if claims.Name == "" {
+		problems = append(problems, "Name is required.")
+	} else if len(claims.Name) > 100 {
+		problems = append(problems, "Name must have less than 100 characters.")
+	}
+
+	if claims.Email == "" && claims.ExternalID == "" {
+		problems = append(problems, "Either email or external ID is required.")
+	}
+
+	if claims.Email != "" {
+		problems = append(problems, validate.Email(c, claims.Email)...)
+	}
+
+	if len(claims.ExternalID) > 100 {
+		problems = append(problems, "External ID must have less than 100 characters.")
+	}
+
+	if len(claims.Organization) > 100 || len(claims.OrganizationID) > 100 {
+		problems = append(problems, "Organization must have less than 100 characters.")
+	}
+
+	return problems
+}
+
+// useSSOToken records the ID of given token for as long as it can be valid, so that each token signs a user in only once
+// It returns false when the token was already used
+func useSSOToken(c *web.Context, claims *jwt.SSOClaims) (bool, error) {
+	hit := &cmd.HitRateLimit{
+		Key:    fmt.Sprintf("%d:sso:%x", c.Tenant().ID, sha256.Sum256([]byte(claims.ID))),
+		Window: jwt.SSOMaxLifetime,
+	}
+	if err := bus.Dispatch(c, hit); err != nil {
+		return false, err
+	}
+	return hit.Result.Hits == 1, nil
+}
+
+// findSSOUser returns nil when there's no user with the external ID or email of given claims
+func findSSOUser(c *web.Context, claims *jwt.SSOClaims) (*entity.User, error) {
+	if claims.ExternalID != "" {
+		getByReference := &query.GetUserByProvider{Provider: "reference", UID: claims.ExternalID}
+		err := bus.Dispatch(c, getByReference)
+		if err == nil {
+			return getByReference.Result, nil
+		}
+		if errors.Cause(err) != app.ErrNotFound {
+			return nil, err
+		}
+	}
+
+	if claims.Email != "" {
+		getByEmail := &query.GetUserByEmail{Email: claims.Email}
+		err := bus.Dispatch(c, getByEmail)
+		if err == nil {
+			return getByEmail.Result, nil
+		}
+		if errors.Cause(err) != app.ErrNotFound {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+func updateSSOUser(c *web.Context, user *entity.User, claims *jwt.SSOClaims) error {
+	if claims.ExternalID != "" && !user.HasProvider("reference") {
+		if err := bus.Dispatch(c, &cmd.RegisterUserProvider{
+			UserID:       user.ID,
+			ProviderName: "reference",
+			ProviderUID:  claims.ExternalID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if claims.Name != user.Name {
+		if err := bus.Dispatch(c, &cmd.ChangeUserName{UserID: user.ID, Name: claims.Name}); err != nil {
+			return err
+		}
+		user.Name = claims.Name
+	}
+
+	if claims.Email != "" && claims.Email != user.Email {
+		// the email is only changed when it doesn't belong to any other user
+		getByEmail := &query.GetUserByEmail{Email: claims.Email}
+		err := bus.Dispatch(c, getByEmail)
+		if err == nil {
+			log.Warnf(c, "SSO email of user @{UserID} is already used by user @{OtherUserID}.", dto.Props{
+				"UserID":      user.ID,
+				"OtherUserID": getByEmail.Result.ID,
+			})
+		} else if errors.Cause(err) == app.ErrNotFound {
+			if err := bus.Dispatch(c, &cmd.ChangeUserEmail{UserID: user.ID, Email: claims.Email}); err != nil {
+				return err
+			}
+			user.Email = claims.Email
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setSSOOrganization assigns the user to the organization of given claims, which is created when it doesn't exist yet
+// Organizations are matched by external ID when given, otherwise by name
+func setSSOOrganization(c *web.Context, user *entity.User, claims *jwt.SSOClaims) error {
+	if claims.Organization == "" && claims.OrganizationID == "" {
+		return nil
+	}
+
+	allOrganizations := &query.GetAllOrganizations{}
+	if err := bus.Dispatch(c, allOrganizations); err != nil {
+		return err
+	}
+
+	var organization *entity.Organization
+	for _, o := range allOrganizations.Result {
+		if (claims.OrganizationID != "" && o.ExternalID == claims.OrganizationID) ||
+			(claims.OrganizationID == "" && strings.EqualFold(o.Name, claims.Organization)) {
+			organization = o
+			break
+		}
+	}
+
+	if organization == nil {
+		name := claims.Organization
+		if name == "" {
+			name = claims.OrganizationID
+		}
+
+		saveOrganization := &cmd.SaveOrganization{ExternalID: claims.OrganizationID, Name: name}
+		if err := bus.Dispatch(c, saveOrganization); err != nil {
+			return err
+		}
+		organization = saveOrganization.Result
+	}
+
+	return bus.Dispatch(c, &cmd.SetUserOrganization{UserID: user.ID, OrganizationID: organization.ID})
+}
+
+// ssoRedirect only allows redirects to a path of current site
+func ssoRedirect(redirect string) string {
+	if strings.HasPrefix(redirect, "/") && !strings.HasPrefix(redirect, "//") && !strings.HasPrefix(redirect, "/\\") {
+		return redirect
+	}
+	return "/"
+}
+
+// RegenerateSSOSecret creates a new secret for single sign-on tokens, invalidating the previous one
+func RegenerateSSOSecret() web.HandlerFunc {
+	return func(c *web.Context) error {
+		before := entity.AuditValues{"isSSOEnabled": c.Tenant().IsSSOEnabled()}
+		regenerate := &cmd.RegenerateSSOSecret{}
+		if err := bus.Dispatch(c, regenerate); err != nil {
+			return c.Failure(err)
+		}
+
+		err := auditSettings(c, "sso", before, entity.AuditValues{"isSSOEnabled": true, "secretChanged": true})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{
+			"secret": regenerate.Result,
+		})
+	}
+}
+
+// DisableSSO removes the secret for single sign-on tokens, so that they are no longer accepted
+func DisableSSO() web.HandlerFunc {
+	return func(c *web.Context) error {
+		before := entity.AuditValues{"isSSOEnabled": c.Tenant().IsSSOEnabled()}
+		if err := bus.Dispatch(c, &cmd.DisableSSO{}); err != nil {
+			return c.Failure(err)
+		}
+
+		err := auditSettings(c, "sso", before, entity.AuditValues{"isSSOEnabled": false})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
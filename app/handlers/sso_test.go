@@ -0,0 +1,325 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/rand"
+	jwtgo "github.com/golang-jwt/jwt/v4"
+)
+
+func ssoTenant() *entity.Tenant {
+	tenant := *mock.DemoTenant
+	tenant.SSOSecret = "my-sso-secret"
+	return &tenant
+}
+
+func ssoToken(claims jwt.SSOClaims) string {
+	if claims.IssuedAt == nil {
+		claims.IssuedAt = jwt.Time(time.Now())
+	}
+	if claims.ExpiresAt == nil {
+		claims.ExpiresAt = jwt.Time(time.Now().Add(5 * time.Minute))
+	}
+	if claims.ID == "" {
+		claims.ID = rand.String(16)
+	}
+	token, _ := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, claims).SignedString([]byte("my-sso-secret"))
+	return token
+}
+
+// mockRateLimitHits counts how many times each rate limit key was hit, such as when SSO tokens or passkey challenges are used
+func mockRateLimitHits() {
+	uses := make(map[string]int)
+	bus.AddHandler(func(ctx context.Context, c *cmd.HitRateLimit) error {
+		uses[c.Key]++
+		c.Result.Hits = uses[c.Key]
+		return nil
+	})
+}
+
+func TestSingleSignOnHandler_Disabled(t *testing.T) {
+	RegisterT(t)
+
+	token := ssoToken(jwt.SSOClaims{Name: "Jon Snow", Email: "jon.snow@got.com"})
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso?token=" + token).
+		Execute(handlers.SingleSignOn())
+
+	Expect(code).Equals(http.StatusNotFound)
+	ExpectFiderAuthCookie(response, nil)
+}
+
+func TestSingleSignOnHandler_InvalidToken(t *testing.T) {
+	RegisterT(t)
+
+	expired := ssoToken(jwt.SSOClaims{
+		Name:     "Jon Snow",
+		Email:    "jon.snow@got.com",
+		Metadata: jwt.Metadata{ExpiresAt: jwt.Time(time.Now().Add(-1 * time.Minute))},
+	})
+	noIssueTime, _ := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, jwt.SSOClaims{
+		Name:     "Jon Snow",
+		Email:    "jon.snow@got.com",
+		Metadata: jwt.Metadata{ID: "token-1", ExpiresAt: jwt.Time(time.Now().Add(5 * time.Minute))},
+	}).SignedString([]byte("my-sso-secret"))
+	noID, _ := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, jwt.SSOClaims{
+		Name:     "Jon Snow",
+		Email:    "jon.snow@got.com",
+		Metadata: jwt.Metadata{IssuedAt: jwt.Time(time.Now()), ExpiresAt: jwt.Time(time.Now().Add(5 * time.Minute))},
+	}).SignedString([]byte("my-sso-secret"))
+	otherSecret, _ := jwt.Encode(jwt.SSOClaims{
+		Name:     "Jon Snow",
+		Email:    "jon.snow@got.com",
+		Metadata: jwt.Metadata{ExpiresAt: jwt.Time(time.Now().Add(5 * time.Minute))},
+	})
+
+	for _, token := range []string{
+		"",
+		"invalid",
+		expired,
+		otherSecret,
+		ssoToken(jwt.SSOClaims{Email: "jon.snow@got.com"}),
+		ssoToken(jwt.SSOClaims{Name: "Jon Snow"}),
+		ssoToken(jwt.SSOClaims{Name: "Jon Snow", Email: "not-an-email"}),
+		ssoToken(jwt.SSOClaims{
+			Name:     "Jon Snow",
+			Email:    "jon.snow@got.com",
+			Metadata: jwt.Metadata{IssuedAt: jwt.Time(time.Now()), ExpiresAt: jwt.Time(time.Now().Add(time.Hour))},
+		}),
+		noIssueTime,
+		noID,
+	} {
+		code, response := mock.NewServer().
+			OnTenant(ssoTenant()).
+			WithURL("http://demo.test.fider.io/sso?token=" + token).
+			Execute(handlers.SingleSignOn())
+
+		Expect(code).Equals(http.StatusForbidden)
+		ExpectFiderAuthCookie(response, nil)
+	}
+	ExpectHandler(&cmd.RegisterUser{}).CalledTimes(0)
+}
+
+func TestSingleSignOnHandler_NewUser(t *testing.T) {
+	RegisterT(t)
+	mockRateLimitHits()
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByProvider) error {
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		return app.ErrNotFound
+	})
+
+	var registeredUser *entity.User
+	bus.AddHandler(func(ctx context.Context, c *cmd.RegisterUser) error {
+		c.User.ID = 10
+		registeredUser = c.User
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetAllOrganizations) error {
+		q.Result = []*entity.Organization{{ID: 4, ExternalID: "org-1", Name: "Night's Watch"}}
+		return nil
+	})
+
+	var setOrganization *cmd.SetUserOrganization
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetUserOrganization) error {
+		setOrganization = c
+		return nil
+	})
+
+	token := ssoToken(jwt.SSOClaims{
+		Name:           "Samwell Tarly",
+		Email:          "Samwell.Tarly@got.com",
+		ExternalID:     "sam-1",
+		Organization:   "The Watch",
+		OrganizationID: "org-1",
+	})
+	code, response := mock.NewServer().
+		OnTenant(ssoTenant()).
+		WithURL("http://demo.test.fider.io/sso?redirect=/posts/1&token=" + token).
+		Execute(handlers.SingleSignOn())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("/posts/1")
+	Expect(registeredUser.Name).Equals("Samwell Tarly")
+	Expect(registeredUser.Email).Equals("samwell.tarly@got.com")
+	Expect(registeredUser.Role).Equals(enum.RoleVisitor)
+	Expect(registeredUser.HasProvider("reference")).IsTrue()
+	Expect(setOrganization.UserID).Equals(10)
+	Expect(setOrganization.OrganizationID).Equals(4)
+	ExpectHandler(&cmd.SaveOrganization{}).CalledTimes(0)
+	ExpectFiderAuthCookie(response, registeredUser)
+}
+
+func TestSingleSignOnHandler_ExistingUser(t *testing.T) {
+	RegisterT(t)
+	mockRateLimitHits()
+
+	existing := &entity.User{
+		ID:     10,
+		Name:   "Sam",
+		Email:  "sam@got.com",
+		Tenant: mock.DemoTenant,
+		Role:   enum.RoleCollaborator,
+		Status: enum.UserActive,
+		Providers: []*entity.UserProvider{
+			{Name: "reference", UID: "sam-1"},
+		},
+	}
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByProvider) error {
+		if q.Provider == "reference" && q.UID == "sam-1" {
+			q.Result = existing
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		return app.ErrNotFound
+	})
+
+	var changeName *cmd.ChangeUserName
+	bus.AddHandler(func(ctx context.Context, c *cmd.ChangeUserName) error {
+		changeName = c
+		return nil
+	})
+
+	var changeEmail *cmd.ChangeUserEmail
+	bus.AddHandler(func(ctx context.Context, c *cmd.ChangeUserEmail) error {
+		changeEmail = c
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetAllOrganizations) error {
+		q.Result = []*entity.Organization{}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.SaveOrganization) error {
+		c.Result = &entity.Organization{ID: 5, Name: c.Name}
+		return nil
+	})
+
+	var setOrganization *cmd.SetUserOrganization
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetUserOrganization) error {
+		setOrganization = c
+		return nil
+	})
+
+	token := ssoToken(jwt.SSOClaims{
+		Name:         "Samwell Tarly",
+		Email:        "samwell.tarly@got.com",
+		ExternalID:   "sam-1",
+		Organization: "Night's Watch",
+	})
+	code, response := mock.NewServer().
+		OnTenant(ssoTenant()).
+		WithURL("http://demo.test.fider.io/sso?redirect=//evil.com&token=" + token).
+		Execute(handlers.SingleSignOn())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("/")
+	Expect(changeName.UserID).Equals(10)
+	Expect(changeName.Name).Equals("Samwell Tarly")
+	Expect(changeEmail.UserID).Equals(10)
+	Expect(changeEmail.Email).Equals("samwell.tarly@got.com")
+	Expect(setOrganization.OrganizationID).Equals(5)
+	Expect(existing.Role).Equals(enum.RoleCollaborator)
+	ExpectHandler(&cmd.RegisterUser{}).CalledTimes(0)
+	ExpectHandler(&cmd.RegisterUserProvider{}).CalledTimes(0)
+	ExpectFiderAuthCookie(response, existing)
+}
+
+func TestSingleSignOnHandler_BlockedUser(t *testing.T) {
+	RegisterT(t)
+	mockRateLimitHits()
+
+	blocked := &entity.User{ID: 10, Name: "Sam", Email: "sam@got.com", Tenant: mock.DemoTenant, Status: enum.UserBlocked}
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		q.Result = blocked
+		return nil
+	})
+
+	token := ssoToken(jwt.SSOClaims{Name: "Sam", Email: "sam@got.com"})
+	code, response := mock.NewServer().
+		OnTenant(ssoTenant()).
+		WithURL("http://demo.test.fider.io/sso?token=" + token).
+		Execute(handlers.SingleSignOn())
+
+	Expect(code).Equals(http.StatusForbidden)
+	ExpectFiderAuthCookie(response, nil)
+}
+
+func TestSingleSignOnHandler_ReplayedToken(t *testing.T) {
+	RegisterT(t)
+	mockRateLimitHits()
+
+	existing := &entity.User{ID: 10, Name: "Sam", Email: "sam@got.com", Tenant: mock.DemoTenant, Status: enum.UserActive}
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		q.Result = existing
+		return nil
+	})
+
+	token := ssoToken(jwt.SSOClaims{Name: "Sam", Email: "sam@got.com"})
+	code, response := mock.NewServer().
+		OnTenant(ssoTenant()).
+		WithURL("http://demo.test.fider.io/sso?token=" + token).
+		Execute(handlers.SingleSignOn())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	ExpectFiderAuthCookie(response, existing)
+
+	code, response = mock.NewServer().
+		OnTenant(ssoTenant()).
+		WithURL("http://demo.test.fider.io/sso?token=" + token).
+		Execute(handlers.SingleSignOn())
+
+	Expect(code).Equals(http.StatusForbidden)
+	ExpectFiderAuthCookie(response, nil)
+}
+
+func TestRegenerateSSOSecretHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.RegenerateSSOSecret) error {
+		c.Result = "new-secret"
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	code, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePostAsJSON(handlers.RegenerateSSOSecret(), `{}`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(query.String("secret")).Equals("new-secret")
+	Expect(auditLog.Action).Equals(enum.AuditSettingsUpdated)
+	Expect(auditLog.TargetID).Equals("sso")
+	Expect(auditLog.After["isSSOEnabled"]).IsTrue()
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// unsubscription is what a signed unsubscribe link of a notification email refers to
+type unsubscription struct {
+	user  *entity.User
+	post  *entity.Post
+	event *enum.NotificationEvent
+}
+
+// getUnsubscription validates the token of an unsubscribe link and returns nil when it's invalid, expired or from another tenant
+func getUnsubscription(c *web.Context) (*unsubscription, error) {
+	claims, err := jwt.DecodeUnsubscribeClaims(c.Param("token"))
+	if err != nil || claims.TenantID != c.Tenant().ID {
+		return nil, nil
+	}
+
+	getUser := &query.GetUserByID{UserID: claims.UserID}
+	if err := bus.Dispatch(c, getUser); err != nil {
+		if errors.Cause(err) == app.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if getUser.Result.Tenant == nil || getUser.Result.Tenant.ID != claims.TenantID {
+		return nil, nil
+	}
+
+	result := &unsubscription{user: getUser.Result}
+	if claims.PostNumber > 0 {
+		getPost := &query.GetPostByNumber{Number: claims.PostNumber}
+		if err := bus.Dispatch(c, getPost); err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return nil, nil
+			}
+			return nil, err
+		}
+		result.post = getPost.Result
+		return result, nil
+	}
+
+	for i, event := range enum.AllNotificationEvents {
+		if event.UserSettingsKeyName == claims.Event {
+			result.event = &enum.AllNotificationEvents[i]
+			return result, nil
+		}
+	}
+	return nil, nil
+}
+
+// UnsubscribePage asks for confirmation before unsubscribing through the link of a notification email
+func UnsubscribePage() web.HandlerFunc {
+	return func(c *web.Context) error {
+		unsubscription, err := getUnsubscription(c)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if unsubscription == nil {
+			return c.NotFound()
+		}
+
+		data := web.Map{
+			"token": c.Param("token"),
+		}
+		if unsubscription.post != nil {
+			data["post"] = web.Map{
+				"number": unsubscription.post.Number,
+				"title":  unsubscription.post.Title,
+				"slug":   unsubscription.post.Slug,
+			}
+		} else {
+			data["event"] = unsubscription.event.UserSettingsKeyName
+		}
+
+		return c.Page(http.StatusOK, web.Props{
+			Page:  "Unsubscribe/Unsubscribe.page",
+			Title: "Unsubscribe",
+			Data:  data,
+		})
+	}
+}
+
+// Unsubscribe stops the email notifications of an unsubscribe link without signing in
+// Mail clients post to it directly when the one-click List-Unsubscribe header is used (RFC 8058)
+func Unsubscribe() web.HandlerFunc {
+	return func(c *web.Context) error {
+		unsubscription, err := getUnsubscription(c)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if unsubscription == nil {
+			return c.NotFound()
+		}
+
+		if unsubscription.post != nil {
+			err := bus.Dispatch(c, &cmd.RemoveSubscriber{Post: unsubscription.post, User: unsubscription.user})
+			if err != nil {
+				return c.Failure(err)
+			}
+			return c.Ok(web.Map{})
+		}
+
+		// User settings are read and written on behalf of the current user
+		c.Set(app.UserCtxKey, unsubscription.user)
+
+		getSettings := &query.GetCurrentUserSettings{}
+		if err := bus.Dispatch(c, getSettings); err != nil {
+			return c.Failure(err)
+		}
+
+		key := unsubscription.event.UserSettingsKeyName
+		channels, _ := strconv.Atoi(getSettings.Result[key])
+		channels &^= int(enum.NotificationChannelEmail)
+
+		err = bus.Dispatch(c, &cmd.UpdateCurrentUserSettings{
+			Settings: map[string]string{key: strconv.Itoa(channels)},
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
@@ -0,0 +1,163 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func unsubscribeToken(claims jwt.UnsubscribeClaims) string {
+	claims.Metadata = jwt.Metadata{
+		ExpiresAt: jwt.Time(time.Now().Add(time.Hour)),
+	}
+	token, err := jwt.Encode(claims)
+	Expect(err).IsNil()
+	return token
+}
+
+func setupUnsubscribe(post *entity.Post) {
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		if q.UserID == mock.AryaStark.ID {
+			q.Result = mock.AryaStark
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		if post != nil && q.Number == post.Number {
+			q.Result = post
+			return nil
+		}
+		return app.ErrNotFound
+	})
+}
+
+func TestUnsubscribePage_Post(t *testing.T) {
+	RegisterT(t)
+
+	post := &entity.Post{ID: 1, Number: 1, Title: "Add dark mode", Slug: "add-dark-mode"}
+	setupUnsubscribe(post)
+
+	token := unsubscribeToken(jwt.UnsubscribeClaims{TenantID: mock.DemoTenant.ID, UserID: mock.AryaStark.ID, PostNumber: post.Number})
+
+	server := mock.NewServer()
+	code, page := server.
+		OnTenant(mock.DemoTenant).
+		AddParam("token", token).
+		ExecuteAsPage(handlers.UnsubscribePage())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(page.Page).Equals("Unsubscribe/Unsubscribe.page")
+	Expect(page.Data["token"]).Equals(token)
+	Expect(page.Data["post"]).Equals(map[string]any{
+		"number": float64(1),
+		"title":  "Add dark mode",
+		"slug":   "add-dark-mode",
+	})
+}
+
+func TestUnsubscribePage_InvalidToken(t *testing.T) {
+	RegisterT(t)
+	setupUnsubscribe(nil)
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AddParam("token", "abc").
+		Execute(handlers.UnsubscribePage())
+
+	Expect(code).Equals(http.StatusNotFound)
+}
+
+func TestUnsubscribeHandler_Post(t *testing.T) {
+	RegisterT(t)
+
+	post := &entity.Post{ID: 1, Number: 1, Title: "Add dark mode", Slug: "add-dark-mode"}
+	setupUnsubscribe(post)
+
+	var removeSubscriber *cmd.RemoveSubscriber
+	bus.AddHandler(func(ctx context.Context, c *cmd.RemoveSubscriber) error {
+		removeSubscriber = c
+		return nil
+	})
+
+	token := unsubscribeToken(jwt.UnsubscribeClaims{TenantID: mock.DemoTenant.ID, UserID: mock.AryaStark.ID, PostNumber: post.Number})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AddParam("token", token).
+		ExecutePost(handlers.Unsubscribe(), "List-Unsubscribe=One-Click")
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(removeSubscriber).IsNotNil()
+	Expect(removeSubscriber.Post).Equals(post)
+	Expect(removeSubscriber.User).Equals(mock.AryaStark)
+}
+
+func TestUnsubscribeHandler_Event(t *testing.T) {
+	RegisterT(t)
+	setupUnsubscribe(nil)
+
+	var settingsUser *entity.User
+	bus.AddHandler(func(ctx context.Context, q *query.GetCurrentUserSettings) error {
+		settingsUser, _ = ctx.Value(app.UserCtxKey).(*entity.User)
+		q.Result = map[string]string{"event_notification_new_post": "7"}
+		return nil
+	})
+
+	var updateSettings *cmd.UpdateCurrentUserSettings
+	bus.AddHandler(func(ctx context.Context, c *cmd.UpdateCurrentUserSettings) error {
+		updateSettings = c
+		return nil
+	})
+
+	token := unsubscribeToken(jwt.UnsubscribeClaims{TenantID: mock.DemoTenant.ID, UserID: mock.AryaStark.ID, Event: "event_notification_new_post"})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AddParam("token", token).
+		ExecutePost(handlers.Unsubscribe(), "")
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(settingsUser).Equals(mock.AryaStark)
+	Expect(updateSettings).IsNotNil()
+	Expect(updateSettings.Settings).Equals(map[string]string{"event_notification_new_post": "5"})
+}
+
+func TestUnsubscribeHandler_OtherTenant(t *testing.T) {
+	RegisterT(t)
+
+	post := &entity.Post{ID: 1, Number: 1, Title: "Add dark mode", Slug: "add-dark-mode"}
+	setupUnsubscribe(post)
+
+	var removeSubscriber *cmd.RemoveSubscriber
+	bus.AddHandler(func(ctx context.Context, c *cmd.RemoveSubscriber) error {
+		removeSubscriber = c
+		return nil
+	})
+
+	token := unsubscribeToken(jwt.UnsubscribeClaims{TenantID: mock.DemoTenant.ID, UserID: mock.AryaStark.ID, PostNumber: post.Number})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.AvengersTenant).
+		AddParam("token", token).
+		ExecutePost(handlers.Unsubscribe(), "")
+
+	Expect(code).Equals(http.StatusNotFound)
+	Expect(removeSubscriber).IsNil()
+}
@@ -1,9 +1,20 @@
 package handlers
 
 import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/getfider/fider/app/actions"
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/backup"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/web"
+	webutil "github.com/getfider/fider/app/pkg/web/util"
+	"github.com/getfider/fider/app/tasks"
 )
 
 // BlockUser is used to block an existing user from using Fider
@@ -19,10 +30,100 @@ func BlockUser() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditUserBlocked,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(userID),
+			Before:     entity.AuditValues{"status": enum.UserActive},
+			After:      entity.AuditValues{"status": enum.UserBlocked},
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		return c.Ok(web.Map{})
 	}
 }
 
+// UserProfile shows the public profile and recent activity of given user
+func UserProfile() web.HandlerFunc {
+	return func(c *web.Context) error {
+		userID, err := c.ParamAsInt("userID")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		getProfile := &query.GetUserProfile{UserID: userID}
+		if err := bus.Dispatch(c, getProfile); err != nil {
+			return c.Failure(err)
+		}
+
+		if !getProfile.Result.IsVisibleTo(c.User()) {
+			return c.NotFound()
+		}
+
+		getActivity := &query.GetUserActivity{UserID: userID}
+		if err := bus.Dispatch(c, getActivity); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Page(http.StatusOK, web.Props{
+			Page:        "UserProfile/UserProfile.page",
+			Title:       getProfile.Result.User.Name,
+			Description: getProfile.Result.Bio,
+			Data: web.Map{
+				"profile":  getProfile.Result,
+				"activity": getActivity.Result,
+			},
+		})
+	}
+}
+
+// HideUserProfile is used to hide the public profile of an user from other users
+func HideUserProfile() web.HandlerFunc {
+	return func(c *web.Context) error {
+		return setUserProfileVisibility(c, true)
+	}
+}
+
+// ShowUserProfile is used to make the public profile of an user visible again
+func ShowUserProfile() web.HandlerFunc {
+	return func(c *web.Context) error {
+		return setUserProfileVisibility(c, false)
+	}
+}
+
+func setUserProfileVisibility(c *web.Context, hidden bool) error {
+	userID, err := c.ParamAsInt("userID")
+	if err != nil {
+		return c.NotFound()
+	}
+
+	action := enum.AuditUserProfileShown
+	var visibility bus.Msg = &cmd.ShowUserProfile{UserID: userID}
+	if hidden {
+		action = enum.AuditUserProfileHidden
+		visibility = &cmd.HideUserProfile{UserID: userID}
+	}
+
+	if err := bus.Dispatch(c, visibility); err != nil {
+		return c.Failure(err)
+	}
+
+	err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+		Action:     action,
+		TargetType: "user",
+		TargetID:   strconv.Itoa(userID),
+		Before:     entity.AuditValues{"profileHidden": !hidden},
+		After:      entity.AuditValues{"profileHidden": hidden},
+	})
+	if err != nil {
+		return c.Failure(err)
+	}
+
+	return c.Ok(web.Map{})
+}
+
 // UnblockUser is used to unblock an existing user so they can use Fider again
 func UnblockUser() web.HandlerFunc {
 	return func(c *web.Context) error {
@@ -36,6 +137,204 @@ func UnblockUser() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditUserUnblocked,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(userID),
+			Before:     entity.AuditValues{"status": enum.UserBlocked},
+			After:      entity.AuditValues{"status": enum.UserActive},
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// SuspendUser is used by collaborators to prevent an existing user from posting and commenting for some time
+// Users on read-only mode are still allowed to vote
+func SuspendUser() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.SuspendUser)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := bus.Dispatch(c, &cmd.SuspendUser{
+			UserID:   action.UserID,
+			Reason:   action.Reason,
+			ReadOnly: action.ReadOnly,
+			EndsAt:   action.EndsAt,
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditUserSuspended,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(action.UserID),
+			After: entity.AuditValues{
+				"reason":   action.Reason,
+				"readOnly": action.ReadOnly,
+				"endsAt":   action.EndsAt,
+			},
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		return c.Ok(web.Map{})
 	}
 }
+
+// LiftUserSuspension is used to lift the active suspension of an existing user before it expires
+func LiftUserSuspension() web.HandlerFunc {
+	return func(c *web.Context) error {
+		userID, err := c.ParamAsInt("userID")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		err = bus.Dispatch(c, &cmd.LiftUserSuspension{UserID: userID})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditUserSuspensionLifted,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(userID),
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// EraseUser is used by administrators to erase the personal data of an existing user
+// Content published by the user is either anonymized or deleted
+func EraseUser() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.EraseUser)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		eraseUser := &cmd.EraseUser{
+			UserID:        action.UserID,
+			DeleteContent: action.DeleteContent,
+		}
+		if err := bus.Dispatch(c, eraseUser); err != nil {
+			return c.Failure(err)
+		}
+
+		err := webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditUserErased,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(action.UserID),
+			Before: entity.AuditValues{
+				"role":   action.User.Role,
+				"status": action.User.Status,
+			},
+			After: entity.AuditValues{
+				"status":        enum.UserDeleted,
+				"deleteContent": action.DeleteContent,
+			},
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		if len(eraseUser.Result) > 0 {
+			c.Enqueue(tasks.DeleteErasedUserBlobs(eraseUser.Result))
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// StartImpersonation signs current administrator in as another user for a limited time
+func StartImpersonation() web.HandlerFunc {
+	return func(c *web.Context) error {
+		action := new(actions.StartImpersonation)
+		if result := c.BindTo(action); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditImpersonationStarted,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(action.User.ID),
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		if err := webutil.AddImpersonationCookie(c, action.User, c.User()); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// StopImpersonation signs the administrator that is impersonating current user back in
+func StopImpersonation() web.HandlerFunc {
+	return func(c *web.Context) error {
+		impersonator := c.Impersonator()
+		if impersonator == nil {
+			return c.BadRequest(web.Map{})
+		}
+
+		err := webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditImpersonationStopped,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(c.User().ID),
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		webutil.AddAuthUserCookie(c, impersonator)
+		return c.Ok(web.Map{})
+	}
+}
+
+// ExportUserData returns a Zip file with all the data tied to an existing user
+func ExportUserData() web.HandlerFunc {
+	return func(c *web.Context) error {
+		userID, err := c.ParamAsInt("userID")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		getUser := &query.GetUserByID{UserID: userID}
+		if err := bus.Dispatch(c, getUser); err != nil {
+			return c.Failure(err)
+		}
+
+		if getUser.Result.Tenant.ID != c.Tenant().ID {
+			return c.NotFound()
+		}
+
+		file, err := backup.CreateUserArchive(c, getUser.Result)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditUserExported,
+			TargetType: "user",
+			TargetID:   strconv.Itoa(userID),
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Attachment(fmt.Sprintf("user-%d.zip", userID), "application/zip", file.Bytes())
+	}
+}
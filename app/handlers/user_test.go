@@ -0,0 +1,353 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+func TestBlockUserHandler(t *testing.T) {
+	RegisterT(t)
+
+	var blockUser *cmd.BlockUser
+	bus.AddHandler(func(ctx context.Context, c *cmd.BlockUser) error {
+		blockUser = c
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", mock.AryaStark.ID).
+		Execute(handlers.BlockUser())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(blockUser.UserID).Equals(mock.AryaStark.ID)
+	Expect(auditLog.Action).Equals(enum.AuditUserBlocked)
+	Expect(auditLog.TargetType).Equals("user")
+	Expect(auditLog.Before).Equals(entity.AuditValues{"status": enum.UserActive})
+	Expect(auditLog.After).Equals(entity.AuditValues{"status": enum.UserBlocked})
+}
+
+func TestUserProfileHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserProfile) error {
+		q.Result = &entity.UserProfile{User: mock.AryaStark, Bio: "A girl has no name"}
+		return nil
+	})
+
+	var getActivity *query.GetUserActivity
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserActivity) error {
+		getActivity = q
+		q.Result = &entity.UserActivity{}
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AddParam("userID", mock.AryaStark.ID).
+		Execute(handlers.UserProfile())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(getActivity.UserID).Equals(mock.AryaStark.ID)
+}
+
+func TestUserProfileHandler_Hidden(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserProfile) error {
+		q.Result = &entity.UserProfile{User: mock.AryaStark, IsHidden: true}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserActivity) error {
+		q.Result = &entity.UserActivity{}
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AddParam("userID", mock.AryaStark.ID).
+		Execute(handlers.UserProfile())
+	Expect(code).Equals(http.StatusNotFound)
+
+	code, _ = mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("userID", mock.AryaStark.ID).
+		Execute(handlers.UserProfile())
+	Expect(code).Equals(http.StatusOK)
+
+	code, _ = mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", mock.AryaStark.ID).
+		Execute(handlers.UserProfile())
+	Expect(code).Equals(http.StatusOK)
+}
+
+func TestHideUserProfileHandler(t *testing.T) {
+	RegisterT(t)
+
+	var hideProfile *cmd.HideUserProfile
+	bus.AddHandler(func(ctx context.Context, c *cmd.HideUserProfile) error {
+		hideProfile = c
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", mock.AryaStark.ID).
+		Execute(handlers.HideUserProfile())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(hideProfile.UserID).Equals(mock.AryaStark.ID)
+	Expect(auditLog.Action).Equals(enum.AuditUserProfileHidden)
+	Expect(auditLog.Before).Equals(entity.AuditValues{"profileHidden": false})
+	Expect(auditLog.After).Equals(entity.AuditValues{"profileHidden": true})
+}
+
+func TestEraseUserHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		q.Result = mock.AryaStark
+		return nil
+	})
+
+	var eraseUser *cmd.EraseUser
+	bus.AddHandler(func(ctx context.Context, c *cmd.EraseUser) error {
+		eraseUser = c
+		c.Result = []string{"avatars/arya.png", "attachments/needle.png"}
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", mock.AryaStark.ID).
+		ExecutePost(handlers.EraseUser(), `{ "deleteContent": true }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(eraseUser.UserID).Equals(mock.AryaStark.ID)
+	Expect(eraseUser.DeleteContent).IsTrue()
+	ExpectHandler(&cmd.DeleteBlob{}).CalledTimes(0)
+	Expect(auditLog.Action).Equals(enum.AuditUserErased)
+	Expect(auditLog.After["deleteContent"]).Equals(true)
+}
+
+func TestEraseUserHandler_Self(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", mock.JonSnow.ID).
+		ExecutePost(handlers.EraseUser(), `{ "deleteContent": false }`)
+
+	Expect(code).Equals(http.StatusForbidden)
+	ExpectHandler(&cmd.EraseUser{}).CalledTimes(0)
+}
+
+func TestExportUserDataHandler_OtherTenant(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		q.Result = &entity.User{ID: q.UserID, Tenant: mock.AvengersTenant}
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", 999).
+		Execute(handlers.ExportUserData())
+
+	Expect(code).Equals(http.StatusNotFound)
+}
+
+func TestStartImpersonationHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		q.Result = mock.AryaStark
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", mock.AryaStark.ID).
+		ExecutePost(handlers.StartImpersonation(), "")
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(auditLog.Action).Equals(enum.AuditImpersonationStarted)
+	Expect(auditLog.TargetID).Equals(strconv.Itoa(mock.AryaStark.ID))
+
+	cookie := web.ParseCookie(response.Header().Get("Set-Cookie"))
+	Expect(cookie.Name).Equals(web.CookieAuthName)
+	Expect(cookie.Expires).TemporarilySimilar(time.Now().Add(30*time.Minute), 5*time.Second)
+	claims, err := jwt.DecodeFiderClaims(cookie.Value)
+	Expect(err).IsNil()
+	Expect(claims.UserID).Equals(mock.AryaStark.ID)
+	Expect(claims.ImpersonatorID).Equals(mock.JonSnow.ID)
+}
+
+func TestStartImpersonationHandler_Administrator(t *testing.T) {
+	RegisterT(t)
+
+	otherAdmin := &entity.User{ID: 5, Name: "Eddard Stark", Tenant: mock.DemoTenant, Role: enum.RoleAdministrator, Status: enum.UserActive}
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		q.Result = otherAdmin
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", otherAdmin.ID).
+		ExecutePost(handlers.StartImpersonation(), "")
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
+
+func TestStopImpersonationHandler(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ImpersonatedBy(mock.JonSnow).
+		ExecutePost(handlers.StopImpersonation(), "")
+
+	Expect(code).Equals(http.StatusOK)
+	ExpectFiderAuthCookie(response, mock.JonSnow)
+}
+
+func TestStopImpersonationHandler_NotImpersonating(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.StopImpersonation(), "")
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
+
+func TestSuspendUserHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		q.Result = mock.AryaStark
+		return nil
+	})
+
+	var suspendUser *cmd.SuspendUser
+	bus.AddHandler(func(ctx context.Context, c *cmd.SuspendUser) error {
+		suspendUser = c
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", mock.AryaStark.ID).
+		ExecutePost(handlers.SuspendUser(), `{ "reason": "Spamming", "readOnly": true, "days": 7 }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(suspendUser.UserID).Equals(mock.AryaStark.ID)
+	Expect(suspendUser.Reason).Equals("Spamming")
+	Expect(suspendUser.ReadOnly).IsTrue()
+	Expect(*suspendUser.EndsAt).TemporarilySimilar(time.Now().AddDate(0, 0, 7), time.Minute)
+	Expect(auditLog.Action).Equals(enum.AuditUserSuspended)
+	Expect(auditLog.After["reason"]).Equals("Spamming")
+}
+
+func TestLiftUserSuspensionHandler(t *testing.T) {
+	RegisterT(t)
+
+	var liftSuspension *cmd.LiftUserSuspension
+	bus.AddHandler(func(ctx context.Context, c *cmd.LiftUserSuspension) error {
+		liftSuspension = c
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("userID", mock.AryaStark.ID).
+		Execute(handlers.LiftUserSuspension())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(liftSuspension.UserID).Equals(mock.AryaStark.ID)
+	Expect(auditLog.Action).Equals(enum.AuditUserSuspensionLifted)
+}
@@ -2,14 +2,18 @@ package handlers
 
 import (
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 
 	"github.com/getfider/fider/app/actions"
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/web"
+	webutil "github.com/getfider/fider/app/pkg/web/util"
 )
 
 // ManageWebhooks is the page used by administrators to configure webhooks
@@ -51,6 +55,16 @@ func CreateWebhook() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		err := webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditWebhookCreated,
+			TargetType: "webhook",
+			TargetID:   strconv.Itoa(createWebhook.Result),
+			After:      webhookAuditValues(createWebhook),
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		return c.Ok(web.Map{"id": createWebhook.Result})
 	}
 }
@@ -81,7 +95,20 @@ func UpdateWebhook() web.HandlerFunc {
 		if action.Status == enum.WebhookFailed {
 			updateWebhook.Status = enum.WebhookDisabled
 		}
-		if err := bus.Dispatch(c, updateWebhook); err != nil {
+
+		getWebhook := &query.GetWebhook{ID: id}
+		if err := bus.Dispatch(c, getWebhook, updateWebhook); err != nil {
+			return c.Failure(err)
+		}
+
+		err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditWebhookUpdated,
+			TargetType: "webhook",
+			TargetID:   strconv.Itoa(id),
+			Before:     webhookAuditValues(webhookToCreateEdit(getWebhook.Result)),
+			After:      webhookAuditValues(updateWebhook),
+		})
+		if err != nil {
 			return c.Failure(err)
 		}
 
@@ -97,8 +124,19 @@ func DeleteWebhook() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		getWebhook := &query.GetWebhook{ID: id}
 		deleteWebhook := &query.DeleteWebhook{ID: id}
-		if err = bus.Dispatch(c, deleteWebhook); err != nil {
+		if err = bus.Dispatch(c, getWebhook, deleteWebhook); err != nil {
+			return c.Failure(err)
+		}
+
+		err = webutil.AddAuditLog(c, &cmd.AddAuditLog{
+			Action:     enum.AuditWebhookDeleted,
+			TargetType: "webhook",
+			TargetID:   strconv.Itoa(id),
+			Before:     webhookAuditValues(webhookToCreateEdit(getWebhook.Result)),
+		})
+		if err != nil {
 			return c.Failure(err)
 		}
 
@@ -160,3 +198,41 @@ func GetWebhookProps() web.HandlerFunc {
 		return c.Ok(webhookProps.Result)
 	}
 }
+
+func webhookToCreateEdit(webhook *entity.Webhook) *query.CreateEditWebhook {
+	return &query.CreateEditWebhook{
+		ID:          webhook.ID,
+		Name:        webhook.Name,
+		Type:        webhook.Type,
+		Status:      webhook.Status,
+		Url:         webhook.Url,
+		Content:     webhook.Content,
+		HttpMethod:  webhook.HttpMethod,
+		HttpHeaders: webhook.HttpHeaders,
+	}
+}
+
+// webhookAuditValues returns the audited fields of a webhook
+// URL path and header values often carry credentials, so only the host and header names are recorded
+func webhookAuditValues(webhook *query.CreateEditWebhook) entity.AuditValues {
+	target := ""
+	if u, err := url.Parse(webhook.Url); err == nil {
+		target = u.Scheme + "://" + u.Host
+	}
+
+	headers := make([]string, 0, len(webhook.HttpHeaders))
+	for name := range webhook.HttpHeaders {
+		headers = append(headers, name)
+	}
+	sort.Strings(headers)
+
+	return entity.AuditValues{
+		"name":        webhook.Name,
+		"type":        webhook.Type,
+		"status":      webhook.Status,
+		"url":         target,
+		"content":     webhook.Content,
+		"httpMethod":  webhook.HttpMethod,
+		"httpHeaders": headers,
+	}
+}
@@ -0,0 +1,79 @@
+package webhooks
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/inbound"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/tasks"
+)
+
+// IncomingEmail handles replies to notifications and bounce messages forwarded by Mailgun routes, Amazon SES (through SNS) or posted as raw MIME messages
+// Bounce and complaint notifications of Amazon SES are also accepted
+func IncomingEmail() web.HandlerFunc {
+	return func(c *web.Context) error {
+		secret := env.Config.Email.Inbound.Secret
+		if secret == "" || subtle.ConstantTimeCompare([]byte(c.QueryParam("secret")), []byte(secret)) != 1 {
+			return c.JSON(http.StatusUnauthorized, web.Map{})
+		}
+
+		var (
+			email *dto.InboundEmail
+			err   error
+		)
+
+		contentType := c.Request.GetHeader("Content-Type")
+		if c.Request.GetHeader("X-Amz-Sns-Message-Type") != "" {
+			var subscribeURL string
+			email, subscribeURL, err = inbound.ParseSES(c.Request.Body)
+			if err == nil && subscribeURL != "" {
+				return confirmSNSSubscription(c, subscribeURL)
+			}
+			if err == nil && email == nil {
+				return c.Ok(web.Map{})
+			}
+			if err == nil && len(email.Bounces) > 0 {
+				// SNS notifications are trusted because of the secret, so there's no bounce address to verify
+				c.Enqueue(tasks.SupressBouncedEmails(email.Bounces))
+				return c.Ok(web.Map{})
+			}
+		} else if strings.HasPrefix(contentType, "multipart/form-data") || strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+			email, err = inbound.ParseMailgun(contentType, c.Request.Body)
+		} else {
+			email, err = inbound.ParseMessage(strings.NewReader(c.Request.Body))
+		}
+
+		// Emails that can't be parsed would fail again if retried, so they are rejected instead
+		if err != nil {
+			log.Warnf(c, "Inbound email was rejected because it could not be parsed: @{Error}", dto.Props{
+				"Error": err.Error(),
+			})
+			return c.BadRequest(web.Map{})
+		}
+
+		c.Enqueue(tasks.ReceiveEmail(email))
+		return c.Ok(web.Map{})
+	}
+}
+
+func confirmSNSSubscription(c *web.Context, subscribeURL string) error {
+	req := &cmd.HTTPRequest{
+		Method: "GET",
+		URL:    subscribeURL,
+	}
+	if err := bus.Dispatch(c, req); err != nil {
+		return c.Failure(err)
+	}
+	if req.ResponseStatusCode >= 300 {
+		return c.Failure(errors.New("failed to confirm SNS subscription with status code %d", req.ResponseStatusCode))
+	}
+	return c.Ok(web.Map{})
+}
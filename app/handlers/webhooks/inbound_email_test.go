@@ -0,0 +1,123 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/getfider/fider/app/handlers/webhooks"
+	"github.com/getfider/fider/app/models/cmd"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func setupInboundEmail() {
+	env.Config.Email.Inbound.Address = "reply@inbound.got.com"
+	env.Config.Email.Inbound.Secret = "s3cr3t"
+}
+
+func TestIncomingEmail_InvalidSecret(t *testing.T) {
+	RegisterT(t)
+	setupInboundEmail()
+
+	server := mock.NewServer()
+	code, _ := server.
+		WithURL("http://demo.test.fider.io/_api/inbound/email?secret=wrong").
+		ExecutePost(webhooks.IncomingEmail(), "From: arya.stark@got.com\r\n\r\nI agree")
+
+	Expect(code).Equals(http.StatusUnauthorized)
+}
+
+func TestIncomingEmail_MissingSecret(t *testing.T) {
+	RegisterT(t)
+	setupInboundEmail()
+	env.Config.Email.Inbound.Secret = ""
+
+	server := mock.NewServer()
+	code, _ := server.
+		WithURL("http://demo.test.fider.io/_api/inbound/email?secret=").
+		ExecutePost(webhooks.IncomingEmail(), "From: arya.stark@got.com\r\n\r\nI agree")
+
+	Expect(code).Equals(http.StatusUnauthorized)
+}
+
+func TestIncomingEmail_RawMessage(t *testing.T) {
+	RegisterT(t)
+	setupInboundEmail()
+
+	server := mock.NewServer()
+	code, _ := server.
+		WithURL("http://demo.test.fider.io/_api/inbound/email?secret=s3cr3t").
+		ExecutePost(webhooks.IncomingEmail(), "From: arya.stark@got.com\r\nTo: reply+1.2.3.abc@inbound.got.com\r\n\r\nI agree")
+
+	Expect(code).Equals(http.StatusOK)
+}
+
+func TestIncomingEmail_InvalidMessage(t *testing.T) {
+	RegisterT(t)
+	setupInboundEmail()
+
+	server := mock.NewServer()
+	code, _ := server.
+		WithURL("http://demo.test.fider.io/_api/inbound/email?secret=s3cr3t").
+		AddHeader("X-Amz-Sns-Message-Type", "Notification").
+		ExecutePost(webhooks.IncomingEmail(), "not json")
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
+
+func TestIncomingEmail_SNSSubscriptionConfirmation(t *testing.T) {
+	RegisterT(t)
+	setupInboundEmail()
+
+	var confirm *cmd.HTTPRequest
+	bus.AddHandler(func(ctx context.Context, c *cmd.HTTPRequest) error {
+		confirm = c
+		c.ResponseStatusCode = http.StatusOK
+		return nil
+	})
+
+	body, _ := json.Marshal(map[string]string{
+		"Type":         "SubscriptionConfirmation",
+		"SubscribeURL": "https://sns.eu-west-1.amazonaws.com/?Action=ConfirmSubscription&Token=123",
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		WithURL("http://demo.test.fider.io/_api/inbound/email?secret=s3cr3t").
+		AddHeader("X-Amz-Sns-Message-Type", "SubscriptionConfirmation").
+		ExecutePost(webhooks.IncomingEmail(), string(body))
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(confirm).IsNotNil()
+	Expect(confirm.Method).Equals("GET")
+	Expect(confirm.URL).Equals("https://sns.eu-west-1.amazonaws.com/?Action=ConfirmSubscription&Token=123")
+}
+
+func TestIncomingEmail_SESBounce(t *testing.T) {
+	RegisterT(t)
+	setupInboundEmail()
+
+	message, _ := json.Marshal(map[string]any{
+		"notificationType": "Bounce",
+		"bounce": map[string]any{
+			"bounceType":        "Permanent",
+			"bouncedRecipients": []map[string]string{{"emailAddress": "jon.snow@got.com"}},
+		},
+	})
+	body, _ := json.Marshal(map[string]string{
+		"Type":    "Notification",
+		"Message": string(message),
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		WithURL("http://demo.test.fider.io/_api/inbound/email?secret=s3cr3t").
+		AddHeader("X-Amz-Sns-Message-Type", "Notification").
+		ExecutePost(webhooks.IncomingEmail(), string(body))
+
+	Expect(code).Equals(http.StatusOK)
+}
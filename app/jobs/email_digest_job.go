@@ -0,0 +1,124 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/markdown"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+type EmailDigestJobHandler struct {
+	Delivery enum.EmailDelivery
+}
+
+func (e EmailDigestJobHandler) Schedule() string {
+	if e.Delivery == enum.EmailDeliveryWeekly {
+		return "0 0 8 * * 1" // every monday at 8:00 AM
+	}
+	return "0 0 8 * * *" // every day at 8:00 AM
+}
+
+func (e EmailDigestJobHandler) Run(ctx Context) error {
+	q := &query.GetTenantsWithPendingEmailDigests{Delivery: e.Delivery}
+	if err := bus.Dispatch(ctx, q); err != nil {
+		return err
+	}
+
+	// Digests of a tenant are kept for the next run when they fail, without holding back the other tenants
+	for _, tenant := range q.Result {
+		tenantCtx := web.WithTenant(ctx, tenant)
+		err := withSavepoint(tenantCtx, func() error {
+			return e.sendDigests(tenantCtx, tenant)
+		})
+		if err != nil {
+			log.Error(tenantCtx, err)
+		}
+	}
+
+	return nil
+}
+
+func (e EmailDigestJobHandler) sendDigests(ctx context.Context, tenant *entity.Tenant) error {
+	q := &query.GetPendingEmailDigestItems{Delivery: e.Delivery}
+	if err := bus.Dispatch(ctx, q); err != nil {
+		return err
+	}
+
+	baseURL, logoURL := web.BaseURL(ctx), web.LogoURL(ctx)
+
+	var (
+		user  *entity.User
+		posts []dto.Props
+	)
+
+	send := func() error {
+		if user == nil {
+			return nil
+		}
+
+		// Users without a preferred locale get the one of the tenant
+		userCtx := i18n.WithLocale(ctx, user.Locale)
+		to := dto.NewRecipient(user.Name, user.Email, dto.Props{})
+		to.Locale = i18n.GetLocale(userCtx)
+
+		return bus.Dispatch(ctx, &cmd.SendMail{
+			From:         dto.Recipient{Name: tenant.Name},
+			To:           []dto.Recipient{to},
+			TemplateName: "email_digest",
+			Props: dto.Props{
+				"siteName": tenant.Name,
+				"delivery": string(e.Delivery),
+				"posts":    posts,
+				"change":   fmt.Sprintf("<a href='%s/settings'>%s</a>", baseURL, i18n.T(userCtx, "email.subscription.change")),
+				"logo":     logoURL,
+			},
+		})
+	}
+
+	// Items are sorted by user and post, so a digest is sent every time the user changes
+	for _, item := range q.Result {
+		if user == nil || user.ID != item.User.ID {
+			if err := send(); err != nil {
+				return err
+			}
+			user, posts = item.User, make([]dto.Props, 0)
+		}
+
+		if len(posts) == 0 || posts[len(posts)-1]["id"] != item.Post.ID {
+			posts = append(posts, dto.Props{
+				"id":    item.Post.ID,
+				"title": item.Post.Title,
+				"url":   fmt.Sprintf("%s/posts/%d/%s", baseURL, item.Post.Number, item.Post.Slug),
+				"items": make([]dto.Props, 0),
+			})
+		}
+
+		post := posts[len(posts)-1]
+		post["items"] = append(post["items"].([]dto.Props), dto.Props{
+			"title":   markdown.Full(item.Title),
+			"content": markdown.Full(item.Content),
+		})
+	}
+	if err := send(); err != nil {
+		return err
+	}
+
+	log.Debugf(ctx, "@{Count} email digest items were sent on tenant @{Tenant}", dto.Props{
+		"Count":  len(q.Result),
+		"Tenant": tenant.Subdomain,
+	})
+
+	return bus.Dispatch(ctx, &cmd.DeleteEmailDigestItems{
+		Delivery: e.Delivery,
+		UpToID:   q.LastID,
+	})
+}
@@ -0,0 +1,133 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/jobs"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/services/email/emailmock"
+)
+
+func TestEmailDigestJob_Schedule_IsCorrect(t *testing.T) {
+	RegisterT(t)
+
+	job := &jobs.EmailDigestJobHandler{Delivery: enum.EmailDeliveryDaily}
+	Expect(job.Schedule()).Equals("0 0 8 * * *")
+
+	job = &jobs.EmailDigestJobHandler{Delivery: enum.EmailDeliveryWeekly}
+	Expect(job.Schedule()).Equals("0 0 8 * * 1")
+}
+
+func TestEmailDigestJob_GroupsItemsByUserAndPost(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	tenant := &entity.Tenant{ID: 1, Name: "Demonstration", Subdomain: "demo", Locale: "en"}
+	jonSnow := &entity.User{ID: 1, Name: "Jon Snow", Email: "jon.snow@got.com"}
+	aryaStark := &entity.User{ID: 2, Name: "Arya Stark", Email: "arya.stark@got.com"}
+	post1 := &entity.Post{ID: 1, Number: 1, Title: "Add support for TypeScript", Slug: "add-support-for-typescript"}
+	post2 := &entity.Post{ID: 2, Number: 2, Title: "Dark mode", Slug: "dark-mode"}
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetTenantsWithPendingEmailDigests) error {
+		q.Result = []*entity.Tenant{tenant}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetPendingEmailDigestItems) error {
+		q.Result = []*entity.EmailDigestItem{
+			{ID: 1, User: jonSnow, Post: post1, Title: "**Arya Stark** created this post.", Content: "Please"},
+			{ID: 4, User: jonSnow, Post: post1, Title: "**Arya Stark** left a comment.", Content: "I agree"},
+			{ID: 2, User: jonSnow, Post: post2, Title: "**Arya Stark** created this post.", Content: "My eyes"},
+			{ID: 3, User: aryaStark, Post: post2, Title: "**Jon Snow** left a comment.", Content: "Winter is coming"},
+		}
+		q.LastID = 5
+		return nil
+	})
+
+	var deleteItems *cmd.DeleteEmailDigestItems
+	bus.AddHandler(func(ctx context.Context, c *cmd.DeleteEmailDigestItems) error {
+		deleteItems = c
+		return nil
+	})
+
+	job := &jobs.EmailDigestJobHandler{Delivery: enum.EmailDeliveryDaily}
+	err := job.Run(jobs.Context{
+		Context: context.Background(),
+	})
+	Expect(err).IsNil()
+
+	Expect(emailmock.MessageHistory).HasLen(2)
+	Expect(emailmock.MessageHistory[0].TemplateName).Equals("email_digest")
+	Expect(emailmock.MessageHistory[0].Tenant).Equals(tenant)
+	Expect(emailmock.MessageHistory[0].To[0].Address).Equals(jonSnow.Email)
+	Expect(emailmock.MessageHistory[0].Props["delivery"]).Equals("daily")
+
+	posts := emailmock.MessageHistory[0].Props["posts"].([]dto.Props)
+	Expect(posts).HasLen(2)
+	Expect(posts[0]["title"]).Equals("Add support for TypeScript")
+	Expect(posts[0]["items"]).HasLen(2)
+	Expect(posts[1]["title"]).Equals("Dark mode")
+	Expect(posts[1]["items"]).HasLen(1)
+
+	Expect(emailmock.MessageHistory[1].To[0].Address).Equals(aryaStark.Email)
+	Expect(emailmock.MessageHistory[1].Props["posts"]).HasLen(1)
+
+	Expect(deleteItems.Delivery).Equals(enum.EmailDeliveryDaily)
+	Expect(deleteItems.UpToID).Equals(5)
+}
+
+func TestEmailDigestJob_KeepsItems_WhenSendingFails(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	failing := &entity.Tenant{ID: 1, Name: "Demonstration", Subdomain: "demo", Locale: "en"}
+	other := &entity.Tenant{ID: 2, Name: "Other", Subdomain: "other", Locale: "en"}
+	jonSnow := &entity.User{ID: 1, Name: "Jon Snow", Email: "jon.snow@got.com"}
+	post := &entity.Post{ID: 1, Number: 1, Title: "Dark mode", Slug: "dark-mode"}
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetTenantsWithPendingEmailDigests) error {
+		q.Result = []*entity.Tenant{failing, other}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetPendingEmailDigestItems) error {
+		q.Result = []*entity.EmailDigestItem{
+			{ID: 1, User: jonSnow, Post: post, Title: "**Arya Stark** created this post.", Content: "My eyes"},
+		}
+		q.LastID = 1
+		return nil
+	})
+
+	sentTo := make([]*entity.Tenant, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.SendMail) error {
+		tenant := ctx.Value(app.TenantCtxKey).(*entity.Tenant)
+		if tenant == failing {
+			return errors.New("outbox is unavailable")
+		}
+		sentTo = append(sentTo, tenant)
+		return nil
+	})
+
+	deletedOn := make([]*entity.Tenant, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.DeleteEmailDigestItems) error {
+		deletedOn = append(deletedOn, ctx.Value(app.TenantCtxKey).(*entity.Tenant))
+		return nil
+	})
+
+	job := &jobs.EmailDigestJobHandler{Delivery: enum.EmailDeliveryDaily}
+	err := job.Run(jobs.Context{
+		Context: context.Background(),
+	})
+	Expect(err).IsNil()
+	Expect(sentTo).Equals([]*entity.Tenant{other})
+	Expect(deletedOn).Equals([]*entity.Tenant{other})
+}
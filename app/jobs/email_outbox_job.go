@@ -0,0 +1,150 @@
+package jobs
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// MaxEmailDeliveryAttempts is how many times an email is sent before it's marked as failed
+const MaxEmailDeliveryAttempts = 8
+
+// emailOutboxBatchSize is the maximum number of emails delivered on each run
+const emailOutboxBatchSize = 100
+
+type EmailOutboxJobHandler struct {
+}
+
+func (e EmailOutboxJobHandler) Schedule() string {
+	return "*/10 * * * * *" // every 10 seconds
+}
+
+func (e EmailOutboxJobHandler) Run(ctx Context) error {
+	// Emails are claimed and their delivery status recorded on their own transactions,
+	// so that what's been delivered isn't sent again if the job fails halfway through
+	q := &query.GetDueEmailBatches{Limit: emailOutboxBatchSize, Standalone: true}
+	if err := bus.Dispatch(ctx, q); err != nil {
+		return errors.Wrap(err, "failed to get due emails")
+	}
+
+	tenants := make(map[int]*entity.Tenant)
+	for _, batch := range q.Result {
+		c := &cmd.DeliverMail{
+			Mail: &cmd.SendMail{
+				From:         batch.From,
+				To:           make([]dto.Recipient, len(batch.Recipients)),
+				TemplateName: batch.TemplateName,
+				Props:        batch.Props,
+				Template:     batch.Template,
+			},
+		}
+		for i, r := range batch.Recipients {
+			c.Mail.To[i] = r.Recipient
+		}
+
+		batchCtx, deliveryErr := emailBatchContext(ctx, batch, tenants)
+		if deliveryErr == nil {
+			deliveryErr = deliverMail(batchCtx, c)
+		}
+		if deliveryErr != nil {
+			log.Error(ctx, deliveryErr)
+		}
+
+		if err := setEmailBatchStatus(ctx, batch, c, deliveryErr); err != nil {
+			log.Error(ctx, err)
+		}
+	}
+
+	if len(q.Result) > 0 {
+		log.Debugf(ctx, "@{Count} email batch(es) were delivered from the outbox", dto.Props{
+			"Count": len(q.Result),
+		})
+	}
+
+	return nil
+}
+
+// EmailRetryDelay returns how long to wait before sending an email again after given number of failed attempts
+func EmailRetryDelay(attempts int) time.Duration {
+	return time.Minute << (attempts - 1)
+}
+
+func emailBatchContext(ctx context.Context, batch *dto.EmailBatch, tenants map[int]*entity.Tenant) (context.Context, error) {
+	if batch.TenantID > 0 {
+		tenant, ok := tenants[batch.TenantID]
+		if !ok {
+			q := &query.GetTenantByID{TenantID: batch.TenantID}
+			if err := bus.Dispatch(ctx, q); err != nil {
+				return nil, err
+			}
+			tenant = q.Result
+			tenants[batch.TenantID] = tenant
+		}
+		ctx = web.WithTenant(ctx, tenant)
+	}
+
+	if batch.Locale != "" {
+		ctx = context.WithValue(ctx, app.LocaleCtxKey, batch.Locale)
+	}
+	return ctx, nil
+}
+
+// deliverMail prevents an email that can't be sent from stopping the delivery of all the others
+func deliverMail(ctx context.Context, c *cmd.DeliverMail) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Panicked(r)
+		}
+	}()
+	return bus.Dispatch(ctx, c)
+}
+
+func setEmailBatchStatus(ctx context.Context, batch *dto.EmailBatch, c *cmd.DeliverMail, deliveryErr error) error {
+	sent := &cmd.SetEmailDeliveryStatus{Status: enum.EmailSent, Standalone: true}
+	suppressed := &cmd.SetEmailDeliveryStatus{Status: enum.EmailSuppressed, Standalone: true}
+	failed := make([]*cmd.SetEmailDeliveryStatus, 0)
+
+	for _, r := range batch.Recipients {
+		switch {
+		case slices.Contains(c.Suppressed, r.Address):
+			suppressed.IDs = append(suppressed.IDs, r.EmailID)
+		case deliveryErr == nil || slices.Contains(c.Sent, r.Address):
+			sent.IDs = append(sent.IDs, r.EmailID)
+		default:
+			attempts := r.Attempts + 1
+			status := &cmd.SetEmailDeliveryStatus{
+				IDs:        []int64{r.EmailID},
+				Status:     enum.EmailPending,
+				Error:      errors.Cause(deliveryErr).Error(),
+				Standalone: true,
+			}
+			if attempts >= MaxEmailDeliveryAttempts {
+				status.Status = enum.EmailFailed
+			} else {
+				status.NextAttemptAt = time.Now().Add(EmailRetryDelay(attempts))
+			}
+			failed = append(failed, status)
+		}
+	}
+
+	if err := bus.Dispatch(ctx, sent, suppressed); err != nil {
+		return errors.Wrap(err, "failed to set delivery status of batch %s", batch.ID)
+	}
+	for _, status := range failed {
+		if err := bus.Dispatch(ctx, status); err != nil {
+			return errors.Wrap(err, "failed to set delivery status of batch %s", batch.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/jobs"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/services/email/emailmock"
+)
+
+func TestEmailOutboxJob_Schedule_IsCorrect(t *testing.T) {
+	RegisterT(t)
+
+	job := &jobs.EmailOutboxJobHandler{}
+	Expect(job.Schedule()).Equals("*/10 * * * * *")
+}
+
+func TestEmailRetryDelay(t *testing.T) {
+	RegisterT(t)
+
+	Expect(jobs.EmailRetryDelay(1)).Equals(1 * time.Minute)
+	Expect(jobs.EmailRetryDelay(2)).Equals(2 * time.Minute)
+	Expect(jobs.EmailRetryDelay(5)).Equals(16 * time.Minute)
+}
+
+func mockEmailOutbox(batches ...*dto.EmailBatch) *[]*cmd.SetEmailDeliveryStatus {
+	tenant := &entity.Tenant{ID: 1, Name: "Demonstration", Subdomain: "demo", Locale: "en"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetDueEmailBatches) error {
+		Expect(q.Standalone).IsTrue()
+		q.Result = batches
+		return nil
+	})
+	bus.AddHandler(func(ctx context.Context, q *query.GetTenantByID) error {
+		q.Result = tenant
+		return nil
+	})
+
+	statuses := make([]*cmd.SetEmailDeliveryStatus, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetEmailDeliveryStatus) error {
+		Expect(c.Standalone).IsTrue()
+		if len(c.IDs) > 0 {
+			statuses = append(statuses, c)
+		}
+		return nil
+	})
+	return &statuses
+}
+
+func newEmailBatch(attempts int, addresses ...string) *dto.EmailBatch {
+	batch := &dto.EmailBatch{
+		ID:           "batch1",
+		TenantID:     1,
+		Locale:       "pt-BR",
+		From:         dto.Recipient{Name: "Demonstration"},
+		TemplateName: "new_post",
+		Props:        dto.Props{"title": "Dark mode"},
+	}
+	for i, address := range addresses {
+		batch.Recipients = append(batch.Recipients, &dto.BatchRecipient{
+			EmailID:   int64(i + 1),
+			Attempts:  attempts,
+			Recipient: dto.NewRecipient(address, address, dto.Props{}),
+		})
+	}
+	return batch
+}
+
+func TestEmailOutboxJob_DeliversBatches(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	statuses := mockEmailOutbox(newEmailBatch(0, "jon.snow@got.com", "arya.stark@got.com"))
+
+	var locale any
+	bus.AddHandler(func(ctx context.Context, c *cmd.DeliverMail) error {
+		locale = ctx.Value(app.LocaleCtxKey)
+		c.Sent = []string{"jon.snow@got.com"}
+		c.Suppressed = []string{"arya.stark@got.com"}
+		return nil
+	})
+
+	job := &jobs.EmailOutboxJobHandler{}
+	err := job.Run(jobs.Context{
+		Context: context.Background(),
+	})
+	Expect(err).IsNil()
+	Expect(locale).Equals("pt-BR")
+
+	Expect(*statuses).HasLen(2)
+	Expect((*statuses)[0].Status).Equals(enum.EmailSent)
+	Expect((*statuses)[0].IDs).Equals([]int64{1})
+	Expect((*statuses)[1].Status).Equals(enum.EmailSuppressed)
+	Expect((*statuses)[1].IDs).Equals([]int64{2})
+}
+
+func TestEmailOutboxJob_UsesEmailProvider(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	statuses := mockEmailOutbox(newEmailBatch(0, "jon.snow@got.com"))
+
+	job := &jobs.EmailOutboxJobHandler{}
+	err := job.Run(jobs.Context{
+		Context: context.Background(),
+	})
+	Expect(err).IsNil()
+
+	Expect(emailmock.MessageHistory).HasLen(1)
+	Expect(emailmock.MessageHistory[0].TemplateName).Equals("new_post")
+	Expect(emailmock.MessageHistory[0].Tenant.Subdomain).Equals("demo")
+	Expect(emailmock.MessageHistory[0].To[0].Address).Equals("jon.snow@got.com")
+	Expect(emailmock.MessageHistory[0].Props["title"]).Equals("Dark mode")
+
+	Expect(*statuses).HasLen(1)
+	Expect((*statuses)[0].Status).Equals(enum.EmailSent)
+}
+
+func TestEmailOutboxJob_RetriesFailedEmails(t *testing.T) {
+	RegisterT(t)
+
+	statuses := mockEmailOutbox(newEmailBatch(2, "jon.snow@got.com", "arya.stark@got.com"))
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.DeliverMail) error {
+		c.Sent = []string{"jon.snow@got.com"}
+		return errors.New("connection refused")
+	})
+
+	job := &jobs.EmailOutboxJobHandler{}
+	err := job.Run(jobs.Context{
+		Context: context.Background(),
+	})
+	Expect(err).IsNil()
+
+	Expect(*statuses).HasLen(2)
+	Expect((*statuses)[0].Status).Equals(enum.EmailSent)
+	Expect((*statuses)[0].IDs).Equals([]int64{1})
+	Expect((*statuses)[1].Status).Equals(enum.EmailPending)
+	Expect((*statuses)[1].IDs).Equals([]int64{2})
+	Expect((*statuses)[1].Error).ContainsSubstring("connection refused")
+	Expect((*statuses)[1].NextAttemptAt).TemporarilySimilar(time.Now().Add(4*time.Minute), 5*time.Second)
+}
+
+func TestEmailOutboxJob_FailsAfterMaxAttempts(t *testing.T) {
+	RegisterT(t)
+
+	statuses := mockEmailOutbox(newEmailBatch(jobs.MaxEmailDeliveryAttempts-1, "jon.snow@got.com"))
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.DeliverMail) error {
+		panic("something went wrong")
+	})
+
+	job := &jobs.EmailOutboxJobHandler{}
+	err := job.Run(jobs.Context{
+		Context: context.Background(),
+	})
+	Expect(err).IsNil()
+
+	Expect(*statuses).HasLen(1)
+	Expect((*statuses)[0].Status).Equals(enum.EmailFailed)
+	Expect((*statuses)[0].Error).ContainsSubstring("something went wrong")
+}
+
+func TestEmailOutboxJob_KeepsStatusOfOtherBatches_WhenOneFails(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	batch1 := newEmailBatch(0, "jon.snow@got.com")
+	batch2 := newEmailBatch(0, "arya.stark@got.com")
+	batch2.ID = "batch2"
+	batch2.Recipients[0].EmailID = 2
+	mockEmailOutbox(batch1, batch2)
+
+	statuses := make([]*cmd.SetEmailDeliveryStatus, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetEmailDeliveryStatus) error {
+		if len(c.IDs) > 0 && c.IDs[0] == 1 {
+			return errors.New("connection lost")
+		}
+		if len(c.IDs) > 0 {
+			statuses = append(statuses, c)
+		}
+		return nil
+	})
+
+	job := &jobs.EmailOutboxJobHandler{}
+	err := job.Run(jobs.Context{
+		Context: context.Background(),
+	})
+	Expect(err).IsNil()
+
+	Expect(emailmock.MessageHistory).HasLen(2)
+	Expect(statuses).HasLen(1)
+	Expect(statuses[0].Status).Equals(enum.EmailSent)
+	Expect(statuses[0].IDs).Equals([]int64{2})
+}
@@ -109,3 +109,26 @@ func newJobContext() (Context, *dbx.Trx, error) {
 	ctx = context.WithValue(ctx, app.TransactionCtxKey, trx)
 	return Context{Context: ctx}, trx, nil
 }
+
+// withSavepoint runs given function on a savepoint of the job transaction
+// Changes made by the function are undone when it fails, while the rest of the job can still be committed
+func withSavepoint(ctx context.Context, fn func() error) error {
+	trx, ok := ctx.Value(app.TransactionCtxKey).(*dbx.Trx)
+	if !ok {
+		return fn()
+	}
+
+	if _, err := trx.Execute("SAVEPOINT job_step"); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		if _, rollbackErr := trx.Execute("ROLLBACK TO SAVEPOINT job_step"); rollbackErr != nil {
+			return errors.Wrap(rollbackErr, "failed to rollback after: %v", err)
+		}
+		return err
+	}
+
+	_, err := trx.Execute("RELEASE SAVEPOINT job_step")
+	return err
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+type LiftExpiredSuspensionsJobHandler struct {
+}
+
+func (e LiftExpiredSuspensionsJobHandler) Schedule() string {
+	return "0 */15 * * * *" // every 15 minutes
+}
+
+func (e LiftExpiredSuspensionsJobHandler) Run(ctx Context) error {
+	log.Debug(ctx, "lifting expired user suspensions")
+
+	c := &cmd.LiftExpiredUserSuspensions{}
+	err := bus.Dispatch(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf(ctx, "@{RowsUpdated} user suspensions were lifted", dto.Props{
+		"RowsUpdated": c.NumOfLiftedSuspensions,
+	})
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/jobs"
+	"github.com/getfider/fider/app/models/cmd"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestLiftExpiredSuspensionsJob_Schedule_IsCorrect(t *testing.T) {
+	RegisterT(t)
+
+	job := &jobs.LiftExpiredSuspensionsJobHandler{}
+	Expect(job.Schedule()).Equals("0 */15 * * * *")
+}
+
+func TestLiftExpiredSuspensionsJob_ShouldJustDispatchCommand(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.LiftExpiredUserSuspensions) error {
+		c.NumOfLiftedSuspensions = 2
+		return nil
+	})
+
+	job := &jobs.LiftExpiredSuspensionsJobHandler{}
+	err := job.Run(jobs.Context{
+		Context: context.Background(),
+	})
+	Expect(err).IsNil()
+	ExpectHandler(&cmd.LiftExpiredUserSuspensions{}).CalledOnce()
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+type PurgeOutboxEmailsJobHandler struct {
+}
+
+func (e PurgeOutboxEmailsJobHandler) Schedule() string {
+	return "0 10 * * * *" // every hour at minute 10
+}
+
+func (e PurgeOutboxEmailsJobHandler) Run(ctx Context) error {
+	log.Debug(ctx, "deleting delivered emails older than 30 days")
+
+	c := &cmd.PurgeOutboxEmails{}
+	err := bus.Dispatch(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf(ctx, "@{RowsDeleted} emails were deleted from the outbox", dto.Props{
+		"RowsDeleted": c.NumOfDeletedEmails,
+	})
+
+	return nil
+}
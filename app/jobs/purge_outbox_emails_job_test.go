@@ -0,0 +1,33 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/jobs"
+	"github.com/getfider/fider/app/models/cmd"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestPurgeOutboxEmailsJob_Schedule_IsCorrect(t *testing.T) {
+	RegisterT(t)
+
+	job := &jobs.PurgeOutboxEmailsJobHandler{}
+	Expect(job.Schedule()).Equals("0 10 * * * *")
+}
+
+func TestPurgeOutboxEmailsJob_ShouldJustDispatchCommand(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.PurgeOutboxEmails) error {
+		return nil
+	})
+
+	job := &jobs.PurgeOutboxEmailsJobHandler{}
+	err := job.Run(jobs.Context{
+		Context: context.Background(),
+	})
+	Expect(err).IsNil()
+	ExpectHandler(&cmd.PurgeOutboxEmails{}).CalledOnce()
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+type PurgeExpiredRateLimitsJobHandler struct {
+}
+
+func (e PurgeExpiredRateLimitsJobHandler) Schedule() string {
+	return "0 30 * * * *" // every hour at minute 30
+}
+
+func (e PurgeExpiredRateLimitsJobHandler) Run(ctx Context) error {
+	log.Debug(ctx, "deleting expired rate limits")
+
+	c := &cmd.PurgeExpiredRateLimits{}
+	err := bus.Dispatch(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf(ctx, "@{RowsDeleted} rate limits were deleted", dto.Props{
+		"RowsDeleted": c.NumOfDeletedRateLimits,
+	})
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/jobs"
+	"github.com/getfider/fider/app/models/cmd"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestPurgeExpiredRateLimitsJob_Schedule_IsCorrect(t *testing.T) {
+	RegisterT(t)
+
+	job := &jobs.PurgeExpiredRateLimitsJobHandler{}
+	Expect(job.Schedule()).Equals("0 30 * * * *")
+}
+
+func TestPurgeExpiredRateLimitsJob_ShouldJustDispatchCommand(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.PurgeExpiredRateLimits) error {
+		c.NumOfDeletedRateLimits = 3
+		return nil
+	})
+
+	job := &jobs.PurgeExpiredRateLimitsJobHandler{}
+	err := job.Run(jobs.Context{
+		Context: context.Background(),
+	})
+	Expect(err).IsNil()
+	ExpectHandler(&cmd.PurgeExpiredRateLimits{}).CalledOnce()
+}
@@ -45,7 +45,7 @@ func (e TrialReminderJobHandler) Run(ctx Context) error {
 	}
 
 	if len(to) > 0 {
-		bus.Publish(ctx, &cmd.SendMail{
+		err := bus.Dispatch(ctx, &cmd.SendMail{
 			From: dto.Recipient{
 				Name:    "Guilherme from Fider",
 				Address: "goenning@fider.io",
@@ -56,6 +56,9 @@ func (e TrialReminderJobHandler) Run(ctx Context) error {
 				"logo": web.LogoURL(ctx),
 			},
 		})
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -42,7 +42,7 @@ func TestTrialReminderJob_ShouldSendEmailsToTrialingContacts(t *testing.T) {
 		return nil
 	})
 
-	bus.AddListener(func(ctx context.Context, c *cmd.SendMail) error {
+	bus.AddHandler(func(ctx context.Context, c *cmd.SendMail) error {
 		Expect(c.TemplateName).Equals("trial_1day")
 		Expect(c.To).Equals([]dto.Recipient{
 			{Name: "user1", Address: "user1@gmail.com", Props: dto.Props{"name": "user1", "url": "https://demo1.test.fider.io"}},
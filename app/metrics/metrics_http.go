@@ -16,6 +16,14 @@ var HttpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 	Buckets: []float64{0.2, 0.5, 1, 2, 5},
 }, []string{"operation"})
 
+var HttpRateLimited = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "http_rate_limited_total",
+		Help: "Number of HTTP requests rejected by rate limits.",
+	},
+	[]string{"bucket", "scope"},
+)
+
 func init() {
-	prometheus.MustRegister(HttpRequests, HttpDuration)
-}
\ No newline at end of file
+	prometheus.MustRegister(HttpRequests, HttpDuration, HttpRateLimited)
+}
@@ -31,3 +31,16 @@ func IsAuthorized(roles ...enum.Role) web.MiddlewareFunc {
 		}
 	}
 }
+
+// BlockImpersonation blocks requests made by an administrator impersonating another user
+// It protects the account of the impersonated user, e.g. credentials that would outlive the impersonation
+func BlockImpersonation() web.MiddlewareFunc {
+	return func(next web.HandlerFunc) web.HandlerFunc {
+		return func(c *web.Context) error {
+			if c.Impersonator() != nil {
+				return c.Forbidden()
+			}
+			return next(c)
+		}
+	}
+}
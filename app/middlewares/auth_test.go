@@ -59,3 +59,27 @@ func TestIsAuthenticated_WithoutUser(t *testing.T) {
 
 	Expect(status).Equals(http.StatusUnauthorized)
 }
+
+func TestBlockImpersonation_NotImpersonating(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	server.Use(middlewares.BlockImpersonation())
+	status, _ := server.AsUser(mock.AryaStark).Execute(func(c *web.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	Expect(status).Equals(http.StatusOK)
+}
+
+func TestBlockImpersonation_Impersonating(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	server.Use(middlewares.BlockImpersonation())
+	status, _ := server.AsUser(mock.AryaStark).ImpersonatedBy(mock.JonSnow).Execute(func(c *web.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	Expect(status).Equals(http.StatusForbidden)
+}
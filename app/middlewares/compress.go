@@ -63,7 +63,17 @@ func (w *gzipResponseWriter) Write(b []byte) (int, error) {
 }
 
 func (r gzipResponseWriter) Flush() {
-	r.Writer.(http.Flusher).Flush()
+	if gw, ok := r.Writer.(*gzip.Writer); ok {
+		_ = gw.Flush()
+	}
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap is used by http.ResponseController to reach the original ResponseWriter
+func (r gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
 }
 
 func (r gzipResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
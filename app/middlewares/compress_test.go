@@ -58,3 +58,25 @@ func TestCompress_AfterPanic(t *testing.T) {
 	Expect(response.Header().Get("Content-Type")).Equals("text/html; charset=utf-8")
 	Expect(response.Header().Get("Content-Encoding")).Equals("gzip")
 }
+
+func TestCompress_Flush(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	server.Use(middlewares.Compress())
+	handler := func(c *web.Context) error {
+		_, _ = c.Response.Write([]byte("Hello World"))
+		c.Response.Flush()
+		return nil
+	}
+
+	status, response := server.
+		AddHeader("Accept-Encoding", "gzip").
+		Execute(handler)
+
+	reader, _ := gzip.NewReader(response.Body)
+	bytes, _ := io.ReadAll(reader)
+	Expect(bytes).Equals([]byte("Hello World"))
+	Expect(status).Equals(http.StatusOK)
+	Expect(response.Flushed).IsTrue()
+}
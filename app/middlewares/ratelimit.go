@@ -0,0 +1,175 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/metrics"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/jsonq"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// Rate limit buckets group the requests that share the same limits
+const (
+	RateLimitSignIn   = "signin"
+	RateLimitPosts    = "posts"
+	RateLimitComments = "comments"
+	RateLimitVotes    = "votes"
+	RateLimitAPI      = "api"
+)
+
+// maxKeyLength is the size of the key column, longer keys are hashed to fit in it
+const maxKeyLength = 200
+
+type rateLimit struct {
+	scope  string
+	limit  int
+	window time.Duration
+}
+
+// key returns the identifier of the counter of current request, or empty when limit doesn't apply to it
+func (l *rateLimit) key(c *web.Context, bucket string) string {
+	tenantID := 0
+	if c.Tenant() != nil {
+		tenantID = c.Tenant().ID
+	}
+
+	key := ""
+	switch l.scope {
+	case "ip":
+		key = fmt.Sprintf("%d:%s:ip:%s", tenantID, bucket, c.Request.ClientIP())
+	case "user":
+		if c.User() != nil {
+			key = fmt.Sprintf("%d:%s:user:%d", tenantID, bucket, c.User().ID)
+		}
+	case "email":
+		email := strings.ToLower(strings.TrimSpace(jsonq.New(c.Request.Body).String("email")))
+		if email != "" {
+			key = fmt.Sprintf("%d:%s:email:%x", tenantID, bucket, sha256.Sum256([]byte(email)))
+		}
+	case "tenant":
+		if tenantID > 0 {
+			key = fmt.Sprintf("%d:%s:tenant", tenantID, bucket)
+		}
+	}
+
+	if len(key) > maxKeyLength {
+		return fmt.Sprintf("%d:%s:%s:%x", tenantID, bucket, l.scope, sha256.Sum256([]byte(key)))
+	}
+	return key
+}
+
+// parseRateLimit parses limits like 10/1h, empty values are unlimited
+func parseRateLimit(scope, value string) *rateLimit {
+	if value == "" {
+		return nil
+	}
+
+	parts := strings.Split(value, "/")
+	if len(parts) == 2 {
+		limit, err := strconv.Atoi(parts[0])
+		window, err2 := time.ParseDuration(parts[1])
+		if err == nil && err2 == nil && limit > 0 && window > 0 {
+			return &rateLimit{scope: scope, limit: limit, window: window}
+		}
+	}
+
+	panic(fmt.Sprintf("'%s' is not a valid rate limit. Use the format {requests}/{window}, e.g: 10/1h", value))
+}
+
+func rateLimits(bucket string) []*rateLimit {
+	config := env.Config.RateLimit
+
+	// Sign in requests are never authenticated, so they're counted per recipient email instead of per user
+	var perIP, perUser, perEmail, perTenant string
+	switch bucket {
+	case RateLimitSignIn:
+		perIP, perEmail, perTenant = config.SignIn.PerIP, config.SignIn.PerEmail, config.SignIn.PerTenant
+	case RateLimitPosts:
+		perIP, perUser, perTenant = config.Posts.PerIP, config.Posts.PerUser, config.Posts.PerTenant
+	case RateLimitComments:
+		perIP, perUser, perTenant = config.Comments.PerIP, config.Comments.PerUser, config.Comments.PerTenant
+	case RateLimitVotes:
+		perIP, perUser, perTenant = config.Votes.PerIP, config.Votes.PerUser, config.Votes.PerTenant
+	case RateLimitAPI:
+		perIP, perUser, perTenant = config.API.PerIP, config.API.PerUser, config.API.PerTenant
+	default:
+		panic(fmt.Sprintf("unknown rate limit bucket '%s'", bucket))
+	}
+
+	limits := make([]*rateLimit, 0)
+	for _, limit := range []*rateLimit{
+		parseRateLimit("ip", perIP),
+		parseRateLimit("user", perUser),
+		parseRateLimit("email", perEmail),
+		parseRateLimit("tenant", perTenant),
+	} {
+		if limit != nil {
+			limits = append(limits, limit)
+		}
+	}
+	return limits
+}
+
+// RateLimit rejects requests with 429 Too Many Requests when any of the limits of given bucket is exceeded
+// Requests are counted per IP, per user (or per recipient email) and per tenant on the database, so that limits hold across instances
+func RateLimit(bucket string) web.MiddlewareFunc {
+	if !env.Config.RateLimit.Enabled {
+		return nil
+	}
+
+	limits := rateLimits(bucket)
+	if len(limits) == 0 {
+		return nil
+	}
+
+	return func(next web.HandlerFunc) web.HandlerFunc {
+		return func(c *web.Context) error {
+			for _, limit := range limits {
+				key := limit.key(c, bucket)
+				if key == "" {
+					continue
+				}
+
+				hit := &cmd.HitRateLimit{Key: key, Window: limit.window}
+				if err := bus.Dispatch(c, hit); err != nil {
+					return c.Failure(err)
+				}
+
+				if hit.Result.Hits > limit.limit {
+					metrics.HttpRateLimited.WithLabelValues(bucket, limit.scope).Inc()
+					log.Warnf(c, "Rate limit of @{Bucket} per @{Scope} exceeded by @{Key}.", dto.Props{
+						"Bucket": bucket,
+						"Scope":  limit.scope,
+						"Key":    key,
+					})
+
+					retryAfter := int(math.Ceil(time.Until(hit.Result.ExpiresAt).Seconds()))
+					if retryAfter < 1 {
+						retryAfter = 1
+					}
+
+					c.Response.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+					return c.JSON(http.StatusTooManyRequests, web.Map{
+						"errors": []web.Map{
+							{"message": i18n.T(c, "validation.custom.ratelimited")},
+						},
+					})
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
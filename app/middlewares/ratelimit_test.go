@@ -0,0 +1,166 @@
+package middlewares_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/middlewares"
+	"github.com/getfider/fider/app/models/cmd"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+func TestRateLimit_Disabled(t *testing.T) {
+	RegisterT(t)
+	env.Config.RateLimit.Enabled = false
+
+	server := mock.NewServer()
+	server.Use(middlewares.RateLimit(middlewares.RateLimitPosts))
+	status, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(func(c *web.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+	Expect(status).Equals(http.StatusOK)
+	ExpectHandler(&cmd.HitRateLimit{}).CalledTimes(0)
+}
+
+func TestRateLimit_UnderLimit(t *testing.T) {
+	RegisterT(t)
+	env.Config.RateLimit.Posts.PerIP = "30/1h"
+	env.Config.RateLimit.Posts.PerUser = "10/10m"
+	env.Config.RateLimit.Posts.PerTenant = ""
+
+	hits := make(map[string]time.Duration)
+	bus.AddHandler(func(ctx context.Context, c *cmd.HitRateLimit) error {
+		hits[c.Key] = c.Window
+		c.Result.Hits = 10
+		c.Result.ExpiresAt = time.Now().Add(c.Window)
+		return nil
+	})
+
+	server := mock.NewServer()
+	server.Use(middlewares.RateLimit(middlewares.RateLimitPosts))
+	status, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(func(c *web.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+	Expect(status).Equals(http.StatusOK)
+	Expect(hits).HasLen(2)
+	Expect(hits["1:posts:user:1"]).Equals(10 * time.Minute)
+}
+
+func TestRateLimit_Exceeded(t *testing.T) {
+	RegisterT(t)
+	env.Config.RateLimit.SignIn.PerIP = "10/1h"
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.HitRateLimit) error {
+		c.Result.Hits = 11
+		c.Result.ExpiresAt = time.Now().Add(90 * time.Second)
+		return nil
+	})
+
+	server := mock.NewServer()
+	server.Use(middlewares.RateLimit(middlewares.RateLimitSignIn))
+	status, response := server.
+		OnTenant(mock.DemoTenant).
+		Execute(func(c *web.Context) error {
+			panic("handler should not be called")
+		})
+
+	Expect(status).Equals(http.StatusTooManyRequests)
+	Expect(response.Header().Get("Retry-After")).Equals("90")
+	Expect(response.Body.String()).ContainsSubstring("You are doing this too often")
+	ExpectHandler(&cmd.HitRateLimit{}).CalledOnce()
+}
+
+func TestRateLimit_PerIP_IgnoresForgedForwardedFor(t *testing.T) {
+	RegisterT(t)
+	env.Config.RateLimit.SignIn.PerIP = "10/1h"
+	env.Config.HTTP.TrustedProxies = "192.0.2.1"
+
+	keys := make([]string, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.HitRateLimit) error {
+		keys = append(keys, c.Key)
+		c.Result.ExpiresAt = time.Now().Add(c.Window)
+		return nil
+	})
+
+	for _, forwardedFor := range []string{"203.0.113.7", "1.1.1.1, 203.0.113.7", strings.Repeat("1.1.1.1, ", 50) + "203.0.113.7"} {
+		server := mock.NewServer()
+		server.Use(middlewares.RateLimit(middlewares.RateLimitSignIn))
+		status, _ := server.
+			OnTenant(mock.DemoTenant).
+			AddHeader("X-Forwarded-For", forwardedFor).
+			Execute(func(c *web.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+		Expect(status).Equals(http.StatusOK)
+	}
+
+	Expect(keys).Equals([]string{"1:signin:ip:203.0.113.7", "1:signin:ip:203.0.113.7", "1:signin:ip:203.0.113.7"})
+}
+
+func TestRateLimit_PerEmail(t *testing.T) {
+	RegisterT(t)
+	env.Config.RateLimit.SignIn.PerIP = ""
+	env.Config.RateLimit.SignIn.PerEmail = "5/1h"
+
+	hits := make(map[string]int)
+	bus.AddHandler(func(ctx context.Context, c *cmd.HitRateLimit) error {
+		hits[c.Key]++
+		c.Result.Hits = hits[c.Key]
+		c.Result.ExpiresAt = time.Now().Add(c.Window)
+		return nil
+	})
+
+	statuses := make([]int, 0)
+	for _, body := range []string{
+		`{ "email": "jon.snow@got.com" }`,
+		`{ "email": "Jon.Snow@got.com" }`,
+		`{ "email": " JON.SNOW@GOT.COM " }`,
+		`{ "email": "jon.snow@got.com" }`,
+		`{ "email": "arya.stark@got.com" }`,
+		`{ "email": "jon.snow@got.com" }`,
+		`{ "email": "jon.snow@got.com" }`,
+		`{ }`,
+	} {
+		server := mock.NewServer()
+		server.Use(middlewares.RateLimit(middlewares.RateLimitSignIn))
+		status, _ := server.
+			OnTenant(mock.DemoTenant).
+			ExecutePost(func(c *web.Context) error {
+				return c.NoContent(http.StatusOK)
+			}, body)
+		statuses = append(statuses, status)
+	}
+
+	Expect(statuses).Equals([]int{200, 200, 200, 200, 200, 200, 429, 200})
+	Expect(hits).HasLen(2)
+	for key := range hits {
+		Expect(strings.HasPrefix(key, "1:signin:email:")).IsTrue()
+		Expect(strings.Contains(key, "got.com")).IsFalse()
+	}
+}
+
+func TestRateLimit_InvalidConfig(t *testing.T) {
+	RegisterT(t)
+
+	for _, value := range []string{"10", "10/", "a/1h", "0/1h", "10/1 hour"} {
+		env.Config.RateLimit.Votes.PerUser = value
+		Expect(func() {
+			middlewares.RateLimit(middlewares.RateLimitVotes)
+		}).Panics()
+	}
+}
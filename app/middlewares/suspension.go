@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// BlockSuspendedUsers rejects requests from users that are currently suspended
+// Users on read-only mode are let through when allowReadOnly is true, which is used for voting
+func BlockSuspendedUsers(allowReadOnly bool) web.MiddlewareFunc {
+	return func(next web.HandlerFunc) web.HandlerFunc {
+		return func(c *web.Context) error {
+			if !c.IsAuthenticated() {
+				return next(c)
+			}
+
+			getSuspension := &query.GetActiveUserSuspension{UserID: c.User().ID}
+			if err := bus.Dispatch(c, getSuspension); err != nil {
+				return c.Failure(err)
+			}
+
+			suspension := getSuspension.Result
+			if suspension == nil || (suspension.ReadOnly && allowReadOnly) {
+				return next(c)
+			}
+
+			return c.JSON(http.StatusForbidden, web.Map{
+				"errors": []web.Map{
+					{"message": suspensionMessage(c, suspension)},
+				},
+			})
+		}
+	}
+}
+
+func suspensionMessage(c *web.Context, suspension *entity.UserSuspension) string {
+	key := "validation.custom.suspended"
+	if suspension.ReadOnly {
+		key = "validation.custom.readonly"
+	}
+
+	if suspension.EndsAt == nil {
+		return i18n.T(c, key+"indefinitely", i18n.Params{"reason": suspension.Reason})
+	}
+
+	return i18n.T(c, key, i18n.Params{
+		"reason": suspension.Reason,
+		"date":   suspension.EndsAt.UTC().Format("2006-01-02 15:04 UTC"),
+	})
+}
@@ -0,0 +1,102 @@
+package middlewares_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/middlewares"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+func okHandler(c *web.Context) error {
+	return c.NoContent(http.StatusOK)
+}
+
+func TestBlockSuspendedUsers_NotSuspended(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveUserSuspension) error {
+		q.Result = nil
+		return nil
+	})
+
+	server.Use(middlewares.BlockSuspendedUsers(false))
+	status, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		Execute(okHandler)
+
+	Expect(status).Equals(http.StatusOK)
+}
+
+func TestBlockSuspendedUsers_Suspended(t *testing.T) {
+	RegisterT(t)
+
+	endsAt := time.Date(2030, time.March, 4, 10, 30, 0, 0, time.UTC)
+	server := mock.NewServer()
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveUserSuspension) error {
+		q.Result = &entity.UserSuspension{UserID: q.UserID, Reason: "Spamming", EndsAt: &endsAt}
+		return nil
+	})
+
+	server.Use(middlewares.BlockSuspendedUsers(true))
+	status, response := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		Execute(okHandler)
+
+	Expect(status).Equals(http.StatusForbidden)
+	Expect(response.Body.String()).ContainsSubstring("Your account is suspended until 2030-03-04 10:30 UTC. Reason: Spamming")
+}
+
+func TestBlockSuspendedUsers_ReadOnly(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveUserSuspension) error {
+		q.Result = &entity.UserSuspension{UserID: q.UserID, Reason: "Heated discussions", ReadOnly: true}
+		return nil
+	})
+
+	server.Use(middlewares.BlockSuspendedUsers(true))
+	status, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		Execute(okHandler)
+	Expect(status).Equals(http.StatusOK)
+
+	server = mock.NewServer()
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveUserSuspension) error {
+		q.Result = &entity.UserSuspension{UserID: q.UserID, Reason: "Heated discussions", ReadOnly: true}
+		return nil
+	})
+
+	server.Use(middlewares.BlockSuspendedUsers(false))
+	status, response := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		Execute(okHandler)
+	Expect(status).Equals(http.StatusForbidden)
+	Expect(response.Body.String()).ContainsSubstring("Your account is on read-only mode, you can still vote but not post or comment. Reason: Heated discussions")
+}
+
+func TestBlockSuspendedUsers_Anonymous(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	server.Use(middlewares.BlockSuspendedUsers(false))
+	status, _ := server.
+		OnTenant(mock.DemoTenant).
+		Execute(okHandler)
+
+	Expect(status).Equals(http.StatusOK)
+	ExpectHandler(&query.GetActiveUserSuspension{}).CalledTimes(0)
+}
@@ -2,9 +2,11 @@ package middlewares
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/entity"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
@@ -24,8 +26,9 @@ func User() web.MiddlewareFunc {
 	return func(next web.HandlerFunc) web.HandlerFunc {
 		return func(c *web.Context) error {
 			var (
-				token string
-				user  *entity.User
+				token        string
+				user         *entity.User
+				impersonator *entity.User
 			)
 
 			cookie, err := c.Request.Cookie(web.CookieAuthName)
@@ -55,6 +58,25 @@ func User() web.MiddlewareFunc {
 					}
 					return err
 				}
+
+				if claims.ImpersonatorID > 0 {
+					userByImpersonatorID := &query.GetUserByID{UserID: claims.ImpersonatorID}
+					err = bus.Dispatch(c, userByImpersonatorID)
+					impersonator = userByImpersonatorID.Result
+					if err != nil {
+						if errors.Cause(err) == app.ErrNotFound {
+							c.RemoveCookie(web.CookieAuthName)
+							return next(c)
+						}
+						return err
+					}
+
+					// the impersonation ends as soon as the administrator loses its privileges
+					if !impersonator.IsAdministrator() || impersonator.Status != enum.UserActive || impersonator.Tenant.ID != user.Tenant.ID {
+						c.RemoveCookie(web.CookieAuthName)
+						return next(c)
+					}
+				}
 			} else if c.Request.IsAPI() {
 				authHeader := c.Request.GetHeader("Authorization")
 				parts := strings.Split(authHeader, "Bearer")
@@ -103,6 +125,28 @@ func User() web.MiddlewareFunc {
 				}
 
 				c.SetUser(user)
+
+				if impersonator != nil {
+					c.SetImpersonator(impersonator)
+
+					// every write action performed on behalf of another user is recorded with the real actor,
+					// even when the action itself fails and its transaction is rolled back
+					if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+						err := webutil.AddAuditLog(c, &cmd.AddAuditLog{
+							Action:     enum.AuditImpersonatedRequest,
+							TargetType: "user",
+							TargetID:   strconv.Itoa(user.ID),
+							After: entity.AuditValues{
+								"method": c.Request.Method,
+								"path":   c.Request.URL.Path,
+							},
+							Standalone: true,
+						})
+						if err != nil {
+							return err
+						}
+					}
+				}
 			}
 
 			return next(c)
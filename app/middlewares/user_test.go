@@ -10,6 +10,7 @@ import (
 	"github.com/getfider/fider/app"
 
 	"github.com/getfider/fider/app/middlewares"
+	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/entity"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
@@ -426,3 +427,112 @@ func TestUser_Impersonation_ValidUser(t *testing.T) {
 	Expect(status).Equals(http.StatusOK)
 	Expect(response.Body.String()).Equals("Arya Stark")
 }
+
+func TestUser_ImpersonationCookie(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	token, _ := jwt.Encode(jwt.FiderClaims{
+		UserID:         mock.AryaStark.ID,
+		UserName:       mock.AryaStark.Name,
+		ImpersonatorID: mock.JonSnow.ID,
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		if q.UserID == mock.JonSnow.ID {
+			q.Result = mock.JonSnow
+			return nil
+		}
+		if q.UserID == mock.AryaStark.ID {
+			q.Result = mock.AryaStark
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	var auditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		auditLog = c
+		return nil
+	})
+
+	server.Use(middlewares.User())
+	status, response := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieAuthName, token).
+		WithURL("http://demo.test.fider.io/_api/user/settings").
+		ExecutePost(func(c *web.Context) error {
+			return c.String(http.StatusOK, c.User().Name+" by "+c.Impersonator().Name)
+		}, "{}")
+
+	Expect(status).Equals(http.StatusOK)
+	Expect(response.Body.String()).Equals("Arya Stark by Jon Snow")
+	Expect(auditLog.Action).Equals(enum.AuditImpersonatedRequest)
+	Expect(auditLog.TargetID).Equals(strconv.Itoa(mock.AryaStark.ID))
+	Expect(auditLog.After["method"]).Equals("POST")
+	Expect(auditLog.After["path"]).Equals("/_api/user/settings")
+	Expect(auditLog.Standalone).IsTrue()
+}
+
+func TestUser_ImpersonationCookie_ReadOnlyRequest(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	token, _ := jwt.Encode(jwt.FiderClaims{
+		UserID:         mock.AryaStark.ID,
+		ImpersonatorID: mock.JonSnow.ID,
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		if q.UserID == mock.JonSnow.ID {
+			q.Result = mock.JonSnow
+		} else {
+			q.Result = mock.AryaStark
+		}
+		return nil
+	})
+
+	server.Use(middlewares.User())
+	status, _ := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieAuthName, token).
+		Execute(func(c *web.Context) error {
+			return c.String(http.StatusOK, c.User().Name)
+		})
+
+	Expect(status).Equals(http.StatusOK)
+	ExpectHandler(&cmd.AddAuditLog{}).CalledTimes(0)
+}
+
+func TestUser_ImpersonationCookie_ImpersonatorNotAdministrator(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	token, _ := jwt.Encode(jwt.FiderClaims{
+		UserID:         mock.JonSnow.ID,
+		ImpersonatorID: mock.AryaStark.ID,
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		if q.UserID == mock.JonSnow.ID {
+			q.Result = mock.JonSnow
+		} else {
+			q.Result = mock.AryaStark
+		}
+		return nil
+	})
+
+	server.Use(middlewares.User())
+	status, response := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieAuthName, token).
+		Execute(func(c *web.Context) error {
+			if c.IsAuthenticated() {
+				return c.NoContent(http.StatusOK)
+			}
+			return c.NoContent(http.StatusNoContent)
+		})
+
+	Expect(status).Equals(http.StatusNoContent)
+	Expect(response.Header().Get("Set-Cookie")).ContainsSubstring(web.CookieAuthName + "=;")
+}
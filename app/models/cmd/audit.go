@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+)
+
+type AddAuditLog struct {
+	Action     enum.AuditAction
+	TargetType string
+	TargetID   string
+	Before     entity.AuditValues
+	After      entity.AuditValues
+	ClientIP   string
+
+	// Standalone logs are written on their own transaction, so they are kept even when the request fails
+	Standalone bool
+}
@@ -5,12 +5,21 @@ import (
 )
 
 type AddNewComment struct {
-	Post    *entity.Post
-	Content string
+	Post          *entity.Post
+	Content       string
+	PendingReview bool
 
 	Result *entity.Comment
 }
 
+type ApproveComment struct {
+	CommentID int
+}
+
+type HideComment struct {
+	CommentID int
+}
+
 type UpdateComment struct {
 	CommentID int
 	Content   string
@@ -1,10 +1,52 @@
 package cmd
 
-import "github.com/getfider/fider/app/models/dto"
+import (
+	"time"
 
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+)
+
+// SendMail queues an email on the outbox, it's delivered once the current transaction is committed
+// Recipients are grouped by locale, so that each group is rendered in its own language
 type SendMail struct {
 	From         dto.Recipient
 	To           []dto.Recipient
 	TemplateName string
 	Props        dto.Props
+
+	// Template is used instead of the tenant template, e.g. to test a template before saving it
+	Template *entity.EmailTemplate
+}
+
+// DeliverMail sends an email right away using the configured email provider
+type DeliverMail struct {
+	Mail *SendMail
+
+	//Output
+	Sent       []string // addresses accepted by the email provider
+	Suppressed []string // addresses that were skipped because they are empty or not allowed
+}
+
+// SetEmailDeliveryStatus records the result of an attempt to deliver emails from the outbox
+type SetEmailDeliveryStatus struct {
+	IDs           []int64
+	Status        enum.EmailStatus
+	Error         string
+	NextAttemptAt time.Time
+
+	// Standalone statuses are committed on their own transaction, so they are kept even when the delivery of other emails fails
+	Standalone bool
+}
+
+// ResendEmail queues a failed email from the outbox to be delivered again
+type ResendEmail struct {
+	ID int64
+}
+
+// PurgeOutboxEmails deletes delivered and suppressed emails from the outbox once they are older than 30 days
+type PurgeOutboxEmails struct {
+	//Output
+	NumOfDeletedEmails int
 }
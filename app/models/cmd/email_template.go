@@ -0,0 +1,13 @@
+package cmd
+
+// SaveEmailTemplate overrides the default subject and body of given email kind
+type SaveEmailTemplate struct {
+	Kind    string
+	Subject string
+	Body    string
+}
+
+// DeleteEmailTemplate restores the default subject and body of given email kind
+type DeleteEmailTemplate struct {
+	Kind string
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
 )
 
 type MarkAllNotificationsAsRead struct{}
@@ -39,3 +40,21 @@ type SupressEmail struct {
 	//Output
 	NumOfSupressedEmailAddresses int
 }
+
+// ClearEmailSupression allows emails to be sent again to given user of current tenant
+type ClearEmailSupression struct {
+	UserID int
+}
+
+type AddEmailDigestItem struct {
+	User     *entity.User
+	Delivery enum.EmailDelivery
+	PostID   int
+	Title    string
+	Content  string
+}
+
+type DeleteEmailDigestItems struct {
+	Delivery enum.EmailDelivery
+	UpToID   int
+}
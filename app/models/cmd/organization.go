@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/getfider/fider/app/models/entity"
+)
+
+// SaveOrganization updates the organization with given ID
+// When ID is not set, a new organization is created, unless one with the same ExternalID already exists
+type SaveOrganization struct {
+	ID         int
+	ExternalID string
+	Name       string
+	Plan       string
+	Segment    string
+	MRR        float64
+
+	Result *entity.Organization
+}
+
+type DeleteOrganization struct {
+	Organization *entity.Organization
+}
+
+// SetUserOrganization assigns a user to an organization, or removes it from any when OrganizationID is 0
+type SetUserOrganization struct {
+	UserID         int
+	OrganizationID int
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import "github.com/getfider/fider/app/models/entity"
+
+type AddUserPasskey struct {
+	Passkey *entity.UserPasskey
+}
+
+type DeleteCurrentUserPasskey struct {
+	ID int
+}
+
+type SetPasskeyAsUsed struct {
+	ID        int
+	SignCount uint32
+}
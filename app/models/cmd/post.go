@@ -6,12 +6,21 @@ import (
 )
 
 type AddNewPost struct {
-	Title       string
-	Description string
+	Title         string
+	Description   string
+	PendingReview bool
 
 	Result *entity.Post
 }
 
+type ApprovePost struct {
+	Post *entity.Post
+}
+
+type HidePost struct {
+	Post *entity.Post
+}
+
 type UpdatePost struct {
 	Post        *entity.Post
 	Title       string
@@ -0,0 +1,24 @@
+package cmd
+
+import "github.com/getfider/fider/app/models/entity"
+
+// AddPushSubscription registers current user's browser to receive Web Push notifications
+type AddPushSubscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// DeletePushSubscription removes a browser of given user from Web Push notifications
+type DeletePushSubscription struct {
+	UserID   int
+	Endpoint string
+}
+
+// SendWebPush delivers a notification to a single registered browser
+type SendWebPush struct {
+	Subscription *entity.PushSubscription
+	Title        string
+	Body         string
+	URL          string
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import "time"
+
+// HitRateLimit counts one more request on the rate limit identified by given key
+// A new window starts when the previous one has expired
+type HitRateLimit struct {
+	Key    string
+	Window time.Duration
+
+	Result struct {
+		Hits      int
+		ExpiresAt time.Time
+	}
+}
+
+type PurgeExpiredRateLimits struct {
+	NumOfDeletedRateLimits int
+}
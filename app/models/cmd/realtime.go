@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+)
+
+// PublishRealtimeEvent streams an event to the browsers connected to current tenant once the transaction is committed
+// When UserID is zero, the event is sent to every connected user of the tenant
+type PublishRealtimeEvent struct {
+	UserID int
+	Type   enum.RealtimeEventType
+	Data   dto.Props
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+)
+
+type AddContentReport struct {
+	Post      *entity.Post
+	CommentID int
+	Reason    enum.ReportReason
+	Details   string
+}
+
+type ResolveContentReports struct {
+	PostID     int
+	CommentID  int
+	Resolution enum.ReportResolution
+
+	// Result is the list of users that reported the content
+	Result []*entity.User
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+)
+
+// AddSavedSearch follows a view and tags of posts on behalf of current user
+type AddSavedSearch struct {
+	Name     string
+	View     string
+	Tags     []*entity.Tag
+	Channels enum.NotificationChannel
+
+	Result *entity.SavedSearch
+}
+
+// UpdateSavedSearch changes the channels used to notify current user about a saved search
+type UpdateSavedSearch struct {
+	ID       int
+	Channels enum.NotificationChannel
+}
+
+// DeleteSavedSearch stops following a saved search of current user
+type DeleteSavedSearch struct {
+	ID int
+}
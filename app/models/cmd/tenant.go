@@ -24,6 +24,22 @@ type UpdateTenantEmailAuthAllowedSettings struct {
 	IsEmailAuthAllowed bool
 }
 
+type UpdateTenantPasskeySettings struct {
+	PasskeyMode enum.PasskeyMode
+}
+
+type UpdateTenantEmailDomainSettings struct {
+	AllowedEmailDomains []string
+	AutoJoinRole        enum.Role
+}
+
+// RegenerateSSOSecret replaces the secret used to sign single sign-on tokens, which also enables it
+type RegenerateSSOSecret struct {
+	Result string
+}
+
+type DisableSSO struct{}
+
 type UpdateTenantSettings struct {
 	Logo           *dto.ImageUpload
 	Title          string
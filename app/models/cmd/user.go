@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/models/entity"
 	"github.com/getfider/fider/app/models/enum"
@@ -14,6 +16,30 @@ type UnblockUser struct {
 	UserID int
 }
 
+// SuspendUser replaces any active suspension of given user with a new one, EndsAt is nil for suspensions that are only lifted manually
+type SuspendUser struct {
+	UserID   int
+	Reason   string
+	ReadOnly bool
+	EndsAt   *time.Time
+}
+
+type LiftUserSuspension struct {
+	UserID int
+}
+
+type LiftExpiredUserSuspensions struct {
+	NumOfLiftedSuspensions int
+}
+
+type HideUserProfile struct {
+	UserID int
+}
+
+type ShowUserProfile struct {
+	UserID int
+}
+
 type RegenerateAPIKey struct {
 	Result string
 }
@@ -21,6 +47,20 @@ type RegenerateAPIKey struct {
 type DeleteCurrentUser struct {
 }
 
+type EraseUser struct {
+	UserID        int
+	DeleteContent bool
+
+	Result []string
+}
+
+// MergeUsers moves everything owned by the source user to the target user and then deletes the source user
+// Source users that appear on audit logs are kept as anonymized and deleted records, as these logs are never changed
+type MergeUsers struct {
+	SourceUserID int
+	TargetUserID int
+}
+
 type ChangeUserRole struct {
 	UserID int
 	Role   enum.Role
@@ -31,6 +71,11 @@ type ChangeUserEmail struct {
 	Email  string
 }
 
+type ChangeUserName struct {
+	UserID int
+	Name   string
+}
+
 type UpdateCurrentUserSettings struct {
 	Settings map[string]string
 }
@@ -49,4 +94,7 @@ type UpdateCurrentUser struct {
 	Name       string
 	AvatarType enum.AvatarType
 	Avatar     *dto.ImageUpload
+	Bio        string
+	Link       string
+	Locale     string
 }
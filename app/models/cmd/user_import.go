@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/getfider/fider/app/models/entity"
+)
+
+type CreateUserImport struct {
+	Total int
+
+	Result *entity.UserImport
+}
+
+// SaveUserImportProgress stores the current status of an import
+// It is committed right away so that progress is visible while the import is still running
+type SaveUserImportProgress struct {
+	Import *entity.UserImport
+}
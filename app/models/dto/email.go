@@ -1,12 +1,19 @@
 package dto
 
-import "net/mail"
+import (
+	"net/mail"
+
+	"github.com/getfider/fider/app/models/entity"
+)
 
 // Recipient contains details of who is receiving the email
 type Recipient struct {
-	Name    string
-	Address string
-	Props   Props
+	Name        string
+	Address     string
+	Props       Props
+	ReplyTo     string // replies of this recipient are sent to this address instead of the sender
+	Unsubscribe string // URL that unsubscribes this recipient with a single POST request (RFC 8058)
+	Locale      string // locale used to render the email of this recipient, the locale of the context is used when empty
 }
 
 // NewRecipient creates a new Recipient
@@ -31,3 +38,34 @@ func (r Recipient) String() string {
 
 	return address.String()
 }
+
+// InboundEmail is an email received by Fider, e.g: a reply to a notification
+type InboundEmail struct {
+	From       string
+	Recipients []string
+	Subject    string
+	Text       string
+	Bounces    []string // addresses reported as undeliverable when the email is a bounce message or a complaint
+
+	// Authenticated is true when the sender passed SPF or DKIM checks, otherwise From could be forged
+	Authenticated bool
+}
+
+// EmailBatch is an email waiting on the outbox to be delivered to one or more recipients
+type EmailBatch struct {
+	ID           string
+	TenantID     int // zero when the email doesn't belong to a tenant
+	Locale       string
+	From         Recipient
+	TemplateName string
+	Props        Props
+	Template     *entity.EmailTemplate
+	Recipients   []*BatchRecipient
+}
+
+// BatchRecipient is a recipient of an EmailBatch and its outbox email
+type BatchRecipient struct {
+	EmailID  int64
+	Attempts int
+	Recipient
+}
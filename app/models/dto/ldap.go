@@ -0,0 +1,12 @@
+package dto
+
+import "github.com/getfider/fider/app/models/enum"
+
+//LDAPUserProfile represents a user authenticated by a LDAP directory
+type LDAPUserProfile struct {
+	ID    string
+	Name  string
+	Email string
+	// Role is only set when a group-to-role mapping is configured
+	Role enum.Role
+}
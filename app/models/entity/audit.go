@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// AuditLog is an entry of the append-only log of privileged actions
+type AuditLog struct {
+	ID           int              `json:"id"`
+	Actor        *User            `json:"actor"`
+	Impersonated *User            `json:"impersonated,omitempty"`
+	Action       enum.AuditAction `json:"action"`
+	TargetType   string           `json:"targetType"`
+	TargetID     string           `json:"targetId"`
+	Before       AuditValues      `json:"before"`
+	After        AuditValues      `json:"after"`
+	ClientIP     string           `json:"clientIP"`
+	CreatedAt    time.Time        `json:"createdAt"`
+}
+
+// AuditValues is the snapshot of a target before or after an audited action
+type AuditValues map[string]any
+
+func (v AuditValues) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func (v *AuditValues) Scan(src any) error {
+	if src == nil {
+		return nil
+	}
+	values, ok := src.([]byte)
+	if !ok {
+		return errors.New("Invalid data stored in database")
+	}
+	return json.Unmarshal(values, &v)
+}
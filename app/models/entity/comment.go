@@ -4,11 +4,14 @@ import "time"
 
 //Comment represents an user comment on an post
 type Comment struct {
-	ID          int        `json:"id"`
-	Content     string     `json:"content"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	User        *User      `json:"user"`
-	Attachments []string   `json:"attachments,omitempty"`
-	EditedAt    *time.Time `json:"editedAt,omitempty"`
-	EditedBy    *User      `json:"editedBy,omitempty"`
+	ID            int        `json:"id"`
+	Content       string     `json:"content"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	User          *User      `json:"user"`
+	Attachments   []string   `json:"attachments,omitempty"`
+	EditedAt      *time.Time `json:"editedAt,omitempty"`
+	EditedBy      *User      `json:"editedBy,omitempty"`
+	PendingReview bool       `json:"pendingReview,omitempty"`
+	IsHidden      bool       `json:"isHidden,omitempty"`
+	PostID        int        `json:"-"`
 }
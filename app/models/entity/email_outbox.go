@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models/enum"
+)
+
+// OutboxEmail is an email to a single recipient stored on the outbox and its delivery status
+type OutboxEmail struct {
+	ID            int64            `json:"id"`
+	TemplateName  string           `json:"templateName"`
+	ToName        string           `json:"toName"`
+	ToAddress     string           `json:"toAddress"`
+	Status        enum.EmailStatus `json:"status"`
+	Attempts      int              `json:"attempts"`
+	LastError     string           `json:"lastError,omitempty"`
+	NextAttemptAt time.Time        `json:"nextAttemptAt"`
+	CreatedAt     time.Time        `json:"createdAt"`
+	SentAt        *time.Time       `json:"sentAt,omitempty"`
+}
+
+// SupressedEmail is the address of a user that no longer receives emails because it bounced or complained
+type SupressedEmail struct {
+	UserID      int       `json:"userId"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	SupressedAt time.Time `json:"suppressedAt"`
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// EmailTemplate is the subject and body of an email kind customized by the administrators of a tenant
+type EmailTemplate struct {
+	Kind      string    `json:"kind"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
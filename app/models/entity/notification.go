@@ -10,3 +10,13 @@ type Notification struct {
 	Read      bool      `json:"read" db:"read"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
+
+// EmailDigestItem is an event queued to be sent on the next email digest of a user
+type EmailDigestItem struct {
+	ID        int
+	User      *User
+	Post      *Post
+	Title     string
+	Content   string
+	CreatedAt time.Time
+}
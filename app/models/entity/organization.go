@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// Organization is a customer (usually a company) that a group of users belong to
+type Organization struct {
+	ID         int       `json:"id"`
+	ExternalID string    `json:"externalId"`
+	Name       string    `json:"name"`
+	Plan       string    `json:"plan"`
+	Segment    string    `json:"segment"`
+	MRR        float64   `json:"mrr"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// UserPasskey is a WebAuthn credential registered by a user to sign in without email
+type UserPasskey struct {
+	ID           int        `json:"id"`
+	UserID       int        `json:"-"`
+	Name         string     `json:"name"`
+	CredentialID string     `json:"-"`
+	PublicKey    []byte     `json:"-"`
+	SignCount    uint32     `json:"-"`
+	Transports   []string   `json:"-"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	LastUsedAt   *time.Time `json:"lastUsedAt,omitempty"`
+}
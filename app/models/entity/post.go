@@ -22,6 +22,11 @@ type Post struct {
 	Status        enum.PostStatus `json:"status"`
 	Response      *PostResponse   `json:"response,omitempty"`
 	Tags          []string        `json:"tags"`
+	PendingReview bool            `json:"pendingReview,omitempty"`
+	IsHidden      bool            `json:"isHidden,omitempty"`
+
+	OrganizationsCount   int     `json:"organizationsCount,omitempty"`
+	OrganizationsRevenue float64 `json:"organizationsRevenue,omitempty"`
 }
 
 // CanBeVoted returns true if this post can have its vote changed
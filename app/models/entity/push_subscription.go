@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// PushSubscription is a browser or device registered by a user to receive Web Push notifications
+type PushSubscription struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"-"`
+	Endpoint  string    `json:"endpoint"`
+	P256dh    string    `json:"-"`
+	Auth      string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+}
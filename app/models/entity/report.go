@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models/enum"
+)
+
+// ReportedContent is a post or comment with open reports, aggregated for moderators
+type ReportedContent struct {
+	PostID         int                 `json:"postId"`
+	PostNumber     int                 `json:"postNumber"`
+	PostTitle      string              `json:"postTitle"`
+	PostSlug       string              `json:"postSlug"`
+	CommentID      int                 `json:"commentId,omitempty"`
+	Content        string              `json:"content"`
+	Author         *User               `json:"author"`
+	ReportsCount   int                 `json:"reportsCount"`
+	Reasons        []enum.ReportReason `json:"reasons"`
+	Details        []string            `json:"details"`
+	LastReportedAt time.Time           `json:"lastReportedAt"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models/enum"
+)
+
+// SavedSearch is a view and tags of posts followed by a user, who is notified when matching posts are created or change status
+type SavedSearch struct {
+	ID        int                      `json:"id"`
+	UserID    int                      `json:"-"`
+	Name      string                   `json:"name"`
+	View      string                   `json:"view"`
+	Tags      []string                 `json:"tags"`
+	Channels  enum.NotificationChannel `json:"channels"`
+	CreatedAt time.Time                `json:"createdAt"`
+}
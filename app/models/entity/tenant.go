@@ -1,6 +1,10 @@
 package entity
 
-import "github.com/getfider/fider/app/models/enum"
+import (
+	"strings"
+
+	"github.com/getfider/fider/app/models/enum"
+)
 
 // Tenant represents a tenant
 type Tenant struct {
@@ -16,12 +20,39 @@ type Tenant struct {
 	LogoBlobKey        string            `json:"logoBlobKey"`
 	CustomCSS          string            `json:"-"`
 	IsEmailAuthAllowed bool              `json:"isEmailAuthAllowed"`
+	PasskeyMode        enum.PasskeyMode  `json:"passkeyMode"`
+
+	AllowedEmailDomains []string  `json:"-"`
+	AutoJoinRole        enum.Role `json:"-"`
+	SSOSecret           string    `json:"-"`
 }
 
 func (t *Tenant) IsDisabled() bool {
 	return t.Status == enum.TenantDisabled
 }
 
+// IsSSOEnabled returns true if users can sign in with tokens signed by the tenant's own application
+func (t *Tenant) IsSSOEnabled() bool {
+	return t.SSOSecret != ""
+}
+
+// IsEmailDomainAllowed returns true if given email belongs to one of the trusted domains
+// Users with such an email can join a private tenant without being invited
+func (t *Tenant) IsEmailDomainAllowed(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+
+	domain := email[at+1:]
+	for _, allowed := range t.AllowedEmailDomains {
+		if strings.EqualFold(domain, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
 // TenantContact is a reference to an administrator account
 type TenantContact struct {
 	Name      string `json:"name"`
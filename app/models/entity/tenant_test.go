@@ -0,0 +1,23 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app/models/entity"
+	. "github.com/getfider/fider/app/pkg/assert"
+)
+
+func TestTenant_IsEmailDomainAllowed(t *testing.T) {
+	RegisterT(t)
+
+	tenant := &entity.Tenant{}
+	Expect(tenant.IsEmailDomainAllowed("jon.snow@got.com")).IsFalse()
+
+	tenant.AllowedEmailDomains = []string{"got.com", "nightswatch.org"}
+	Expect(tenant.IsEmailDomainAllowed("jon.snow@got.com")).IsTrue()
+	Expect(tenant.IsEmailDomainAllowed("jon.snow@NightsWatch.org")).IsTrue()
+	Expect(tenant.IsEmailDomainAllowed("jon.snow@mail.got.com")).IsFalse()
+	Expect(tenant.IsEmailDomainAllowed("jon.snow@got.com.evil.com")).IsFalse()
+	Expect(tenant.IsEmailDomainAllowed("got.com")).IsFalse()
+	Expect(tenant.IsEmailDomainAllowed("")).IsFalse()
+}
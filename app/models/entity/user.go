@@ -2,6 +2,7 @@ package entity
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/getfider/fider/app/models/enum"
 )
@@ -18,6 +19,7 @@ type User struct {
 	AvatarType    enum.AvatarType `json:"-"`
 	AvatarURL     string          `json:"avatarURL,omitempty"`
 	Status        enum.UserStatus `json:"status"`
+	Locale        string          `json:"-"` // preferred locale, the tenant locale is used when empty
 }
 
 // HasProvider returns true if current user has registered with given provider
@@ -40,6 +42,40 @@ func (u *User) IsAdministrator() bool {
 	return u.Role == enum.RoleAdministrator
 }
 
+// UserProfile is the public information an user shares with everyone on the site
+type UserProfile struct {
+	User     *User  `json:"user"`
+	Bio      string `json:"bio"`
+	Link     string `json:"link"`
+	IsHidden bool   `json:"isHidden"`
+}
+
+// IsVisibleTo returns true if given user can see this profile
+// Hidden profiles are only visible to staff members and the profile owner
+func (p *UserProfile) IsVisibleTo(user *User) bool {
+	if !p.IsHidden {
+		return true
+	}
+	return user != nil && (user.IsCollaborator() || user.ID == p.User.ID)
+}
+
+// UserActivity is what an user has recently published or voted on
+type UserActivity struct {
+	Posts    []*Post        `json:"posts"`
+	Comments []*UserComment `json:"comments"`
+	Votes    []*Post        `json:"votes"`
+}
+
+// UserComment is a comment listed on an user activity along with the post it was written on
+type UserComment struct {
+	ID         int       `json:"id"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"createdAt"`
+	PostNumber int       `json:"postNumber"`
+	PostTitle  string    `json:"postTitle"`
+	PostSlug   string    `json:"postSlug"`
+}
+
 // UserProvider represents the relationship between an User and an Authentication provide
 type UserProvider struct {
 	Name string
@@ -61,3 +97,14 @@ func (umc UserWithEmail) MarshalJSON() ([]byte, error) {
 		Email: umc.User.Email,
 	})
 }
+
+// UserSuspension temporarily restricts what a user can do on the site
+// Suspended users can't post, comment or vote, while users on read-only mode are still allowed to vote
+type UserSuspension struct {
+	ID        int        `json:"id"`
+	UserID    int        `json:"userId"`
+	Reason    string     `json:"reason"`
+	ReadOnly  bool       `json:"readOnly"`
+	CreatedAt time.Time  `json:"createdAt"`
+	EndsAt    *time.Time `json:"endsAt,omitempty"`
+}
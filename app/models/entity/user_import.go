@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// UserImport tracks the progress of a bulk user import
+type UserImport struct {
+	ID         int                   `json:"id"`
+	Status     enum.UserImportStatus `json:"status"`
+	Total      int                   `json:"total"`
+	Processed  int                   `json:"processed"`
+	Created    int                   `json:"created"`
+	Updated    int                   `json:"updated"`
+	Failed     int                   `json:"failed"`
+	Errors     UserImportErrors      `json:"errors"`
+	CreatedAt  time.Time             `json:"createdAt"`
+	FinishedAt *time.Time            `json:"finishedAt,omitempty"`
+}
+
+// UserImportError holds the reasons why a row of an import was rejected
+type UserImportError struct {
+	Row      int      `json:"row"`
+	Messages []string `json:"messages"`
+}
+
+// UserImportErrors is the list of rejected rows of an import
+type UserImportErrors []*UserImportError
+
+func (e UserImportErrors) Value() (driver.Value, error) {
+	if e == nil {
+		return nil, nil
+	}
+	return json.Marshal(e)
+}
+
+func (e *UserImportErrors) Scan(src any) error {
+	if src == nil {
+		return nil
+	}
+	values, ok := src.([]byte)
+	if !ok {
+		return errors.New("Invalid data stored in database")
+	}
+	return json.Unmarshal(values, &e)
+}
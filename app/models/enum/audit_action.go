@@ -0,0 +1,63 @@
+package enum
+
+// AuditAction is the name of a privileged action recorded on the audit log
+type AuditAction string
+
+const (
+	// AuditUserRoleChanged is recorded when an administrator changes the role of a user
+	AuditUserRoleChanged AuditAction = "user.role_changed"
+	// AuditUserBlocked is recorded when a user is blocked
+	AuditUserBlocked AuditAction = "user.blocked"
+	// AuditUserUnblocked is recorded when a user is unblocked
+	AuditUserUnblocked AuditAction = "user.unblocked"
+	// AuditUserSuspended is recorded when a user is suspended or put on read-only mode
+	AuditUserSuspended AuditAction = "user.suspended"
+	// AuditUserSuspensionLifted is recorded when a moderator lifts the suspension of a user
+	AuditUserSuspensionLifted AuditAction = "user.suspension_lifted"
+	// AuditUserProfileHidden is recorded when an administrator hides the public profile of a user
+	AuditUserProfileHidden AuditAction = "user.profile_hidden"
+	// AuditUserProfileShown is recorded when an administrator makes the public profile of a user visible again
+	AuditUserProfileShown AuditAction = "user.profile_shown"
+	// AuditUserErased is recorded when an administrator erases the personal data of a user
+	AuditUserErased AuditAction = "user.erased"
+	// AuditUserExported is recorded when an administrator exports the personal data of a user
+	AuditUserExported AuditAction = "user.exported"
+	// AuditUsersImported is recorded when an administrator imports users from a CSV file
+	AuditUsersImported AuditAction = "users.imported"
+	// AuditUserMerged is recorded when an administrator merges a user into another
+	AuditUserMerged AuditAction = "user.merged"
+	// AuditImpersonationStarted is recorded when an administrator starts impersonating a user
+	AuditImpersonationStarted AuditAction = "impersonation.started"
+	// AuditImpersonationStopped is recorded when an administrator stops impersonating a user
+	AuditImpersonationStopped AuditAction = "impersonation.stopped"
+	// AuditImpersonatedRequest is recorded for every write action performed while impersonating a user
+	AuditImpersonatedRequest AuditAction = "impersonation.request"
+	// AuditPostDeleted is recorded when a post is deleted
+	AuditPostDeleted AuditAction = "post.deleted"
+	// AuditContentReportsResolved is recorded when a moderator resolves the reports of a post or comment
+	AuditContentReportsResolved AuditAction = "reports.resolved"
+	// AuditTagCreated is recorded when a tag is created
+	AuditTagCreated AuditAction = "tag.created"
+	// AuditTagUpdated is recorded when a tag is updated
+	AuditTagUpdated AuditAction = "tag.updated"
+	// AuditTagDeleted is recorded when a tag is deleted
+	AuditTagDeleted AuditAction = "tag.deleted"
+	// AuditWebhookCreated is recorded when a webhook is created
+	AuditWebhookCreated AuditAction = "webhook.created"
+	// AuditWebhookUpdated is recorded when a webhook is updated
+	AuditWebhookUpdated AuditAction = "webhook.updated"
+	// AuditWebhookDeleted is recorded when a webhook is deleted
+	AuditWebhookDeleted AuditAction = "webhook.deleted"
+	// AuditEmailTemplateUpdated is recorded when an email template is customized
+	AuditEmailTemplateUpdated AuditAction = "email_template.updated"
+	// AuditEmailTemplateReset is recorded when an email template is reset to its default
+	AuditEmailTemplateReset AuditAction = "email_template.reset"
+	// AuditEmailResent is recorded when a failed email is queued to be sent again
+	AuditEmailResent AuditAction = "email.resent"
+	// AuditEmailSupressionCleared is recorded when emails are sent again to an address that bounced or complained
+	AuditEmailSupressionCleared AuditAction = "email.suppression_cleared"
+	// AuditOAuthConfigSaved is recorded when an OAuth provider is created or updated
+	AuditOAuthConfigSaved AuditAction = "oauth.saved"
+	// AuditSettingsUpdated is recorded when any of the site settings is updated
+	AuditSettingsUpdated AuditAction = "settings.updated"
+)
@@ -0,0 +1,45 @@
+package enum
+
+// EmailStatus is the delivery status of an email on the outbox
+type EmailStatus int
+
+var (
+	//EmailPending is used while the email is waiting to be sent, including retries
+	EmailPending EmailStatus = 1
+	//EmailSent is used when the email was accepted by the email provider
+	EmailSent EmailStatus = 2
+	//EmailFailed is used when every attempt to send the email failed
+	EmailFailed EmailStatus = 3
+	//EmailSuppressed is used when the email was not sent because its recipient is not allowed
+	EmailSuppressed EmailStatus = 4
+)
+
+var emailStatusIDs = map[EmailStatus]string{
+	EmailPending:    "pending",
+	EmailSent:       "sent",
+	EmailFailed:     "failed",
+	EmailSuppressed: "suppressed",
+}
+
+var emailStatusName = map[string]EmailStatus{
+	"pending":    EmailPending,
+	"sent":       EmailSent,
+	"failed":     EmailFailed,
+	"suppressed": EmailSuppressed,
+}
+
+// String returns the string version of the email status
+func (status EmailStatus) String() string {
+	return emailStatusIDs[status]
+}
+
+// MarshalText returns the Text version of the email status
+func (status EmailStatus) MarshalText() ([]byte, error) {
+	return []byte(emailStatusIDs[status]), nil
+}
+
+// UnmarshalText parse string into an email status
+func (status *EmailStatus) UnmarshalText(text []byte) error {
+	*status = emailStatusName[string(text)]
+	return nil
+}
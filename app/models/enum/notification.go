@@ -12,6 +12,8 @@ var (
 	NotificationChannelWeb NotificationChannel = 1
 	//NotificationChannelEmail is an email notification
 	NotificationChannelEmail NotificationChannel = 2
+	//NotificationChannelPush is a Web Push notification sent to every device registered by the user
+	NotificationChannelPush NotificationChannel = 4
 )
 
 //NotificationEvent represents all possible notification events
@@ -24,7 +26,11 @@ type NotificationEvent struct {
 }
 
 func notificationEventValidation(v string) bool {
-	return v == "0" || v == "1" || v == "2" || v == "3"
+	channels, err := strconv.Atoi(v)
+	if err != nil || strconv.Itoa(channels) != v {
+		return false
+	}
+	return channels >= 0 && channels <= int(NotificationChannelWeb|NotificationChannelEmail|NotificationChannelPush)
 }
 
 var (
@@ -73,3 +79,23 @@ var (
 		NotificationEventChangeStatus,
 	}
 )
+
+//EmailDelivery represents how often a user receives email notifications
+type EmailDelivery string
+
+var (
+	//EmailDeliveryImmediate sends an email as soon as the event happens
+	EmailDeliveryImmediate EmailDelivery = "immediate"
+	//EmailDeliveryDaily groups the events of the last day into a single email
+	EmailDeliveryDaily EmailDelivery = "daily"
+	//EmailDeliveryWeekly groups the events of the last week into a single email
+	EmailDeliveryWeekly EmailDelivery = "weekly"
+)
+
+//EmailDeliverySettingsKeyName is the user setting that holds the email delivery preference
+const EmailDeliverySettingsKeyName = "email_delivery"
+
+//IsValidEmailDelivery returns true if given value is a known email delivery preference
+func IsValidEmailDelivery(v string) bool {
+	return v == string(EmailDeliveryImmediate) || v == string(EmailDeliveryDaily) || v == string(EmailDeliveryWeekly)
+}
@@ -0,0 +1,41 @@
+package enum
+
+// PasskeyMode defines how passkeys can be used to sign in to a tenant
+type PasskeyMode int
+
+var (
+	//PasskeyDisabled prevents users from registering and signing in with passkeys
+	PasskeyDisabled PasskeyMode = 1
+	//PasskeyOptional allows users to register passkeys and sign in with them
+	PasskeyOptional PasskeyMode = 2
+	//PasskeyRequired forces users with a registered passkey to sign in with it
+	PasskeyRequired PasskeyMode = 3
+)
+
+var passkeyModeIDs = map[PasskeyMode]string{
+	PasskeyDisabled: "disabled",
+	PasskeyOptional: "optional",
+	PasskeyRequired: "required",
+}
+
+var passkeyModeNames = map[string]PasskeyMode{
+	"disabled": PasskeyDisabled,
+	"optional": PasskeyOptional,
+	"required": PasskeyRequired,
+}
+
+// String returns the string version of the passkey mode
+func (mode PasskeyMode) String() string {
+	return passkeyModeIDs[mode]
+}
+
+// MarshalText returns the Text version of the passkey mode
+func (mode PasskeyMode) MarshalText() ([]byte, error) {
+	return []byte(passkeyModeIDs[mode]), nil
+}
+
+// UnmarshalText parse string into a passkey mode
+func (mode *PasskeyMode) UnmarshalText(text []byte) error {
+	*mode = passkeyModeNames[string(text)]
+	return nil
+}
@@ -0,0 +1,15 @@
+package enum
+
+// RealtimeEventType is the kind of event streamed to connected browsers
+type RealtimeEventType string
+
+const (
+	// RealtimeNotification is sent to a user when a new notification is added to their inbox
+	RealtimeNotification RealtimeEventType = "notification"
+	// RealtimeComment is sent when a comment is added, edited or deleted
+	RealtimeComment RealtimeEventType = "comment"
+	// RealtimeVote is sent when a vote is added or removed
+	RealtimeVote RealtimeEventType = "vote"
+	// RealtimeStatus is sent when the status of a post changes
+	RealtimeStatus RealtimeEventType = "status"
+)
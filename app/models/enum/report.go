@@ -0,0 +1,39 @@
+package enum
+
+// ReportReason is why a user reported a post or comment
+type ReportReason string
+
+const (
+	// ReportSpam is used for advertising and other unsolicited content
+	ReportSpam ReportReason = "spam"
+	// ReportAbusive is used for offensive, harassing or hateful content
+	ReportAbusive ReportReason = "abusive"
+	// ReportOffTopic is used for content that doesn't belong to the site
+	ReportOffTopic ReportReason = "off-topic"
+	// ReportOther is used when none of the other reasons apply
+	ReportOther ReportReason = "other"
+)
+
+// IsValid returns true if given reason is known
+func (r ReportReason) IsValid() bool {
+	return r == ReportSpam || r == ReportAbusive || r == ReportOffTopic || r == ReportOther
+}
+
+// ReportResolution is how a moderator resolved the reports of a post or comment
+type ReportResolution string
+
+const (
+	// ReportDismissed is used when the content is fine and is kept as it is
+	ReportDismissed ReportResolution = "dismissed"
+	// ReportHidden is used when the content is hidden from everyone but staff members and its author
+	ReportHidden ReportResolution = "hidden"
+	// ReportDeleted is used when the content is deleted
+	ReportDeleted ReportResolution = "deleted"
+	// ReportAuthorBlocked is used when the author of the content is blocked
+	ReportAuthorBlocked ReportResolution = "blocked"
+)
+
+// IsValid returns true if given resolution is known
+func (r ReportResolution) IsValid() bool {
+	return r == ReportDismissed || r == ReportHidden || r == ReportDeleted || r == ReportAuthorBlocked
+}
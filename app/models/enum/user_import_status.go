@@ -0,0 +1,41 @@
+package enum
+
+// UserImportStatus is the status of a bulk user import
+type UserImportStatus int
+
+var (
+	//UserImportRunning is used while rows are still being imported
+	UserImportRunning UserImportStatus = 1
+	//UserImportCompleted is used when every row has been processed
+	UserImportCompleted UserImportStatus = 2
+	//UserImportFailed is used when the import stopped because of an unexpected error
+	UserImportFailed UserImportStatus = 3
+)
+
+var userImportStatusIDs = map[UserImportStatus]string{
+	UserImportRunning:   "running",
+	UserImportCompleted: "completed",
+	UserImportFailed:    "failed",
+}
+
+var userImportStatusName = map[string]UserImportStatus{
+	"running":   UserImportRunning,
+	"completed": UserImportCompleted,
+	"failed":    UserImportFailed,
+}
+
+// String returns the string version of the user import status
+func (status UserImportStatus) String() string {
+	return userImportStatusIDs[status]
+}
+
+// MarshalText returns the Text version of the user import status
+func (status UserImportStatus) MarshalText() ([]byte, error) {
+	return []byte(userImportStatusIDs[status]), nil
+}
+
+// UnmarshalText parse string into a user import status
+func (status *UserImportStatus) UnmarshalText(text []byte) error {
+	*status = userImportStatusName[string(text)]
+	return nil
+}
@@ -0,0 +1,21 @@
+package query
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+)
+
+type SearchAuditLogs struct {
+	Action     enum.AuditAction
+	ActorID    int
+	TargetType string
+	TargetID   string
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+	Offset     int
+
+	Result []*entity.AuditLog
+}
@@ -1,6 +1,12 @@
 package query
 
-import "time"
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+)
 
 type FetchRecentSupressions struct {
 	StartTime time.Time
@@ -8,3 +14,27 @@ type FetchRecentSupressions struct {
 	//Output
 	EmailAddresses []string
 }
+
+// GetSupressedEmails returns the users of current tenant whose email is supressed, most recent first
+type GetSupressedEmails struct {
+	Result []*entity.SupressedEmail
+}
+
+// GetDueEmailBatches returns the emails of all tenants that are due to be delivered
+// and claims them for a while, so that they aren't picked up again while they're being delivered
+type GetDueEmailBatches struct {
+	Limit int
+
+	// Standalone claims are committed on their own transaction, so they don't hold any lock during the delivery
+	Standalone bool
+
+	Result []*dto.EmailBatch
+}
+
+// SearchOutboxEmails returns the most recent emails of current tenant, optionally filtered by status
+type SearchOutboxEmails struct {
+	Status enum.EmailStatus
+	Offset int
+
+	Result []*entity.OutboxEmail
+}
@@ -0,0 +1,15 @@
+package query
+
+import "github.com/getfider/fider/app/models/entity"
+
+// GetEmailTemplate returns the customized template of given email kind, it fails with ErrNotFound when defaults are used
+type GetEmailTemplate struct {
+	Kind string
+
+	Result *entity.EmailTemplate
+}
+
+// ListEmailTemplates returns all email templates customized by current tenant
+type ListEmailTemplates struct {
+	Result []*entity.EmailTemplate
+}
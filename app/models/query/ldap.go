@@ -0,0 +1,12 @@
+package query
+
+import "github.com/getfider/fider/app/models/dto"
+
+// GetLDAPProfile authenticates given credentials against the LDAP directory
+// app.ErrNotFound is returned when the user doesn't exist or the password is wrong
+type GetLDAPProfile struct {
+	Username string
+	Password string
+
+	Result *dto.LDAPUserProfile
+}
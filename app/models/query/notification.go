@@ -25,3 +25,24 @@ type GetActiveSubscribers struct {
 
 	Result []*entity.User
 }
+
+// GetUsersEmailDelivery returns the email delivery preference of given users
+// Users that never changed it are not included, which means they receive emails immediately
+type GetUsersEmailDelivery struct {
+	UserIDs []int
+
+	Result map[int]enum.EmailDelivery
+}
+
+type GetTenantsWithPendingEmailDigests struct {
+	Delivery enum.EmailDelivery
+
+	Result []*entity.Tenant
+}
+
+type GetPendingEmailDigestItems struct {
+	Delivery enum.EmailDelivery
+
+	Result []*entity.EmailDigestItem
+	LastID int
+}
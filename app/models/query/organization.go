@@ -0,0 +1,15 @@
+package query
+
+import (
+	"github.com/getfider/fider/app/models/entity"
+)
+
+type GetOrganizationByID struct {
+	OrganizationID int
+
+	Result *entity.Organization
+}
+
+type GetAllOrganizations struct {
+	Result []*entity.Organization
+}
@@ -0,0 +1,15 @@
+package query
+
+import "github.com/getfider/fider/app/models/entity"
+
+type ListUserPasskeys struct {
+	UserID int
+
+	Result []*entity.UserPasskey
+}
+
+type GetPasskeyByCredentialID struct {
+	CredentialID string
+
+	Result *entity.UserPasskey
+}
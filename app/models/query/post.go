@@ -39,6 +39,10 @@ type SearchPosts struct {
 	Limit string
 	Tags  []string
 
+	// Only applied when searching as a staff member
+	MinOrganizations int
+	MinRevenue       float64
+
 	Result []*entity.Post
 }
 
@@ -0,0 +1,10 @@
+package query
+
+import "github.com/getfider/fider/app/models/entity"
+
+// GetPushSubscriptions returns the browsers registered by given users
+type GetPushSubscriptions struct {
+	UserIDs []int
+
+	Result []*entity.PushSubscription
+}
@@ -0,0 +1,7 @@
+package query
+
+import "github.com/getfider/fider/app/models/entity"
+
+type GetOpenContentReports struct {
+	Result []*entity.ReportedContent
+}
@@ -0,0 +1,21 @@
+package query
+
+import (
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+)
+
+// GetCurrentUserSavedSearches returns the searches followed by current user
+type GetCurrentUserSavedSearches struct {
+	Result []*entity.SavedSearch
+}
+
+// GetSavedSearchFollowers returns the users who follow a saved search that matches given post on given channel
+// When Tag is set, only the searches that include it are matched
+type GetSavedSearchFollowers struct {
+	Post    *entity.Post
+	Channel enum.NotificationChannel
+	Tag     *entity.Tag
+
+	Result []*entity.User
+}
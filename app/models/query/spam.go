@@ -0,0 +1,27 @@
+package query
+
+import "time"
+
+// CheckSpam screens new or edited content of current user before it's published
+type CheckSpam struct {
+	Kind    string // post or comment
+	Title   string
+	Content string
+	IsEdit  bool
+
+	Result struct {
+		IsSpam  bool
+		Reasons []string
+	}
+}
+
+// GetUserContentVelocity returns when a user signed up and how many posts and comments they added since given time
+type GetUserContentVelocity struct {
+	UserID int
+	Since  time.Time
+
+	Result struct {
+		AccountCreatedAt time.Time
+		RecentCount      int
+	}
+}
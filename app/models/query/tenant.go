@@ -42,6 +42,13 @@ type GetTenantByDomain struct {
 	Result *entity.Tenant
 }
 
+type GetTenantByID struct {
+	TenantID int
+
+	// Output
+	Result *entity.Tenant
+}
+
 type GetTrialingTenantContacts struct {
 	TrialExpiresOn time.Time
 
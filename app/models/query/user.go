@@ -44,3 +44,27 @@ type GetUserByProvider struct {
 type GetAllUsers struct {
 	Result []*entity.User
 }
+
+type GetUserProfile struct {
+	UserID int
+
+	Result *entity.UserProfile
+}
+
+type GetUserActivity struct {
+	UserID int
+	Limit  int
+
+	Result *entity.UserActivity
+}
+
+// GetActiveUserSuspension returns the suspension currently applied to given user, or nil when there is none
+type GetActiveUserSuspension struct {
+	UserID int
+
+	Result *entity.UserSuspension
+}
+
+type GetActiveUserSuspensions struct {
+	Result []*entity.UserSuspension
+}
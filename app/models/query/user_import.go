@@ -0,0 +1,11 @@
+package query
+
+import (
+	"github.com/getfider/fider/app/models/entity"
+)
+
+type GetUserImportByID struct {
+	ImportID int
+
+	Result *entity.UserImport
+}
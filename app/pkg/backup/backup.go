@@ -19,10 +19,15 @@ func Create(ctx context.Context) (*bytes.Buffer, error) {
 
 	for _, tableName := range []string{
 		"attachments",
+		"audit_logs",
 		"comments",
+		"content_reports",
+		"email_digest_items",
+		"email_templates",
 		"email_verifications",
 		"notifications",
 		"oauth_providers",
+		"organizations",
 		"posts",
 		"post_subscribers",
 		"post_tags",
@@ -30,8 +35,10 @@ func Create(ctx context.Context) (*bytes.Buffer, error) {
 		"tags",
 		"tenants",
 		"user_providers",
+		"user_saved_searches",
 		"users",
 		"user_settings",
+		"user_suspensions",
 	} {
 		err := addTableDataToZipFile(ctx, zipWriter, tableName)
 		if err != nil {
@@ -0,0 +1,139 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// userTables lists every table holding data tied to a user and the column that references it
+var userTables = []struct {
+	name       string
+	userColumn string
+}{
+	{"user_providers", "user_id"},
+	{"user_settings", "user_id"},
+	{"user_passkeys", "user_id"},
+	{"user_saved_searches", "user_id"},
+	{"posts", "user_id"},
+	{"comments", "user_id"},
+	{"attachments", "user_id"},
+	{"content_reports", "user_id"},
+	{"user_suspensions", "user_id"},
+	{"post_votes", "user_id"},
+	{"post_subscribers", "user_id"},
+	{"notifications", "user_id"},
+	{"email_digest_items", "user_id"},
+}
+
+// CreateUserArchive returns a Zip file with all the data tied to given user
+func CreateUserArchive(ctx context.Context, user *entity.User) (*bytes.Buffer, error) {
+	buffer := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buffer)
+
+	profile, err := exportUserProfile(ctx, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to export user profile")
+	}
+	if err := addFileToZipFile(zipWriter, "users.json", profile); err != nil {
+		return nil, err
+	}
+
+	for _, table := range userTables {
+		tableData, err := exportUserTable(ctx, table.name, table.userColumn, user.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to export %s table", table.name)
+		}
+		if err := addFileToZipFile(zipWriter, fmt.Sprintf("%s.json", table.name), tableData); err != nil {
+			return nil, err
+		}
+	}
+
+	bkeys, err := listUserBlobs(ctx, user)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list user blobs")
+	}
+
+	for _, bkey := range bkeys {
+		if err := addBlobToZipFile(ctx, zipWriter, bkey); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to close zip file")
+	}
+
+	return buffer, nil
+}
+
+func exportUserProfile(ctx context.Context, userID int) ([]byte, error) {
+	trx := ctx.Value(app.TransactionCtxKey).(*dbx.Trx)
+	tenant, _ := ctx.Value(app.TenantCtxKey).(*entity.Tenant)
+
+	// API Key is a credential, not personal data, so it's never exported
+	rows, err := trx.Query(`
+		SELECT id, name, email, role, status, avatar_type, avatar_bkey, bio, link, locale, created_at
+		FROM users WHERE id = $1 AND tenant_id = $2`, userID, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonify(rows))
+}
+
+func exportUserTable(ctx context.Context, tableName, columnName string, userID int) ([]byte, error) {
+	trx := ctx.Value(app.TransactionCtxKey).(*dbx.Trx)
+	tenant, _ := ctx.Value(app.TenantCtxKey).(*entity.Tenant)
+
+	rows, err := trx.Query(fmt.Sprintf("SELECT * FROM %s WHERE %s = $1 AND tenant_id = $2", tableName, columnName), userID, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonify(rows))
+}
+
+func listUserBlobs(ctx context.Context, user *entity.User) ([]string, error) {
+	trx := ctx.Value(app.TransactionCtxKey).(*dbx.Trx)
+	tenant, _ := ctx.Value(app.TenantCtxKey).(*entity.Tenant)
+
+	rows, err := trx.Query("SELECT attachment_bkey FROM attachments WHERE user_id = $1 AND tenant_id = $2", user.ID, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bkeys := make([]string, 0)
+	for rows.Next() {
+		var bkey string
+		if err := rows.Scan(&bkey); err != nil {
+			return nil, err
+		}
+		bkeys = append(bkeys, bkey)
+	}
+
+	if user.AvatarBlobKey != "" {
+		bkeys = append(bkeys, user.AvatarBlobKey)
+	}
+	return bkeys, nil
+}
+
+func addFileToZipFile(zipWriter *zip.Writer, fileName string, content []byte) error {
+	fileWriter, err := zipWriter.Create(fileName)
+	if err != nil {
+		return errors.Wrap(err, "failed to create %s in zip file", fileName)
+	}
+	_, err = fileWriter.Write(content)
+	if err != nil {
+		return errors.Wrap(err, "failed to write %s to zip file", fileName)
+	}
+	return nil
+}
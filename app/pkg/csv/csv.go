@@ -30,6 +30,8 @@ func FromPosts(posts []*entity.Post) ([]byte, error) {
 		"original_number",
 		"original_title",
 		"tags",
+		"organizations_count",
+		"organizations_revenue",
 	}
 	if err := writer.Write(header); err != nil {
 		return nil, err
@@ -69,6 +71,8 @@ func FromPosts(posts []*entity.Post) ([]byte, error) {
 			originalNumber,
 			originalTitle,
 			strings.Join(post.Tags, ", "),
+			strconv.Itoa(post.OrganizationsCount),
+			strconv.FormatFloat(post.OrganizationsRevenue, 'f', 2, 64),
 		}
 		if err := writer.Write(record); err != nil {
 			return nil, err
@@ -70,7 +70,9 @@ var declinedPost = &entity.Post{
 			Name: "John Snow",
 		},
 	},
-	Tags: []string{"easy", "ignored"},
+	Tags:                 []string{"easy", "ignored"},
+	OrganizationsCount:   2,
+	OrganizationsRevenue: 1500.5,
 }
 
 var openPost = &entity.Post{
@@ -0,0 +1,176 @@
+package emailtemplate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/tpl"
+)
+
+// MaxSubjectLength is the maximum length of the subject of a custom email template
+const MaxSubjectLength = 500
+
+const baseFileName = "/views/email/base_email.html"
+
+// Variable is a prop that is available to the template of an email
+type Variable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Kind is an email that tenants can customize
+type Kind struct {
+	Name        string      `json:"name"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Variables   []*Variable `json:"variables"`
+}
+
+var (
+	siteName     = &Variable{"siteName", "Name of the site"}
+	postTitle    = &Variable{"title", "Title of the post"}
+	postLink     = &Variable{"postLink", "HTML link to the post, e.g. #12"}
+	userName     = &Variable{"userName", "Name of the user who triggered the notification"}
+	view         = &Variable{"view", "HTML link to view the post on the browser"}
+	unsubscribe  = &Variable{"unsubscribe", "HTML link to unsubscribe from the post"}
+	stopEvent    = &Variable{"unsubscribe", "HTML link to stop receiving emails about new posts"}
+	change       = &Variable{"change", "HTML link to change the notification preferences"}
+	replyByEmail = &Variable{"replyByEmail", "Whether replying to this email leaves a comment on the post"}
+	logo         = &Variable{"logo", "URL of the site logo, empty when there is none"}
+)
+
+// Kinds are all the emails that tenants can customize
+var Kinds = []*Kind{
+	{
+		Name:        "new_post",
+		Title:       "New post",
+		Description: "Sent to subscribers when a post is created.",
+		Variables:   []*Variable{siteName, postTitle, postLink, userName, {"content", "Description of the post as HTML"}, view, stopEvent, change, replyByEmail, logo},
+	},
+	{
+		Name:        "new_comment",
+		Title:       "New comment",
+		Description: "Sent to subscribers when a post is commented on.",
+		Variables:   []*Variable{siteName, postTitle, postLink, userName, {"content", "Comment as HTML"}, view, unsubscribe, change, replyByEmail, logo},
+	},
+	{
+		Name:        "change_status",
+		Title:       "Status change",
+		Description: "Sent to subscribers when the status of a post changes.",
+		Variables:   []*Variable{siteName, postTitle, postLink, {"status", "New status of the post"}, {"content", "Response to the post as HTML"}, {"duplicate", "HTML link to the original post when it's marked as duplicate, empty otherwise"}, view, unsubscribe, change, replyByEmail, logo},
+	},
+	{
+		Name:        "delete_post",
+		Title:       "Deleted post",
+		Description: "Sent to subscribers when a post is deleted.",
+		Variables:   []*Variable{siteName, postTitle, {"content", "Reason of the deletion as HTML"}, change, logo},
+	},
+	{
+		Name:        "invite_email",
+		Title:       "Invitation",
+		Description: "Sent when an administrator invites people to the site.",
+		Variables:   []*Variable{{"subject", "Subject written on the invitation"}, {"message", "Message written on the invitation as HTML, including the link to join"}, logo},
+	},
+	{
+		Name:        "signin_email",
+		Title:       "Sign in",
+		Description: "Sent when someone signs in by email.",
+		Variables:   []*Variable{siteName, {"link", "HTML link to sign in"}, logo},
+	},
+	{
+		Name:        "change_emailaddress_email",
+		Title:       "Email change",
+		Description: "Sent to confirm a new email address.",
+		Variables:   []*Variable{{"name", "Name of the user"}, {"oldEmail", "Current email address"}, {"newEmail", "New email address"}, {"link", "HTML link to confirm the change"}, logo},
+	},
+	{
+		Name:        "email_digest",
+		Title:       "Email digest",
+		Description: "Sent to users that prefer a daily or weekly summary of their notifications.",
+		Variables:   []*Variable{siteName, {"delivery", "Either 'daily' or 'weekly'"}, {"posts", "List of posts, each with 'title', 'url' and a list of 'items', each with 'title' and 'content' as HTML"}, change, logo},
+	},
+}
+
+// GetKind returns the kind of email with given name, or nil if it can't be customized
+func GetKind(name string) *Kind {
+	for _, kind := range Kinds {
+		if kind.Name == name {
+			return kind
+		}
+	}
+	return nil
+}
+
+// IsCustomizable returns true if tenants can customize emails of given kind
+func IsCustomizable(name string) bool {
+	return GetKind(name) != nil
+}
+
+// Default returns the template Fider uses for given kind when it's not customized
+func Default(kind string) (*entity.EmailTemplate, error) {
+	if !IsCustomizable(kind) {
+		return nil, errors.New("email template '%s' can't be customized", kind)
+	}
+
+	fileName := fmt.Sprintf("/views/email/%s.html", kind)
+	subject, err := tpl.GetBlockSource(fileName, "subject")
+	if err != nil {
+		return nil, err
+	}
+	body, err := tpl.GetBlockSource(fileName, "body")
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.EmailTemplate{
+		Kind:    kind,
+		Subject: subject,
+		Body:    strings.TrimSpace(body),
+	}, nil
+}
+
+// Parse returns the email template ready to be rendered
+func Parse(t *entity.EmailTemplate) (*template.Template, error) {
+	if len(t.Subject) > MaxSubjectLength {
+		return nil, errors.New("subject must have less than %d characters", MaxSubjectLength)
+	}
+	if strings.ContainsAny(t.Subject, "\r\n") {
+		return nil, errors.New("subject must be on a single line")
+	}
+
+	return tpl.GetSandboxedTemplate(baseFileName, map[string]string{
+		"subject": t.Subject,
+		"body":    t.Body,
+	})
+}
+
+// Render executes a parsed email template and returns its subject and body
+func Render(ctx context.Context, tmpl *template.Template, params map[string]any) (subject, body string, err error) {
+	var bf bytes.Buffer
+	if err := tpl.RenderSandboxed(ctx, tmpl, &bf, params); err != nil {
+		return "", "", err
+	}
+
+	lines := strings.Split(bf.String(), "\n")
+	if len(lines) < 3 {
+		return "", "", errors.New("subject must be on a single line")
+	}
+	subject = strings.TrimPrefix(lines[0], "subject: ")
+	body = strings.TrimLeft(strings.Join(lines[2:], "\n"), " ")
+	return subject, body, nil
+}
+
+// Validate returns an error when the template can't be parsed or rendered with sample data
+func Validate(ctx context.Context, t *entity.EmailTemplate) error {
+	tmpl, err := Parse(t)
+	if err != nil {
+		return err
+	}
+	_, _, err = Render(ctx, tmpl, SampleProps(ctx, t.Kind, "", ""))
+	return err
+}
@@ -0,0 +1,72 @@
+package emailtemplate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/entity"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/emailtemplate"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+var ctx = context.WithValue(context.Background(), app.TenantCtxKey, mock.DemoTenant)
+
+func TestDefault_AllKinds(t *testing.T) {
+	RegisterT(t)
+
+	for _, kind := range emailtemplate.Kinds {
+		template, err := emailtemplate.Default(kind.Name)
+		Expect(err).IsNil()
+		Expect(template.Kind).Equals(kind.Name)
+		Expect(template.Subject).IsNotEmpty()
+		Expect(template.Body).IsNotEmpty()
+		Expect(emailtemplate.Validate(ctx, template)).IsNil()
+	}
+}
+
+func TestDefault_NotCustomizable(t *testing.T) {
+	RegisterT(t)
+
+	template, err := emailtemplate.Default("signup_email")
+	Expect(err).IsNotNil()
+	Expect(template).IsNil()
+	Expect(emailtemplate.IsCustomizable("signup_email")).IsFalse()
+	Expect(emailtemplate.IsCustomizable("new_post")).IsTrue()
+}
+
+func TestRender_CustomTemplate(t *testing.T) {
+	RegisterT(t)
+
+	tmpl, err := emailtemplate.Parse(&entity.EmailTemplate{
+		Kind:    "new_post",
+		Subject: "New idea: {{ .title }}",
+		Body:    "<tr><td>{{ .userName }} wrote {{ .content }}</td></tr>",
+	})
+	Expect(err).IsNil()
+
+	subject, body, err := emailtemplate.Render(ctx, tmpl, map[string]any{
+		"title":    "Add dark mode",
+		"userName": "<b>Jon</b>",
+		"content":  "Please",
+	})
+	Expect(err).IsNil()
+	Expect(subject).Equals("New idea: Add dark mode")
+	Expect(body).ContainsSubstring("<tr><td>&lt;b&gt;Jon&lt;/b&gt; wrote Please</td></tr>")
+	Expect(body).ContainsSubstring("<!DOCTYPE html")
+}
+
+func TestValidate_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	for _, template := range []*entity.EmailTemplate{
+		{Kind: "new_post", Subject: "{{ .title", Body: "Hello"},
+		{Kind: "new_post", Subject: "Line 1\nLine 2", Body: "Hello"},
+		{Kind: "new_post", Subject: "Hello", Body: "{{ range .title }}{{ end }}"},
+		{Kind: "new_post", Subject: "Hello", Body: `{{ template "subject" . }}`},
+	} {
+		err := emailtemplate.Validate(ctx, template)
+		Expect(err != nil).IsTrue()
+	}
+}
@@ -0,0 +1,86 @@
+package emailtemplate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/markdown"
+)
+
+// SampleProps returns props with sample data for given kind, used to preview and test templates
+func SampleProps(ctx context.Context, kind, baseURL, logoURL string) dto.Props {
+	siteName := "Fider"
+	if tenant, ok := ctx.Value(app.TenantCtxKey).(*entity.Tenant); ok {
+		siteName = tenant.Name
+	}
+
+	link := func(text, path string) string {
+		return fmt.Sprintf("<a href='%s%s'>%s</a>", baseURL, path, text)
+	}
+	postPath := "/posts/1/add-dark-mode"
+
+	props := dto.Props{
+		"siteName": siteName,
+		"logo":     logoURL,
+	}
+
+	switch kind {
+	case "new_post", "new_comment", "change_status":
+		props["title"] = "Add dark mode"
+		props["postLink"] = link("#1", postPath)
+		props["userName"] = "Jon Snow"
+		props["view"] = link(i18n.T(ctx, "email.subscription.view"), postPath)
+		props["unsubscribe"] = link(i18n.T(ctx, "email.subscription.unsubscribe"), "/unsubscribe/sample")
+		props["change"] = link(i18n.T(ctx, "email.subscription.change"), "/settings")
+		props["replyByEmail"] = env.IsInboundEmailEnabled()
+		props["content"] = markdown.Full("It would be **great** to have a dark theme for late night reading.")
+		if kind == "new_post" {
+			props["unsubscribe"] = link(i18n.T(ctx, "email.subscription.unsubscribe_event"), "/unsubscribe/sample")
+		}
+		if kind == "new_comment" {
+			props["content"] = markdown.Full("I agree, my eyes would **thank** you!")
+		}
+		if kind == "change_status" {
+			props["status"] = i18n.T(ctx, "enum.poststatus.planned")
+			props["duplicate"] = ""
+			props["content"] = markdown.Full("We're working on it and it should be ready next month.")
+		}
+	case "delete_post":
+		props["title"] = "Add dark mode"
+		props["content"] = markdown.Full("This has been requested before, please vote on the original post.")
+		props["change"] = link(i18n.T(ctx, "email.subscription.change"), "/settings")
+	case "invite_email":
+		props["subject"] = fmt.Sprintf("Share your ideas and thoughts about %s", siteName)
+		props["message"] = markdown.Full(fmt.Sprintf("Hi,\n\nWe're inviting you to share your feedback on %s.\n\n%s/invite/verify?k=sample", siteName, baseURL))
+	case "signin_email":
+		props["link"] = link(baseURL+"/signin/verify?k=sample", "/signin/verify?k=sample")
+	case "change_emailaddress_email":
+		props["name"] = "Jon Snow"
+		props["oldEmail"] = "jon.snow@got.com"
+		props["newEmail"] = "jon.snow@winterfell.com"
+		props["link"] = link(baseURL+"/change-email/verify?k=sample", "/change-email/verify?k=sample")
+	case "email_digest":
+		props["delivery"] = "daily"
+		props["change"] = link(i18n.T(ctx, "email.subscription.change"), "/settings")
+		props["posts"] = []dto.Props{
+			{
+				"id":    1,
+				"title": "Add dark mode",
+				"url":   baseURL + postPath,
+				"items": []dto.Props{
+					{
+						"title":   markdown.Full(i18n.T(ctx, "email.digest.new_comment", i18n.Params{"userName": "Arya Stark"})),
+						"content": markdown.Full("I agree, my eyes would **thank** you!"),
+					},
+				},
+			},
+		}
+	}
+
+	return props
+}
@@ -41,6 +41,8 @@ type config struct {
 		ReadTimeout  time.Duration `env:"HTTP_READ_TIMEOUT,default=5s,strict"`
 		WriteTimeout time.Duration `env:"HTTP_WRITE_TIMEOUT,default=10s,strict"`
 		IdleTimeout  time.Duration `env:"HTTP_IDLE_TIMEOUT,default=120s,strict"`
+		// list of IPs or CIDRs separated by comma, X-Forwarded-For entries are only trusted when added by these proxies
+		TrustedProxies string `env:"HTTP_TRUSTED_PROXIES"`
 	}
 	Port       string `env:"PORT,default=3000"`
 	HostMode   string `env:"HOST_MODE,default=single"`
@@ -90,6 +92,22 @@ type config struct {
 			Secret   string `env:"OAUTH_GITHUB_SECRET"`
 		}
 	}
+	LDAP struct {
+		URL                 string `env:"LDAP_URL"` // ldap://host:389 or ldaps://host:636
+		StartTLS            bool   `env:"LDAP_STARTTLS,default=false"`
+		TLSSkipVerify       bool   `env:"LDAP_TLS_SKIP_VERIFY,default=false"`
+		DisplayName         string `env:"LDAP_DISPLAY_NAME,default=LDAP"`
+		BindDN              string `env:"LDAP_BIND_DN"`
+		BindPassword        string `env:"LDAP_BIND_PASSWORD"`
+		BaseDN              string `env:"LDAP_BASE_DN"`
+		SearchFilter        string `env:"LDAP_SEARCH_FILTER,default=(uid={username})"`
+		AttributeID         string `env:"LDAP_ATTRIBUTE_ID,default=uid"`
+		AttributeName       string `env:"LDAP_ATTRIBUTE_NAME,default=cn"`
+		AttributeEmail      string `env:"LDAP_ATTRIBUTE_EMAIL,default=mail"`
+		AttributeGroups     string `env:"LDAP_ATTRIBUTE_GROUPS,default=memberOf"`
+		AdministratorGroups string `env:"LDAP_ADMINISTRATOR_GROUPS"` // list of group DNs separated by semicolon
+		CollaboratorGroups  string `env:"LDAP_COLLABORATOR_GROUPS"`  // list of group DNs separated by semicolon
+	}
 	Email struct {
 		Type      string `env:"EMAIL"` // possible values: smtp, mailgun, awsses
 		NoReply   string `env:"EMAIL_NOREPLY,required"`
@@ -112,6 +130,14 @@ type config struct {
 			Password       string `env:"EMAIL_SMTP_PASSWORD"`
 			EnableStartTLS bool   `env:"EMAIL_SMTP_ENABLE_STARTTLS,default=true"`
 		}
+		// Replies to notifications are sent to {local}+{token}@{domain} of given address and turned into comments
+		Inbound struct {
+			Address  string `env:"EMAIL_INBOUND_ADDRESS"`   // e.g: reply@inbound.mysite.com
+			Secret   string `env:"EMAIL_INBOUND_SECRET"`    // required on the query string of the inbound webhook, which also accepts SES bounce notifications
+			SMTPPort string `env:"EMAIL_INBOUND_SMTP_PORT"` // e.g: 2525, the SMTP listener is disabled when empty
+			// e.g: mx.mysite.com, only Authentication-Results headers added by the mail server with this id are trusted
+			AuthServID string `env:"EMAIL_INBOUND_AUTHSERV_ID"`
+		}
 	}
 	BlobStorage struct {
 		Type string `env:"BLOB_STORAGE,default=sql"` // possible values: sql, fs or s3
@@ -131,6 +157,50 @@ type config struct {
 		Message string `env:"MAINTENANCE_MESSAGE"`
 		Until   string `env:"MAINTENANCE_UNTIL"`
 	}
+	// Each limit has the format {requests}/{window}, e.g: 10/1h. Empty means unlimited
+	RateLimit struct {
+		Enabled bool `env:"RATE_LIMIT_ENABLED,default=true"`
+		SignIn  struct {
+			PerIP     string `env:"RATE_LIMIT_SIGNIN_PER_IP,default=10/1h"`
+			PerEmail  string `env:"RATE_LIMIT_SIGNIN_PER_EMAIL,default=5/1h"`
+			PerTenant string `env:"RATE_LIMIT_SIGNIN_PER_TENANT"`
+		}
+		Posts struct {
+			PerIP     string `env:"RATE_LIMIT_POSTS_PER_IP,default=30/1h"`
+			PerUser   string `env:"RATE_LIMIT_POSTS_PER_USER,default=10/1h"`
+			PerTenant string `env:"RATE_LIMIT_POSTS_PER_TENANT"`
+		}
+		Comments struct {
+			PerIP     string `env:"RATE_LIMIT_COMMENTS_PER_IP,default=120/1h"`
+			PerUser   string `env:"RATE_LIMIT_COMMENTS_PER_USER,default=30/10m"`
+			PerTenant string `env:"RATE_LIMIT_COMMENTS_PER_TENANT"`
+		}
+		Votes struct {
+			PerIP     string `env:"RATE_LIMIT_VOTES_PER_IP,default=300/1h"`
+			PerUser   string `env:"RATE_LIMIT_VOTES_PER_USER,default=60/10m"`
+			PerTenant string `env:"RATE_LIMIT_VOTES_PER_TENANT"`
+		}
+		API struct {
+			PerIP     string `env:"RATE_LIMIT_API_PER_IP,default=1200/1m"`
+			PerUser   string `env:"RATE_LIMIT_API_PER_USER,default=600/1m"`
+			PerTenant string `env:"RATE_LIMIT_API_PER_TENANT"`
+		}
+	}
+	// New posts and comments are checked before being published, those flagged as spam wait for a review
+	SpamFilter struct {
+		Enabled       bool          `env:"SPAM_FILTER_ENABLED,default=true"`
+		MaxLinks      int           `env:"SPAM_FILTER_MAX_LINKS,default=3,strict"`
+		BlockedWords  string        `env:"SPAM_FILTER_BLOCKED_WORDS"` // comma separated list of words and phrases
+		NewAccountAge time.Duration `env:"SPAM_FILTER_NEW_ACCOUNT_AGE,default=24h,strict"`
+		NewAccountMax int           `env:"SPAM_FILTER_NEW_ACCOUNT_MAX,default=5,strict"` // posts and comments within the last hour
+		CheckerURL    string        `env:"SPAM_FILTER_CHECKER_URL"`
+	}
+	// VAPID keys are base64url encoded, the public key as an uncompressed P-256 point and the private key as its raw scalar
+	WebPush struct {
+		VAPIDPublicKey  string `env:"WEBPUSH_VAPID_PUBLIC_KEY"`
+		VAPIDPrivateKey string `env:"WEBPUSH_VAPID_PRIVATE_KEY"`
+		Subject         string `env:"WEBPUSH_SUBJECT"` // mailto: or https: contact of the push service operator, defaults to EMAIL_NOREPLY
+	}
 	GoogleAnalytics string `env:"GOOGLE_ANALYTICS"`
 }
 
@@ -232,6 +302,22 @@ func IsBillingEnabled() bool {
 	return Config.Paddle.VendorID != "" && Config.Paddle.VendorAuthCode != ""
 }
 
+// IsLDAPEnabled returns true if a LDAP server is configured
+// The server is set for the whole instance, so it's only used when hosting a single tenant
+func IsLDAPEnabled() bool {
+	return IsSingleHostMode() && Config.LDAP.URL != ""
+}
+
+// IsInboundEmailEnabled returns true if replies to notifications are accepted by email
+func IsInboundEmailEnabled() bool {
+	return Config.Email.Inbound.Address != ""
+}
+
+// IsWebPushEnabled returns true if VAPID keys are configured
+func IsWebPushEnabled() bool {
+	return Config.WebPush.VAPIDPublicKey != "" && Config.WebPush.VAPIDPrivateKey != ""
+}
+
 // IsProduction returns true on Fider production environment
 func IsProduction() bool {
 	return Config.Environment == "production" || (!IsTest() && !IsDevelopment())
@@ -66,3 +66,16 @@ func TestSubdomain(t *testing.T) {
 	Expect(env.Subdomain("test.fidercdn.com")).Equals("")
 	Expect(env.Subdomain("helloworld.com")).Equals("")
 }
+
+func TestIsLDAPEnabled(t *testing.T) {
+	RegisterT(t)
+
+	Expect(env.IsLDAPEnabled()).IsFalse()
+
+	env.Config.LDAP.URL = "ldap://localhost:1389"
+	env.Config.HostMode = "multi"
+	Expect(env.IsLDAPEnabled()).IsFalse()
+
+	env.Config.HostMode = "single"
+	Expect(env.IsLDAPEnabled()).IsTrue()
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/getfider/fider/app"
@@ -102,6 +103,40 @@ func IsValidLocale(locale string) bool {
 	return false
 }
 
+// WithLocale returns a context that translates messages to given locale
+// Invalid locales are ignored, so the locale defined in context is kept
+func WithLocale(ctx context.Context, locale string) context.Context {
+	if !IsValidLocale(locale) {
+		return ctx
+	}
+	return context.WithValue(ctx, app.LocaleCtxKey, locale)
+}
+
+// DetectLocale returns the supported locale that best matches an Accept-Language header
+// Quality values are ignored as browsers list languages in order of preference
+// An empty string is returned when none of the languages is supported
+func DetectLocale(acceptLanguage string) string {
+	for _, tag := range strings.Split(acceptLanguage, ",") {
+		tag = strings.TrimSpace(strings.Split(tag, ";")[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		language := strings.ToLower(strings.Split(strings.ReplaceAll(tag, "_", "-"), "-")[0])
+		for locale := range localeToPlurals {
+			if strings.EqualFold(locale, tag) {
+				return locale
+			}
+		}
+		for locale := range localeToPlurals {
+			if strings.ToLower(strings.Split(locale, "-")[0]) == language {
+				return locale
+			}
+		}
+	}
+	return ""
+}
+
 // GetLocale returns the locale defined in context
 // If it is not defined, the environment locale is used
 func GetLocale(ctx context.Context) string {
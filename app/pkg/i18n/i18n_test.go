@@ -81,3 +81,25 @@ func TestIsValidLocale(t *testing.T) {
 	Expect(i18n.IsValidLocale("")).IsFalse()
 	Expect(i18n.IsValidLocale("xx")).IsFalse()
 }
+
+func TestWithLocale(t *testing.T) {
+	RegisterT(t)
+
+	Expect(i18n.GetLocale(i18n.WithLocale(enContext, "fr"))).Equals("fr")
+	Expect(i18n.GetLocale(i18n.WithLocale(ptBRContext, ""))).Equals("pt-BR")
+	Expect(i18n.GetLocale(i18n.WithLocale(ptBRContext, "xx"))).Equals("pt-BR")
+}
+
+func TestDetectLocale(t *testing.T) {
+	RegisterT(t)
+
+	Expect(i18n.DetectLocale("fr-FR,fr;q=0.9,en-US;q=0.8,en;q=0.7")).Equals("fr")
+	Expect(i18n.DetectLocale("de-CH")).Equals("de")
+	Expect(i18n.DetectLocale("pt-BR,pt;q=0.9")).Equals("pt-BR")
+	Expect(i18n.DetectLocale("pt-PT")).Equals("pt-BR")
+	Expect(i18n.DetectLocale("sv")).Equals("sv-SE")
+	Expect(i18n.DetectLocale("ja-JP,ja;q=0.9,en;q=0.8")).Equals("en")
+	Expect(i18n.DetectLocale("ja-JP")).Equals("")
+	Expect(i18n.DetectLocale("*")).Equals("")
+	Expect(i18n.DetectLocale("")).Equals("")
+}
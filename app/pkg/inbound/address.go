@@ -0,0 +1,179 @@
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// ReplyAddressLifetime is how long a reply address accepts emails after the notification has been sent
+var ReplyAddressLifetime = 30 * 24 * time.Hour
+
+// ReplyToken identifies who is replying to which post
+type ReplyToken struct {
+	TenantID   int
+	UserID     int
+	PostNumber int
+}
+
+// payload includes the hour when the address expires, so that a leaked address can't be used forever
+func (t ReplyToken) payload(expiresAt int64) string {
+	return strings.Join([]string{
+		strconv.FormatInt(int64(t.TenantID), 36),
+		strconv.FormatInt(int64(t.UserID), 36),
+		strconv.FormatInt(int64(t.PostNumber), 36),
+		strconv.FormatInt(expiresAt, 36),
+	}, ".")
+}
+
+// signature is lowercase so that it survives mail servers that don't preserve the case of local parts
+func (t ReplyToken) signature(expiresAt int64) string {
+	mac := hmac.New(sha256.New, []byte(env.Config.JWTSecret))
+	mac.Write([]byte("reply:" + t.payload(expiresAt)))
+	return hex.EncodeToString(mac.Sum(nil))[:20]
+}
+
+func splitAddress(address string) (local, domain string, ok bool) {
+	at := strings.LastIndex(address, "@")
+	if at <= 0 || at == len(address)-1 {
+		return "", "", false
+	}
+	return address[:at], address[at+1:], true
+}
+
+// ReplyAddress returns the address that accepts replies of given user to given post
+// It's empty when inbound email is not configured
+func ReplyAddress(token ReplyToken) string {
+	local, domain, ok := splitAddress(env.Config.Email.Inbound.Address)
+	if !env.IsInboundEmailEnabled() || !ok {
+		return ""
+	}
+	expiresAt := time.Now().Add(ReplyAddressLifetime).Unix() / 3600
+	return fmt.Sprintf("%s+%s.%s@%s", local, token.payload(expiresAt), token.signature(expiresAt), domain)
+}
+
+// ParseReplyAddress verifies that given address has been generated by ReplyAddress and returns its token
+func ParseReplyAddress(address string) (*ReplyToken, error) {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		address = parsed.Address
+	}
+
+	local, domain, ok := splitAddress(strings.ToLower(strings.TrimSpace(address)))
+	expectedLocal, expectedDomain, _ := splitAddress(strings.ToLower(env.Config.Email.Inbound.Address))
+	if !ok || !env.IsInboundEmailEnabled() || domain != expectedDomain {
+		return nil, errors.New("'%s' is not a reply address", address)
+	}
+
+	prefix := expectedLocal + "+"
+	if !strings.HasPrefix(local, prefix) {
+		return nil, errors.New("'%s' is not a reply address", address)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(local, prefix), ".")
+	if len(parts) != 5 {
+		return nil, errors.New("'%s' is not a reply address", address)
+	}
+
+	ids := make([]int, 3)
+	for i, part := range parts[:3] {
+		id, err := strconv.ParseInt(part, 36, 32)
+		if err != nil || id <= 0 {
+			return nil, errors.New("'%s' is not a reply address", address)
+		}
+		ids[i] = int(id)
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[3], 36, 64)
+	if err != nil {
+		return nil, errors.New("'%s' is not a reply address", address)
+	}
+
+	token := &ReplyToken{TenantID: ids[0], UserID: ids[1], PostNumber: ids[2]}
+	if !hmac.Equal([]byte(parts[4]), []byte(token.signature(expiresAt))) {
+		return nil, errors.New("'%s' has an invalid signature", address)
+	}
+
+	if time.Now().Unix() >= expiresAt*3600 {
+		return nil, errors.New("'%s' has expired", address)
+	}
+
+	return token, nil
+}
+
+// FindReplyToken returns the token of the first reply address among given recipients
+func FindReplyToken(recipients []string) *ReplyToken {
+	for _, recipient := range recipients {
+		if token, err := ParseReplyAddress(recipient); err == nil {
+			return token
+		}
+	}
+	return nil
+}
+
+// bounceSignature ties a bounce address to the recipient it was used for, so that a forged bounce message can't suppress other addresses
+func bounceSignature(recipient string) string {
+	mac := hmac.New(sha256.New, []byte(env.Config.JWTSecret))
+	mac.Write([]byte("bounce:" + strings.ToLower(strings.TrimSpace(recipient))))
+	return hex.EncodeToString(mac.Sum(nil))[:20]
+}
+
+// BounceAddress returns the envelope sender of emails to given recipient, so that bounce messages are sent back to Fider
+// It's empty when inbound email is not configured
+func BounceAddress(recipient string) string {
+	local, domain, ok := splitAddress(env.Config.Email.Inbound.Address)
+	if !env.IsInboundEmailEnabled() || !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s+bounce.%s@%s", local, bounceSignature(recipient), domain)
+}
+
+func parseBounceAddress(address string) (signature string, ok bool) {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		address = parsed.Address
+	}
+
+	local, domain, ok := splitAddress(strings.ToLower(strings.TrimSpace(address)))
+	expectedLocal, expectedDomain, _ := splitAddress(strings.ToLower(env.Config.Email.Inbound.Address))
+	if !ok || !env.IsInboundEmailEnabled() || domain != expectedDomain {
+		return "", false
+	}
+
+	prefix := expectedLocal + "+bounce."
+	if !strings.HasPrefix(local, prefix) || len(local) == len(prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(local, prefix), true
+}
+
+// IsBounceAddress returns true if given address looks like one generated by BounceAddress
+func IsBounceAddress(address string) bool {
+	_, ok := parseBounceAddress(address)
+	return ok
+}
+
+// VerifiedBounces returns the bounced addresses of given email that match the signature of the bounce address it was sent to
+func VerifiedBounces(email *dto.InboundEmail) []string {
+	signatures := make(map[string]bool)
+	for _, recipient := range email.Recipients {
+		if signature, ok := parseBounceAddress(recipient); ok {
+			signatures[signature] = true
+		}
+	}
+
+	verified := make([]string, 0)
+	for _, address := range email.Bounces {
+		if signatures[bounceSignature(address)] {
+			verified = append(verified, address)
+		}
+	}
+	return verified
+}
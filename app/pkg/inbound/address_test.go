@@ -0,0 +1,120 @@
+package inbound_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/models/dto"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/inbound"
+)
+
+func TestReplyAddress_Disabled(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Address = ""
+
+	Expect(inbound.ReplyAddress(inbound.ReplyToken{TenantID: 1, UserID: 2, PostNumber: 3})).Equals("")
+}
+
+func TestReplyAddress_RoundTrip(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Address = "reply@inbound.got.com"
+
+	token := inbound.ReplyToken{TenantID: 1, UserID: 2000, PostNumber: 35}
+	address := inbound.ReplyAddress(token)
+	Expect(strings.HasPrefix(address, "reply+1.1jk.z.")).IsTrue()
+	Expect(strings.HasSuffix(address, "@inbound.got.com")).IsTrue()
+
+	parsed, err := inbound.ParseReplyAddress(address)
+	Expect(err).IsNil()
+	Expect(*parsed).Equals(token)
+
+	parsed, err = inbound.ParseReplyAddress("Fider <" + strings.ToUpper(address) + ">")
+	Expect(err).IsNil()
+	Expect(*parsed).Equals(token)
+}
+
+func TestParseReplyAddress_Invalid(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Address = "reply@inbound.got.com"
+
+	address := inbound.ReplyAddress(inbound.ReplyToken{TenantID: 1, UserID: 2, PostNumber: 3})
+	local, _, _ := strings.Cut(address, "@")
+
+	for _, invalid := range []string{
+		"",
+		"reply@inbound.got.com",
+		"jon.snow@got.com",
+		local + "@got.com",
+		strings.Replace(address, "reply+1.2.3.", "reply+1.2.4.", 1),
+		strings.Replace(address, "reply+", "other+", 1),
+		"reply+1.2.3@inbound.got.com",
+		"reply+0.2.3.abc@inbound.got.com",
+	} {
+		token, err := inbound.ParseReplyAddress(invalid)
+		Expect(err).IsNotNil()
+		Expect(token).IsNil()
+	}
+}
+
+func TestParseReplyAddress_Expired(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Address = "reply@inbound.got.com"
+
+	lifetime := inbound.ReplyAddressLifetime
+	defer func() { inbound.ReplyAddressLifetime = lifetime }()
+
+	inbound.ReplyAddressLifetime = -2 * time.Hour
+	token, err := inbound.ParseReplyAddress(inbound.ReplyAddress(inbound.ReplyToken{TenantID: 1, UserID: 2, PostNumber: 3}))
+	Expect(err).IsNotNil()
+	Expect(token).IsNil()
+}
+
+func TestFindReplyToken(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Address = "reply@inbound.got.com"
+
+	token := inbound.ReplyToken{TenantID: 4, UserID: 5, PostNumber: 6}
+	Expect(inbound.FindReplyToken([]string{"jon.snow@got.com"})).IsNil()
+	Expect(*inbound.FindReplyToken([]string{"jon.snow@got.com", inbound.ReplyAddress(token)})).Equals(token)
+}
+
+func TestBounceAddress(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Address = ""
+	Expect(inbound.BounceAddress("jon.snow@got.com")).Equals("")
+
+	env.Config.Email.Inbound.Address = "reply@inbound.got.com"
+	address := inbound.BounceAddress("jon.snow@got.com")
+	Expect(strings.HasPrefix(address, "reply+bounce.")).IsTrue()
+	Expect(strings.HasSuffix(address, "@inbound.got.com")).IsTrue()
+	Expect(inbound.BounceAddress("Jon.Snow@got.com")).Equals(address)
+
+	Expect(inbound.IsBounceAddress(address)).IsTrue()
+	Expect(inbound.IsBounceAddress("<" + strings.ToUpper(address) + ">")).IsTrue()
+	Expect(inbound.IsBounceAddress("reply+bounce.@inbound.got.com")).IsFalse()
+	Expect(inbound.IsBounceAddress("reply+bounce.abc@got.com")).IsFalse()
+	Expect(inbound.IsBounceAddress(inbound.ReplyAddress(inbound.ReplyToken{TenantID: 1, UserID: 2, PostNumber: 3}))).IsFalse()
+
+	_, err := inbound.ParseReplyAddress(address)
+	Expect(err).IsNotNil()
+}
+
+func TestVerifiedBounces(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Address = "reply@inbound.got.com"
+
+	verified := inbound.VerifiedBounces(&dto.InboundEmail{
+		Recipients: []string{inbound.BounceAddress("jon.snow@got.com")},
+		Bounces:    []string{"jon.snow@got.com", "arya.stark@got.com"},
+	})
+	Expect(verified).Equals([]string{"jon.snow@got.com"})
+
+	verified = inbound.VerifiedBounces(&dto.InboundEmail{
+		Recipients: []string{"reply+bounce.abc@inbound.got.com"},
+		Bounces:    []string{"jon.snow@got.com"},
+	})
+	Expect(verified).HasLen(0)
+}
@@ -0,0 +1,124 @@
+package inbound
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// parseReport reads a multipart/report message and returns the recipients that it reports as undeliverable
+// Delivery status notifications (RFC 3464) report recipients that permanently failed
+// and abuse reports (RFC 5965) report recipients that complained about an email
+func parseReport(params map[string]string, body io.Reader) ([]string, error) {
+	var (
+		bounces     = make([]string, 0)
+		isComplaint bool
+		returnedTo  []string
+	)
+
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read report")
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch mediaType {
+		case "message/delivery-status", "message/global-delivery-status":
+			groups, err := readFieldGroups(decodeTransfer(part.Header, part))
+			if err != nil {
+				return nil, err
+			}
+			// the first group has per-message fields, all others are about a single recipient
+			for _, fields := range groups {
+				if isPermanentFailure(fields) {
+					if address := reportedAddress(fields.Get("Final-Recipient"), fields.Get("Original-Recipient")); address != "" {
+						bounces = append(bounces, address)
+					}
+				}
+			}
+		case "message/feedback-report":
+			groups, err := readFieldGroups(decodeTransfer(part.Header, part))
+			if err != nil {
+				return nil, err
+			}
+			for _, fields := range groups {
+				if !strings.EqualFold(fields.Get("Feedback-Type"), "abuse") {
+					continue
+				}
+				isComplaint = true
+				for _, rcpt := range fields.Values("Original-Rcpt-To") {
+					if address := reportedAddress(rcpt); address != "" {
+						bounces = append(bounces, address)
+					}
+				}
+			}
+		case "message/rfc822", "text/rfc822-headers":
+			// Original-Rcpt-To is often redacted from complaints, so the returned email is the only other clue
+			msg, err := mail.ReadMessage(bufio.NewReader(decodeTransfer(part.Header, part)))
+			if err != nil {
+				continue
+			}
+			if list, err := msg.Header.AddressList("To"); err == nil {
+				for _, address := range list {
+					returnedTo = append(returnedTo, address.Address)
+				}
+			}
+		}
+	}
+
+	if isComplaint && len(bounces) == 0 {
+		bounces = append(bounces, returnedTo...)
+	}
+
+	return bounces, nil
+}
+
+// readFieldGroups reads blocks of header-like fields separated by blank lines
+func readFieldGroups(r io.Reader) ([]textproto.MIMEHeader, error) {
+	groups := make([]textproto.MIMEHeader, 0)
+	reader := textproto.NewReader(bufio.NewReader(r))
+	for {
+		fields, err := reader.ReadMIMEHeader()
+		if len(fields) > 0 {
+			groups = append(groups, fields)
+		}
+		if err == io.EOF {
+			return groups, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read report fields")
+		}
+	}
+}
+
+// isPermanentFailure returns true if a delivery status is failed and not a temporary (4.X.X) error
+func isPermanentFailure(fields textproto.MIMEHeader) bool {
+	if !strings.EqualFold(strings.TrimSpace(fields.Get("Action")), "failed") {
+		return false
+	}
+	return !strings.HasPrefix(strings.TrimSpace(fields.Get("Status")), "4")
+}
+
+// reportedAddress returns the first valid address among given fields, which might be prefixed with their type, e.g: rfc822; jon.snow@got.com
+func reportedAddress(values ...string) string {
+	for _, value := range values {
+		if _, address, found := strings.Cut(value, ";"); found {
+			value = address
+		}
+		if parsed, err := mail.ParseAddress(strings.TrimSpace(value)); err == nil {
+			return parsed.Address
+		}
+	}
+	return ""
+}
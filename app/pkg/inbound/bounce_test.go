@@ -0,0 +1,104 @@
+package inbound_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/inbound"
+)
+
+func reportMessage(reportType, report string) string {
+	return strings.ReplaceAll(`From: Mail Delivery System <MAILER-DAEMON@mx.got.com>
+To: reply+bounce.abc@inbound.got.com
+Subject: Undelivered Mail Returned to Sender
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=`+reportType+`; boundary="XYZ"
+
+--XYZ
+Content-Type: text/plain; charset="UTF-8"
+
+This is the mail system at host mx.got.com.
+--XYZ
+`+report+`
+--XYZ
+Content-Type: text/rfc822-headers
+
+From: Fider <noreply@got.com>
+To: Jon Snow <jon.snow@got.com>
+Subject: New post
+
+--XYZ--
+`, "\n", "\r\n")
+}
+
+func TestParseMessage_DeliveryStatusNotification(t *testing.T) {
+	RegisterT(t)
+
+	email, err := inbound.ParseMessage(strings.NewReader(reportMessage("delivery-status", `Content-Type: message/delivery-status
+
+Reporting-MTA: dns; mx.got.com
+Arrival-Date: Mon, 1 Jan 2024 10:00:00 +0000
+
+Final-Recipient: rfc822; jon.snow@got.com
+Original-Recipient: rfc822;jon.snow@got.com
+Action: failed
+Status: 5.1.1
+Diagnostic-Code: smtp; 550 5.1.1 User unknown
+
+Final-Recipient: rfc822; arya.stark@got.com
+Action: delayed
+Status: 4.4.1
+
+Final-Recipient: rfc822; <sansa.stark@got.com>
+Action: failed
+Status: 5.2.2
+`)))
+	Expect(err).IsNil()
+	Expect(email.From).Equals("MAILER-DAEMON@mx.got.com")
+	Expect(email.Recipients).Equals([]string{"reply+bounce.abc@inbound.got.com"})
+	Expect(email.Bounces).Equals([]string{"jon.snow@got.com", "sansa.stark@got.com"})
+	Expect(email.Text).Equals("")
+}
+
+func TestParseMessage_DelayedDeliveryStatusNotification(t *testing.T) {
+	RegisterT(t)
+
+	email, err := inbound.ParseMessage(strings.NewReader(reportMessage("delivery-status", `Content-Type: message/delivery-status
+
+Reporting-MTA: dns; mx.got.com
+
+Final-Recipient: rfc822; jon.snow@got.com
+Action: delayed
+Status: 4.4.1
+`)))
+	Expect(err).IsNil()
+	Expect(email.Bounces).HasLen(0)
+}
+
+func TestParseMessage_AbuseReport(t *testing.T) {
+	RegisterT(t)
+
+	email, err := inbound.ParseMessage(strings.NewReader(reportMessage("feedback-report", `Content-Type: message/feedback-report
+
+Feedback-Type: abuse
+User-Agent: SomeGenerator/1.0
+Version: 1
+Original-Rcpt-To: <arya.stark@got.com>
+`)))
+	Expect(err).IsNil()
+	Expect(email.Bounces).Equals([]string{"arya.stark@got.com"})
+}
+
+func TestParseMessage_RedactedAbuseReport(t *testing.T) {
+	RegisterT(t)
+
+	email, err := inbound.ParseMessage(strings.NewReader(reportMessage("feedback-report", `Content-Type: message/feedback-report
+
+Feedback-Type: abuse
+User-Agent: SomeGenerator/1.0
+Version: 1
+`)))
+	Expect(err).IsNil()
+	Expect(email.Bounces).Equals([]string{"jon.snow@got.com"})
+}
@@ -0,0 +1,253 @@
+package inbound
+
+import (
+	"encoding/base64"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// MaxMessageSize is the largest inbound email that is accepted, attachments included
+const MaxMessageSize = 10 << 20
+
+var mimeWordDecoder = &mime.WordDecoder{
+	CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+		return decodeCharset(charset, input)
+	},
+}
+
+// ParseMessage reads a raw RFC 5322 message and extracts what's needed to turn it into a comment
+func ParseMessage(r io.Reader) (*dto.InboundEmail, error) {
+	msg, err := mail.ReadMessage(io.LimitReader(r, MaxMessageSize))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read message")
+	}
+
+	email := &dto.InboundEmail{
+		Recipients: make([]string, 0),
+	}
+
+	if from, err := mail.ParseAddress(msg.Header.Get("From")); err == nil {
+		email.From = from.Address
+		email.Authenticated = isAuthenticated(msg.Header, from.Address)
+	}
+
+	for _, name := range []string{"To", "Cc", "Delivered-To", "X-Original-To"} {
+		list, err := msg.Header.AddressList(name)
+		if err != nil {
+			continue
+		}
+		for _, address := range list {
+			email.Recipients = append(email.Recipients, address.Address)
+		}
+	}
+
+	email.Subject, err = mimeWordDecoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		email.Subject = msg.Header.Get("Subject")
+	}
+
+	// Bounce messages and complaints are reports about an email sent by Fider, so there's no text to read
+	if mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type")); err == nil && mediaType == "multipart/report" {
+		email.Bounces, err = parseReport(params, msg.Body)
+		if err != nil {
+			return nil, err
+		}
+		return email, nil
+	}
+
+	plain, htm, err := readBody(msg.Header, msg.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	email.Text = plain
+	if strings.TrimSpace(email.Text) == "" {
+		email.Text = HTMLToText(htm)
+	}
+
+	return email, nil
+}
+
+// isAuthenticated returns true if the Authentication-Results of our own mail server report that
+// the domain of given sender passed DMARC, DKIM or SPF checks
+// Results added by any other server are ignored, they could have been written by the sender
+func isAuthenticated(h mail.Header, from string) bool {
+	authServID := strings.ToLower(env.Config.Email.Inbound.AuthServID)
+	_, fromDomain, ok := splitAddress(strings.ToLower(from))
+	if authServID == "" || !ok {
+		return false
+	}
+
+	for _, value := range h["Authentication-Results"] {
+		results := strings.Split(strings.ToLower(value), ";")
+		if id := strings.Fields(results[0]); len(id) == 0 || id[0] != authServID {
+			continue
+		}
+
+		for _, result := range results[1:] {
+			fields := strings.Fields(result)
+			if len(fields) == 0 || (fields[0] != "dmarc=pass" && fields[0] != "dkim=pass" && fields[0] != "spf=pass") {
+				continue
+			}
+
+			// the domain that passed has to be the one of the sender, e.g: "dkim=pass header.d=got.com"
+			for _, property := range fields[1:] {
+				key, domain, _ := strings.Cut(property, "=")
+				if key != "header.d" && key != "header.from" && key != "smtp.mailfrom" {
+					continue
+				}
+				if _, addressDomain, ok := splitAddress(domain); ok {
+					domain = addressDomain
+				}
+				if isAlignedDomain(domain, fromDomain) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isAlignedDomain returns true if given authenticated domain is the domain of the sender or one of its parents
+func isAlignedDomain(domain, fromDomain string) bool {
+	return domain != "" && (domain == fromDomain || strings.HasSuffix(fromDomain, "."+domain))
+}
+
+// header is implemented by both mail.Header and textproto.MIMEHeader
+type header interface {
+	Get(key string) string
+}
+
+// readBody walks through the MIME tree and returns the first text/plain and text/html parts it finds
+func readBody(h header, body io.Reader) (plain string, htm string, err error) {
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+		params = map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", "", errors.Wrap(err, "failed to read multipart message")
+			}
+
+			if strings.HasPrefix(part.Header.Get("Content-Disposition"), "attachment") {
+				continue
+			}
+
+			partPlain, partHTML, err := readBody(part.Header, part)
+			if err != nil {
+				return "", "", err
+			}
+			if plain == "" {
+				plain = partPlain
+			}
+			if htm == "" {
+				htm = partHTML
+			}
+		}
+		return plain, htm, nil
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", "", nil
+	}
+
+	decoded, err := decodeCharset(params["charset"], decodeTransfer(h, body))
+	if err != nil {
+		return "", "", err
+	}
+
+	content, err := io.ReadAll(decoded)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to read message body")
+	}
+
+	if mediaType == "text/html" {
+		return "", string(content), nil
+	}
+	return string(content), "", nil
+}
+
+// decodeTransfer undoes the Content-Transfer-Encoding of given part
+func decodeTransfer(h header, body io.Reader) io.Reader {
+	switch strings.ToLower(h.Get("Content-Transfer-Encoding")) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &newlineStripper{body})
+	}
+	return body
+}
+
+// decodeCharset converts given input into UTF-8, only UTF-8, US-ASCII and ISO-8859-1 are supported
+func decodeCharset(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "latin1", "windows-1252":
+		content, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		runes := make([]rune, len(content))
+		for i, b := range content {
+			runes[i] = rune(b)
+		}
+		return strings.NewReader(string(runes)), nil
+	}
+	return nil, errors.New("unsupported charset '%s'", charset)
+}
+
+// newlineStripper removes line breaks, which base64.NewDecoder doesn't accept
+type newlineStripper struct {
+	r io.Reader
+}
+
+func (s *newlineStripper) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	kept := 0
+	for _, b := range p[:n] {
+		if b != '\r' && b != '\n' {
+			p[kept] = b
+			kept++
+		}
+	}
+	return kept, err
+}
+
+var htmlBlockRegex = regexp.MustCompile(`(?i)<\s*(br|/p|/div|/li|/tr|/h[1-6])\s*/?>`)
+var htmlQuoteRegex = regexp.MustCompile(`(?is)<blockquote.*?</blockquote>`)
+var htmlIgnoredRegex = regexp.MustCompile(`(?is)<(head|style|script)[^>]*>.*?</(head|style|script)>`)
+var htmlTagRegex = regexp.MustCompile(`(?s)<[^>]*>`)
+
+// HTMLToText is a best-effort conversion of an HTML email to plain text, used when no text/plain part is available
+func HTMLToText(s string) string {
+	s = htmlIgnoredRegex.ReplaceAllString(s, "")
+	s = htmlQuoteRegex.ReplaceAllString(s, "")
+	s = htmlBlockRegex.ReplaceAllString(s, "\n")
+	s = htmlTagRegex.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
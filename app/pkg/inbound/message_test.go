@@ -0,0 +1,267 @@
+package inbound_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/inbound"
+)
+
+var multipartMessage = strings.ReplaceAll(`From: Arya Stark <arya.stark@got.com>
+To: Fider <reply+1.2.3.abc@inbound.got.com>
+Cc: jon.snow@got.com
+Subject: =?UTF-8?Q?Re:_Caf=C3=A9?=
+MIME-Version: 1.0
+Content-Type: multipart/alternative; boundary="XYZ"
+
+--XYZ
+Content-Type: text/plain; charset="UTF-8"
+Content-Transfer-Encoding: quoted-printable
+
+I like caf=C3=A9!
+
+On Mon, Jan 1, 2024 at 10:00 AM Jon Snow wrote:
+> Coffee?
+--XYZ
+Content-Type: text/html; charset="UTF-8"
+
+<p>I like café!</p>
+--XYZ--
+`, "\n", "\r\n")
+
+func TestParseMessage_Multipart(t *testing.T) {
+	RegisterT(t)
+
+	email, err := inbound.ParseMessage(strings.NewReader(multipartMessage))
+	Expect(err).IsNil()
+	Expect(email.From).Equals("arya.stark@got.com")
+	Expect(email.Recipients).Equals([]string{"reply+1.2.3.abc@inbound.got.com", "jon.snow@got.com"})
+	Expect(email.Subject).Equals("Re: Café")
+	Expect(inbound.StripReply(email.Text)).Equals("I like café!")
+}
+
+func TestParseMessage_AuthenticationResults(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.AuthServID = "mx.inbound.got.com"
+
+	for value, authenticated := range map[string]bool{
+		"mx.inbound.got.com; spf=pass smtp.mailfrom=arya.stark@got.com":                                     true,
+		"mx.inbound.got.com 1; spf=fail smtp.mailfrom=got.com; dkim=pass (good signature) header.d=got.com": true,
+		"MX.inbound.got.com; dmarc=pass header.from=got.com":                                                true,
+		"mx.inbound.got.com; dkim=pass header.d=mail.got.com":                                               false,
+		"mx.inbound.got.com; dkim=pass header.d=evil.com":                                                   false,
+		"mx.inbound.got.com; spf=fail smtp.mailfrom=got.com":                                                false,
+		"mx.evil.com; spf=pass smtp.mailfrom=got.com":                                                       false,
+	} {
+		email, err := inbound.ParseMessage(strings.NewReader("From: arya.stark@got.com\r\nAuthentication-Results: " + value + "\r\n\r\nI agree"))
+		Expect(err).IsNil()
+		Expect(email.Authenticated).Equals(authenticated)
+	}
+
+	env.Config.Email.Inbound.AuthServID = ""
+	email, err := inbound.ParseMessage(strings.NewReader("From: arya.stark@got.com\r\nAuthentication-Results: ; spf=pass smtp.mailfrom=got.com\r\n\r\nI agree"))
+	Expect(err).IsNil()
+	Expect(email.Authenticated).IsFalse()
+}
+
+func TestParseMessage_HTMLOnly(t *testing.T) {
+	RegisterT(t)
+
+	email, err := inbound.ParseMessage(strings.NewReader(`From: arya.stark@got.com
+To: reply+1.2.3.abc@inbound.got.com
+Subject: Re: Hello
+Content-Type: text/html; charset=iso-8859-1
+Content-Transfer-Encoding: base64
+
+` + base64.StdEncoding.EncodeToString([]byte("<html><head><style>p{}</style></head><body><p>Caf\xe9 &amp; tea</p><blockquote>Old</blockquote></body></html>")) + `
+`))
+	Expect(err).IsNil()
+	Expect(email.From).Equals("arya.stark@got.com")
+	Expect(email.Text).Equals("Café & tea")
+}
+
+func TestParseMailgun_Fields(t *testing.T) {
+	RegisterT(t)
+
+	body := "recipient=reply%2B1.2.3.abc%40inbound.got.com&from=Arya+Stark+%3Carya.stark%40got.com%3E&subject=Re%3A+Hello&body-plain=I+agree"
+	email, err := inbound.ParseMailgun("application/x-www-form-urlencoded", body)
+	Expect(err).IsNil()
+	Expect(email.From).Equals("arya.stark@got.com")
+	Expect(email.Recipients).Equals([]string{"reply+1.2.3.abc@inbound.got.com"})
+	Expect(email.Subject).Equals("Re: Hello")
+	Expect(email.Text).Equals("I agree")
+	Expect(email.Authenticated).IsFalse()
+
+	email, err = inbound.ParseMailgun("application/x-www-form-urlencoded", body+"&sender=bounces%40mail.got.com&X-Mailgun-Spf=Pass")
+	Expect(err).IsNil()
+	Expect(email.Authenticated).IsFalse()
+
+	email, err = inbound.ParseMailgun("application/x-www-form-urlencoded", body+"&sender=bounces%40got.com&X-Mailgun-Spf=Pass")
+	Expect(err).IsNil()
+	Expect(email.Authenticated).IsTrue()
+
+	headers := url.QueryEscape(`[["From", "arya.stark@got.com"], ["DKIM-Signature", "v=1; a=rsa-sha256; d=got.com; s=mail; b=abc"]]`)
+	email, err = inbound.ParseMailgun("application/x-www-form-urlencoded", body+"&X-Mailgun-Spf=Fail&X-Mailgun-Dkim-Check-Result=Pass&message-headers="+headers)
+	Expect(err).IsNil()
+	Expect(email.Authenticated).IsTrue()
+}
+
+func TestParseMailgun_FromAnotherDomain(t *testing.T) {
+	RegisterT(t)
+
+	// evil.com passes SPF and DKIM for itself, while claiming to be arya.stark@got.com
+	body := "recipient=reply%2B1.2.3.abc%40inbound.got.com&from=arya.stark%40got.com&sender=attacker%40evil.com&subject=Re%3A+Hello&body-plain=I+agree&X-Mailgun-Spf=Pass&X-Mailgun-Dkim-Check-Result=Pass"
+	email, err := inbound.ParseMailgun("application/x-www-form-urlencoded", body)
+	Expect(err).IsNil()
+	Expect(email.Authenticated).IsFalse()
+
+	headers := url.QueryEscape(`[["DKIM-Signature", "v=1; a=rsa-sha256; d=evil.com; s=mail; b=abc"]]`)
+	email, err = inbound.ParseMailgun("application/x-www-form-urlencoded", body+"&message-headers="+headers)
+	Expect(err).IsNil()
+	Expect(email.Authenticated).IsFalse()
+
+	// any signature could be the one that passed
+	headers = url.QueryEscape(`[["DKIM-Signature", "v=1; d=got.com; s=mail; b=forged"], ["DKIM-Signature", "v=1; d=evil.com; s=mail; b=abc"]]`)
+	email, err = inbound.ParseMailgun("application/x-www-form-urlencoded", body+"&message-headers="+headers)
+	Expect(err).IsNil()
+	Expect(email.Authenticated).IsFalse()
+}
+
+func TestParseMailgun_MultipartMIME(t *testing.T) {
+	RegisterT(t)
+
+	body := strings.ReplaceAll(`--BOUNDARY
+Content-Disposition: form-data; name="recipient"
+
+reply+1.2.3.abc@inbound.got.com
+--BOUNDARY
+Content-Disposition: form-data; name="body-mime"
+
+From: arya.stark@got.com
+To: someone@got.com
+Subject: Re: Hello
+
+I agree
+--BOUNDARY--
+`, "\n", "\r\n")
+
+	email, err := inbound.ParseMailgun("multipart/form-data; boundary=BOUNDARY", body)
+	Expect(err).IsNil()
+	Expect(email.From).Equals("arya.stark@got.com")
+	Expect(email.Recipients).Equals([]string{"reply+1.2.3.abc@inbound.got.com", "someone@got.com"})
+	Expect(strings.TrimSpace(email.Text)).Equals("I agree")
+}
+
+func snsBody(msgType string, message any, subscribeURL string) string {
+	content, _ := json.Marshal(message)
+	body, _ := json.Marshal(map[string]string{
+		"Type":         msgType,
+		"Message":      string(content),
+		"SubscribeURL": subscribeURL,
+	})
+	return string(body)
+}
+
+func TestParseSES_Notification(t *testing.T) {
+	RegisterT(t)
+
+	body := snsBody("Notification", map[string]any{
+		"notificationType": "Received",
+		"receipt": map[string]any{
+			"recipients":   []string{"reply+1.2.3.abc@inbound.got.com"},
+			"spfVerdict":   map[string]any{"status": "PASS"},
+			"dkimVerdict":  map[string]any{"status": "GRAY"},
+			"dmarcVerdict": map[string]any{"status": "PASS"},
+			"action":       map[string]any{"type": "SNS", "encoding": "BASE64"},
+		},
+		"content": base64.StdEncoding.EncodeToString([]byte(multipartMessage)),
+	}, "")
+
+	email, subscribeURL, err := inbound.ParseSES(body)
+	Expect(err).IsNil()
+	Expect(subscribeURL).Equals("")
+	Expect(email.From).Equals("arya.stark@got.com")
+	Expect(email.Recipients[0]).Equals("reply+1.2.3.abc@inbound.got.com")
+	Expect(inbound.StripReply(email.Text)).Equals("I like café!")
+	Expect(email.Authenticated).IsTrue()
+}
+
+func TestParseSES_FromAnotherDomain(t *testing.T) {
+	RegisterT(t)
+
+	// SPF and DKIM passed for the domain of the attacker, so DMARC fails for the From header
+	body := snsBody("Notification", map[string]any{
+		"notificationType": "Received",
+		"receipt": map[string]any{
+			"recipients":   []string{"reply+1.2.3.abc@inbound.got.com"},
+			"spfVerdict":   map[string]any{"status": "PASS"},
+			"dkimVerdict":  map[string]any{"status": "PASS"},
+			"dmarcVerdict": map[string]any{"status": "FAIL"},
+			"action":       map[string]any{"type": "SNS", "encoding": "BASE64"},
+		},
+		"content": base64.StdEncoding.EncodeToString([]byte(multipartMessage)),
+	}, "")
+
+	email, _, err := inbound.ParseSES(body)
+	Expect(err).IsNil()
+	Expect(email.From).Equals("arya.stark@got.com")
+	Expect(email.Authenticated).IsFalse()
+}
+
+func TestParseSES_Bounce(t *testing.T) {
+	RegisterT(t)
+
+	email, subscribeURL, err := inbound.ParseSES(snsBody("Notification", map[string]any{
+		"notificationType": "Bounce",
+		"bounce": map[string]any{
+			"bounceType":        "Permanent",
+			"bouncedRecipients": []map[string]string{{"emailAddress": "jon.snow@got.com"}, {"emailAddress": "Arya Stark <arya.stark@got.com>"}},
+		},
+	}, ""))
+	Expect(err).IsNil()
+	Expect(subscribeURL).Equals("")
+	Expect(email.Bounces).Equals([]string{"jon.snow@got.com", "arya.stark@got.com"})
+
+	email, subscribeURL, err = inbound.ParseSES(snsBody("Notification", map[string]any{
+		"eventType": "Bounce",
+		"bounce": map[string]any{
+			"bounceType":        "Transient",
+			"bouncedRecipients": []map[string]string{{"emailAddress": "jon.snow@got.com"}},
+		},
+	}, ""))
+	Expect(err).IsNil()
+	Expect(subscribeURL).Equals("")
+	Expect(email).IsNil()
+}
+
+func TestParseSES_Complaint(t *testing.T) {
+	RegisterT(t)
+
+	email, _, err := inbound.ParseSES(snsBody("Notification", map[string]any{
+		"eventType": "Complaint",
+		"complaint": map[string]any{
+			"complainedRecipients": []map[string]string{{"emailAddress": "jon.snow@got.com"}},
+		},
+	}, ""))
+	Expect(err).IsNil()
+	Expect(email.Bounces).Equals([]string{"jon.snow@got.com"})
+}
+
+func TestParseSES_SubscriptionConfirmation(t *testing.T) {
+	RegisterT(t)
+
+	email, subscribeURL, err := inbound.ParseSES(snsBody("SubscriptionConfirmation", "", "https://sns.us-east-1.amazonaws.com/?Action=ConfirmSubscription&Token=123"))
+	Expect(err).IsNil()
+	Expect(email).IsNil()
+	Expect(subscribeURL).Equals("https://sns.us-east-1.amazonaws.com/?Action=ConfirmSubscription&Token=123")
+
+	email, subscribeURL, err = inbound.ParseSES(snsBody("SubscriptionConfirmation", "", "https://evil.com/?Action=ConfirmSubscription"))
+	Expect(err).IsNotNil()
+	Expect(email).IsNil()
+	Expect(subscribeURL).Equals("")
+}
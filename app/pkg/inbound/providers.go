@@ -0,0 +1,240 @@
+package inbound
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// ParseMailgun reads an email forwarded by a Mailgun route, either as parsed fields or as a raw MIME message
+func ParseMailgun(contentType, body string) (*dto.InboundEmail, error) {
+	values, err := parseForm(contentType, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var email *dto.InboundEmail
+	if raw := values.Get("body-mime"); raw != "" {
+		email, err = ParseMessage(strings.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		email = &dto.InboundEmail{
+			Recipients: make([]string, 0),
+			Subject:    values.Get("subject"),
+			Text:       values.Get("body-plain"),
+		}
+		if from, err := mail.ParseAddress(values.Get("from")); err == nil {
+			email.From = from.Address
+		} else {
+			email.From = values.Get("sender")
+		}
+	}
+
+	// Mailgun checks the sender when receiving the email and adds the verdicts to the request
+	// SPF covers the envelope sender and DKIM the signing domain, so they only count when these match the From header
+	_, fromDomain, _ := splitAddress(strings.ToLower(email.From))
+	if fromDomain != "" && strings.EqualFold(values.Get("X-Mailgun-Spf"), "Pass") {
+		if _, senderDomain, ok := splitAddress(strings.ToLower(values.Get("sender"))); ok && isAlignedDomain(senderDomain, fromDomain) {
+			email.Authenticated = true
+		}
+	}
+	if fromDomain != "" && strings.EqualFold(values.Get("X-Mailgun-Dkim-Check-Result"), "Pass") {
+		if isSignedBy(mailgunHeaders(values)["Dkim-Signature"], fromDomain) {
+			email.Authenticated = true
+		}
+	}
+
+	// 'recipient' is the address that matched the route, which might not be on the To header
+	for _, recipient := range strings.Split(values.Get("recipient"), ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			email.Recipients = append([]string{recipient}, email.Recipients...)
+		}
+	}
+
+	return email, nil
+}
+
+// mailgunHeaders returns the headers of the original message, which are sent as a JSON list of name and value pairs
+func mailgunHeaders(values url.Values) mail.Header {
+	if raw := values.Get("body-mime"); raw != "" {
+		if msg, err := mail.ReadMessage(strings.NewReader(raw)); err == nil {
+			return msg.Header
+		}
+		return mail.Header{}
+	}
+
+	pairs := make([][]string, 0)
+	h := mail.Header{}
+	if err := json.Unmarshal([]byte(values.Get("message-headers")), &pairs); err != nil {
+		return h
+	}
+	for _, pair := range pairs {
+		if len(pair) == 2 {
+			key := textproto.CanonicalMIMEHeaderKey(pair[0])
+			h[key] = append(h[key], pair[1])
+		}
+	}
+	return h
+}
+
+// isSignedBy returns true if every given DKIM signature is from the domain of the sender
+// The verdict doesn't tell which signature passed, so one from any other domain could be the one that did
+func isSignedBy(signatures []string, fromDomain string) bool {
+	if len(signatures) == 0 {
+		return false
+	}
+
+	for _, signature := range signatures {
+		signed := false
+		for _, tag := range strings.Split(signature, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(tag), "=")
+			if strings.TrimSpace(key) == "d" && isAlignedDomain(strings.ToLower(strings.TrimSpace(value)), fromDomain) {
+				signed = true
+			}
+		}
+		if !signed {
+			return false
+		}
+	}
+	return true
+}
+
+func parseForm(contentType, body string) (url.Values, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err == nil && mediaType == "multipart/form-data" {
+		form, err := multipart.NewReader(strings.NewReader(body), params["boundary"]).ReadForm(MaxMessageSize)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read multipart form")
+		}
+		defer func() {
+			_ = form.RemoveAll()
+		}()
+		return url.Values(form.Value), nil
+	}
+
+	values, err := url.ParseQuery(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse form")
+	}
+	return values, nil
+}
+
+var snsHostRegex = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+type snsMessage struct {
+	Type         string `json:"Type"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+type sesVerdict struct {
+	Status string `json:"status"`
+}
+
+type sesRecipient struct {
+	EmailAddress string `json:"emailAddress"`
+}
+
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"` // used instead of notificationType when published by a configuration set
+	Bounce           struct {
+		BounceType        string          `json:"bounceType"`
+		BouncedRecipients []*sesRecipient `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplainedRecipients []*sesRecipient `json:"complainedRecipients"`
+	} `json:"complaint"`
+	Receipt struct {
+		Recipients   []string   `json:"recipients"`
+		DMARCVerdict sesVerdict `json:"dmarcVerdict"`
+		Action       struct {
+			Encoding string `json:"encoding"`
+		} `json:"action"`
+	} `json:"receipt"`
+	Content string `json:"content"`
+}
+
+// ParseSES reads an email received by Amazon SES and published to an SNS topic
+// Bounce and complaint notifications are returned as an email with the Bounces of the notification,
+// but email is nil for transient bounces, which require no action
+// When SNS is confirming the subscription, email is nil and the returned URL must be visited
+func ParseSES(body string) (email *dto.InboundEmail, subscribeURL string, err error) {
+	msg := &snsMessage{}
+	if err := json.Unmarshal([]byte(body), msg); err != nil {
+		return nil, "", errors.Wrap(err, "failed to parse SNS message")
+	}
+
+	switch msg.Type {
+	case "SubscriptionConfirmation":
+		u, err := url.Parse(msg.SubscribeURL)
+		if err != nil || u.Scheme != "https" || !snsHostRegex.MatchString(u.Hostname()) {
+			return nil, "", errors.New("invalid SNS subscription URL '%s'", msg.SubscribeURL)
+		}
+		return nil, msg.SubscribeURL, nil
+	case "Notification":
+		notification := &sesNotification{}
+		if err := json.Unmarshal([]byte(msg.Message), notification); err != nil {
+			return nil, "", errors.Wrap(err, "failed to parse SES notification")
+		}
+		notificationType := notification.NotificationType
+		if notificationType == "" {
+			notificationType = notification.EventType
+		}
+
+		switch notificationType {
+		case "Bounce":
+			if notification.Bounce.BounceType != "Permanent" {
+				return nil, "", nil
+			}
+			return &dto.InboundEmail{Bounces: sesAddresses(notification.Bounce.BouncedRecipients)}, "", nil
+		case "Complaint":
+			return &dto.InboundEmail{Bounces: sesAddresses(notification.Complaint.ComplainedRecipients)}, "", nil
+		}
+
+		if notificationType != "Received" || notification.Content == "" {
+			return nil, "", errors.New("SES notification '%s' has no content", notificationType)
+		}
+
+		content := notification.Content
+		if strings.EqualFold(notification.Receipt.Action.Encoding, "BASE64") {
+			decoded, err := base64.StdEncoding.DecodeString(content)
+			if err != nil {
+				return nil, "", errors.Wrap(err, "failed to decode SES content")
+			}
+			content = string(decoded)
+		}
+
+		email, err := ParseMessage(strings.NewReader(content))
+		if err != nil {
+			return nil, "", err
+		}
+		email.Recipients = append(notification.Receipt.Recipients, email.Recipients...)
+		// unlike the SPF and DKIM verdicts, DMARC requires the domain that passed to be the one of the From header
+		email.Authenticated = strings.EqualFold(notification.Receipt.DMARCVerdict.Status, "PASS")
+		return email, "", nil
+	}
+
+	return nil, "", errors.New("unsupported SNS message type '%s'", msg.Type)
+}
+
+func sesAddresses(recipients []*sesRecipient) []string {
+	addresses := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		if address := reportedAddress(recipient.EmailAddress); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,71 @@
+package inbound
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Lines that start the quoted message appended by email clients
+var quoteHeaderRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^(on|le|am|el|em|op|il|den)\s.+(wrote|a écrit|schrieb|escribió|escreveu|schreef|ha scritto|skrev)\s?:$`),
+	regexp.MustCompile(`(?i)^-+\s*original message\s*-+$`),
+	regexp.MustCompile(`(?i)^-+\s*forwarded message\s*-+$`),
+	regexp.MustCompile(`^_{10,}$`),
+}
+
+// Outlook quotes the headers of previous message, e.g: "From: Jon Snow\nSent: Monday, ..."
+var outlookHeaderRegex = regexp.MustCompile(`(?i)^from:\s.+\n(sent|date):\s`)
+
+// Lines that start a signature
+var signatureRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`^--\s?$`),
+	regexp.MustCompile(`(?i)^sent from my\s`),
+	regexp.MustCompile(`(?i)^sent from (mail|outlook|yahoo mail) for\s`),
+	regexp.MustCompile(`(?i)^get outlook for\s`),
+	regexp.MustCompile(`(?i)^(envoyé de mon|enviado desde mi|enviado do meu|von meinem .+ gesendet)`),
+}
+
+func matchesAny(line string, regexes []*regexp.Regexp) bool {
+	for _, r := range regexes {
+		if r.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// StripReply returns only what has been written by the sender of a reply
+// Quoted text of previous messages and signatures are removed
+func StripReply(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+
+	kept := make([]string, 0, len(lines))
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		// Some clients break the quote header in two lines, e.g: "On Mon, 1 Jan 2024, Jon Snow\n<jon@got.com> wrote:"
+		if i+1 < len(lines) && !strings.HasSuffix(trimmed, ":") {
+			joined := trimmed + " " + strings.TrimSpace(lines[i+1])
+			if matchesAny(joined, quoteHeaderRegexes[:1]) {
+				break
+			}
+		}
+
+		if i+1 < len(lines) && outlookHeaderRegex.MatchString(trimmed+"\n"+strings.TrimSpace(lines[i+1])) {
+			break
+		}
+
+		if matchesAny(trimmed, quoteHeaderRegexes) || matchesAny(line, signatureRegexes) {
+			break
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+
+		kept = append(kept, line)
+	}
+
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
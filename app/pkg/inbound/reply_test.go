@@ -0,0 +1,33 @@
+package inbound_test
+
+import (
+	"testing"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/inbound"
+)
+
+func TestStripReply(t *testing.T) {
+	RegisterT(t)
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"I agree!", "I agree!"},
+		{"  I agree!\r\n\r\nWith this.\r\n", "I agree!\n\nWith this."},
+		{"I agree!\n\nOn Mon, Jan 1, 2024 at 10:00 AM Jon Snow <jon.snow@got.com> wrote:\n> Add support for TypeScript", "I agree!"},
+		{"I agree!\n\nOn Mon, Jan 1, 2024 at 10:00 AM Jon Snow\n<jon.snow@got.com> wrote:\n> Add support for TypeScript", "I agree!"},
+		{"Je suis d'accord\n\nLe lun. 1 janv. 2024 à 10:00, Jon Snow a écrit :\n> Bonjour", "Je suis d'accord"},
+		{"Agreed\n\n-----Original Message-----\nFrom: Jon Snow", "Agreed"},
+		{"Agreed\n\nFrom: Jon Snow <jon.snow@got.com>\nSent: Monday, January 1, 2024 10:00 AM\nTo: Arya", "Agreed"},
+		{"Agreed\n\n-- \nArya Stark\nWinterfell", "Agreed"},
+		{"Agreed\n\nSent from my iPhone", "Agreed"},
+		{"First\n> quoted\nSecond", "First\nSecond"},
+		{"> only quoted text", ""},
+	}
+
+	for _, testCase := range testCases {
+		Expect(inbound.StripReply(testCase.input)).Equals(testCase.expected)
+	}
+}
@@ -0,0 +1,225 @@
+package inbound
+
+import (
+	"bytes"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// SMTPTimeout is how long an SMTP client can stay idle before being disconnected
+var SMTPTimeout = 2 * time.Minute
+
+// MaxSMTPSessions is how many SMTP clients can be connected at the same time, each of them might buffer a whole message
+var MaxSMTPSessions = 20
+
+const maxRecipients = 50
+
+// ServeSMTP accepts replies and bounce messages on given listener until it's closed
+// Only the minimum of SMTP needed to receive messages from a mail server is implemented
+// and recipients that are neither reply nor bounce addresses are rejected
+func ServeSMTP(listener net.Listener, hostname string, handle func(email *dto.InboundEmail)) error {
+	sessions := make(chan struct{}, MaxSMTPSessions)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if stdErrors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return errors.Wrap(err, "failed to accept SMTP connection")
+		}
+
+		session := &smtpSession{
+			conn:     conn,
+			text:     textproto.NewConn(conn),
+			hostname: hostname,
+			handle:   handle,
+		}
+
+		select {
+		case sessions <- struct{}{}:
+			go func() {
+				defer func() { <-sessions }()
+				session.serve()
+			}()
+		default:
+			// mail servers retry later when the service is temporarily unavailable
+			session.reply(421, hostname+" Too many connections, try again later")
+			session.text.Close()
+		}
+	}
+}
+
+type smtpSession struct {
+	conn       net.Conn
+	text       *textproto.Conn
+	hostname   string
+	handle     func(email *dto.InboundEmail)
+	from       string
+	recipients []string
+}
+
+func (s *smtpSession) reply(code int, message string) bool {
+	_ = s.conn.SetWriteDeadline(time.Now().Add(SMTPTimeout))
+	return s.text.PrintfLine("%d %s", code, message) == nil
+}
+
+func (s *smtpSession) reset() {
+	s.from = ""
+	s.recipients = nil
+}
+
+func (s *smtpSession) serve() {
+	defer s.text.Close()
+
+	if !s.reply(220, s.hostname+" ESMTP ready") {
+		return
+	}
+
+	for {
+		_ = s.conn.SetReadDeadline(time.Now().Add(SMTPTimeout))
+		line, err := s.text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+
+		ok := true
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			ok = s.reply(250, s.hostname)
+		case "EHLO":
+			ok = s.replyLines(250, s.hostname, fmt.Sprintf("SIZE %d", MaxMessageSize), "8BITMIME")
+		case "MAIL":
+			ok = s.mail(arg)
+		case "RCPT":
+			ok = s.rcpt(arg)
+		case "DATA":
+			ok = s.data()
+		case "RSET":
+			s.reset()
+			ok = s.reply(250, "OK")
+		case "NOOP":
+			ok = s.reply(250, "OK")
+		case "QUIT":
+			s.reply(221, "Bye")
+			return
+		default:
+			ok = s.reply(502, "Command not implemented")
+		}
+
+		if !ok {
+			return
+		}
+	}
+}
+
+func (s *smtpSession) replyLines(code int, lines ...string) bool {
+	for i, line := range lines {
+		separator := "-"
+		if i == len(lines)-1 {
+			separator = " "
+		}
+		_ = s.conn.SetWriteDeadline(time.Now().Add(SMTPTimeout))
+		if err := s.text.PrintfLine("%d%s%s", code, separator, line); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePath extracts the address of "FROM:<jon@got.com> SIZE=100" and "TO:<jon@got.com>"
+func parsePath(arg, prefix string) (string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", false
+	}
+	path := strings.TrimSpace(arg[len(prefix):])
+	start, end := strings.Index(path, "<"), strings.Index(path, ">")
+	if start != 0 || end < start {
+		return "", false
+	}
+	return path[1:end], true
+}
+
+func (s *smtpSession) mail(arg string) bool {
+	if s.from != "" {
+		return s.reply(503, "Sender already specified")
+	}
+	from, ok := parsePath(arg, "FROM:")
+	if !ok {
+		return s.reply(501, "Syntax error in MAIL command")
+	}
+	if from == "" {
+		// Bounces have an empty reverse-path
+		from = "<>"
+	}
+	s.from = from
+	return s.reply(250, "OK")
+}
+
+func (s *smtpSession) rcpt(arg string) bool {
+	if s.from == "" {
+		return s.reply(503, "Need MAIL command first")
+	}
+	to, ok := parsePath(arg, "TO:")
+	if !ok {
+		return s.reply(501, "Syntax error in RCPT command")
+	}
+	if len(s.recipients) >= maxRecipients {
+		return s.reply(452, "Too many recipients")
+	}
+	if _, err := ParseReplyAddress(to); err != nil && !IsBounceAddress(to) {
+		return s.reply(550, "No such user")
+	}
+	s.recipients = append(s.recipients, to)
+	return s.reply(250, "OK")
+}
+
+func (s *smtpSession) data() bool {
+	if len(s.recipients) == 0 {
+		return s.reply(503, "Need RCPT command first")
+	}
+	if !s.reply(354, "End data with <CR><LF>.<CR><LF>") {
+		return false
+	}
+
+	_ = s.conn.SetReadDeadline(time.Now().Add(SMTPTimeout))
+	reader := s.text.DotReader()
+	content, err := io.ReadAll(io.LimitReader(reader, MaxMessageSize+1))
+	if err != nil {
+		return false
+	}
+	if len(content) > MaxMessageSize {
+		_, _ = io.Copy(io.Discard, reader)
+		s.reset()
+		return s.reply(552, "Message size exceeds maximum permitted")
+	}
+
+	email, err := ParseMessage(bytes.NewReader(content))
+	if err != nil {
+		s.reset()
+		return s.reply(554, "Message could not be parsed")
+	}
+
+	// The envelope is more reliable than headers, which can be missing or rewritten by forwarders
+	email.Recipients = append(s.recipients, email.Recipients...)
+	if email.From == "" {
+		if address, err := mail.ParseAddress(s.from); err == nil {
+			email.From = address.Address
+		}
+	}
+
+	s.handle(email)
+	s.reset()
+	return s.reply(250, "OK")
+}
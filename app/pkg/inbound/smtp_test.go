@@ -0,0 +1,90 @@
+package inbound_test
+
+import (
+	"net"
+	"net/textproto"
+	"testing"
+
+	"github.com/getfider/fider/app/models/dto"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/inbound"
+)
+
+func TestServeSMTP(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Address = "reply@inbound.got.com"
+	replyAddress := inbound.ReplyAddress(inbound.ReplyToken{TenantID: 1, UserID: 2, PostNumber: 3})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).IsNil()
+	defer listener.Close()
+
+	received := make(chan *dto.InboundEmail, 1)
+	go func() {
+		_ = inbound.ServeSMTP(listener, "inbound.got.com", func(email *dto.InboundEmail) {
+			received <- email
+		})
+	}()
+
+	conn, err := textproto.Dial("tcp", listener.Addr().String())
+	Expect(err).IsNil()
+	defer conn.Close()
+
+	expect := func(code int) {
+		_, _, err := conn.ReadResponse(code)
+		Expect(err).IsNil()
+	}
+	send := func(line string, code int) {
+		Expect(conn.PrintfLine("%s", line)).IsNil()
+		expect(code)
+	}
+
+	expect(220)
+	send("EHLO mx.got.com", 250)
+	send("MAIL FROM:<arya.stark@got.com>", 250)
+	send("RCPT TO:<jon.snow@got.com>", 550)
+	send("RCPT TO:<"+replyAddress+">", 250)
+	send("DATA", 354)
+
+	w := conn.DotWriter()
+	_, err = w.Write([]byte("From: arya.stark@got.com\r\nSubject: Re: Hello\r\n\r\nI agree\r\n"))
+	Expect(err).IsNil()
+	Expect(w.Close()).IsNil()
+	expect(250)
+	send("QUIT", 221)
+
+	email := <-received
+	Expect(email.From).Equals("arya.stark@got.com")
+	Expect(email.Recipients).Equals([]string{replyAddress})
+	Expect(email.Subject).Equals("Re: Hello")
+	Expect(email.Text).Equals("I agree\n")
+}
+
+func TestServeSMTP_TooManySessions(t *testing.T) {
+	RegisterT(t)
+
+	maxSessions := inbound.MaxSMTPSessions
+	defer func() { inbound.MaxSMTPSessions = maxSessions }()
+	inbound.MaxSMTPSessions = 1
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).IsNil()
+	defer listener.Close()
+
+	go func() {
+		_ = inbound.ServeSMTP(listener, "inbound.got.com", func(email *dto.InboundEmail) {})
+	}()
+
+	first, err := textproto.Dial("tcp", listener.Addr().String())
+	Expect(err).IsNil()
+	defer first.Close()
+	_, _, err = first.ReadResponse(220)
+	Expect(err).IsNil()
+
+	second, err := textproto.Dial("tcp", listener.Addr().String())
+	Expect(err).IsNil()
+	defer second.Close()
+	_, _, err = second.ReadResponse(421)
+	Expect(err).IsNil()
+}
@@ -63,6 +63,20 @@ func (q *Query) Int32(selector string) int {
 	return 0
 }
 
+//Bool returns a boolean value from the json object based on its selector
+func (q *Query) Bool(selector string) bool {
+	data := q.get(selector)
+	if data != nil {
+		var b bool
+		err := json.Unmarshal(*data, &b)
+		if err != nil {
+			panic(err)
+		}
+		return b
+	}
+	return false
+}
+
 //IsArray returns true if the json object is an array
 func (q *Query) IsArray() bool {
 	return q.m == nil
@@ -15,6 +15,15 @@ func TestGet(t *testing.T) {
 	Expect(query.Int32("age")).Equals(23)
 }
 
+func TestGetBool(t *testing.T) {
+	RegisterT(t)
+
+	query := jsonq.New(`{ "isAdmin": true, "isBlocked": false }`)
+	Expect(query.Bool("isAdmin")).IsTrue()
+	Expect(query.Bool("isBlocked")).IsFalse()
+	Expect(query.Bool("isMissing")).IsFalse()
+}
+
 func TestGetNull(t *testing.T) {
 	RegisterT(t)
 
@@ -27,10 +27,11 @@ const (
 
 // FiderClaims represents what goes into JWT tokens
 type FiderClaims struct {
-	UserID    int    `json:"user/id"`
-	UserName  string `json:"user/name"`
-	UserEmail string `json:"user/email"`
-	Origin    string `json:"origin"`
+	UserID         int    `json:"user/id"`
+	UserName       string `json:"user/name"`
+	UserEmail      string `json:"user/email"`
+	Origin         string `json:"origin"`
+	ImpersonatorID int    `json:"impersonator/id,omitempty"`
 	Metadata
 }
 
@@ -50,6 +51,34 @@ type OAuthStateClaims struct {
 	Metadata
 }
 
+// WebAuthnClaims represents what goes into temporary JWT tokens used during passkey ceremonies
+type WebAuthnClaims struct {
+	Challenge  string `json:"webauthn/challenge"`
+	Identifier string `json:"webauthn/identifier"`
+	UserID     int    `json:"webauthn/userid,omitempty"`
+	Metadata
+}
+
+// UnsubscribeClaims represents what goes into JWT tokens of unsubscribe links sent by email
+// Either PostNumber or Event is set, depending on what the user is unsubscribing from
+type UnsubscribeClaims struct {
+	TenantID   int    `json:"unsubscribe/tenantid"`
+	UserID     int    `json:"unsubscribe/userid"`
+	PostNumber int    `json:"unsubscribe/post,omitempty"`
+	Event      string `json:"unsubscribe/event,omitempty"`
+	Metadata
+}
+
+// SSOClaims represents what goes into JWT tokens signed by a tenant's own application to sign users in
+type SSOClaims struct {
+	Email          string `json:"email"`
+	Name           string `json:"name"`
+	ExternalID     string `json:"external_id"`
+	Organization   string `json:"organization"`
+	OrganizationID string `json:"organization_id"`
+	Metadata
+}
+
 // Encode creates new JWT token with given claims
 func Encode(claims jwtgo.Claims) (string, error) {
 	jwtToken := jwtgo.NewWithClaims(jwtgo.GetSigningMethod("HS256"), claims)
@@ -90,13 +119,63 @@ func DecodeOAuthStateClaims(token string) (*OAuthStateClaims, error) {
 	return claims, nil
 }
 
+// DecodeWebAuthnClaims extract WebAuthnClaims from given JWT token
+func DecodeWebAuthnClaims(token string) (*WebAuthnClaims, error) {
+	claims := &WebAuthnClaims{}
+	err := decode(token, claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode WebAuthn claims")
+	}
+	return claims, nil
+}
+
+// DecodeUnsubscribeClaims extract UnsubscribeClaims from given JWT token
+func DecodeUnsubscribeClaims(token string) (*UnsubscribeClaims, error) {
+	claims := &UnsubscribeClaims{}
+	err := decode(token, claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode Unsubscribe claims")
+	}
+	return claims, nil
+}
+
+// SSOMaxLifetime is the longest time between the issue and the expiration of an SSO token
+const SSOMaxLifetime = 5 * time.Minute
+
+// DecodeSSOClaims extract SSOClaims from given JWT token, which must be signed with given secret
+// and have an ID and a lifetime of at most SSOMaxLifetime
+func DecodeSSOClaims(token, secret string) (*SSOClaims, error) {
+	claims := &SSOClaims{}
+	err := decodeWithSecret(token, secret, claims)
+	if err == nil {
+		switch {
+		case claims.ExpiresAt == nil:
+			err = errors.New("missing expiration time")
+		case claims.IssuedAt == nil:
+			err = errors.New("missing issue time")
+		case claims.ExpiresAt.Sub(claims.IssuedAt.Time) > SSOMaxLifetime:
+			err = errors.New("lifetime is longer than %s", SSOMaxLifetime)
+		case claims.ID == "":
+			err = errors.New("missing token ID")
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode SSO claims")
+	}
+	return claims, nil
+}
+
 func decode(token string, claims jwtgo.Claims) error {
+	return decodeWithSecret(token, jwtSecret, claims)
+}
+
+func decodeWithSecret(token, secret string, claims jwtgo.Claims) error {
 	jwtToken, err := jwtgo.ParseWithClaims(token, claims, func(t *jwtgo.Token) (any, error) {
 		if _, ok := t.Method.(*jwtgo.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("Unexpected signing method: %v", t.Header["alg"])
 		}
 
-		return []byte(jwtSecret), nil
+		return []byte(secret), nil
 	})
 
 	if err == nil {
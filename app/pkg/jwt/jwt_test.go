@@ -135,3 +135,122 @@ func TestJWT_DecodeOAuthClaimsExpired(t *testing.T) {
 	Expect(err).IsNotNil()
 	Expect(decoded).IsNil()
 }
+
+func TestJWT_EncodeAndDecodeWebAuthnClaims(t *testing.T) {
+	RegisterT(t)
+
+	claims := &jwt.WebAuthnClaims{
+		Challenge:  "some-challenge",
+		Identifier: "session-id",
+		UserID:     2,
+		Metadata: jwt.Metadata{
+			ExpiresAt: jwt.Time(time.Now().Add(5 * time.Minute)),
+		},
+	}
+
+	token, err := jwt.Encode(claims)
+	Expect(err).IsNil()
+
+	decoded, err := jwt.DecodeWebAuthnClaims(token)
+	Expect(err).IsNil()
+	Expect(decoded.Challenge).Equals(claims.Challenge)
+	Expect(decoded.Identifier).Equals(claims.Identifier)
+	Expect(decoded.UserID).Equals(claims.UserID)
+}
+
+func TestJWT_EncodeAndDecodeUnsubscribeClaims(t *testing.T) {
+	RegisterT(t)
+
+	claims := &jwt.UnsubscribeClaims{
+		TenantID:   1,
+		UserID:     2,
+		PostNumber: 3,
+		Metadata: jwt.Metadata{
+			ExpiresAt: jwt.Time(time.Now().Add(5 * time.Minute)),
+		},
+	}
+
+	token, err := jwt.Encode(claims)
+	Expect(err).IsNil()
+
+	decoded, err := jwt.DecodeUnsubscribeClaims(token)
+	Expect(err).IsNil()
+	Expect(decoded.TenantID).Equals(1)
+	Expect(decoded.UserID).Equals(2)
+	Expect(decoded.PostNumber).Equals(3)
+	Expect(decoded.Event).Equals("")
+
+	_, err = jwt.DecodeUnsubscribeClaims(token + "x")
+	Expect(err).IsNotNil()
+}
+
+func TestJWT_DecodeSSOClaims(t *testing.T) {
+	RegisterT(t)
+
+	claims := &jwt.SSOClaims{
+		Email:        "jon.snow@got.com",
+		Name:         "Jon Snow",
+		ExternalID:   "jon-123",
+		Organization: "Night's Watch",
+		Metadata: jwt.Metadata{
+			ID:        "token-1",
+			IssuedAt:  jwt.Time(time.Now()),
+			ExpiresAt: jwt.Time(time.Now().Add(5 * time.Minute)),
+		},
+	}
+
+	token, _ := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, claims).SignedString([]byte("tenant-secret"))
+
+	decoded, err := jwt.DecodeSSOClaims(token, "tenant-secret")
+	Expect(err).IsNil()
+	Expect(decoded.Email).Equals("jon.snow@got.com")
+	Expect(decoded.Name).Equals("Jon Snow")
+	Expect(decoded.ExternalID).Equals("jon-123")
+	Expect(decoded.Organization).Equals("Night's Watch")
+
+	decoded, err = jwt.DecodeSSOClaims(token, "other-secret")
+	Expect(err).IsNotNil()
+	Expect(decoded).IsNil()
+
+	fiderToken, _ := jwt.Encode(claims)
+	decoded, err = jwt.DecodeSSOClaims(fiderToken, "tenant-secret")
+	Expect(err).IsNotNil()
+	Expect(decoded).IsNil()
+}
+
+func TestJWT_DecodeSSOClaims_RequiresExpiration(t *testing.T) {
+	RegisterT(t)
+
+	claims := &jwt.SSOClaims{Email: "jon.snow@got.com", Name: "Jon Snow", Metadata: jwt.Metadata{ID: "token-1", IssuedAt: jwt.Time(time.Now())}}
+	token, _ := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, claims).SignedString([]byte("tenant-secret"))
+
+	decoded, err := jwt.DecodeSSOClaims(token, "tenant-secret")
+	Expect(err).IsNotNil()
+	Expect(decoded).IsNil()
+
+	claims.ExpiresAt = jwt.Time(time.Now().Add(-1 * time.Minute))
+	token, _ = jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, claims).SignedString([]byte("tenant-secret"))
+
+	decoded, err = jwt.DecodeSSOClaims(token, "tenant-secret")
+	Expect(err).IsNotNil()
+	Expect(decoded).IsNil()
+}
+
+func TestJWT_DecodeSSOClaims_RequiresShortLifetimeAndID(t *testing.T) {
+	RegisterT(t)
+
+	now := time.Now()
+	for _, metadata := range []jwt.Metadata{
+		{ID: "token-1", ExpiresAt: jwt.Time(now.Add(time.Minute))},
+		{ID: "token-1", IssuedAt: jwt.Time(now), ExpiresAt: jwt.Time(now.Add(time.Hour))},
+		{IssuedAt: jwt.Time(now), ExpiresAt: jwt.Time(now.Add(time.Minute))},
+		{ID: "token-1", IssuedAt: jwt.Time(now.Add(time.Hour)), ExpiresAt: jwt.Time(now.Add(time.Hour + time.Minute))},
+	} {
+		claims := &jwt.SSOClaims{Email: "jon.snow@got.com", Name: "Jon Snow", Metadata: metadata}
+		token, _ := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, claims).SignedString([]byte("tenant-secret"))
+
+		decoded, err := jwt.DecodeSSOClaims(token, "tenant-secret")
+		Expect(err).IsNotNil()
+		Expect(decoded).IsNil()
+	}
+}
@@ -0,0 +1,194 @@
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// BER identifier octets used by LDAP (RFC 4511)
+const (
+	tagBoolean     = 0x01
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagEnumerated  = 0x0a
+	tagSequence    = 0x30
+	tagSet         = 0x31
+
+	classApplication = 0x40
+	classContext     = 0x80
+	constructed      = 0x20
+)
+
+// maxPacketSize protects the client from servers sending huge or malicious responses
+const maxPacketSize = 16 * 1024 * 1024
+
+// packet is a BER encoded element, either primitive (value) or constructed (children)
+type packet struct {
+	tag      byte
+	value    []byte
+	children []*packet
+}
+
+func (p *packet) isConstructed() bool {
+	return p.tag&constructed != 0
+}
+
+func newConstructed(tag byte, children ...*packet) *packet {
+	return &packet{tag: tag | constructed, children: children}
+}
+
+func newString(tag byte, value string) *packet {
+	return &packet{tag: tag, value: []byte(value)}
+}
+
+func newInteger(tag byte, value int64) *packet {
+	// minimal two's complement encoding
+	b := []byte{byte(value)}
+	for v := value >> 8; ; v >>= 8 {
+		last := b[0]
+		if (v == 0 && last&0x80 == 0) || (v == -1 && last&0x80 != 0) {
+			break
+		}
+		b = append([]byte{byte(v)}, b...)
+	}
+	return &packet{tag: tag, value: b}
+}
+
+func newBoolean(value bool) *packet {
+	if value {
+		return &packet{tag: tagBoolean, value: []byte{0xff}}
+	}
+	return &packet{tag: tagBoolean, value: []byte{0x00}}
+}
+
+func (p *packet) int() int64 {
+	if len(p.value) == 0 {
+		return 0
+	}
+	v := int64(int8(p.value[0]))
+	for _, b := range p.value[1:] {
+		v = v<<8 | int64(b)
+	}
+	return v
+}
+
+func (p *packet) string() string {
+	return string(p.value)
+}
+
+func (p *packet) child(i int) *packet {
+	if i < len(p.children) {
+		return p.children[i]
+	}
+	return &packet{}
+}
+
+func (p *packet) bytes() []byte {
+	content := p.value
+	if p.isConstructed() {
+		content = nil
+		for _, child := range p.children {
+			content = append(content, child.bytes()...)
+		}
+	}
+
+	out := []byte{p.tag}
+	out = append(out, encodeLength(len(content))...)
+	return append(out, content...)
+}
+
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var b []byte
+	for ; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+// readPacket reads a single BER element from given reader
+func readPacket(r *bufio.Reader) (*packet, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if tag&0x1f == 0x1f {
+		return nil, errors.New("ldap: high tag numbers are not supported")
+	}
+
+	length, err := readLength(r)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	return parsePacket(tag, content)
+}
+
+func readLength(r *bufio.Reader) (int, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if first&0x80 == 0 {
+		return int(first), nil
+	}
+
+	size := int(first & 0x7f)
+	if size == 0 || size > 4 {
+		return 0, errors.New("ldap: unsupported length encoding")
+	}
+
+	length := 0
+	for i := 0; i < size; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length = length<<8 | int(b)
+	}
+	if length > maxPacketSize {
+		return 0, errors.New("ldap: packet of %d bytes exceeds maximum size", length)
+	}
+	return length, nil
+}
+
+func parsePacket(tag byte, content []byte) (*packet, error) {
+	p := &packet{tag: tag}
+	if !p.isConstructed() {
+		p.value = content
+		return p, nil
+	}
+
+	r := bufio.NewReader(bytes.NewReader(content))
+	for {
+		child, err := readPacket(r)
+		if err == io.EOF {
+			return p, nil
+		}
+		if err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return nil, errors.New("ldap: truncated packet")
+			}
+			return nil, err
+		}
+		p.children = append(p.children, child)
+	}
+}
+
+// unexpectedEOF is used once an element has started, as EOF is only expected between elements
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
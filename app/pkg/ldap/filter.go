@@ -0,0 +1,188 @@
+package ldap
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// Filter choices (RFC 4511 section 4.5.1)
+const (
+	filterAnd            = classContext | constructed | 0
+	filterOr             = classContext | constructed | 1
+	filterNot            = classContext | constructed | 2
+	filterEqualityMatch  = classContext | constructed | 3
+	filterSubstrings     = classContext | constructed | 4
+	filterGreaterOrEqual = classContext | constructed | 5
+	filterLessOrEqual    = classContext | constructed | 6
+	filterPresent        = classContext | 7
+	filterApproxMatch    = classContext | constructed | 8
+
+	substringInitial = classContext | 0
+	substringAny     = classContext | 1
+	substringFinal   = classContext | 2
+)
+
+// EscapeFilter escapes special characters of given value so it can be safely used inside a search filter
+func EscapeFilter(value string) string {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch c {
+		case '*', '(', ')', '\\', 0:
+			sb.WriteString("\\")
+			sb.WriteString(hex.EncodeToString([]byte{c}))
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// compileFilter converts a RFC 4515 string filter into its BER representation
+func compileFilter(filter string) (*packet, error) {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return nil, errors.New("ldap: empty filter")
+	}
+	if filter[0] != '(' {
+		filter = "(" + filter + ")"
+	}
+
+	p, rest, err := parseFilter(filter, 0)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, errors.New("ldap: unexpected '%s' after filter", rest)
+	}
+	return p, nil
+}
+
+func parseFilter(filter string, depth int) (*packet, string, error) {
+	if depth > 32 {
+		return nil, "", errors.New("ldap: filter is too deeply nested")
+	}
+	if len(filter) < 3 || filter[0] != '(' {
+		return nil, "", errors.New("ldap: invalid filter '%s'", filter)
+	}
+
+	switch filter[1] {
+	case '&', '|':
+		tag := byte(filterAnd)
+		if filter[1] == '|' {
+			tag = filterOr
+		}
+		p := newConstructed(tag)
+		rest := filter[2:]
+		for len(rest) > 0 && rest[0] == '(' {
+			child, remaining, err := parseFilter(rest, depth+1)
+			if err != nil {
+				return nil, "", err
+			}
+			p.children = append(p.children, child)
+			rest = remaining
+		}
+		if len(rest) == 0 || rest[0] != ')' {
+			return nil, "", errors.New("ldap: missing ')' in filter")
+		}
+		return p, rest[1:], nil
+	case '!':
+		child, rest, err := parseFilter(filter[2:], depth+1)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(rest) == 0 || rest[0] != ')' {
+			return nil, "", errors.New("ldap: missing ')' in filter")
+		}
+		return newConstructed(filterNot, child), rest[1:], nil
+	}
+
+	end := strings.IndexByte(filter, ')')
+	if end < 0 {
+		return nil, "", errors.New("ldap: missing ')' in filter")
+	}
+	p, err := parseItem(filter[1:end])
+	if err != nil {
+		return nil, "", err
+	}
+	return p, filter[end+1:], nil
+}
+
+func parseItem(item string) (*packet, error) {
+	eq := strings.IndexByte(item, '=')
+	if eq <= 0 {
+		return nil, errors.New("ldap: invalid filter item '%s'", item)
+	}
+
+	attr, value := item[:eq], item[eq+1:]
+	tag := byte(filterEqualityMatch)
+	switch attr[len(attr)-1] {
+	case '>':
+		tag, attr = filterGreaterOrEqual, attr[:len(attr)-1]
+	case '<':
+		tag, attr = filterLessOrEqual, attr[:len(attr)-1]
+	case '~':
+		tag, attr = filterApproxMatch, attr[:len(attr)-1]
+	}
+	if attr == "" {
+		return nil, errors.New("ldap: invalid filter item '%s'", item)
+	}
+
+	if tag == filterEqualityMatch && value == "*" {
+		return newString(filterPresent, attr), nil
+	}
+
+	if tag == filterEqualityMatch && strings.Contains(value, "*") {
+		parts := strings.Split(value, "*")
+		substrings := newConstructed(tagSequence)
+		for i, part := range parts {
+			if part == "" {
+				continue
+			}
+			unescaped, err := unescapeFilterValue(part)
+			if err != nil {
+				return nil, err
+			}
+			kind := byte(substringAny)
+			if i == 0 {
+				kind = substringInitial
+			} else if i == len(parts)-1 {
+				kind = substringFinal
+			}
+			substrings.children = append(substrings.children, newString(kind, unescaped))
+		}
+		return newConstructed(filterSubstrings, newString(tagOctetString, attr), substrings), nil
+	}
+
+	unescaped, err := unescapeFilterValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return newConstructed(tag, newString(tagOctetString, attr), newString(tagOctetString, unescaped)), nil
+}
+
+func unescapeFilterValue(value string) (string, error) {
+	if !strings.Contains(value, "\\") {
+		return value, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			sb.WriteByte(value[i])
+			continue
+		}
+		if i+3 > len(value) {
+			return "", errors.New("ldap: invalid escape sequence in '%s'", value)
+		}
+		b, err := hex.DecodeString(value[i+1 : i+3])
+		if err != nil {
+			return "", errors.New("ldap: invalid escape sequence in '%s'", value)
+		}
+		sb.Write(b)
+		i += 2
+	}
+	return sb.String(), nil
+}
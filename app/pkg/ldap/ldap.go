@@ -0,0 +1,281 @@
+package ldap
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// Timeout is the maximum duration of a single LDAP operation
+var Timeout = 10 * time.Second
+
+// ErrInvalidCredentials is returned when the server rejects a bind request
+var ErrInvalidCredentials = errors.New("ldap: invalid credentials")
+
+// Protocol operations (RFC 4511 section 4.2 to 4.12)
+const (
+	opBindRequest      = classApplication | constructed | 0
+	opBindResponse     = classApplication | constructed | 1
+	opUnbindRequest    = classApplication | 2
+	opSearchRequest    = classApplication | constructed | 3
+	opSearchEntry      = classApplication | constructed | 4
+	opSearchDone       = classApplication | constructed | 5
+	opExtendedRequest  = classApplication | constructed | 23
+	opExtendedResponse = classApplication | constructed | 24
+)
+
+// Protocol values (RFC 4511)
+const (
+	resultSuccess            = 0
+	resultSizeLimitExceeded  = 4
+	resultInvalidCredentials = 49
+	scopeWholeSubtree        = 2
+	derefAliasesNever        = 0
+	startTLSOID              = "1.3.6.1.4.1.1466.20037"
+	simpleAuthenticationTag  = classContext | 0
+	extendedRequestNameTag   = classContext | 0
+)
+
+// Entry is a directory entry returned by a search
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// GetAttributeValues returns all values of given attribute, ignoring the case of its name
+func (e *Entry) GetAttributeValues(name string) []string {
+	for key, values := range e.Attributes {
+		if strings.EqualFold(key, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+// GetAttributeValue returns the first value of given attribute, or empty when it's missing
+func (e *Entry) GetAttributeValue(name string) string {
+	values := e.GetAttributeValues(name)
+	if len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Conn is a synchronous connection to a LDAP server
+type Conn struct {
+	host      string
+	conn      net.Conn
+	reader    *bufio.Reader
+	messageID int64
+}
+
+// Dial connects to the server of given URL, which must use either ldap:// or ldaps:// scheme
+func Dial(rawURL string, tlsConfig *tls.Config) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse LDAP URL")
+	}
+
+	host := u.Hostname()
+	port := u.Port()
+	dialer := &net.Dialer{Timeout: Timeout}
+
+	var conn net.Conn
+	switch u.Scheme {
+	case "ldap":
+		if port == "" {
+			port = "389"
+		}
+		conn, err = dialer.Dial("tcp", net.JoinHostPort(host, port))
+	case "ldaps":
+		if port == "" {
+			port = "636"
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(host, port), withServerName(tlsConfig, host))
+	default:
+		return nil, errors.New("ldap: unsupported scheme '%s'", u.Scheme)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to LDAP server '%s'", u.Host)
+	}
+
+	c := NewConn(conn)
+	c.host = host
+	return c, nil
+}
+
+// NewConn wraps an already established connection
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// StartTLS upgrades the connection to TLS using the StartTLS extended operation
+func (c *Conn) StartTLS(tlsConfig *tls.Config) error {
+	request := newConstructed(opExtendedRequest, newString(extendedRequestNameTag, startTLSOID))
+	responses, err := c.roundTrip(request, opExtendedResponse)
+	if err != nil {
+		return errors.Wrap(err, "failed to start TLS")
+	}
+	if err := resultError(responses[len(responses)-1]); err != nil {
+		return errors.Wrap(err, "failed to start TLS")
+	}
+
+	tlsConn := tls.Client(c.conn, withServerName(tlsConfig, c.host))
+	_ = tlsConn.SetDeadline(time.Now().Add(Timeout))
+	if err := tlsConn.Handshake(); err != nil {
+		return errors.Wrap(err, "failed to complete TLS handshake")
+	}
+
+	c.conn = tlsConn
+	c.reader = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// Bind authenticates the connection with a simple bind
+// ErrInvalidCredentials is returned when the server rejects given DN or password
+func (c *Conn) Bind(dn, password string) error {
+	if password == "" {
+		// an empty password would be an unauthenticated bind, which most servers accept for any DN
+		return ErrInvalidCredentials
+	}
+
+	request := newConstructed(opBindRequest,
+		newInteger(tagInteger, 3),
+		newString(tagOctetString, dn),
+		newString(simpleAuthenticationTag, password),
+	)
+	responses, err := c.roundTrip(request, opBindResponse)
+	if err != nil {
+		return errors.Wrap(err, "failed to bind")
+	}
+	return resultError(responses[len(responses)-1])
+}
+
+// Search returns all entries under baseDN matching given filter
+// When sizeLimit is reached, the entries received so far are returned without error
+func (c *Conn) Search(baseDN, filter string, attributes []string, sizeLimit int) ([]*Entry, error) {
+	compiled, err := compileFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := newConstructed(tagSequence)
+	for _, attr := range attributes {
+		attrs.children = append(attrs.children, newString(tagOctetString, attr))
+	}
+
+	request := newConstructed(opSearchRequest,
+		newString(tagOctetString, baseDN),
+		newInteger(tagEnumerated, scopeWholeSubtree),
+		newInteger(tagEnumerated, derefAliasesNever),
+		newInteger(tagInteger, int64(sizeLimit)),
+		newInteger(tagInteger, int64(Timeout/time.Second)),
+		newBoolean(false),
+		compiled,
+		attrs,
+	)
+	responses, err := c.roundTrip(request, opSearchDone)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to search")
+	}
+
+	done := responses[len(responses)-1]
+	if code := done.child(0).int(); code != resultSizeLimitExceeded {
+		if err := resultError(done); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]*Entry, 0)
+	for _, response := range responses[:len(responses)-1] {
+		if response.tag != opSearchEntry {
+			continue
+		}
+		entry := &Entry{DN: response.child(0).string(), Attributes: make(map[string][]string)}
+		for _, attr := range response.child(1).children {
+			values := make([]string, len(attr.child(1).children))
+			for i, value := range attr.child(1).children {
+				values[i] = value.string()
+			}
+			entry.Attributes[attr.child(0).string()] = values
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Close sends an unbind request and closes the connection
+func (c *Conn) Close() error {
+	c.messageID++
+	message := newConstructed(tagSequence, newInteger(tagInteger, c.messageID), &packet{tag: opUnbindRequest})
+	_ = c.conn.SetDeadline(time.Now().Add(Timeout))
+	_, _ = c.conn.Write(message.bytes())
+	return c.conn.Close()
+}
+
+// roundTrip sends given operation and reads responses until one with the final operation tag is received
+func (c *Conn) roundTrip(op *packet, final byte) ([]*packet, error) {
+	c.messageID++
+	message := newConstructed(tagSequence, newInteger(tagInteger, c.messageID), op)
+
+	if err := c.conn.SetDeadline(time.Now().Add(Timeout)); err != nil {
+		return nil, err
+	}
+	if _, err := c.conn.Write(message.bytes()); err != nil {
+		return nil, err
+	}
+
+	responses := make([]*packet, 0)
+	for {
+		response, err := readPacket(c.reader)
+		if err != nil {
+			return nil, err
+		}
+		if response.tag != tagSequence || len(response.children) < 2 {
+			return nil, errors.New("ldap: malformed response")
+		}
+
+		id := response.child(0).int()
+		if id == 0 {
+			// unsolicited notification, usually a notice of disconnection
+			return nil, resultError(response.child(1))
+		}
+		if id != c.messageID {
+			continue
+		}
+
+		responses = append(responses, response.child(1))
+		if response.child(1).tag == final {
+			return responses, nil
+		}
+	}
+}
+
+func resultError(result *packet) error {
+	code := result.child(0).int()
+	switch code {
+	case resultSuccess:
+		return nil
+	case resultInvalidCredentials:
+		return ErrInvalidCredentials
+	}
+
+	message := result.child(2).string()
+	return errors.New("ldap: operation failed with result code %d: %s", code, message)
+}
+
+func withServerName(tlsConfig *tls.Config, host string) *tls.Config {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = host
+	}
+	return tlsConfig
+}
@@ -0,0 +1,357 @@
+package ldap
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+)
+
+// directory is a tiny in-memory LDAP server used to test the client
+type directory struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	passwords map[string]string
+	entries   []*Entry
+}
+
+func newDirectory(t *testing.T) *directory {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &directory{
+		listener:  listener,
+		tlsConfig: selfSignedTLSConfig(t),
+		passwords: map[string]string{
+			"cn=admin,dc=got,dc=com":               "admin_pw",
+			"uid=jon.snow,ou=people,dc=got,dc=com": "ghost",
+		},
+		entries: []*Entry{
+			{
+				DN: "uid=jon.snow,ou=people,dc=got,dc=com",
+				Attributes: map[string][]string{
+					"objectClass": {"person"},
+					"uid":         {"jon.snow"},
+					"cn":          {"Jon Snow"},
+					"mail":        {"jon.snow@got.com"},
+					"memberOf":    {"cn=nightswatch,ou=groups,dc=got,dc=com", "cn=starks,ou=groups,dc=got,dc=com"},
+				},
+			},
+			{
+				DN: "uid=arya.stark,ou=people,dc=got,dc=com",
+				Attributes: map[string][]string{
+					"objectClass": {"person"},
+					"uid":         {"arya.stark"},
+					"cn":          {"Arya Stark"},
+					"mail":        {"arya.stark@got.com"},
+				},
+			},
+		},
+	}
+	go d.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+	return d
+}
+
+func (d *directory) url() string {
+	return "ldap://" + d.listener.Addr().String()
+}
+
+func (d *directory) serve() {
+	for {
+		conn, err := d.listener.Accept()
+		if err != nil {
+			return
+		}
+		go d.handle(conn)
+	}
+}
+
+func (d *directory) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		message, err := readPacket(reader)
+		if err != nil {
+			return
+		}
+
+		id := message.child(0).int()
+		op := message.child(1)
+		reply := func(responses ...*packet) {
+			for _, response := range responses {
+				_, _ = conn.Write(newConstructed(tagSequence, newInteger(tagInteger, id), response).bytes())
+			}
+		}
+
+		switch op.tag {
+		case opBindRequest:
+			code := int64(resultInvalidCredentials)
+			if password, ok := d.passwords[op.child(1).string()]; ok && password == op.child(2).string() {
+				code = resultSuccess
+			}
+			reply(ldapResult(opBindResponse, code))
+		case opSearchRequest:
+			responses := make([]*packet, 0)
+			for _, entry := range d.entries {
+				if strings.HasSuffix(entry.DN, op.child(0).string()) && matches(op.child(6), entry) {
+					responses = append(responses, toSearchEntry(entry, op.child(7)))
+				}
+			}
+			code := int64(resultSuccess)
+			if limit := int(op.child(3).int()); limit > 0 && len(responses) > limit {
+				responses = responses[:limit]
+				code = resultSizeLimitExceeded
+			}
+			reply(append(responses, ldapResult(opSearchDone, code))...)
+		case opExtendedRequest:
+			reply(ldapResult(opExtendedResponse, resultSuccess))
+			tlsConn := tls.Server(conn, d.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			reader = bufio.NewReader(tlsConn)
+		case opUnbindRequest:
+			return
+		}
+	}
+}
+
+func ldapResult(tag byte, code int64) *packet {
+	return newConstructed(tag, newInteger(tagEnumerated, code), newString(tagOctetString, ""), newString(tagOctetString, ""))
+}
+
+func toSearchEntry(entry *Entry, attributes *packet) *packet {
+	attrs := newConstructed(tagSequence)
+	for _, requested := range attributes.children {
+		values := newConstructed(tagSet)
+		for _, value := range entry.GetAttributeValues(requested.string()) {
+			values.children = append(values.children, newString(tagOctetString, value))
+		}
+		attrs.children = append(attrs.children, newConstructed(tagSequence, newString(tagOctetString, requested.string()), values))
+	}
+	return newConstructed(opSearchEntry, newString(tagOctetString, entry.DN), attrs)
+}
+
+func matches(filter *packet, entry *Entry) bool {
+	switch filter.tag {
+	case filterAnd:
+		for _, child := range filter.children {
+			if !matches(child, entry) {
+				return false
+			}
+		}
+		return true
+	case filterOr:
+		for _, child := range filter.children {
+			if matches(child, entry) {
+				return true
+			}
+		}
+		return false
+	case filterNot:
+		return !matches(filter.child(0), entry)
+	case filterPresent:
+		return len(entry.GetAttributeValues(filter.string())) > 0
+	case filterEqualityMatch:
+		for _, value := range entry.GetAttributeValues(filter.child(0).string()) {
+			if strings.EqualFold(value, filter.child(1).string()) {
+				return true
+			}
+		}
+	case filterSubstrings:
+		for _, value := range entry.GetAttributeValues(filter.child(0).string()) {
+			ok := true
+			for _, part := range filter.child(1).children {
+				switch part.tag {
+				case substringInitial:
+					ok = ok && strings.HasPrefix(value, part.string())
+				case substringFinal:
+					ok = ok && strings.HasSuffix(value, part.string())
+				default:
+					ok = ok && strings.Contains(value, part.string())
+				}
+			}
+			if ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func selfSignedTLSConfig(t *testing.T) *tls.Config {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+}
+
+func TestPacket_Integer(t *testing.T) {
+	RegisterT(t)
+
+	for _, n := range []int64{0, 1, 127, 128, 255, 256, 65535, -1, -128, -129, 2147483647} {
+		p := newInteger(tagInteger, n)
+		Expect(p.int()).Equals(n)
+
+		decoded, err := readPacket(bufio.NewReader(strings.NewReader(string(p.bytes()))))
+		Expect(err).IsNil()
+		Expect(decoded.int()).Equals(n)
+	}
+}
+
+func TestPacket_LongLength(t *testing.T) {
+	RegisterT(t)
+
+	value := strings.Repeat("a", 300)
+	p := newConstructed(tagSequence, newString(tagOctetString, value), newBoolean(true))
+	encoded := p.bytes()
+	Expect(encoded[1]).Equals(byte(0x82))
+
+	decoded, err := readPacket(bufio.NewReader(strings.NewReader(string(encoded))))
+	Expect(err).IsNil()
+	Expect(decoded.children).HasLen(2)
+	Expect(decoded.child(0).string()).Equals(value)
+	Expect(decoded.child(1).value).Equals([]byte{0xff})
+}
+
+func TestPacket_Truncated(t *testing.T) {
+	RegisterT(t)
+
+	encoded := newConstructed(tagSequence, newString(tagOctetString, "hello")).bytes()
+	_, err := readPacket(bufio.NewReader(strings.NewReader(string(encoded[:len(encoded)-2]))))
+	Expect(err).IsNotNil()
+}
+
+func TestEscapeFilter(t *testing.T) {
+	RegisterT(t)
+
+	Expect(EscapeFilter("jon.snow")).Equals("jon.snow")
+	Expect(EscapeFilter("*)(uid=*")).Equals("\\2a\\29\\28uid=\\2a")
+	Expect(EscapeFilter("a\\b")).Equals("a\\5cb")
+}
+
+func TestCompileFilter(t *testing.T) {
+	RegisterT(t)
+
+	entry := &Entry{Attributes: map[string][]string{
+		"objectClass": {"person"},
+		"uid":         {"jon.snow"},
+		"cn":          {"Jon (Lord Commander) Snow"},
+	}}
+
+	testCases := []struct {
+		filter  string
+		matches bool
+	}{
+		{"(uid=jon.snow)", true},
+		{"uid=jon.snow", true},
+		{"(uid=arya.stark)", false},
+		{"(&(objectClass=person)(uid=jon.snow))", true},
+		{"(&(objectClass=person)(uid=arya.stark))", false},
+		{"(|(uid=arya.stark)(uid=jon.snow))", true},
+		{"(!(uid=jon.snow))", false},
+		{"(mail=*)", false},
+		{"(uid=*)", true},
+		{"(uid=jon*)", true},
+		{"(uid=*snow)", true},
+		{"(uid=j*n.s*w)", true},
+		{"(cn=Jon \\28Lord Commander\\29 Snow)", true},
+	}
+
+	for _, testCase := range testCases {
+		filter, err := compileFilter(testCase.filter)
+		Expect(err).IsNil()
+		Expect(matches(filter, entry)).Equals(testCase.matches)
+	}
+
+	for _, invalid := range []string{"", "(uid=jon", "(&(uid=jon)", "(=jon)", "(uid=\\2)", "(uid=a)(uid=b)"} {
+		_, err := compileFilter(invalid)
+		Expect(err).IsNotNil()
+	}
+}
+
+func TestConn_BindAndSearch(t *testing.T) {
+	RegisterT(t)
+
+	d := newDirectory(t)
+	conn, err := Dial(d.url(), nil)
+	Expect(err).IsNil()
+	defer conn.Close()
+
+	Expect(conn.Bind("cn=admin,dc=got,dc=com", "wrong")).Equals(ErrInvalidCredentials)
+	Expect(conn.Bind("cn=admin,dc=got,dc=com", "")).Equals(ErrInvalidCredentials)
+	Expect(conn.Bind("cn=admin,dc=got,dc=com", "admin_pw")).IsNil()
+
+	entries, err := conn.Search("dc=got,dc=com", "(&(objectClass=person)(uid="+EscapeFilter("jon.snow")+"))", []string{"uid", "cn", "mail", "memberOf"}, 2)
+	Expect(err).IsNil()
+	Expect(entries).HasLen(1)
+	Expect(entries[0].DN).Equals("uid=jon.snow,ou=people,dc=got,dc=com")
+	Expect(entries[0].GetAttributeValue("CN")).Equals("Jon Snow")
+	Expect(entries[0].GetAttributeValue("mail")).Equals("jon.snow@got.com")
+	Expect(entries[0].GetAttributeValues("memberof")).HasLen(2)
+	Expect(entries[0].GetAttributeValue("telephoneNumber")).Equals("")
+
+	entries, err = conn.Search("dc=got,dc=com", "(uid="+EscapeFilter("*")+")", []string{"uid"}, 2)
+	Expect(err).IsNil()
+	Expect(entries).HasLen(0)
+
+	entries, err = conn.Search("dc=got,dc=com", "(objectClass=person)", []string{"uid"}, 1)
+	Expect(err).IsNil()
+	Expect(entries).HasLen(1)
+
+	Expect(conn.Bind("uid=jon.snow,ou=people,dc=got,dc=com", "ghost")).IsNil()
+}
+
+func TestConn_StartTLS(t *testing.T) {
+	RegisterT(t)
+
+	d := newDirectory(t)
+	conn, err := Dial(d.url(), nil)
+	Expect(err).IsNil()
+	defer conn.Close()
+
+	// the certificate is self-signed, so it must be rejected by default
+	err = conn.StartTLS(nil)
+	Expect(err).IsNotNil()
+
+	conn, err = Dial(d.url(), nil)
+	Expect(err).IsNil()
+	defer conn.Close()
+
+	err = conn.StartTLS(&tls.Config{InsecureSkipVerify: true})
+	Expect(err).IsNil()
+	Expect(conn.Bind("cn=admin,dc=got,dc=com", "admin_pw")).IsNil()
+}
+
+func TestDial_UnsupportedScheme(t *testing.T) {
+	RegisterT(t)
+
+	conn, err := Dial("http://localhost:389", nil)
+	Expect(err).IsNotNil()
+	Expect(conn).IsNil()
+}
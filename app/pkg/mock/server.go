@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/entity"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
@@ -31,11 +32,21 @@ func createServer() *Server {
 	bus.AddHandler(func(ctx context.Context, q *query.ListActiveOAuthProviders) error {
 		return nil
 	})
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		return nil
+	})
+	bus.AddHandler(func(ctx context.Context, q *query.CheckSpam) error {
+		return nil
+	})
+	bus.AddHandler(func(ctx context.Context, c *cmd.PublishRealtimeEvent) error {
+		return nil
+	})
 
 	engine := web.New()
 
 	// Create a new request and set matched routed into context
 	request, _ := http.NewRequest("GET", "/", nil)
+	request.RemoteAddr = "192.0.2.1:1234"
 	request = request.WithContext(context.WithValue(request.Context(), httprouter.ParamsKey, httprouter.Params{
 		httprouter.Param{Key: httprouter.MatchedRoutePathParam, Value: "/"},
 	}))
@@ -77,6 +88,12 @@ func (s *Server) AsUser(user *entity.User) *Server {
 	return s
 }
 
+// ImpersonatedBy set the administrator that is impersonating current context user
+func (s *Server) ImpersonatedBy(user *entity.User) *Server {
+	s.context.SetImpersonator(user)
+	return s
+}
+
 // AddParam to current context route parameters
 func (s *Server) AddParam(name string, value any) *Server {
 	s.context.AddParam(name, fmt.Sprintf("%v", value))
@@ -6,6 +6,8 @@ import (
 
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/web"
 	"github.com/getfider/fider/app/pkg/worker"
 )
@@ -18,6 +20,10 @@ type Worker struct {
 }
 
 func createWorker() *Worker {
+	bus.AddHandler(func(ctx context.Context, q *query.GetUsersEmailDelivery) error {
+		return nil
+	})
+
 	return &Worker{}
 }
 
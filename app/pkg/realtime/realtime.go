@@ -0,0 +1,134 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/lib/pq"
+)
+
+// Channel is the Postgres channel used to fan out events across all server instances
+const Channel = "fider_events"
+
+// bufferSize is how many events a subscriber can have pending before new ones are dropped
+const bufferSize = 16
+
+// Event is a message streamed to connected browsers
+// When UserID is zero, the event is sent to every connected user of the tenant
+type Event struct {
+	TenantID int                    `json:"tenantId"`
+	UserID   int                    `json:"userId,omitempty"`
+	Type     enum.RealtimeEventType `json:"type"`
+	Data     dto.Props              `json:"data,omitempty"`
+}
+
+type subscriber struct {
+	tenantID int
+	userID   int
+	events   chan Event
+}
+
+var (
+	mu          sync.RWMutex
+	subscribers = make(map[*subscriber]bool)
+)
+
+// Subscribe returns a channel that receives the events of given tenant and user
+// The returned function must be called to release the subscription
+func Subscribe(tenantID, userID int) (<-chan Event, func()) {
+	s := &subscriber{
+		tenantID: tenantID,
+		userID:   userID,
+		events:   make(chan Event, bufferSize),
+	}
+
+	mu.Lock()
+	subscribers[s] = true
+	mu.Unlock()
+
+	var once sync.Once
+	return s.events, func() {
+		once.Do(func() {
+			mu.Lock()
+			delete(subscribers, s)
+			mu.Unlock()
+		})
+	}
+}
+
+// Broadcast sends given event to every local subscriber it's meant for
+// Slow subscribers never block the broadcast, they miss the event instead
+func Broadcast(event Event) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for s := range subscribers {
+		if s.tenantID != event.TenantID || (event.UserID != 0 && s.userID != event.UserID) {
+			continue
+		}
+
+		select {
+		case s.events <- event:
+		default:
+		}
+	}
+}
+
+// Close ends every subscription, which is used to let open streams finish before the server shuts down
+func Close() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for s := range subscribers {
+		close(s.events)
+		delete(subscribers, s)
+	}
+}
+
+// Listen starts listening for events published by any server instance and broadcasts them to local subscribers
+func Listen(ctx context.Context) error {
+	listener := pq.NewListener(env.Config.Database.URL, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Error(ctx, errors.Wrap(err, "realtime listener failed"))
+		}
+	})
+
+	if err := listener.Listen(Channel); err != nil {
+		return errors.Wrap(err, "failed to listen to channel '%s'", Channel)
+	}
+
+	go func() {
+		defer listener.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case n := <-listener.Notify:
+				// nil is sent after the connection is re-established
+				if n == nil {
+					continue
+				}
+
+				var event Event
+				if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+					log.Error(ctx, errors.Wrap(err, "failed to parse realtime event"))
+					continue
+				}
+				Broadcast(event)
+			case <-time.After(90 * time.Second):
+				go func() {
+					_ = listener.Ping()
+				}()
+			}
+		}
+	}()
+
+	return nil
+}
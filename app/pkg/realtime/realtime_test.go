@@ -0,0 +1,72 @@
+package realtime_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/pkg/realtime"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+)
+
+func TestBroadcast_FiltersByTenantAndUser(t *testing.T) {
+	RegisterT(t)
+
+	jon, unsubscribeJon := realtime.Subscribe(1, 10)
+	defer unsubscribeJon()
+	arya, unsubscribeArya := realtime.Subscribe(1, 20)
+	defer unsubscribeArya()
+	other, unsubscribeOther := realtime.Subscribe(2, 10)
+	defer unsubscribeOther()
+
+	realtime.Broadcast(realtime.Event{TenantID: 1, Type: enum.RealtimeVote, Data: dto.Props{"postNumber": 1}})
+	realtime.Broadcast(realtime.Event{TenantID: 1, UserID: 20, Type: enum.RealtimeNotification})
+
+	Expect(len(jon)).Equals(1)
+	Expect(len(arya)).Equals(2)
+	Expect(len(other)).Equals(0)
+
+	event := <-jon
+	Expect(event.Type).Equals(enum.RealtimeVote)
+	Expect(event.Data["postNumber"]).Equals(1)
+
+	event = <-arya
+	Expect(event.Type).Equals(enum.RealtimeVote)
+	event = <-arya
+	Expect(event.Type).Equals(enum.RealtimeNotification)
+}
+
+func TestBroadcast_Unsubscribed(t *testing.T) {
+	RegisterT(t)
+
+	events, unsubscribe := realtime.Subscribe(1, 10)
+	unsubscribe()
+	unsubscribe()
+
+	realtime.Broadcast(realtime.Event{TenantID: 1, Type: enum.RealtimeComment})
+	Expect(len(events)).Equals(0)
+}
+
+func TestBroadcast_DoesNotBlockOnSlowSubscribers(t *testing.T) {
+	RegisterT(t)
+
+	events, unsubscribe := realtime.Subscribe(1, 10)
+	defer unsubscribe()
+
+	for i := 0; i < 100; i++ {
+		realtime.Broadcast(realtime.Event{TenantID: 1, Type: enum.RealtimeComment})
+	}
+	Expect(len(events)).Equals(cap(events))
+}
+
+func TestClose(t *testing.T) {
+	RegisterT(t)
+
+	events, unsubscribe := realtime.Subscribe(1, 10)
+	realtime.Close()
+	unsubscribe()
+
+	_, ok := <-events
+	Expect(ok).IsFalse()
+}
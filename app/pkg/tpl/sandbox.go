@@ -0,0 +1,162 @@
+package tpl
+
+import (
+	"context"
+	"html/template"
+	"io"
+	"os"
+	"path"
+	"text/template/parse"
+
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// MaxSandboxedBlockSize is the maximum length of the source of a sandboxed block
+const MaxSandboxedBlockSize = 64 * 1024
+
+// MaxSandboxedOutputSize is the maximum number of bytes a sandboxed template can render
+const MaxSandboxedOutputSize = 2 * 1024 * 1024
+
+// ErrOutputTooLarge is returned when a sandboxed template renders more than MaxSandboxedOutputSize
+var ErrOutputTooLarge = errors.New("template output is too large")
+
+// GetSandboxedTemplate returns base template with its blocks replaced by given user provided sources
+// Sources can only use the data they are executed with and the template functions,
+// so they can't define, invoke or override other templates
+func GetSandboxedTemplate(baseFileName string, blocks map[string]string) (*template.Template, error) {
+	baseFile := env.Path(baseFileName)
+	tmpl, err := template.New(path.Base(baseFile)).Funcs(templateFunctions).ParseFiles(baseFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse template %s", baseFileName)
+	}
+
+	for name, text := range blocks {
+		if err := checkSandboxedSource(name, text); err != nil {
+			return nil, err
+		}
+		if _, err := tmpl.New(name).Parse(text); err != nil {
+			return nil, err
+		}
+	}
+
+	return tmpl, nil
+}
+
+// GetBlockSource returns the source of a block defined on given template file
+func GetBlockSource(templateFileName, name string) (string, error) {
+	content, err := os.ReadFile(env.Path(templateFileName))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read template %s", templateFileName)
+	}
+
+	trees, err := parseTrees(templateFileName, string(content))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse template %s", templateFileName)
+	}
+
+	tree, ok := trees[name]
+	if !ok || tree.Root == nil {
+		return "", errors.New("block '%s' is not defined on template %s", name, templateFileName)
+	}
+	return tree.Root.String(), nil
+}
+
+// RenderSandboxed executes a template returned by GetSandboxedTemplate
+// limiting how much it can write on w
+func RenderSandboxed(ctx context.Context, tmpl *template.Template, w io.Writer, data any) error {
+	return Render(ctx, tmpl, &limitedWriter{w: w, remaining: MaxSandboxedOutputSize}, data)
+}
+
+func checkSandboxedSource(name, text string) error {
+	if len(text) > MaxSandboxedBlockSize {
+		return errors.New("template: %s: source must have less than %d characters", name, MaxSandboxedBlockSize)
+	}
+
+	trees, err := parseTrees(name, text)
+	if err != nil {
+		return err
+	}
+	if len(trees) > 1 {
+		return errors.New("template: %s: defining templates is not allowed", name)
+	}
+	if tree, ok := trees[name]; ok && tree.Root != nil {
+		return checkSandboxedNode(name, tree.Root)
+	}
+	return nil
+}
+
+func checkSandboxedNode(name string, node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkSandboxedNode(name, child); err != nil {
+				return err
+			}
+		}
+	case *parse.TemplateNode:
+		return errors.New("template: %s: invoking templates is not allowed", name)
+	case *parse.RangeNode:
+		// Only data can be ranged over, otherwise a template could loop for as long as it wants
+		// e.g.: integers can be ranged over since Go 1.22
+		if !isDataPipe(n.Pipe) {
+			return errors.New("template: %s: only fields can be ranged over", name)
+		}
+		return checkBranchNode(name, &n.BranchNode)
+	case *parse.IfNode:
+		return checkBranchNode(name, &n.BranchNode)
+	case *parse.WithNode:
+		return checkBranchNode(name, &n.BranchNode)
+	}
+	return nil
+}
+
+func isDataPipe(pipe *parse.PipeNode) bool {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.DotNode, *parse.FieldNode:
+		return true
+	case *parse.VariableNode:
+		// Variables can hold anything, but only data has fields
+		return len(arg.Ident) > 1
+	}
+	return false
+}
+
+func checkBranchNode(name string, n *parse.BranchNode) error {
+	if err := checkSandboxedNode(name, n.List); err != nil {
+		return err
+	}
+	if n.ElseList != nil {
+		return checkSandboxedNode(name, n.ElseList)
+	}
+	return nil
+}
+
+func parseTrees(name, text string) (map[string]*parse.Tree, error) {
+	trees := make(map[string]*parse.Tree)
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+	if _, err := tree.Parse(text, "", "", trees); err != nil {
+		return nil, err
+	}
+	return trees, nil
+}
+
+type limitedWriter struct {
+	w         io.Writer
+	remaining int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.remaining {
+		return 0, ErrOutputTooLarge
+	}
+	l.remaining -= len(p)
+	return l.w.Write(p)
+}
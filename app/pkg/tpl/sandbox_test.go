@@ -0,0 +1,87 @@
+package tpl_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/getfider/fider/app/models/dto"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/tpl"
+)
+
+func TestGetSandboxedTemplate_Render(t *testing.T) {
+	RegisterT(t)
+
+	tmpl, err := tpl.GetSandboxedTemplate("app/pkg/tpl/testdata/base.html", map[string]string{
+		"head": "Custom head",
+		"body": "{{ range .items }}<b>{{ . }}</b>{{ end }}",
+	})
+	Expect(err).IsNil()
+
+	bf := new(bytes.Buffer)
+	err = tpl.RenderSandboxed(context.Background(), tmpl, bf, dto.Props{
+		"items": []string{"<script>", "Jon"},
+	})
+	Expect(err).IsNil()
+	Expect(bf.String()).Equals(`<html>
+  <head>Custom head</head>
+  <body><b>&lt;script&gt;</b><b>Jon</b></body>
+</html>`)
+}
+
+func TestGetSandboxedTemplate_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	for _, body := range []string{
+		"{{ .name ",
+		"{{ unknown .name }}",
+		`{{ define "head" }}Hacked{{ end }}`,
+		`{{ block "other" . }}Hacked{{ end }}`,
+		`{{ template "head" . }}`,
+		"{{ range 1000000000 }}{{ end }}",
+		"{{ $n := 1000000000 }}{{ range $n }}{{ end }}",
+		`{{ if .name }}{{ range len "abc" }}{{ end }}{{ end }}`,
+		strings.Repeat("a", tpl.MaxSandboxedBlockSize+1),
+	} {
+		tmpl, err := tpl.GetSandboxedTemplate("app/pkg/tpl/testdata/base.html", map[string]string{
+			"body": body,
+		})
+		Expect(err).IsNotNil()
+		Expect(tmpl).IsNil()
+	}
+}
+
+func TestRenderSandboxed_OutputTooLarge(t *testing.T) {
+	RegisterT(t)
+
+	tmpl, err := tpl.GetSandboxedTemplate("app/pkg/tpl/testdata/base.html", map[string]string{
+		"body": "{{ range . }}{{ range $.items }}{{ . }}{{ end }}{{ end }}",
+	})
+	Expect(err).IsNil()
+
+	items := make([]string, 2000)
+	for i := range items {
+		items[i] = strings.Repeat("a", 1000)
+	}
+
+	err = tpl.RenderSandboxed(context.Background(), tmpl, new(bytes.Buffer), dto.Props{
+		"items": items,
+		"other": 1,
+	})
+	Expect(err).IsNotNil()
+}
+
+func TestGetBlockSource(t *testing.T) {
+	RegisterT(t)
+
+	source, err := tpl.GetBlockSource("app/pkg/tpl/testdata/echo.html", "body")
+	Expect(err).IsNil()
+	Expect(source).Equals(`
+  {{translate "email.greetings_name" (dict "name" .name) | html}}
+`)
+
+	_, err = tpl.GetBlockSource("app/pkg/tpl/testdata/echo.html", "subject")
+	Expect(err).IsNotNil()
+}
@@ -55,6 +55,19 @@ func URL(ctx context.Context, rawurl string) []string {
 	return []string{}
 }
 
+//EmailDomain validates given domain of email addresses
+func EmailDomain(ctx context.Context, domain string) []string {
+	domain = strings.ToLower(domain)
+
+	if len(domain) > 100 || !hostnameRegex.MatchString(domain) || !strings.Contains(domain, ".") {
+		return []string{i18n.T(ctx, "validation.custom.invalidemaildomain",
+			i18n.Params{"domain": domain},
+		)}
+	}
+
+	return []string{}
+}
+
 //CNAME validates given cname
 func CNAME(ctx context.Context, cname string) []string {
 	cname = strings.ToLower(cname)
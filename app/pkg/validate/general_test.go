@@ -72,6 +72,31 @@ func TestValidURL(t *testing.T) {
 	}
 }
 
+func TestEmailDomain(t *testing.T) {
+	RegisterT(t)
+
+	for _, domain := range []string{
+		"got.com",
+		"mail.got.com",
+		"jon-snow.got.com",
+	} {
+		messages := validate.EmailDomain(context.Background(), domain)
+		Expect(messages).HasLen(0)
+	}
+
+	for _, domain := range []string{
+		"",
+		"got",
+		"@got.com",
+		"jon.snow@got.com",
+		"got.com/abc",
+		"*.got.com",
+	} {
+		messages := validate.EmailDomain(context.Background(), domain)
+		Expect(len(messages) > 0).IsTrue()
+	}
+}
+
 func TestInvalidCNAME(t *testing.T) {
 	RegisterT(t)
 
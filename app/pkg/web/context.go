@@ -21,6 +21,7 @@ import (
 	"github.com/getfider/fider/app/pkg/dbx"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/pkg/log"
 	"github.com/getfider/fider/app/pkg/rand"
 	"github.com/getfider/fider/app/pkg/validate"
@@ -342,13 +343,31 @@ func (c *Context) User() *entity.User {
 }
 
 // SetUser update HTTP context with current user
+// The preferred locale of the user replaces the locale of the tenant
 func (c *Context) SetUser(user *entity.User) {
 	if user != nil {
 		c.Context = log.WithProperty(c.Context, log.PropertyKeyUserID, user.ID)
+		if i18n.IsValidLocale(user.Locale) {
+			c.Set(app.LocaleCtxKey, user.Locale)
+		}
 	}
 	c.Set(app.UserCtxKey, user)
 }
 
+// Impersonator returns the administrator that is impersonating current user, if any
+func (c *Context) Impersonator() *entity.User {
+	user, ok := c.Value(app.ImpersonatorCtxKey).(*entity.User)
+	if ok {
+		return user
+	}
+	return nil
+}
+
+// SetImpersonator update HTTP context with the administrator that is impersonating current user
+func (c *Context) SetImpersonator(user *entity.User) {
+	c.Set(app.ImpersonatorCtxKey, user)
+}
+
 // AddCookie adds a cookie
 func (c *Context) AddCookie(name, value string, expires time.Time) *http.Cookie {
 	cookie := &http.Cookie{
@@ -533,6 +552,26 @@ func (c *Context) SetCanonicalURL(rawurl string) {
 	}
 }
 
+// WithTenant returns a context that behaves as if it was created by a request to given tenant
+// It's used by code that doesn't run within a request, e.g: jobs and inbound emails
+func WithTenant(ctx context.Context, tenant *entity.Tenant) context.Context {
+	baseURL := env.Config.BaseURL
+	if !env.IsSingleHostMode() {
+		host := tenant.Subdomain + env.MultiTenantDomain()
+		if tenant.CNAME != "" {
+			host = tenant.CNAME
+		}
+		baseURL = "https://" + host
+	}
+
+	ctx = context.WithValue(ctx, app.TenantCtxKey, tenant)
+	ctx = context.WithValue(ctx, app.LocaleCtxKey, tenant.Locale)
+	if u, err := url.Parse(baseURL); err == nil {
+		ctx = context.WithValue(ctx, app.RequestCtxKey, Request{URL: u})
+	}
+	return ctx
+}
+
 // TenantBaseURL returns base URL for a given tenant
 func TenantBaseURL(ctx context.Context, tenant *entity.Tenant) string {
 	if env.IsSingleHostMode() {
@@ -10,6 +10,7 @@ import (
 	"github.com/getfider/fider/app/models/entity"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/pkg/web"
 )
 
@@ -49,6 +50,20 @@ func TestContextID(t *testing.T) {
 	Expect(ctx.ContextID()).HasLen(32)
 }
 
+func TestSetUser_Locale(t *testing.T) {
+	RegisterT(t)
+
+	ctx := newGetContext("http://demo.test.fider.io:3000", nil)
+	ctx.SetTenant(&entity.Tenant{ID: 1, Locale: "de"})
+	Expect(i18n.GetLocale(ctx)).Equals("de")
+
+	ctx.SetUser(&entity.User{ID: 1})
+	Expect(i18n.GetLocale(ctx)).Equals("de")
+
+	ctx.SetUser(&entity.User{ID: 1, Locale: "fr"})
+	Expect(i18n.GetLocale(ctx)).Equals("fr")
+}
+
 func TestBaseURL(t *testing.T) {
 	RegisterT(t)
 
@@ -288,6 +288,10 @@ func (g *Group) Group() *Group {
 
 // Use adds a middleware to current route stack
 func (g *Group) Use(middleware MiddlewareFunc) {
+	if middleware == nil {
+		return
+	}
+
 	g.middlewares = append(g.middlewares, middleware)
 }
 
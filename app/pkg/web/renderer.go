@@ -197,6 +197,11 @@ func (r *Renderer) Render(w io.Writer, statusCode int, props Props, ctx *Context
 		}
 	}
 
+	ldap := ""
+	if env.IsLDAPEnabled() {
+		ldap = env.Config.LDAP.DisplayName
+	}
+
 	public["page"] = props.Page
 	public["contextID"] = ctx.ContextID()
 	public["sessionID"] = ctx.SessionID()
@@ -213,6 +218,8 @@ func (r *Renderer) Render(w io.Writer, statusCode int, props Props, ctx *Context
 		"baseURL":          ctx.BaseURL(),
 		"assetsURL":        AssetsURL(ctx, ""),
 		"oauth":            oauthProviders.Result,
+		"ldap":             ldap,
+		"vapidPublicKey":   env.Config.WebPush.VAPIDPublicKey,
 	}
 
 	if ctx.IsAuthenticated() {
@@ -226,9 +233,17 @@ func (r *Renderer) Render(w io.Writer, statusCode int, props Props, ctx *Context
 			"avatarType":      u.AvatarType,
 			"avatarURL":       u.AvatarURL,
 			"avatarBlobKey":   u.AvatarBlobKey,
+			"locale":          u.Locale,
 			"isAdministrator": u.IsAdministrator(),
 			"isCollaborator":  u.IsCollaborator(),
 		}
+
+		if impersonator := ctx.Impersonator(); impersonator != nil {
+			public["impersonator"] = &Map{
+				"id":   impersonator.ID,
+				"name": impersonator.Name,
+			}
+		}
 	}
 
 	templateName := "index.html"
@@ -2,6 +2,7 @@ package web
 
 import (
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -84,6 +85,68 @@ func (r *Request) AddCookie(cookie *http.Cookie) {
 	r.instance.AddCookie(cookie)
 }
 
+// ClientIP returns the IP address of the client
+// X-Forwarded-For is only trusted when the request comes from one of the configured proxies,
+// in which case the right-most entry that isn't a trusted proxy is the client, anything left of it can be forged
+func (r *Request) ClientIP() string {
+	remoteIP, _, err := net.SplitHostPort(r.instance.RemoteAddr)
+	if err != nil {
+		remoteIP = r.instance.RemoteAddr
+	}
+
+	proxies := trustedProxies()
+	if !isTrustedProxy(proxies, remoteIP) {
+		return remoteIP
+	}
+
+	hops := strings.Split(r.GetHeader("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		if !isTrustedProxy(proxies, hop) {
+			return hop
+		}
+	}
+
+	return remoteIP
+}
+
+func trustedProxies() []*net.IPNet {
+	proxies := make([]*net.IPNet, 0)
+	for _, value := range strings.Split(env.Config.HTTP.TrustedProxies, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil && ip.To4() != nil {
+				value += "/32"
+			} else {
+				value += "/128"
+			}
+		}
+		if _, network, err := net.ParseCIDR(value); err == nil {
+			proxies = append(proxies, network)
+		}
+	}
+	return proxies
+}
+
+func isTrustedProxy(proxies []*net.IPNet, value string) bool {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return false
+	}
+	for _, network := range proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsAPI returns true if its a request for an API resource
 func (r *Request) IsAPI() bool {
 	return strings.HasPrefix(r.URL.Path, "/api/")
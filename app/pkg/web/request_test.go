@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/web"
 )
 
@@ -32,6 +33,43 @@ func TestRequest_Basic(t *testing.T) {
 	Expect(req.IsSecure).Equals(false)
 }
 
+func TestRequest_ClientIP(t *testing.T) {
+	RegisterT(t)
+
+	req := web.WrapRequest(&http.Request{Header: make(http.Header), Host: "helloworld.com", RemoteAddr: "10.0.0.1:52341"})
+	Expect(req.ClientIP()).Equals("10.0.0.1")
+
+	// Forwarded headers are ignored unless they come from a trusted proxy
+	header := make(http.Header)
+	header.Set("X-Real-IP", "172.16.0.3")
+	header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.2")
+	req = web.WrapRequest(&http.Request{Header: header, Host: "helloworld.com", RemoteAddr: "10.0.0.1:52341"})
+	Expect(req.ClientIP()).Equals("10.0.0.1")
+}
+
+func TestRequest_ClientIP_TrustedProxies(t *testing.T) {
+	RegisterT(t)
+	env.Config.HTTP.TrustedProxies = "10.0.0.0/8, 192.168.1.10"
+
+	header := make(http.Header)
+	header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.2")
+	req := web.WrapRequest(&http.Request{Header: header, Host: "helloworld.com", RemoteAddr: "10.0.0.1:52341"})
+	Expect(req.ClientIP()).Equals("203.0.113.7")
+
+	// Only the hop added by the trusted proxy counts, whatever the client sent before it is ignored
+	header.Set("X-Forwarded-For", "1.1.1.1, 203.0.113.7")
+	req = web.WrapRequest(&http.Request{Header: header, Host: "helloworld.com", RemoteAddr: "192.168.1.10:52341"})
+	Expect(req.ClientIP()).Equals("203.0.113.7")
+
+	header.Set("X-Forwarded-For", "203.0.113.7")
+	req = web.WrapRequest(&http.Request{Header: header, Host: "helloworld.com", RemoteAddr: "198.51.100.4:52341"})
+	Expect(req.ClientIP()).Equals("198.51.100.4")
+
+	header.Set("X-Forwarded-For", "not-an-ip")
+	req = web.WrapRequest(&http.Request{Header: header, Host: "helloworld.com", RemoteAddr: "10.0.0.1:52341"})
+	Expect(req.ClientIP()).Equals("10.0.0.1")
+}
+
 func TestRequest_WithPort(t *testing.T) {
 	RegisterT(t)
 
@@ -0,0 +1,13 @@
+package webutil
+
+import (
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// AddAuditLog records a privileged action performed by current user on the tenant audit log
+func AddAuditLog(ctx *web.Context, log *cmd.AddAuditLog) error {
+	log.ClientIP = ctx.Request.ClientIP()
+	return bus.Dispatch(ctx, log)
+}
@@ -40,6 +40,30 @@ func AddAuthTokenCookie(ctx *web.Context, token string) {
 	ctx.AddCookie(web.CookieAuthName, token, expiresAt)
 }
 
+// ImpersonationDuration is how long an administrator can impersonate another user before being signed out
+const ImpersonationDuration = 30 * time.Minute
+
+// AddImpersonationCookie replaces the Auth Token of given administrator with one that acts on behalf of given user
+func AddImpersonationCookie(ctx *web.Context, user, impersonator *entity.User) error {
+	expiresAt := time.Now().Add(ImpersonationDuration)
+	token, err := jwt.Encode(jwt.FiderClaims{
+		UserID:         user.ID,
+		UserName:       user.Name,
+		UserEmail:      user.Email,
+		Origin:         jwt.FiderClaimsOriginUI,
+		ImpersonatorID: impersonator.ID,
+		Metadata: jwt.Metadata{
+			ExpiresAt: jwt.Time(expiresAt),
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to add impersonation cookie")
+	}
+
+	ctx.AddCookie(web.CookieAuthName, token, expiresAt)
+	return nil
+}
+
 //SetSignUpAuthCookie sets a temporary domain-wide Auth Token
 func SetSignUpAuthCookie(ctx *web.Context, user *entity.User) {
 	http.SetCookie(&ctx.Response, &http.Cookie{
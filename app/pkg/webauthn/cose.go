@@ -0,0 +1,105 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// COSE algorithm identifiers supported by Fider
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// SupportedAlgorithms lists all algorithms accepted during registration, in order of preference
+var SupportedAlgorithms = []int{AlgES256, AlgEdDSA, AlgRS256}
+
+const (
+	coseKeyTypeOKP = 1
+	coseKeyTypeEC2 = 2
+	coseKeyTypeRSA = 3
+
+	coseCurveP256    = 1
+	coseCurveEd25519 = 6
+)
+
+// publicKey is a parsed COSE_Key
+type publicKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+func parsePublicKey(coseKey []byte) (*publicKey, error) {
+	item, _, err := decodeCBOR(coseKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode COSE key")
+	}
+
+	m, ok := item.(map[any]any)
+	if !ok {
+		return nil, errors.New("COSE key must be a map")
+	}
+
+	kty, _ := m[int64(1)].(int64)
+	alg, _ := m[int64(3)].(int64)
+
+	switch kty {
+	case coseKeyTypeEC2:
+		crv, _ := m[int64(-1)].(int64)
+		x, _ := m[int64(-2)].([]byte)
+		y, _ := m[int64(-3)].([]byte)
+		if alg != AlgES256 || crv != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("unsupported EC2 key (alg: %d, crv: %d)", alg, crv)
+		}
+		key := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("EC2 key is not on curve P-256")
+		}
+		return &publicKey{alg: alg, key: key}, nil
+	case coseKeyTypeOKP:
+		crv, _ := m[int64(-1)].(int64)
+		x, _ := m[int64(-2)].([]byte)
+		if alg != AlgEdDSA || crv != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("unsupported OKP key (alg: %d, crv: %d)", alg, crv)
+		}
+		return &publicKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+	case coseKeyTypeRSA:
+		n, _ := m[int64(-1)].([]byte)
+		e, _ := m[int64(-2)].([]byte)
+		if alg != AlgRS256 || len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("unsupported RSA key (alg: %d)", alg)
+		}
+		return &publicKey{alg: alg, key: &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}}, nil
+	}
+
+	return nil, errors.New("unsupported COSE key type '%d'", kty)
+}
+
+func (k *publicKey) verify(data, signature []byte) bool {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		hash := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(key, hash[:], signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, data, signature)
+	case *rsa.PublicKey:
+		hash := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature) == nil
+	}
+	return false
+}
@@ -0,0 +1,397 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// Timeout is the number of milliseconds the browser waits for the user to complete a ceremony
+const Timeout = 120000
+
+const (
+	flagUserPresent      = 0x01
+	flagAttestedCredData = 0x40
+)
+
+// transports are the AuthenticatorTransport values a browser can report for a new credential
+var transports = map[string]bool{
+	"usb":        true,
+	"nfc":        true,
+	"ble":        true,
+	"smart-card": true,
+	"hybrid":     true,
+	"internal":   true,
+	"cable":      true,
+}
+
+// IsKnownTransport returns true if given value is a known AuthenticatorTransport
+func IsKnownTransport(transport string) bool {
+	return transports[transport]
+}
+
+// ErrSignCountMismatch is returned when an authenticator reports a sign count that is not greater than the stored one,
+// which usually means the credential has been cloned
+var ErrSignCountMismatch = errors.New("authenticator sign count is not greater than the stored value")
+
+// Config identifies the Relying Party (the Fider site) in WebAuthn ceremonies
+type Config struct {
+	RPID   string
+	RPName string
+	Origin string
+}
+
+// RelyingParty is the JSON representation of PublicKeyCredentialRpEntity
+type RelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity is the JSON representation of PublicKeyCredentialUserEntity
+// ID is a base64url encoded user handle
+type UserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialParameter is the JSON representation of PublicKeyCredentialParameters
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// CredentialDescriptor is the JSON representation of PublicKeyCredentialDescriptor
+// ID is a base64url encoded credential ID
+type CredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// AuthenticatorSelection is the JSON representation of AuthenticatorSelectionCriteria
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	RequireResident  bool   `json:"requireResidentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// CreationOptions is the JSON representation of PublicKeyCredentialCreationOptions
+type CreationOptions struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     RelyingParty           `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int                    `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions is the JSON representation of PublicKeyCredentialRequestOptions
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	RPID             string                 `json:"rpId"`
+	Timeout          int                    `json:"timeout"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// RegistrationResponse is the JSON serialized PublicKeyCredential returned by navigator.credentials.create
+// All binary fields are base64url encoded
+type RegistrationResponse struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string   `json:"clientDataJSON"`
+		AttestationObject string   `json:"attestationObject"`
+		Transports        []string `json:"transports"`
+	} `json:"response"`
+}
+
+// AssertionResponse is the JSON serialized PublicKeyCredential returned by navigator.credentials.get
+// All binary fields are base64url encoded
+type AssertionResponse struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response"`
+}
+
+// Credential is a verified public key credential
+type Credential struct {
+	ID        string
+	PublicKey []byte
+	SignCount uint32
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type authenticatorData struct {
+	rpIDHash  []byte
+	flags     byte
+	signCount uint32
+	credID    []byte
+	publicKey []byte
+}
+
+// NewChallenge returns a random base64url encoded challenge
+func NewChallenge() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(errors.Wrap(err, "failed to generate WebAuthn challenge"))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// EncodeUserHandle returns the base64url encoding of given user handle
+func EncodeUserHandle(handle string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(handle))
+}
+
+// DecodeUserHandle returns the user handle from its base64url encoding
+func DecodeUserHandle(encoded string) (string, error) {
+	b, err := decodeBase64URL(encoded)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// NewCreationOptions returns the options used to register a new passkey for given user
+func (cfg Config) NewCreationOptions(challenge string, user UserEntity, excludeCredentialIDs []string) *CreationOptions {
+	params := make([]CredentialParameter, len(SupportedAlgorithms))
+	for i, alg := range SupportedAlgorithms {
+		params[i] = CredentialParameter{Type: "public-key", Alg: alg}
+	}
+
+	return &CreationOptions{
+		Challenge:          challenge,
+		RP:                 RelyingParty{ID: cfg.RPID, Name: cfg.RPName},
+		User:               user,
+		PubKeyCredParams:   params,
+		Timeout:            Timeout,
+		ExcludeCredentials: descriptors(excludeCredentialIDs),
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "required",
+			RequireResident:  true,
+			UserVerification: "preferred",
+		},
+		Attestation: "none",
+	}
+}
+
+// NewRequestOptions returns the options used to sign in with a passkey
+// An empty list of allowed credentials lets the browser offer any discoverable credential for this site
+func (cfg Config) NewRequestOptions(challenge string, allowCredentialIDs []string) *RequestOptions {
+	return &RequestOptions{
+		Challenge:        challenge,
+		RPID:             cfg.RPID,
+		Timeout:          Timeout,
+		AllowCredentials: descriptors(allowCredentialIDs),
+		UserVerification: "preferred",
+	}
+}
+
+// VerifyRegistration validates the response of a registration ceremony and returns the new credential
+// Attestation statements are not verified, as Fider requests "none" attestation
+func (cfg Config) VerifyRegistration(challenge string, resp *RegistrationResponse) (*Credential, error) {
+	if resp == nil || resp.Type != "public-key" {
+		return nil, errors.New("invalid credential type")
+	}
+
+	if _, err := cfg.verifyClientData(resp.Response.ClientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	rawAttestation, err := decodeBase64URL(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode attestation object")
+	}
+
+	item, _, err := decodeCBOR(rawAttestation)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse attestation object")
+	}
+
+	attestation, ok := item.(map[any]any)
+	if !ok {
+		return nil, errors.New("attestation object must be a map")
+	}
+
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("attestation object is missing authData")
+	}
+
+	authData, err := cfg.verifyAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+
+	if authData.flags&flagAttestedCredData == 0 || len(authData.credID) == 0 {
+		return nil, errors.New("authenticator data is missing the attested credential")
+	}
+
+	if _, err := parsePublicKey(authData.publicKey); err != nil {
+		return nil, err
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(authData.credID)
+	if resp.ID != credentialID {
+		return nil, errors.New("credential ID does not match attested credential")
+	}
+
+	return &Credential{
+		ID:        credentialID,
+		PublicKey: authData.publicKey,
+		SignCount: authData.signCount,
+	}, nil
+}
+
+// VerifyAssertion validates the response of an authentication ceremony against a stored credential
+// The new sign count is returned and should be stored for future verifications
+func (cfg Config) VerifyAssertion(challenge string, resp *AssertionResponse, credential *Credential) (uint32, error) {
+	if resp == nil || resp.Type != "public-key" {
+		return 0, errors.New("invalid credential type")
+	}
+
+	if resp.ID != credential.ID {
+		return 0, errors.New("credential ID does not match")
+	}
+
+	rawClientData, err := cfg.verifyClientData(resp.Response.ClientDataJSON, "webauthn.get", challenge)
+	if err != nil {
+		return 0, err
+	}
+
+	rawAuthData, err := decodeBase64URL(resp.Response.AuthenticatorData)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to decode authenticator data")
+	}
+
+	authData, err := cfg.verifyAuthenticatorData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+
+	signature, err := decodeBase64URL(resp.Response.Signature)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to decode signature")
+	}
+
+	key, err := parsePublicKey(credential.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(rawClientData)
+	signed := append(append([]byte{}, rawAuthData...), clientDataHash[:]...)
+	if !key.verify(signed, signature) {
+		return 0, errors.New("invalid assertion signature")
+	}
+
+	if (authData.signCount != 0 || credential.SignCount != 0) && authData.signCount <= credential.SignCount {
+		return 0, ErrSignCountMismatch
+	}
+
+	return authData.signCount, nil
+}
+
+func (cfg Config) verifyClientData(encoded, ceremony, challenge string) ([]byte, error) {
+	raw, err := decodeBase64URL(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode client data")
+	}
+
+	data := &clientData{}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, errors.Wrap(err, "failed to parse client data")
+	}
+
+	if data.Type != ceremony {
+		return nil, errors.New("unexpected ceremony type '%s'", data.Type)
+	}
+
+	if challenge == "" || subtle.ConstantTimeCompare([]byte(data.Challenge), []byte(challenge)) != 1 {
+		return nil, errors.New("challenge does not match")
+	}
+
+	if data.Origin != cfg.Origin {
+		return nil, errors.New("origin '%s' does not match '%s'", data.Origin, cfg.Origin)
+	}
+
+	return raw, nil
+}
+
+func (cfg Config) verifyAuthenticatorData(raw []byte) (*authenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, errors.New("authenticator data is too short")
+	}
+
+	data := &authenticatorData{
+		rpIDHash:  raw[:32],
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+
+	rpIDHash := sha256.Sum256([]byte(cfg.RPID))
+	if !bytes.Equal(data.rpIDHash, rpIDHash[:]) {
+		return nil, errors.New("relying party ID hash does not match")
+	}
+
+	if data.flags&flagUserPresent == 0 {
+		return nil, errors.New("user presence is required")
+	}
+
+	if data.flags&flagAttestedCredData != 0 {
+		rest := raw[37:]
+		if len(rest) < 18 {
+			return nil, errors.New("attested credential data is too short")
+		}
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if len(rest) < idLen {
+			return nil, errors.New("attested credential data is too short")
+		}
+		data.credID = rest[:idLen]
+		rest = rest[idLen:]
+
+		_, remaining, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode credential public key")
+		}
+		data.publicKey = rest[:len(rest)-len(remaining)]
+	}
+
+	return data, nil
+}
+
+func descriptors(ids []string) []CredentialDescriptor {
+	result := make([]CredentialDescriptor, len(ids))
+	for i, id := range ids {
+		result[i] = CredentialDescriptor{Type: "public-key", ID: id}
+	}
+	return result
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	// browsers and libraries are inconsistent about padding, so accept both forms
+	for len(s)%4 != 0 {
+		s += "="
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
@@ -0,0 +1,216 @@
+package webauthn_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/webauthn"
+)
+
+var cfg = webauthn.Config{
+	RPID:   "demo.test.fider.io",
+	RPName: "Demonstration",
+	Origin: "https://demo.test.fider.io",
+}
+
+// authenticator is a fake ES256 authenticator used to produce WebAuthn responses
+type authenticator struct {
+	key       *ecdsa.PrivateKey
+	credID    []byte
+	signCount uint32
+}
+
+func newAuthenticator() *authenticator {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	return &authenticator{key: key, credID: []byte("credential-1234")}
+}
+
+func (a *authenticator) credentialID() string {
+	return base64.RawURLEncoding.EncodeToString(a.credID)
+}
+
+func (a *authenticator) coseKey() []byte {
+	x := a.key.X.FillBytes(make([]byte, 32))
+	y := a.key.Y.FillBytes(make([]byte, 32))
+	key := []byte{0xa5}
+	key = append(key, 0x01, 0x02)       // kty: EC2
+	key = append(key, 0x03, 0x26)       // alg: ES256
+	key = append(key, 0x20, 0x01)       // crv: P-256
+	key = append(key, 0x21, 0x58, 0x20) // x
+	key = append(key, x...)
+	key = append(key, 0x22, 0x58, 0x20) // y
+	key = append(key, y...)
+	return key
+}
+
+func (a *authenticator) authData(rpID string, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append([]byte{}, rpIDHash[:]...)
+	flags := byte(0x01)
+	if attested {
+		flags |= 0x40
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credID)))
+		data = append(data, a.credID...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+func clientDataJSON(ceremony, challenge, origin string) []byte {
+	data, _ := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": challenge,
+		"origin":    origin,
+	})
+	return data
+}
+
+func (a *authenticator) create(challenge, origin string) *webauthn.RegistrationResponse {
+	authData := a.authData(cfg.RPID, true)
+
+	// { "fmt": "none", "attStmt": {}, "authData": <bytes> }
+	attestation := []byte{0xa3, 0x63, 'f', 'm', 't', 0x64, 'n', 'o', 'n', 'e'}
+	attestation = append(attestation, 0x67, 'a', 't', 't', 'S', 't', 'm', 't', 0xa0)
+	attestation = append(attestation, 0x68, 'a', 'u', 't', 'h', 'D', 'a', 't', 'a', 0x59)
+	attestation = binary.BigEndian.AppendUint16(attestation, uint16(len(authData)))
+	attestation = append(attestation, authData...)
+
+	resp := &webauthn.RegistrationResponse{ID: a.credentialID(), Type: "public-key"}
+	resp.Response.ClientDataJSON = base64.RawURLEncoding.EncodeToString(clientDataJSON("webauthn.create", challenge, origin))
+	resp.Response.AttestationObject = base64.RawURLEncoding.EncodeToString(attestation)
+	return resp
+}
+
+func (a *authenticator) get(challenge, origin string) *webauthn.AssertionResponse {
+	a.signCount++
+	authData := a.authData(cfg.RPID, false)
+	clientData := clientDataJSON("webauthn.get", challenge, origin)
+	clientDataHash := sha256.Sum256(clientData)
+	hash := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, _ := ecdsa.SignASN1(rand.Reader, a.key, hash[:])
+
+	resp := &webauthn.AssertionResponse{ID: a.credentialID(), Type: "public-key"}
+	resp.Response.ClientDataJSON = base64.RawURLEncoding.EncodeToString(clientData)
+	resp.Response.AuthenticatorData = base64.RawURLEncoding.EncodeToString(authData)
+	resp.Response.Signature = base64.RawURLEncoding.EncodeToString(signature)
+	return resp
+}
+
+func TestNewChallenge(t *testing.T) {
+	RegisterT(t)
+
+	c1 := webauthn.NewChallenge()
+	c2 := webauthn.NewChallenge()
+	Expect(c1).HasLen(43)
+	Expect(c1).NotEquals(c2)
+}
+
+func TestNewCreationOptions(t *testing.T) {
+	RegisterT(t)
+
+	user := webauthn.UserEntity{ID: webauthn.EncodeUserHandle("1"), Name: "jon.snow@got.com", DisplayName: "Jon Snow"}
+	options := cfg.NewCreationOptions("abc", user, []string{"cred1"})
+	Expect(options.RP.ID).Equals("demo.test.fider.io")
+	Expect(options.User.ID).Equals("MQ")
+	Expect(options.PubKeyCredParams).HasLen(3)
+	Expect(options.ExcludeCredentials).HasLen(1)
+	Expect(options.ExcludeCredentials[0].ID).Equals("cred1")
+	Expect(options.Attestation).Equals("none")
+}
+
+func TestVerifyRegistration_Success(t *testing.T) {
+	RegisterT(t)
+
+	auth := newAuthenticator()
+	challenge := webauthn.NewChallenge()
+
+	credential, err := cfg.VerifyRegistration(challenge, auth.create(challenge, cfg.Origin))
+	Expect(err).IsNil()
+	Expect(credential.ID).Equals(auth.credentialID())
+	Expect(credential.PublicKey).Equals(auth.coseKey())
+	Expect(credential.SignCount).Equals(uint32(0))
+}
+
+func TestVerifyRegistration_WrongChallenge(t *testing.T) {
+	RegisterT(t)
+
+	auth := newAuthenticator()
+	credential, err := cfg.VerifyRegistration(webauthn.NewChallenge(), auth.create(webauthn.NewChallenge(), cfg.Origin))
+	Expect(err).IsNotNil()
+	Expect(credential).IsNil()
+}
+
+func TestVerifyRegistration_WrongOrigin(t *testing.T) {
+	RegisterT(t)
+
+	auth := newAuthenticator()
+	challenge := webauthn.NewChallenge()
+	credential, err := cfg.VerifyRegistration(challenge, auth.create(challenge, "https://evil.com"))
+	Expect(err).IsNotNil()
+	Expect(credential).IsNil()
+}
+
+func TestVerifyAssertion_Success(t *testing.T) {
+	RegisterT(t)
+
+	auth := newAuthenticator()
+	challenge := webauthn.NewChallenge()
+	credential, err := cfg.VerifyRegistration(challenge, auth.create(challenge, cfg.Origin))
+	Expect(err).IsNil()
+
+	challenge = webauthn.NewChallenge()
+	signCount, err := cfg.VerifyAssertion(challenge, auth.get(challenge, cfg.Origin), credential)
+	Expect(err).IsNil()
+	Expect(signCount).Equals(uint32(1))
+}
+
+func TestVerifyAssertion_InvalidSignature(t *testing.T) {
+	RegisterT(t)
+
+	auth := newAuthenticator()
+	challenge := webauthn.NewChallenge()
+	credential, _ := cfg.VerifyRegistration(challenge, auth.create(challenge, cfg.Origin))
+
+	other := newAuthenticator()
+	challenge = webauthn.NewChallenge()
+	_, err := cfg.VerifyAssertion(challenge, other.get(challenge, cfg.Origin), credential)
+	Expect(err).IsNotNil()
+}
+
+func TestVerifyAssertion_WrongCeremony(t *testing.T) {
+	RegisterT(t)
+
+	auth := newAuthenticator()
+	challenge := webauthn.NewChallenge()
+	credential, _ := cfg.VerifyRegistration(challenge, auth.create(challenge, cfg.Origin))
+
+	resp := auth.get(challenge, cfg.Origin)
+	resp.Response.ClientDataJSON = base64.RawURLEncoding.EncodeToString(clientDataJSON("webauthn.create", challenge, cfg.Origin))
+	_, err := cfg.VerifyAssertion(challenge, resp, credential)
+	Expect(err).IsNotNil()
+}
+
+func TestVerifyAssertion_ClonedAuthenticator(t *testing.T) {
+	RegisterT(t)
+
+	auth := newAuthenticator()
+	challenge := webauthn.NewChallenge()
+	credential, _ := cfg.VerifyRegistration(challenge, auth.create(challenge, cfg.Origin))
+	credential.SignCount = 10
+
+	challenge = webauthn.NewChallenge()
+	_, err := cfg.VerifyAssertion(challenge, auth.get(challenge, cfg.Origin), credential)
+	Expect(err).Equals(webauthn.ErrSignCountMismatch)
+}
@@ -0,0 +1,223 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/hkdf"
+)
+
+// recordSize is the single record size used by the aes128gcm content encoding
+const recordSize = 4096
+
+// MaxPayloadSize is the largest payload push services are required to accept
+// The 4096 bytes of a message include an 86 bytes header, a delimiter byte and the 16 bytes authentication tag
+const MaxPayloadSize = 4096 - 86 - 1 - 16
+
+// Keys are the public key and authentication secret generated by the browser when subscribing
+type Keys struct {
+	P256dh string
+	Auth   string
+}
+
+// Encrypt encrypts given payload for a subscription using the aes128gcm content encoding as of RFC 8291
+func Encrypt(keys Keys, payload []byte) ([]byte, error) {
+	if len(payload) > MaxPayloadSize {
+		return nil, errors.New("payload has %d bytes, limit is %d", len(payload), MaxPayloadSize)
+	}
+
+	uaPublicBytes, err := decodeBase64(keys.P256dh)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode p256dh key")
+	}
+	authSecret, err := decodeBase64(keys.Auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode auth secret")
+	}
+	if len(authSecret) != 16 {
+		return nil, errors.New("auth secret must have 16 bytes, got %d", len(authSecret))
+	}
+
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid p256dh key")
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate ephemeral key")
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, errors.Wrap(err, "failed to generate salt")
+	}
+
+	return encrypt(uaPublic, authSecret, asPrivate, salt, payload)
+}
+
+func encrypt(uaPublic *ecdh.PublicKey, authSecret []byte, asPrivate *ecdh.PrivateKey, salt, payload []byte) ([]byte, error) {
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compute shared secret")
+	}
+
+	asPublicBytes := asPrivate.PublicKey().Bytes()
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic.Bytes()...)
+	keyInfo = append(keyInfo, asPublicBytes...)
+
+	ikm, err := expand(hkdf.Extract(sha256.New, ecdhSecret, authSecret), keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	cek, err := expand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := expand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+
+	// 0x02 marks the last (and only) record, no padding is added
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	header := make([]byte, 0, 16+4+1+len(asPublicBytes))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublicBytes)))
+	header = append(header, asPublicBytes...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+func expand(prk, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, info), out); err != nil {
+		return nil, errors.Wrap(err, "failed to derive key")
+	}
+	return out, nil
+}
+
+// VAPIDAuthorization returns the Authorization header that identifies the application server as of RFC 8292
+func VAPIDAuthorization(endpoint, subject, publicKey, privateKey string, expiresAt time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", errors.New("invalid push endpoint '%s'", endpoint)
+	}
+
+	key, pub, err := parseKeys(publicKey, privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": fmt.Sprintf("%s://%s", u.Scheme, u.Host),
+		"exp": expiresAt.Unix(),
+		"sub": subject,
+	})
+	signed, err := token.SignedString(key)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign VAPID token")
+	}
+
+	return fmt.Sprintf("vapid t=%s, k=%s", signed, base64.RawURLEncoding.EncodeToString(pub)), nil
+}
+
+func parseKeys(publicKey, privateKey string) (*ecdsa.PrivateKey, []byte, error) {
+	d, err := decodeBase64(privateKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to decode VAPID private key")
+	}
+	priv, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid VAPID private key")
+	}
+
+	pub, err := decodeBase64(publicKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to decode VAPID public key")
+	}
+	derived := priv.PublicKey().Bytes()
+	if string(pub) != string(derived) {
+		return nil, nil, errors.New("VAPID public key doesn't match the private key")
+	}
+
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(derived[1:33]),
+			Y:     new(big.Int).SetBytes(derived[33:65]),
+		},
+		D: new(big.Int).SetBytes(d),
+	}, pub, nil
+}
+
+// GenerateVAPIDKeys returns a new base64url encoded pair of VAPID keys
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to generate VAPID keys")
+	}
+	encoding := base64.RawURLEncoding
+	return encoding.EncodeToString(priv.PublicKey().Bytes()), encoding.EncodeToString(priv.Bytes()), nil
+}
+
+// decodeBase64 accepts both url and standard alphabets, with or without padding
+// Browsers return url-safe keys, but some libraries that generate VAPID keys don't
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// pushServiceHosts are the push services used by browsers, a subdomain of any of them is also accepted
+var pushServiceHosts = []string{
+	"fcm.googleapis.com",
+	"android.googleapis.com",
+	"push.services.mozilla.com",
+	"notify.windows.com",
+	"push.apple.com",
+}
+
+// IsPushServiceEndpoint returns true if endpoint is an https URL of a known push service
+// Endpoints are provided by the browser, so anything else could be used to make the server call internal services
+func IsPushServiceEndpoint(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.User != nil || u.Port() != "" {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, known := range pushServiceHosts {
+		if host == known || strings.HasSuffix(host, "."+known) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func b64(s string) []byte {
+	b, err := decodeBase64(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Example from RFC 8291, Appendix A
+func TestEncrypt_RFC8291(t *testing.T) {
+	RegisterT(t)
+
+	uaPublic, err := ecdh.P256().NewPublicKey(b64("BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"))
+	Expect(err).IsNil()
+	asPrivate, err := ecdh.P256().NewPrivateKey(b64("yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	Expect(err).IsNil()
+
+	message, err := encrypt(
+		uaPublic,
+		b64("BTBZMqHH6r4Tts7J_aSIgg"),
+		asPrivate,
+		b64("DGv6ra1nlYgDCS1FRnbzlw"),
+		[]byte("When I grow up, I want to be a watermelon"),
+	)
+	Expect(err).IsNil()
+	Expect(base64.RawURLEncoding.EncodeToString(message)).Equals("DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN")
+}
+
+func TestEncrypt_InvalidKeys(t *testing.T) {
+	RegisterT(t)
+
+	keys := Keys{
+		P256dh: "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+		Auth:   "BTBZMqHH6r4Tts7J_aSIgg",
+	}
+	message, err := Encrypt(keys, []byte("Hello"))
+	Expect(err).IsNil()
+	Expect(len(message)).Equals(86 + len("Hello") + 1 + 16)
+
+	_, err = Encrypt(Keys{P256dh: "invalid", Auth: keys.Auth}, []byte("Hello"))
+	Expect(err).IsNotNil()
+
+	_, err = Encrypt(Keys{P256dh: keys.P256dh, Auth: "c2hvcnQ"}, []byte("Hello"))
+	Expect(err).IsNotNil()
+
+	_, err = Encrypt(keys, []byte(strings.Repeat("a", MaxPayloadSize+1)))
+	Expect(err).IsNotNil()
+}
+
+func TestVAPIDAuthorization(t *testing.T) {
+	RegisterT(t)
+
+	publicKey, privateKey, err := GenerateVAPIDKeys()
+	Expect(err).IsNil()
+
+	expiresAt := time.Now().Add(12 * time.Hour)
+	header, err := VAPIDAuthorization("https://push.example.com/send/abc123", "mailto:noreply@random.org", publicKey, privateKey, expiresAt)
+	Expect(err).IsNil()
+	Expect(strings.HasPrefix(header, "vapid t=")).IsTrue()
+	Expect(strings.HasSuffix(header, ", k="+publicKey)).IsTrue()
+
+	signed := strings.TrimSuffix(strings.TrimPrefix(header, "vapid t="), ", k="+publicKey)
+	key, _, err := parseKeys(publicKey, privateKey)
+	Expect(err).IsNil()
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(signed, claims, func(token *jwt.Token) (any, error) {
+		return &key.PublicKey, nil
+	})
+	Expect(err).IsNil()
+	Expect(claims["aud"]).Equals("https://push.example.com")
+	Expect(claims["sub"]).Equals("mailto:noreply@random.org")
+	Expect(int64(claims["exp"].(float64))).Equals(expiresAt.Unix())
+}
+
+func TestVAPIDAuthorization_MismatchedKeys(t *testing.T) {
+	RegisterT(t)
+
+	publicKey, _, _ := GenerateVAPIDKeys()
+	_, privateKey, _ := GenerateVAPIDKeys()
+
+	_, err := VAPIDAuthorization("https://push.example.com/send/abc123", "mailto:noreply@random.org", publicKey, privateKey, time.Now())
+	Expect(err).IsNotNil()
+
+	_, err = VAPIDAuthorization("not a url", "mailto:noreply@random.org", publicKey, privateKey, time.Now())
+	Expect(err).IsNotNil()
+}
+
+func TestIsPushServiceEndpoint(t *testing.T) {
+	RegisterT(t)
+
+	for _, endpoint := range []string{
+		"https://fcm.googleapis.com/fcm/send/abc",
+		"https://updates.push.services.mozilla.com/wpush/v2/abc",
+		"https://wns2-by3p.notify.windows.com/w/?token=abc",
+		"https://web.push.apple.com/abc",
+	} {
+		Expect(IsPushServiceEndpoint(endpoint)).IsTrue()
+	}
+
+	for _, endpoint := range []string{
+		"http://fcm.googleapis.com/fcm/send/abc",
+		"https://fcm.googleapis.com:8443/fcm/send/abc",
+		"https://user@fcm.googleapis.com/fcm/send/abc",
+		"https://fcm.googleapis.com.example.com/abc",
+		"https://evilpush.apple.com.internal/abc",
+		"https://localhost/abc",
+		"https://169.254.169.254/latest/meta-data",
+		"https://push.example.com/abc",
+	} {
+		Expect(IsPushServiceEndpoint(endpoint)).IsFalse()
+	}
+}
@@ -1,7 +1,11 @@
 package awsses
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -51,11 +55,12 @@ func (s Service) Init() {
 	}
 
 	sesClient = ses.New(awsSession)
-	bus.AddListener(sendMail)
+	bus.AddHandler(deliverMail)
 	bus.AddHandler(fetchRecentSupressions)
 }
 
-func sendMail(ctx context.Context, c *cmd.SendMail) {
+func deliverMail(ctx context.Context, d *cmd.DeliverMail) error {
+	c := d.Mail
 	if c.Props == nil {
 		c.Props = dto.Props{}
 	}
@@ -64,9 +69,11 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 		c.From.Address = email.NoReply
 	}
 
+	template := email.CustomTemplate(ctx, c)
 	for _, to := range c.To {
 		if to.Address == "" {
-			return
+			d.Suppressed = append(d.Suppressed, to.Address)
+			continue
 		}
 
 		if !email.CanSendTo(to.Address) {
@@ -74,7 +81,8 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 				"Name":    to.Name,
 				"Address": to.Address,
 			})
-			return
+			d.Suppressed = append(d.Suppressed, to.Address)
+			continue
 		}
 
 		log.Debugf(ctx, "Sending email to @{Address} with template @{TemplateName} and params @{Props}.", dto.Props{
@@ -83,7 +91,12 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 			"Props":        to.Props,
 		})
 
-		message := email.RenderMessage(ctx, c.TemplateName, c.From.Address, c.Props.Merge(to.Props))
+		replyTo := c.From.Address
+		if to.ReplyTo != "" {
+			replyTo = to.ReplyTo
+		}
+
+		message := email.RenderMessage(ctx, c.TemplateName, template, replyTo, c.Props.Merge(to.Props))
 		tags := []*ses.MessageTag{
 			{Name: aws.String("template"), Value: aws.String(c.TemplateName)},
 		}
@@ -117,15 +130,32 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 			EmailTags: tags,
 		}
 
+		if replyTo != email.NoReply {
+			input.ReplyToAddresses = []*string{aws.String(replyTo)}
+		}
+
+		// Custom headers can only be set on raw messages
+		if to.Unsubscribe != "" {
+			raw, err := rawMessage(c.From.String(), to.String(), input.ReplyToAddresses, to.Unsubscribe, message)
+			if err != nil {
+				return err
+			}
+			input.FromEmailAddress = nil
+			input.ReplyToAddresses = nil
+			input.Content = &ses.EmailContent{Raw: &ses.RawMessage{Data: raw}}
+		}
+
 		result, err := sesClient.SendEmailWithContext(ctx, input)
 		if err != nil {
-			panic(errors.Wrap(err, "failed to send email with template %s", c.TemplateName))
+			return errors.Wrap(err, "failed to send email with template %s", c.TemplateName)
 		}
+		d.Sent = append(d.Sent, to.Address)
 
 		log.Debugf(ctx, "Email sent with ID @{MessageId}.", dto.Props{
 			"MessageId": *result.MessageId,
 		})
 	}
+	return nil
 }
 
 func fetchRecentSupressions(ctx context.Context, q *query.FetchRecentSupressions) error {
@@ -143,3 +173,33 @@ func fetchRecentSupressions(ctx context.Context, q *query.FetchRecentSupressions
 	}
 	return nil
 }
+
+// rawMessage builds a MIME message of given email with one-click unsubscribe headers (RFC 8058)
+func rawMessage(from, to string, replyTo []*string, unsubscribe string, message *email.Message) ([]byte, error) {
+	var b bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+	}
+
+	header("From", from)
+	header("To", to)
+	if len(replyTo) > 0 {
+		header("Reply-To", *replyTo[0])
+	}
+	header("Subject", mime.QEncoding.Encode("UTF-8", message.Subject))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/html; charset=\"UTF-8\"")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	header("List-Unsubscribe", "<"+unsubscribe+">")
+	header("List-Unsubscribe-Post", email.ListUnsubscribePost)
+	b.WriteString("\r\n")
+
+	w := quotedprintable.NewWriter(&b)
+	if _, err := w.Write([]byte(message.Body)); err != nil {
+		return nil, errors.Wrap(err, "failed to encode email body")
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to encode email body")
+	}
+	return b.Bytes(), nil
+}
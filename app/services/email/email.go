@@ -10,6 +10,10 @@ import (
 // NoReply is the default 'from' address
 var NoReply = env.Config.Email.NoReply
 
+// ListUnsubscribePost is the value of the List-Unsubscribe-Post header
+// It tells mail clients that the List-Unsubscribe URL accepts a POST request to unsubscribe with a single click (RFC 8058)
+const ListUnsubscribePost = "List-Unsubscribe=One-Click"
+
 var allowlist = env.Config.Email.Allowlist
 var allowlistRegex = regexp.MustCompile(allowlist)
 var blocklist = env.Config.Email.Blocklist
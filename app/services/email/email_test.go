@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
 	"github.com/getfider/fider/app/services/email"
 
 	. "github.com/getfider/fider/app/pkg/assert"
@@ -13,7 +14,7 @@ import (
 func TestRenderMessage(t *testing.T) {
 	RegisterT(t)
 
-	message := email.RenderMessage(context.Background(), "echo_test", email.NoReply, dto.Props{
+	message := email.RenderMessage(context.Background(), "echo_test", nil, email.NoReply, dto.Props{
 		"name": "Fider",
 	})
 	Expect(message.Subject).Equals("Message to: Fider")
@@ -54,6 +55,40 @@ Hello World Fider!
 </html>`)
 }
 
+func TestRenderMessage_CustomTemplate(t *testing.T) {
+	RegisterT(t)
+
+	custom := &entity.EmailTemplate{
+		Kind:    "new_post",
+		Subject: "New idea on {{ .siteName }}: {{ .title }}",
+		Body:    "<tr><td>{{ .content }}</td></tr>",
+	}
+
+	message := email.RenderMessage(context.Background(), "new_post", custom, email.NoReply, dto.Props{
+		"siteName": "Fider",
+		"title":    "Add dark mode",
+		"content":  "<b>Please</b>",
+	})
+	Expect(message.Subject).Equals("New idea on Fider: Add dark mode")
+	Expect(message.Body).ContainsSubstring("<tr><td>&lt;b&gt;Please&lt;/b&gt;</td></tr>")
+}
+
+func TestRenderMessage_InvalidCustomTemplate(t *testing.T) {
+	RegisterT(t)
+
+	custom := &entity.EmailTemplate{
+		Kind:    "echo_test",
+		Subject: "Hello {{ .name",
+		Body:    "<tr><td>{{ .name }}</td></tr>",
+	}
+
+	message := email.RenderMessage(context.Background(), "echo_test", custom, email.NoReply, dto.Props{
+		"name": "Fider",
+	})
+	Expect(message.Subject).Equals("Message to: Fider")
+	Expect(message.Body).ContainsSubstring("Hello World Fider!")
+}
+
 func TestCanSendTo(t *testing.T) {
 	RegisterT(t)
 
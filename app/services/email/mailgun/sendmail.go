@@ -19,9 +19,10 @@ import (
 	"github.com/getfider/fider/app/services/email"
 )
 
-func sendMail(ctx context.Context, c *cmd.SendMail) {
+func deliverMail(ctx context.Context, d *cmd.DeliverMail) error {
+	c := d.Mail
 	if len(c.To) == 0 {
-		return
+		return nil
 	}
 
 	if c.Props == nil {
@@ -34,6 +35,20 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 
 	isBatch := len(c.To) > 1
 
+	replyTo := c.From.Address
+	if c.To[0].ReplyTo != "" {
+		replyTo = c.To[0].ReplyTo
+		if isBatch {
+			replyTo = "%recipient.replyTo%"
+		}
+	}
+
+	unsubscribe := c.To[0].Unsubscribe
+	if unsubscribe != "" && isBatch {
+		unsubscribe = "%recipient.unsubscribeURL%"
+	}
+
+	template := email.CustomTemplate(ctx, c)
 	var message *email.Message
 	if isBatch {
 		// Replace recipient specific Go templates variables with Mailgun template variables
@@ -42,16 +57,20 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 				c.Props[k] = fmt.Sprintf("%%recipient.%s%%", k)
 			}
 		}
-		message = email.RenderMessage(ctx, c.TemplateName, c.From.Address, c.Props)
+		message = email.RenderMessage(ctx, c.TemplateName, template, replyTo, c.Props)
 	} else {
-		message = email.RenderMessage(ctx, c.TemplateName, c.From.Address, c.Props.Merge(c.To[0].Props))
+		message = email.RenderMessage(ctx, c.TemplateName, template, replyTo, c.Props.Merge(c.To[0].Props))
 	}
 
 	form := url.Values{}
 	form.Add("from", c.From.String())
-	form.Add("h:Reply-To", c.From.Address)
+	form.Add("h:Reply-To", replyTo)
 	form.Add("subject", message.Subject)
 	form.Add("html", message.Body)
+	if unsubscribe != "" {
+		form.Add("h:List-Unsubscribe", "<"+unsubscribe+">")
+		form.Add("h:List-Unsubscribe-Post", email.ListUnsubscribePost)
+	}
 	form.Add("o:tag", fmt.Sprintf("template:%s", c.TemplateName))
 
 	tenant, ok := ctx.Value(app.TenantCtxKey).(*entity.Tenant)
@@ -61,29 +80,38 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 
 	// Set Mailgun's var based on each recipient's variables
 	recipientVariables := make(map[string]dto.Props)
+	recipients := make([]string, 0, len(c.To))
 	for _, r := range c.To {
 		if r.Address != "" {
 			if email.CanSendTo(r.Address) {
 				form.Add("to", r.String())
 				recipientVariables[r.Address] = r.Props
-			} else {
-				log.Warnf(ctx, "Skipping email to '@{Name} <@{Address}>'.", dto.Props{
-					"Name":    r.Name,
-					"Address": r.Address,
-				})
+				if r.ReplyTo != "" {
+					recipientVariables[r.Address] = recipientVariables[r.Address].Merge(dto.Props{"replyTo": r.ReplyTo})
+				}
+				if r.Unsubscribe != "" {
+					recipientVariables[r.Address] = recipientVariables[r.Address].Merge(dto.Props{"unsubscribeURL": r.Unsubscribe})
+				}
+				recipients = append(recipients, r.Address)
+				continue
 			}
+			log.Warnf(ctx, "Skipping email to '@{Name} <@{Address}>'.", dto.Props{
+				"Name":    r.Name,
+				"Address": r.Address,
+			})
 		}
+		d.Suppressed = append(d.Suppressed, r.Address)
 	}
 
 	// If we skipped all recipients, just return
 	if len(recipientVariables) == 0 {
-		return
+		return nil
 	}
 
 	if isBatch {
 		json, err := json.Marshal(recipientVariables)
 		if err != nil {
-			panic(errors.Wrap(err, "failed to marshal recipient variables"))
+			return errors.Wrap(err, "failed to marshal recipient variables")
 		}
 
 		form.Add("recipient-variables", string(json))
@@ -115,9 +143,14 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 	}
 	err := bus.Dispatch(ctx, req)
 	if err != nil {
-		panic(errors.Wrap(err, "failed to send email with template %s", c.TemplateName))
+		return errors.Wrap(err, "failed to send email with template %s", c.TemplateName)
+	}
+	if req.ResponseStatusCode >= 300 {
+		return errors.New("failed to send email with template %s: Mailgun responded with status code %d", c.TemplateName, req.ResponseStatusCode)
 	}
+	d.Sent = recipients
 	log.Debugf(ctx, "Email sent with response code @{StatusCode}.", dto.Props{
 		"StatusCode": req.ResponseStatusCode,
 	})
+	return nil
 }
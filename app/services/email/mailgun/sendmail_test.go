@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/getfider/fider/app"
@@ -29,12 +30,19 @@ func reset() {
 	bus.Init(mailgun.Service{}, httpclientmock.Service{})
 }
 
+func deliver(mail *cmd.SendMail) *cmd.DeliverMail {
+	c := &cmd.DeliverMail{Mail: mail}
+	err := bus.Dispatch(ctx, c)
+	Expect(err).IsNil()
+	return c
+}
+
 func TestSend_Success(t *testing.T) {
 	RegisterT(t)
 	env.Config.HostMode = "multi"
 	reset()
 
-	bus.Publish(ctx, &cmd.SendMail{
+	deliver(&cmd.SendMail{
 		From: dto.Recipient{Name: "Fider Test"},
 		To: []dto.Recipient{
 			{
@@ -105,7 +113,7 @@ func TestSend_SkipEmptyAddress(t *testing.T) {
 	RegisterT(t)
 	reset()
 
-	bus.Publish(ctx, &cmd.SendMail{
+	deliver(&cmd.SendMail{
 		From: dto.Recipient{Name: "Fider Test"},
 		To: []dto.Recipient{
 			{
@@ -127,7 +135,7 @@ func TestSend_SkipUnlistedAddress(t *testing.T) {
 	reset()
 	email.SetAllowlist("^.*@gmail.com$")
 
-	bus.Publish(ctx, &cmd.SendMail{
+	result := deliver(&cmd.SendMail{
 		From: dto.Recipient{Name: "Fider Test"},
 		To: []dto.Recipient{
 			{
@@ -142,6 +150,7 @@ func TestSend_SkipUnlistedAddress(t *testing.T) {
 	})
 
 	Expect(httpclientmock.RequestsHistory).HasLen(0)
+	Expect(result.Suppressed).Equals([]string{"jon.snow@got.com"})
 }
 
 func TestBatch_Success(t *testing.T) {
@@ -149,7 +158,7 @@ func TestBatch_Success(t *testing.T) {
 	reset()
 	email.SetAllowlist("")
 
-	bus.Publish(ctx, &cmd.SendMail{
+	deliver(&cmd.SendMail{
 		From: dto.Recipient{Name: "Fider Test"},
 		To: []dto.Recipient{
 			{
@@ -227,6 +236,76 @@ Hello World %recipient.name%!
 </html>`)
 }
 
+func TestBatch_ReplyTo(t *testing.T) {
+	RegisterT(t)
+	reset()
+	email.SetAllowlist("")
+
+	deliver(&cmd.SendMail{
+		From: dto.Recipient{Name: "Fider Test"},
+		To: []dto.Recipient{
+			{
+				Name:    "Jon Sow",
+				Address: "jon.snow@got.com",
+				Props:   dto.Props{"name": "Jon"},
+				ReplyTo: "reply+1.1.1.abc@inbound.got.com",
+			},
+			{
+				Name:    "Arya Stark",
+				Address: "arya.start@got.com",
+				Props:   dto.Props{"name": "Arya"},
+				ReplyTo: "reply+1.2.1.def@inbound.got.com",
+			},
+		},
+		TemplateName: "echo_test",
+	})
+
+	Expect(httpclientmock.RequestsHistory).HasLen(1)
+
+	bytes, err := io.ReadAll(httpclientmock.RequestsHistory[0].Body)
+	Expect(err).IsNil()
+	values, err := url.ParseQuery(string(bytes))
+	Expect(err).IsNil()
+	Expect(values.Get("h:Reply-To")).Equals("%recipient.replyTo%")
+	Expect(values.Get("recipient-variables")).Equals("{\"arya.start@got.com\":{\"name\":\"Arya\",\"replyTo\":\"reply+1.2.1.def@inbound.got.com\"},\"jon.snow@got.com\":{\"name\":\"Jon\",\"replyTo\":\"reply+1.1.1.abc@inbound.got.com\"}}")
+	Expect(strings.Contains(values.Get("html"), "notification-only address")).IsFalse()
+}
+
+func TestBatch_ListUnsubscribe(t *testing.T) {
+	RegisterT(t)
+	reset()
+	email.SetAllowlist("")
+
+	deliver(&cmd.SendMail{
+		From: dto.Recipient{Name: "Fider Test"},
+		To: []dto.Recipient{
+			{
+				Name:        "Jon Sow",
+				Address:     "jon.snow@got.com",
+				Props:       dto.Props{"name": "Jon"},
+				Unsubscribe: "http://domain.com/unsubscribe/abc",
+			},
+			{
+				Name:        "Arya Stark",
+				Address:     "arya.start@got.com",
+				Props:       dto.Props{"name": "Arya"},
+				Unsubscribe: "http://domain.com/unsubscribe/def",
+			},
+		},
+		TemplateName: "echo_test",
+	})
+
+	Expect(httpclientmock.RequestsHistory).HasLen(1)
+
+	bytes, err := io.ReadAll(httpclientmock.RequestsHistory[0].Body)
+	Expect(err).IsNil()
+	values, err := url.ParseQuery(string(bytes))
+	Expect(err).IsNil()
+	Expect(values.Get("h:List-Unsubscribe")).Equals("<%recipient.unsubscribeURL%>")
+	Expect(values.Get("h:List-Unsubscribe-Post")).Equals("List-Unsubscribe=One-Click")
+	Expect(values.Get("recipient-variables")).Equals("{\"arya.start@got.com\":{\"name\":\"Arya\",\"unsubscribeURL\":\"http://domain.com/unsubscribe/def\"},\"jon.snow@got.com\":{\"name\":\"Jon\",\"unsubscribeURL\":\"http://domain.com/unsubscribe/abc\"}}")
+}
+
 func TestGetBaseURL(t *testing.T) {
 	RegisterT(t)
 	reset()
@@ -247,29 +326,29 @@ func TestGetBaseURL(t *testing.T) {
 
 	// Fall back to US if there is nothing set
 	env.Config.Email.Mailgun.Region = ""
-	bus.Publish(ctx, sendMail)
+	deliver(sendMail)
 	Expect(httpclientmock.RequestsHistory[0].URL.String()).Equals("https://api.mailgun.net/v3/mydomain.com/messages")
 
 	// Return the EU domain for EU, ignore the case
 	env.Config.Email.Mailgun.Region = "EU"
-	bus.Publish(ctx, sendMail)
+	deliver(sendMail)
 	Expect(httpclientmock.RequestsHistory[1].URL.String()).Equals("https://api.eu.mailgun.net/v3/mydomain.com/messages")
 
 	env.Config.Email.Mailgun.Region = "eu"
-	bus.Publish(ctx, sendMail)
+	deliver(sendMail)
 	Expect(httpclientmock.RequestsHistory[2].URL.String()).Equals("https://api.eu.mailgun.net/v3/mydomain.com/messages")
 
 	// Return the US domain for US, ignore the case
 	env.Config.Email.Mailgun.Region = "US"
-	bus.Publish(ctx, sendMail)
+	deliver(sendMail)
 	Expect(httpclientmock.RequestsHistory[3].URL.String()).Equals("https://api.mailgun.net/v3/mydomain.com/messages")
 	env.Config.Email.Mailgun.Region = "us"
-	bus.Publish(ctx, sendMail)
+	deliver(sendMail)
 	Expect(httpclientmock.RequestsHistory[4].URL.String()).Equals("https://api.mailgun.net/v3/mydomain.com/messages")
 
 	// Return the US domain if the region is invalid
 	env.Config.Email.Mailgun.Region = "Mars"
-	bus.Publish(ctx, sendMail)
+	deliver(sendMail)
 	Expect(httpclientmock.RequestsHistory[5].URL.String()).Equals("https://api.mailgun.net/v3/mydomain.com/messages")
 
 }
@@ -38,7 +38,7 @@ func (s Service) Enabled() bool {
 }
 
 func (s Service) Init() {
-	bus.AddListener(sendMail)
+	bus.AddHandler(deliverMail)
 	bus.AddHandler(fetchRecentSupressions)
 }
 
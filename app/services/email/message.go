@@ -5,7 +5,15 @@ import (
 	"context"
 	"strings"
 
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/emailtemplate"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
 	"github.com/getfider/fider/app/pkg/tpl"
 )
 
@@ -15,19 +23,54 @@ type Message struct {
 	Body    string
 }
 
+// CustomTemplate returns the template that replaces the default one of given email, if any
+func CustomTemplate(ctx context.Context, c *cmd.SendMail) *entity.EmailTemplate {
+	if c.Template != nil {
+		return c.Template
+	}
+
+	if _, hasTenant := ctx.Value(app.TenantCtxKey).(*entity.Tenant); !hasTenant || !emailtemplate.IsCustomizable(c.TemplateName) {
+		return nil
+	}
+
+	q := &query.GetEmailTemplate{Kind: c.TemplateName}
+	if err := bus.Dispatch(ctx, q); err != nil {
+		if errors.Cause(err) != app.ErrNotFound {
+			log.Error(ctx, err)
+		}
+		return nil
+	}
+	return q.Result
+}
+
 // RenderMessage returns the HTML of an email based on template and params
-func RenderMessage(ctx context.Context, templateName string, fromAddress string, params dto.Props) *Message {
+// replyTo is the address that receives replies to this email
+// When custom is given it's used instead of the default template, unless it fails to render
+func RenderMessage(ctx context.Context, templateName string, custom *entity.EmailTemplate, replyTo string, params dto.Props) *Message {
 	noreply := false
-	if fromAddress == NoReply {
+	if replyTo == NoReply {
 		noreply = true
 	}
 
-	tmpl := tpl.GetTemplate("/views/email/base_email.html", "/views/email/"+templateName+".html")
-	var bf bytes.Buffer
-	if err := tpl.Render(ctx, tmpl, &bf, params.Merge(dto.Props{
+	params = params.Merge(dto.Props{
 		"logo":    params["logo"],
 		"noreply": noreply,
-	})); err != nil {
+	})
+
+	if custom != nil {
+		message, err := renderCustomMessage(ctx, custom, params)
+		if err == nil {
+			return message
+		}
+		log.Warnf(ctx, "Custom email template @{TemplateName} failed to render, using default instead: @{Error}", dto.Props{
+			"TemplateName": templateName,
+			"Error":        err.Error(),
+		})
+	}
+
+	tmpl := tpl.GetTemplate("/views/email/base_email.html", "/views/email/"+templateName+".html")
+	var bf bytes.Buffer
+	if err := tpl.Render(ctx, tmpl, &bf, params); err != nil {
 		panic(err)
 	}
 
@@ -39,3 +82,20 @@ func RenderMessage(ctx context.Context, templateName string, fromAddress string,
 		Body:    body,
 	}
 }
+
+func renderCustomMessage(ctx context.Context, custom *entity.EmailTemplate, params dto.Props) (*Message, error) {
+	tmpl, err := emailtemplate.Parse(custom)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, body, err := emailtemplate.Render(ctx, tmpl, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		Subject: subject,
+		Body:    body,
+	}, nil
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/inbound"
 	"github.com/getfider/fider/app/pkg/log"
 	"github.com/getfider/fider/app/pkg/web"
 	"github.com/getfider/fider/app/services/email"
@@ -42,7 +43,7 @@ func (s Service) Enabled() bool {
 }
 
 func (s Service) Init() {
-	bus.AddListener(sendMail)
+	bus.AddHandler(deliverMail)
 	bus.AddHandler(fetchRecentSupressions)
 }
 
@@ -51,7 +52,8 @@ func fetchRecentSupressions(ctx context.Context, c *query.FetchRecentSupressions
 	return nil
 }
 
-func sendMail(ctx context.Context, c *cmd.SendMail) {
+func deliverMail(ctx context.Context, d *cmd.DeliverMail) error {
+	c := d.Mail
 	if c.Props == nil {
 		c.Props = dto.Props{}
 	}
@@ -60,9 +62,11 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 		c.From.Address = email.NoReply
 	}
 
+	template := email.CustomTemplate(ctx, c)
 	for _, to := range c.To {
 		if to.Address == "" {
-			return
+			d.Suppressed = append(d.Suppressed, to.Address)
+			continue
 		}
 
 		u, err := url.Parse(web.BaseURL(ctx))
@@ -76,7 +80,8 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 				"Name":    to.Name,
 				"Address": to.Address,
 			})
-			return
+			d.Suppressed = append(d.Suppressed, to.Address)
+			continue
 		}
 
 		log.Debugf(ctx, "Sending email to @{Address} with template @{TemplateName} and params @{Props}.", dto.Props{
@@ -85,27 +90,45 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 			"Props":        to.Props,
 		})
 
-		message := email.RenderMessage(ctx, c.TemplateName, c.From.Address, c.Props.Merge(to.Props))
+		replyTo := c.From.Address
+		if to.ReplyTo != "" {
+			replyTo = to.ReplyTo
+		}
+
+		message := email.RenderMessage(ctx, c.TemplateName, template, replyTo, c.Props.Merge(to.Props))
 		b := builder{}
 		b.Set("From", c.From.String())
-		b.Set("Reply-To", c.From.Address)
+		b.Set("Reply-To", replyTo)
 		b.Set("To", to.String())
 		b.Set("Subject", message.Subject)
 		b.Set("MIME-version", "1.0")
 		b.Set("Content-Type", "text/html; charset=\"UTF-8\"")
 		b.Set("Date", time.Now().Format(time.RFC1123Z))
 		b.Set("Message-ID", generateMessageID(localname))
+		if to.Unsubscribe != "" {
+			b.Set("List-Unsubscribe", "<"+to.Unsubscribe+">")
+			b.Set("List-Unsubscribe-Post", email.ListUnsubscribePost)
+		}
 		b.Body(message.Body)
 
 		smtpConfig := env.Config.Email.SMTP
 		servername := fmt.Sprintf("%s:%s", smtpConfig.Host, smtpConfig.Port)
 		auth := authenticate(smtpConfig.Username, smtpConfig.Password, smtpConfig.Host)
-		err = Send(localname, servername, smtpConfig.EnableStartTLS, auth, email.NoReply, []string{to.Address}, b.Bytes())
+
+		// Bounce messages are sent to the envelope sender, which is a bounce address when inbound email is enabled
+		envelopeFrom := inbound.BounceAddress(to.Address)
+		if envelopeFrom == "" {
+			envelopeFrom = email.NoReply
+		}
+
+		err = Send(localname, servername, smtpConfig.EnableStartTLS, auth, envelopeFrom, []string{to.Address}, b.Bytes())
 		if err != nil {
-			panic(errors.Wrap(err, "failed to send email with template %s", c.TemplateName))
+			return errors.Wrap(err, "failed to send email with template %s", c.TemplateName)
 		}
+		d.Sent = append(d.Sent, to.Address)
 		log.Debug(ctx, "Email sent.")
 	}
+	return nil
 }
 
 var Send = func(localName, serverAddress string, enableStartTLS bool, a gosmtp.Auth, from string, to []string, msg []byte) error {
@@ -4,6 +4,7 @@ import (
 	"context"
 	gosmtp "net/smtp"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/getfider/fider/app"
@@ -11,8 +12,12 @@ import (
 	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/inbound"
 	"github.com/getfider/fider/app/services/email"
 	"github.com/getfider/fider/app/services/email/smtp"
 )
@@ -43,11 +48,18 @@ func reset() {
 	bus.Init(smtp.Service{})
 }
 
+func deliver(mail *cmd.SendMail) *cmd.DeliverMail {
+	c := &cmd.DeliverMail{Mail: mail}
+	err := bus.Dispatch(ctx, c)
+	Expect(err).IsNil()
+	return c
+}
+
 func TestSend_Success(t *testing.T) {
 	RegisterT(t)
 	reset()
 
-	bus.Publish(ctx, &cmd.SendMail{
+	deliver(&cmd.SendMail{
 		From: dto.Recipient{Name: "Fider Test"},
 		To: []dto.Recipient{
 			{
@@ -73,11 +85,55 @@ func TestSend_Success(t *testing.T) {
 	var validID = regexp.MustCompile(`.*Message-ID: <[a-z0-9\-].*\.[0-9].*@.*>.*`)
 	Expect(validID.MatchString(string(requests[0].body))).IsTrue()
 }
+func TestSend_ReplyTo(t *testing.T) {
+	RegisterT(t)
+	reset()
+
+	deliver(&cmd.SendMail{
+		From: dto.Recipient{Name: "Fider Test"},
+		To: []dto.Recipient{
+			{
+				Name:    "Jon Sow",
+				Address: "jon.snow@got.com",
+				ReplyTo: "reply+1.1.1.abc@inbound.got.com",
+			},
+		},
+		TemplateName: "echo_test",
+		Props: dto.Props{
+			"name": "Hello",
+		},
+	})
+
+	Expect(requests).HasLen(1)
+	Expect(string(requests[0].body)).ContainsSubstring("Reply-To: reply+1.1.1.abc@inbound.got.com\r\n")
+	Expect(strings.Contains(string(requests[0].body), "notification-only address")).IsFalse()
+}
+
+func TestSend_BounceAddress(t *testing.T) {
+	RegisterT(t)
+	reset()
+	env.Config.Email.Inbound.Address = "reply@inbound.got.com"
+	defer func() {
+		env.Config.Email.Inbound.Address = ""
+	}()
+
+	deliver(&cmd.SendMail{
+		From:         dto.Recipient{Name: "Fider Test"},
+		To:           []dto.Recipient{{Name: "Jon Sow", Address: "jon.snow@got.com"}},
+		TemplateName: "echo_test",
+		Props:        dto.Props{"name": "Hello"},
+	})
+
+	Expect(requests).HasLen(1)
+	Expect(requests[0].from).Equals(inbound.BounceAddress("jon.snow@got.com"))
+	Expect(string(requests[0].body)).ContainsSubstring("From: \"Fider Test\" <noreply@random.org>\r\n")
+}
+
 func TestSend_SkipEmptyAddress(t *testing.T) {
 	RegisterT(t)
 	reset()
 
-	bus.Publish(ctx, &cmd.SendMail{
+	deliver(&cmd.SendMail{
 		From: dto.Recipient{Name: "Fider Test"},
 		To: []dto.Recipient{
 			{
@@ -99,7 +155,7 @@ func TestSend_SkipUnlistedAddress(t *testing.T) {
 	reset()
 	email.SetAllowlist("^.*@gmail.com$")
 
-	bus.Publish(ctx, &cmd.SendMail{
+	result := deliver(&cmd.SendMail{
 		From: dto.Recipient{Name: "Fider Test"},
 		To: []dto.Recipient{
 			{
@@ -114,6 +170,8 @@ func TestSend_SkipUnlistedAddress(t *testing.T) {
 	})
 
 	Expect(requests).HasLen(0)
+	Expect(result.Sent).HasLen(0)
+	Expect(result.Suppressed).Equals([]string{"jon.snow@got.com"})
 }
 
 func TestBatch_Success(t *testing.T) {
@@ -121,7 +179,7 @@ func TestBatch_Success(t *testing.T) {
 	reset()
 	email.SetAllowlist("")
 
-	bus.Publish(ctx, &cmd.SendMail{
+	deliver(&cmd.SendMail{
 		From: dto.Recipient{Name: "Fider Test"},
 		To: []dto.Recipient{
 			{
@@ -160,3 +218,109 @@ func TestBatch_Success(t *testing.T) {
 	Expect(string(requests[1].body)).ContainsSubstring("Message-ID: ")
 	Expect(string(requests[1].body)).ContainsSubstring("Hello World Arya!")
 }
+
+func TestSend_TenantTemplate(t *testing.T) {
+	RegisterT(t)
+	reset()
+	email.SetAllowlist("")
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetEmailTemplate) error {
+		q.Result = &entity.EmailTemplate{
+			Kind:    "signin_email",
+			Subject: "Sign in to {{ .siteName }}",
+			Body:    "<tr><td>Click here: {{ .link | html }}</td></tr>",
+		}
+		return nil
+	})
+
+	deliver(&cmd.SendMail{
+		From: dto.Recipient{Name: "Fider Test"},
+		To: []dto.Recipient{
+			{
+				Name:    "Jon Sow",
+				Address: "jon.snow@got.com",
+				Props: dto.Props{
+					"siteName": "Game of Thrones",
+					"link":     "<a href='http://got.test.fider.io/signin/verify?k=123'>Sign in</a>",
+				},
+			},
+		},
+		TemplateName: "signin_email",
+	})
+
+	Expect(requests).HasLen(1)
+	Expect(string(requests[0].body)).ContainsSubstring("Subject: Sign in to Game of Thrones\r\n")
+	Expect(string(requests[0].body)).ContainsSubstring("Click here: <a href='http://got.test.fider.io/signin/verify?k=123'>Sign in</a>")
+	ExpectHandler(&query.GetEmailTemplate{}).CalledOnce()
+}
+
+func TestSend_SkipSuppressedAndContinue(t *testing.T) {
+	RegisterT(t)
+	reset()
+	email.SetAllowlist("^.*@got.com$")
+
+	result := deliver(&cmd.SendMail{
+		From: dto.Recipient{Name: "Fider Test"},
+		To: []dto.Recipient{
+			{Name: "Nobody", Address: ""},
+			{Name: "Bran Stark", Address: "bran.stark@gmail.com"},
+			{Name: "Jon Sow", Address: "jon.snow@got.com"},
+		},
+		TemplateName: "echo_test",
+		Props: dto.Props{
+			"name": "Hello",
+		},
+	})
+
+	Expect(requests).HasLen(1)
+	Expect(requests[0].to).Equals([]string{"jon.snow@got.com"})
+	Expect(result.Sent).Equals([]string{"jon.snow@got.com"})
+	Expect(result.Suppressed).Equals([]string{"", "bran.stark@gmail.com"})
+	email.SetAllowlist("")
+}
+
+func TestSend_Failure(t *testing.T) {
+	RegisterT(t)
+	reset()
+	email.SetAllowlist("")
+
+	smtp.Send = func(localname, servername string, enableStartTLS bool, auth gosmtp.Auth, from string, to []string, body []byte) error {
+		if to[0] == "arya.stark@got.com" {
+			return errors.New("connection refused")
+		}
+		return mockSend(localname, servername, enableStartTLS, auth, from, to, body)
+	}
+
+	c := &cmd.DeliverMail{
+		Mail: &cmd.SendMail{
+			From: dto.Recipient{Name: "Fider Test"},
+			To: []dto.Recipient{
+				{Name: "Jon Sow", Address: "jon.snow@got.com"},
+				{Name: "Arya Stark", Address: "arya.stark@got.com"},
+			},
+			TemplateName: "echo_test",
+		},
+	}
+	err := bus.Dispatch(ctx, c)
+	Expect(err).IsNotNil()
+	Expect(requests).HasLen(1)
+	Expect(c.Sent).Equals([]string{"jon.snow@got.com"})
+}
+
+func TestSend_ListUnsubscribe(t *testing.T) {
+	RegisterT(t)
+	reset()
+
+	deliver(&cmd.SendMail{
+		From: dto.Recipient{Name: "Fider Test"},
+		To: []dto.Recipient{
+			{Name: "Jon Sow", Address: "jon.snow@got.com", Unsubscribe: "http://domain.com/unsubscribe/abc"},
+		},
+		TemplateName: "echo_test",
+		Props:        dto.Props{"name": "Hello"},
+	})
+
+	Expect(requests).HasLen(1)
+	Expect(string(requests[0].body)).ContainsSubstring("List-Unsubscribe: <http://domain.com/unsubscribe/abc>\r\n")
+	Expect(string(requests[0].body)).ContainsSubstring("List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+}
@@ -0,0 +1,128 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	ldapclient "github.com/getfider/fider/app/pkg/ldap"
+)
+
+func init() {
+	bus.Register(Service{})
+}
+
+type Service struct{}
+
+func (s Service) Name() string {
+	return "LDAP"
+}
+
+func (s Service) Category() string {
+	return "auth"
+}
+
+func (s Service) Enabled() bool {
+	return env.IsLDAPEnabled()
+}
+
+func (s Service) Init() {
+	bus.AddHandler(getLDAPProfile)
+}
+
+func getLDAPProfile(ctx context.Context, q *query.GetLDAPProfile) error {
+	cfg := env.Config.LDAP
+	if q.Username == "" || q.Password == "" {
+		return app.ErrNotFound
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.TLSSkipVerify} //nolint:gosec
+	conn, err := ldapclient.Dial(cfg.URL, tlsConfig)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if cfg.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if cfg.BindDN != "" {
+		if err := conn.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+			return errors.Wrap(err, "failed to bind LDAP service account")
+		}
+	}
+
+	filter := strings.ReplaceAll(cfg.SearchFilter, "{username}", ldapclient.EscapeFilter(q.Username))
+	attributes := []string{cfg.AttributeID, cfg.AttributeName, cfg.AttributeEmail, cfg.AttributeGroups}
+	entries, err := conn.Search(cfg.BaseDN, filter, attributes, 2)
+	if err != nil {
+		return errors.Wrap(err, "failed to search LDAP user '%s'", q.Username)
+	}
+	if len(entries) != 1 {
+		return errors.Wrap(app.ErrNotFound, "expected one LDAP user '%s', found %d", q.Username, len(entries))
+	}
+
+	if err := conn.Bind(entries[0].DN, q.Password); err != nil {
+		if errors.Cause(err) == ldapclient.ErrInvalidCredentials {
+			return errors.Wrap(app.ErrNotFound, "invalid password for LDAP user '%s'", q.Username)
+		}
+		return errors.Wrap(err, "failed to bind LDAP user '%s'", q.Username)
+	}
+
+	q.Result = toUserProfile(entries[0], q.Username)
+	return nil
+}
+
+func toUserProfile(entry *ldapclient.Entry, username string) *dto.LDAPUserProfile {
+	cfg := env.Config.LDAP
+	profile := &dto.LDAPUserProfile{
+		ID:    entry.GetAttributeValue(cfg.AttributeID),
+		Name:  entry.GetAttributeValue(cfg.AttributeName),
+		Email: strings.ToLower(entry.GetAttributeValue(cfg.AttributeEmail)),
+	}
+
+	if profile.ID == "" {
+		profile.ID = entry.DN
+	}
+	if profile.Name == "" {
+		profile.Name = username
+	}
+
+	if cfg.AdministratorGroups != "" || cfg.CollaboratorGroups != "" {
+		groups := entry.GetAttributeValues(cfg.AttributeGroups)
+		profile.Role = enum.RoleVisitor
+		if isMemberOf(groups, cfg.CollaboratorGroups) {
+			profile.Role = enum.RoleCollaborator
+		}
+		if isMemberOf(groups, cfg.AdministratorGroups) {
+			profile.Role = enum.RoleAdministrator
+		}
+	}
+
+	return profile
+}
+
+func isMemberOf(groups []string, mapping string) bool {
+	for _, expected := range strings.Split(mapping, ";") {
+		expected = strings.TrimSpace(expected)
+		if expected == "" {
+			continue
+		}
+		for _, group := range groups {
+			if strings.EqualFold(strings.TrimSpace(group), expected) {
+				return true
+			}
+		}
+	}
+	return false
+}
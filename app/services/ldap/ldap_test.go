@@ -0,0 +1,105 @@
+package ldap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	ldapclient "github.com/getfider/fider/app/pkg/ldap"
+)
+
+var jonSnow = &ldapclient.Entry{
+	DN: "uid=jon.snow,ou=people,dc=got,dc=com",
+	Attributes: map[string][]string{
+		"uid":      {"jon.snow"},
+		"cn":       {"Jon Snow"},
+		"mail":     {"Jon.Snow@got.com"},
+		"memberOf": {"cn=nightswatch,ou=groups,dc=got,dc=com", "CN=Starks,OU=Groups,DC=got,DC=com"},
+	},
+}
+
+func TestGetLDAPProfile_EmptyPassword(t *testing.T) {
+	RegisterT(t)
+
+	q := &query.GetLDAPProfile{Username: "jon.snow", Password: ""}
+	err := getLDAPProfile(context.Background(), q)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	Expect(q.Result).IsNil()
+}
+
+func TestToUserProfile(t *testing.T) {
+	RegisterT(t)
+
+	profile := toUserProfile(jonSnow, "jon.snow")
+	Expect(profile.ID).Equals("jon.snow")
+	Expect(profile.Name).Equals("Jon Snow")
+	Expect(profile.Email).Equals("jon.snow@got.com")
+	Expect(profile.Role).Equals(enum.Role(0))
+}
+
+func TestToUserProfile_MissingAttributes(t *testing.T) {
+	RegisterT(t)
+
+	entry := &ldapclient.Entry{DN: "uid=arya,ou=people,dc=got,dc=com", Attributes: map[string][]string{}}
+	profile := toUserProfile(entry, "arya")
+	Expect(profile.ID).Equals("uid=arya,ou=people,dc=got,dc=com")
+	Expect(profile.Name).Equals("arya")
+	Expect(profile.Email).Equals("")
+}
+
+func TestToUserProfile_GroupMapping(t *testing.T) {
+	RegisterT(t)
+
+	defer func(admins, collaborators string) {
+		env.Config.LDAP.AdministratorGroups = admins
+		env.Config.LDAP.CollaboratorGroups = collaborators
+	}(env.Config.LDAP.AdministratorGroups, env.Config.LDAP.CollaboratorGroups)
+
+	env.Config.LDAP.AdministratorGroups = "cn=lords,ou=groups,dc=got,dc=com"
+	env.Config.LDAP.CollaboratorGroups = "cn=lannisters,ou=groups,dc=got,dc=com"
+	Expect(toUserProfile(jonSnow, "jon.snow").Role).Equals(enum.RoleVisitor)
+
+	env.Config.LDAP.CollaboratorGroups = "cn=lannisters,ou=groups,dc=got,dc=com; cn=nightswatch,ou=groups,dc=got,dc=com"
+	Expect(toUserProfile(jonSnow, "jon.snow").Role).Equals(enum.RoleCollaborator)
+
+	env.Config.LDAP.AdministratorGroups = "cn=starks,ou=groups,dc=got,dc=com"
+	Expect(toUserProfile(jonSnow, "jon.snow").Role).Equals(enum.RoleAdministrator)
+}
+
+// Runs against the ldaptest service of docker-compose
+func TestGetLDAPProfile_Server(t *testing.T) {
+	RegisterT(t)
+
+	env.Config.LDAP.URL = "ldap://localhost:1389"
+	env.Config.LDAP.BindDN = "cn=admin,dc=fider,dc=io"
+	env.Config.LDAP.BindPassword = "admin_pw"
+	env.Config.LDAP.BaseDN = "ou=users,dc=fider,dc=io"
+	env.Config.LDAP.SearchFilter = "(&(objectClass=inetOrgPerson)(uid={username}))"
+
+	q := &query.GetLDAPProfile{Username: "jon.snow", Password: "ghost"}
+	err := getLDAPProfile(context.Background(), q)
+	Expect(err).IsNil()
+	Expect(q.Result.ID).Equals("jon.snow")
+	Expect(q.Result.Name).IsNotEmpty()
+	Expect(q.Result.Role).Equals(enum.Role(0))
+
+	q = &query.GetLDAPProfile{Username: "jon.snow", Password: "needle"}
+	err = getLDAPProfile(context.Background(), q)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	Expect(q.Result).IsNil()
+
+	q = &query.GetLDAPProfile{Username: "hot.pie", Password: "bread"}
+	err = getLDAPProfile(context.Background(), q)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	Expect(q.Result).IsNil()
+
+	q = &query.GetLDAPProfile{Username: "*", Password: "ghost"}
+	err = getLDAPProfile(context.Background(), q)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	Expect(q.Result).IsNil()
+}
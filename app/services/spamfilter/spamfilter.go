@@ -0,0 +1,213 @@
+package spamfilter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+var linkRegex = regexp.MustCompile(`(?i)(https?://|www\.)\S+`)
+
+func init() {
+	bus.Register(Service{})
+}
+
+type Service struct{}
+
+func (s Service) Name() string {
+	return "Spam Filter"
+}
+
+func (s Service) Category() string {
+	return "spamfilter"
+}
+
+func (s Service) Enabled() bool {
+	return env.Config.SpamFilter.Enabled
+}
+
+func (s Service) Init() {
+	bus.AddHandler(checkSpam)
+}
+
+// checkSpam runs the built-in heuristics and, when configured, the external checker
+// Content from staff members is always trusted
+func checkSpam(ctx context.Context, q *query.CheckSpam) error {
+	q.Result.IsSpam = false
+	q.Result.Reasons = make([]string, 0)
+
+	user, ok := ctx.Value(app.UserCtxKey).(*entity.User)
+	if !ok || user.IsCollaborator() {
+		return nil
+	}
+
+	text := q.Title + "\n" + q.Content
+	cfg := env.Config.SpamFilter
+
+	if cfg.MaxLinks > 0 {
+		if count := len(linkRegex.FindAllString(text, -1)); count > cfg.MaxLinks {
+			q.Result.Reasons = append(q.Result.Reasons, fmt.Sprintf("Contains %d links, limit is %d.", count, cfg.MaxLinks))
+		}
+	}
+
+	if word := findBlockedWord(text, compileBlocklist(cfg.BlockedWords)); word != "" {
+		q.Result.Reasons = append(q.Result.Reasons, fmt.Sprintf("Contains blocked word '%s'.", word))
+	}
+
+	// Edits don't add content, so they don't count towards the velocity of new accounts
+	if !q.IsEdit && cfg.NewAccountMax > 0 && cfg.NewAccountAge > 0 {
+		velocity := &query.GetUserContentVelocity{UserID: user.ID, Since: time.Now().Add(-1 * time.Hour)}
+		if err := bus.Dispatch(ctx, velocity); err != nil {
+			return err
+		}
+
+		isNewAccount := time.Since(velocity.Result.AccountCreatedAt) < cfg.NewAccountAge
+		if isNewAccount && velocity.Result.RecentCount >= cfg.NewAccountMax {
+			q.Result.Reasons = append(q.Result.Reasons, fmt.Sprintf("New account added %d posts and comments within the last hour.", velocity.Result.RecentCount))
+		}
+	}
+
+	if len(q.Result.Reasons) == 0 && cfg.CheckerURL != "" {
+		if reason := checkExternal(ctx, cfg.CheckerURL, user, q); reason != "" {
+			q.Result.Reasons = append(q.Result.Reasons, reason)
+		}
+	}
+
+	q.Result.IsSpam = len(q.Result.Reasons) > 0
+	return nil
+}
+
+type blockedWord struct {
+	word    string
+	pattern *regexp.Regexp
+}
+
+var blocklist struct {
+	sync.Mutex
+	source string
+	words  []blockedWord
+}
+
+// compileBlocklist returns the patterns of the comma separated blocklist, which are only compiled again when it changes
+func compileBlocklist(source string) []blockedWord {
+	blocklist.Lock()
+	defer blocklist.Unlock()
+
+	if blocklist.words != nil && blocklist.source == source {
+		return blocklist.words
+	}
+
+	words := make([]blockedWord, 0)
+	for _, word := range strings.Split(source, ",") {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" {
+			continue
+		}
+		words = append(words, blockedWord{
+			word:    word,
+			pattern: regexp.MustCompile(`\b` + regexp.QuoteMeta(word) + `\b`),
+		})
+	}
+
+	blocklist.source, blocklist.words = source, words
+	return words
+}
+
+// findBlockedWord returns the first word or phrase of the blocklist that is found on text
+func findBlockedWord(text string, words []blockedWord) string {
+	text = strings.ToLower(text)
+	for _, w := range words {
+		if w.pattern.MatchString(text) {
+			return w.word
+		}
+	}
+	return ""
+}
+
+type externalCheckRequest struct {
+	Kind    string              `json:"kind"`
+	Title   string              `json:"title,omitempty"`
+	Content string              `json:"content"`
+	Tenant  string              `json:"tenant"`
+	User    externalCheckAuthor `json:"user"`
+}
+
+type externalCheckAuthor struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type externalCheckResponse struct {
+	Spam   bool   `json:"spam"`
+	Reason string `json:"reason"`
+}
+
+// checkExternal sends the content to the external checker and returns why it was flagged, or empty when it wasn't
+// Content is published when the checker fails, so that an outage doesn't block everyone from posting
+func checkExternal(ctx context.Context, url string, user *entity.User, q *query.CheckSpam) string {
+	tenant := ""
+	if t, ok := ctx.Value(app.TenantCtxKey).(*entity.Tenant); ok {
+		tenant = t.Subdomain
+	}
+
+	body, err := json.Marshal(externalCheckRequest{
+		Kind:    q.Kind,
+		Title:   q.Title,
+		Content: q.Content,
+		Tenant:  tenant,
+		User: externalCheckAuthor{
+			ID:    user.ID,
+			Name:  user.Name,
+			Email: user.Email,
+		},
+	})
+	if err != nil {
+		log.Error(ctx, err)
+		return ""
+	}
+
+	request := &cmd.HTTPRequest{
+		URL:     url,
+		Body:    strings.NewReader(string(body)),
+		Method:  http.MethodPost,
+		Headers: map[string]string{"Content-Type": "application/json"},
+	}
+	if err := bus.Dispatch(ctx, request); err != nil {
+		log.Warnf(ctx, "Spam checker request failed: @{Error}", dto.Props{"Error": err.Error()})
+		return ""
+	}
+
+	if request.ResponseStatusCode >= http.StatusBadRequest {
+		log.Warnf(ctx, "Spam checker returned @{StatusCode}", dto.Props{"StatusCode": request.ResponseStatusCode})
+		return ""
+	}
+
+	response := externalCheckResponse{}
+	if err := json.Unmarshal(request.ResponseBody, &response); err != nil {
+		log.Warnf(ctx, "Spam checker returned an invalid response: @{Error}", dto.Props{"Error": err.Error()})
+		return ""
+	}
+
+	if !response.Spam {
+		return ""
+	}
+	if response.Reason == "" {
+		return "Flagged by spam checker."
+	}
+	return response.Reason
+}
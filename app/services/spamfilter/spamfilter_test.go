@@ -0,0 +1,151 @@
+package spamfilter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+)
+
+var aryaStark = &entity.User{ID: 2, Name: "Arya Stark", Email: "arya.stark@got.com", Role: enum.RoleVisitor}
+
+func withUser(user *entity.User) context.Context {
+	return context.WithValue(context.Background(), app.UserCtxKey, user)
+}
+
+func registerVelocity(createdAt time.Time, recentCount int) {
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserContentVelocity) error {
+		q.Result.AccountCreatedAt = createdAt
+		q.Result.RecentCount = recentCount
+		return nil
+	})
+}
+
+func TestCheckSpam_CleanContent(t *testing.T) {
+	RegisterT(t)
+	registerVelocity(time.Now().AddDate(-1, 0, 0), 0)
+
+	q := &query.CheckSpam{Kind: "post", Title: "Dark mode", Content: "Please add a dark mode, see https://example.com"}
+	err := checkSpam(withUser(aryaStark), q)
+	Expect(err).IsNil()
+	Expect(q.Result.IsSpam).IsFalse()
+	Expect(q.Result.Reasons).HasLen(0)
+}
+
+func TestCheckSpam_TooManyLinks(t *testing.T) {
+	RegisterT(t)
+	registerVelocity(time.Now().AddDate(-1, 0, 0), 0)
+	env.Config.SpamFilter.MaxLinks = 2
+
+	q := &query.CheckSpam{Kind: "comment", Content: "http://a.com https://b.com www.c.com"}
+	err := checkSpam(withUser(aryaStark), q)
+	Expect(err).IsNil()
+	Expect(q.Result.IsSpam).IsTrue()
+	Expect(q.Result.Reasons).Equals([]string{"Contains 3 links, limit is 2."})
+}
+
+func TestCheckSpam_BlockedWords(t *testing.T) {
+	RegisterT(t)
+	registerVelocity(time.Now().AddDate(-1, 0, 0), 0)
+	env.Config.SpamFilter.BlockedWords = "casino, free money"
+
+	q := &query.CheckSpam{Kind: "post", Title: "Get FREE MONEY now", Content: ""}
+	err := checkSpam(withUser(aryaStark), q)
+	Expect(err).IsNil()
+	Expect(q.Result.IsSpam).IsTrue()
+	Expect(q.Result.Reasons).Equals([]string{"Contains blocked word 'free money'."})
+
+	q = &query.CheckSpam{Kind: "post", Title: "Occasionally slow", Content: ""}
+	err = checkSpam(withUser(aryaStark), q)
+	Expect(err).IsNil()
+	Expect(q.Result.IsSpam).IsFalse()
+
+	env.Config.SpamFilter.BlockedWords = "slow"
+	q = &query.CheckSpam{Kind: "post", Title: "Occasionally slow", Content: ""}
+	err = checkSpam(withUser(aryaStark), q)
+	Expect(err).IsNil()
+	Expect(q.Result.Reasons).Equals([]string{"Contains blocked word 'slow'."})
+}
+
+func TestCheckSpam_NewAccountVelocity(t *testing.T) {
+	RegisterT(t)
+	registerVelocity(time.Now().Add(-2*time.Hour), 5)
+
+	q := &query.CheckSpam{Kind: "comment", Content: "Me too!"}
+	err := checkSpam(withUser(aryaStark), q)
+	Expect(err).IsNil()
+	Expect(q.Result.IsSpam).IsTrue()
+	Expect(q.Result.Reasons).Equals([]string{"New account added 5 posts and comments within the last hour."})
+
+	q = &query.CheckSpam{Kind: "comment", Content: "Me too!", IsEdit: true}
+	err = checkSpam(withUser(aryaStark), q)
+	Expect(err).IsNil()
+	Expect(q.Result.IsSpam).IsFalse()
+}
+
+func TestCheckSpam_OldAccountVelocity(t *testing.T) {
+	RegisterT(t)
+	registerVelocity(time.Now().AddDate(0, -1, 0), 20)
+
+	q := &query.CheckSpam{Kind: "comment", Content: "Me too!"}
+	err := checkSpam(withUser(aryaStark), q)
+	Expect(err).IsNil()
+	Expect(q.Result.IsSpam).IsFalse()
+}
+
+func TestCheckSpam_StaffIsTrusted(t *testing.T) {
+	RegisterT(t)
+	env.Config.SpamFilter.BlockedWords = "casino"
+
+	jonSnow := &entity.User{ID: 1, Name: "Jon Snow", Role: enum.RoleAdministrator}
+	q := &query.CheckSpam{Kind: "post", Title: "Casino night", Content: ""}
+	err := checkSpam(withUser(jonSnow), q)
+	Expect(err).IsNil()
+	Expect(q.Result.IsSpam).IsFalse()
+}
+
+func TestCheckSpam_ExternalChecker(t *testing.T) {
+	RegisterT(t)
+	registerVelocity(time.Now().AddDate(-1, 0, 0), 0)
+	env.Config.SpamFilter.CheckerURL = "https://spamcheck.example.com/check"
+
+	var request *cmd.HTTPRequest
+	bus.AddHandler(func(ctx context.Context, c *cmd.HTTPRequest) error {
+		request = c
+		c.ResponseStatusCode = 200
+		c.ResponseBody = []byte(`{ "spam": true, "reason": "Known spammer." }`)
+		return nil
+	})
+
+	q := &query.CheckSpam{Kind: "post", Title: "Hello", Content: "World"}
+	err := checkSpam(withUser(aryaStark), q)
+	Expect(err).IsNil()
+	Expect(request.URL).Equals("https://spamcheck.example.com/check")
+	Expect(request.Method).Equals("POST")
+	Expect(q.Result.IsSpam).IsTrue()
+	Expect(q.Result.Reasons).Equals([]string{"Known spammer."})
+}
+
+func TestCheckSpam_ExternalCheckerFailure(t *testing.T) {
+	RegisterT(t)
+	registerVelocity(time.Now().AddDate(-1, 0, 0), 0)
+	env.Config.SpamFilter.CheckerURL = "https://spamcheck.example.com/check"
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.HTTPRequest) error {
+		c.ResponseStatusCode = 503
+		return nil
+	})
+
+	q := &query.CheckSpam{Kind: "post", Title: "Hello", Content: "World"}
+	err := checkSpam(withUser(aryaStark), q)
+	Expect(err).IsNil()
+	Expect(q.Result.IsSpam).IsFalse()
+}
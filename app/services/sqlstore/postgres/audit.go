@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+type dbAuditLog struct {
+	ID           int                `db:"id"`
+	Actor        *dbUser            `db:"actor"`
+	Impersonated *dbUser            `db:"impersonated"`
+	Action       string             `db:"action"`
+	TargetType   string             `db:"target_type"`
+	TargetID     string             `db:"target_id"`
+	Before       entity.AuditValues `db:"before_value"`
+	After        entity.AuditValues `db:"after_value"`
+	ClientIP     sql.NullString     `db:"client_ip"`
+	CreatedAt    time.Time          `db:"created_at"`
+}
+
+func (l *dbAuditLog) toModel(ctx context.Context) *entity.AuditLog {
+	log := &entity.AuditLog{
+		ID:         l.ID,
+		Action:     enum.AuditAction(l.Action),
+		TargetType: l.TargetType,
+		TargetID:   l.TargetID,
+		Before:     l.Before,
+		After:      l.After,
+		ClientIP:   l.ClientIP.String,
+		CreatedAt:  l.CreatedAt,
+	}
+	if l.Actor != nil && l.Actor.ID.Valid {
+		log.Actor = l.Actor.toModel(ctx)
+	}
+	if l.Impersonated != nil && l.Impersonated.ID.Valid {
+		log.Impersonated = l.Impersonated.toModel(ctx)
+	}
+	return log
+}
+
+func addAuditLog(ctx context.Context, c *cmd.AddAuditLog) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		if !c.Standalone {
+			return insertAuditLog(ctx, trx, tenant, user, c)
+		}
+
+		ownTrx, err := dbx.BeginTx(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to open transaction")
+		}
+
+		if err = insertAuditLog(ctx, ownTrx, tenant, user, c); err != nil {
+			ownTrx.MustRollback()
+			return err
+		}
+
+		if err = ownTrx.Commit(); err != nil {
+			return errors.Wrap(err, "failed commit transaction")
+		}
+		return nil
+	})
+}
+
+func insertAuditLog(ctx context.Context, trx *dbx.Trx, tenant *entity.Tenant, user *entity.User, c *cmd.AddAuditLog) error {
+	var actorID, impersonatedID sql.NullInt64
+	if user != nil {
+		actorID = sql.NullInt64{Int64: int64(user.ID), Valid: true}
+	}
+
+	// while impersonating, the administrator is the real actor
+	if impersonator, ok := ctx.Value(app.ImpersonatorCtxKey).(*entity.User); ok && impersonator != nil && user != nil {
+		actorID = sql.NullInt64{Int64: int64(impersonator.ID), Valid: true}
+		impersonatedID = sql.NullInt64{Int64: int64(user.ID), Valid: true}
+	}
+
+	dbClientIP := sql.NullString{
+		String: c.ClientIP,
+		Valid:  len(c.ClientIP) > 0,
+	}
+
+	_, err := trx.Execute(`
+		INSERT INTO audit_logs (tenant_id, actor_id, impersonated_id, action, target_type, target_id, before_value, after_value, client_ip, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, tenant.ID, actorID, impersonatedID, string(c.Action), c.TargetType, c.TargetID, c.Before, c.After, dbClientIP, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "failed to add audit log for '%s'", c.Action)
+	}
+	return nil
+}
+
+func searchAuditLogs(ctx context.Context, q *query.SearchAuditLogs) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		conditions := []string{"l.tenant_id = $1"}
+		args := []any{tenant.ID}
+		addCondition := func(condition string, arg any) {
+			args = append(args, arg)
+			conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+		}
+
+		if q.Action != "" {
+			addCondition("l.action = $%d", string(q.Action))
+		}
+		if q.ActorID > 0 {
+			addCondition("l.actor_id = $%d", q.ActorID)
+		}
+		if q.TargetType != "" {
+			addCondition("l.target_type = $%d", q.TargetType)
+		}
+		if q.TargetID != "" {
+			addCondition("l.target_id = $%d", q.TargetID)
+		}
+		if q.Since != nil {
+			addCondition("l.created_at >= $%d", *q.Since)
+		}
+		if q.Until != nil {
+			addCondition("l.created_at < $%d", *q.Until)
+		}
+
+		if q.Limit <= 0 || q.Limit > 100 {
+			q.Limit = 50
+		}
+		if q.Offset < 0 {
+			q.Offset = 0
+		}
+
+		logs := []*dbAuditLog{}
+		err := trx.Select(&logs, fmt.Sprintf(`
+			SELECT l.id,
+						 l.action,
+						 l.target_type,
+						 l.target_id,
+						 l.before_value,
+						 l.after_value,
+						 l.client_ip,
+						 l.created_at,
+						 u.id AS actor_id,
+						 u.name AS actor_name,
+						 u.email AS actor_email,
+						 u.role AS actor_role,
+						 u.status AS actor_status,
+						 u.avatar_type AS actor_avatar_type,
+						 u.avatar_bkey AS actor_avatar_bkey,
+						 i.id AS impersonated_id,
+						 i.name AS impersonated_name,
+						 i.email AS impersonated_email,
+						 i.role AS impersonated_role,
+						 i.status AS impersonated_status,
+						 i.avatar_type AS impersonated_avatar_type,
+						 i.avatar_bkey AS impersonated_avatar_bkey
+			FROM audit_logs l
+			LEFT JOIN users u
+			ON u.id = l.actor_id
+			AND u.tenant_id = l.tenant_id
+			LEFT JOIN users i
+			ON i.id = l.impersonated_id
+			AND i.tenant_id = l.tenant_id
+			WHERE %s
+			ORDER BY l.created_at DESC, l.id DESC
+			LIMIT %d OFFSET %d`, strings.Join(conditions, " AND "), q.Limit, q.Offset), args...)
+		if err != nil {
+			return errors.Wrap(err, "failed to search audit logs")
+		}
+
+		q.Result = make([]*entity.AuditLog, len(logs))
+		for i, log := range logs {
+			q.Result[i] = log.toModel(ctx)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,80 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestAuditLogStorage_AddAndSearch(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := bus.Dispatch(jonSnowCtx, &cmd.AddAuditLog{
+		Action:     enum.AuditUserRoleChanged,
+		TargetType: "user",
+		TargetID:   "3",
+		Before:     entity.AuditValues{"role": "visitor"},
+		After:      entity.AuditValues{"role": "collaborator"},
+		ClientIP:   "127.0.0.1",
+	})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.AddAuditLog{
+		Action:     enum.AuditTagDeleted,
+		TargetType: "tag",
+		TargetID:   "bug",
+	})
+	Expect(err).IsNil()
+
+	searchAll := &query.SearchAuditLogs{}
+	err = bus.Dispatch(jonSnowCtx, searchAll)
+	Expect(err).IsNil()
+	Expect(searchAll.Result).HasLen(2)
+	Expect(searchAll.Result[0].Action).Equals(enum.AuditTagDeleted)
+	Expect(searchAll.Result[0].Before).IsNil()
+	Expect(searchAll.Result[0].ClientIP).Equals("")
+	Expect(searchAll.Result[1].Action).Equals(enum.AuditUserRoleChanged)
+	Expect(searchAll.Result[1].Actor.ID).Equals(jonSnow.ID)
+	Expect(searchAll.Result[1].Before).Equals(entity.AuditValues{"role": "visitor"})
+	Expect(searchAll.Result[1].After).Equals(entity.AuditValues{"role": "collaborator"})
+	Expect(searchAll.Result[1].ClientIP).Equals("127.0.0.1")
+
+	searchByAction := &query.SearchAuditLogs{Action: enum.AuditUserRoleChanged, ActorID: jonSnow.ID}
+	err = bus.Dispatch(jonSnowCtx, searchByAction)
+	Expect(err).IsNil()
+	Expect(searchByAction.Result).HasLen(1)
+	Expect(searchByAction.Result[0].TargetID).Equals("3")
+
+	future := time.Now().Add(time.Hour)
+	searchSince := &query.SearchAuditLogs{Since: &future}
+	err = bus.Dispatch(jonSnowCtx, searchSince)
+	Expect(err).IsNil()
+	Expect(searchSince.Result).HasLen(0)
+
+	searchOtherTenant := &query.SearchAuditLogs{}
+	err = bus.Dispatch(avengersTenantCtx, searchOtherTenant)
+	Expect(err).IsNil()
+	Expect(searchOtherTenant.Result).HasLen(0)
+}
+
+func TestAuditLogStorage_AppendOnly(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := bus.Dispatch(jonSnowCtx, &cmd.AddAuditLog{
+		Action:     enum.AuditUserBlocked,
+		TargetType: "user",
+		TargetID:   "2",
+	})
+	Expect(err).IsNil()
+
+	_, err = trx.Execute("UPDATE audit_logs SET action = 'user.unblocked' WHERE tenant_id = 1")
+	Expect(err).IsNotNil()
+}
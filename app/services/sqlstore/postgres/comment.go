@@ -12,22 +12,28 @@ import (
 )
 
 type dbComment struct {
-	ID          int          `db:"id"`
-	Content     string       `db:"content"`
-	CreatedAt   time.Time    `db:"created_at"`
-	User        *dbUser      `db:"user"`
-	Attachments []string     `db:"attachment_bkeys"`
-	EditedAt    dbx.NullTime `db:"edited_at"`
-	EditedBy    *dbUser      `db:"edited_by"`
+	ID            int          `db:"id"`
+	Content       string       `db:"content"`
+	CreatedAt     time.Time    `db:"created_at"`
+	User          *dbUser      `db:"user"`
+	Attachments   []string     `db:"attachment_bkeys"`
+	EditedAt      dbx.NullTime `db:"edited_at"`
+	EditedBy      *dbUser      `db:"edited_by"`
+	PendingReview bool         `db:"pending_review"`
+	IsHidden      bool         `db:"is_hidden"`
+	PostID        int          `db:"post_id"`
 }
 
 func (c *dbComment) toModel(ctx context.Context) *entity.Comment {
 	comment := &entity.Comment{
-		ID:          c.ID,
-		Content:     c.Content,
-		CreatedAt:   c.CreatedAt,
-		User:        c.User.toModel(ctx),
-		Attachments: c.Attachments,
+		ID:            c.ID,
+		Content:       c.Content,
+		CreatedAt:     c.CreatedAt,
+		User:          c.User.toModel(ctx),
+		Attachments:   c.Attachments,
+		PendingReview: c.PendingReview,
+		IsHidden:      c.IsHidden,
+		PostID:        c.PostID,
 	}
 	if c.EditedAt.Valid {
 		comment.EditedBy = c.EditedBy.toModel(ctx)
@@ -40,10 +46,10 @@ func addNewComment(ctx context.Context, c *cmd.AddNewComment) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
 		var id int
 		if err := trx.Get(&id, `
-			INSERT INTO comments (tenant_id, post_id, content, user_id, created_at) 
-			VALUES ($1, $2, $3, $4, $5) 
+			INSERT INTO comments (tenant_id, post_id, content, user_id, created_at, pending_review) 
+			VALUES ($1, $2, $3, $4, $5, $6) 
 			RETURNING id
-		`, tenant.ID, c.Post.ID, c.Content, user.ID, time.Now()); err != nil {
+		`, tenant.ID, c.Post.ID, c.Content, user.ID, time.Now(), c.PendingReview); err != nil {
 			return errors.Wrap(err, "failed add new comment")
 		}
 
@@ -57,6 +63,26 @@ func addNewComment(ctx context.Context, c *cmd.AddNewComment) error {
 	})
 }
 
+func approveComment(ctx context.Context, c *cmd.ApproveComment) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute("UPDATE comments SET pending_review = false WHERE id = $1 AND tenant_id = $2", c.CommentID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to approve comment")
+		}
+		return nil
+	})
+}
+
+func hideComment(ctx context.Context, c *cmd.HideComment) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute("UPDATE comments SET is_hidden = true WHERE id = $1 AND tenant_id = $2", c.CommentID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to hide comment")
+		}
+		return nil
+	})
+}
+
 func updateComment(ctx context.Context, c *cmd.UpdateComment) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
 		_, err := trx.Execute(`
@@ -91,6 +117,11 @@ func getCommentByID(ctx context.Context, q *query.GetCommentByID) error {
 							c.content, 
 							c.created_at, 
 							c.edited_at, 
+							c.pending_review,
+					c.is_hidden,
+					c.post_id,
+							c.is_hidden,
+							c.post_id,
 							u.id AS user_id, 
 							u.name AS user_name,
 							u.email AS user_email,
@@ -114,7 +145,8 @@ func getCommentByID(ctx context.Context, q *query.GetCommentByID) error {
 			AND e.tenant_id = c.tenant_id
 			WHERE c.id = $1
 			AND c.tenant_id = $2
-			AND c.deleted_at IS NULL`, q.CommentID, tenant.ID)
+			AND c.deleted_at IS NULL
+			AND `+visibleContentCondition(user, "c"), q.CommentID, tenant.ID)
 
 		if err != nil {
 			return err
@@ -149,6 +181,7 @@ func getCommentsByPost(ctx context.Context, q *query.GetCommentsByPost) error {
 					c.content, 
 					c.created_at, 
 					c.edited_at, 
+					c.pending_review,
 					u.id AS user_id, 
 					u.name AS user_name,
 					u.email AS user_email,
@@ -179,6 +212,7 @@ func getCommentsByPost(ctx context.Context, q *query.GetCommentsByPost) error {
 			WHERE p.id = $1
 			AND p.tenant_id = $2
 			AND c.deleted_at IS NULL
+			AND `+visibleContentCondition(user, "c")+`
 			ORDER BY c.created_at ASC`, q.Post.ID, tenant.ID)
 		if err != nil {
 			return errors.Wrap(err, "failed get comments of post with id '%d'", q.Post.ID)
@@ -45,6 +45,8 @@ func getViewData(view string) (string, []enum.PostStatus, string) {
 		sort = "votes_count"
 	case "most-discussed":
 		sort = "comments_count"
+	case "most-valuable":
+		sort = "organizations_revenue DESC, organizations_count"
 	case "planned":
 		sort = "response_date"
 		statuses = []enum.PostStatus{enum.PostPlanned}
@@ -57,6 +59,16 @@ func getViewData(view string) (string, []enum.PostStatus, string) {
 	case "declined":
 		sort = "response_date"
 		statuses = []enum.PostStatus{enum.PostDeclined}
+	case "pending-review":
+		condition = "AND (pending_review = true OR EXISTS (SELECT 1 FROM comments c WHERE c.post_id = q.id AND c.pending_review = true AND c.deleted_at IS NULL))"
+		sort = "id"
+		statuses = []enum.PostStatus{
+			enum.PostOpen,
+			enum.PostStarted,
+			enum.PostPlanned,
+			enum.PostCompleted,
+			enum.PostDeclined,
+		}
 	case "all":
 		sort = "id"
 		statuses = []enum.PostStatus{
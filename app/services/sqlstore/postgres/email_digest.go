@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/lib/pq"
+)
+
+type dbEmailDigestItem struct {
+	ID         int       `db:"id"`
+	UserID     int       `db:"user_id"`
+	UserName   string    `db:"user_name"`
+	UserEmail  string    `db:"user_email"`
+	UserRole   enum.Role `db:"user_role"`
+	UserLocale string    `db:"user_locale"`
+	PostID     int       `db:"post_id"`
+	PostNumber int       `db:"post_number"`
+	PostTitle  string    `db:"post_title"`
+	PostSlug   string    `db:"post_slug"`
+	Title      string    `db:"title"`
+	Content    string    `db:"content"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+func (i *dbEmailDigestItem) toModel(tenant *entity.Tenant) *entity.EmailDigestItem {
+	return &entity.EmailDigestItem{
+		ID: i.ID,
+		User: &entity.User{
+			ID:     i.UserID,
+			Name:   i.UserName,
+			Email:  i.UserEmail,
+			Role:   i.UserRole,
+			Locale: i.UserLocale,
+			Tenant: tenant,
+		},
+		Post: &entity.Post{
+			ID:     i.PostID,
+			Number: i.PostNumber,
+			Title:  i.PostTitle,
+			Slug:   i.PostSlug,
+		},
+		Title:     i.Title,
+		Content:   i.Content,
+		CreatedAt: i.CreatedAt,
+	}
+}
+
+func addEmailDigestItem(ctx context.Context, c *cmd.AddEmailDigestItem) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute(`
+			INSERT INTO email_digest_items (tenant_id, user_id, post_id, delivery, title, content, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			tenant.ID, c.User.ID, c.PostID, c.Delivery, c.Title, c.Content, time.Now(),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to add email digest item")
+		}
+		return nil
+	})
+}
+
+func deleteEmailDigestItems(ctx context.Context, c *cmd.DeleteEmailDigestItems) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute(
+			"DELETE FROM email_digest_items WHERE tenant_id = $1 AND delivery = $2 AND id <= $3",
+			tenant.ID, c.Delivery, c.UpToID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete email digest items")
+		}
+		return nil
+	})
+}
+
+func getUsersEmailDelivery(ctx context.Context, q *query.GetUsersEmailDelivery) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		q.Result = make(map[int]enum.EmailDelivery)
+		if len(q.UserIDs) == 0 {
+			return nil
+		}
+
+		type dbUserDelivery struct {
+			UserID int    `db:"user_id"`
+			Value  string `db:"value"`
+		}
+
+		var settings []*dbUserDelivery
+		err := trx.Select(&settings, `
+			SELECT user_id, value FROM user_settings
+			WHERE tenant_id = $1 AND key = $2 AND user_id = ANY($3)`,
+			tenant.ID, enum.EmailDeliverySettingsKeyName, pq.Array(q.UserIDs),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to get users email delivery")
+		}
+
+		for _, s := range settings {
+			q.Result[s.UserID] = enum.EmailDelivery(s.Value)
+		}
+		return nil
+	})
+}
+
+func getTenantsWithPendingEmailDigests(ctx context.Context, q *query.GetTenantsWithPendingEmailDigests) error {
+	return using(ctx, func(trx *dbx.Trx, _ *entity.Tenant, _ *entity.User) error {
+		var tenants []*dbTenant
+		err := trx.Select(&tenants, `
+			SELECT id, name, subdomain, cname, invitation, locale, welcome_message, status, is_private, logo_bkey, custom_css, is_email_auth_allowed, passkey_mode,
+			       allowed_email_domains, auto_join_role, sso_secret
+			FROM tenants
+			WHERE status = $1
+			AND id IN (SELECT DISTINCT tenant_id FROM email_digest_items WHERE delivery = $2)
+			ORDER BY id`,
+			enum.TenantActive, q.Delivery,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to get tenants with pending email digests")
+		}
+
+		q.Result = make([]*entity.Tenant, len(tenants))
+		for i, tenant := range tenants {
+			q.Result[i] = tenant.toModel()
+		}
+		return nil
+	})
+}
+
+func getPendingEmailDigestItems(ctx context.Context, q *query.GetPendingEmailDigestItems) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		// Items of users and posts that are no longer active are not returned,
+		// but LastID still covers them so they are deleted along with the others
+		err := trx.Scalar(&q.LastID, "SELECT COALESCE(MAX(id), 0) FROM email_digest_items WHERE tenant_id = $1 AND delivery = $2", tenant.ID, q.Delivery)
+		if err != nil {
+			return errors.Wrap(err, "failed to get last email digest item")
+		}
+
+		var items []*dbEmailDigestItem
+		err = trx.Select(&items, `
+			SELECT i.id, i.title, i.content, i.created_at,
+			       u.id AS user_id, u.name AS user_name, u.email AS user_email, u.role AS user_role, u.locale AS user_locale,
+			       p.id AS post_id, p.number AS post_number, p.title AS post_title, p.slug AS post_slug
+			FROM email_digest_items i
+			INNER JOIN users u ON u.id = i.user_id AND u.tenant_id = i.tenant_id
+			INNER JOIN posts p ON p.id = i.post_id AND p.tenant_id = i.tenant_id
+			WHERE i.tenant_id = $1 AND i.delivery = $2 AND i.id <= $5
+			AND u.status = $3 AND u.email_supressed_at IS NULL
+			AND p.status != $4
+			ORDER BY i.user_id, i.post_id, i.id`,
+			tenant.ID, q.Delivery, enum.UserActive, enum.PostDeleted, q.LastID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to get pending email digest items")
+		}
+
+		q.Result = make([]*entity.EmailDigestItem, len(items))
+		for i, item := range items {
+			q.Result[i] = item.toModel(tenant)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,74 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestEmailDigestStorage_UsersEmailDelivery(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := bus.Dispatch(aryaStarkCtx, &cmd.UpdateCurrentUserSettings{Settings: map[string]string{
+		enum.EmailDeliverySettingsKeyName: string(enum.EmailDeliveryWeekly),
+	}})
+	Expect(err).IsNil()
+
+	getDelivery := &query.GetUsersEmailDelivery{UserIDs: []int{jonSnow.ID, aryaStark.ID}}
+	err = bus.Dispatch(jonSnowCtx, getDelivery)
+	Expect(err).IsNil()
+	Expect(getDelivery.Result).Equals(map[int]enum.EmailDelivery{
+		aryaStark.ID: enum.EmailDeliveryWeekly,
+	})
+}
+
+func TestEmailDigestStorage_AddListAndDelete(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "My new post", Description: "with this description"}
+	err := bus.Dispatch(aryaStarkCtx, newPost)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(aryaStarkCtx, &cmd.AddEmailDigestItem{
+		User:     jonSnow,
+		Delivery: enum.EmailDeliveryDaily,
+		PostID:   newPost.Result.ID,
+		Title:    "**Arya Stark** created this post.",
+		Content:  "with this description",
+	})
+	Expect(err).IsNil()
+
+	tenants := &query.GetTenantsWithPendingEmailDigests{Delivery: enum.EmailDeliveryDaily}
+	err = bus.Dispatch(demoTenantCtx, tenants)
+	Expect(err).IsNil()
+	Expect(tenants.Result).HasLen(1)
+	Expect(tenants.Result[0].ID).Equals(demoTenant.ID)
+
+	tenants = &query.GetTenantsWithPendingEmailDigests{Delivery: enum.EmailDeliveryWeekly}
+	err = bus.Dispatch(demoTenantCtx, tenants)
+	Expect(err).IsNil()
+	Expect(tenants.Result).HasLen(0)
+
+	items := &query.GetPendingEmailDigestItems{Delivery: enum.EmailDeliveryDaily}
+	err = bus.Dispatch(demoTenantCtx, items)
+	Expect(err).IsNil()
+	Expect(items.Result).HasLen(1)
+	Expect(items.Result[0].User.ID).Equals(jonSnow.ID)
+	Expect(items.Result[0].Post.Number).Equals(newPost.Result.Number)
+	Expect(items.Result[0].Title).Equals("**Arya Stark** created this post.")
+	Expect(items.LastID).Equals(items.Result[0].ID)
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.DeleteEmailDigestItems{Delivery: enum.EmailDeliveryDaily, UpToID: items.LastID})
+	Expect(err).IsNil()
+
+	items = &query.GetPendingEmailDigestItems{Delivery: enum.EmailDeliveryDaily}
+	err = bus.Dispatch(demoTenantCtx, items)
+	Expect(err).IsNil()
+	Expect(items.Result).HasLen(0)
+}
@@ -0,0 +1,394 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/rand"
+	"github.com/lib/pq"
+)
+
+const outboxEmailsPageSize = 50
+
+// emailClaimDuration is how long due emails are held by the delivery that claimed them,
+// they are picked up again once it's over if their delivery status was never recorded
+const emailClaimDuration = 10 * time.Minute
+
+// htmlPropKey tags props holding HTML, which must not be escaped once the email is rendered
+const htmlPropKey = "$html"
+
+type dbOutboxEmail struct {
+	ID            int64          `db:"id"`
+	TemplateName  string         `db:"template_name"`
+	ToName        string         `db:"to_name"`
+	ToAddress     string         `db:"to_address"`
+	Status        int            `db:"status"`
+	Attempts      int            `db:"attempts"`
+	LastError     sql.NullString `db:"last_error"`
+	NextAttemptAt time.Time      `db:"next_attempt_at"`
+	CreatedAt     time.Time      `db:"created_at"`
+	SentAt        dbx.NullTime   `db:"sent_at"`
+}
+
+func (e *dbOutboxEmail) toModel() *entity.OutboxEmail {
+	email := &entity.OutboxEmail{
+		ID:            e.ID,
+		TemplateName:  e.TemplateName,
+		ToName:        e.ToName,
+		ToAddress:     e.ToAddress,
+		Status:        enum.EmailStatus(e.Status),
+		Attempts:      e.Attempts,
+		LastError:     e.LastError.String,
+		NextAttemptAt: e.NextAttemptAt,
+		CreatedAt:     e.CreatedAt,
+	}
+	if e.SentAt.Valid {
+		email.SentAt = &e.SentAt.Time
+	}
+	return email
+}
+
+type dbEmailDelivery struct {
+	ID             int64          `db:"id"`
+	TenantID       sql.NullInt64  `db:"tenant_id"`
+	BatchID        string         `db:"batch_id"`
+	Locale         string         `db:"locale"`
+	TemplateName   string         `db:"template_name"`
+	FromName       string         `db:"from_name"`
+	FromAddress    string         `db:"from_address"`
+	ToName         string         `db:"to_name"`
+	ToAddress      string         `db:"to_address"`
+	ReplyTo        string         `db:"reply_to"`
+	UnsubscribeURL string         `db:"unsubscribe_url"`
+	Props          string         `db:"props"`
+	RecipientProps string         `db:"recipient_props"`
+	CustomTemplate sql.NullString `db:"custom_template"`
+	Attempts       int            `db:"attempts"`
+}
+
+func queueEmail(ctx context.Context, c *cmd.SendMail) error {
+	if len(c.To) == 0 {
+		return nil
+	}
+
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		var tenantID sql.NullInt64
+		if tenant != nil {
+			tenantID = sql.NullInt64{Int64: int64(tenant.ID), Valid: true}
+		}
+
+		props, err := json.Marshal(encodeEmailProps(c.Props))
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal email props")
+		}
+
+		var customTemplate sql.NullString
+		if c.Template != nil {
+			value, err := json.Marshal(c.Template)
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal email template")
+			}
+			customTemplate = sql.NullString{String: string(value), Valid: true}
+		}
+
+		// Emails of a batch are delivered together, so each locale gets its own batch
+		batchIDs := make(map[string]string)
+		now := time.Now()
+		for _, to := range c.To {
+			locale := to.Locale
+			if !i18n.IsValidLocale(locale) {
+				locale = i18n.GetLocale(ctx)
+			}
+			batchID, ok := batchIDs[locale]
+			if !ok {
+				batchID = rand.String(32)
+				batchIDs[locale] = batchID
+			}
+
+			recipientProps, err := json.Marshal(encodeEmailProps(to.Props))
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal recipient props")
+			}
+
+			_, err = trx.Execute(`
+				INSERT INTO email_outbox (
+					tenant_id, batch_id, locale, template_name, from_name, from_address,
+					to_name, to_address, reply_to, unsubscribe_url, props, recipient_props, custom_template,
+					status, attempts, next_attempt_at, created_at
+				)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, 0, $15, $15)
+			`, tenantID, batchID, locale, c.TemplateName, c.From.Name, c.From.Address,
+				to.Name, to.Address, to.ReplyTo, to.Unsubscribe, string(props), string(recipientProps), customTemplate,
+				enum.EmailPending, now)
+			if err != nil {
+				return errors.Wrap(err, "failed to queue email with template %s", c.TemplateName)
+			}
+		}
+		return nil
+	})
+}
+
+func getDueEmailBatches(ctx context.Context, q *query.GetDueEmailBatches) error {
+	handler := func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		now := time.Now()
+		var deliveries []*dbEmailDelivery
+		err := trx.Select(&deliveries, `
+			WITH due AS (
+				SELECT id
+				FROM email_outbox
+				WHERE status = $1 AND next_attempt_at <= $2
+				ORDER BY id
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			), claimed AS (
+				UPDATE email_outbox e
+				SET next_attempt_at = $4
+				FROM due
+				WHERE e.id = due.id
+				RETURNING e.id, e.tenant_id, e.batch_id, e.locale, e.template_name, e.from_name, e.from_address,
+				          e.to_name, e.to_address, e.reply_to, e.unsubscribe_url, e.props, e.recipient_props, e.custom_template, e.attempts
+			)
+			SELECT * FROM claimed ORDER BY id
+		`, enum.EmailPending, now, q.Limit, now.Add(emailClaimDuration))
+		if err != nil {
+			return errors.Wrap(err, "failed to get due emails")
+		}
+
+		q.Result = make([]*dto.EmailBatch, 0)
+		batches := make(map[string]*dto.EmailBatch)
+		for _, d := range deliveries {
+			batch, ok := batches[d.BatchID]
+			if !ok {
+				batch, err = d.toBatch()
+				if err != nil {
+					return err
+				}
+				batches[d.BatchID] = batch
+				q.Result = append(q.Result, batch)
+			}
+
+			recipientProps, err := decodeEmailPropsJSON(d.RecipientProps)
+			if err != nil {
+				return errors.Wrap(err, "failed to unmarshal recipient props of email %d", d.ID)
+			}
+
+			batch.Recipients = append(batch.Recipients, &dto.BatchRecipient{
+				EmailID:  d.ID,
+				Attempts: d.Attempts,
+				Recipient: dto.Recipient{
+					Name:        d.ToName,
+					Address:     d.ToAddress,
+					Props:       recipientProps,
+					ReplyTo:     d.ReplyTo,
+					Unsubscribe: d.UnsubscribeURL,
+					Locale:      d.Locale,
+				},
+			})
+		}
+		return nil
+	}
+
+	if q.Standalone {
+		return usingOwnTransaction(ctx, handler)
+	}
+	return using(ctx, handler)
+}
+
+func (d *dbEmailDelivery) toBatch() (*dto.EmailBatch, error) {
+	props, err := decodeEmailPropsJSON(d.Props)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal props of email %d", d.ID)
+	}
+
+	batch := &dto.EmailBatch{
+		ID:           d.BatchID,
+		TenantID:     int(d.TenantID.Int64),
+		Locale:       d.Locale,
+		From:         dto.Recipient{Name: d.FromName, Address: d.FromAddress},
+		TemplateName: d.TemplateName,
+		Props:        props,
+	}
+
+	if d.CustomTemplate.Valid {
+		batch.Template = &entity.EmailTemplate{}
+		if err := json.Unmarshal([]byte(d.CustomTemplate.String), batch.Template); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal template of email %d", d.ID)
+		}
+	}
+
+	return batch, nil
+}
+
+func setEmailDeliveryStatus(ctx context.Context, c *cmd.SetEmailDeliveryStatus) error {
+	if len(c.IDs) == 0 {
+		return nil
+	}
+
+	handler := func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		now := time.Now()
+		nextAttemptAt := c.NextAttemptAt
+		if nextAttemptAt.IsZero() {
+			nextAttemptAt = now
+		}
+
+		var sentAt dbx.NullTime
+		if c.Status == enum.EmailSent {
+			sentAt.Time, sentAt.Valid = now, true
+		}
+
+		lastError := sql.NullString{String: c.Error, Valid: c.Error != ""}
+		_, err := trx.Execute(`
+			UPDATE email_outbox
+			SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4, sent_at = $5
+			WHERE id = ANY($1)
+		`, pq.Array(c.IDs), c.Status, lastError, nextAttemptAt, sentAt)
+		if err != nil {
+			return errors.Wrap(err, "failed to set delivery status of emails")
+		}
+		return nil
+	}
+
+	if c.Standalone {
+		return usingOwnTransaction(ctx, handler)
+	}
+	return using(ctx, handler)
+}
+
+func resendEmail(ctx context.Context, c *cmd.ResendEmail) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		rows, err := trx.Execute(`
+			UPDATE email_outbox
+			SET status = $3, attempts = 0, last_error = NULL, next_attempt_at = $4
+			WHERE id = $1 AND tenant_id = $2 AND status = $5
+		`, c.ID, tenant.ID, enum.EmailPending, time.Now(), enum.EmailFailed)
+		if err != nil {
+			return errors.Wrap(err, "failed to resend email %d", c.ID)
+		}
+		if rows == 0 {
+			return app.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func searchOutboxEmails(ctx context.Context, q *query.SearchOutboxEmails) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		condition := ""
+		args := []any{tenant.ID, outboxEmailsPageSize, q.Offset}
+		if q.Status > 0 {
+			condition = "AND status = $4"
+			args = append(args, q.Status)
+		}
+
+		var emails []*dbOutboxEmail
+		err := trx.Select(&emails, fmt.Sprintf(`
+			SELECT id, template_name, to_name, to_address, status, attempts, last_error, next_attempt_at, created_at, sent_at
+			FROM email_outbox
+			WHERE tenant_id = $1 %s
+			ORDER BY id DESC
+			LIMIT $2 OFFSET $3
+		`, condition), args...)
+		if err != nil {
+			return errors.Wrap(err, "failed to search outbox emails")
+		}
+
+		q.Result = make([]*entity.OutboxEmail, len(emails))
+		for i, email := range emails {
+			q.Result[i] = email.toModel()
+		}
+		return nil
+	})
+}
+
+func purgeOutboxEmails(ctx context.Context, c *cmd.PurgeOutboxEmails) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		count, err := trx.Execute(
+			"DELETE FROM email_outbox WHERE status IN ($1, $2) AND created_at <= NOW() - INTERVAL '30 days'",
+			enum.EmailSent, enum.EmailSuppressed,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to purge outbox emails")
+		}
+
+		c.NumOfDeletedEmails = int(count)
+		return nil
+	})
+}
+
+func encodeEmailProps(props dto.Props) dto.Props {
+	encoded := make(dto.Props, len(props))
+	for key, value := range props {
+		encoded[key] = encodeEmailProp(value)
+	}
+	return encoded
+}
+
+func encodeEmailProp(value any) any {
+	switch v := value.(type) {
+	case template.HTML:
+		return dto.Props{htmlPropKey: string(v)}
+	case dto.Props:
+		return encodeEmailProps(v)
+	case map[string]any:
+		return encodeEmailProps(v)
+	case []dto.Props:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i] = encodeEmailProps(item)
+		}
+		return list
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i] = encodeEmailProp(item)
+		}
+		return list
+	}
+	return value
+}
+
+func decodeEmailPropsJSON(data string) (dto.Props, error) {
+	props := dto.Props{}
+	if err := json.Unmarshal([]byte(data), &props); err != nil {
+		return nil, err
+	}
+	return decodeEmailProps(props), nil
+}
+
+func decodeEmailProps(props map[string]any) dto.Props {
+	decoded := make(dto.Props, len(props))
+	for key, value := range props {
+		decoded[key] = decodeEmailProp(value)
+	}
+	return decoded
+}
+
+func decodeEmailProp(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if html, ok := v[htmlPropKey].(string); ok && len(v) == 1 {
+			return template.HTML(html)
+		}
+		return decodeEmailProps(v)
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i] = decodeEmailProp(item)
+		}
+		return list
+	}
+	return value
+}
@@ -0,0 +1,217 @@
+package postgres_test
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func TestEmailOutboxStorage_QueueAndGetDueBatches(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	Expect(bus.Dispatch(demoTenantCtx, &cmd.SendMail{
+		From:         dto.Recipient{Name: "Demonstration"},
+		TemplateName: "email_digest",
+		Props: dto.Props{
+			"siteName": "Demonstration",
+			"posts": []dto.Props{
+				{"title": "Dark mode", "items": []dto.Props{{"content": template.HTML("<p>I agree</p>")}}},
+			},
+		},
+		To: []dto.Recipient{
+			dto.NewRecipient("Jon Snow", "jon.snow@got.com", dto.Props{"content": template.HTML("<b>Hi</b>")}),
+			{Name: "Arya Stark", Address: "arya.stark@got.com", ReplyTo: "reply+1@inbound.fider.io", Unsubscribe: "http://demo.test.fider.io/unsubscribe/abc"},
+		},
+		Template: &entity.EmailTemplate{Kind: "email_digest", Subject: "Digest", Body: "<tr><td>Hi</td></tr>"},
+	})).IsNil()
+
+	due := &query.GetDueEmailBatches{Limit: 10}
+	err := bus.Dispatch(demoTenantCtx, due)
+	Expect(err).IsNil()
+	Expect(due.Result).HasLen(1)
+
+	batch := due.Result[0]
+	Expect(batch.TenantID).Equals(demoTenant.ID)
+	Expect(batch.Locale).Equals("en")
+	Expect(batch.From.Name).Equals("Demonstration")
+	Expect(batch.TemplateName).Equals("email_digest")
+	Expect(batch.Props["siteName"]).Equals("Demonstration")
+	Expect(batch.Template.Subject).Equals("Digest")
+
+	posts := batch.Props["posts"].([]any)
+	Expect(posts).HasLen(1)
+	items := posts[0].(dto.Props)["items"].([]any)
+	Expect(items[0].(dto.Props)["content"]).Equals(template.HTML("<p>I agree</p>"))
+
+	Expect(batch.Recipients).HasLen(2)
+	Expect(batch.Recipients[0].Address).Equals("jon.snow@got.com")
+	Expect(batch.Recipients[0].Attempts).Equals(0)
+	Expect(batch.Recipients[0].Props["content"]).Equals(template.HTML("<b>Hi</b>"))
+	Expect(batch.Recipients[1].Address).Equals("arya.stark@got.com")
+	Expect(batch.Recipients[1].ReplyTo).Equals("reply+1@inbound.fider.io")
+	Expect(batch.Recipients[1].Unsubscribe).Equals("http://demo.test.fider.io/unsubscribe/abc")
+	Expect(batch.Recipients[0].Unsubscribe).Equals("")
+
+	// Claimed emails are not picked up again until their delivery status is recorded
+	due = &query.GetDueEmailBatches{Limit: 10}
+	err = bus.Dispatch(demoTenantCtx, due)
+	Expect(err).IsNil()
+	Expect(due.Result).HasLen(0)
+
+	err = bus.Dispatch(demoTenantCtx,
+		&cmd.SetEmailDeliveryStatus{IDs: []int64{batch.Recipients[0].EmailID}, Status: enum.EmailSent},
+		&cmd.SetEmailDeliveryStatus{IDs: []int64{batch.Recipients[1].EmailID}, Status: enum.EmailPending, Error: "connection refused"},
+	)
+	Expect(err).IsNil()
+
+	due = &query.GetDueEmailBatches{Limit: 10}
+	err = bus.Dispatch(demoTenantCtx, due)
+	Expect(err).IsNil()
+	Expect(due.Result).HasLen(1)
+	Expect(due.Result[0].Recipients).HasLen(1)
+	Expect(due.Result[0].Recipients[0].Address).Equals("arya.stark@got.com")
+	Expect(due.Result[0].Recipients[0].Attempts).Equals(1)
+}
+
+func TestEmailOutboxStorage_QueueByRecipientLocale(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	Expect(bus.Dispatch(demoTenantCtx, &cmd.SendMail{
+		From:         dto.Recipient{Name: "Demonstration"},
+		TemplateName: "new_post",
+		Props:        dto.Props{"title": "Dark mode"},
+		To: []dto.Recipient{
+			{Name: "Jon Snow", Address: "jon.snow@got.com", Locale: "fr"},
+			{Name: "Arya Stark", Address: "arya.stark@got.com"},
+			{Name: "Sansa Stark", Address: "sansa.stark@got.com", Locale: "fr"},
+			{Name: "Hot Pie", Address: "hot.pie@got.com", Locale: "xx"},
+		},
+	})).IsNil()
+
+	due := &query.GetDueEmailBatches{Limit: 10}
+	err := bus.Dispatch(demoTenantCtx, due)
+	Expect(err).IsNil()
+	Expect(due.Result).HasLen(2)
+
+	Expect(due.Result[0].Locale).Equals("fr")
+	Expect(due.Result[0].Props["title"]).Equals("Dark mode")
+	Expect(due.Result[0].Recipients).HasLen(2)
+	Expect(due.Result[0].Recipients[0].Address).Equals("jon.snow@got.com")
+	Expect(due.Result[0].Recipients[1].Address).Equals("sansa.stark@got.com")
+
+	Expect(due.Result[1].Locale).Equals("en")
+	Expect(due.Result[1].Recipients).HasLen(2)
+	Expect(due.Result[1].Recipients[0].Address).Equals("arya.stark@got.com")
+	Expect(due.Result[1].Recipients[1].Address).Equals("hot.pie@got.com")
+}
+
+func TestEmailOutboxStorage_SearchAndResend(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	Expect(bus.Dispatch(demoTenantCtx, &cmd.SendMail{
+		From:         dto.Recipient{Name: "Demonstration"},
+		TemplateName: "new_post",
+		To: []dto.Recipient{
+			dto.NewRecipient("Jon Snow", "jon.snow@got.com", dto.Props{}),
+			dto.NewRecipient("Arya Stark", "arya.stark@got.com", dto.Props{}),
+		},
+	})).IsNil()
+
+	search := &query.SearchOutboxEmails{}
+	err := bus.Dispatch(demoTenantCtx, search)
+	Expect(err).IsNil()
+	Expect(search.Result).HasLen(2)
+	Expect(search.Result[0].ToAddress).Equals("arya.stark@got.com")
+	Expect(search.Result[0].Status).Equals(enum.EmailPending)
+	Expect(search.Result[1].ToAddress).Equals("jon.snow@got.com")
+
+	aryaEmailID, jonEmailID := search.Result[0].ID, search.Result[1].ID
+	err = bus.Dispatch(demoTenantCtx,
+		&cmd.SetEmailDeliveryStatus{IDs: []int64{jonEmailID}, Status: enum.EmailSent},
+		&cmd.SetEmailDeliveryStatus{IDs: []int64{aryaEmailID}, Status: enum.EmailFailed, Error: "mailbox unavailable"},
+	)
+	Expect(err).IsNil()
+
+	search = &query.SearchOutboxEmails{Status: enum.EmailFailed}
+	err = bus.Dispatch(demoTenantCtx, search)
+	Expect(err).IsNil()
+	Expect(search.Result).HasLen(1)
+	Expect(search.Result[0].ID).Equals(aryaEmailID)
+	Expect(search.Result[0].Attempts).Equals(1)
+	Expect(search.Result[0].LastError).Equals("mailbox unavailable")
+	Expect(search.Result[0].SentAt).IsNil()
+
+	search = &query.SearchOutboxEmails{Status: enum.EmailSent}
+	err = bus.Dispatch(demoTenantCtx, search)
+	Expect(err).IsNil()
+	Expect(search.Result).HasLen(1)
+	Expect(search.Result[0].SentAt).IsNotNil()
+
+	search = &query.SearchOutboxEmails{}
+	err = bus.Dispatch(avengersTenantCtx, search)
+	Expect(err).IsNil()
+	Expect(search.Result).HasLen(0)
+
+	err = bus.Dispatch(avengersTenantCtx, &cmd.ResendEmail{ID: aryaEmailID})
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.ResendEmail{ID: jonEmailID})
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.ResendEmail{ID: aryaEmailID})
+	Expect(err).IsNil()
+
+	search = &query.SearchOutboxEmails{Status: enum.EmailPending}
+	err = bus.Dispatch(demoTenantCtx, search)
+	Expect(err).IsNil()
+	Expect(search.Result).HasLen(1)
+	Expect(search.Result[0].ID).Equals(aryaEmailID)
+	Expect(search.Result[0].Attempts).Equals(0)
+	Expect(search.Result[0].LastError).Equals("")
+}
+
+func TestEmailOutboxStorage_Purge(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	Expect(bus.Dispatch(demoTenantCtx, &cmd.SendMail{
+		From:         dto.Recipient{Name: "Demonstration"},
+		TemplateName: "new_post",
+		To: []dto.Recipient{
+			dto.NewRecipient("Jon Snow", "jon.snow@got.com", dto.Props{}),
+			dto.NewRecipient("Arya Stark", "arya.stark@got.com", dto.Props{}),
+		},
+	})).IsNil()
+
+	_, err := trx.Execute("UPDATE email_outbox SET created_at = NOW() - INTERVAL '31 days'")
+	Expect(err).IsNil()
+
+	search := &query.SearchOutboxEmails{}
+	err = bus.Dispatch(demoTenantCtx, search)
+	Expect(err).IsNil()
+	err = bus.Dispatch(demoTenantCtx, &cmd.SetEmailDeliveryStatus{IDs: []int64{search.Result[0].ID}, Status: enum.EmailSent})
+	Expect(err).IsNil()
+
+	purge := &cmd.PurgeOutboxEmails{}
+	err = bus.Dispatch(demoTenantCtx, purge)
+	Expect(err).IsNil()
+	Expect(purge.NumOfDeletedEmails).Equals(1)
+
+	search = &query.SearchOutboxEmails{}
+	err = bus.Dispatch(demoTenantCtx, search)
+	Expect(err).IsNil()
+	Expect(search.Result).HasLen(1)
+	Expect(search.Result[0].Status).Equals(enum.EmailPending)
+}
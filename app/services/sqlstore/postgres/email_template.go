@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+type dbEmailTemplate struct {
+	Kind      string    `db:"kind"`
+	Subject   string    `db:"subject"`
+	Body      string    `db:"body"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (t *dbEmailTemplate) toModel() *entity.EmailTemplate {
+	return &entity.EmailTemplate{
+		Kind:      t.Kind,
+		Subject:   t.Subject,
+		Body:      t.Body,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+func getEmailTemplate(ctx context.Context, q *query.GetEmailTemplate) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		template := dbEmailTemplate{}
+		err := trx.Get(&template, `
+			SELECT kind, subject, body, updated_at
+			FROM email_templates
+			WHERE tenant_id = $1 AND kind = $2
+		`, tenant.ID, q.Kind)
+		if err != nil {
+			return errors.Wrap(err, "failed to get email template '%s'", q.Kind)
+		}
+
+		q.Result = template.toModel()
+		return nil
+	})
+}
+
+func listEmailTemplates(ctx context.Context, q *query.ListEmailTemplates) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		var templates []*dbEmailTemplate
+		err := trx.Select(&templates, `
+			SELECT kind, subject, body, updated_at
+			FROM email_templates
+			WHERE tenant_id = $1
+			ORDER BY kind
+		`, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to list email templates")
+		}
+
+		q.Result = make([]*entity.EmailTemplate, len(templates))
+		for i, template := range templates {
+			q.Result[i] = template.toModel()
+		}
+		return nil
+	})
+}
+
+func saveEmailTemplate(ctx context.Context, c *cmd.SaveEmailTemplate) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute(`
+			INSERT INTO email_templates (tenant_id, kind, subject, body, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (tenant_id, kind) DO UPDATE
+			SET subject = $3, body = $4, updated_at = $5
+		`, tenant.ID, c.Kind, c.Subject, c.Body, time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to save email template '%s'", c.Kind)
+		}
+		return nil
+	})
+}
+
+func deleteEmailTemplate(ctx context.Context, c *cmd.DeleteEmailTemplate) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute(
+			"DELETE FROM email_templates WHERE tenant_id = $1 AND kind = $2",
+			tenant.ID, c.Kind,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete email template '%s'", c.Kind)
+		}
+		return nil
+	})
+}
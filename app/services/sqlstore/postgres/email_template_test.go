@@ -0,0 +1,49 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func TestEmailTemplateStorage_SaveGetAndDelete(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	getTemplate := &query.GetEmailTemplate{Kind: "new_post"}
+	err := bus.Dispatch(demoTenantCtx, getTemplate)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.SaveEmailTemplate{Kind: "new_post", Subject: "New: {{ .title }}", Body: "<tr><td>{{ .content }}</td></tr>"})
+	Expect(err).IsNil()
+	err = bus.Dispatch(demoTenantCtx, &cmd.SaveEmailTemplate{Kind: "new_post", Subject: "Idea: {{ .title }}", Body: "<tr><td>{{ .content }}</td></tr>"})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(demoTenantCtx, getTemplate)
+	Expect(err).IsNil()
+	Expect(getTemplate.Result.Kind).Equals("new_post")
+	Expect(getTemplate.Result.Subject).Equals("Idea: {{ .title }}")
+	Expect(getTemplate.Result.Body).Equals("<tr><td>{{ .content }}</td></tr>")
+
+	listTemplates := &query.ListEmailTemplates{}
+	err = bus.Dispatch(demoTenantCtx, listTemplates)
+	Expect(err).IsNil()
+	Expect(listTemplates.Result).HasLen(1)
+
+	listTemplates = &query.ListEmailTemplates{}
+	err = bus.Dispatch(avengersTenantCtx, listTemplates)
+	Expect(err).IsNil()
+	Expect(listTemplates.Result).HasLen(0)
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.DeleteEmailTemplate{Kind: "new_post"})
+	Expect(err).IsNil()
+
+	getTemplate = &query.GetEmailTemplate{Kind: "new_post"}
+	err = bus.Dispatch(demoTenantCtx, getTemplate)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
@@ -6,12 +6,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/models/entity"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/dbx"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/realtime"
 	"github.com/lib/pq"
 )
 
@@ -139,6 +142,20 @@ func addNewNotification(ctx context.Context, c *cmd.AddNewNotification) error {
 			return errors.Wrap(err, "failed to insert notification")
 		}
 
+		err = internalPublishRealtimeEvent(trx, realtime.Event{
+			TenantID: tenant.ID,
+			UserID:   c.User.ID,
+			Type:     enum.RealtimeNotification,
+			Data: dto.Props{
+				"id":    notification.ID,
+				"title": notification.Title,
+				"link":  notification.Link,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
 		c.Result = notification
 		return nil
 	})
@@ -180,10 +197,11 @@ func getActiveSubscribers(ctx context.Context, q *query.GetActiveSubscribers) er
 			supressionCondition = "AND u.email_supressed_at IS NULL"
 		}
 
+		// Users that never changed their settings only get notified on the channels enabled by default
 		// If the event doesn't require a subscription, notify everyone
 		if len(q.Event.RequiresSubscriptionUserRoles) == 0 {
 			err = trx.Select(&users, fmt.Sprintf(`
-				SELECT DISTINCT u.id, u.name, u.email, u.tenant_id, u.role, u.status
+				SELECT DISTINCT u.id, u.name, u.email, u.tenant_id, u.role, u.status, u.locale
 				FROM users u
 				LEFT JOIN user_settings set
 				ON set.user_id = u.id
@@ -193,7 +211,7 @@ func getActiveSubscribers(ctx context.Context, q *query.GetActiveSubscribers) er
 				AND u.status = $5
 				%s
 				AND (
-					(set.value IS NULL AND u.role = ANY($3))
+					(set.value IS NULL AND u.role = ANY($3) AND CAST($6 AS integer) & $4 > 0)
 					OR CAST(set.value AS integer) & $4 > 0
 				)
 				ORDER by u.id`, supressionCondition),
@@ -202,11 +220,12 @@ func getActiveSubscribers(ctx context.Context, q *query.GetActiveSubscribers) er
 				pq.Array(q.Event.DefaultEnabledUserRoles),
 				q.Channel,
 				enum.UserActive,
+				q.Event.DefaultSettingValue,
 			)
 		} else {
 			// If the event requires a subscription, notify only those who subscribed
 			err = trx.Select(&users, fmt.Sprintf(`
-				SELECT DISTINCT u.id, u.name, u.email, u.tenant_id, u.role, u.status
+				SELECT DISTINCT u.id, u.name, u.email, u.tenant_id, u.role, u.status, u.locale
 				FROM users u
 				LEFT JOIN post_subscribers sub
 				ON sub.user_id = u.id
@@ -221,7 +240,7 @@ func getActiveSubscribers(ctx context.Context, q *query.GetActiveSubscribers) er
 				%s
 				AND ( sub.status = $2 OR (sub.status IS NULL AND NOT u.role = ANY($7)) )
 				AND (
-					(set.value IS NULL AND u.role = ANY($5))
+					(set.value IS NULL AND u.role = ANY($5) AND CAST($9 AS integer) & $6 > 0)
 					OR CAST(set.value AS integer) & $6 > 0
 				)
 				ORDER by u.id`, supressionCondition),
@@ -233,6 +252,7 @@ func getActiveSubscribers(ctx context.Context, q *query.GetActiveSubscribers) er
 				q.Channel,
 				pq.Array(q.Event.RequiresSubscriptionUserRoles),
 				enum.UserActive,
+				q.Event.DefaultSettingValue,
 			)
 		}
 
@@ -276,3 +296,52 @@ func supressEmail(ctx context.Context, c *cmd.SupressEmail) error {
 		return nil
 	})
 }
+
+type dbSupressedEmail struct {
+	UserID      int       `db:"id"`
+	Name        string    `db:"name"`
+	Email       string    `db:"email"`
+	SupressedAt time.Time `db:"email_supressed_at"`
+}
+
+func getSupressedEmails(ctx context.Context, q *query.GetSupressedEmails) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		var emails []*dbSupressedEmail
+		err := trx.Select(&emails, `
+			SELECT id, name, email, email_supressed_at
+			FROM users
+			WHERE tenant_id = $1 AND email_supressed_at IS NOT NULL
+			ORDER BY email_supressed_at DESC
+		`, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get supressed emails")
+		}
+
+		q.Result = make([]*entity.SupressedEmail, len(emails))
+		for i, email := range emails {
+			q.Result[i] = &entity.SupressedEmail{
+				UserID:      email.UserID,
+				Name:        email.Name,
+				Email:       email.Email,
+				SupressedAt: email.SupressedAt,
+			}
+		}
+		return nil
+	})
+}
+
+func clearEmailSupression(ctx context.Context, c *cmd.ClearEmailSupression) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		rows, err := trx.Execute(
+			"UPDATE users SET email_supressed_at = NULL WHERE id = $1 AND tenant_id = $2 AND email_supressed_at IS NOT NULL",
+			c.UserID, tenant.ID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to clear email supression of user %d", c.UserID)
+		}
+		if rows == 0 {
+			return app.ErrNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+type dbOrganization struct {
+	ID         int            `db:"id"`
+	ExternalID sql.NullString `db:"external_id"`
+	Name       string         `db:"name"`
+	Plan       string         `db:"plan"`
+	Segment    string         `db:"segment"`
+	MRR        float64        `db:"mrr"`
+	CreatedAt  time.Time      `db:"created_at"`
+	UpdatedAt  time.Time      `db:"updated_at"`
+}
+
+func (o *dbOrganization) toModel() *entity.Organization {
+	return &entity.Organization{
+		ID:         o.ID,
+		ExternalID: o.ExternalID.String,
+		Name:       o.Name,
+		Plan:       o.Plan,
+		Segment:    o.Segment,
+		MRR:        o.MRR,
+		CreatedAt:  o.CreatedAt,
+		UpdatedAt:  o.UpdatedAt,
+	}
+}
+
+func getOrganizationByID(ctx context.Context, q *query.GetOrganizationByID) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		organization, err := queryOrganizationByID(trx, tenant, q.OrganizationID)
+		q.Result = organization
+		return err
+	})
+}
+
+func getAllOrganizations(ctx context.Context, q *query.GetAllOrganizations) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		organizations := []*dbOrganization{}
+		err := trx.Select(&organizations, `
+			SELECT id, external_id, name, plan, segment, mrr, created_at, updated_at
+			FROM organizations
+			WHERE tenant_id = $1
+			ORDER BY name
+		`, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get all organizations")
+		}
+
+		q.Result = make([]*entity.Organization, len(organizations))
+		for i, organization := range organizations {
+			q.Result[i] = organization.toModel()
+		}
+		return nil
+	})
+}
+
+func saveOrganization(ctx context.Context, c *cmd.SaveOrganization) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		c.Result = nil
+		now := time.Now()
+		externalID := sql.NullString{String: c.ExternalID, Valid: c.ExternalID != ""}
+
+		var id int
+		if c.ID > 0 {
+			_, err := trx.Execute(`
+				UPDATE organizations SET external_id = $1, name = $2, plan = $3, segment = $4, mrr = $5, updated_at = $6
+				WHERE id = $7 AND tenant_id = $8
+			`, externalID, c.Name, c.Plan, c.Segment, c.MRR, now, c.ID, tenant.ID)
+			if err != nil {
+				return errors.Wrap(err, "failed to update organization")
+			}
+			id = c.ID
+		} else {
+			err := trx.Scalar(&id, `
+				INSERT INTO organizations (tenant_id, external_id, name, plan, segment, mrr, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+				ON CONFLICT (tenant_id, external_id) WHERE external_id IS NOT NULL
+				DO UPDATE SET name = EXCLUDED.name, plan = EXCLUDED.plan, segment = EXCLUDED.segment, mrr = EXCLUDED.mrr, updated_at = EXCLUDED.updated_at
+				RETURNING id
+			`, tenant.ID, externalID, c.Name, c.Plan, c.Segment, c.MRR, now)
+			if err != nil {
+				return errors.Wrap(err, "failed to add new organization")
+			}
+		}
+
+		organization, err := queryOrganizationByID(trx, tenant, id)
+		c.Result = organization
+		return err
+	})
+}
+
+func deleteOrganization(ctx context.Context, c *cmd.DeleteOrganization) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute(`UPDATE users SET organization_id = NULL WHERE organization_id = $1 AND tenant_id = $2`, c.Organization.ID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to remove users from organization with id '%d'", c.Organization.ID)
+		}
+
+		_, err = trx.Execute(`DELETE FROM organizations WHERE id = $1 AND tenant_id = $2`, c.Organization.ID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete organization with id '%d'", c.Organization.ID)
+		}
+		return nil
+	})
+}
+
+func setUserOrganization(ctx context.Context, c *cmd.SetUserOrganization) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		organizationID := sql.NullInt64{Int64: int64(c.OrganizationID), Valid: c.OrganizationID > 0}
+		_, err := trx.Execute(
+			`UPDATE users SET organization_id = $1 WHERE id = $2 AND tenant_id = $3`,
+			organizationID, c.UserID, tenant.ID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to set organization of user with id '%d'", c.UserID)
+		}
+		return nil
+	})
+}
+
+func queryOrganizationByID(trx *dbx.Trx, tenant *entity.Tenant, id int) (*entity.Organization, error) {
+	organization := dbOrganization{}
+
+	err := trx.Get(&organization, `
+		SELECT id, external_id, name, plan, segment, mrr, created_at, updated_at
+		FROM organizations
+		WHERE tenant_id = $1 AND id = $2
+	`, tenant.ID, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get organization with id '%d'", id)
+	}
+
+	return organization.toModel(), nil
+}
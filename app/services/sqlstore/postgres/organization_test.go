@@ -0,0 +1,124 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func TestOrganizationStorage_SaveAndGet(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	saveOrganization := &cmd.SaveOrganization{Name: "Winterfell Inc.", Plan: "enterprise", Segment: "north", MRR: 1250.5}
+	err := bus.Dispatch(jonSnowCtx, saveOrganization)
+	Expect(err).IsNil()
+	Expect(saveOrganization.Result.ID).NotEquals(0)
+
+	getOrganization := &query.GetOrganizationByID{OrganizationID: saveOrganization.Result.ID}
+	err = bus.Dispatch(jonSnowCtx, getOrganization)
+	Expect(err).IsNil()
+	Expect(getOrganization.Result.Name).Equals("Winterfell Inc.")
+	Expect(getOrganization.Result.ExternalID).Equals("")
+	Expect(getOrganization.Result.Plan).Equals("enterprise")
+	Expect(getOrganization.Result.Segment).Equals("north")
+	Expect(getOrganization.Result.MRR).Equals(1250.5)
+
+	getOrganization = &query.GetOrganizationByID{OrganizationID: saveOrganization.Result.ID}
+	err = bus.Dispatch(avengersTenantCtx, getOrganization)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestOrganizationStorage_UpsertByExternalID(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	first := &cmd.SaveOrganization{ExternalID: "crm-42", Name: "Winterfell Inc.", MRR: 100}
+	err := bus.Dispatch(jonSnowCtx, first)
+	Expect(err).IsNil()
+
+	second := &cmd.SaveOrganization{ExternalID: "crm-42", Name: "Winterfell Ltd.", MRR: 200}
+	err = bus.Dispatch(jonSnowCtx, second)
+	Expect(err).IsNil()
+	Expect(second.Result.ID).Equals(first.Result.ID)
+	Expect(second.Result.Name).Equals("Winterfell Ltd.")
+	Expect(second.Result.MRR).Equals(float64(200))
+
+	allOrganizations := &query.GetAllOrganizations{}
+	err = bus.Dispatch(jonSnowCtx, allOrganizations)
+	Expect(err).IsNil()
+	Expect(allOrganizations.Result).HasLen(1)
+}
+
+func TestOrganizationStorage_Delete(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	saveOrganization := &cmd.SaveOrganization{Name: "Winterfell Inc."}
+	err := bus.Dispatch(jonSnowCtx, saveOrganization)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.SetUserOrganization{UserID: aryaStark.ID, OrganizationID: saveOrganization.Result.ID})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.DeleteOrganization{Organization: saveOrganization.Result})
+	Expect(err).IsNil()
+
+	getOrganization := &query.GetOrganizationByID{OrganizationID: saveOrganization.Result.ID}
+	err = bus.Dispatch(jonSnowCtx, getOrganization)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestOrganizationStorage_MostValuablePosts(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	small := &cmd.SaveOrganization{Name: "Night's Watch", MRR: 50}
+	large := &cmd.SaveOrganization{Name: "Winterfell Inc.", MRR: 1000}
+	err := bus.Dispatch(jonSnowCtx, small, large)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx,
+		&cmd.SetUserOrganization{UserID: jonSnow.ID, OrganizationID: small.Result.ID},
+		&cmd.SetUserOrganization{UserID: aryaStark.ID, OrganizationID: large.Result.ID},
+	)
+	Expect(err).IsNil()
+
+	popular := &cmd.AddNewPost{Title: "My popular post", Description: "voted by the watch"}
+	valuable := &cmd.AddNewPost{Title: "My valuable post", Description: "voted by winterfell"}
+	err = bus.Dispatch(jonSnowCtx, popular, valuable)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx,
+		&cmd.AddVote{Post: popular.Result, User: jonSnow},
+		&cmd.AddVote{Post: valuable.Result, User: aryaStark},
+	)
+	Expect(err).IsNil()
+
+	search := &query.SearchPosts{View: "most-valuable"}
+	err = bus.Dispatch(jonSnowCtx, search)
+	Expect(err).IsNil()
+	Expect(search.Result).HasLen(2)
+	Expect(search.Result[0].ID).Equals(valuable.Result.ID)
+	Expect(search.Result[0].OrganizationsCount).Equals(1)
+	Expect(search.Result[0].OrganizationsRevenue).Equals(float64(1000))
+	Expect(search.Result[1].ID).Equals(popular.Result.ID)
+	Expect(search.Result[1].OrganizationsRevenue).Equals(float64(50))
+
+	search = &query.SearchPosts{View: "all", MinRevenue: 100}
+	err = bus.Dispatch(jonSnowCtx, search)
+	Expect(err).IsNil()
+	Expect(search.Result).HasLen(1)
+	Expect(search.Result[0].ID).Equals(valuable.Result.ID)
+
+	search = &query.SearchPosts{View: "all", MinRevenue: 100}
+	err = bus.Dispatch(aryaStarkCtx, search)
+	Expect(err).IsNil()
+	Expect(search.Result).HasLen(2)
+	Expect(search.Result[0].OrganizationsRevenue).Equals(float64(0))
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/lib/pq"
+)
+
+type dbUserPasskey struct {
+	ID           int          `db:"id"`
+	UserID       int          `db:"user_id"`
+	Name         string       `db:"name"`
+	CredentialID string       `db:"credential_id"`
+	PublicKey    []byte       `db:"public_key"`
+	SignCount    int64        `db:"sign_count"`
+	Transports   []string     `db:"transports"`
+	CreatedAt    time.Time    `db:"created_at"`
+	LastUsedAt   dbx.NullTime `db:"last_used_at"`
+}
+
+func (p *dbUserPasskey) toModel() *entity.UserPasskey {
+	passkey := &entity.UserPasskey{
+		ID:           p.ID,
+		UserID:       p.UserID,
+		Name:         p.Name,
+		CredentialID: p.CredentialID,
+		PublicKey:    p.PublicKey,
+		SignCount:    uint32(p.SignCount),
+		Transports:   p.Transports,
+		CreatedAt:    p.CreatedAt,
+	}
+
+	if p.LastUsedAt.Valid {
+		passkey.LastUsedAt = &p.LastUsedAt.Time
+	}
+
+	return passkey
+}
+
+func addUserPasskey(ctx context.Context, c *cmd.AddUserPasskey) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		c.Passkey.CreatedAt = time.Now()
+		if c.Passkey.Transports == nil {
+			c.Passkey.Transports = []string{}
+		}
+
+		err := trx.Get(&c.Passkey.ID, `
+			INSERT INTO user_passkeys (tenant_id, user_id, name, credential_id, public_key, sign_count, transports, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id
+		`, tenant.ID, c.Passkey.UserID, c.Passkey.Name, c.Passkey.CredentialID, c.Passkey.PublicKey,
+			int64(c.Passkey.SignCount), pq.Array(c.Passkey.Transports), c.Passkey.CreatedAt)
+		if err != nil {
+			return errors.Wrap(err, "failed to add passkey to user with id '%d'", c.Passkey.UserID)
+		}
+		return nil
+	})
+}
+
+func deleteCurrentUserPasskey(ctx context.Context, c *cmd.DeleteCurrentUserPasskey) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute(
+			"DELETE FROM user_passkeys WHERE id = $1 AND user_id = $2 AND tenant_id = $3",
+			c.ID, user.ID, tenant.ID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete passkey with id '%d'", c.ID)
+		}
+		return nil
+	})
+}
+
+func setPasskeyAsUsed(ctx context.Context, c *cmd.SetPasskeyAsUsed) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute(
+			"UPDATE user_passkeys SET sign_count = $1, last_used_at = $2 WHERE id = $3 AND tenant_id = $4",
+			int64(c.SignCount), time.Now(), c.ID, tenant.ID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to update passkey with id '%d'", c.ID)
+		}
+		return nil
+	})
+}
+
+func listUserPasskeys(ctx context.Context, q *query.ListUserPasskeys) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		var passkeys []*dbUserPasskey
+		err := trx.Select(&passkeys, `
+			SELECT id, user_id, name, credential_id, public_key, sign_count, transports, created_at, last_used_at
+			FROM user_passkeys
+			WHERE user_id = $1 AND tenant_id = $2
+			ORDER BY id`, q.UserID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to list passkeys of user with id '%d'", q.UserID)
+		}
+
+		q.Result = make([]*entity.UserPasskey, len(passkeys))
+		for i, passkey := range passkeys {
+			q.Result[i] = passkey.toModel()
+		}
+		return nil
+	})
+}
+
+func getPasskeyByCredentialID(ctx context.Context, q *query.GetPasskeyByCredentialID) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		passkey := &dbUserPasskey{}
+		err := trx.Get(passkey, `
+			SELECT id, user_id, name, credential_id, public_key, sign_count, transports, created_at, last_used_at
+			FROM user_passkeys
+			WHERE credential_id = $1 AND tenant_id = $2`, q.CredentialID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get passkey by credential id")
+		}
+
+		q.Result = passkey.toModel()
+		return nil
+	})
+}
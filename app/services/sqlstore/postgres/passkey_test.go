@@ -0,0 +1,96 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func TestPasskeyStorage_AddListAndDelete(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	passkey := &entity.UserPasskey{
+		UserID:       jonSnow.ID,
+		Name:         "My Phone",
+		CredentialID: "cred1",
+		PublicKey:    []byte{1, 2, 3},
+		Transports:   []string{"internal"},
+	}
+	err := bus.Dispatch(jonSnowCtx, &cmd.AddUserPasskey{Passkey: passkey})
+	Expect(err).IsNil()
+	Expect(passkey.ID).NotEquals(0)
+
+	list := &query.ListUserPasskeys{UserID: jonSnow.ID}
+	err = bus.Dispatch(jonSnowCtx, list)
+	Expect(err).IsNil()
+	Expect(list.Result).HasLen(1)
+	Expect(list.Result[0].Name).Equals("My Phone")
+	Expect(list.Result[0].PublicKey).Equals([]byte{1, 2, 3})
+	Expect(list.Result[0].Transports).Equals([]string{"internal"})
+	Expect(list.Result[0].LastUsedAt).IsNil()
+
+	// Other users cannot delete it
+	err = bus.Dispatch(aryaStarkCtx, &cmd.DeleteCurrentUserPasskey{ID: passkey.ID})
+	Expect(err).IsNil()
+	err = bus.Dispatch(jonSnowCtx, list)
+	Expect(err).IsNil()
+	Expect(list.Result).HasLen(1)
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.DeleteCurrentUserPasskey{ID: passkey.ID})
+	Expect(err).IsNil()
+	err = bus.Dispatch(jonSnowCtx, list)
+	Expect(err).IsNil()
+	Expect(list.Result).HasLen(0)
+}
+
+func TestPasskeyStorage_GetByCredentialID(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	passkey := &entity.UserPasskey{
+		UserID:       jonSnow.ID,
+		Name:         "YubiKey",
+		CredentialID: "cred1",
+		PublicKey:    []byte{1, 2, 3},
+	}
+	err := bus.Dispatch(jonSnowCtx, &cmd.AddUserPasskey{Passkey: passkey})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.SetPasskeyAsUsed{ID: passkey.ID, SignCount: 5})
+	Expect(err).IsNil()
+
+	getPasskey := &query.GetPasskeyByCredentialID{CredentialID: "cred1"}
+	err = bus.Dispatch(demoTenantCtx, getPasskey)
+	Expect(err).IsNil()
+	Expect(getPasskey.Result.ID).Equals(passkey.ID)
+	Expect(getPasskey.Result.UserID).Equals(jonSnow.ID)
+	Expect(getPasskey.Result.SignCount).Equals(uint32(5))
+	Expect(getPasskey.Result.LastUsedAt).IsNotNil()
+
+	getPasskey = &query.GetPasskeyByCredentialID{CredentialID: "cred1"}
+	err = bus.Dispatch(avengersTenantCtx, getPasskey)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestTenantStorage_UpdatePasskeySettings(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	Expect(demoTenant.PasskeyMode).Equals(enum.PasskeyOptional)
+
+	err := bus.Dispatch(demoTenantCtx, &cmd.UpdateTenantPasskeySettings{PasskeyMode: enum.PasskeyRequired})
+	Expect(err).IsNil()
+
+	getDemo := &query.GetTenantByDomain{Domain: "demo"}
+	err = bus.Dispatch(demoTenantCtx, getDemo)
+	Expect(err).IsNil()
+	Expect(getDemo.Result.PasskeyMode).Equals(enum.PasskeyRequired)
+}
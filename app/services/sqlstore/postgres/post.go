@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models/entity"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
@@ -33,6 +34,8 @@ type dbPost struct {
 	CommentsCount  int            `db:"comments_count"`
 	RecentVotes    int            `db:"recent_votes_count"`
 	RecentComments int            `db:"recent_comments_count"`
+	Organizations  int            `db:"organizations_count"`
+	Revenue        float64        `db:"organizations_revenue"`
 	Status         int            `db:"status"`
 	Response       sql.NullString `db:"response"`
 	RespondedAt    dbx.NullTime   `db:"response_date"`
@@ -42,6 +45,8 @@ type dbPost struct {
 	OriginalSlug   sql.NullString `db:"original_slug"`
 	OriginalStatus sql.NullInt64  `db:"original_status"`
 	Tags           []string       `db:"tags"`
+	PendingReview  bool           `db:"pending_review"`
+	IsHidden       bool           `db:"is_hidden"`
 }
 
 func (i *dbPost) toModel(ctx context.Context) *entity.Post {
@@ -58,6 +63,14 @@ func (i *dbPost) toModel(ctx context.Context) *entity.Post {
 		Status:        enum.PostStatus(i.Status),
 		User:          i.User.toModel(ctx),
 		Tags:          i.Tags,
+		PendingReview: i.PendingReview,
+		IsHidden:      i.IsHidden,
+	}
+
+	// Customer data is only visible to staff members
+	if user, ok := ctx.Value(app.UserCtxKey).(*entity.User); ok && user.IsCollaborator() {
+		post.OrganizationsCount = i.Organizations
+		post.OrganizationsRevenue = i.Revenue
 	}
 
 	if i.Response.Valid {
@@ -103,6 +116,8 @@ var (
 															AND posts.tenant_id = comments.tenant_id
 															WHERE posts.tenant_id = $1
 															AND comments.deleted_at IS NULL
+															AND comments.pending_review = false
+															AND comments.is_hidden = false
 															GROUP BY post_id
 													),
 													agg_votes AS (
@@ -116,7 +131,7 @@ var (
 															AND posts.tenant_id = post_votes.tenant_id
 															WHERE posts.tenant_id = $1
 															GROUP BY post_id
-													)
+													)%s
 													SELECT p.id, 
 																p.number, 
 																p.title, 
@@ -127,7 +142,10 @@ var (
 																COALESCE(agg_c.all, 0) as comments_count,
 																COALESCE(agg_s.recent, 0) AS recent_votes_count,
 																COALESCE(agg_c.recent, 0) AS recent_comments_count,																
+																%s
 																p.status, 
+																p.pending_review,
+																p.is_hidden,
 																u.id AS user_id, 
 																u.name AS user_name, 
 																u.email AS user_email,
@@ -164,9 +182,37 @@ var (
 													ON agg_c.post_id = p.id
 													LEFT JOIN agg_votes agg_s
 													ON agg_s.post_id = p.id
+													%s
 													LEFT JOIN agg_tags agg_t 
 													ON agg_t.post_id = p.id
-													WHERE p.status != ` + strconv.Itoa(int(enum.PostDeleted)) + ` AND %s`
+													WHERE p.status != ` + strconv.Itoa(int(enum.PostDeleted)) + ` AND %s AND %s`
+
+	// Customer data of the voters is only aggregated for staff members, everyone else gets zeros
+	sqlAggOrganizations = `,
+													agg_organizations AS (
+															SELECT 
+																	post_id, 
+																	COUNT(*) as all,
+																	SUM(mrr) as revenue
+															FROM (
+																	SELECT DISTINCT post_votes.post_id, organizations.id, organizations.mrr
+																	FROM post_votes
+																	INNER JOIN users
+																	ON users.id = post_votes.user_id
+																	AND users.tenant_id = post_votes.tenant_id
+																	INNER JOIN organizations
+																	ON organizations.id = users.organization_id
+																	AND organizations.tenant_id = users.tenant_id
+																	WHERE post_votes.tenant_id = $1
+															) AS voters
+															GROUP BY post_id
+													)`
+	sqlOrganizationsColumns = `COALESCE(agg_o.all, 0) AS organizations_count,
+																COALESCE(agg_o.revenue, 0) AS organizations_revenue,`
+	sqlOrganizationsJoin = `LEFT JOIN agg_organizations agg_o
+													ON agg_o.post_id = p.id`
+	sqlNoOrganizationsColumns = `0 AS organizations_count,
+																0 AS organizations_revenue,`
 )
 
 func postIsReferenced(ctx context.Context, q *query.PostIsReferenced) error {
@@ -273,7 +319,7 @@ func countPostPerStatus(ctx context.Context, q *query.CountPostPerStatus) error
 
 		q.Result = make(map[enum.PostStatus]int)
 		stats := []*dbStatusCount{}
-		err := trx.Select(&stats, "SELECT status, COUNT(*) AS count FROM posts WHERE tenant_id = $1 GROUP BY status", tenant.ID)
+		err := trx.Select(&stats, "SELECT status, COUNT(*) AS count FROM posts WHERE tenant_id = $1 AND pending_review = false AND is_hidden = false GROUP BY status", tenant.ID)
 		if err != nil {
 			return errors.Wrap(err, "failed to count posts per status")
 		}
@@ -289,9 +335,9 @@ func addNewPost(ctx context.Context, c *cmd.AddNewPost) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
 		var id int
 		err := trx.Get(&id,
-			`INSERT INTO posts (title, slug, number, description, tenant_id, user_id, created_at, status) 
-			 VALUES ($1, $2, (SELECT COALESCE(MAX(number), 0) + 1 FROM posts p WHERE p.tenant_id = $4), $3, $4, $5, $6, 0) 
-			 RETURNING id`, c.Title, slug.Make(c.Title), c.Description, tenant.ID, user.ID, time.Now())
+			`INSERT INTO posts (title, slug, number, description, tenant_id, user_id, created_at, status, pending_review) 
+			 VALUES ($1, $2, (SELECT COALESCE(MAX(number), 0) + 1 FROM posts p WHERE p.tenant_id = $4), $3, $4, $5, $6, 0, $7) 
+			 RETURNING id`, c.Title, slug.Make(c.Title), c.Description, tenant.ID, user.ID, time.Now(), c.PendingReview)
 		if err != nil {
 			return errors.Wrap(err, "failed add new post")
 		}
@@ -310,6 +356,28 @@ func addNewPost(ctx context.Context, c *cmd.AddNewPost) error {
 	})
 }
 
+func approvePost(ctx context.Context, c *cmd.ApprovePost) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute(`UPDATE posts SET pending_review = false WHERE id = $1 AND tenant_id = $2`, c.Post.ID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to approve post")
+		}
+		c.Post.PendingReview = false
+		return nil
+	})
+}
+
+func hidePost(ctx context.Context, c *cmd.HidePost) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute(`UPDATE posts SET is_hidden = true WHERE id = $1 AND tenant_id = $2`, c.Post.ID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to hide post")
+		}
+		c.Post.IsHidden = true
+		return nil
+	})
+}
+
 func updatePost(ctx context.Context, c *cmd.UpdatePost) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
 		_, err := trx.Execute(`UPDATE posts SET title = $1, slug = $2, description = $3 
@@ -374,6 +442,19 @@ func searchPosts(ctx context.Context, q *query.SearchPosts) error {
 			}
 		}
 
+		// Customer data can only be used by staff members to sort and filter posts
+		organizationsCondition := ""
+		if user != nil && user.IsCollaborator() {
+			if q.MinOrganizations > 0 {
+				organizationsCondition += fmt.Sprintf(" AND organizations_count >= %d", q.MinOrganizations)
+			}
+			if q.MinRevenue > 0 {
+				organizationsCondition += fmt.Sprintf(" AND organizations_revenue >= %s", strconv.FormatFloat(q.MinRevenue, 'f', -1, 64))
+			}
+		} else if q.View == "most-valuable" || q.View == "pending-review" {
+			q.View = ""
+		}
+
 		var (
 			posts []*dbPost
 			err   error
@@ -382,10 +463,10 @@ func searchPosts(ctx context.Context, q *query.SearchPosts) error {
 			scoreField := "ts_rank(setweight(to_tsvector(title), 'A') || setweight(to_tsvector(description), 'B'), to_tsquery('english', $3)) + similarity(title, $4) + similarity(description, $4)"
 			sql := fmt.Sprintf(`
 				SELECT * FROM (%s) AS q 
-				WHERE %s > 0.1
+				WHERE %s > 0.1 %s
 				ORDER BY %s DESC
 				LIMIT %s
-			`, innerQuery, scoreField, scoreField, q.Limit)
+			`, innerQuery, scoreField, organizationsCondition, scoreField, q.Limit)
 			err = trx.Select(&posts, sql, tenant.ID, pq.Array([]enum.PostStatus{
 				enum.PostOpen,
 				enum.PostStarted,
@@ -397,10 +478,10 @@ func searchPosts(ctx context.Context, q *query.SearchPosts) error {
 			condition, statuses, sort := getViewData(q.View)
 			sql := fmt.Sprintf(`
 				SELECT * FROM (%s) AS q 
-				WHERE tags @> $3 %s
+				WHERE tags @> $3 %s %s
 				ORDER BY %s DESC
 				LIMIT %s
-			`, innerQuery, condition, sort, q.Limit)
+			`, innerQuery, condition, organizationsCondition, sort, q.Limit)
 			err = trx.Select(&posts, sql, tenant.ID, pq.Array(statuses), pq.Array(q.Tags))
 		}
 
@@ -439,12 +520,26 @@ func querySinglePost(ctx context.Context, trx *dbx.Trx, query string, args ...an
 
 func buildPostQuery(user *entity.User, filter string) string {
 	tagCondition := `AND tags.is_public = true`
+	aggOrganizations, organizationsColumns, organizationsJoin := "", sqlNoOrganizationsColumns, ""
 	if user != nil && user.IsCollaborator() {
 		tagCondition = ``
+		aggOrganizations, organizationsColumns, organizationsJoin = sqlAggOrganizations, sqlOrganizationsColumns, sqlOrganizationsJoin
 	}
 	hasVotedSubQuery := "null"
 	if user != nil {
 		hasVotedSubQuery = fmt.Sprintf("(SELECT true FROM post_votes WHERE post_id = p.id AND user_id = %d)", user.ID)
 	}
-	return fmt.Sprintf(sqlSelectPostsWhere, tagCondition, hasVotedSubQuery, filter)
+	return fmt.Sprintf(sqlSelectPostsWhere, tagCondition, aggOrganizations, organizationsColumns, hasVotedSubQuery, organizationsJoin, visibleContentCondition(user, "p"), filter)
+}
+
+// visibleContentCondition hides content that is waiting for a review from everyone but staff members and its author,
+// and content hidden by a moderator from everyone but staff members
+func visibleContentCondition(user *entity.User, alias string) string {
+	if user != nil && user.IsCollaborator() {
+		return "true"
+	}
+	if user != nil {
+		return fmt.Sprintf("(%s.is_hidden = false AND (%s.pending_review = false OR %s.user_id = %d))", alias, alias, alias, user.ID)
+	}
+	return fmt.Sprintf("(%s.is_hidden = false AND %s.pending_review = false)", alias, alias)
 }
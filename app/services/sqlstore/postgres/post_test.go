@@ -651,3 +651,97 @@ func TestPostStorage_Attachments(t *testing.T) {
 	Expect(err).IsNil()
 	Expect(getAttachments1.Result).HasLen(0)
 }
+
+func TestPostStorage_PendingReview(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "Buy cheap watches", Description: "http://spam.example.com", PendingReview: true}
+	err := bus.Dispatch(aryaStarkCtx, newPost)
+	Expect(err).IsNil()
+	Expect(newPost.Result.PendingReview).IsTrue()
+
+	getBySansa := &query.GetPostByNumber{Number: newPost.Result.Number}
+	err = bus.Dispatch(sansaStarkCtx, getBySansa)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	getByAuthor := &query.GetPostByNumber{Number: newPost.Result.Number}
+	getByStaff := &query.GetPostByNumber{Number: newPost.Result.Number}
+	err = bus.Dispatch(aryaStarkCtx, getByAuthor)
+	Expect(err).IsNil()
+	err = bus.Dispatch(jonSnowCtx, getByStaff)
+	Expect(err).IsNil()
+	Expect(getByStaff.Result.PendingReview).IsTrue()
+
+	pendingReview := &query.SearchPosts{View: "pending-review"}
+	err = bus.Dispatch(jonSnowCtx, pendingReview)
+	Expect(err).IsNil()
+	Expect(pendingReview.Result).HasLen(1)
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.ApprovePost{Post: getByStaff.Result})
+	Expect(err).IsNil()
+
+	getBySansa = &query.GetPostByNumber{Number: newPost.Result.Number}
+	err = bus.Dispatch(sansaStarkCtx, getBySansa)
+	Expect(err).IsNil()
+	Expect(getBySansa.Result.PendingReview).IsFalse()
+
+	pendingReview = &query.SearchPosts{View: "pending-review"}
+	err = bus.Dispatch(jonSnowCtx, pendingReview)
+	Expect(err).IsNil()
+	Expect(pendingReview.Result).HasLen(0)
+}
+
+func TestPostStorage_CommentPendingReview(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "My new post", Description: "with this description"}
+	err := bus.Dispatch(jonSnowCtx, newPost)
+	Expect(err).IsNil()
+
+	newComment := &cmd.AddNewComment{Post: newPost.Result, Content: "Visit http://spam.example.com", PendingReview: true}
+	err = bus.Dispatch(aryaStarkCtx, newComment)
+	Expect(err).IsNil()
+	Expect(newComment.Result.PendingReview).IsTrue()
+
+	bySansa := &query.GetCommentsByPost{Post: newPost.Result}
+	byAuthor := &query.GetCommentsByPost{Post: newPost.Result}
+	byStaff := &query.GetCommentsByPost{Post: newPost.Result}
+	Expect(bus.Dispatch(sansaStarkCtx, bySansa)).IsNil()
+	Expect(bus.Dispatch(aryaStarkCtx, byAuthor)).IsNil()
+	Expect(bus.Dispatch(jonSnowCtx, byStaff)).IsNil()
+	Expect(bySansa.Result).HasLen(0)
+	Expect(byAuthor.Result).HasLen(1)
+	Expect(byStaff.Result).HasLen(1)
+
+	getPost := &query.GetPostByNumber{Number: newPost.Result.Number}
+	Expect(bus.Dispatch(jonSnowCtx, getPost)).IsNil()
+	Expect(getPost.Result.CommentsCount).Equals(0)
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.ApproveComment{CommentID: newComment.Result.ID})
+	Expect(err).IsNil()
+
+	bySansa = &query.GetCommentsByPost{Post: newPost.Result}
+	Expect(bus.Dispatch(sansaStarkCtx, bySansa)).IsNil()
+	Expect(bySansa.Result).HasLen(1)
+	Expect(bySansa.Result[0].PendingReview).IsFalse()
+}
+
+func TestPostStorage_GetUserContentVelocity(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "My new post", Description: "with this description"}
+	err := bus.Dispatch(aryaStarkCtx, newPost)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(aryaStarkCtx, &cmd.AddNewComment{Post: newPost.Result, Content: "Comment #1"})
+	Expect(err).IsNil()
+
+	velocity := &query.GetUserContentVelocity{UserID: aryaStark.ID, Since: time.Now().Add(-1 * time.Hour)}
+	err = bus.Dispatch(aryaStarkCtx, velocity)
+	Expect(err).IsNil()
+	Expect(velocity.Result.RecentCount).Equals(2)
+	Expect(velocity.Result.AccountCreatedAt.IsZero()).IsFalse()
+}
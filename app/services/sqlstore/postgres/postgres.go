@@ -8,6 +8,7 @@ import (
 	"github.com/getfider/fider/app/models/entity"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
 )
 
 func init() {
@@ -31,7 +32,12 @@ func (s Service) Enabled() bool {
 func (s Service) Init() {
 	bus.AddHandler(storeEvent)
 
+	bus.AddHandler(addAuditLog)
+	bus.AddHandler(searchAuditLogs)
+
 	bus.AddHandler(purgeExpiredNotifications)
+	bus.AddHandler(hitRateLimit)
+	bus.AddHandler(purgeExpiredRateLimits)
 
 	bus.AddHandler(markAllNotificationsAsRead)
 	bus.AddHandler(markNotificationAsRead)
@@ -42,7 +48,45 @@ func (s Service) Init() {
 	bus.AddHandler(addSubscriber)
 	bus.AddHandler(removeSubscriber)
 	bus.AddHandler(supressEmail)
+	bus.AddHandler(getSupressedEmails)
+	bus.AddHandler(clearEmailSupression)
 	bus.AddHandler(getActiveSubscribers)
+	bus.AddHandler(addEmailDigestItem)
+	bus.AddHandler(deleteEmailDigestItems)
+	bus.AddHandler(getUsersEmailDelivery)
+	bus.AddHandler(getTenantsWithPendingEmailDigests)
+	bus.AddHandler(getPendingEmailDigestItems)
+	bus.AddHandler(publishRealtimeEvent)
+	bus.AddHandler(getEmailTemplate)
+	bus.AddHandler(listEmailTemplates)
+	bus.AddHandler(saveEmailTemplate)
+	bus.AddHandler(deleteEmailTemplate)
+	bus.AddHandler(queueEmail)
+	bus.AddHandler(getDueEmailBatches)
+	bus.AddHandler(setEmailDeliveryStatus)
+	bus.AddHandler(resendEmail)
+	bus.AddHandler(searchOutboxEmails)
+	bus.AddHandler(purgeOutboxEmails)
+
+	bus.AddHandler(addPushSubscription)
+	bus.AddHandler(deletePushSubscription)
+	bus.AddHandler(getPushSubscriptions)
+
+	bus.AddHandler(addSavedSearch)
+	bus.AddHandler(updateSavedSearch)
+	bus.AddHandler(deleteSavedSearch)
+	bus.AddHandler(getCurrentUserSavedSearches)
+	bus.AddHandler(getSavedSearchFollowers)
+
+	bus.AddHandler(getOrganizationByID)
+	bus.AddHandler(getAllOrganizations)
+	bus.AddHandler(saveOrganization)
+	bus.AddHandler(deleteOrganization)
+	bus.AddHandler(setUserOrganization)
+
+	bus.AddHandler(createUserImport)
+	bus.AddHandler(saveUserImportProgress)
+	bus.AddHandler(getUserImportByID)
 
 	bus.AddHandler(getTagBySlug)
 	bus.AddHandler(getAssignedTags)
@@ -59,6 +103,8 @@ func (s Service) Init() {
 
 	bus.AddHandler(addNewPost)
 	bus.AddHandler(updatePost)
+	bus.AddHandler(approvePost)
+	bus.AddHandler(hidePost)
 	bus.AddHandler(getPostByID)
 	bus.AddHandler(getPostBySlug)
 	bus.AddHandler(getPostByNumber)
@@ -75,18 +121,34 @@ func (s Service) Init() {
 	bus.AddHandler(uploadImages)
 
 	bus.AddHandler(addNewComment)
+	bus.AddHandler(approveComment)
+	bus.AddHandler(hideComment)
 	bus.AddHandler(updateComment)
 	bus.AddHandler(deleteComment)
 	bus.AddHandler(getCommentByID)
 	bus.AddHandler(getCommentsByPost)
 
+	bus.AddHandler(addContentReport)
+	bus.AddHandler(getOpenContentReports)
+	bus.AddHandler(resolveContentReports)
+
 	bus.AddHandler(countUsers)
 	bus.AddHandler(blockUser)
 	bus.AddHandler(unblockUser)
+	bus.AddHandler(suspendUser)
+	bus.AddHandler(liftUserSuspension)
+	bus.AddHandler(liftExpiredUserSuspensions)
+	bus.AddHandler(getActiveUserSuspension)
+	bus.AddHandler(getActiveUserSuspensions)
+	bus.AddHandler(hideUserProfile)
+	bus.AddHandler(showUserProfile)
 	bus.AddHandler(regenerateAPIKey)
 	bus.AddHandler(userSubscribedTo)
 	bus.AddHandler(deleteCurrentUser)
+	bus.AddHandler(eraseUser)
+	bus.AddHandler(mergeUsers)
 	bus.AddHandler(changeUserEmail)
+	bus.AddHandler(changeUserName)
 	bus.AddHandler(changeUserRole)
 	bus.AddHandler(updateCurrentUserSettings)
 	bus.AddHandler(getCurrentUserSettings)
@@ -97,17 +159,31 @@ func (s Service) Init() {
 	bus.AddHandler(getUserByEmail)
 	bus.AddHandler(getUserByID)
 	bus.AddHandler(getUserByProvider)
+	bus.AddHandler(getUserProfile)
+	bus.AddHandler(getUserActivity)
+	bus.AddHandler(getUserContentVelocity)
 	bus.AddHandler(getAllUsers)
 
+	bus.AddHandler(addUserPasskey)
+	bus.AddHandler(deleteCurrentUserPasskey)
+	bus.AddHandler(setPasskeyAsUsed)
+	bus.AddHandler(listUserPasskeys)
+	bus.AddHandler(getPasskeyByCredentialID)
+
 	bus.AddHandler(createTenant)
 	bus.AddHandler(getFirstTenant)
 	bus.AddHandler(getTenantByDomain)
+	bus.AddHandler(getTenantByID)
 	bus.AddHandler(activateTenant)
 	bus.AddHandler(isSubdomainAvailable)
 	bus.AddHandler(isCNAMEAvailable)
 	bus.AddHandler(updateTenantSettings)
 	bus.AddHandler(updateTenantPrivacySettings)
 	bus.AddHandler(updateTenantEmailAuthAllowedSettings)
+	bus.AddHandler(updateTenantPasskeySettings)
+	bus.AddHandler(regenerateSSOSecret)
+	bus.AddHandler(disableSSO)
+	bus.AddHandler(updateTenantEmailDomainSettings)
 	bus.AddHandler(updateTenantAdvancedSettings)
 
 	bus.AddHandler(getVerificationByKey)
@@ -144,3 +220,21 @@ func using(ctx context.Context, handler SqlHandler) error {
 	user, _ := ctx.Value(app.UserCtxKey).(*entity.User)
 	return handler(trx, tenant, user)
 }
+
+// usingOwnTransaction is like using, but the handler runs on a new transaction that is committed as soon as it succeeds
+func usingOwnTransaction(ctx context.Context, handler SqlHandler) error {
+	trx, err := dbx.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to open transaction")
+	}
+
+	if err = using(context.WithValue(ctx, app.TransactionCtxKey, trx), handler); err != nil {
+		trx.MustRollback()
+		return err
+	}
+
+	if err = trx.Commit(); err != nil {
+		return errors.Wrap(err, "failed commit transaction")
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/lib/pq"
+)
+
+type dbPushSubscription struct {
+	ID        int       `db:"id"`
+	UserID    int       `db:"user_id"`
+	Endpoint  string    `db:"endpoint"`
+	P256dh    string    `db:"key_p256dh"`
+	Auth      string    `db:"key_auth"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (s *dbPushSubscription) toModel() *entity.PushSubscription {
+	return &entity.PushSubscription{
+		ID:        s.ID,
+		UserID:    s.UserID,
+		Endpoint:  s.Endpoint,
+		P256dh:    s.P256dh,
+		Auth:      s.Auth,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+func addPushSubscription(ctx context.Context, c *cmd.AddPushSubscription) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		// A browser has a single endpoint, so it's moved to whoever signed in last
+		_, err := trx.Execute(`
+			INSERT INTO user_push_subscriptions (tenant_id, user_id, endpoint, key_p256dh, key_auth, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (tenant_id, endpoint) DO UPDATE
+			SET user_id = $2, key_p256dh = $4, key_auth = $5, created_at = $6
+		`, tenant.ID, user.ID, c.Endpoint, c.P256dh, c.Auth, time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to add push subscription to user with id '%d'", user.ID)
+		}
+		return nil
+	})
+}
+
+func deletePushSubscription(ctx context.Context, c *cmd.DeletePushSubscription) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute(
+			"DELETE FROM user_push_subscriptions WHERE endpoint = $1 AND user_id = $2 AND tenant_id = $3",
+			c.Endpoint, c.UserID, tenant.ID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete push subscription")
+		}
+		return nil
+	})
+}
+
+func getPushSubscriptions(ctx context.Context, q *query.GetPushSubscriptions) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		var subscriptions []*dbPushSubscription
+		err := trx.Select(&subscriptions, `
+			SELECT id, user_id, endpoint, key_p256dh, key_auth, created_at
+			FROM user_push_subscriptions
+			WHERE tenant_id = $1 AND user_id = ANY($2)
+			ORDER BY user_id, id
+		`, tenant.ID, pq.Array(q.UserIDs))
+		if err != nil {
+			return errors.Wrap(err, "failed to get push subscriptions")
+		}
+
+		q.Result = make([]*entity.PushSubscription, len(subscriptions))
+		for i, s := range subscriptions {
+			q.Result[i] = s.toModel()
+		}
+		return nil
+	})
+}
@@ -0,0 +1,63 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestPushSubscriptionStorage_AddListAndDelete(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := bus.Dispatch(jonSnowCtx, &cmd.AddPushSubscription{Endpoint: "https://push.example.com/1", P256dh: "key1", Auth: "auth1"})
+	Expect(err).IsNil()
+	err = bus.Dispatch(aryaStarkCtx, &cmd.AddPushSubscription{Endpoint: "https://push.example.com/2", P256dh: "key2", Auth: "auth2"})
+	Expect(err).IsNil()
+
+	q := &query.GetPushSubscriptions{UserIDs: []int{jonSnow.ID, aryaStark.ID}}
+	err = bus.Dispatch(jonSnowCtx, q)
+	Expect(err).IsNil()
+	Expect(q.Result).HasLen(2)
+	Expect(q.Result[0].UserID).Equals(jonSnow.ID)
+	Expect(q.Result[0].Endpoint).Equals("https://push.example.com/1")
+	Expect(q.Result[0].P256dh).Equals("key1")
+	Expect(q.Result[0].Auth).Equals("auth1")
+	Expect(q.Result[1].UserID).Equals(aryaStark.ID)
+
+	// Another user's browser is left alone
+	err = bus.Dispatch(aryaStarkCtx, &cmd.DeletePushSubscription{UserID: aryaStark.ID, Endpoint: "https://push.example.com/1"})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, q)
+	Expect(err).IsNil()
+	Expect(q.Result).HasLen(2)
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.DeletePushSubscription{UserID: jonSnow.ID, Endpoint: "https://push.example.com/1"})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, q)
+	Expect(err).IsNil()
+	Expect(q.Result).HasLen(1)
+	Expect(q.Result[0].UserID).Equals(aryaStark.ID)
+}
+
+func TestPushSubscriptionStorage_SameBrowserMovesToLastUser(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := bus.Dispatch(jonSnowCtx, &cmd.AddPushSubscription{Endpoint: "https://push.example.com/1", P256dh: "key1", Auth: "auth1"})
+	Expect(err).IsNil()
+	err = bus.Dispatch(aryaStarkCtx, &cmd.AddPushSubscription{Endpoint: "https://push.example.com/1", P256dh: "key2", Auth: "auth2"})
+	Expect(err).IsNil()
+
+	q := &query.GetPushSubscriptions{UserIDs: []int{jonSnow.ID, aryaStark.ID}}
+	err = bus.Dispatch(jonSnowCtx, q)
+	Expect(err).IsNil()
+	Expect(q.Result).HasLen(1)
+	Expect(q.Result[0].UserID).Equals(aryaStark.ID)
+	Expect(q.Result[0].P256dh).Equals("key2")
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+type dbRateLimit struct {
+	Hits      int       `db:"hits"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+func hitRateLimit(ctx context.Context, c *cmd.HitRateLimit) error {
+	// Hits are stored on their own transaction so that they are counted even when the request fails
+	// and so that the row isn't locked until the end of the request
+	trx, err := dbx.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to open transaction")
+	}
+
+	now := time.Now()
+	limit := dbRateLimit{}
+	err = trx.Get(&limit, `
+		INSERT INTO rate_limits (key, hits, expires_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			hits = CASE WHEN rate_limits.expires_at <= $3 THEN 1 ELSE rate_limits.hits + 1 END,
+			expires_at = CASE WHEN rate_limits.expires_at <= $3 THEN EXCLUDED.expires_at ELSE rate_limits.expires_at END
+		RETURNING hits, expires_at
+	`, c.Key, now.Add(c.Window), now)
+	if err != nil {
+		trx.MustRollback()
+		return errors.Wrap(err, "failed to hit rate limit '%s'", c.Key)
+	}
+
+	if err = trx.Commit(); err != nil {
+		return errors.Wrap(err, "failed commit transaction")
+	}
+
+	c.Result.Hits = limit.Hits
+	c.Result.ExpiresAt = limit.ExpiresAt
+	return nil
+}
+
+func purgeExpiredRateLimits(ctx context.Context, c *cmd.PurgeExpiredRateLimits) error {
+	trx, err := dbx.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to open transaction")
+	}
+
+	count, err := trx.Execute("DELETE FROM rate_limits WHERE expires_at <= $1", time.Now())
+	if err != nil {
+		trx.MustRollback()
+		return errors.Wrap(err, "failed to delete expired rate limits")
+	}
+
+	if err = trx.Commit(); err != nil {
+		return errors.Wrap(err, "failed commit transaction")
+	}
+
+	c.NumOfDeletedRateLimits = int(count)
+	return nil
+}
@@ -0,0 +1,52 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestRateLimitStorage_Hit(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	first := &cmd.HitRateLimit{Key: "1:signin:ip:127.0.0.1", Window: time.Hour}
+	err := bus.Dispatch(demoTenantCtx, first)
+	Expect(err).IsNil()
+	Expect(first.Result.Hits).Equals(1)
+	Expect(first.Result.ExpiresAt).TemporarilySimilar(time.Now().Add(time.Hour), 5*time.Second)
+
+	second := &cmd.HitRateLimit{Key: "1:signin:ip:127.0.0.1", Window: time.Hour}
+	err = bus.Dispatch(demoTenantCtx, second)
+	Expect(err).IsNil()
+	Expect(second.Result.Hits).Equals(2)
+	Expect(second.Result.ExpiresAt).Equals(first.Result.ExpiresAt)
+
+	other := &cmd.HitRateLimit{Key: "2:signin:ip:127.0.0.1", Window: time.Hour}
+	err = bus.Dispatch(demoTenantCtx, other)
+	Expect(err).IsNil()
+	Expect(other.Result.Hits).Equals(1)
+}
+
+func TestRateLimitStorage_ExpiredWindow(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	hit := &cmd.HitRateLimit{Key: "1:votes:user:1", Window: time.Millisecond}
+	err := bus.Dispatch(demoTenantCtx, hit)
+	Expect(err).IsNil()
+	time.Sleep(10 * time.Millisecond)
+
+	hit = &cmd.HitRateLimit{Key: "1:votes:user:1", Window: time.Hour}
+	err = bus.Dispatch(demoTenantCtx, hit)
+	Expect(err).IsNil()
+	Expect(hit.Result.Hits).Equals(1)
+
+	purge := &cmd.PurgeExpiredRateLimits{}
+	err = bus.Dispatch(demoTenantCtx, purge)
+	Expect(err).IsNil()
+	Expect(purge.NumOfDeletedRateLimits).Equals(0)
+}
@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/realtime"
+)
+
+func publishRealtimeEvent(ctx context.Context, c *cmd.PublishRealtimeEvent) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		return internalPublishRealtimeEvent(trx, realtime.Event{
+			TenantID: tenant.ID,
+			UserID:   c.UserID,
+			Type:     c.Type,
+			Data:     c.Data,
+		})
+	})
+}
+
+// Postgres only delivers notifications when the transaction is committed, so rolled back changes are never streamed
+func internalPublishRealtimeEvent(trx *dbx.Trx, event realtime.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal realtime event")
+	}
+
+	if _, err := trx.Execute("SELECT pg_notify($1, $2)", realtime.Channel, string(payload)); err != nil {
+		return errors.Wrap(err, "failed to publish realtime event")
+	}
+	return nil
+}
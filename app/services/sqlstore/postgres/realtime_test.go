@@ -0,0 +1,22 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestRealtimeStorage_PublishEvent(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := bus.Dispatch(jonSnowCtx, &cmd.PublishRealtimeEvent{
+		Type: enum.RealtimeVote,
+		Data: dto.Props{"postNumber": 1},
+	})
+	Expect(err).IsNil()
+}
@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+type dbReportedContent struct {
+	PostID         int       `db:"post_id"`
+	PostNumber     int       `db:"post_number"`
+	PostTitle      string    `db:"post_title"`
+	PostSlug       string    `db:"post_slug"`
+	CommentID      int       `db:"comment_id"`
+	Content        string    `db:"content"`
+	Author         *dbUser   `db:"author"`
+	ReportsCount   int       `db:"reports_count"`
+	Reasons        []string  `db:"reasons"`
+	Details        []string  `db:"details"`
+	LastReportedAt time.Time `db:"last_reported_at"`
+}
+
+func (r *dbReportedContent) toModel(ctx context.Context) *entity.ReportedContent {
+	reasons := make([]enum.ReportReason, len(r.Reasons))
+	for i, reason := range r.Reasons {
+		reasons[i] = enum.ReportReason(reason)
+	}
+
+	return &entity.ReportedContent{
+		PostID:         r.PostID,
+		PostNumber:     r.PostNumber,
+		PostTitle:      r.PostTitle,
+		PostSlug:       r.PostSlug,
+		CommentID:      r.CommentID,
+		Content:        r.Content,
+		Author:         r.Author.toModel(ctx),
+		ReportsCount:   r.ReportsCount,
+		Reasons:        reasons,
+		Details:        r.Details,
+		LastReportedAt: r.LastReportedAt,
+	}
+}
+
+// addContentReport files a report of current user, which replaces any open report of theirs on the same content
+func addContentReport(ctx context.Context, c *cmd.AddContentReport) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		if c.CommentID > 0 {
+			exists, err := trx.Exists("SELECT 1 FROM comments WHERE id = $1 AND post_id = $2 AND tenant_id = $3", c.CommentID, c.Post.ID, tenant.ID)
+			if err != nil {
+				return errors.Wrap(err, "failed to check if comment exists")
+			}
+			if !exists {
+				return app.ErrNotFound
+			}
+		}
+
+		updated, err := trx.Execute(`
+			UPDATE content_reports SET reason = $5, details = $6, created_at = $7
+			WHERE tenant_id = $1 AND user_id = $2 AND post_id = $3 AND COALESCE(comment_id, 0) = $4 AND resolved_at IS NULL`,
+			tenant.ID, user.ID, c.Post.ID, c.CommentID, c.Reason, c.Details, time.Now(),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to update content report")
+		}
+
+		if updated == 0 {
+			_, err = trx.Execute(`
+				INSERT INTO content_reports (tenant_id, user_id, post_id, comment_id, reason, details, created_at)
+				VALUES ($1, $2, $3, NULLIF($4, 0), $5, $6, $7)`,
+				tenant.ID, user.ID, c.Post.ID, c.CommentID, c.Reason, c.Details, time.Now(),
+			)
+			if err != nil {
+				return errors.Wrap(err, "failed to add content report")
+			}
+		}
+
+		return nil
+	})
+}
+
+func getOpenContentReports(ctx context.Context, q *query.GetOpenContentReports) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		reports := []*dbReportedContent{}
+		err := trx.Select(&reports, `
+			SELECT p.id AS post_id,
+						 p.number AS post_number,
+						 p.title AS post_title,
+						 p.slug AS post_slug,
+						 COALESCE(c.id, 0) AS comment_id,
+						 COALESCE(c.content, p.description) AS content,
+						 u.id AS author_id,
+						 u.name AS author_name,
+						 u.email AS author_email,
+						 u.role AS author_role,
+						 u.status AS author_status,
+						 u.avatar_type AS author_avatar_type,
+						 u.avatar_bkey AS author_avatar_bkey,
+						 COUNT(*) AS reports_count,
+						 ARRAY_AGG(DISTINCT r.reason) AS reasons,
+						 ARRAY_REMOVE(ARRAY_AGG(NULLIF(r.details, '')), NULL) AS details,
+						 MAX(r.created_at) AS last_reported_at
+			FROM content_reports r
+			INNER JOIN posts p
+			ON p.id = r.post_id
+			AND p.tenant_id = r.tenant_id
+			LEFT JOIN comments c
+			ON c.id = r.comment_id
+			AND c.tenant_id = r.tenant_id
+			INNER JOIN users u
+			ON u.id = COALESCE(c.user_id, p.user_id)
+			AND u.tenant_id = r.tenant_id
+			WHERE r.tenant_id = $1
+			AND r.resolved_at IS NULL
+			AND p.status != $2
+			AND c.deleted_at IS NULL
+			GROUP BY p.id, c.id, u.id
+			ORDER BY reports_count DESC, last_reported_at DESC`, tenant.ID, enum.PostDeleted)
+		if err != nil {
+			return errors.Wrap(err, "failed to get open content reports")
+		}
+
+		q.Result = make([]*entity.ReportedContent, len(reports))
+		for i, report := range reports {
+			q.Result[i] = report.toModel(ctx)
+		}
+		return nil
+	})
+}
+
+// resolveContentReports closes every open report of given content and returns who reported it
+func resolveContentReports(ctx context.Context, c *cmd.ResolveContentReports) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		reporters := []*dbUser{}
+		err := trx.Select(&reporters, `
+			UPDATE content_reports r SET resolution = $4, resolved_at = $5, resolved_by_id = $6
+			FROM users u
+			WHERE u.id = r.user_id
+			AND u.tenant_id = r.tenant_id
+			AND r.tenant_id = $1
+			AND r.post_id = $2
+			AND COALESCE(r.comment_id, 0) = $3
+			AND r.resolved_at IS NULL
+			RETURNING u.id, u.name, u.email, u.role, u.status, u.avatar_type, u.avatar_bkey`,
+			tenant.ID, c.PostID, c.CommentID, c.Resolution, time.Now(), user.ID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to resolve content reports")
+		}
+
+		c.Result = make([]*entity.User, len(reporters))
+		for i, reporter := range reporters {
+			c.Result[i] = reporter.toModel(ctx)
+		}
+		return nil
+	})
+}
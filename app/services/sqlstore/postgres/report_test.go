@@ -0,0 +1,108 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func TestContentReportStorage_AddAndList(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "Buy cheap stuff", Description: "Visit my shop"}
+	err := bus.Dispatch(aryaStarkCtx, newPost)
+	Expect(err).IsNil()
+
+	newComment := &cmd.AddNewComment{Post: newPost.Result, Content: "You are all idiots"}
+	err = bus.Dispatch(aryaStarkCtx, newComment)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.AddContentReport{Post: newPost.Result, Reason: enum.ReportSpam, Details: "Advertising"})
+	Expect(err).IsNil()
+	err = bus.Dispatch(sansaStarkCtx, &cmd.AddContentReport{Post: newPost.Result, Reason: enum.ReportOffTopic})
+	Expect(err).IsNil()
+	err = bus.Dispatch(sansaStarkCtx, &cmd.AddContentReport{Post: newPost.Result, Reason: enum.ReportSpam})
+	Expect(err).IsNil()
+	err = bus.Dispatch(sansaStarkCtx, &cmd.AddContentReport{Post: newPost.Result, CommentID: newComment.Result.ID, Reason: enum.ReportAbusive})
+	Expect(err).IsNil()
+
+	reports := &query.GetOpenContentReports{}
+	err = bus.Dispatch(jonSnowCtx, reports)
+	Expect(err).IsNil()
+	Expect(reports.Result).HasLen(2)
+
+	Expect(reports.Result[0].PostID).Equals(newPost.Result.ID)
+	Expect(reports.Result[0].CommentID).Equals(0)
+	Expect(reports.Result[0].Content).Equals("Visit my shop")
+	Expect(reports.Result[0].Author.ID).Equals(aryaStark.ID)
+	Expect(reports.Result[0].ReportsCount).Equals(2)
+	Expect(reports.Result[0].Reasons).Equals([]enum.ReportReason{enum.ReportSpam})
+	Expect(reports.Result[0].Details).Equals([]string{"Advertising"})
+
+	Expect(reports.Result[1].CommentID).Equals(newComment.Result.ID)
+	Expect(reports.Result[1].Content).Equals("You are all idiots")
+	Expect(reports.Result[1].ReportsCount).Equals(1)
+	Expect(reports.Result[1].Reasons).Equals([]enum.ReportReason{enum.ReportAbusive})
+}
+
+func TestContentReportStorage_CommentOfAnotherPost(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	post1 := &cmd.AddNewPost{Title: "My first post", Description: "with this description"}
+	post2 := &cmd.AddNewPost{Title: "My second post", Description: "with this description"}
+	Expect(bus.Dispatch(aryaStarkCtx, post1, post2)).IsNil()
+
+	newComment := &cmd.AddNewComment{Post: post1.Result, Content: "Comment #1"}
+	Expect(bus.Dispatch(aryaStarkCtx, newComment)).IsNil()
+
+	err := bus.Dispatch(jonSnowCtx, &cmd.AddContentReport{Post: post2.Result, CommentID: newComment.Result.ID, Reason: enum.ReportSpam})
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestContentReportStorage_Resolve(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "Buy cheap stuff", Description: "Visit my shop"}
+	err := bus.Dispatch(aryaStarkCtx, newPost)
+	Expect(err).IsNil()
+
+	Expect(bus.Dispatch(jonSnowCtx, &cmd.AddContentReport{Post: newPost.Result, Reason: enum.ReportSpam})).IsNil()
+	Expect(bus.Dispatch(sansaStarkCtx, &cmd.AddContentReport{Post: newPost.Result, Reason: enum.ReportSpam})).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.HidePost{Post: newPost.Result})
+	Expect(err).IsNil()
+	Expect(newPost.Result.IsHidden).IsTrue()
+	Expect(newPost.Result.PendingReview).IsFalse()
+
+	resolve := &cmd.ResolveContentReports{PostID: newPost.Result.ID, Resolution: enum.ReportHidden}
+	err = bus.Dispatch(jonSnowCtx, resolve)
+	Expect(err).IsNil()
+	Expect(resolve.Result).HasLen(2)
+
+	reports := &query.GetOpenContentReports{}
+	err = bus.Dispatch(jonSnowCtx, reports)
+	Expect(err).IsNil()
+	Expect(reports.Result).HasLen(0)
+
+	bySansa := &query.GetPostByNumber{Number: newPost.Result.Number}
+	err = bus.Dispatch(sansaStarkCtx, bySansa)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	byAuthor := &query.GetPostByNumber{Number: newPost.Result.Number}
+	err = bus.Dispatch(aryaStarkCtx, byAuthor)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	pendingReview := &query.SearchPosts{View: "pending-review"}
+	err = bus.Dispatch(jonSnowCtx, pendingReview)
+	Expect(err).IsNil()
+	Expect(pendingReview.Result).HasLen(0)
+}
@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/lib/pq"
+)
+
+type dbSavedSearch struct {
+	ID        int       `db:"id"`
+	UserID    int       `db:"user_id"`
+	Name      string    `db:"name"`
+	View      string    `db:"view"`
+	Tags      []string  `db:"tags"`
+	Channels  int       `db:"channels"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (s *dbSavedSearch) toModel() *entity.SavedSearch {
+	return &entity.SavedSearch{
+		ID:        s.ID,
+		UserID:    s.UserID,
+		Name:      s.Name,
+		View:      s.View,
+		Tags:      s.Tags,
+		Channels:  enum.NotificationChannel(s.Channels),
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+type dbSavedSearchFollower struct {
+	View string  `db:"view"`
+	User *dbUser `db:"user"`
+}
+
+// Tags are stored by id so that saved searches survive a tag being renamed
+const sqlSelectSavedSearches = `
+	SELECT s.id, s.user_id, s.name, s.view, s.channels, s.created_at,
+	ARRAY(SELECT t.slug FROM tags t WHERE t.id = ANY(s.tag_ids) AND t.tenant_id = s.tenant_id ORDER BY t.slug) AS tags
+	FROM user_saved_searches s
+`
+
+func addSavedSearch(ctx context.Context, c *cmd.AddSavedSearch) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		tagIDs := make([]int, len(c.Tags))
+		for i, tag := range c.Tags {
+			tagIDs[i] = tag.ID
+		}
+
+		var id int
+		err := trx.Scalar(&id, `
+			INSERT INTO user_saved_searches (tenant_id, user_id, name, view, tag_ids, channels, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id
+		`, tenant.ID, user.ID, c.Name, c.View, pq.Array(tagIDs), c.Channels, time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to add saved search to user with id '%d'", user.ID)
+		}
+
+		search := dbSavedSearch{}
+		err = trx.Get(&search, sqlSelectSavedSearches+" WHERE s.id = $1 AND s.tenant_id = $2", id, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get saved search with id '%d'", id)
+		}
+
+		c.Result = search.toModel()
+		return nil
+	})
+}
+
+func updateSavedSearch(ctx context.Context, c *cmd.UpdateSavedSearch) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		rows, err := trx.Execute(
+			"UPDATE user_saved_searches SET channels = $1 WHERE id = $2 AND user_id = $3 AND tenant_id = $4",
+			c.Channels, c.ID, user.ID, tenant.ID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to update saved search with id '%d'", c.ID)
+		}
+		if rows == 0 {
+			return app.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func deleteSavedSearch(ctx context.Context, c *cmd.DeleteSavedSearch) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		rows, err := trx.Execute(
+			"DELETE FROM user_saved_searches WHERE id = $1 AND user_id = $2 AND tenant_id = $3",
+			c.ID, user.ID, tenant.ID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete saved search with id '%d'", c.ID)
+		}
+		if rows == 0 {
+			return app.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func getCurrentUserSavedSearches(ctx context.Context, q *query.GetCurrentUserSavedSearches) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		var searches []*dbSavedSearch
+		err := trx.Select(&searches, sqlSelectSavedSearches+" WHERE s.user_id = $1 AND s.tenant_id = $2 ORDER BY s.id", user.ID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get saved searches of user with id '%d'", user.ID)
+		}
+
+		q.Result = make([]*entity.SavedSearch, len(searches))
+		for i, s := range searches {
+			q.Result[i] = s.toModel()
+		}
+		return nil
+	})
+}
+
+func getSavedSearchFollowers(ctx context.Context, q *query.GetSavedSearchFollowers) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		q.Result = make([]*entity.User, 0)
+
+		// When searching for email followers, skip users with email supressed
+		supressionCondition := ""
+		if q.Channel == enum.NotificationChannelEmail {
+			supressionCondition = "AND u.email_supressed_at IS NULL"
+		}
+
+		tagCondition := ""
+		if q.Tag != nil {
+			tagCondition = fmt.Sprintf("AND %d = ANY(s.tag_ids)", q.Tag.ID)
+		}
+
+		// A search matches when the post has all of its tags, while users who unsubscribed from the post are left out
+		var followers []*dbSavedSearchFollower
+		err := trx.Select(&followers, fmt.Sprintf(`
+			SELECT s.view, u.id AS user_id, u.name AS user_name, u.email AS user_email,
+			u.tenant_id AS user_tenant_id, u.role AS user_role, u.status AS user_status, u.locale AS user_locale
+			FROM user_saved_searches s
+			INNER JOIN users u
+			ON u.id = s.user_id
+			AND u.tenant_id = s.tenant_id
+			WHERE s.tenant_id = $1
+			AND s.channels & $2 > 0
+			AND u.status = $3
+			%s
+			%s
+			AND s.tag_ids <@ ARRAY(SELECT pt.tag_id FROM post_tags pt WHERE pt.post_id = $4 AND pt.tenant_id = $1)
+			AND NOT EXISTS (
+				SELECT 1 FROM post_subscribers sub
+				WHERE sub.post_id = $4 AND sub.user_id = u.id AND sub.tenant_id = $1 AND sub.status = $5
+			)
+			ORDER BY u.id`, supressionCondition, tagCondition),
+			tenant.ID, q.Channel, enum.UserActive, q.Post.ID, enum.SubscriberInactive,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to get followers of post with id '%d'", q.Post.ID)
+		}
+
+		added := make(map[int]bool)
+		for _, follower := range followers {
+			userID := int(follower.User.ID.Int64)
+			if added[userID] || !isStatusOnView(q.Post.Status, follower.View) {
+				continue
+			}
+			added[userID] = true
+			q.Result = append(q.Result, follower.User.toModel(ctx))
+		}
+		return nil
+	})
+}
+
+// isStatusOnView returns true if posts with given status are listed on given view
+func isStatusOnView(status enum.PostStatus, view string) bool {
+	_, statuses, _ := getViewData(view)
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
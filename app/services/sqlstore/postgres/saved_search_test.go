@@ -0,0 +1,118 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func TestSavedSearchStorage_AddUpdateAndDelete(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	addBug := &cmd.AddNewTag{Name: "Bug", Color: "FF0000", IsPublic: true}
+	bus.MustDispatch(jonSnowCtx, addBug)
+
+	addSearch := &cmd.AddSavedSearch{Name: "Planned bugs", View: "planned", Tags: []*entity.Tag{addBug.Result}, Channels: enum.NotificationChannelWeb}
+	err := bus.Dispatch(aryaStarkCtx, addSearch)
+	Expect(err).IsNil()
+	Expect(addSearch.Result.ID).NotEquals(0)
+	Expect(addSearch.Result.Name).Equals("Planned bugs")
+	Expect(addSearch.Result.View).Equals("planned")
+	Expect(addSearch.Result.Tags).Equals([]string{"bug"})
+	Expect(addSearch.Result.Channels).Equals(enum.NotificationChannelWeb)
+
+	err = bus.Dispatch(aryaStarkCtx, &cmd.UpdateSavedSearch{ID: addSearch.Result.ID, Channels: enum.NotificationChannelEmail})
+	Expect(err).IsNil()
+
+	// Tags are kept by id, so renaming them changes the saved search too
+	bus.MustDispatch(jonSnowCtx, &cmd.UpdateTag{TagID: addBug.Result.ID, Name: "Defect", Color: "FF0000", IsPublic: true})
+
+	q := &query.GetCurrentUserSavedSearches{}
+	err = bus.Dispatch(aryaStarkCtx, q)
+	Expect(err).IsNil()
+	Expect(q.Result).HasLen(1)
+	Expect(q.Result[0].Tags).Equals([]string{"defect"})
+	Expect(q.Result[0].Channels).Equals(enum.NotificationChannelEmail)
+
+	err = bus.Dispatch(jonSnowCtx, q)
+	Expect(err).IsNil()
+	Expect(q.Result).HasLen(0)
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.DeleteSavedSearch{ID: addSearch.Result.ID})
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	err = bus.Dispatch(aryaStarkCtx, &cmd.DeleteSavedSearch{ID: addSearch.Result.ID})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(aryaStarkCtx, q)
+	Expect(err).IsNil()
+	Expect(q.Result).HasLen(0)
+}
+
+func TestSavedSearchStorage_GetFollowers(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	addBug := &cmd.AddNewTag{Name: "Bug", Color: "FF0000", IsPublic: true}
+	addUI := &cmd.AddNewTag{Name: "UI", Color: "00FF00", IsPublic: true}
+	bus.MustDispatch(jonSnowCtx, addBug, addUI)
+
+	newPost := &cmd.AddNewPost{Title: "Button is broken", Description: "It doesn't do anything"}
+	bus.MustDispatch(jonSnowCtx, newPost)
+	bus.MustDispatch(jonSnowCtx, &cmd.AssignTag{Tag: addBug.Result, Post: newPost.Result})
+
+	bus.MustDispatch(aryaStarkCtx, &cmd.AddSavedSearch{Name: "Bugs", View: "all", Tags: []*entity.Tag{addBug.Result}, Channels: enum.NotificationChannelWeb | enum.NotificationChannelEmail})
+	bus.MustDispatch(aryaStarkCtx, &cmd.AddSavedSearch{Name: "Open bugs", View: "trending", Tags: []*entity.Tag{addBug.Result}, Channels: enum.NotificationChannelWeb})
+	bus.MustDispatch(sansaStarkCtx, &cmd.AddSavedSearch{Name: "UI bugs", View: "all", Tags: []*entity.Tag{addBug.Result, addUI.Result}, Channels: enum.NotificationChannelWeb})
+	bus.MustDispatch(sansaStarkCtx, &cmd.AddSavedSearch{Name: "Planned", View: "planned", Tags: []*entity.Tag{}, Channels: enum.NotificationChannelWeb})
+
+	webFollowers := &query.GetSavedSearchFollowers{Post: newPost.Result, Channel: enum.NotificationChannelWeb}
+	emailFollowers := &query.GetSavedSearchFollowers{Post: newPost.Result, Channel: enum.NotificationChannelEmail}
+	pushFollowers := &query.GetSavedSearchFollowers{Post: newPost.Result, Channel: enum.NotificationChannelPush}
+	err := bus.Dispatch(jonSnowCtx, webFollowers, emailFollowers, pushFollowers)
+	Expect(err).IsNil()
+	Expect(webFollowers.Result).HasLen(1)
+	Expect(webFollowers.Result[0].ID).Equals(aryaStark.ID)
+	Expect(emailFollowers.Result).HasLen(1)
+	Expect(emailFollowers.Result[0].ID).Equals(aryaStark.ID)
+	Expect(pushFollowers.Result).HasLen(0)
+
+	bus.MustDispatch(jonSnowCtx, &cmd.SetPostResponse{Post: newPost.Result, Text: "Next week", Status: enum.PostPlanned})
+	newPost.Result.Status = enum.PostPlanned
+
+	err = bus.Dispatch(jonSnowCtx, webFollowers)
+	Expect(err).IsNil()
+	Expect(webFollowers.Result).HasLen(2)
+	Expect(webFollowers.Result[0].ID).Equals(aryaStark.ID)
+	Expect(webFollowers.Result[1].ID).Equals(sansaStark.ID)
+
+	// Searches without the assigned tag already matched the post before it was tagged
+	bugFollowers := &query.GetSavedSearchFollowers{Post: newPost.Result, Channel: enum.NotificationChannelWeb, Tag: addBug.Result}
+	err = bus.Dispatch(jonSnowCtx, bugFollowers)
+	Expect(err).IsNil()
+	Expect(bugFollowers.Result).HasLen(1)
+	Expect(bugFollowers.Result[0].ID).Equals(aryaStark.ID)
+
+	bus.MustDispatch(aryaStarkCtx, &cmd.RemoveSubscriber{Post: newPost.Result, User: aryaStark})
+
+	err = bus.Dispatch(jonSnowCtx, webFollowers)
+	Expect(err).IsNil()
+	Expect(webFollowers.Result).HasLen(1)
+	Expect(webFollowers.Result[0].ID).Equals(sansaStark.ID)
+
+	// Deleting a tag removes the saved searches that depend on it
+	bus.MustDispatch(jonSnowCtx, &cmd.DeleteTag{Tag: addBug.Result})
+
+	searches := &query.GetCurrentUserSavedSearches{}
+	err = bus.Dispatch(aryaStarkCtx, searches)
+	Expect(err).IsNil()
+	Expect(searches.Result).HasLen(0)
+}
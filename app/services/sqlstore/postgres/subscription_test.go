@@ -4,10 +4,12 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
 
 	. "github.com/getfider/fider/app/pkg/assert"
 )
@@ -352,3 +354,37 @@ func TestSubscription_EmailSupressed(t *testing.T) {
 	Expect(q.Result).HasLen(1)
 	Expect(q.Result[0].ID).Equals(jonSnow.ID)
 }
+
+func TestSubscription_GetAndClearSupressedEmails(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := bus.Dispatch(aryaStarkCtx, &cmd.SupressEmail{EmailAddresses: []string{aryaStark.Email}})
+	Expect(err).IsNil()
+
+	q := &query.GetSupressedEmails{}
+	err = bus.Dispatch(demoTenantCtx, q)
+	Expect(err).IsNil()
+	Expect(q.Result).HasLen(1)
+	Expect(q.Result[0].UserID).Equals(aryaStark.ID)
+	Expect(q.Result[0].Email).Equals(aryaStark.Email)
+
+	q = &query.GetSupressedEmails{}
+	err = bus.Dispatch(avengersTenantCtx, q)
+	Expect(err).IsNil()
+	Expect(q.Result).HasLen(0)
+
+	err = bus.Dispatch(avengersTenantCtx, &cmd.ClearEmailSupression{UserID: aryaStark.ID})
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.ClearEmailSupression{UserID: jonSnow.ID})
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.ClearEmailSupression{UserID: aryaStark.ID})
+	Expect(err).IsNil()
+
+	q = &query.GetSupressedEmails{}
+	err = bus.Dispatch(demoTenantCtx, q)
+	Expect(err).IsNil()
+	Expect(q.Result).HasLen(0)
+}
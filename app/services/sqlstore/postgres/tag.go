@@ -130,6 +130,12 @@ func deleteTag(ctx context.Context, c *cmd.DeleteTag) error {
 			return errors.Wrap(err, "failed to remove tag with id '%d' from all posts", c.Tag.ID)
 		}
 
+		// Saved searches of a deleted tag would otherwise match posts with any tag
+		_, err = trx.Execute(`DELETE FROM user_saved_searches WHERE $1 = ANY(tag_ids) AND tenant_id = $2`, c.Tag.ID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete saved searches of tag with id '%d'", c.Tag.ID)
+		}
+
 		_, err = trx.Execute(`DELETE FROM tags WHERE id = $1 AND tenant_id = $2`, c.Tag.ID, tenant.ID)
 		if err != nil {
 			return errors.Wrap(err, "failed to delete tag with id '%d'", c.Tag.ID)
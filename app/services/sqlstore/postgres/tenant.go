@@ -14,6 +14,8 @@ import (
 	"github.com/getfider/fider/app/pkg/dbx"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/rand"
+	"github.com/lib/pq"
 )
 
 type dbTenant struct {
@@ -29,6 +31,11 @@ type dbTenant struct {
 	LogoBlobKey        string `db:"logo_bkey"`
 	CustomCSS          string `db:"custom_css"`
 	IsEmailAuthAllowed bool   `db:"is_email_auth_allowed"`
+	PasskeyMode        int    `db:"passkey_mode"`
+
+	AllowedEmailDomains []string `db:"allowed_email_domains"`
+	AutoJoinRole        int      `db:"auto_join_role"`
+	SSOSecret           string   `db:"sso_secret"`
 }
 
 func (t *dbTenant) toModel() *entity.Tenant {
@@ -49,6 +56,11 @@ func (t *dbTenant) toModel() *entity.Tenant {
 		LogoBlobKey:        t.LogoBlobKey,
 		CustomCSS:          t.CustomCSS,
 		IsEmailAuthAllowed: t.IsEmailAuthAllowed,
+		PasskeyMode:        enum.PasskeyMode(t.PasskeyMode),
+
+		AllowedEmailDomains: t.AllowedEmailDomains,
+		AutoJoinRole:        enum.Role(t.AutoJoinRole),
+		SSOSecret:           t.SSOSecret,
 	}
 
 	return tenant
@@ -137,6 +149,50 @@ func updateTenantEmailAuthAllowedSettings(ctx context.Context, c *cmd.UpdateTena
 	})
 }
 
+func updateTenantPasskeySettings(ctx context.Context, c *cmd.UpdateTenantPasskeySettings) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute("UPDATE tenants SET passkey_mode = $1 WHERE id = $2", c.PasskeyMode, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed update tenant passkey settings")
+		}
+		return nil
+	})
+}
+
+func regenerateSSOSecret(ctx context.Context, c *cmd.RegenerateSSOSecret) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		secret := rand.String(64)
+		_, err := trx.Execute("UPDATE tenants SET sso_secret = $1 WHERE id = $2", secret, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to regenerate tenant SSO secret")
+		}
+
+		c.Result = secret
+		return nil
+	})
+}
+
+func disableSSO(ctx context.Context, c *cmd.DisableSSO) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute("UPDATE tenants SET sso_secret = '' WHERE id = $1", tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to disable tenant SSO")
+		}
+		return nil
+	})
+}
+
+func updateTenantEmailDomainSettings(ctx context.Context, c *cmd.UpdateTenantEmailDomainSettings) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		_, err := trx.Execute("UPDATE tenants SET allowed_email_domains = $1, auto_join_role = $2 WHERE id = $3",
+			pq.Array(c.AllowedEmailDomains), c.AutoJoinRole, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed update tenant email domain settings")
+		}
+		return nil
+	})
+}
+
 func updateTenantSettings(ctx context.Context, c *cmd.UpdateTenantSettings) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
 		if c.Logo.Remove {
@@ -259,7 +315,8 @@ func getFirstTenant(ctx context.Context, q *query.GetFirstTenant) error {
 		tenant := dbTenant{}
 
 		err := trx.Get(&tenant, `
-			SELECT id, name, subdomain, cname, invitation, locale, welcome_message, status, is_private, logo_bkey, custom_css, is_email_auth_allowed
+			SELECT id, name, subdomain, cname, invitation, locale, welcome_message, status, is_private, logo_bkey, custom_css, is_email_auth_allowed, passkey_mode,
+			       allowed_email_domains, auto_join_role, sso_secret
 			FROM tenants
 			ORDER BY id LIMIT 1
 		`)
@@ -273,12 +330,32 @@ func getFirstTenant(ctx context.Context, q *query.GetFirstTenant) error {
 	})
 }
 
+func getTenantByID(ctx context.Context, q *query.GetTenantByID) error {
+	return using(ctx, func(trx *dbx.Trx, _ *entity.Tenant, _ *entity.User) error {
+		tenant := dbTenant{}
+
+		err := trx.Get(&tenant, `
+			SELECT id, name, subdomain, cname, invitation, locale, welcome_message, status, is_private, logo_bkey, custom_css, is_email_auth_allowed, passkey_mode,
+			       allowed_email_domains, auto_join_role, sso_secret
+			FROM tenants
+			WHERE id = $1
+		`, q.TenantID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get tenant with id '%d'", q.TenantID)
+		}
+
+		q.Result = tenant.toModel()
+		return nil
+	})
+}
+
 func getTenantByDomain(ctx context.Context, q *query.GetTenantByDomain) error {
 	return using(ctx, func(trx *dbx.Trx, _ *entity.Tenant, _ *entity.User) error {
 		tenant := dbTenant{}
 
 		err := trx.Get(&tenant, `
-			SELECT id, name, subdomain, cname, invitation, locale, welcome_message, status, is_private, logo_bkey, custom_css, is_email_auth_allowed
+			SELECT id, name, subdomain, cname, invitation, locale, welcome_message, status, is_private, logo_bkey, custom_css, is_email_auth_allowed, passkey_mode,
+			       allowed_email_domains, auto_join_role, sso_secret
 			FROM tenants t
 			WHERE subdomain = $1 OR subdomain = $2 OR cname = $3 
 			ORDER BY cname DESC
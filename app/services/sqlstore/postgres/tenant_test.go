@@ -106,6 +106,26 @@ func TestTenantStorage_UpdatePrivacy(t *testing.T) {
 	Expect(getByDomain.Result.IsPrivate).IsTrue()
 }
 
+func TestTenantStorage_UpdateEmailDomains(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	getByDomain := &query.GetTenantByDomain{Domain: "demo"}
+	err := bus.Dispatch(demoTenantCtx, getByDomain)
+	Expect(err).IsNil()
+	Expect(getByDomain.Result.AllowedEmailDomains).HasLen(0)
+	Expect(getByDomain.Result.AutoJoinRole).Equals(enum.RoleVisitor)
+
+	setDomains := &cmd.UpdateTenantEmailDomainSettings{
+		AllowedEmailDomains: []string{"got.com", "nightswatch.org"},
+		AutoJoinRole:        enum.RoleCollaborator,
+	}
+	err = bus.Dispatch(demoTenantCtx, setDomains, getByDomain)
+	Expect(err).IsNil()
+	Expect(getByDomain.Result.AllowedEmailDomains).Equals([]string{"got.com", "nightswatch.org"})
+	Expect(getByDomain.Result.AutoJoinRole).Equals(enum.RoleCollaborator)
+}
+
 func TestTenantStorage_GetByDomain_NotFound(t *testing.T) {
 	ctx := SetupDatabaseTest(t)
 	defer TeardownDatabaseTest()
@@ -118,6 +138,22 @@ func TestTenantStorage_GetByDomain_NotFound(t *testing.T) {
 	Expect(getByDomain.Result).IsNil()
 }
 
+func TestTenantStorage_GetByID(t *testing.T) {
+	ctx := SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	getByID := &query.GetTenantByID{TenantID: 1}
+	err := bus.Dispatch(ctx, getByID)
+	Expect(err).IsNil()
+	Expect(getByID.Result.ID).Equals(1)
+	Expect(getByID.Result.Subdomain).Equals("demo")
+
+	getByID = &query.GetTenantByID{TenantID: 999}
+	err = bus.Dispatch(ctx, getByID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	Expect(getByID.Result).IsNil()
+}
+
 func TestTenantStorage_GetByDomain_CNAME(t *testing.T) {
 	SetupDatabaseTest(t)
 	defer TeardownDatabaseTest()
@@ -408,3 +444,28 @@ func TestTenantStorage_Save_Get_ListOAuthConfig(t *testing.T) {
 	Expect(customConfigs.Result[0].JSONUserNamePath).Equals("New user.name")
 	Expect(customConfigs.Result[0].JSONUserEmailPath).Equals("New user.email")
 }
+
+func TestTenantStorage_RegenerateAndDisableSSO(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	Expect(demoTenant.IsSSOEnabled()).IsFalse()
+
+	regenerate := &cmd.RegenerateSSOSecret{}
+	err := bus.Dispatch(demoTenantCtx, regenerate)
+	Expect(err).IsNil()
+	Expect(regenerate.Result).HasLen(64)
+
+	getDemo := &query.GetTenantByDomain{Domain: "demo"}
+	err = bus.Dispatch(demoTenantCtx, getDemo)
+	Expect(err).IsNil()
+	Expect(getDemo.Result.SSOSecret).Equals(regenerate.Result)
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.DisableSSO{})
+	Expect(err).IsNil()
+
+	getDemo = &query.GetTenantByDomain{Domain: "demo"}
+	err = bus.Dispatch(demoTenantCtx, getDemo)
+	Expect(err).IsNil()
+	Expect(getDemo.Result.IsSSOEnabled()).IsFalse()
+}
@@ -25,6 +25,7 @@ type dbUser struct {
 	Status        sql.NullInt64  `db:"status"`
 	AvatarType    sql.NullInt64  `db:"avatar_type"`
 	AvatarBlobKey sql.NullString `db:"avatar_bkey"`
+	Locale        sql.NullString `db:"locale"`
 	Providers     []*dbUserProvider
 }
 
@@ -55,6 +56,7 @@ func (u *dbUser) toModel(ctx context.Context) *entity.User {
 		AvatarType:    avatarType,
 		AvatarBlobKey: u.AvatarBlobKey.String,
 		AvatarURL:     avatarURL,
+		Locale:        u.Locale.String,
 	}
 
 	for i, p := range u.Providers {
@@ -67,6 +69,21 @@ func (u *dbUser) toModel(ctx context.Context) *entity.User {
 	return user
 }
 
+type dbUserProfile struct {
+	Bio      sql.NullString `db:"bio"`
+	Link     sql.NullString `db:"link"`
+	IsHidden bool           `db:"profile_hidden"`
+}
+
+type dbUserComment struct {
+	ID         int       `db:"id"`
+	Content    string    `db:"content"`
+	CreatedAt  time.Time `db:"created_at"`
+	PostNumber int       `db:"post_number"`
+	PostTitle  string    `db:"post_title"`
+	PostSlug   string    `db:"post_slug"`
+}
+
 type dbUserSetting struct {
 	Key   string `db:"key"`
 	Value string `db:"value"`
@@ -108,41 +125,239 @@ func unblockUser(ctx context.Context, c *cmd.UnblockUser) error {
 	})
 }
 
+func hideUserProfile(ctx context.Context, c *cmd.HideUserProfile) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		if _, err := trx.Execute(
+			"UPDATE users SET profile_hidden = true WHERE id = $1 AND tenant_id = $2",
+			c.UserID, tenant.ID,
+		); err != nil {
+			return errors.Wrap(err, "failed to hide user profile")
+		}
+		return nil
+	})
+}
+
+func showUserProfile(ctx context.Context, c *cmd.ShowUserProfile) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		if _, err := trx.Execute(
+			"UPDATE users SET profile_hidden = false WHERE id = $1 AND tenant_id = $2",
+			c.UserID, tenant.ID,
+		); err != nil {
+			return errors.Wrap(err, "failed to show user profile")
+		}
+		return nil
+	})
+}
+
 func deleteCurrentUser(ctx context.Context, c *cmd.DeleteCurrentUser) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		return anonymizeUser(trx, tenant, user.ID)
+	})
+}
+
+func eraseUser(ctx context.Context, c *cmd.EraseUser) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		c.Result = make([]string, 0)
+
+		var avatarBlobKey string
+		if err := trx.Scalar(&avatarBlobKey,
+			"SELECT avatar_bkey FROM users WHERE id = $1 AND tenant_id = $2",
+			c.UserID, tenant.ID,
+		); err != nil {
+			return errors.Wrap(err, "failed to get user's avatar")
+		}
+		if avatarBlobKey != "" {
+			c.Result = append(c.Result, avatarBlobKey)
+		}
+
+		if c.DeleteContent {
+			bkeys, err := deleteUserContent(trx, tenant, c.UserID)
+			if err != nil {
+				return err
+			}
+			c.Result = append(c.Result, bkeys...)
+		}
+
 		if _, err := trx.Execute(
-			"UPDATE users SET role = $3, status = $4, name = '', email = '', api_key = null, api_key_date = null WHERE id = $1 AND tenant_id = $2",
-			user.ID, tenant.ID, enum.RoleVisitor, enum.UserDeleted,
+			"UPDATE users SET avatar_type = $3, avatar_bkey = '' WHERE id = $1 AND tenant_id = $2",
+			c.UserID, tenant.ID, enum.AvatarTypeLetter,
 		); err != nil {
-			return errors.Wrap(err, "failed to delete current user")
+			return errors.Wrap(err, "failed to erase user's avatar")
 		}
 
-		var tables = []struct {
-			name       string
-			userColumn string
+		return anonymizeUser(trx, tenant, c.UserID)
+	})
+}
+
+func mergeUsers(ctx context.Context, c *cmd.MergeUsers) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		// Votes and subscriptions are unique per post, so the ones the target already has are left behind
+		var commands = []struct {
+			description string
+			command     string
 		}{
-			{"user_providers", "user_id"},
-			{"user_settings", "user_id"},
-			{"notifications", "user_id"},
-			{"notifications", "author_id"},
-			{"post_votes", "user_id"},
-			{"post_subscribers", "user_id"},
-			{"email_verifications", "user_id"},
-		}
-
-		for _, table := range tables {
-			if _, err := trx.Execute(
-				fmt.Sprintf("DELETE FROM %s WHERE %s = $1 AND tenant_id = $2", table.name, table.userColumn),
-				user.ID, tenant.ID,
-			); err != nil {
-				return errors.Wrap(err, "failed to delete current user's %s records", table)
+			{"votes", "UPDATE post_votes SET user_id = $2 WHERE user_id = $1 AND tenant_id = $3 AND post_id NOT IN (SELECT post_id FROM post_votes WHERE user_id = $2 AND tenant_id = $3)"},
+			{"subscriptions", "UPDATE post_subscribers SET user_id = $2 WHERE user_id = $1 AND tenant_id = $3 AND post_id NOT IN (SELECT post_id FROM post_subscribers WHERE user_id = $2 AND tenant_id = $3)"},
+			{"providers", "UPDATE user_providers SET user_id = $2 WHERE user_id = $1 AND tenant_id = $3 AND provider NOT IN (SELECT provider FROM user_providers WHERE user_id = $2 AND tenant_id = $3)"},
+			{"passkeys", "UPDATE user_passkeys SET user_id = $2 WHERE user_id = $1 AND tenant_id = $3"},
+			{"push subscriptions", "UPDATE user_push_subscriptions SET user_id = $2 WHERE user_id = $1 AND tenant_id = $3"},
+			{"saved searches", "UPDATE user_saved_searches SET user_id = $2 WHERE user_id = $1 AND tenant_id = $3"},
+			{"posts", "UPDATE posts SET user_id = $2 WHERE user_id = $1 AND tenant_id = $3"},
+			{"post responses", "UPDATE posts SET response_user_id = $2 WHERE response_user_id = $1 AND tenant_id = $3"},
+			{"post tags", "UPDATE post_tags SET created_by_id = $2 WHERE created_by_id = $1 AND tenant_id = $3"},
+			{"comments", "UPDATE comments SET user_id = $2 WHERE user_id = $1 AND tenant_id = $3"},
+			{"comment edits", "UPDATE comments SET edited_by_id = $2 WHERE edited_by_id = $1 AND tenant_id = $3"},
+			{"comment deletions", "UPDATE comments SET deleted_by_id = $2 WHERE deleted_by_id = $1 AND tenant_id = $3"},
+			{"content reports", "UPDATE content_reports SET user_id = $2 WHERE user_id = $1 AND tenant_id = $3"},
+			{"content report resolutions", "UPDATE content_reports SET resolved_by_id = $2 WHERE resolved_by_id = $1 AND tenant_id = $3"},
+			{"suspensions", "UPDATE user_suspensions SET created_by_id = $2 WHERE created_by_id = $1 AND tenant_id = $3"},
+			{"attachments", "UPDATE attachments SET user_id = $2 WHERE user_id = $1 AND tenant_id = $3"},
+			{"notifications", "UPDATE notifications SET user_id = $2 WHERE user_id = $1 AND tenant_id = $3"},
+			{"notification authors", "UPDATE notifications SET author_id = $2 WHERE author_id = $1 AND tenant_id = $3"},
+			{"organization", "UPDATE users SET organization_id = (SELECT organization_id FROM users WHERE id = $1 AND tenant_id = $3) WHERE id = $2 AND tenant_id = $3 AND organization_id IS NULL"},
+			{"digest items", "UPDATE email_digest_items SET user_id = $2 WHERE user_id = $1 AND tenant_id = $3"},
+			{"suspensions", "UPDATE user_suspensions SET user_id = $2 WHERE user_id = $1 AND tenant_id = $3"},
+			{"imports", "UPDATE user_imports SET user_id = $2 WHERE user_id = $1 AND tenant_id = $3"},
+		}
+
+		for _, merge := range commands {
+			if _, err := trx.Execute(merge.command, c.SourceUserID, c.TargetUserID, tenant.ID); err != nil {
+				return errors.Wrap(err, "failed to merge user's %s", merge.description)
 			}
 		}
 
-		return nil
+		// audit logs are append-only, so users they refer to are kept as anonymized tombstones
+		audited, err := trx.Exists(
+			"SELECT 1 FROM audit_logs WHERE tenant_id = $2 AND (actor_id = $1 OR impersonated_id = $1)",
+			c.SourceUserID, tenant.ID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to check user's audit logs")
+		}
+		if audited {
+			return anonymizeUser(trx, tenant, c.SourceUserID)
+		}
+
+		return deleteUser(trx, tenant, c.SourceUserID)
 	})
 }
 
+// deleteUser removes given user along with the records that could not be moved to another user
+func deleteUser(trx *dbx.Trx, tenant *entity.Tenant, userID int) error {
+	if _, err := trx.Execute(
+		"DELETE FROM email_outbox WHERE tenant_id = $2 AND to_address <> '' AND to_address = (SELECT email FROM users WHERE id = $1 AND tenant_id = $2)",
+		userID, tenant.ID,
+	); err != nil {
+		return errors.Wrap(err, "failed to delete user's emails")
+	}
+
+	var tables = []string{"user_providers", "user_settings", "post_votes", "post_subscribers", "email_verifications"}
+	for _, table := range tables {
+		if _, err := trx.Execute(fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 AND tenant_id = $2", table), userID, tenant.ID); err != nil {
+			return errors.Wrap(err, "failed to delete user's %s records", table)
+		}
+	}
+
+	if _, err := trx.Execute("DELETE FROM users WHERE id = $1 AND tenant_id = $2", userID, tenant.ID); err != nil {
+		return errors.Wrap(err, "failed to delete user")
+	}
+	return nil
+}
+
+func anonymizeUser(trx *dbx.Trx, tenant *entity.Tenant, userID int) error {
+	// emails on the outbox are only tied to the user by their address, which is about to be erased
+	if _, err := trx.Execute(
+		"DELETE FROM email_outbox WHERE tenant_id = $2 AND to_address <> '' AND to_address = (SELECT email FROM users WHERE id = $1 AND tenant_id = $2)",
+		userID, tenant.ID,
+	); err != nil {
+		return errors.Wrap(err, "failed to delete user's emails")
+	}
+
+	if _, err := trx.Execute(
+		"UPDATE users SET role = $3, status = $4, name = '', email = '', bio = null, link = null, locale = '', api_key = null, api_key_date = null WHERE id = $1 AND tenant_id = $2",
+		userID, tenant.ID, enum.RoleVisitor, enum.UserDeleted,
+	); err != nil {
+		return errors.Wrap(err, "failed to delete user")
+	}
+
+	var tables = []struct {
+		name       string
+		userColumn string
+	}{
+		{"user_providers", "user_id"},
+		{"user_settings", "user_id"},
+		{"user_passkeys", "user_id"},
+		{"user_push_subscriptions", "user_id"},
+		{"user_saved_searches", "user_id"},
+		{"notifications", "user_id"},
+		{"notifications", "author_id"},
+		{"email_digest_items", "user_id"},
+		{"post_votes", "user_id"},
+		{"post_subscribers", "user_id"},
+		{"email_verifications", "user_id"},
+	}
+
+	for _, table := range tables {
+		if _, err := trx.Execute(
+			fmt.Sprintf("DELETE FROM %s WHERE %s = $1 AND tenant_id = $2", table.name, table.userColumn),
+			userID, tenant.ID,
+		); err != nil {
+			return errors.Wrap(err, "failed to delete user's %s records", table)
+		}
+	}
+
+	return nil
+}
+
+// deleteUserContent removes every post and comment published by given user
+// Posts are removed along with everything attached to them, including other users' comments and votes
+// Returns the keys of the attachments that are no longer referenced
+func deleteUserContent(trx *dbx.Trx, tenant *entity.Tenant, userID int) ([]string, error) {
+	userPosts := "SELECT id FROM posts WHERE user_id = $1 AND tenant_id = $2"
+
+	type entry struct {
+		BlobKey string `db:"attachment_bkey"`
+	}
+
+	entries := []*entry{}
+	err := trx.Select(&entries, fmt.Sprintf(`
+		SELECT attachment_bkey FROM attachments
+		WHERE tenant_id = $2 AND (user_id = $1 OR post_id IN (%s))
+	`, userPosts), userID, tenant.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get user's attachments")
+	}
+
+	bkeys := make([]string, len(entries))
+	for i, entry := range entries {
+		bkeys[i] = entry.BlobKey
+	}
+
+	var commands = []struct {
+		description string
+		command     string
+	}{
+		{"attachments", "DELETE FROM attachments WHERE tenant_id = $2 AND (user_id = $1 OR post_id IN (%s))"},
+		{"content reports", "DELETE FROM content_reports WHERE tenant_id = $2 AND (post_id IN (%s) OR comment_id IN (SELECT id FROM comments WHERE tenant_id = $2 AND user_id = $1))"},
+		{"comments", "DELETE FROM comments WHERE tenant_id = $2 AND (user_id = $1 OR post_id IN (%s))"},
+		{"post votes", "DELETE FROM post_votes WHERE tenant_id = $2 AND post_id IN (%s)"},
+		{"post subscribers", "DELETE FROM post_subscribers WHERE tenant_id = $2 AND post_id IN (%s)"},
+		{"post tags", "DELETE FROM post_tags WHERE tenant_id = $2 AND post_id IN (%s)"},
+		{"notifications", "DELETE FROM notifications WHERE tenant_id = $2 AND post_id IN (%s)"},
+		{"email digest items", "DELETE FROM email_digest_items WHERE tenant_id = $2 AND post_id IN (%s)"},
+		{"duplicates", fmt.Sprintf("UPDATE posts SET original_id = NULL, status = %d WHERE tenant_id = $2 AND original_id IN (%%s)", enum.PostOpen)},
+		{"posts", "DELETE FROM posts WHERE tenant_id = $2 AND id IN (%s)"},
+	}
+
+	for _, c := range commands {
+		if _, err := trx.Execute(fmt.Sprintf(c.command, userPosts), userID, tenant.ID); err != nil {
+			return nil, errors.Wrap(err, "failed to delete user's %s", c.description)
+		}
+	}
+
+	return bkeys, nil
+}
+
 func regenerateAPIKey(ctx context.Context, c *cmd.RegenerateAPIKey) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
 		apiKey := entity.GenerateEmailVerificationKey()
@@ -228,6 +443,17 @@ func changeUserEmail(ctx context.Context, c *cmd.ChangeUserEmail) error {
 	})
 }
 
+func changeUserName(ctx context.Context, c *cmd.ChangeUserName) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		cmd := "UPDATE users SET name = $3 WHERE id = $1 AND tenant_id = $2"
+		_, err := trx.Execute(cmd, c.UserID, tenant.ID, c.Name)
+		if err != nil {
+			return errors.Wrap(err, "failed to update user's name")
+		}
+		return nil
+	})
+}
+
 func updateCurrentUserSettings(ctx context.Context, c *cmd.UpdateCurrentUserSettings) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
 		if user != nil && c.Settings != nil && len(c.Settings) > 0 {
@@ -280,8 +506,8 @@ func registerUser(ctx context.Context, c *cmd.RegisterUser) error {
 		c.User.Status = enum.UserActive
 		c.User.Email = strings.ToLower(strings.TrimSpace(c.User.Email))
 		if err := trx.Get(&c.User.ID,
-			"INSERT INTO users (name, email, created_at, tenant_id, role, status, avatar_type, avatar_bkey) VALUES ($1, $2, $3, $4, $5, $6, $7, '') RETURNING id",
-			c.User.Name, c.User.Email, now, tenant.ID, c.User.Role, enum.UserActive, enum.AvatarTypeGravatar); err != nil {
+			"INSERT INTO users (name, email, created_at, tenant_id, role, status, avatar_type, avatar_bkey, locale) VALUES ($1, $2, $3, $4, $5, $6, $7, '', $8) RETURNING id",
+			c.User.Name, c.User.Email, now, tenant.ID, c.User.Role, enum.UserActive, enum.AvatarTypeGravatar, c.User.Locale); err != nil {
 			return errors.Wrap(err, "failed to register new user")
 		}
 
@@ -312,8 +538,8 @@ func updateCurrentUser(ctx context.Context, c *cmd.UpdateCurrentUser) error {
 		if c.Avatar.Remove {
 			c.Avatar.BlobKey = ""
 		}
-		cmd := "UPDATE users SET name = $3, avatar_type = $4, avatar_bkey = $5 WHERE id = $1 AND tenant_id = $2"
-		_, err := trx.Execute(cmd, user.ID, tenant.ID, c.Name, c.AvatarType, c.Avatar.BlobKey)
+		cmd := "UPDATE users SET name = $3, avatar_type = $4, avatar_bkey = $5, bio = $6, link = $7, locale = $8 WHERE id = $1 AND tenant_id = $2"
+		_, err := trx.Execute(cmd, user.ID, tenant.ID, c.Name, c.AvatarType, c.Avatar.BlobKey, c.Bio, c.Link, c.Locale)
 		if err != nil {
 			return errors.Wrap(err, "failed to update user")
 		}
@@ -332,6 +558,114 @@ func getUserByID(ctx context.Context, q *query.GetUserByID) error {
 	})
 }
 
+func getUserProfile(ctx context.Context, q *query.GetUserProfile) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		u, err := queryUser(ctx, trx, "id = $1 AND tenant_id = $2", q.UserID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get user with id '%d'", q.UserID)
+		}
+
+		profile := dbUserProfile{}
+		err = trx.Get(&profile, "SELECT bio, link, profile_hidden FROM users WHERE id = $1 AND tenant_id = $2", q.UserID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get profile of user with id '%d'", q.UserID)
+		}
+
+		q.Result = &entity.UserProfile{
+			User:     u,
+			Bio:      profile.Bio.String,
+			Link:     profile.Link.String,
+			IsHidden: profile.IsHidden,
+		}
+		return nil
+	})
+}
+
+func getUserActivity(ctx context.Context, q *query.GetUserActivity) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		if q.Limit <= 0 || q.Limit > 100 {
+			q.Limit = 30
+		}
+
+		var posts []*dbPost
+		innerQuery := buildPostQuery(user, "p.tenant_id = $1 AND p.user_id = $2")
+		sql := fmt.Sprintf("SELECT * FROM (%s) AS q ORDER BY created_at DESC LIMIT %d", innerQuery, q.Limit)
+		if err := trx.Select(&posts, sql, tenant.ID, q.UserID); err != nil {
+			return errors.Wrap(err, "failed to get posts of user with id '%d'", q.UserID)
+		}
+
+		var votes []*dbPost
+		innerQuery = buildPostQuery(user, "p.tenant_id = $1 AND p.id IN (SELECT post_id FROM post_votes WHERE user_id = $2 AND tenant_id = $1)")
+		sql = fmt.Sprintf("SELECT * FROM (%s) AS q ORDER BY created_at DESC LIMIT %d", innerQuery, q.Limit)
+		if err := trx.Select(&votes, sql, tenant.ID, q.UserID); err != nil {
+			return errors.Wrap(err, "failed to get votes of user with id '%d'", q.UserID)
+		}
+
+		var comments []*dbUserComment
+		if err := trx.Select(&comments, `
+			SELECT c.id, c.content, c.created_at, p.number AS post_number, p.title AS post_title, p.slug AS post_slug
+			FROM comments c
+			INNER JOIN posts p
+			ON p.id = c.post_id
+			AND p.tenant_id = c.tenant_id
+			WHERE c.user_id = $1
+			AND c.tenant_id = $2
+			AND c.deleted_at IS NULL
+			AND p.status != $3
+			AND `+visibleContentCondition(user, "c")+`
+			AND `+visibleContentCondition(user, "p")+`
+			ORDER BY c.created_at DESC
+			LIMIT $4`, q.UserID, tenant.ID, enum.PostDeleted, q.Limit); err != nil {
+			return errors.Wrap(err, "failed to get comments of user with id '%d'", q.UserID)
+		}
+
+		q.Result = &entity.UserActivity{
+			Posts:    make([]*entity.Post, len(posts)),
+			Comments: make([]*entity.UserComment, len(comments)),
+			Votes:    make([]*entity.Post, len(votes)),
+		}
+		for i, post := range posts {
+			q.Result.Posts[i] = post.toModel(ctx)
+		}
+		for i, post := range votes {
+			q.Result.Votes[i] = post.toModel(ctx)
+		}
+		for i, comment := range comments {
+			q.Result.Comments[i] = &entity.UserComment{
+				ID:         comment.ID,
+				Content:    comment.Content,
+				CreatedAt:  comment.CreatedAt,
+				PostNumber: comment.PostNumber,
+				PostTitle:  comment.PostTitle,
+				PostSlug:   comment.PostSlug,
+			}
+		}
+		return nil
+	})
+}
+
+func getUserContentVelocity(ctx context.Context, q *query.GetUserContentVelocity) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		velocity := struct {
+			CreatedAt   time.Time `db:"created_at"`
+			RecentCount int       `db:"recent_count"`
+		}{}
+		err := trx.Get(&velocity, `
+			SELECT u.created_at,
+			       (SELECT COUNT(*) FROM posts WHERE tenant_id = $1 AND user_id = u.id AND created_at >= $3)
+			     + (SELECT COUNT(*) FROM comments WHERE tenant_id = $1 AND user_id = u.id AND created_at >= $3) AS recent_count
+			FROM users u
+			WHERE u.tenant_id = $1 AND u.id = $2`, tenant.ID, q.UserID, q.Since)
+		if err != nil {
+			return errors.Wrap(err, "failed to get content velocity of user with id '%d'", q.UserID)
+		}
+
+		q.Result.AccountCreatedAt = velocity.CreatedAt
+		q.Result.RecentCount = velocity.RecentCount
+		return nil
+	})
+}
+
 func getUserByEmail(ctx context.Context, q *query.GetUserByEmail) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
 		email := strings.ToLower(q.Email)
@@ -370,7 +704,7 @@ func getAllUsers(ctx context.Context, q *query.GetAllUsers) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
 		var users []*dbUser
 		err := trx.Select(&users, `
-			SELECT id, name, email, tenant_id, role, status, avatar_type, avatar_bkey
+			SELECT id, name, email, tenant_id, role, status, avatar_type, avatar_bkey, locale
 			FROM users 
 			WHERE tenant_id = $1 
 			AND status != $2
@@ -389,7 +723,7 @@ func getAllUsers(ctx context.Context, q *query.GetAllUsers) error {
 
 func queryUser(ctx context.Context, trx *dbx.Trx, filter string, args ...any) (*entity.User, error) {
 	user := dbUser{}
-	sql := fmt.Sprintf("SELECT id, name, email, tenant_id, role, status, avatar_type, avatar_bkey FROM users WHERE status != %d AND ", enum.UserDeleted)
+	sql := fmt.Sprintf("SELECT id, name, email, tenant_id, role, status, avatar_type, avatar_bkey, locale FROM users WHERE status != %d AND ", enum.UserDeleted)
 	err := trx.Get(&user, sql+filter, args...)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+type dbUserImport struct {
+	ID         int                     `db:"id"`
+	Status     int                     `db:"status"`
+	Total      int                     `db:"total"`
+	Processed  int                     `db:"processed"`
+	Created    int                     `db:"created"`
+	Updated    int                     `db:"updated"`
+	Failed     int                     `db:"failed"`
+	Errors     entity.UserImportErrors `db:"errors"`
+	CreatedAt  time.Time               `db:"created_at"`
+	FinishedAt dbx.NullTime            `db:"finished_at"`
+}
+
+func (i *dbUserImport) toModel() *entity.UserImport {
+	userImport := &entity.UserImport{
+		ID:        i.ID,
+		Status:    enum.UserImportStatus(i.Status),
+		Total:     i.Total,
+		Processed: i.Processed,
+		Created:   i.Created,
+		Updated:   i.Updated,
+		Failed:    i.Failed,
+		Errors:    i.Errors,
+		CreatedAt: i.CreatedAt,
+	}
+	if userImport.Errors == nil {
+		userImport.Errors = entity.UserImportErrors{}
+	}
+	if i.FinishedAt.Valid {
+		userImport.FinishedAt = &i.FinishedAt.Time
+	}
+	return userImport
+}
+
+func createUserImport(ctx context.Context, c *cmd.CreateUserImport) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		now := time.Now()
+
+		var id int
+		err := trx.Get(&id, `
+			INSERT INTO user_imports (tenant_id, user_id, status, total, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`, tenant.ID, user.ID, enum.UserImportRunning, c.Total, now)
+		if err != nil {
+			return errors.Wrap(err, "failed to create user import")
+		}
+
+		c.Result = &entity.UserImport{
+			ID:        id,
+			Status:    enum.UserImportRunning,
+			Total:     c.Total,
+			Errors:    entity.UserImportErrors{},
+			CreatedAt: now,
+		}
+		return nil
+	})
+}
+
+func saveUserImportProgress(ctx context.Context, c *cmd.SaveUserImportProgress) error {
+	tenant, ok := ctx.Value(app.TenantCtxKey).(*entity.Tenant)
+	if !ok || tenant == nil {
+		return errors.New("failed to save user import progress: tenant is required")
+	}
+
+	// Progress is stored on its own transaction, otherwise it would only be visible once the whole import is finished
+	trx, err := dbx.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to open transaction")
+	}
+
+	i := c.Import
+	var finishedAt *time.Time
+	if i.Status != enum.UserImportRunning {
+		now := time.Now()
+		finishedAt = &now
+		i.FinishedAt = finishedAt
+	}
+
+	_, err = trx.Execute(`
+		UPDATE user_imports
+		SET status = $3, processed = $4, created = $5, updated = $6, failed = $7, errors = $8, finished_at = $9
+		WHERE id = $1 AND tenant_id = $2
+	`, i.ID, tenant.ID, i.Status, i.Processed, i.Created, i.Updated, i.Failed, i.Errors, finishedAt)
+	if err != nil {
+		trx.MustRollback()
+		return errors.Wrap(err, "failed to save user import progress")
+	}
+
+	if err = trx.Commit(); err != nil {
+		return errors.Wrap(err, "failed commit transaction")
+	}
+	return nil
+}
+
+func getUserImportByID(ctx context.Context, q *query.GetUserImportByID) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		userImport := dbUserImport{}
+		err := trx.Get(&userImport, `
+			SELECT id, status, total, processed, created, updated, failed, errors, created_at, finished_at
+			FROM user_imports
+			WHERE id = $1 AND tenant_id = $2
+		`, q.ImportID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get user import with id '%d'", q.ImportID)
+		}
+
+		q.Result = userImport.toModel()
+		return nil
+	})
+}
@@ -0,0 +1,36 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func TestUserImportStorage_CreateAndGet(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	createImport := &cmd.CreateUserImport{Total: 42}
+	err := bus.Dispatch(jonSnowCtx, createImport)
+	Expect(err).IsNil()
+	Expect(createImport.Result.ID).NotEquals(0)
+
+	getImport := &query.GetUserImportByID{ImportID: createImport.Result.ID}
+	err = bus.Dispatch(jonSnowCtx, getImport)
+	Expect(err).IsNil()
+	Expect(getImport.Result.Status).Equals(enum.UserImportRunning)
+	Expect(getImport.Result.Total).Equals(42)
+	Expect(getImport.Result.Processed).Equals(0)
+	Expect(getImport.Result.Errors).HasLen(0)
+	Expect(getImport.Result.FinishedAt).IsNil()
+
+	getImport = &query.GetUserImportByID{ImportID: createImport.Result.ID}
+	err = bus.Dispatch(avengersTenantCtx, getImport)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+type dbUserSuspension struct {
+	ID        int          `db:"id"`
+	UserID    int          `db:"user_id"`
+	Reason    string       `db:"reason"`
+	ReadOnly  bool         `db:"read_only"`
+	CreatedAt time.Time    `db:"created_at"`
+	EndsAt    dbx.NullTime `db:"ends_at"`
+}
+
+func (s *dbUserSuspension) toModel() *entity.UserSuspension {
+	suspension := &entity.UserSuspension{
+		ID:        s.ID,
+		UserID:    s.UserID,
+		Reason:    s.Reason,
+		ReadOnly:  s.ReadOnly,
+		CreatedAt: s.CreatedAt,
+	}
+	if s.EndsAt.Valid {
+		suspension.EndsAt = &s.EndsAt.Time
+	}
+	return suspension
+}
+
+// Expired suspensions are lifted by a job, but they are ignored right away so users don't need to wait for it
+const sqlSelectActiveUserSuspensions = `
+	SELECT id, user_id, reason, read_only, created_at, ends_at
+	FROM user_suspensions
+	WHERE tenant_id = $1 AND lifted_at IS NULL AND (ends_at IS NULL OR ends_at > $2)
+`
+
+func suspendUser(ctx context.Context, c *cmd.SuspendUser) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		now := time.Now()
+		if _, err := trx.Execute(
+			"UPDATE user_suspensions SET lifted_at = $3 WHERE user_id = $1 AND tenant_id = $2 AND lifted_at IS NULL",
+			c.UserID, tenant.ID, now,
+		); err != nil {
+			return errors.Wrap(err, "failed to lift previous user suspension")
+		}
+
+		if _, err := trx.Execute(`
+			INSERT INTO user_suspensions (tenant_id, user_id, reason, read_only, created_at, created_by_id, ends_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			tenant.ID, c.UserID, c.Reason, c.ReadOnly, now, user.ID, c.EndsAt,
+		); err != nil {
+			return errors.Wrap(err, "failed to suspend user")
+		}
+		return nil
+	})
+}
+
+func liftUserSuspension(ctx context.Context, c *cmd.LiftUserSuspension) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		if _, err := trx.Execute(
+			"UPDATE user_suspensions SET lifted_at = $3 WHERE user_id = $1 AND tenant_id = $2 AND lifted_at IS NULL",
+			c.UserID, tenant.ID, time.Now(),
+		); err != nil {
+			return errors.Wrap(err, "failed to lift user suspension")
+		}
+		return nil
+	})
+}
+
+func liftExpiredUserSuspensions(ctx context.Context, c *cmd.LiftExpiredUserSuspensions) error {
+	trx, err := dbx.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to open transaction")
+	}
+
+	now := time.Now()
+	count, err := trx.Execute("UPDATE user_suspensions SET lifted_at = $1 WHERE ends_at <= $1 AND lifted_at IS NULL", now)
+	if err != nil {
+		trx.MustRollback()
+		return errors.Wrap(err, "failed to lift expired user suspensions")
+	}
+
+	if err = trx.Commit(); err != nil {
+		return errors.Wrap(err, "failed commit transaction")
+	}
+
+	c.NumOfLiftedSuspensions = int(count)
+	return nil
+}
+
+func getActiveUserSuspension(ctx context.Context, q *query.GetActiveUserSuspension) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		q.Result = nil
+
+		suspension := dbUserSuspension{}
+		err := trx.Get(&suspension, sqlSelectActiveUserSuspensions+" AND user_id = $3 ORDER BY id DESC LIMIT 1", tenant.ID, time.Now(), q.UserID)
+		if err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return nil
+			}
+			return errors.Wrap(err, "failed to get active user suspension")
+		}
+
+		q.Result = suspension.toModel()
+		return nil
+	})
+}
+
+func getActiveUserSuspensions(ctx context.Context, q *query.GetActiveUserSuspensions) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *entity.Tenant, user *entity.User) error {
+		suspensions := []*dbUserSuspension{}
+		err := trx.Select(&suspensions, sqlSelectActiveUserSuspensions+" ORDER BY id", tenant.ID, time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to get active user suspensions")
+		}
+
+		q.Result = make([]*entity.UserSuspension, len(suspensions))
+		for i, suspension := range suspensions {
+			q.Result[i] = suspension.toModel()
+		}
+		return nil
+	})
+}
@@ -0,0 +1,62 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestUserSuspensionStorage_SuspendAndLift(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	endsAt := time.Now().Add(24 * time.Hour)
+	err := bus.Dispatch(jonSnowCtx, &cmd.SuspendUser{UserID: aryaStark.ID, Reason: "Spamming", EndsAt: &endsAt})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.SuspendUser{UserID: aryaStark.ID, Reason: "Heated discussions", ReadOnly: true})
+	Expect(err).IsNil()
+
+	getSuspension := &query.GetActiveUserSuspension{UserID: aryaStark.ID}
+	err = bus.Dispatch(jonSnowCtx, getSuspension)
+	Expect(err).IsNil()
+	Expect(getSuspension.Result.Reason).Equals("Heated discussions")
+	Expect(getSuspension.Result.ReadOnly).IsTrue()
+	Expect(getSuspension.Result.EndsAt).IsNil()
+
+	getSuspensions := &query.GetActiveUserSuspensions{}
+	err = bus.Dispatch(jonSnowCtx, getSuspensions)
+	Expect(err).IsNil()
+	Expect(getSuspensions.Result).HasLen(1)
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.LiftUserSuspension{UserID: aryaStark.ID})
+	Expect(err).IsNil()
+
+	getSuspension = &query.GetActiveUserSuspension{UserID: aryaStark.ID}
+	err = bus.Dispatch(jonSnowCtx, getSuspension)
+	Expect(err).IsNil()
+	Expect(getSuspension.Result).IsNil()
+}
+
+func TestUserSuspensionStorage_LiftExpired(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	endsAt := time.Now().Add(-1 * time.Minute)
+	err := bus.Dispatch(jonSnowCtx, &cmd.SuspendUser{UserID: aryaStark.ID, Reason: "Spamming", EndsAt: &endsAt})
+	Expect(err).IsNil()
+
+	getSuspension := &query.GetActiveUserSuspension{UserID: aryaStark.ID}
+	err = bus.Dispatch(jonSnowCtx, getSuspension)
+	Expect(err).IsNil()
+	Expect(getSuspension.Result).IsNil()
+
+	liftExpired := &cmd.LiftExpiredUserSuspensions{}
+	err = bus.Dispatch(demoTenantCtx, liftExpired)
+	Expect(err).IsNil()
+	Expect(liftExpired.NumOfLiftedSuspensions).Equals(1)
+}
@@ -363,6 +363,216 @@ func TestUserStorage_Delete(t *testing.T) {
 	Expect(getByID.Result).IsNil()
 }
 
+func TestUserStorage_EraseUser(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "My new post", Description: "with this description"}
+	err := bus.Dispatch(aryaStarkCtx, newPost)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.EraseUser{UserID: aryaStark.ID})
+	Expect(err).IsNil()
+
+	getByID := &query.GetUserByID{UserID: aryaStark.ID}
+	err = bus.Dispatch(jonSnowCtx, getByID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	postByID := &query.GetPostByID{PostID: newPost.Result.ID}
+	err = bus.Dispatch(jonSnowCtx, postByID)
+	Expect(err).IsNil()
+	Expect(postByID.Result.User.Name).Equals("")
+}
+
+func TestUserStorage_EraseUser_DeleteContent(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "My new post", Description: "with this description"}
+	err := bus.Dispatch(aryaStarkCtx, newPost)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.AddNewComment{Post: newPost.Result, Content: "Comment #1"})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.EraseUser{UserID: aryaStark.ID, DeleteContent: true})
+	Expect(err).IsNil()
+
+	postByID := &query.GetPostByID{PostID: newPost.Result.ID}
+	err = bus.Dispatch(jonSnowCtx, postByID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestUserStorage_MergeUsers(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	first := &cmd.AddNewPost{Title: "My first post", Description: "voted by both"}
+	second := &cmd.AddNewPost{Title: "My second post", Description: "voted by sansa only"}
+	err := bus.Dispatch(sansaStarkCtx, first, second)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx,
+		&cmd.AddVote{Post: first.Result, User: aryaStark},
+		&cmd.AddVote{Post: first.Result, User: sansaStark},
+		&cmd.AddVote{Post: second.Result, User: sansaStark},
+		&cmd.AddNewComment{Post: first.Result, Content: "Comment #1"},
+	)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(sansaStarkCtx, &cmd.AddNewComment{Post: first.Result, Content: "Comment #2"})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.MergeUsers{SourceUserID: sansaStark.ID, TargetUserID: aryaStark.ID})
+	Expect(err).IsNil()
+
+	getByID := &query.GetUserByID{UserID: sansaStark.ID}
+	err = bus.Dispatch(jonSnowCtx, getByID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	exists, err := trx.Exists("SELECT 1 FROM users WHERE id = $1", sansaStark.ID)
+	Expect(err).IsNil()
+	Expect(exists).IsFalse()
+
+	postByID := &query.GetPostByID{PostID: second.Result.ID}
+	err = bus.Dispatch(jonSnowCtx, postByID)
+	Expect(err).IsNil()
+	Expect(postByID.Result.User.ID).Equals(aryaStark.ID)
+	Expect(postByID.Result.VotesCount).Equals(1)
+
+	postByID = &query.GetPostByID{PostID: first.Result.ID}
+	err = bus.Dispatch(jonSnowCtx, postByID)
+	Expect(err).IsNil()
+	Expect(postByID.Result.VotesCount).Equals(1)
+
+	commentsByPost := &query.GetCommentsByPost{Post: first.Result}
+	err = bus.Dispatch(jonSnowCtx, commentsByPost)
+	Expect(err).IsNil()
+	Expect(commentsByPost.Result).HasLen(2)
+	Expect(commentsByPost.Result[1].User.ID).Equals(aryaStark.ID)
+}
+
+func TestUserStorage_MergeUsers_WithAuditLogs(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "My first post", Description: "by sansa"}
+	bus.MustDispatch(sansaStarkCtx, newPost)
+
+	// Sansa acts on her own and is also impersonated by Jon
+	impersonatedCtx := context.WithValue(sansaStarkCtx, app.ImpersonatorCtxKey, jonSnow)
+	bus.MustDispatch(sansaStarkCtx, &cmd.AddAuditLog{Action: enum.AuditTagDeleted, TargetType: "tag", TargetID: "bug"})
+	bus.MustDispatch(impersonatedCtx, &cmd.AddAuditLog{Action: enum.AuditTagDeleted, TargetType: "tag", TargetID: "feature"})
+
+	err := bus.Dispatch(jonSnowCtx, &cmd.MergeUsers{SourceUserID: sansaStark.ID, TargetUserID: aryaStark.ID})
+	Expect(err).IsNil()
+
+	getByID := &query.GetUserByID{UserID: sansaStark.ID}
+	err = bus.Dispatch(jonSnowCtx, getByID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	postByID := &query.GetPostByID{PostID: newPost.Result.ID}
+	err = bus.Dispatch(jonSnowCtx, postByID)
+	Expect(err).IsNil()
+	Expect(postByID.Result.User.ID).Equals(aryaStark.ID)
+
+	// The audit logs still refer to the anonymized source user
+	actorLogs := &query.SearchAuditLogs{ActorID: sansaStark.ID}
+	err = bus.Dispatch(jonSnowCtx, actorLogs)
+	Expect(err).IsNil()
+	Expect(actorLogs.Result).HasLen(1)
+
+	count, err := trx.Count("SELECT 1 FROM audit_logs WHERE impersonated_id = $1", sansaStark.ID)
+	Expect(err).IsNil()
+	Expect(count).Equals(1)
+
+	name, email := "", ""
+	err = trx.Scalar(&name, "SELECT name FROM users WHERE id = $1", sansaStark.ID)
+	Expect(err).IsNil()
+	Expect(name).Equals("")
+	err = trx.Scalar(&email, "SELECT email FROM users WHERE id = $1", sansaStark.ID)
+	Expect(err).IsNil()
+	Expect(email).Equals("")
+}
+
+func TestUserStorage_Profile(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := bus.Dispatch(aryaStarkCtx, &cmd.UpdateCurrentUser{
+		Name:   "Arya Stark",
+		Avatar: &dto.ImageUpload{},
+		Bio:    "A girl has no name",
+		Link:   "https://braavos.com",
+	})
+	Expect(err).IsNil()
+
+	getProfile := &query.GetUserProfile{UserID: aryaStark.ID}
+	err = bus.Dispatch(jonSnowCtx, getProfile)
+	Expect(err).IsNil()
+	Expect(getProfile.Result.User.ID).Equals(aryaStark.ID)
+	Expect(getProfile.Result.Bio).Equals("A girl has no name")
+	Expect(getProfile.Result.Link).Equals("https://braavos.com")
+	Expect(getProfile.Result.IsHidden).IsFalse()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.HideUserProfile{UserID: aryaStark.ID})
+	Expect(err).IsNil()
+
+	getProfile = &query.GetUserProfile{UserID: aryaStark.ID}
+	err = bus.Dispatch(jonSnowCtx, getProfile)
+	Expect(err).IsNil()
+	Expect(getProfile.Result.IsHidden).IsTrue()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.ShowUserProfile{UserID: aryaStark.ID})
+	Expect(err).IsNil()
+
+	getProfile = &query.GetUserProfile{UserID: aryaStark.ID}
+	err = bus.Dispatch(jonSnowCtx, getProfile)
+	Expect(err).IsNil()
+	Expect(getProfile.Result.IsHidden).IsFalse()
+
+	getProfile = &query.GetUserProfile{UserID: aryaStark.ID}
+	err = bus.Dispatch(avengersTenantCtx, getProfile)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestUserStorage_Activity(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	first := &cmd.AddNewPost{Title: "My first post", Description: "by arya"}
+	err := bus.Dispatch(aryaStarkCtx, first)
+	Expect(err).IsNil()
+
+	second := &cmd.AddNewPost{Title: "My second post", Description: "by sansa"}
+	err = bus.Dispatch(sansaStarkCtx, second)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(aryaStarkCtx,
+		&cmd.AddVote{Post: second.Result, User: aryaStark},
+		&cmd.AddNewComment{Post: second.Result, Content: "Nice idea"},
+	)
+	Expect(err).IsNil()
+
+	getActivity := &query.GetUserActivity{UserID: aryaStark.ID}
+	err = bus.Dispatch(jonSnowCtx, getActivity)
+	Expect(err).IsNil()
+	Expect(getActivity.Result.Posts).HasLen(1)
+	Expect(getActivity.Result.Posts[0].ID).Equals(first.Result.ID)
+	Expect(getActivity.Result.Votes).HasLen(1)
+	Expect(getActivity.Result.Votes[0].ID).Equals(second.Result.ID)
+	Expect(getActivity.Result.Comments).HasLen(1)
+	Expect(getActivity.Result.Comments[0].Content).Equals("Nice idea")
+	Expect(getActivity.Result.Comments[0].PostNumber).Equals(second.Result.Number)
+
+	getActivity = &query.GetUserActivity{UserID: jonSnow.ID}
+	err = bus.Dispatch(jonSnowCtx, getActivity)
+	Expect(err).IsNil()
+	Expect(getActivity.Result.Posts).HasLen(0)
+	Expect(getActivity.Result.Votes).HasLen(0)
+	Expect(getActivity.Result.Comments).HasLen(0)
+}
+
 func TestUserStorage_APIKey(t *testing.T) {
 	SetupDatabaseTest(t)
 	defer TeardownDatabaseTest()
@@ -0,0 +1,108 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/webpush"
+)
+
+// ttl is how long push services keep a message while the device is offline
+const ttl = 7 * 24 * time.Hour
+
+func init() {
+	bus.Register(Service{})
+}
+
+type Service struct{}
+
+func (s Service) Name() string {
+	return "Web Push"
+}
+
+func (s Service) Category() string {
+	return "webpush"
+}
+
+func (s Service) Enabled() bool {
+	return env.IsWebPushEnabled()
+}
+
+func (s Service) Init() {
+	bus.AddListener(sendWebPush)
+}
+
+func subject() string {
+	if env.Config.WebPush.Subject != "" {
+		return env.Config.WebPush.Subject
+	}
+	return "mailto:" + env.Config.Email.NoReply
+}
+
+// sendWebPush delivers a notification to a single device
+// Subscriptions that the push service reports as gone are removed, the browser has unsubscribed or expired them
+func sendWebPush(ctx context.Context, c *cmd.SendWebPush) error {
+	// Subscriptions registered before endpoints were restricted may point anywhere
+	if !webpush.IsPushServiceEndpoint(c.Subscription.Endpoint) {
+		return errors.New("push subscription '%d' has an unknown push service endpoint", c.Subscription.ID)
+	}
+
+	payload, err := json.Marshal(dto.Props{
+		"title": c.Title,
+		"body":  c.Body,
+		"url":   c.URL,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal push payload")
+	}
+
+	body, err := webpush.Encrypt(webpush.Keys{P256dh: c.Subscription.P256dh, Auth: c.Subscription.Auth}, payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt push payload")
+	}
+
+	cfg := env.Config.WebPush
+	authorization, err := webpush.VAPIDAuthorization(c.Subscription.Endpoint, subject(), cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, time.Now().Add(12*time.Hour))
+	if err != nil {
+		return err
+	}
+
+	req := &cmd.HTTPRequest{
+		URL:    c.Subscription.Endpoint,
+		Body:   bytes.NewReader(body),
+		Method: http.MethodPost,
+		Headers: map[string]string{
+			"Authorization":    authorization,
+			"Content-Encoding": "aes128gcm",
+			"Content-Type":     "application/octet-stream",
+			"TTL":              strconv.Itoa(int(ttl.Seconds())),
+			"Urgency":          "normal",
+		},
+	}
+	if err := bus.Dispatch(ctx, req); err != nil {
+		return errors.Wrap(err, "failed to send push notification")
+	}
+
+	switch {
+	case req.ResponseStatusCode == http.StatusNotFound || req.ResponseStatusCode == http.StatusGone:
+		log.Debugf(ctx, "Removing expired push subscription @{SubscriptionID} of user @{UserID}", dto.Props{
+			"SubscriptionID": c.Subscription.ID,
+			"UserID":         c.Subscription.UserID,
+		})
+		return bus.Dispatch(ctx, &cmd.DeletePushSubscription{UserID: c.Subscription.UserID, Endpoint: c.Subscription.Endpoint})
+	case req.ResponseStatusCode >= 400:
+		return errors.New("push service responded with status code '%d': %s", req.ResponseStatusCode, string(req.ResponseBody))
+	}
+
+	return nil
+}
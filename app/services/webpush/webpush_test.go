@@ -0,0 +1,92 @@
+package webpush
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/webpush"
+)
+
+var subscription = &entity.PushSubscription{
+	ID:       1,
+	UserID:   2,
+	Endpoint: "https://fcm.googleapis.com/fcm/send/abc123",
+	P256dh:   "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+	Auth:     "BTBZMqHH6r4Tts7J_aSIgg",
+}
+
+func setupVAPIDKeys() {
+	publicKey, privateKey, _ := webpush.GenerateVAPIDKeys()
+	env.Config.WebPush.VAPIDPublicKey = publicKey
+	env.Config.WebPush.VAPIDPrivateKey = privateKey
+}
+
+func TestSendWebPush(t *testing.T) {
+	RegisterT(t)
+	setupVAPIDKeys()
+
+	var request *cmd.HTTPRequest
+	var body []byte
+	bus.AddHandler(func(ctx context.Context, c *cmd.HTTPRequest) error {
+		request = c
+		body, _ = io.ReadAll(c.Body)
+		c.ResponseStatusCode = http.StatusCreated
+		return nil
+	})
+
+	err := sendWebPush(context.Background(), &cmd.SendWebPush{
+		Subscription: subscription,
+		Title:        "Jon Snow changed status of Add dark mode to Completed",
+		Body:         "Add dark mode",
+		URL:          "https://demo.test.fider.io/posts/1/add-dark-mode",
+	})
+	Expect(err).IsNil()
+	Expect(request.URL).Equals(subscription.Endpoint)
+	Expect(request.Method).Equals("POST")
+	Expect(request.Headers["Content-Encoding"]).Equals("aes128gcm")
+	Expect(request.Headers["TTL"]).Equals("604800")
+	Expect(strings.HasPrefix(request.Headers["Authorization"], "vapid t=")).IsTrue()
+	Expect(len(body) > 86).IsTrue()
+}
+
+func TestSendWebPush_ExpiredSubscription(t *testing.T) {
+	RegisterT(t)
+	setupVAPIDKeys()
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.HTTPRequest) error {
+		c.ResponseStatusCode = http.StatusGone
+		return nil
+	})
+
+	var deleted *cmd.DeletePushSubscription
+	bus.AddHandler(func(ctx context.Context, c *cmd.DeletePushSubscription) error {
+		deleted = c
+		return nil
+	})
+
+	err := sendWebPush(context.Background(), &cmd.SendWebPush{Subscription: subscription, Title: "Hello"})
+	Expect(err).IsNil()
+	Expect(deleted.Endpoint).Equals(subscription.Endpoint)
+}
+
+func TestSendWebPush_Failure(t *testing.T) {
+	RegisterT(t)
+	setupVAPIDKeys()
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.HTTPRequest) error {
+		c.ResponseStatusCode = http.StatusBadRequest
+		c.ResponseBody = []byte("invalid payload")
+		return nil
+	})
+
+	err := sendWebPush(context.Background(), &cmd.SendWebPush{Subscription: subscription, Title: "Hello"})
+	Expect(err).IsNotNil()
+}
@@ -0,0 +1,41 @@
+package tasks
+
+import (
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/inbound"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/worker"
+)
+
+// ReceiveEmail handles an email sent to the inbound address, which is either a bounce message or a reply to a notification
+// Bounced addresses are only trusted when they match the signature of the bounce address
+func ReceiveEmail(email *dto.InboundEmail) worker.Task {
+	for _, recipient := range email.Recipients {
+		if inbound.IsBounceAddress(recipient) {
+			return SupressBouncedEmails(inbound.VerifiedBounces(email))
+		}
+	}
+	return ReplyByEmail(email)
+}
+
+// SupressBouncedEmails stops sending emails to addresses that bounced or complained
+func SupressBouncedEmails(addresses []string) worker.Task {
+	return describe("Supress bounced emails", func(c *worker.Context) error {
+		if len(addresses) == 0 {
+			log.Warn(c, "Bounce message was discarded because it has no verified recipients")
+			return nil
+		}
+
+		supressEmail := &cmd.SupressEmail{EmailAddresses: addresses}
+		if err := bus.Dispatch(c, supressEmail); err != nil {
+			return c.Failure(err)
+		}
+
+		log.Infof(c, "@{Count} account(s) marked with supressed email after a bounce or complaint", dto.Props{
+			"Count": supressEmail.NumOfSupressedEmailAddresses,
+		})
+		return nil
+	})
+}
@@ -0,0 +1,70 @@
+package tasks_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/inbound"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/tasks"
+)
+
+func TestSupressBouncedEmailsTask(t *testing.T) {
+	RegisterT(t)
+
+	var supressEmail *cmd.SupressEmail
+	bus.AddHandler(func(ctx context.Context, c *cmd.SupressEmail) error {
+		supressEmail = c
+		c.NumOfSupressedEmailAddresses = len(c.EmailAddresses)
+		return nil
+	})
+
+	err := mock.NewWorker().Execute(tasks.SupressBouncedEmails([]string{"jon.snow@got.com"}))
+	Expect(err).IsNil()
+	Expect(supressEmail.EmailAddresses).Equals([]string{"jon.snow@got.com"})
+}
+
+func TestReceiveEmailTask_VerifiedBounce(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Address = "reply@inbound.got.com"
+
+	var supressEmail *cmd.SupressEmail
+	bus.AddHandler(func(ctx context.Context, c *cmd.SupressEmail) error {
+		supressEmail = c
+		return nil
+	})
+
+	task := tasks.ReceiveEmail(&dto.InboundEmail{
+		From:       "MAILER-DAEMON@mx.got.com",
+		Recipients: []string{inbound.BounceAddress("jon.snow@got.com")},
+		Bounces:    []string{"jon.snow@got.com", "arya.stark@got.com"},
+	})
+
+	err := mock.NewWorker().Execute(task)
+	Expect(err).IsNil()
+	Expect(supressEmail.EmailAddresses).Equals([]string{"jon.snow@got.com"})
+}
+
+func TestReceiveEmailTask_ForgedBounce(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Address = "reply@inbound.got.com"
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.SupressEmail) error {
+		return nil
+	})
+
+	task := tasks.ReceiveEmail(&dto.InboundEmail{
+		From:       "MAILER-DAEMON@mx.got.com",
+		Recipients: []string{inbound.BounceAddress("arya.stark@got.com")},
+		Bounces:    []string{"jon.snow@got.com"},
+	})
+
+	err := mock.NewWorker().Execute(task)
+	Expect(err).IsNil()
+	ExpectHandler(&cmd.SupressEmail{}).CalledTimes(0)
+}
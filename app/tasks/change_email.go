@@ -25,7 +25,7 @@ func SendChangeEmailConfirmation(action *actions.ChangeUserEmail) worker.Task {
 			"link":     link(web.BaseURL(c), "/change-email/verify?k=%s", action.VerificationKey),
 		})
 
-		bus.Publish(c, &cmd.SendMail{
+		err := bus.Dispatch(c, &cmd.SendMail{
 			From:         dto.Recipient{Name: c.Tenant().Name},
 			To:           []dto.Recipient{to},
 			TemplateName: "change_emailaddress_email",
@@ -33,6 +33,9 @@ func SendChangeEmailConfirmation(action *actions.ChangeUserEmail) worker.Task {
 				"logo": web.LogoURL(c),
 			},
 		})
+		if err != nil {
+			return c.Failure(err)
+		}
 
 		return nil
 	})
@@ -46,30 +46,37 @@ func NotifyAboutDeletedPost(post *entity.Post) worker.Task {
 			return c.Failure(err)
 		}
 
+		tenant := c.Tenant()
+		baseURL, logoURL := web.BaseURL(c), web.LogoURL(c)
+
 		to := make([]dto.Recipient, 0)
 		for _, user := range users {
 			if user.ID != author.ID {
-				to = append(to, dto.NewRecipient(user.Name, user.Email, dto.Props{}))
+				locale := recipientLocale(c, user)
+				recipient := dto.NewRecipient(user.Name, user.Email, dto.Props{
+					"change": linkWithText(i18n.T(i18n.WithLocale(c, locale), "email.subscription.change"), baseURL, "/settings"),
+				})
+				recipient.Locale = locale
+				to = append(to, recipient)
 			}
 		}
 
-		tenant := c.Tenant()
-		baseURL, logoURL := web.BaseURL(c), web.LogoURL(c)
-
 		props := dto.Props{
 			"title":    post.Title,
 			"siteName": tenant.Name,
 			"content":  markdown.Full(post.Response.Text),
-			"change":   linkWithText(i18n.T(c, "email.subscription.change"), baseURL, "/settings"),
 			"logo":     logoURL,
 		}
 
-		bus.Publish(c, &cmd.SendMail{
+		err = bus.Dispatch(c, &cmd.SendMail{
 			From:         dto.Recipient{Name: c.User().Name},
 			To:           to,
 			TemplateName: "delete_post",
 			Props:        props,
 		})
+		if err != nil {
+			return c.Failure(err)
+		}
 
 		webhookProps := webhook.Props{}
 		webhookProps.SetPost(post, "post", baseURL, true, true)
@@ -38,6 +38,10 @@ func TestNotifyAboutDeletePostTask(t *testing.T) {
 		return nil
 	})
 
+	bus.AddHandler(func(ctx context.Context, q *query.GetSavedSearchFollowers) error {
+		return nil
+	})
+
 	var triggerWebhooks *cmd.TriggerWebhooks
 	bus.AddHandler(func(ctx context.Context, c *cmd.TriggerWebhooks) error {
 		triggerWebhooks = c
@@ -76,7 +80,6 @@ func TestNotifyAboutDeletePostTask(t *testing.T) {
 		"title":    "Add support for TypeScript",
 		"siteName": "Demonstration",
 		"content":  template.HTML("<p>Invalid post!</p>"),
-		"change":   "<a href='http://domain.com/settings'>change your notification preferences</a>",
 		"logo":     "https://fider.io/images/logo-100x100.png",
 	})
 	Expect(emailmock.MessageHistory[0].From).Equals(dto.Recipient{
@@ -86,7 +89,9 @@ func TestNotifyAboutDeletePostTask(t *testing.T) {
 	Expect(emailmock.MessageHistory[0].To[0]).Equals(dto.Recipient{
 		Name:    "Arya Stark",
 		Address: "arya.stark@got.com",
-		Props:   dto.Props{},
+		Props: dto.Props{
+			"change": "<a href='http://domain.com/settings'>change your notification preferences</a>",
+		},
 	})
 
 	Expect(addNewNotification).IsNotNil()
@@ -0,0 +1,20 @@
+package tasks
+
+import (
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/worker"
+)
+
+// DeleteErasedUserBlobs removes the avatar and attachments of a user that has been erased
+// It runs once the erasure is committed, so that a failed erasure doesn't leave the user with broken images
+func DeleteErasedUserBlobs(keys []string) worker.Task {
+	return describe("Delete erased user blobs", func(c *worker.Context) error {
+		for _, key := range keys {
+			if err := bus.Dispatch(c, &cmd.DeleteBlob{Key: key}); err != nil {
+				return c.Failure(err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,30 @@
+package tasks_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/tasks"
+)
+
+func TestDeleteErasedUserBlobsTask(t *testing.T) {
+	RegisterT(t)
+
+	deletedBlobs := make([]string, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.DeleteBlob) error {
+		deletedBlobs = append(deletedBlobs, c.Key)
+		return nil
+	})
+
+	err := mock.NewWorker().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(tasks.DeleteErasedUserBlobs([]string{"avatars/arya.png", "attachments/needle.png"}))
+
+	Expect(err).IsNil()
+	Expect(deletedBlobs).Equals([]string{"avatars/arya.png", "attachments/needle.png"})
+}
@@ -0,0 +1,193 @@
+package tasks
+
+import (
+	"strings"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/worker"
+)
+
+// number of rows processed between each progress update
+const importProgressInterval = 100
+
+// number of recipients of each invitation email
+const importInvitesBatchSize = 100
+
+//ImportUsers creates or updates one user for each valid row of given import
+func ImportUsers(userImport *entity.UserImport, action *actions.ImportUsers) worker.Task {
+	return describe("Import users", func(c *worker.Context) error {
+		saveProgress := func() error {
+			return bus.Dispatch(c, &cmd.SaveUserImportProgress{Import: userImport})
+		}
+
+		// Rows imported so far are rolled back with the task, so they are not reported as created or updated
+		fail := func(err error) error {
+			userImport.Status = enum.UserImportFailed
+			userImport.Created, userImport.Updated = 0, 0
+			if saveErr := saveProgress(); saveErr != nil {
+				return c.Failure(saveErr)
+			}
+			return c.Failure(err)
+		}
+
+		allOrganizations := &query.GetAllOrganizations{}
+		if err := bus.Dispatch(c, allOrganizations); err != nil {
+			return fail(err)
+		}
+
+		organizations := make(map[string]*entity.Organization)
+		for _, organization := range allOrganizations.Result {
+			organizations[strings.ToLower(organization.Name)] = organization
+			if organization.ExternalID != "" {
+				organizations[organization.ExternalID] = organization
+			}
+		}
+
+		invitations := make([]*actions.UserInvitation, 0)
+		for _, row := range action.Rows {
+			if row.IsValid() {
+				user, created, err := importUser(c, row, organizations)
+				if err != nil {
+					return fail(err)
+				}
+
+				if created {
+					userImport.Created++
+					if action.SendInvites && user.Email != "" {
+						invitations = append(invitations, &actions.UserInvitation{
+							Email:           user.Email,
+							VerificationKey: entity.GenerateEmailVerificationKey(),
+						})
+					}
+				} else {
+					userImport.Updated++
+				}
+
+				if row.Role != 0 && row.Role != user.Role {
+					userImport.Errors = append(userImport.Errors, &entity.UserImportError{
+						Row:      row.Row,
+						Messages: []string{"Administrators keep their role, it can only be changed from the Members page."},
+					})
+				}
+			} else {
+				userImport.Failed++
+				userImport.Errors = append(userImport.Errors, &entity.UserImportError{
+					Row:      row.Row,
+					Messages: row.Errors,
+				})
+			}
+
+			userImport.Processed++
+			if userImport.Processed%importProgressInterval == 0 {
+				if err := saveProgress(); err != nil {
+					return c.Failure(err)
+				}
+			}
+		}
+
+		for start := 0; start < len(invitations); start += importInvitesBatchSize {
+			end := start + importInvitesBatchSize
+			if end > len(invitations) {
+				end = len(invitations)
+			}
+			if err := SendInvites(action.Subject, action.Message, invitations[start:end]).Job(c); err != nil {
+				return fail(err)
+			}
+		}
+
+		userImport.Status = enum.UserImportCompleted
+		if err := saveProgress(); err != nil {
+			return c.Failure(err)
+		}
+
+		return nil
+	})
+}
+
+// importUser finds the user of given row by its external ID or email, creating it when none is found
+func importUser(c *worker.Context, row *actions.ImportedUser, organizations map[string]*entity.Organization) (*entity.User, bool, error) {
+	var user *entity.User
+
+	if row.Reference != "" {
+		getByReference := &query.GetUserByProvider{Provider: "reference", UID: row.Reference}
+		err := bus.Dispatch(c, getByReference)
+		if err != nil && errors.Cause(err) != app.ErrNotFound {
+			return nil, false, err
+		}
+		user = getByReference.Result
+	}
+
+	if user == nil && row.Email != "" {
+		getByEmail := &query.GetUserByEmail{Email: row.Email}
+		err := bus.Dispatch(c, getByEmail)
+		if err != nil && errors.Cause(err) != app.ErrNotFound {
+			return nil, false, err
+		}
+		user = getByEmail.Result
+	}
+
+	created := user == nil
+	if created {
+		user = &entity.User{
+			Tenant: c.Tenant(),
+			Name:   row.Name,
+			Email:  row.Email,
+			Role:   row.Role,
+		}
+		if user.Role == 0 {
+			user.Role = enum.RoleVisitor
+		}
+		if row.Reference != "" {
+			user.Providers = []*entity.UserProvider{{Name: "reference", UID: row.Reference}}
+		}
+		if err := bus.Dispatch(c, &cmd.RegisterUser{User: user}); err != nil {
+			return nil, false, err
+		}
+	} else {
+		// administrators are never demoted by an import, including the one running it
+		if row.Role != 0 && row.Role != user.Role && user.Role != enum.RoleAdministrator {
+			if err := bus.Dispatch(c, &cmd.ChangeUserRole{UserID: user.ID, Role: row.Role}); err != nil {
+				return nil, false, err
+			}
+			user.Role = row.Role
+		}
+
+		if row.Reference != "" && !user.HasProvider("reference") {
+			if err := bus.Dispatch(c, &cmd.RegisterUserProvider{
+				UserID:       user.ID,
+				ProviderName: "reference",
+				ProviderUID:  row.Reference,
+			}); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	if row.Organization != "" {
+		organization, ok := organizations[row.Organization]
+		if !ok {
+			organization, ok = organizations[strings.ToLower(row.Organization)]
+		}
+		if !ok {
+			saveOrganization := &cmd.SaveOrganization{Name: row.Organization}
+			if err := bus.Dispatch(c, saveOrganization); err != nil {
+				return nil, false, err
+			}
+			organization = saveOrganization.Result
+			organizations[strings.ToLower(organization.Name)] = organization
+		}
+
+		if err := bus.Dispatch(c, &cmd.SetUserOrganization{UserID: user.ID, OrganizationID: organization.ID}); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return user, created, nil
+}
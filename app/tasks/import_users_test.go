@@ -0,0 +1,220 @@
+package tasks_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/services/email/emailmock"
+	"github.com/getfider/fider/app/tasks"
+)
+
+func TestImportUsers(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	nightsWatch := &entity.Organization{ID: 1, Name: "Night's Watch"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetAllOrganizations) error {
+		q.Result = []*entity.Organization{nightsWatch}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByProvider) error {
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		if q.Email == mock.AryaStark.Email {
+			q.Result = mock.AryaStark
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	registered := make([]*entity.User, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.RegisterUser) error {
+		c.User.ID = 100 + len(registered)
+		registered = append(registered, c.User)
+		return nil
+	})
+
+	var changeRole *cmd.ChangeUserRole
+	bus.AddHandler(func(ctx context.Context, c *cmd.ChangeUserRole) error {
+		changeRole = c
+		return nil
+	})
+
+	var registerProvider *cmd.RegisterUserProvider
+	bus.AddHandler(func(ctx context.Context, c *cmd.RegisterUserProvider) error {
+		registerProvider = c
+		return nil
+	})
+
+	var saveOrganization *cmd.SaveOrganization
+	bus.AddHandler(func(ctx context.Context, c *cmd.SaveOrganization) error {
+		saveOrganization = c
+		c.Result = &entity.Organization{ID: 2, Name: c.Name}
+		return nil
+	})
+
+	organizations := make(map[int]int)
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetUserOrganization) error {
+		organizations[c.UserID] = c.OrganizationID
+		return nil
+	})
+
+	progress := make([]enum.UserImportStatus, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.SaveUserImportProgress) error {
+		progress = append(progress, c.Import.Status)
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.SaveVerificationKey) error {
+		return nil
+	})
+
+	action := &actions.ImportUsers{
+		CSV: `name,email,role,external_id,organization
+Jon Snow,jon.snow@got.com,collaborator,,night's watch
+Arya Stark,arya.stark@got.com,collaborator,arya,Faceless Men
+Sansa Stark,not-an-email,,,
+`,
+		SendInvites: true,
+		Subject:     "Welcome",
+		Message:     "Join us: %invite%",
+	}
+	result := action.Validate(context.Background(), mock.JonSnow)
+	Expect(result.Ok).IsTrue()
+
+	userImport := &entity.UserImport{ID: 1, Status: enum.UserImportRunning, Total: len(action.Rows)}
+	err := mock.NewWorker().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithBaseURL("http://domain.com").
+		Execute(tasks.ImportUsers(userImport, action))
+
+	Expect(err).IsNil()
+	Expect(userImport.Status).Equals(enum.UserImportCompleted)
+	Expect(userImport.Processed).Equals(3)
+	Expect(userImport.Created).Equals(1)
+	Expect(userImport.Updated).Equals(1)
+	Expect(userImport.Failed).Equals(1)
+	Expect(userImport.Errors).HasLen(1)
+	Expect(userImport.Errors[0].Row).Equals(4)
+	Expect(progress).Equals([]enum.UserImportStatus{enum.UserImportCompleted})
+
+	Expect(registered).HasLen(1)
+	Expect(registered[0].Name).Equals("Jon Snow")
+	Expect(registered[0].Role).Equals(enum.RoleCollaborator)
+	Expect(organizations[registered[0].ID]).Equals(nightsWatch.ID)
+
+	Expect(changeRole.UserID).Equals(mock.AryaStark.ID)
+	Expect(changeRole.Role).Equals(enum.RoleCollaborator)
+	Expect(registerProvider.UserID).Equals(mock.AryaStark.ID)
+	Expect(registerProvider.ProviderUID).Equals("arya")
+	Expect(saveOrganization.Name).Equals("Faceless Men")
+	Expect(organizations[mock.AryaStark.ID]).Equals(2)
+
+	Expect(emailmock.MessageHistory).HasLen(1)
+	Expect(emailmock.MessageHistory[0].TemplateName).Equals("invite_email")
+	Expect(emailmock.MessageHistory[0].To).HasLen(1)
+	Expect(emailmock.MessageHistory[0].To[0].Address).Equals("jon.snow@got.com")
+}
+
+func TestImportUsers_KeepsRoleOfAdministrators(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	daenerys := &entity.User{ID: 10, Name: "Daenerys Targaryen", Email: "daenerys@got.com", Tenant: mock.DemoTenant, Role: enum.RoleAdministrator, Status: enum.UserActive}
+	bus.AddHandler(func(ctx context.Context, q *query.GetAllOrganizations) error {
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		q.Result = daenerys
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.SaveUserImportProgress) error {
+		return nil
+	})
+
+	action := &actions.ImportUsers{
+		CSV: `name,email,role
+Daenerys Targaryen,daenerys@got.com,visitor
+`,
+	}
+	result := action.Validate(context.Background(), mock.JonSnow)
+	Expect(result.Ok).IsTrue()
+
+	userImport := &entity.UserImport{ID: 1, Status: enum.UserImportRunning, Total: len(action.Rows)}
+	err := mock.NewWorker().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(tasks.ImportUsers(userImport, action))
+
+	Expect(err).IsNil()
+	Expect(userImport.Status).Equals(enum.UserImportCompleted)
+	Expect(userImport.Updated).Equals(1)
+	Expect(userImport.Errors).HasLen(1)
+	Expect(userImport.Errors[0].Row).Equals(2)
+	Expect(daenerys.Role).Equals(enum.RoleAdministrator)
+}
+
+func TestImportUsers_DoesNotReportRolledBackRows_WhenItFails(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetAllOrganizations) error {
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		return app.ErrNotFound
+	})
+
+	registered := 0
+	bus.AddHandler(func(ctx context.Context, c *cmd.RegisterUser) error {
+		registered++
+		if registered == 2 {
+			return errors.New("connection lost")
+		}
+		c.User.ID = 100 + registered
+		return nil
+	})
+
+	var saved entity.UserImport
+	bus.AddHandler(func(ctx context.Context, c *cmd.SaveUserImportProgress) error {
+		saved = *c.Import
+		return nil
+	})
+
+	action := &actions.ImportUsers{
+		CSV: `name,email
+Jon Snow,jon.snow@got.com
+Arya Stark,arya.stark@got.com
+`,
+	}
+	result := action.Validate(context.Background(), mock.JonSnow)
+	Expect(result.Ok).IsTrue()
+
+	userImport := &entity.UserImport{ID: 1, Status: enum.UserImportRunning, Total: len(action.Rows)}
+	err := mock.NewWorker().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(tasks.ImportUsers(userImport, action))
+
+	Expect(err).IsNotNil()
+	Expect(saved.Status).Equals(enum.UserImportFailed)
+	Expect(saved.Created).Equals(0)
+	Expect(saved.Updated).Equals(0)
+}
@@ -34,9 +34,11 @@ func SendInvites(subject, message string, invitations []*actions.UserInvitation)
 			to[i] = dto.NewRecipient("", invite.Email, dto.Props{
 				"message": markdown.Full(toMessage),
 			})
+			// invited users have no locale yet, so they get the one of the tenant rather than the one of who invited them
+			to[i].Locale = c.Tenant().Locale
 		}
 
-		bus.Publish(c, &cmd.SendMail{
+		err := bus.Dispatch(c, &cmd.SendMail{
 			From: dto.Recipient{
 				Name: c.User().Name,
 			},
@@ -47,6 +49,9 @@ func SendInvites(subject, message string, invitations []*actions.UserInvitation)
 				"logo":    web.LogoURL(c),
 			},
 		})
+		if err != nil {
+			return c.Failure(err)
+		}
 
 		return nil
 	})
@@ -69,3 +69,31 @@ func TestSendInvites(t *testing.T) {
 	Expect(savedKeys[1].Key).Equals("5678")
 	Expect(savedKeys[1].Request.GetEmail()).Equals("user2@domain.com")
 }
+
+func TestSendInvites_UsesTenantLocale(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.SaveVerificationKey) error {
+		return nil
+	})
+
+	tenant := *mock.DemoTenant
+	tenant.Locale = "pt-BR"
+	admin := *mock.JonSnow
+	admin.Locale = "en"
+
+	task := tasks.SendInvites("My Subject", "Click here: %invite%", []*actions.UserInvitation{
+		{Email: "user1@domain.com", VerificationKey: "1234"},
+	})
+
+	err := mock.NewWorker().
+		OnTenant(&tenant).
+		AsUser(&admin).
+		WithBaseURL("http://domain.com").
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(emailmock.MessageHistory).HasLen(1)
+	Expect(emailmock.MessageHistory[0].To[0].Locale).Equals("pt-BR")
+}
@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/getfider/fider/app/models/cmd"
@@ -15,7 +16,7 @@ import (
 	"github.com/getfider/fider/app/pkg/worker"
 )
 
-//NotifyAboutNewComment sends a notification (web and email) to subscribers
+//NotifyAboutNewComment sends a notification (web, push and email) to subscribers
 func NotifyAboutNewComment(post *entity.Post, comment string) worker.Task {
 	return describe("Notify about new comment", func(c *worker.Context) error {
 		// Web notification
@@ -41,40 +42,51 @@ func NotifyAboutNewComment(post *entity.Post, comment string) worker.Task {
 			}
 		}
 
+		// Push notification
+		pushTitle := func(ctx context.Context) string {
+			return i18n.T(ctx, "push.new_comment", i18n.Params{"userName": author.Name})
+		}
+		if err := sendPushNotifications(c, post, eventSubscribers(post, enum.NotificationEventNewComment), pushTitle, link); err != nil {
+			return c.Failure(err)
+		}
+
 		// Email notification
 		users, err = getActiveSubscribers(c, post, enum.NotificationChannelEmail, enum.NotificationEventNewComment)
 		if err != nil {
 			return c.Failure(err)
 		}
 
-		to := make([]dto.Recipient, 0)
-		for _, user := range users {
-			if user.ID != author.ID {
-				to = append(to, dto.NewRecipient(user.Name, user.Email, dto.Props{}))
-			}
-		}
-
 		tenant := c.Tenant()
 		baseURL, logoURL := web.BaseURL(c), web.LogoURL(c)
 
+		to, err := emailRecipients(c, users, post, enum.NotificationEventNewComment, comment, func(ctx context.Context) (string, dto.Props) {
+			return i18n.T(ctx, "email.digest.new_comment", i18n.Params{"userName": author.Name}), dto.Props{
+				"view":   linkWithText(i18n.T(ctx, "email.subscription.view"), baseURL, "/posts/%d/%s", post.Number, post.Slug),
+				"change": linkWithText(i18n.T(ctx, "email.subscription.change"), baseURL, "/settings"),
+			}
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		mailProps := dto.Props{
-			"title":       post.Title,
-			"siteName":    tenant.Name,
-			"userName":    author.Name,
-			"content":     markdown.Full(comment),
-			"postLink":    linkWithText(fmt.Sprintf("#%d", post.Number), baseURL, "/posts/%d/%s", post.Number, post.Slug),
-			"view":        linkWithText(i18n.T(c, "email.subscription.view"), baseURL, "/posts/%d/%s", post.Number, post.Slug),
-			"unsubscribe": linkWithText(i18n.T(c, "email.subscription.unsubscribe"), baseURL, "/posts/%d/%s", post.Number, post.Slug),
-			"change":      linkWithText(i18n.T(c, "email.subscription.change"), baseURL, "/settings"),
-			"logo":        logoURL,
+			"title":    post.Title,
+			"siteName": tenant.Name,
+			"userName": author.Name,
+			"content":  markdown.Full(comment),
+			"postLink": linkWithText(fmt.Sprintf("#%d", post.Number), baseURL, "/posts/%d/%s", post.Number, post.Slug),
+			"logo":     logoURL,
 		}
 
-		bus.Publish(c, &cmd.SendMail{
+		err = bus.Dispatch(c, &cmd.SendMail{
 			From:         dto.Recipient{Name: author.Name},
 			To:           to,
 			TemplateName: "new_comment",
 			Props:        mailProps,
 		})
+		if err != nil {
+			return c.Failure(err)
+		}
 
 		webhookProps := webhook.Props{"comment": comment}
 		webhookProps.SetPost(post, "post", baseURL, true, true)
@@ -2,7 +2,9 @@ package tasks_test
 
 import (
 	"context"
+	"fmt"
 	"html/template"
+	"strings"
 	"testing"
 
 	"github.com/getfider/fider/app/pkg/webhook"
@@ -15,6 +17,9 @@ import (
 	"github.com/getfider/fider/app/models/dto"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/inbound"
+	"github.com/getfider/fider/app/pkg/jwt"
 	"github.com/getfider/fider/app/pkg/mock"
 	"github.com/getfider/fider/app/services/email/emailmock"
 	"github.com/getfider/fider/app/tasks"
@@ -65,25 +70,22 @@ func TestNotifyAboutNewCommentTask(t *testing.T) {
 	Expect(emailmock.MessageHistory[0].TemplateName).Equals("new_comment")
 	Expect(emailmock.MessageHistory[0].Tenant).Equals(mock.DemoTenant)
 	Expect(emailmock.MessageHistory[0].Props).Equals(dto.Props{
-		"title":       "Add support for TypeScript",
-		"postLink":    "<a href='http://domain.com/posts/1/add-support-for-typescript'>#1</a>",
-		"siteName":    "Demonstration",
-		"userName":    "Arya Stark",
-		"content":     template.HTML("<p>I agree</p>"),
-		"view":        "<a href='http://domain.com/posts/1/add-support-for-typescript'>view it on your browser</a>",
-		"change":      "<a href='http://domain.com/settings'>change your notification preferences</a>",
-		"unsubscribe": "<a href='http://domain.com/posts/1/add-support-for-typescript'>unsubscribe from it</a>",
-		"logo":        "https://fider.io/images/logo-100x100.png",
+		"title":    "Add support for TypeScript",
+		"postLink": "<a href='http://domain.com/posts/1/add-support-for-typescript'>#1</a>",
+		"siteName": "Demonstration",
+		"userName": "Arya Stark",
+		"content":  template.HTML("<p>I agree</p>"),
+		"logo":     "https://fider.io/images/logo-100x100.png",
 	})
 	Expect(emailmock.MessageHistory[0].From).Equals(dto.Recipient{
 		Name: "Arya Stark",
 	})
 	Expect(emailmock.MessageHistory[0].To).HasLen(1)
-	Expect(emailmock.MessageHistory[0].To[0]).Equals(dto.Recipient{
-		Name:    "Jon Snow",
-		Address: "jon.snow@got.com",
-		Props:   dto.Props{},
-	})
+	Expect(emailmock.MessageHistory[0].To[0].Name).Equals("Jon Snow")
+	Expect(emailmock.MessageHistory[0].To[0].Address).Equals("jon.snow@got.com")
+	Expect(emailmock.MessageHistory[0].To[0].Props["view"]).Equals("<a href='http://domain.com/posts/1/add-support-for-typescript'>view it on your browser</a>")
+	Expect(emailmock.MessageHistory[0].To[0].Props["change"]).Equals("<a href='http://domain.com/settings'>change your notification preferences</a>")
+	expectUnsubscribeLink(emailmock.MessageHistory[0].To[0], "unsubscribe from it", jwt.UnsubscribeClaims{TenantID: mock.DemoTenant.ID, UserID: mock.JonSnow.ID, PostNumber: 1})
 
 	Expect(addNewNotification).IsNotNil()
 	Expect(addNewNotification.PostID).Equals(post.ID)
@@ -116,3 +118,181 @@ func TestNotifyAboutNewCommentTask(t *testing.T) {
 		"tenant_url":        "http://domain.com",
 	})
 }
+
+func TestNotifyAboutNewCommentTask_QueuesEmailDigest(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewNotification) error {
+		return nil
+	})
+
+	sansaStark := &entity.User{ID: 3, Name: "Sansa Stark", Email: "sansa@got.com", Role: enum.RoleVisitor}
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveSubscribers) error {
+		q.Result = []*entity.User{
+			mock.JonSnow,
+			sansaStark,
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.TriggerWebhooks) error {
+		return nil
+	})
+
+	worker := mock.NewWorker()
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUsersEmailDelivery) error {
+		q.Result = map[int]enum.EmailDelivery{
+			mock.JonSnow.ID: enum.EmailDeliveryDaily,
+		}
+		return nil
+	})
+
+	var addDigestItem *cmd.AddEmailDigestItem
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddEmailDigestItem) error {
+		addDigestItem = c
+		return nil
+	})
+
+	post := &entity.Post{
+		ID:     1,
+		Number: 1,
+		Title:  "Add support for TypeScript",
+		Slug:   "add-support-for-typescript",
+		User:   mock.JonSnow,
+	}
+	task := tasks.NotifyAboutNewComment(post, "I agree")
+
+	err := worker.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		WithBaseURL("http://domain.com").
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(emailmock.MessageHistory).HasLen(1)
+	Expect(emailmock.MessageHistory[0].To).HasLen(1)
+	Expect(emailmock.MessageHistory[0].To[0].Address).Equals(sansaStark.Email)
+
+	ExpectHandler(&cmd.AddEmailDigestItem{}).CalledOnce()
+	Expect(addDigestItem.User).Equals(mock.JonSnow)
+	Expect(addDigestItem.Delivery).Equals(enum.EmailDeliveryDaily)
+	Expect(addDigestItem.PostID).Equals(post.ID)
+	Expect(addDigestItem.Title).Equals("**Arya Stark** left a comment.")
+	Expect(addDigestItem.Content).Equals("I agree")
+}
+
+func TestNotifyAboutNewCommentTask_RecipientLocale(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewNotification) error {
+		return nil
+	})
+
+	sansaStark := &entity.User{ID: 3, Name: "Sansa Stark", Email: "sansa@got.com", Role: enum.RoleVisitor, Locale: "fr"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveSubscribers) error {
+		q.Result = []*entity.User{
+			mock.JonSnow,
+			sansaStark,
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.TriggerWebhooks) error {
+		return nil
+	})
+
+	worker := mock.NewWorker()
+	post := &entity.Post{
+		ID:     1,
+		Number: 1,
+		Title:  "Add support for TypeScript",
+		Slug:   "add-support-for-typescript",
+		User:   mock.JonSnow,
+	}
+	task := tasks.NotifyAboutNewComment(post, "I agree")
+
+	err := worker.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		WithBaseURL("http://domain.com").
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(emailmock.MessageHistory).HasLen(1)
+	Expect(emailmock.MessageHistory[0].To).HasLen(2)
+
+	jon := emailmock.MessageHistory[0].To[0]
+	Expect(jon.Address).Equals(mock.JonSnow.Email)
+	Expect(jon.Locale).Equals(mock.DemoTenant.Locale)
+	Expect(jon.Props["view"]).Equals("<a href='http://domain.com/posts/1/add-support-for-typescript'>view it on your browser</a>")
+	expectUnsubscribeLink(jon, "unsubscribe from it", jwt.UnsubscribeClaims{TenantID: mock.DemoTenant.ID, UserID: mock.JonSnow.ID, PostNumber: 1})
+
+	sansa := emailmock.MessageHistory[0].To[1]
+	Expect(sansa.Address).Equals(sansaStark.Email)
+	Expect(sansa.Locale).Equals("fr")
+	Expect(sansa.Props["view"]).Equals("<a href='http://domain.com/posts/1/add-support-for-typescript'>visualiser-le dans votre navigateur</a>")
+	Expect(sansa.Props["change"]).Equals("<a href='http://domain.com/settings'>changez vos préférences de notification</a>")
+	expectUnsubscribeLink(sansa, "me désabonner", jwt.UnsubscribeClaims{TenantID: mock.DemoTenant.ID, UserID: sansaStark.ID, PostNumber: 1})
+}
+
+func TestNotifyAboutNewCommentTask_ReplyByEmail(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+	env.Config.Email.Inbound.Address = "reply@inbound.got.com"
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewNotification) error {
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveSubscribers) error {
+		q.Result = []*entity.User{
+			mock.JonSnow,
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.TriggerWebhooks) error {
+		return nil
+	})
+
+	worker := mock.NewWorker()
+	post := &entity.Post{
+		ID:     1,
+		Number: 1,
+		Title:  "Add support for TypeScript",
+		Slug:   "add-support-for-typescript",
+		User:   mock.JonSnow,
+	}
+	task := tasks.NotifyAboutNewComment(post, "I agree")
+
+	err := worker.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		WithBaseURL("http://domain.com").
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(emailmock.MessageHistory).HasLen(1)
+	Expect(emailmock.MessageHistory[0].To).HasLen(1)
+	Expect(emailmock.MessageHistory[0].To[0].Props["replyByEmail"]).Equals(true)
+
+	token, err := inbound.ParseReplyAddress(emailmock.MessageHistory[0].To[0].ReplyTo)
+	Expect(err).IsNil()
+	Expect(token).Equals(&inbound.ReplyToken{TenantID: mock.DemoTenant.ID, UserID: mock.JonSnow.ID, PostNumber: post.Number})
+}
+
+func expectUnsubscribeLink(recipient dto.Recipient, text string, expected jwt.UnsubscribeClaims) {
+	token, found := strings.CutPrefix(recipient.Unsubscribe, "http://domain.com/unsubscribe/")
+	Expect(found).IsTrue()
+	Expect(recipient.Props["unsubscribe"]).Equals(fmt.Sprintf("<a href='%s'>%s</a>", recipient.Unsubscribe, text))
+
+	claims, err := jwt.DecodeUnsubscribeClaims(token)
+	Expect(err).IsNil()
+	Expect(claims.TenantID).Equals(expected.TenantID)
+	Expect(claims.UserID).Equals(expected.UserID)
+	Expect(claims.PostNumber).Equals(expected.PostNumber)
+	Expect(claims.Event).Equals(expected.Event)
+}
@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/getfider/fider/app/models/cmd"
@@ -15,79 +16,107 @@ import (
 	"github.com/getfider/fider/app/pkg/worker"
 )
 
-//NotifyAboutNewPost sends a notification (web and email) to subscribers
+//NotifyAboutNewPost sends a notification (web, push and email) to subscribers
 func NotifyAboutNewPost(post *entity.Post) worker.Task {
 	return describe("Notify about new post", func(c *worker.Context) error {
-		// Web notification
-		users, err := getActiveSubscribers(c, post, enum.NotificationChannelWeb, enum.NotificationEventNewPost)
-		if err != nil {
+		if err := notifyAboutPost(c, post, eventSubscribers(post, enum.NotificationEventNewPost)); err != nil {
 			return c.Failure(err)
 		}
 
-		author := c.User()
-		title := fmt.Sprintf("New post: **%s**", post.Title)
-		link := fmt.Sprintf("/posts/%d/%s", post.Number, post.Slug)
-		for _, user := range users {
-			if user.ID != author.ID {
-				err = bus.Dispatch(c, &cmd.AddNewNotification{
-					User:   user,
-					Title:  title,
-					Link:   link,
-					PostID: post.ID,
-				})
-				if err != nil {
-					return c.Failure(err)
-				}
-			}
-		}
+		baseURL, logoURL := web.BaseURL(c), web.LogoURL(c)
+		webhookProps := webhook.Props{}
+		webhookProps.SetPost(post, "post", baseURL, false, false)
+		webhookProps.SetUser(c.User(), "author")
+		webhookProps.SetTenant(c.Tenant(), "tenant", baseURL, logoURL)
 
-		// Email notification
-		users, err = getActiveSubscribers(c, post, enum.NotificationChannelEmail, enum.NotificationEventNewPost)
+		err := bus.Dispatch(c, &cmd.TriggerWebhooks{
+			Type:  enum.WebhookNewPost,
+			Props: webhookProps,
+		})
 		if err != nil {
 			return c.Failure(err)
 		}
 
-		to := make([]dto.Recipient, 0)
-		for _, user := range users {
-			if user.ID != author.ID {
-				to = append(to, dto.NewRecipient(user.Name, user.Email, dto.Props{}))
-			}
+		return nil
+	})
+}
+
+// NotifyAboutTaggedPost sends a notification (web, push and email) to users following a saved search that includes given tag
+// Posts are usually tagged after they are created, which is when they start matching these searches
+func NotifyAboutTaggedPost(post *entity.Post, tag *entity.Tag) worker.Task {
+	return describe("Notify about tagged post", func(c *worker.Context) error {
+		if err := notifyAboutPost(c, post, tagFollowers(post, tag)); err != nil {
+			return c.Failure(err)
 		}
+		return nil
+	})
+}
 
-		tenant := c.Tenant()
-		baseURL, logoURL := web.BaseURL(c), web.LogoURL(c)
+// notifyAboutPost tells given subscribers that a new post was published, on every channel they chose
+func notifyAboutPost(c *worker.Context, post *entity.Post, subscribers subscribersOf) error {
+	// Web notification
+	users, err := subscribers(c, enum.NotificationChannelWeb)
+	if err != nil {
+		return err
+	}
 
-		mailProps := dto.Props{
-			"title":    post.Title,
-			"siteName": tenant.Name,
-			"userName": author.Name,
-			"content":  markdown.Full(post.Description),
-			"postLink": linkWithText(fmt.Sprintf("#%d", post.Number), baseURL, "/posts/%d/%s", post.Number, post.Slug),
-			"view":     linkWithText(i18n.T(c, "email.subscription.view"), baseURL, "/posts/%d/%s", post.Number, post.Slug),
-			"change":   linkWithText(i18n.T(c, "email.subscription.change"), baseURL, "/settings"),
-			"logo":     logoURL,
+	author := c.User()
+	title := fmt.Sprintf("New post: **%s**", post.Title)
+	link := fmt.Sprintf("/posts/%d/%s", post.Number, post.Slug)
+	for _, user := range users {
+		if user.ID != author.ID {
+			err = bus.Dispatch(c, &cmd.AddNewNotification{
+				User:   user,
+				Title:  title,
+				Link:   link,
+				PostID: post.ID,
+			})
+			if err != nil {
+				return err
+			}
 		}
+	}
 
-		bus.Publish(c, &cmd.SendMail{
-			From:         dto.Recipient{Name: author.Name},
-			To:           to,
-			TemplateName: "new_post",
-			Props:        mailProps,
-		})
+	// Push notification
+	pushTitle := func(ctx context.Context) string {
+		return i18n.T(ctx, "push.new_post", i18n.Params{"userName": author.Name})
+	}
+	if err := sendPushNotifications(c, post, subscribers, pushTitle, link); err != nil {
+		return err
+	}
 
-		webhookProps := webhook.Props{}
-		webhookProps.SetPost(post, "post", baseURL, false, false)
-		webhookProps.SetUser(author, "author")
-		webhookProps.SetTenant(tenant, "tenant", baseURL, logoURL)
+	// Email notification
+	users, err = subscribers(c, enum.NotificationChannelEmail)
+	if err != nil {
+		return err
+	}
 
-		err = bus.Dispatch(c, &cmd.TriggerWebhooks{
-			Type:  enum.WebhookNewPost,
-			Props: webhookProps,
-		})
-		if err != nil {
-			return c.Failure(err)
+	tenant := c.Tenant()
+	baseURL, logoURL := web.BaseURL(c), web.LogoURL(c)
+
+	to, err := emailRecipients(c, users, post, enum.NotificationEventNewPost, post.Description, func(ctx context.Context) (string, dto.Props) {
+		return i18n.T(ctx, "email.digest.new_post", i18n.Params{"userName": author.Name}), dto.Props{
+			"view":   linkWithText(i18n.T(ctx, "email.subscription.view"), baseURL, "/posts/%d/%s", post.Number, post.Slug),
+			"change": linkWithText(i18n.T(ctx, "email.subscription.change"), baseURL, "/settings"),
 		}
+	})
+	if err != nil {
+		return err
+	}
 
-		return nil
+	mailProps := dto.Props{
+		"title":    post.Title,
+		"siteName": tenant.Name,
+		"userName": author.Name,
+		"content":  markdown.Full(post.Description),
+		"postLink": linkWithText(fmt.Sprintf("#%d", post.Number), baseURL, "/posts/%d/%s", post.Number, post.Slug),
+		"logo":     logoURL,
+	}
+
+	return bus.Dispatch(c, &cmd.SendMail{
+		From:         dto.Recipient{Name: author.Name},
+		To:           to,
+		TemplateName: "new_post",
+		Props:        mailProps,
 	})
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/getfider/fider/app/models/dto"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/jwt"
 	"github.com/getfider/fider/app/pkg/mock"
 	"github.com/getfider/fider/app/services/email/emailmock"
 	"github.com/getfider/fider/app/tasks"
@@ -37,6 +38,10 @@ func TestNotifyAboutNewPostTask(t *testing.T) {
 		return nil
 	})
 
+	bus.AddHandler(func(ctx context.Context, q *query.GetSavedSearchFollowers) error {
+		return nil
+	})
+
 	var triggerWebhooks *cmd.TriggerWebhooks
 	bus.AddHandler(func(ctx context.Context, c *cmd.TriggerWebhooks) error {
 		triggerWebhooks = c
@@ -69,19 +74,17 @@ func TestNotifyAboutNewPostTask(t *testing.T) {
 		"siteName": "Demonstration",
 		"userName": "Jon Snow",
 		"content":  template.HTML("<p>TypeScript is great, please add support for it</p>"),
-		"view":     "<a href='http://domain.com/posts/1/add-support-for-typescript'>view it on your browser</a>",
-		"change":   "<a href='http://domain.com/settings'>change your notification preferences</a>",
 		"logo":     "https://fider.io/images/logo-100x100.png",
 	})
 	Expect(emailmock.MessageHistory[0].From).Equals(dto.Recipient{
 		Name: "Jon Snow",
 	})
 	Expect(emailmock.MessageHistory[0].To).HasLen(1)
-	Expect(emailmock.MessageHistory[0].To[0]).Equals(dto.Recipient{
-		Name:    "Arya Stark",
-		Address: "arya.stark@got.com",
-		Props:   dto.Props{},
-	})
+	Expect(emailmock.MessageHistory[0].To[0].Name).Equals("Arya Stark")
+	Expect(emailmock.MessageHistory[0].To[0].Address).Equals("arya.stark@got.com")
+	Expect(emailmock.MessageHistory[0].To[0].Props["view"]).Equals("<a href='http://domain.com/posts/1/add-support-for-typescript'>view it on your browser</a>")
+	Expect(emailmock.MessageHistory[0].To[0].Props["change"]).Equals("<a href='http://domain.com/settings'>change your notification preferences</a>")
+	expectUnsubscribeLink(emailmock.MessageHistory[0].To[0], "stop receiving these emails", jwt.UnsubscribeClaims{TenantID: mock.DemoTenant.ID, UserID: mock.AryaStark.ID, Event: "event_notification_new_post"})
 
 	Expect(addNewNotification).IsNotNil()
 	Expect(addNewNotification.PostID).Equals(post.ID)
@@ -109,3 +112,166 @@ func TestNotifyAboutNewPostTask(t *testing.T) {
 		"tenant_url":       "http://domain.com",
 	})
 }
+
+func TestNotifyAboutNewPostTask_OnBehalfOfAuthor(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	var addNewNotification *cmd.AddNewNotification
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewNotification) error {
+		addNewNotification = c
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveSubscribers) error {
+		q.Result = []*entity.User{
+			mock.JonSnow,
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetSavedSearchFollowers) error {
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.TriggerWebhooks) error {
+		return nil
+	})
+
+	worker := mock.NewWorker()
+	post := &entity.Post{
+		ID:     1,
+		Number: 1,
+		Title:  "Add support for TypeScript",
+		Slug:   "add-support-for-typescript",
+		User:   mock.AryaStark,
+	}
+	task := tasks.OnBehalfOf(post.User, tasks.NotifyAboutNewPost(post))
+
+	err := worker.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithBaseURL("http://domain.com").
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(emailmock.MessageHistory).HasLen(1)
+	Expect(emailmock.MessageHistory[0].From).Equals(dto.Recipient{
+		Name: "Arya Stark",
+	})
+	Expect(emailmock.MessageHistory[0].To).HasLen(1)
+	Expect(emailmock.MessageHistory[0].To[0].Name).Equals("Jon Snow")
+
+	Expect(addNewNotification).IsNotNil()
+	Expect(addNewNotification.User).Equals(mock.JonSnow)
+	Expect(addNewNotification.Title).Equals("New post: **Add support for TypeScript**")
+}
+
+func TestNotifyAboutNewPostTask_SavedSearchFollowers(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	notified := make([]*entity.User, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewNotification) error {
+		notified = append(notified, c.User)
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveSubscribers) error {
+		q.Result = []*entity.User{}
+		if q.Channel == enum.NotificationChannelWeb {
+			q.Result = []*entity.User{mock.JonSnow}
+		}
+		return nil
+	})
+
+	sansa := &entity.User{ID: 3, Name: "Sansa Stark", Email: "sansa.stark@got.com", Tenant: mock.DemoTenant, Role: enum.RoleVisitor, Status: enum.UserActive}
+	var getFollowers []*query.GetSavedSearchFollowers
+	bus.AddHandler(func(ctx context.Context, q *query.GetSavedSearchFollowers) error {
+		getFollowers = append(getFollowers, q)
+		q.Result = []*entity.User{}
+		if q.Channel == enum.NotificationChannelWeb {
+			q.Result = []*entity.User{mock.JonSnow, sansa}
+		}
+		if q.Channel == enum.NotificationChannelEmail {
+			q.Result = []*entity.User{sansa}
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.TriggerWebhooks) error {
+		return nil
+	})
+
+	worker := mock.NewWorker()
+	post := &entity.Post{
+		ID:     1,
+		Number: 1,
+		Title:  "Add support for TypeScript",
+		Slug:   "add-support-for-typescript",
+		Status: enum.PostOpen,
+		Tags:   []string{"feature-request"},
+	}
+	task := tasks.NotifyAboutNewPost(post)
+
+	err := worker.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		WithBaseURL("http://domain.com").
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(notified).Equals([]*entity.User{mock.JonSnow, sansa})
+	Expect(getFollowers[0].Post).Equals(post)
+
+	Expect(emailmock.MessageHistory).HasLen(1)
+	Expect(emailmock.MessageHistory[0].To).HasLen(1)
+	Expect(emailmock.MessageHistory[0].To[0].Name).Equals("Sansa Stark")
+}
+
+func TestNotifyAboutTaggedPostTask(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	notified := make([]*entity.User, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewNotification) error {
+		notified = append(notified, c.User)
+		return nil
+	})
+
+	var getFollowers []*query.GetSavedSearchFollowers
+	bus.AddHandler(func(ctx context.Context, q *query.GetSavedSearchFollowers) error {
+		getFollowers = append(getFollowers, q)
+		q.Result = []*entity.User{mock.JonSnow, mock.AryaStark}
+		return nil
+	})
+
+	worker := mock.NewWorker()
+	tag := &entity.Tag{ID: 5, Slug: "bug"}
+	post := &entity.Post{
+		ID:     1,
+		Number: 1,
+		Title:  "Add support for TypeScript",
+		Slug:   "add-support-for-typescript",
+		Status: enum.PostOpen,
+		Tags:   []string{"bug"},
+		User:   mock.AryaStark,
+	}
+	task := tasks.OnBehalfOf(post.User, tasks.NotifyAboutTaggedPost(post, tag))
+
+	err := worker.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithBaseURL("http://domain.com").
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(notified).Equals([]*entity.User{mock.JonSnow})
+	Expect(getFollowers[0].Post).Equals(post)
+	Expect(getFollowers[0].Tag).Equals(tag)
+
+	Expect(emailmock.MessageHistory).HasLen(1)
+	Expect(emailmock.MessageHistory[0].TemplateName).Equals("new_post")
+	Expect(emailmock.MessageHistory[0].To).HasLen(1)
+	Expect(emailmock.MessageHistory[0].To[0].Name).Equals("Jon Snow")
+}
@@ -0,0 +1,131 @@
+package tasks
+
+import (
+	"strings"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/inbound"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/pkg/worker"
+)
+
+// ReplyByEmail turns a reply to a notification email into a comment on its post
+// Emails that can't be verified as coming from the notified user are discarded
+func ReplyByEmail(email *dto.InboundEmail) worker.Task {
+	return describe("Reply by email", func(c *worker.Context) error {
+		token := inbound.FindReplyToken(email.Recipients)
+		if token == nil {
+			return discardReply(c, email, "it was not sent to a reply address")
+		}
+
+		getTenant := &query.GetTenantByID{TenantID: token.TenantID}
+		if err := bus.Dispatch(c, getTenant); err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return discardReply(c, email, "tenant was not found")
+			}
+			return c.Failure(err)
+		}
+		if getTenant.Result.Status != enum.TenantActive {
+			return discardReply(c, email, "tenant is not active")
+		}
+		c.Context = web.WithTenant(c.Context, getTenant.Result)
+
+		getUser := &query.GetUserByID{UserID: token.UserID}
+		if err := bus.Dispatch(c, getUser); err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return discardReply(c, email, "user was not found")
+			}
+			return c.Failure(err)
+		}
+		user := getUser.Result
+		if user.Status != enum.UserActive || !strings.EqualFold(user.Email, email.From) {
+			return discardReply(c, email, "sender is not the notified user")
+		}
+		if !email.Authenticated {
+			return discardReply(c, email, "sender could not be authenticated")
+		}
+		c.Set(app.UserCtxKey, user)
+
+		getSuspension := &query.GetActiveUserSuspension{UserID: user.ID}
+		if err := bus.Dispatch(c, getSuspension); err != nil {
+			return c.Failure(err)
+		}
+		if getSuspension.Result != nil {
+			return discardReply(c, email, "user is suspended")
+		}
+
+		getPost := &query.GetPostByNumber{Number: token.PostNumber}
+		if err := bus.Dispatch(c, getPost); err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return discardReply(c, email, "post was not found")
+			}
+			return c.Failure(err)
+		}
+		post := getPost.Result
+		if post.Status == enum.PostDeleted {
+			return discardReply(c, email, "post has been deleted")
+		}
+
+		content := inbound.StripReply(email.Text)
+		if content == "" {
+			return discardReply(c, email, "it has no content")
+		}
+
+		pendingReview := false
+		if env.Config.SpamFilter.Enabled {
+			checkSpam := &query.CheckSpam{Kind: "comment", Content: content}
+			if err := bus.Dispatch(c, checkSpam); err != nil {
+				return c.Failure(err)
+			}
+			pendingReview = checkSpam.Result.IsSpam
+		}
+
+		addNewComment := &cmd.AddNewComment{
+			Post:          post,
+			Content:       content,
+			PendingReview: pendingReview,
+		}
+		if err := bus.Dispatch(c, addNewComment); err != nil {
+			return c.Failure(err)
+		}
+
+		log.Infof(c, "Reply of user @{UserID} on post @{PostNumber} was added as comment @{CommentID}", dto.Props{
+			"UserID":     user.ID,
+			"PostNumber": post.Number,
+			"CommentID":  addNewComment.Result.ID,
+		})
+
+		if pendingReview {
+			return nil
+		}
+
+		err := bus.Dispatch(c, &cmd.PublishRealtimeEvent{
+			Type: enum.RealtimeComment,
+			Data: dto.Props{
+				"postNumber": post.Number,
+				"commentId":  addNewComment.Result.ID,
+			},
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return NotifyAboutNewComment(post, content).Job(c)
+	})
+}
+
+func discardReply(c *worker.Context, email *dto.InboundEmail, reason string) error {
+	log.Warnf(c, "Email from @{From} was discarded because @{Reason}", dto.Props{
+		"From":   email.From,
+		"Reason": reason,
+	})
+	return nil
+}
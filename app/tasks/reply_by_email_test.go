@@ -0,0 +1,201 @@
+package tasks_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/inbound"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/services/email/emailmock"
+	"github.com/getfider/fider/app/tasks"
+)
+
+var replyPost = &entity.Post{
+	ID:     1,
+	Number: 1,
+	Title:  "Add support for TypeScript",
+	Slug:   "add-support-for-typescript",
+	Status: enum.PostOpen,
+	User:   mock.JonSnow,
+}
+
+func setupReplyByEmail() {
+	bus.Init(emailmock.Service{})
+	env.Config.Email.Inbound.Address = "reply@inbound.got.com"
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetTenantByID) error {
+		if q.TenantID == mock.DemoTenant.ID {
+			q.Result = mock.DemoTenant
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		if q.UserID == mock.AryaStark.ID {
+			q.Result = mock.AryaStark
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveUserSuspension) error {
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.CheckSpam) error {
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = replyPost
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveSubscribers) error {
+		q.Result = []*entity.User{}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.PublishRealtimeEvent) error {
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.TriggerWebhooks) error {
+		return nil
+	})
+}
+
+func TestReplyByEmailTask(t *testing.T) {
+	RegisterT(t)
+	setupReplyByEmail()
+
+	var addNewComment *cmd.AddNewComment
+	var user *entity.User
+	var tenant *entity.Tenant
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewComment) error {
+		addNewComment = c
+		user = ctx.Value(app.UserCtxKey).(*entity.User)
+		tenant = ctx.Value(app.TenantCtxKey).(*entity.Tenant)
+		c.Result = &entity.Comment{ID: 10, Content: c.Content}
+		return nil
+	})
+
+	task := tasks.ReplyByEmail(&dto.InboundEmail{
+		From:          "Arya.Stark@got.com",
+		Authenticated: true,
+		Recipients: []string{
+			"someone@got.com",
+			inbound.ReplyAddress(inbound.ReplyToken{TenantID: mock.DemoTenant.ID, UserID: mock.AryaStark.ID, PostNumber: replyPost.Number}),
+		},
+		Text: "I agree!\n\nOn Mon, Jan 1, 2024 at 10:00 AM Jon Snow <jon.snow@got.com> wrote:\n> Add support for TypeScript",
+	})
+
+	err := mock.NewWorker().Execute(task)
+	Expect(err).IsNil()
+	Expect(addNewComment).IsNotNil()
+	Expect(addNewComment.Post).Equals(replyPost)
+	Expect(addNewComment.Content).Equals("I agree!")
+	Expect(addNewComment.PendingReview).IsFalse()
+	Expect(user).Equals(mock.AryaStark)
+	Expect(tenant).Equals(mock.DemoTenant)
+	ExpectHandler(&cmd.PublishRealtimeEvent{}).CalledOnce()
+	ExpectHandler(&cmd.TriggerWebhooks{}).CalledOnce()
+}
+
+func TestReplyByEmailTask_DifferentSender(t *testing.T) {
+	RegisterT(t)
+	setupReplyByEmail()
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewComment) error {
+		return nil
+	})
+
+	task := tasks.ReplyByEmail(&dto.InboundEmail{
+		From:          "jon.snow@got.com",
+		Authenticated: true,
+		Recipients: []string{
+			inbound.ReplyAddress(inbound.ReplyToken{TenantID: mock.DemoTenant.ID, UserID: mock.AryaStark.ID, PostNumber: replyPost.Number}),
+		},
+		Text: "I agree!",
+	})
+
+	err := mock.NewWorker().Execute(task)
+	Expect(err).IsNil()
+	ExpectHandler(&cmd.AddNewComment{}).CalledTimes(0)
+}
+
+func TestReplyByEmailTask_NotAuthenticated(t *testing.T) {
+	RegisterT(t)
+	setupReplyByEmail()
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewComment) error {
+		return nil
+	})
+
+	task := tasks.ReplyByEmail(&dto.InboundEmail{
+		From: "arya.stark@got.com",
+		Recipients: []string{
+			inbound.ReplyAddress(inbound.ReplyToken{TenantID: mock.DemoTenant.ID, UserID: mock.AryaStark.ID, PostNumber: replyPost.Number}),
+		},
+		Text: "I agree!",
+	})
+
+	err := mock.NewWorker().Execute(task)
+	Expect(err).IsNil()
+	ExpectHandler(&cmd.AddNewComment{}).CalledTimes(0)
+}
+
+func TestReplyByEmailTask_InvalidAddress(t *testing.T) {
+	RegisterT(t)
+	setupReplyByEmail()
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewComment) error {
+		return nil
+	})
+
+	task := tasks.ReplyByEmail(&dto.InboundEmail{
+		From:          "arya.stark@got.com",
+		Authenticated: true,
+		Recipients: []string{
+			"reply+1.2.1.00000000000000000000@inbound.got.com",
+		},
+		Text: "I agree!",
+	})
+
+	err := mock.NewWorker().Execute(task)
+	Expect(err).IsNil()
+	ExpectHandler(&query.GetTenantByID{}).CalledTimes(0)
+	ExpectHandler(&cmd.AddNewComment{}).CalledTimes(0)
+}
+
+func TestReplyByEmailTask_EmptyReply(t *testing.T) {
+	RegisterT(t)
+	setupReplyByEmail()
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewComment) error {
+		return nil
+	})
+
+	task := tasks.ReplyByEmail(&dto.InboundEmail{
+		From:          "arya.stark@got.com",
+		Authenticated: true,
+		Recipients: []string{
+			inbound.ReplyAddress(inbound.ReplyToken{TenantID: mock.DemoTenant.ID, UserID: mock.AryaStark.ID, PostNumber: replyPost.Number}),
+		},
+		Text: "> Add support for TypeScript",
+	})
+
+	err := mock.NewWorker().Execute(task)
+	Expect(err).IsNil()
+	ExpectHandler(&cmd.AddNewComment{}).CalledTimes(0)
+}
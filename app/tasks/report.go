@@ -0,0 +1,48 @@
+package tasks
+
+import (
+	"fmt"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/worker"
+)
+
+var reportResolutionTitles = map[enum.ReportResolution]string{
+	enum.ReportDismissed:     "Your report of a %s on **%s** was reviewed and no action was needed",
+	enum.ReportHidden:        "The %s you reported on **%s** has been hidden",
+	enum.ReportDeleted:       "The %s you reported on **%s** has been deleted",
+	enum.ReportAuthorBlocked: "The author of the %s you reported on **%s** has been blocked",
+}
+
+// NotifyReportersAboutResolution sends a web notification to the users who reported a post or comment once a moderator resolves it
+func NotifyReportersAboutResolution(reporters []*entity.User, post *entity.Post, isComment bool, resolution enum.ReportResolution) worker.Task {
+	return describe("Notify reporters about resolution", func(c *worker.Context) error {
+		kind := "post"
+		if isComment {
+			kind = "comment"
+		}
+
+		title := fmt.Sprintf(reportResolutionTitles[resolution], kind, post.Title)
+		link := fmt.Sprintf("/posts/%d/%s", post.Number, post.Slug)
+		if !isComment && resolution == enum.ReportDeleted {
+			link = ""
+		}
+
+		for _, user := range reporters {
+			err := bus.Dispatch(c, &cmd.AddNewNotification{
+				User:   user,
+				Title:  title,
+				Link:   link,
+				PostID: post.ID,
+			})
+			if err != nil {
+				return c.Failure(err)
+			}
+		}
+
+		return nil
+	})
+}
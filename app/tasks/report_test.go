@@ -0,0 +1,65 @@
+package tasks_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/entity"
+	"github.com/getfider/fider/app/models/enum"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/tasks"
+)
+
+func TestNotifyReportersAboutResolutionTask(t *testing.T) {
+	RegisterT(t)
+
+	notifications := make([]*cmd.AddNewNotification, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewNotification) error {
+		notifications = append(notifications, c)
+		return nil
+	})
+
+	worker := mock.NewWorker()
+	post := &entity.Post{ID: 1, Number: 1, Title: "Add support for TypeScript", Slug: "add-support-for-typescript"}
+
+	task := tasks.NotifyReportersAboutResolution([]*entity.User{mock.AryaStark}, post, true, enum.ReportHidden)
+	err := worker.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(notifications).HasLen(1)
+	Expect(notifications[0].User).Equals(mock.AryaStark)
+	Expect(notifications[0].PostID).Equals(post.ID)
+	Expect(notifications[0].Title).Equals("The comment you reported on **Add support for TypeScript** has been hidden")
+	Expect(notifications[0].Link).Equals("/posts/1/add-support-for-typescript")
+}
+
+func TestNotifyReportersAboutResolutionTask_DeletedPost(t *testing.T) {
+	RegisterT(t)
+
+	notifications := make([]*cmd.AddNewNotification, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewNotification) error {
+		notifications = append(notifications, c)
+		return nil
+	})
+
+	worker := mock.NewWorker()
+	post := &entity.Post{ID: 1, Number: 1, Title: "Buy cheap stuff", Slug: "buy-cheap-stuff"}
+
+	task := tasks.NotifyReportersAboutResolution([]*entity.User{mock.AryaStark, mock.JonSnow}, post, false, enum.ReportDeleted)
+	err := worker.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(notifications).HasLen(2)
+	Expect(notifications[0].Title).Equals("The post you reported on **Buy cheap stuff** has been deleted")
+	Expect(notifications[0].Link).Equals("")
+	Expect(notifications[1].User).Equals(mock.JonSnow)
+}
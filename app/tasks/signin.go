@@ -15,8 +15,9 @@ func SendSignInEmail(email, verificationKey string) worker.Task {
 			"siteName": c.Tenant().Name,
 			"link":     link(web.BaseURL(c), "/signin/verify?k=%s", verificationKey),
 		})
+		to.Locale = c.Tenant().Locale
 
-		bus.Publish(c, &cmd.SendMail{
+		err := bus.Dispatch(c, &cmd.SendMail{
 			From:         dto.Recipient{Name: c.Tenant().Name},
 			To:           []dto.Recipient{to},
 			TemplateName: "signin_email",
@@ -24,6 +25,9 @@ func SendSignInEmail(email, verificationKey string) worker.Task {
 				"logo": web.LogoURL(c),
 			},
 		})
+		if err != nil {
+			return c.Failure(err)
+		}
 
 		return nil
 	})
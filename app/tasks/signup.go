@@ -17,7 +17,7 @@ func SendSignUpEmail(action *actions.CreateTenant, baseURL string) worker.Task {
 			"link": link(baseURL, "/signup/verify?k=%s", action.VerificationKey),
 		})
 
-		bus.Publish(c, &cmd.SendMail{
+		err := bus.Dispatch(c, &cmd.SendMail{
 			From:         dto.Recipient{Name: "Fider"},
 			To:           []dto.Recipient{to},
 			TemplateName: "signup_email",
@@ -25,6 +25,9 @@ func SendSignUpEmail(action *actions.CreateTenant, baseURL string) worker.Task {
 				"logo": web.LogoURL(c),
 			},
 		})
+		if err != nil {
+			return c.Failure(err)
+		}
 
 		return nil
 	})
@@ -43,7 +46,7 @@ func SendWelcomeEmail(name, email, baseURL string) worker.Task {
 			"url":  link(baseURL, "/"),
 		})
 
-		bus.Publish(c, &cmd.SendMail{
+		err := bus.Dispatch(c, &cmd.SendMail{
 			From: dto.Recipient{
 				Name:    "Guilherme from Fider",
 				Address: "goenning@fider.io",
@@ -54,6 +57,9 @@ func SendWelcomeEmail(name, email, baseURL string) worker.Task {
 				"logo": web.LogoURL(c),
 			},
 		})
+		if err != nil {
+			return c.Failure(err)
+		}
 
 		return nil
 	})
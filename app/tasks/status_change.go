@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/getfider/fider/app/models/cmd"
@@ -15,7 +16,7 @@ import (
 	"github.com/getfider/fider/app/pkg/worker"
 )
 
-//NotifyAboutStatusChange sends a notification (web and email) to subscribers
+//NotifyAboutStatusChange sends a notification (web, push and email) to subscribers
 func NotifyAboutStatusChange(post *entity.Post, prevStatus enum.PostStatus) worker.Task {
 	return describe("Notify about post status change", func(c *worker.Context) error {
 		//Don't notify if previous status is the same
@@ -46,6 +47,15 @@ func NotifyAboutStatusChange(post *entity.Post, prevStatus enum.PostStatus) work
 			}
 		}
 
+		// Push notification
+		statusKey := fmt.Sprintf("enum.poststatus.%s", post.Status.Name())
+		pushTitle := func(ctx context.Context) string {
+			return i18n.T(ctx, "push.change_status", i18n.Params{"userName": author.Name, "status": i18n.T(ctx, statusKey)})
+		}
+		if err := sendPushNotifications(c, post, eventSubscribers(post, enum.NotificationEventChangeStatus), pushTitle, link); err != nil {
+			return c.Failure(err)
+		}
+
 		// Email notification
 		users, err = getActiveSubscribers(c, post, enum.NotificationChannelEmail, enum.NotificationEventChangeStatus)
 		if err != nil {
@@ -58,35 +68,39 @@ func NotifyAboutStatusChange(post *entity.Post, prevStatus enum.PostStatus) work
 			duplicate = linkWithText(post.Response.Original.Title, baseURL, "/posts/%d/%s", post.Response.Original.Number, post.Response.Original.Slug)
 		}
 
-		to := make([]dto.Recipient, 0)
-		for _, user := range users {
-			if user.ID != author.ID {
-				to = append(to, dto.NewRecipient(user.Name, user.Email, dto.Props{}))
+		to, err := emailRecipients(c, users, post, enum.NotificationEventChangeStatus, post.Response.Text, func(ctx context.Context) (string, dto.Props) {
+			status := i18n.T(ctx, statusKey)
+			return i18n.T(ctx, "email.digest.change_status", i18n.Params{"userName": author.Name, "status": status}), dto.Props{
+				"status": status,
+				"view":   linkWithText(i18n.T(ctx, "email.subscription.view"), baseURL, "/posts/%d/%s", post.Number, post.Slug),
+				"change": linkWithText(i18n.T(ctx, "email.subscription.change"), baseURL, "/settings"),
 			}
+		})
+		if err != nil {
+			return c.Failure(err)
 		}
 
 		tenant := c.Tenant()
 		logoURL := web.LogoURL(c)
 
 		props := dto.Props{
-			"title":       post.Title,
-			"postLink":    linkWithText(fmt.Sprintf("#%d", post.Number), baseURL, "/posts/%d/%s", post.Number, post.Slug),
-			"siteName":    tenant.Name,
-			"content":     markdown.Full(post.Response.Text),
-			"status":      i18n.T(c, fmt.Sprintf("enum.poststatus.%s", post.Status.Name())),
-			"duplicate":   duplicate,
-			"view":        linkWithText(i18n.T(c, "email.subscription.view"), baseURL, "/posts/%d/%s", post.Number, post.Slug),
-			"unsubscribe": linkWithText(i18n.T(c, "email.subscription.unsubscribe"), baseURL, "/posts/%d/%s", post.Number, post.Slug),
-			"change":      linkWithText(i18n.T(c, "email.subscription.change"), baseURL, "/settings"),
-			"logo":        logoURL,
+			"title":     post.Title,
+			"postLink":  linkWithText(fmt.Sprintf("#%d", post.Number), baseURL, "/posts/%d/%s", post.Number, post.Slug),
+			"siteName":  tenant.Name,
+			"content":   markdown.Full(post.Response.Text),
+			"duplicate": duplicate,
+			"logo":      logoURL,
 		}
 
-		bus.Publish(c, &cmd.SendMail{
+		err = bus.Dispatch(c, &cmd.SendMail{
 			From:         dto.Recipient{Name: author.Name},
 			To:           to,
 			TemplateName: "change_status",
 			Props:        props,
 		})
+		if err != nil {
+			return c.Failure(err)
+		}
 
 		webhookProps := webhook.Props{"post_old_status": prevStatus.Name()}
 		webhookProps.SetPost(post, "post", baseURL, true, true)
@@ -16,6 +16,8 @@ import (
 	"github.com/getfider/fider/app/models/dto"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/jwt"
 	"github.com/getfider/fider/app/pkg/mock"
 	"github.com/getfider/fider/app/services/email/emailmock"
 	"github.com/getfider/fider/app/tasks"
@@ -38,6 +40,10 @@ func TestNotifyAboutStatusChangeTask(t *testing.T) {
 		return nil
 	})
 
+	bus.AddHandler(func(ctx context.Context, q *query.GetSavedSearchFollowers) error {
+		return nil
+	})
+
 	var triggerWebhooks *cmd.TriggerWebhooks
 	bus.AddHandler(func(ctx context.Context, c *cmd.TriggerWebhooks) error {
 		triggerWebhooks = c
@@ -73,26 +79,23 @@ func TestNotifyAboutStatusChangeTask(t *testing.T) {
 	Expect(emailmock.MessageHistory[0].TemplateName).Equals("change_status")
 	Expect(emailmock.MessageHistory[0].Tenant).Equals(mock.DemoTenant)
 	Expect(emailmock.MessageHistory[0].Props).Equals(dto.Props{
-		"title":       "Add support for TypeScript",
-		"postLink":    "<a href='http://domain.com/posts/1/add-support-for-typescript'>#1</a>",
-		"siteName":    "Demonstration",
-		"content":     template.HTML("<p>Planned for next release.</p>"),
-		"duplicate":   "",
-		"status":      "Planned",
-		"view":        "<a href='http://domain.com/posts/1/add-support-for-typescript'>view it on your browser</a>",
-		"change":      "<a href='http://domain.com/settings'>change your notification preferences</a>",
-		"unsubscribe": "<a href='http://domain.com/posts/1/add-support-for-typescript'>unsubscribe from it</a>",
-		"logo":        "https://fider.io/images/logo-100x100.png",
+		"title":     "Add support for TypeScript",
+		"postLink":  "<a href='http://domain.com/posts/1/add-support-for-typescript'>#1</a>",
+		"siteName":  "Demonstration",
+		"content":   template.HTML("<p>Planned for next release.</p>"),
+		"duplicate": "",
+		"logo":      "https://fider.io/images/logo-100x100.png",
 	})
 	Expect(emailmock.MessageHistory[0].From).Equals(dto.Recipient{
 		Name: "Jon Snow",
 	})
 	Expect(emailmock.MessageHistory[0].To).HasLen(1)
-	Expect(emailmock.MessageHistory[0].To[0]).Equals(dto.Recipient{
-		Name:    "Arya Stark",
-		Address: "arya.stark@got.com",
-		Props:   dto.Props{},
-	})
+	Expect(emailmock.MessageHistory[0].To[0].Name).Equals("Arya Stark")
+	Expect(emailmock.MessageHistory[0].To[0].Address).Equals("arya.stark@got.com")
+	Expect(emailmock.MessageHistory[0].To[0].Props["status"]).Equals("Planned")
+	Expect(emailmock.MessageHistory[0].To[0].Props["view"]).Equals("<a href='http://domain.com/posts/1/add-support-for-typescript'>view it on your browser</a>")
+	Expect(emailmock.MessageHistory[0].To[0].Props["change"]).Equals("<a href='http://domain.com/settings'>change your notification preferences</a>")
+	expectUnsubscribeLink(emailmock.MessageHistory[0].To[0], "unsubscribe from it", jwt.UnsubscribeClaims{TenantID: mock.DemoTenant.ID, UserID: mock.AryaStark.ID, PostNumber: 1})
 
 	Expect(addNewNotification).IsNotNil()
 	Expect(addNewNotification.PostID).Equals(post.ID)
@@ -151,6 +154,10 @@ func TestNotifyAboutStatusChangeTask_Duplicate(t *testing.T) {
 		return nil
 	})
 
+	bus.AddHandler(func(ctx context.Context, q *query.GetSavedSearchFollowers) error {
+		return nil
+	})
+
 	var triggerWebhooks *cmd.TriggerWebhooks
 	bus.AddHandler(func(ctx context.Context, c *cmd.TriggerWebhooks) error {
 		triggerWebhooks = c
@@ -190,26 +197,23 @@ func TestNotifyAboutStatusChangeTask_Duplicate(t *testing.T) {
 	Expect(emailmock.MessageHistory[0].TemplateName).Equals("change_status")
 	Expect(emailmock.MessageHistory[0].Tenant).Equals(mock.DemoTenant)
 	Expect(emailmock.MessageHistory[0].Props).Equals(dto.Props{
-		"title":       "I need TypeScript",
-		"postLink":    "<a href='http://domain.com/posts/2/i-need-typescript'>#2</a>",
-		"siteName":    "Demonstration",
-		"content":     template.HTML(""),
-		"duplicate":   "<a href='http://domain.com/posts/1/add-support-for-typescript'>Add support for TypeScript</a>",
-		"status":      "Duplicate",
-		"view":        "<a href='http://domain.com/posts/2/i-need-typescript'>view it on your browser</a>",
-		"change":      "<a href='http://domain.com/settings'>change your notification preferences</a>",
-		"unsubscribe": "<a href='http://domain.com/posts/2/i-need-typescript'>unsubscribe from it</a>",
-		"logo":        "https://fider.io/images/logo-100x100.png",
+		"title":     "I need TypeScript",
+		"postLink":  "<a href='http://domain.com/posts/2/i-need-typescript'>#2</a>",
+		"siteName":  "Demonstration",
+		"content":   template.HTML(""),
+		"duplicate": "<a href='http://domain.com/posts/1/add-support-for-typescript'>Add support for TypeScript</a>",
+		"logo":      "https://fider.io/images/logo-100x100.png",
 	})
 	Expect(emailmock.MessageHistory[0].From).Equals(dto.Recipient{
 		Name: "Jon Snow",
 	})
 	Expect(emailmock.MessageHistory[0].To).HasLen(1)
-	Expect(emailmock.MessageHistory[0].To[0]).Equals(dto.Recipient{
-		Name:    "Arya Stark",
-		Address: "arya.stark@got.com",
-		Props:   dto.Props{},
-	})
+	Expect(emailmock.MessageHistory[0].To[0].Name).Equals("Arya Stark")
+	Expect(emailmock.MessageHistory[0].To[0].Address).Equals("arya.stark@got.com")
+	Expect(emailmock.MessageHistory[0].To[0].Props["status"]).Equals("Duplicate")
+	Expect(emailmock.MessageHistory[0].To[0].Props["view"]).Equals("<a href='http://domain.com/posts/2/i-need-typescript'>view it on your browser</a>")
+	Expect(emailmock.MessageHistory[0].To[0].Props["change"]).Equals("<a href='http://domain.com/settings'>change your notification preferences</a>")
+	expectUnsubscribeLink(emailmock.MessageHistory[0].To[0], "unsubscribe from it", jwt.UnsubscribeClaims{TenantID: mock.DemoTenant.ID, UserID: mock.AryaStark.ID, PostNumber: 2})
 
 	Expect(addNewNotification).IsNotNil()
 	Expect(addNewNotification.PostID).Equals(post.ID)
@@ -255,3 +259,77 @@ func TestNotifyAboutStatusChangeTask_Duplicate(t *testing.T) {
 		"tenant_url":                    "http://domain.com",
 	})
 }
+
+func TestNotifyAboutStatusChangeTask_WebPush(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+	env.Config.WebPush.VAPIDPublicKey = "public"
+	env.Config.WebPush.VAPIDPrivateKey = "private"
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewNotification) error {
+		return nil
+	})
+	bus.AddHandler(func(ctx context.Context, c *cmd.TriggerWebhooks) error {
+		return nil
+	})
+
+	var channels []enum.NotificationChannel
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveSubscribers) error {
+		channels = append(channels, q.Channel)
+		q.Result = []*entity.User{mock.JonSnow, mock.AryaStark}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetSavedSearchFollowers) error {
+		return nil
+	})
+
+	var getPushSubscriptions *query.GetPushSubscriptions
+	bus.AddHandler(func(ctx context.Context, q *query.GetPushSubscriptions) error {
+		getPushSubscriptions = q
+		q.Result = []*entity.PushSubscription{
+			{ID: 1, UserID: mock.AryaStark.ID, Endpoint: "https://push.example.com/1"},
+			{ID: 2, UserID: mock.AryaStark.ID, Endpoint: "https://push.example.com/2"},
+		}
+		return nil
+	})
+
+	var sent []*cmd.SendWebPush
+	bus.AddListener(func(ctx context.Context, c *cmd.SendWebPush) error {
+		sent = append(sent, c)
+		return nil
+	})
+
+	post := &entity.Post{
+		ID:     1,
+		Number: 1,
+		Title:  "Add support for TypeScript",
+		Slug:   "add-support-for-typescript",
+		Status: enum.PostCompleted,
+		Response: &entity.PostResponse{
+			RespondedAt: time.Now(),
+			Text:        "Shipped!",
+			User:        mock.JonSnow,
+		},
+	}
+
+	err := mock.NewWorker().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithBaseURL("http://domain.com").
+		Execute(tasks.NotifyAboutStatusChange(post, enum.PostStarted))
+
+	Expect(err).IsNil()
+	Expect(channels).Equals([]enum.NotificationChannel{
+		enum.NotificationChannelWeb,
+		enum.NotificationChannelPush,
+		enum.NotificationChannelEmail,
+	})
+	Expect(getPushSubscriptions.UserIDs).Equals([]int{mock.AryaStark.ID})
+	Expect(sent).HasLen(2)
+	Expect(sent[0].Subscription.Endpoint).Equals("https://push.example.com/1")
+	Expect(sent[0].Title).Equals("Jon Snow changed the status to Completed")
+	Expect(sent[0].Body).Equals("Add support for TypeScript")
+	Expect(sent[0].URL).Equals("http://domain.com/posts/1/add-support-for-typescript")
+	Expect(sent[1].Subscription.Endpoint).Equals("https://push.example.com/2")
+}
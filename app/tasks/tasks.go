@@ -3,11 +3,20 @@ package tasks
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/models/entity"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/inbound"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/web"
 	"github.com/getfider/fider/app/pkg/worker"
 )
 
@@ -15,6 +24,17 @@ func describe(name string, job worker.Job) worker.Task {
 	return worker.Task{Name: name, Job: job}
 }
 
+// OnBehalfOf runs given task as if it had been triggered by given user
+// It's used when content of a user is published by someone else, e.g: a moderator approving it
+func OnBehalfOf(user *entity.User, task worker.Task) worker.Task {
+	job := task.Job
+	task.Job = func(c *worker.Context) error {
+		c.Set(app.UserCtxKey, user)
+		return job(c)
+	}
+	return task
+}
+
 func link(baseURL, path string, args ...any) string {
 	return fmt.Sprintf("<a href='%[1]s%[2]s'>%[1]s%[2]s</a>", baseURL, fmt.Sprintf(path, args...))
 }
@@ -23,12 +43,201 @@ func linkWithText(text, baseURL, path string, args ...any) string {
 	return fmt.Sprintf("<a href='%s%s'>%s</a>", baseURL, fmt.Sprintf(path, args...), text)
 }
 
+// getActiveSubscribers returns who should be notified about an event on given post through given channel
+// Users following a saved search that matches the post are also notified when it's created or changes status
+// Posts are usually tagged after they are created, so followers are also notified by NotifyAboutTaggedPost
 func getActiveSubscribers(ctx context.Context, post *entity.Post, channel enum.NotificationChannel, event enum.NotificationEvent) ([]*entity.User, error) {
 	q := &query.GetActiveSubscribers{
 		Number:  post.Number,
 		Channel: channel,
 		Event:   event,
 	}
-	err := bus.Dispatch(ctx, q)
-	return q.Result, err
+	if err := bus.Dispatch(ctx, q); err != nil {
+		return nil, err
+	}
+
+	if event.UserSettingsKeyName != enum.NotificationEventNewPost.UserSettingsKeyName &&
+		event.UserSettingsKeyName != enum.NotificationEventChangeStatus.UserSettingsKeyName {
+		return q.Result, nil
+	}
+
+	getFollowers := &query.GetSavedSearchFollowers{Post: post, Channel: channel}
+	if err := bus.Dispatch(ctx, getFollowers); err != nil {
+		return nil, err
+	}
+
+	users := q.Result
+	subscribed := make(map[int]bool, len(users))
+	for _, user := range users {
+		subscribed[user.ID] = true
+	}
+	for _, follower := range getFollowers.Result {
+		if !subscribed[follower.ID] {
+			users = append(users, follower)
+		}
+	}
+	return users, nil
+}
+
+// subscribersOf returns who should be notified about a post through given channel
+type subscribersOf func(ctx context.Context, channel enum.NotificationChannel) ([]*entity.User, error)
+
+// eventSubscribers returns the active subscribers of given event on given post
+func eventSubscribers(post *entity.Post, event enum.NotificationEvent) subscribersOf {
+	return func(ctx context.Context, channel enum.NotificationChannel) ([]*entity.User, error) {
+		return getActiveSubscribers(ctx, post, channel, event)
+	}
+}
+
+// tagFollowers returns the users following a saved search that includes given tag and now matches given post
+func tagFollowers(post *entity.Post, tag *entity.Tag) subscribersOf {
+	return func(ctx context.Context, channel enum.NotificationChannel) ([]*entity.User, error) {
+		q := &query.GetSavedSearchFollowers{Post: post, Channel: channel, Tag: tag}
+		if err := bus.Dispatch(ctx, q); err != nil {
+			return nil, err
+		}
+		return q.Result, nil
+	}
+}
+
+// recipientLocale returns the locale used to notify given user
+// Users without a preference get the locale of the tenant, rather than the one of who triggered the task
+func recipientLocale(c *worker.Context, user *entity.User) string {
+	if i18n.IsValidLocale(user.Locale) {
+		return user.Locale
+	}
+	return c.Tenant().Locale
+}
+
+// localizedTexts returns the digest title and the email props of a notification, translated to the locale of given context
+type localizedTexts func(ctx context.Context) (title string, props dto.Props)
+
+// emailRecipients returns who should be emailed right away about an event on given post
+// The author is left out and the event is queued for users that prefer a daily or weekly digest
+// Each recipient gets a signed link to unsubscribe from the post, or from the event when it doesn't depend on subscriptions
+func emailRecipients(c *worker.Context, users []*entity.User, post *entity.Post, event enum.NotificationEvent, content string, texts localizedTexts) ([]dto.Recipient, error) {
+	author := c.User()
+	userIDs := make([]int, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+
+	getDelivery := &query.GetUsersEmailDelivery{UserIDs: userIDs}
+	if err := bus.Dispatch(c, getDelivery); err != nil {
+		return nil, err
+	}
+
+	baseURL := web.BaseURL(c)
+	to := make([]dto.Recipient, 0)
+	for _, user := range users {
+		if user.ID == author.ID {
+			continue
+		}
+
+		locale := recipientLocale(c, user)
+		ctx := i18n.WithLocale(c, locale)
+		title, props := texts(ctx)
+
+		delivery, ok := getDelivery.Result[user.ID]
+		if ok && delivery != enum.EmailDeliveryImmediate {
+			err := bus.Dispatch(c, &cmd.AddEmailDigestItem{
+				User:     user,
+				Delivery: delivery,
+				PostID:   post.ID,
+				Title:    title,
+				Content:  content,
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		token, err := unsubscribeToken(c, user, post, event)
+		if err != nil {
+			return nil, err
+		}
+
+		props["unsubscribe"] = linkWithText(unsubscribeText(ctx, event), baseURL, "/unsubscribe/%s", token)
+		recipient := dto.NewRecipient(user.Name, user.Email, props)
+		recipient.Unsubscribe = fmt.Sprintf("%s/unsubscribe/%s", baseURL, token)
+		recipient.Locale = locale
+		if env.IsInboundEmailEnabled() {
+			recipient.ReplyTo = inbound.ReplyAddress(inbound.ReplyToken{TenantID: c.Tenant().ID, UserID: user.ID, PostNumber: post.Number})
+			recipient.Props["replyByEmail"] = true
+		}
+		to = append(to, recipient)
+	}
+
+	return to, nil
+}
+
+// unsubscribeToken returns a token that unsubscribes given user from given post without signing in
+// Events that users receive without subscribing to the post are unsubscribed from altogether
+func unsubscribeToken(c *worker.Context, user *entity.User, post *entity.Post, event enum.NotificationEvent) (string, error) {
+	claims := jwt.UnsubscribeClaims{
+		TenantID: c.Tenant().ID,
+		UserID:   user.ID,
+		Metadata: jwt.Metadata{
+			ExpiresAt: jwt.Time(time.Now().Add(365 * 24 * time.Hour)),
+		},
+	}
+	if len(event.RequiresSubscriptionUserRoles) > 0 {
+		claims.PostNumber = post.Number
+	} else {
+		claims.Event = event.UserSettingsKeyName
+	}
+
+	return jwt.Encode(claims)
+}
+
+func unsubscribeText(ctx context.Context, event enum.NotificationEvent) string {
+	if len(event.RequiresSubscriptionUserRoles) > 0 {
+		return i18n.T(ctx, "email.subscription.unsubscribe")
+	}
+	return i18n.T(ctx, "email.subscription.unsubscribe_event")
+}
+
+// sendPushNotifications delivers a Web Push notification to every browser registered by the subscribers of given event
+// The author is left out and nothing is queried when Web Push is not configured
+// The title is translated to the locale of each subscriber
+func sendPushNotifications(c *worker.Context, post *entity.Post, subscribers subscribersOf, title func(ctx context.Context) string, link string) error {
+	if !env.IsWebPushEnabled() {
+		return nil
+	}
+
+	users, err := subscribers(c, enum.NotificationChannelPush)
+	if err != nil {
+		return err
+	}
+
+	author := c.User()
+	userIDs := make([]int, 0, len(users))
+	titles := make(map[int]string, len(users))
+	for _, user := range users {
+		if user.ID != author.ID {
+			userIDs = append(userIDs, user.ID)
+			titles[user.ID] = title(i18n.WithLocale(c, recipientLocale(c, user)))
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	q := &query.GetPushSubscriptions{UserIDs: userIDs}
+	if err := bus.Dispatch(c, q); err != nil {
+		return err
+	}
+
+	url := web.BaseURL(c) + link
+	for _, subscription := range q.Result {
+		bus.Publish(c, &cmd.SendWebPush{
+			Subscription: subscription,
+			Title:        titles[subscription.UserID],
+			Body:         post.Title,
+			URL:          url,
+		})
+	}
+
+	return nil
 }
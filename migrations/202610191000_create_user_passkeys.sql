@@ -0,0 +1,19 @@
+ALTER TABLE tenants ADD passkey_mode SMALLINT NOT NULL DEFAULT 2;
+
+CREATE TABLE IF NOT EXISTS user_passkeys (
+  id            SERIAL PRIMARY KEY,
+  tenant_id     INT NOT NULL,
+  user_id       INT NOT NULL,
+  name          VARCHAR(50) NOT NULL,
+  credential_id TEXT NOT NULL,
+  public_key    BYTEA NOT NULL,
+  sign_count    BIGINT NOT NULL DEFAULT 0,
+  transports    VARCHAR(20)[] NOT NULL DEFAULT '{}',
+  created_at    TIMESTAMPTZ NOT NULL,
+  last_used_at  TIMESTAMPTZ NULL,
+  FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+  FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE UNIQUE INDEX user_passkeys_credential_id_uq ON user_passkeys (tenant_id, credential_id);
+CREATE INDEX user_passkeys_user_id_idx ON user_passkeys (tenant_id, user_id);
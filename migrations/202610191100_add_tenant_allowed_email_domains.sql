@@ -0,0 +1,2 @@
+ALTER TABLE tenants ADD allowed_email_domains TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE tenants ADD auto_join_role SMALLINT NOT NULL DEFAULT 1;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS audit_logs (
+  id           SERIAL PRIMARY KEY,
+  tenant_id    INT NOT NULL,
+  actor_id     INT NULL,
+  action       VARCHAR(50) NOT NULL,
+  target_type  VARCHAR(50) NOT NULL,
+  target_id    VARCHAR(100) NOT NULL,
+  before_value JSONB NULL,
+  after_value  JSONB NULL,
+  client_ip    VARCHAR(50) NULL,
+  created_at   TIMESTAMPTZ NOT NULL,
+  FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+  FOREIGN KEY (actor_id) REFERENCES users (id)
+);
+
+CREATE INDEX audit_logs_tenant_id_created_at_idx ON audit_logs (tenant_id, created_at DESC);
+
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_append_only_trg
+  BEFORE UPDATE OR DELETE ON audit_logs
+  FOR EACH ROW EXECUTE PROCEDURE audit_logs_append_only();
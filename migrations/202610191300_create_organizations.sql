@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS organizations (
+  id          SERIAL PRIMARY KEY,
+  tenant_id   INT NOT NULL,
+  external_id VARCHAR(100) NULL,
+  name        VARCHAR(100) NOT NULL,
+  plan        VARCHAR(50) NOT NULL DEFAULT '',
+  segment     VARCHAR(50) NOT NULL DEFAULT '',
+  mrr         NUMERIC(14, 2) NOT NULL DEFAULT 0,
+  created_at  TIMESTAMPTZ NOT NULL,
+  updated_at  TIMESTAMPTZ NOT NULL,
+  FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+
+CREATE UNIQUE INDEX organizations_tenant_id_external_id_key ON organizations (tenant_id, external_id) WHERE external_id IS NOT NULL;
+
+ALTER TABLE users ADD organization_id INT NULL;
+ALTER TABLE users ADD FOREIGN KEY (organization_id) REFERENCES organizations (id);
+CREATE INDEX users_organization_id_idx ON users (organization_id);
@@ -0,0 +1,2 @@
+ALTER TABLE audit_logs ADD impersonated_id INT NULL;
+ALTER TABLE audit_logs ADD FOREIGN KEY (impersonated_id) REFERENCES users (id);
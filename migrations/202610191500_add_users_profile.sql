@@ -0,0 +1,3 @@
+ALTER TABLE users ADD bio VARCHAR(500) NULL;
+ALTER TABLE users ADD link VARCHAR(300) NULL;
+ALTER TABLE users ADD profile_hidden BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS user_imports (
+  id          SERIAL PRIMARY KEY,
+  tenant_id   INT NOT NULL,
+  user_id     INT NOT NULL,
+  status      SMALLINT NOT NULL,
+  total       INT NOT NULL,
+  processed   INT NOT NULL DEFAULT 0,
+  created     INT NOT NULL DEFAULT 0,
+  updated     INT NOT NULL DEFAULT 0,
+  failed      INT NOT NULL DEFAULT 0,
+  errors      JSONB NULL,
+  created_at  TIMESTAMPTZ NOT NULL,
+  finished_at TIMESTAMPTZ NULL,
+  FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+  FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX user_imports_tenant_id_idx ON user_imports (tenant_id);
@@ -0,0 +1 @@
+ALTER TABLE tenants ADD sso_secret VARCHAR(100) NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS rate_limits (
+  key        VARCHAR(200) NOT NULL,
+  hits       INT NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (key)
+);
+
+CREATE INDEX rate_limits_expires_at_idx ON rate_limits (expires_at);
@@ -0,0 +1,5 @@
+ALTER TABLE posts ADD pending_review BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE comments ADD pending_review BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX posts_pending_review_idx ON posts (tenant_id) WHERE pending_review = true;
+CREATE INDEX comments_pending_review_idx ON comments (tenant_id) WHERE pending_review = true;
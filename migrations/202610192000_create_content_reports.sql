@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS content_reports (
+  id             SERIAL PRIMARY KEY,
+  tenant_id      INT NOT NULL,
+  post_id        INT NOT NULL,
+  comment_id     INT NULL,
+  user_id        INT NOT NULL,
+  reason         VARCHAR(20) NOT NULL,
+  details        VARCHAR(500) NOT NULL DEFAULT '',
+  created_at     TIMESTAMPTZ NOT NULL,
+  resolution     VARCHAR(20) NULL,
+  resolved_at    TIMESTAMPTZ NULL,
+  resolved_by_id INT NULL,
+  FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+  FOREIGN KEY (post_id) REFERENCES posts (id),
+  FOREIGN KEY (comment_id) REFERENCES comments (id),
+  FOREIGN KEY (user_id) REFERENCES users (id),
+  FOREIGN KEY (resolved_by_id) REFERENCES users (id)
+);
+
+CREATE INDEX content_reports_open_idx ON content_reports (tenant_id, post_id, comment_id) WHERE resolved_at IS NULL;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS user_suspensions (
+  id            SERIAL PRIMARY KEY,
+  tenant_id     INT NOT NULL,
+  user_id       INT NOT NULL,
+  reason        VARCHAR(500) NOT NULL,
+  read_only     BOOLEAN NOT NULL DEFAULT false,
+  created_at    TIMESTAMPTZ NOT NULL,
+  created_by_id INT NOT NULL,
+  ends_at       TIMESTAMPTZ NULL,
+  lifted_at     TIMESTAMPTZ NULL,
+  FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+  FOREIGN KEY (user_id) REFERENCES users (id),
+  FOREIGN KEY (created_by_id) REFERENCES users (id)
+);
+
+CREATE INDEX user_suspensions_active_idx ON user_suspensions (tenant_id, user_id) WHERE lifted_at IS NULL;
+CREATE INDEX user_suspensions_ends_at_idx ON user_suspensions (ends_at) WHERE lifted_at IS NULL;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS email_digest_items (
+  id         SERIAL PRIMARY KEY,
+  tenant_id  INT NOT NULL,
+  user_id    INT NOT NULL,
+  post_id    INT NOT NULL,
+  delivery   VARCHAR(20) NOT NULL,
+  title      TEXT NOT NULL,
+  content    TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+  FOREIGN KEY (user_id) REFERENCES users (id),
+  FOREIGN KEY (post_id) REFERENCES posts (id)
+);
+
+CREATE INDEX email_digest_items_delivery_idx ON email_digest_items (delivery, tenant_id);
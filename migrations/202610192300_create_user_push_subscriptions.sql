@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS user_push_subscriptions (
+  id         SERIAL PRIMARY KEY,
+  tenant_id  INT NOT NULL,
+  user_id    INT NOT NULL,
+  endpoint   TEXT NOT NULL,
+  key_p256dh VARCHAR(200) NOT NULL,
+  key_auth   VARCHAR(50) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+  FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE UNIQUE INDEX user_push_subscriptions_endpoint_idx ON user_push_subscriptions (tenant_id, endpoint);
+CREATE INDEX user_push_subscriptions_user_idx ON user_push_subscriptions (tenant_id, user_id);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS email_templates (
+  id         SERIAL PRIMARY KEY,
+  tenant_id  INT NOT NULL,
+  kind       VARCHAR(50) NOT NULL,
+  subject    TEXT NOT NULL,
+  body       TEXT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL,
+  FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+
+CREATE UNIQUE INDEX email_templates_kind_idx ON email_templates (tenant_id, kind);
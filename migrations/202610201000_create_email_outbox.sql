@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS email_outbox (
+  id               BIGSERIAL PRIMARY KEY,
+  tenant_id        INT NULL,
+  batch_id         VARCHAR(32) NOT NULL,
+  locale           VARCHAR(10) NOT NULL,
+  template_name    VARCHAR(50) NOT NULL,
+  from_name        VARCHAR(200) NOT NULL,
+  from_address     VARCHAR(200) NOT NULL,
+  to_name          VARCHAR(200) NOT NULL,
+  to_address       VARCHAR(200) NOT NULL,
+  reply_to         VARCHAR(200) NOT NULL,
+  props            JSONB NOT NULL,
+  recipient_props  JSONB NOT NULL,
+  custom_template  JSONB NULL,
+  status           SMALLINT NOT NULL,
+  attempts         INT NOT NULL DEFAULT 0,
+  last_error       TEXT NULL,
+  next_attempt_at  TIMESTAMPTZ NOT NULL,
+  created_at       TIMESTAMPTZ NOT NULL,
+  sent_at          TIMESTAMPTZ NULL,
+  FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+
+CREATE INDEX email_outbox_due_idx ON email_outbox (next_attempt_at) WHERE status = 1;
+CREATE INDEX email_outbox_tenant_idx ON email_outbox (tenant_id, created_at);
@@ -0,0 +1 @@
+ALTER TABLE email_outbox ADD unsubscribe_url TEXT NOT NULL DEFAULT '';
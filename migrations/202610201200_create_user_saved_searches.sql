@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS user_saved_searches (
+  id         SERIAL PRIMARY KEY,
+  tenant_id  INT NOT NULL,
+  user_id    INT NOT NULL,
+  name       VARCHAR(100) NOT NULL,
+  view       VARCHAR(50) NOT NULL,
+  tag_ids    INT[] NOT NULL,
+  channels   INT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+  FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX user_saved_searches_user_idx ON user_saved_searches (tenant_id, user_id);
@@ -0,0 +1,2 @@
+ALTER TABLE posts ADD is_hidden BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE comments ADD is_hidden BOOLEAN NOT NULL DEFAULT false;
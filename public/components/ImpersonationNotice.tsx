@@ -0,0 +1,29 @@
+import React from "react"
+import { useFider } from "@fider/hooks"
+import { actions, navigator } from "@fider/services"
+import { Message } from "./common"
+
+export const ImpersonationNotice = () => {
+  const fider = useFider()
+  const impersonator = fider.session.impersonator
+  if (!impersonator || !fider.session.isAuthenticated) {
+    return null
+  }
+
+  const stop = async (e: React.MouseEvent) => {
+    e.preventDefault()
+    const result = await actions.stopImpersonation()
+    if (result.ok) {
+      navigator.goTo("/admin/members")
+    }
+  }
+
+  return (
+    <Message alignment="center" type="warning">
+      You are viewing this site as <strong>{fider.session.user.name}</strong>. Every change you make is recorded under your name ({impersonator.name}).{" "}
+      <a className="text-link" href="#" onClick={stop}>
+        Stop impersonating
+      </a>
+    </Message>
+  )
+}
@@ -3,7 +3,7 @@ import "./NotificationIndicator.scss"
 import React, { useEffect, useState } from "react"
 import IconBell from "@fider/assets/images/heroicons-bell.svg"
 import { useFider } from "@fider/hooks"
-import { actions } from "@fider/services"
+import { actions, realtime } from "@fider/services"
 import { Icon } from "./common"
 
 export const NotificationIndicator = () => {
@@ -11,13 +11,20 @@ export const NotificationIndicator = () => {
   const [unreadNotifications, setUnreadNotifications] = useState(0)
 
   useEffect(() => {
-    if (fider.session.isAuthenticated) {
+    if (!fider.session.isAuthenticated) {
+      return
+    }
+
+    const refresh = () => {
       actions.getTotalUnreadNotifications().then((result) => {
-        if (result.ok && result.data > 0) {
+        if (result.ok) {
           setUnreadNotifications(result.data)
         }
       })
     }
+
+    refresh()
+    return realtime.subscribe("notification", refresh)
   }, [fider.session.isAuthenticated])
 
   return (
@@ -1,6 +1,6 @@
 import "./VoteCounter.scss"
 
-import React, { useState } from "react"
+import React, { useEffect, useState } from "react"
 import { Post, PostStatus } from "@fider/models"
 import { actions, classSet } from "@fider/services"
 import { Icon, SignInModal } from "@fider/components"
@@ -17,6 +17,11 @@ export const VoteCounter = (props: VoteCounterProps) => {
   const [votesCount, setVotesCount] = useState(props.post.votesCount)
   const [isSignInModalOpen, setIsSignInModalOpen] = useState(false)
 
+  useEffect(() => {
+    setHasVoted(props.post.hasVoted)
+    setVotesCount(props.post.votesCount)
+  }, [props.post.hasVoted, props.post.votesCount])
+
   const voteOrUndo = async () => {
     if (!fider.session.isAuthenticated) {
       setIsSignInModalOpen(true)
@@ -3,9 +3,10 @@ import "./SignInControl.scss"
 import React, { useState } from "react"
 import { SocialSignInButton, Form, Button, Input, Message } from "@fider/components"
 import { Divider } from "@fider/components/layout"
-import { device, actions, Failure, isCookieEnabled } from "@fider/services"
+import { device, actions, webauthn, Failure, isCookieEnabled } from "@fider/services"
+import { PasskeyMode } from "@fider/models"
 import { useFider } from "@fider/hooks"
-import { Trans } from "@lingui/macro"
+import { t, Trans } from "@lingui/macro"
 
 interface SignInControlProps {
   useEmail: boolean
@@ -17,6 +18,8 @@ export const SignInControl: React.FunctionComponent<SignInControlProps> = (props
   const fider = useFider()
   const [showEmailForm, setShowEmailForm] = useState(fider.session.tenant ? fider.session.tenant.isEmailAuthAllowed : true)
   const [email, setEmail] = useState("")
+  const [username, setUsername] = useState("")
+  const [password, setPassword] = useState("")
   const [error, setError] = useState<Failure | undefined>(undefined)
 
   const forceShowEmailForm = (e: React.MouseEvent<HTMLAnchorElement>) => {
@@ -37,7 +40,41 @@ export const SignInControl: React.FunctionComponent<SignInControlProps> = (props
     }
   }
 
+  const signInWithPasskey = async () => {
+    const options = await actions.getPasskeySignInOptions()
+    if (!options.ok) {
+      return
+    }
+
+    let credential
+    try {
+      credential = await webauthn.get(options.data.options)
+    } catch (err) {
+      // user cancelled the browser prompt or no passkey is available
+      return
+    }
+
+    const result = await actions.signInByPasskey(options.data.token, credential)
+    if (result.ok) {
+      location.href = props.redirectTo || "/"
+    } else if (result.error) {
+      setError(result.error)
+    }
+  }
+
+  const signInWithLDAP = async () => {
+    const result = await actions.signInByLDAP(username, password)
+    if (result.ok) {
+      location.href = props.redirectTo || "/"
+    } else if (result.error) {
+      setPassword("")
+      setError(result.error)
+    }
+  }
+
   const providersLen = fider.settings.oauth.length
+  const useLDAP = !!fider.settings.ldap
+  const usePasskey = fider.session.tenant && fider.session.tenant.passkeyMode !== PasskeyMode.Disabled && webauthn.isSupported()
 
   if (!isCookieEnabled()) {
     return (
@@ -50,6 +87,48 @@ export const SignInControl: React.FunctionComponent<SignInControlProps> = (props
 
   return (
     <div className="c-signin-control">
+      {usePasskey && (
+        <>
+          <div className="c-signin-control__passkey mb-2">
+            <Button variant="secondary" className="w-full" onClick={signInWithPasskey}>
+              <Trans id="signin.passkey">Sign in with a passkey</Trans>
+            </Button>
+          </div>
+          {(useLDAP || providersLen > 0 || props.useEmail) && <Divider />}
+        </>
+      )}
+
+      {useLDAP && (
+        <>
+          <div className="c-signin-control__ldap mb-2">
+            <p>
+              <Trans id="signin.message.ldap">Sign in with your {fider.settings.ldap} account</Trans>
+            </p>
+            <Form error={error}>
+              <Input
+                field="username"
+                value={username}
+                autoComplete="username"
+                onChange={setUsername}
+                placeholder={t({ id: "signin.ldap.username.placeholder", message: "Username" })}
+              />
+              <Input
+                field="password"
+                type="password"
+                value={password}
+                autoComplete="current-password"
+                onChange={setPassword}
+                placeholder={t({ id: "signin.ldap.password.placeholder", message: "Password" })}
+              />
+              <Button type="submit" variant="primary" className="w-full" disabled={username === "" || password === ""} onClick={signInWithLDAP}>
+                <Trans id="action.signin">Sign in</Trans>
+              </Button>
+            </Form>
+          </div>
+          {(providersLen > 0 || props.useEmail) && <Divider />}
+        </>
+      )}
+
       {providersLen > 0 && (
         <>
           <div className="c-signin-control__oauth mb-2">
@@ -10,6 +10,7 @@ import { HStack } from "@fider/components/layout"
 interface InputProps {
   field: string
   label?: string
+  type?: "text" | "password"
   className?: string
   autoComplete?: string
   autoFocus?: boolean
@@ -62,7 +63,7 @@ export const Input: React.FunctionComponent<InputProps> = (props) => {
                 "c-input--suffixed": !!suffix,
               })}
               id={`input-${props.field}`}
-              type="text"
+              type={props.type || "text"}
               autoComplete={props.autoComplete}
               tabIndex={props.noTabFocus ? -1 : undefined}
               ref={props.inputRef}
@@ -8,4 +8,5 @@ export * from "./VoteCounter"
 export * from "./NotificationIndicator"
 export * from "./UserMenu"
 export * from "./ReadOnlyNotice"
+export * from "./ImpersonationNotice"
 export * from "./common"
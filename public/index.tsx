@@ -2,7 +2,7 @@ import "@fider/assets/styles/index.scss"
 
 import React, { Suspense } from "react"
 import ReactDOM from "react-dom"
-import { ErrorBoundary, Loader, ReadOnlyNotice, DevBanner } from "@fider/components"
+import { ErrorBoundary, Loader, ReadOnlyNotice, ImpersonationNotice, DevBanner } from "@fider/components"
 import { classSet, Fider, FiderContext, actions, activateI18N } from "@fider/services"
 
 import { I18n } from "@lingui/core"
@@ -50,6 +50,7 @@ const bootstrapApp = (i18n: I18n) => {
           <FiderContext.Provider value={fider}>
             <DevBanner />
             <ReadOnlyNotice />
+            <ImpersonationNotice />
             <Suspense fallback={<Loading />}>{React.createElement(component, fider.session.props)}</Suspense>
           </FiderContext.Provider>
         </I18nProvider>
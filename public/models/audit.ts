@@ -0,0 +1,14 @@
+import { User } from "./identity"
+
+export interface AuditLog {
+  id: number
+  actor?: User
+  impersonated?: User
+  action: string
+  targetType: string
+  targetId: string
+  before?: { [key: string]: any }
+  after?: { [key: string]: any }
+  clientIP: string
+  createdAt: string
+}
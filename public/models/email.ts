@@ -0,0 +1,45 @@
+export interface EmailTemplate {
+  kind: string
+  subject: string
+  body: string
+  updatedAt?: string
+}
+
+export interface EmailTemplateVariable {
+  name: string
+  description: string
+}
+
+export interface EmailTemplateKind {
+  name: string
+  title: string
+  description: string
+  variables: EmailTemplateVariable[]
+}
+
+export interface EmailTemplatePreview {
+  subject: string
+  body: string
+}
+
+export type EmailStatus = "pending" | "sent" | "failed" | "suppressed"
+
+export interface OutboxEmail {
+  id: number
+  templateName: string
+  toName: string
+  toAddress: string
+  status: EmailStatus
+  attempts: number
+  lastError?: string
+  nextAttemptAt: string
+  createdAt: string
+  sentAt?: string
+}
+
+export interface SuppressedEmail {
+  userId: number
+  name: string
+  email: string
+  suppressedAt: string
+}
@@ -10,6 +10,20 @@ export interface Tenant {
   isPrivate: boolean
   logoBlobKey: string
   isEmailAuthAllowed: boolean
+  passkeyMode: PasskeyMode
+}
+
+export enum PasskeyMode {
+  Disabled = "disabled",
+  Optional = "optional",
+  Required = "required",
+}
+
+export interface Passkey {
+  id: number
+  name: string
+  createdAt: string
+  lastUsedAt?: string
 }
 
 export enum TenantStatus {
@@ -50,6 +64,15 @@ export const isCollaborator = (role: UserRole): boolean => {
   return role === UserRole.Collaborator || role === UserRole.Administrator
 }
 
+export interface UserSuspension {
+  id: number
+  userId: number
+  reason: string
+  readOnly: boolean
+  createdAt: string
+  endsAt?: string
+}
+
 export interface CurrentUser {
   id: number
   name: string
@@ -57,8 +80,39 @@ export interface CurrentUser {
   avatarType: UserAvatarType
   avatarBlobKey: string
   avatarURL: string
+  locale: string
   role: UserRole
   status: UserStatus
   isAdministrator: boolean
   isCollaborator: boolean
 }
+
+export interface ImportedUser {
+  row: number
+  name: string
+  email: string
+  role: UserRole | ""
+  externalId: string
+  organization: string
+  errors?: string[]
+}
+
+export interface UserImportPreview {
+  total: number
+  valid: number
+  invalid: number
+  rows: ImportedUser[]
+}
+
+export interface UserImport {
+  id: number
+  status: "running" | "completed" | "failed"
+  total: number
+  processed: number
+  created: number
+  updated: number
+  failed: number
+  errors: { row: number; messages: string[] }[]
+  createdAt: string
+  finishedAt?: string
+}
@@ -4,3 +4,7 @@ export * from "./settings"
 export * from "./billing"
 export * from "./notification"
 export * from "./webhook"
+export * from "./audit"
+export * from "./profile"
+export * from "./report"
+export * from "./email"
@@ -5,3 +5,12 @@ export interface Notification {
   read: boolean
   createdAt: string
 }
+
+export interface SavedSearch {
+  id: number
+  name: string
+  view: string
+  tags: string[]
+  channels: number
+  createdAt: string
+}
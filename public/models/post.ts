@@ -14,6 +14,10 @@ export interface Post {
   votesCount: number
   commentsCount: number
   tags: string[]
+  organizationsCount?: number
+  organizationsRevenue?: number
+  pendingReview?: boolean
+  isHidden?: boolean
 }
 
 export class PostStatus {
@@ -59,6 +63,8 @@ export interface Comment {
   attachments?: string[]
   editedAt?: string
   editedBy?: User
+  pendingReview?: boolean
+  isHidden?: boolean
 }
 
 export interface Tag {
@@ -0,0 +1,24 @@
+import { User } from "./identity"
+import { Post } from "./post"
+
+export interface UserProfile {
+  user: User
+  bio: string
+  link: string
+  isHidden: boolean
+}
+
+export interface UserComment {
+  id: number
+  content: string
+  createdAt: string
+  postNumber: number
+  postTitle: string
+  postSlug: string
+}
+
+export interface UserActivity {
+  posts: Post[]
+  comments: UserComment[]
+  votes: Post[]
+}
@@ -0,0 +1,18 @@
+import { User } from "./identity"
+
+export type ReportReason = "spam" | "abusive" | "off-topic" | "other"
+export type ReportResolution = "dismissed" | "hidden" | "deleted" | "blocked"
+
+export interface ReportedContent {
+  postId: number
+  postNumber: number
+  postTitle: string
+  postSlug: string
+  commentId?: number
+  content: string
+  author: User
+  reportsCount: number
+  reasons: ReportReason[]
+  details: string[]
+  lastReportedAt: string
+}
@@ -20,6 +20,8 @@ export interface SystemSettings {
   baseURL: string
   assetsURL: string
   oauth: OAuthProviderOption[]
+  ldap: string
+  vapidPublicKey: string
 }
 
 export interface UserSettings {
@@ -44,6 +44,7 @@ export const SideMenu = (props: SiteMenuProps) => {
         <SideMenuItem name="privacy" title="Privacy" href="/admin/privacy" isActive={activeItem === "privacy"} />
         <SideMenuItem name="members" title="Members" href="/admin/members" isActive={activeItem === "members"} />
         <SideMenuItem name="tags" title="Tags" href="/admin/tags" isActive={activeItem === "tags"} />
+        <SideMenuItem name="moderation" title="Moderation" href="/admin/moderation" isActive={activeItem === "moderation"} />
         <SideMenuItem name="invitations" title="Invitations" href="/admin/invitations" isActive={activeItem === "invitations"} />
         <SideMenuItem name="authentication" title="Authentication" href="/admin/authentication" isActive={activeItem === "authentication"} />
         <SideMenuItem name="advanced" title="Advanced" href="/admin/advanced" isActive={activeItem === "advanced"} />
@@ -51,6 +52,9 @@ export const SideMenu = (props: SiteMenuProps) => {
           <>
             {fider.settings.isBillingEnabled && <SideMenuItem name="billing" title="Billing" href="/admin/billing" isActive={activeItem === "billing"} />}
             <SideMenuItem name="webhooks" title="Webhooks" href="/admin/webhooks" isActive={activeItem === "webhooks"} />
+            <SideMenuItem name="email-templates" title="Email Templates" href="/admin/email-templates" isActive={activeItem === "email-templates"} />
+            <SideMenuItem name="emails" title="Email Log" href="/admin/emails" isActive={activeItem === "emails"} />
+            <SideMenuItem name="audit" title="Audit Log" href="/admin/audit" isActive={activeItem === "audit"} />
             <SideMenuItem name="export" title="Export" href="/admin/export" isActive={activeItem === "export"} />
           </>
         )}
@@ -0,0 +1,133 @@
+import "./EmailTemplateForm.scss"
+
+import React, { useEffect, useState } from "react"
+import { Button, Field, Form, Input, Loader, TextArea } from "@fider/components"
+import { actions, Failure, notify } from "@fider/services"
+import { HStack, VStack } from "@fider/components/layout"
+import { EmailTemplate, EmailTemplateKind, EmailTemplatePreview } from "@fider/models"
+
+interface EmailTemplateFormProps {
+  kind: EmailTemplateKind
+  template: EmailTemplate
+  isCustomized: boolean
+  onSaved: (template: EmailTemplate) => void
+  onReset: () => void
+}
+
+export const EmailTemplateForm = (props: EmailTemplateFormProps) => {
+  const [subject, setSubject] = useState(props.template.subject)
+  const [body, setBody] = useState(props.template.body)
+  const [typing, setTyping] = useState<NodeJS.Timeout | undefined>()
+  const [preview, setPreview] = useState<EmailTemplatePreview | undefined>()
+  const [previewError, setPreviewError] = useState<Failure | undefined>()
+  const [error, setError] = useState<Failure | undefined>()
+
+  const calculatePreview = () => {
+    actions.previewEmailTemplate(props.kind.name, subject, body).then((result) => {
+      if (result.ok) {
+        setPreview(result.data)
+        setPreviewError(undefined)
+      } else {
+        setPreviewError(result.error)
+      }
+    })
+  }
+
+  useEffect(() => {
+    if (typing) clearTimeout(typing)
+    setPreview(undefined)
+    setTyping(
+      setTimeout(() => {
+        calculatePreview()
+        setTyping(undefined)
+      }, 1_000)
+    )
+  }, [subject, body])
+
+  const handleSave = async () => {
+    const result = await actions.saveEmailTemplate(props.kind.name, subject, body)
+    if (result.ok) {
+      setError(undefined)
+      notify.success("Email template has been saved.")
+      props.onSaved({ kind: props.kind.name, subject, body })
+    } else {
+      setError(result.error)
+    }
+  }
+
+  const handleSendTest = async () => {
+    const result = await actions.sendTestEmailTemplate(props.kind.name, subject, body)
+    if (result.ok) {
+      setError(undefined)
+      notify.success("A test email has been sent to you.")
+    } else {
+      setError(result.error)
+    }
+  }
+
+  const handleReset = async () => {
+    const result = await actions.resetEmailTemplate(props.kind.name)
+    if (result.ok) {
+      notify.success("Email template has been reset to its default.")
+      props.onReset()
+    }
+  }
+
+  const previewErrorMessages = previewError?.errors?.map((e) => e.message) || []
+
+  return (
+    <Form className="c-email-template-form" error={error}>
+      <p className="text-muted">
+        {props.kind.description} {props.isCustomized ? "This email is customized." : "This email uses the default template."}
+      </p>
+      <Input field="subject" label="Subject" value={subject} onChange={setSubject} />
+      <TextArea className="c-email-template-form__body" field="body" label="Body" minRows={12} value={body} onChange={setBody} />
+      <Field label="Variables" className="c-email-template-form__variables">
+        <p className="text-muted">
+          Subject and body are Go templates rendered inside the site email layout. Values are HTML escaped, except the ones described as HTML, which are
+          already safe and can be written as-is. An invalid template is never sent, the default one is used instead.
+        </p>
+        <ul>
+          {props.kind.variables.map((v) => (
+            <li key={v.name}>
+              <code>{`{{ .${v.name} }}`}</code> <span className="text-muted">{v.description}</span>
+            </li>
+          ))}
+        </ul>
+      </Field>
+      <Field label="Preview" className="c-email-template-form__preview">
+        {previewErrorMessages.length > 0 ? (
+          <VStack spacing={1}>
+            {previewErrorMessages.map((message) => (
+              <p key={message} className="text-red-700">
+                {message}
+              </p>
+            ))}
+          </VStack>
+        ) : preview === undefined ? (
+          <Loader className="text-center" text="Loading preview" />
+        ) : (
+          <VStack className="bg-gray-50 rounded-md p-2" spacing={2}>
+            <p>
+              <strong>Subject:</strong> {preview.subject}
+            </p>
+            <iframe title="Preview" sandbox="" srcDoc={preview.body} />
+          </VStack>
+        )}
+      </Field>
+      <HStack>
+        <Button variant="primary" onClick={handleSave}>
+          Save
+        </Button>
+        <Button variant="secondary" onClick={handleSendTest}>
+          Send test
+        </Button>
+        {props.isCustomized && (
+          <Button variant="danger" onClick={handleReset}>
+            Reset to default
+          </Button>
+        )}
+      </HStack>
+    </Form>
+  )
+}
@@ -0,0 +1,128 @@
+import React, { useState } from "react"
+import { Avatar, Button, Moment, Select, SelectOption, UserName } from "@fider/components"
+import { AuditLog } from "@fider/models"
+import { actions, Fider } from "@fider/services"
+import { AdminPageContainer } from "../components/AdminBasePage"
+import { HStack, VStack } from "@fider/components/layout"
+
+interface AuditLogPageProps {
+  logs: AuditLog[]
+}
+
+const pageSize = 50
+
+const actionOptions: SelectOption[] = [
+  { value: "", label: "All actions" },
+  { value: "user.role_changed", label: "User role changed" },
+  { value: "user.blocked", label: "User blocked" },
+  { value: "user.unblocked", label: "User unblocked" },
+  { value: "user.suspended", label: "User suspended" },
+  { value: "user.suspension_lifted", label: "User suspension lifted" },
+  { value: "user.profile_hidden", label: "User profile hidden" },
+  { value: "user.profile_shown", label: "User profile shown" },
+  { value: "user.erased", label: "User erased" },
+  { value: "user.exported", label: "User data exported" },
+  { value: "user.merged", label: "User merged" },
+  { value: "users.imported", label: "Users imported" },
+  { value: "impersonation.started", label: "Impersonation started" },
+  { value: "impersonation.stopped", label: "Impersonation stopped" },
+  { value: "impersonation.request", label: "Change made while impersonating" },
+  { value: "post.deleted", label: "Post deleted" },
+  { value: "reports.resolved", label: "Reports resolved" },
+  { value: "tag.created", label: "Tag created" },
+  { value: "tag.updated", label: "Tag updated" },
+  { value: "tag.deleted", label: "Tag deleted" },
+  { value: "webhook.created", label: "Webhook created" },
+  { value: "webhook.updated", label: "Webhook updated" },
+  { value: "webhook.deleted", label: "Webhook deleted" },
+  { value: "email_template.updated", label: "Email template updated" },
+  { value: "email_template.reset", label: "Email template reset" },
+  { value: "email.resent", label: "Email resent" },
+  { value: "email.suppression_cleared", label: "Email suppression cleared" },
+  { value: "oauth.saved", label: "OAuth provider saved" },
+  { value: "settings.updated", label: "Settings updated" },
+]
+
+const actionLabel = (action: string): string => {
+  const option = actionOptions.find((o) => o.value === action)
+  return option ? option.label : action
+}
+
+const formatValues = (values?: { [key: string]: any }): string => {
+  if (!values) {
+    return ""
+  }
+  return Object.keys(values)
+    .map((key) => `${key}: ${JSON.stringify(values[key])}`)
+    .join(", ")
+}
+
+const AuditLogItem = (props: { log: AuditLog }) => {
+  const log = props.log
+  return (
+    <HStack spacing={4} center={false}>
+      {log.actor ? <Avatar user={log.actor} /> : <span />}
+      <VStack spacing={1}>
+        <span>
+          {log.actor ? <UserName user={log.actor} /> : <span className="text-muted">System</span>}
+          {log.impersonated && (
+            <span className="text-muted">
+              {" "}
+              as <UserName user={log.impersonated} />
+            </span>
+          )}{" "}
+          · <strong>{actionLabel(log.action)}</strong> ·{" "}
+          <span className="text-muted">
+            {log.targetType} #{log.targetId}
+          </span>
+        </span>
+        {log.before && <span className="text-muted text-sm">Before: {formatValues(log.before)}</span>}
+        {log.after && <span className="text-muted text-sm">After: {formatValues(log.after)}</span>}
+        <span className="text-muted text-sm">
+          <Moment locale={Fider.currentLocale} date={log.createdAt} format="full" />
+          {log.clientIP && <> · {log.clientIP}</>}
+        </span>
+      </VStack>
+    </HStack>
+  )
+}
+
+const AuditLogPage = (props: AuditLogPageProps) => {
+  const [logs, setLogs] = useState(props.logs)
+  const [action, setAction] = useState("")
+  const [hasMore, setHasMore] = useState(props.logs.length === pageSize)
+
+  const search = async (newAction: string, offset: number) => {
+    const result = await actions.searchAuditLogs({ action: newAction || undefined, offset: offset || undefined })
+    if (result.ok) {
+      setLogs(offset === 0 ? result.data : logs.concat(result.data))
+      setHasMore(result.data.length === pageSize)
+    }
+  }
+
+  const changeAction = (opt?: SelectOption) => {
+    const newAction = opt ? opt.value : ""
+    setAction(newAction)
+    search(newAction, 0)
+  }
+
+  const showMore = () => search(action, logs.length)
+
+  return (
+    <AdminPageContainer id="p-admin-audit" name="audit" title="Audit Log" subtitle="Review privileged actions performed on your site">
+      <VStack spacing={8}>
+        <Select field="action" defaultValue={action} options={actionOptions} onChange={changeAction} />
+        <VStack spacing={4} divide>
+          {logs.length === 0 ? <p className="text-muted">There aren’t any matching entries on the audit log.</p> : logs.map((l) => <AuditLogItem key={l.id} log={l} />)}
+        </VStack>
+        {hasMore && (
+          <Button variant="tertiary" onClick={showMore}>
+            Show more
+          </Button>
+        )}
+      </VStack>
+    </AdminPageContainer>
+  )
+}
+
+export default AuditLogPage
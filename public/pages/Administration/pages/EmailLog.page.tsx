@@ -0,0 +1,176 @@
+import React, { useState } from "react"
+import { Button, Moment, Select, SelectOption } from "@fider/components"
+import { EmailStatus, OutboxEmail, SuppressedEmail } from "@fider/models"
+import { actions, Fider, notify } from "@fider/services"
+import { AdminPageContainer } from "../components/AdminBasePage"
+import { HStack, VStack } from "@fider/components/layout"
+
+interface EmailLogPageProps {
+  emails: OutboxEmail[]
+  suppressions: SuppressedEmail[]
+}
+
+const pageSize = 50
+
+const statusOptions: SelectOption[] = [
+  { value: "", label: "All emails" },
+  { value: "pending", label: "Pending" },
+  { value: "sent", label: "Sent" },
+  { value: "failed", label: "Failed" },
+  { value: "suppressed", label: "Suppressed" },
+]
+
+const statusClassName: { [key in EmailStatus]: string } = {
+  pending: "text-muted",
+  sent: "text-green-700",
+  failed: "text-red-700",
+  suppressed: "text-yellow-700",
+}
+
+const statusLabel = (status: EmailStatus): string => {
+  const option = statusOptions.find((o) => o.value === status)
+  return option ? option.label : status
+}
+
+const EmailLogItem = (props: { email: OutboxEmail; onResent: (email: OutboxEmail) => void }) => {
+  const email = props.email
+
+  const resend = async () => {
+    const result = await actions.resendEmail(email.id)
+    if (result.ok) {
+      notify.success("The email will be sent again shortly.")
+      props.onResent(email)
+    }
+  }
+
+  return (
+    <HStack justify="between" center={false}>
+      <VStack spacing={1}>
+        <span>
+          <strong className={statusClassName[email.status]}>{statusLabel(email.status)}</strong> · {email.templateName} ·{" "}
+          <span className="text-muted">{email.toName ? `${email.toName} <${email.toAddress}>` : email.toAddress}</span>
+        </span>
+        {email.lastError && <span className="text-muted text-sm">Last error: {email.lastError}</span>}
+        <span className="text-muted text-sm">
+          <Moment locale={Fider.currentLocale} date={email.createdAt} format="full" />
+          {email.attempts > 0 && <> · {email.attempts} attempt(s)</>}
+          {email.sentAt && (
+            <>
+              {" "}
+              · sent <Moment locale={Fider.currentLocale} date={email.sentAt} format="relative" />
+            </>
+          )}
+          {email.status === "pending" && email.attempts > 0 && (
+            <>
+              {" "}
+              · next attempt <Moment locale={Fider.currentLocale} date={email.nextAttemptAt} format="relative" />
+            </>
+          )}
+        </span>
+      </VStack>
+      {email.status === "failed" && (
+        <Button variant="secondary" size="small" onClick={resend}>
+          Resend
+        </Button>
+      )}
+    </HStack>
+  )
+}
+
+const SuppressedEmailItem = (props: { suppression: SuppressedEmail; onCleared: (suppression: SuppressedEmail) => void }) => {
+  const suppression = props.suppression
+
+  const clear = async () => {
+    const result = await actions.clearEmailSuppression(suppression.userId)
+    if (result.ok) {
+      notify.success("Emails will be sent to this address again.")
+      props.onCleared(suppression)
+    }
+  }
+
+  return (
+    <HStack justify="between" center={false}>
+      <VStack spacing={1}>
+        <span>
+          {suppression.name} · <span className="text-muted">{suppression.email}</span>
+        </span>
+        <span className="text-muted text-sm">
+          Suppressed <Moment locale={Fider.currentLocale} date={suppression.suppressedAt} format="relative" />
+        </span>
+      </VStack>
+      <Button variant="secondary" size="small" onClick={clear}>
+        Clear
+      </Button>
+    </HStack>
+  )
+}
+
+const EmailLogPage = (props: EmailLogPageProps) => {
+  const [emails, setEmails] = useState(props.emails)
+  const [status, setStatus] = useState("")
+  const [hasMore, setHasMore] = useState(props.emails.length === pageSize)
+  const [suppressions, setSuppressions] = useState(props.suppressions)
+
+  const search = async (newStatus: string, offset: number) => {
+    const result = await actions.searchEmailLog(newStatus || undefined, offset || undefined)
+    if (result.ok) {
+      setEmails(offset === 0 ? result.data : emails.concat(result.data))
+      setHasMore(result.data.length === pageSize)
+    }
+  }
+
+  const changeStatus = (opt?: SelectOption) => {
+    const newStatus = opt ? opt.value : ""
+    setStatus(newStatus)
+    search(newStatus, 0)
+  }
+
+  const showMore = () => search(status, emails.length)
+
+  const onResent = (email: OutboxEmail) => {
+    const resent: OutboxEmail = { ...email, status: "pending", attempts: 0, lastError: undefined }
+    setEmails(emails.map((e) => (e.id === email.id ? resent : e)))
+  }
+
+  const onCleared = (suppression: SuppressedEmail) => {
+    setSuppressions(suppressions.filter((s) => s.userId !== suppression.userId))
+  }
+
+  return (
+    <AdminPageContainer id="p-admin-emails" name="emails" title="Email Log" subtitle="Inspect the delivery of emails sent by your site">
+      <VStack spacing={8}>
+        <p className="text-muted">
+          Emails are sent in the background and retried a few times when they fail. Failed emails can be sent again once the problem is solved.
+        </p>
+        <Select field="status" defaultValue={status} options={statusOptions} onChange={changeStatus} />
+        <VStack spacing={4} divide>
+          {emails.length === 0 ? (
+            <p className="text-muted">There aren’t any matching emails.</p>
+          ) : (
+            emails.map((e) => <EmailLogItem key={e.id} email={e} onResent={onResent} />)
+          )}
+        </VStack>
+        {hasMore && (
+          <Button variant="tertiary" onClick={showMore}>
+            Show more
+          </Button>
+        )}
+        <VStack spacing={4}>
+          <h2 className="text-display">Suppressed addresses</h2>
+          <p className="text-muted">
+            Emails are no longer sent to addresses that bounced or were reported as spam. Clear a suppression once the address works again.
+          </p>
+          <VStack spacing={4} divide>
+            {suppressions.length === 0 ? (
+              <p className="text-muted">There aren’t any suppressed addresses.</p>
+            ) : (
+              suppressions.map((s) => <SuppressedEmailItem key={s.userId} suppression={s} onCleared={onCleared} />)
+            )}
+          </VStack>
+        </VStack>
+      </VStack>
+    </AdminPageContainer>
+  )
+}
+
+export default EmailLogPage
@@ -0,0 +1,216 @@
+import React, { useEffect, useState } from "react"
+
+import { Button, Checkbox, Field, Form, Input, TextArea } from "@fider/components"
+import { UserImport, UserImportPreview } from "@fider/models"
+import { actions, Failure, Fider } from "@fider/services"
+import { AdminPageContainer } from "../components/AdminBasePage"
+import { VStack } from "@fider/components/layout"
+
+const progressInterval = 2000
+
+const defaultMessage = () => `Hi,
+
+An account has been created for you on the ${Fider.session.tenant.name} feedback site, a place where you can vote, discuss and share your ideas and thoughts on how to improve our services!
+
+Click the link below to join!
+
+%invite%
+
+Regards,
+${Fider.session.user.name} (${Fider.session.tenant.name})`
+
+const ImportProgress = (props: { userImport: UserImport }) => {
+  const { userImport } = props
+
+  return (
+    <VStack spacing={2}>
+      {userImport.status === "running" && (
+        <p>
+          Importing users... <strong>{userImport.processed}</strong> of <strong>{userImport.total}</strong> rows processed.
+        </p>
+      )}
+      {userImport.status === "completed" && <p>The import has finished.</p>}
+      {userImport.status === "failed" && <p className="text-red-700">The import has stopped because of an unexpected error and no users were changed.</p>}
+      <p className="text-muted">
+        {userImport.created} created · {userImport.updated} updated · {userImport.failed} failed
+      </p>
+      {userImport.errors.length > 0 && (
+        <ul className="text-muted">
+          {userImport.errors.map((error) => (
+            <li key={error.row}>
+              Line {error.row}: {error.messages.join(" ")}
+            </li>
+          ))}
+        </ul>
+      )}
+    </VStack>
+  )
+}
+
+const ImportPreview = (props: { preview: UserImportPreview }) => {
+  const { preview } = props
+
+  return (
+    <VStack spacing={2}>
+      <p>
+        <strong>{preview.valid}</strong> of <strong>{preview.total}</strong> rows are ready to be imported.
+        {preview.invalid > 0 && <> Rows with errors are skipped during the import.</>}
+      </p>
+      <table className="w-full text-sm">
+        <thead>
+          <tr className="text-left">
+            <th>Line</th>
+            <th>Name</th>
+            <th>Email</th>
+            <th>Role</th>
+            <th>External ID</th>
+            <th>Organization</th>
+          </tr>
+        </thead>
+        <tbody>
+          {preview.rows.map((row) => (
+            <React.Fragment key={row.row}>
+              <tr>
+                <td>{row.row}</td>
+                <td>{row.name}</td>
+                <td>{row.email}</td>
+                <td>{row.role}</td>
+                <td>{row.externalId}</td>
+                <td>{row.organization}</td>
+              </tr>
+              {row.errors && row.errors.length > 0 && (
+                <tr>
+                  <td />
+                  <td colSpan={5} className="text-red-700">
+                    {row.errors.join(" ")}
+                  </td>
+                </tr>
+              )}
+            </React.Fragment>
+          ))}
+        </tbody>
+      </table>
+    </VStack>
+  )
+}
+
+const ImportUsersPage = () => {
+  const [csv, setCSV] = useState("")
+  const [sendInvites, setSendInvites] = useState(false)
+  const [subject, setSubject] = useState(`[${Fider.session.tenant.name}] Your account is ready!`)
+  const [message, setMessage] = useState(defaultMessage())
+  const [preview, setPreview] = useState<UserImportPreview | undefined>()
+  const [userImport, setUserImport] = useState<UserImport | undefined>()
+  const [error, setError] = useState<Failure | undefined>()
+
+  useEffect(() => {
+    if (!userImport || userImport.status !== "running") {
+      return
+    }
+
+    const timer = window.setTimeout(async () => {
+      const result = await actions.getUserImport(userImport.id)
+      if (result.ok) {
+        setUserImport(result.data)
+      }
+    }, progressInterval)
+    return () => window.clearTimeout(timer)
+  }, [userImport])
+
+  const changeCSV = (value: string) => {
+    setCSV(value)
+    setPreview(undefined)
+  }
+
+  const selectFile = (e: React.ChangeEvent<HTMLInputElement>) => {
+    const file = e.currentTarget.files && e.currentTarget.files[0]
+    if (!file) {
+      return
+    }
+
+    const reader = new FileReader()
+    reader.onload = () => changeCSV(reader.result as string)
+    reader.readAsText(file)
+  }
+
+  const runPreview = async () => {
+    const result = await actions.previewUserImport({ csv, sendInvites, subject, message })
+    if (result.ok) {
+      setPreview(result.data)
+    }
+    setError(result.error)
+  }
+
+  const runImport = async () => {
+    const result = await actions.importUsers({ csv, sendInvites, subject, message })
+    if (result.ok) {
+      setUserImport(result.data)
+      setPreview(undefined)
+      setCSV("")
+    }
+    setError(result.error)
+  }
+
+  return (
+    <AdminPageContainer id="p-admin-import-users" name="members" title="Import Users" subtitle="Create or update users in bulk from a CSV file">
+      <Form error={error}>
+        {userImport && (
+          <Field label="Progress">
+            <ImportProgress userImport={userImport} />
+          </Field>
+        )}
+
+        <Field label="CSV file">
+          <input type="file" accept=".csv,text/csv" onChange={selectFile} />
+        </Field>
+
+        <TextArea field="csv" minRows={6} value={csv} onChange={changeCSV} placeholder="name,email,role,external_id,organization">
+          <div className="text-muted">
+            <p>
+              The first line must contain the column names. Supported columns are <strong>name</strong>, <strong>email</strong>, <strong>role</strong>,{" "}
+              <strong>external_id</strong> and <strong>organization</strong>. Each row needs a name and either an email or an external ID.
+            </p>
+            <p>
+              Existing users are matched by external ID and then by email. Their role is only changed when the <strong>role</strong> column has a value.
+            </p>
+          </div>
+        </TextArea>
+
+        <Checkbox field="sendInvites" checked={sendInvites} onChange={setSendInvites}>
+          Send an invitation email to each new user
+        </Checkbox>
+
+        {sendInvites && (
+          <>
+            <Input field="subject" label="Subject" value={subject} maxLength={70} onChange={setSubject} />
+            <TextArea field="message" label="Message" minRows={8} value={message} onChange={setMessage}>
+              <p className="text-muted">
+                The message must include the invitation link placeholder named <strong>%invite%</strong>.
+              </p>
+            </TextArea>
+          </>
+        )}
+
+        <Field label="Preview">
+          <p className="text-muted">Check the file for problems before importing it. Nothing is changed until you start the import.</p>
+          <Button onClick={runPreview} disabled={!csv}>
+            Preview import
+          </Button>
+        </Field>
+
+        {preview && (
+          <>
+            <ImportPreview preview={preview} />
+            <Field label="Confirmation">
+              <Button variant="primary" onClick={runImport} disabled={preview.valid === 0}>
+                Import {preview.valid} {preview.valid === 1 ? "user" : "users"}
+              </Button>
+            </Field>
+          </>
+        )}
+      </Form>
+    </AdminPageContainer>
+  )
+}
+
+export default ImportUsersPage
@@ -1,7 +1,7 @@
 import React from "react"
 
-import { Button, OAuthProviderLogo, Icon, Field, Toggle, Form } from "@fider/components"
-import { OAuthConfig, OAuthProviderOption } from "@fider/models"
+import { Button, OAuthProviderLogo, Icon, Field, Toggle, Form, Select, SelectOption } from "@fider/components"
+import { OAuthConfig, OAuthProviderOption, PasskeyMode } from "@fider/models"
 import { OAuthForm } from "../components/OAuthForm"
 import { actions, notify, Fider, Failure } from "@fider/services"
 import { AdminBasePage } from "../components/AdminBasePage"
@@ -13,11 +13,14 @@ import { HStack, VStack } from "@fider/components/layout"
 
 interface ManageAuthenticationPageProps {
   providers: OAuthProviderOption[]
+  ssoSecret: string
 }
 
 interface ManageAuthenticationPageState {
   isAdding: boolean
   isEmailAuthAllowed: boolean
+  passkeyMode: PasskeyMode
+  ssoSecret: string
   canDisableEmailAuth: boolean
   editing?: OAuthConfig
   error?: Failure
@@ -34,6 +37,8 @@ export default class ManageAuthenticationPage extends AdminBasePage<ManageAuthen
     this.state = {
       isAdding: false,
       isEmailAuthAllowed: Fider.session.tenant.isEmailAuthAllowed,
+      passkeyMode: Fider.session.tenant.passkeyMode,
+      ssoSecret: props.ssoSecret,
       canDisableEmailAuth: props.providers.map((o) => o.isEnabled).reduce((a, b) => a || b, false),
     }
   }
@@ -82,6 +87,37 @@ export default class ManageAuthenticationPage extends AdminBasePage<ManageAuthen
     )
   }
 
+  private changePasskeyMode = async (opt?: SelectOption) => {
+    if (!opt) {
+      return
+    }
+
+    const passkeyMode = opt.value as PasskeyMode
+    const response = await actions.updateTenantPasskeyMode(passkeyMode)
+    if (response.ok) {
+      this.setState({ passkeyMode })
+      notify.success(`You successfully changed passkey setting.`)
+    } else {
+      this.setState({ error: response.error }, () => notify.error("Unable to save this setting."))
+    }
+  }
+
+  private regenerateSSOSecret = async () => {
+    const response = await actions.regenerateSSOSecret()
+    if (response.ok) {
+      this.setState({ ssoSecret: response.data.secret })
+      notify.success("A new single sign-on secret has been generated.")
+    }
+  }
+
+  private disableSSO = async () => {
+    const response = await actions.disableSSO()
+    if (response.ok) {
+      this.setState({ ssoSecret: "" })
+      notify.success("Single sign-on has been disabled.")
+    }
+  }
+
   public content() {
     let enabledProvidersCount = 0
     for (const o of this.props.providers) {
@@ -124,8 +160,59 @@ export default class ManageAuthenticationPage extends AdminBasePage<ManageAuthen
               </p>
               <p className="text-muted mt-1">Note: Administrator accounts will still be allowed to sign in using their email.</p>
             </Field>
+            {Fider.session.user.isAdministrator && (
+              <Select
+                label="Passkeys"
+                field="passkeyMode"
+                defaultValue={this.state.passkeyMode}
+                options={[
+                  { value: PasskeyMode.Disabled, label: "Disabled" },
+                  { value: PasskeyMode.Optional, label: "Optional" },
+                  { value: PasskeyMode.Required, label: "Required" },
+                ]}
+                onChange={this.changePasskeyMode}
+              >
+                <p className="text-muted mt-1">
+                  Passkeys let users sign in with their fingerprint, face or device PIN. When required, users who have registered a passkey can no longer
+                  sign in by email.
+                </p>
+                <p className="text-muted mt-1">Note: Administrator accounts will still be allowed to sign in using their email.</p>
+              </Select>
+            )}
           </Form>
         </div>
+        {Fider.session.user.isAdministrator && (
+          <div>
+            <h2 className="text-display">Single Sign-On</h2>
+            <p>
+              Sign users in from your own application without a second login. Your backend signs a JWT with the HS256 algorithm using the secret below, and
+              redirects users to <strong>{Fider.settings.baseURL}/sso?token=TOKEN</strong>. An optional <strong>redirect</strong> parameter sets the page
+              users land on.
+            </p>
+            <p className="text-muted">
+              The token must have <strong>iat</strong> and <strong>exp</strong> claims at most 5 minutes apart, a unique <strong>jti</strong>, a{" "}
+              <strong>name</strong> and either an <strong>email</strong> or an <strong>external_id</strong>. Each token can only be used once. Users can
+              also be assigned to an <strong>organization</strong>, matched by <strong>organization_id</strong> when given. Regenerating the secret
+              invalidates all tokens signed with the previous one.
+            </p>
+            <div className="text-xs block my-1">{this.state.ssoSecret ? enabled : disabled}</div>
+            {this.state.ssoSecret && (
+              <p className="text-muted">
+                <strong>Secret:</strong> <code>{this.state.ssoSecret}</code>
+              </p>
+            )}
+            <HStack>
+              <Button variant="secondary" onClick={this.regenerateSSOSecret}>
+                {this.state.ssoSecret ? "Regenerate secret" : "Enable"}
+              </Button>
+              {this.state.ssoSecret && (
+                <Button variant="danger" onClick={this.disableSSO}>
+                  Disable
+                </Button>
+              )}
+            </HStack>
+          </div>
+        )}
         <div>
           <h2 className="text-display">OAuth Providers</h2>
           <p>
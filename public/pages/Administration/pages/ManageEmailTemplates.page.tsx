@@ -0,0 +1,59 @@
+import React, { useState } from "react"
+import { Select, SelectOption } from "@fider/components"
+import { EmailTemplate, EmailTemplateKind } from "@fider/models"
+import { AdminPageContainer } from "../components/AdminBasePage"
+import { EmailTemplateForm } from "../components/email/EmailTemplateForm"
+import { VStack } from "@fider/components/layout"
+
+interface ManageEmailTemplatesPageProps {
+  kinds: EmailTemplateKind[]
+  defaults: { [kind: string]: EmailTemplate }
+  templates: EmailTemplate[]
+}
+
+const ManageEmailTemplatesPage = (props: ManageEmailTemplatesPageProps) => {
+  const [kindName, setKindName] = useState(props.kinds[0].name)
+  const [templates, setTemplates] = useState(props.templates)
+  const [version, setVersion] = useState(0)
+
+  const kind = props.kinds.find((k) => k.name === kindName) || props.kinds[0]
+  const custom = templates.find((t) => t.kind === kind.name)
+
+  const changeKind = (option?: SelectOption) => {
+    if (option) {
+      setKindName(option.value)
+    }
+  }
+
+  const handleSaved = (template: EmailTemplate) => {
+    setTemplates(templates.filter((t) => t.kind !== template.kind).concat(template))
+  }
+
+  const handleReset = () => {
+    setTemplates(templates.filter((t) => t.kind !== kind.name))
+    setVersion(version + 1)
+  }
+
+  const options = props.kinds.map((k) => ({
+    value: k.name,
+    label: templates.some((t) => t.kind === k.name) ? `${k.title} (customized)` : k.title,
+  }))
+
+  return (
+    <AdminPageContainer id="p-admin-email-templates" name="email-templates" title="Email Templates" subtitle="Customize the emails sent by your site">
+      <VStack spacing={4}>
+        <Select field="kind" label="Email" defaultValue={kind.name} options={options} onChange={changeKind} />
+        <EmailTemplateForm
+          key={`${kind.name}-${version}`}
+          kind={kind}
+          template={custom || props.defaults[kind.name]}
+          isCustomized={!!custom}
+          onSaved={handleSaved}
+          onReset={handleReset}
+        />
+      </VStack>
+    </AdminPageContainer>
+  )
+}
+
+export default ManageEmailTemplatesPage
@@ -1,33 +1,67 @@
 import React from "react"
-import { Input, Avatar, UserName, Icon, Dropdown, Button } from "@fider/components"
-import { User, UserRole, UserStatus } from "@fider/models"
+import { Input, Avatar, UserName, Icon, Dropdown, Button, Modal, Checkbox, Form, Select, SelectOption, TextArea, Moment } from "@fider/components"
+import { User, UserRole, UserStatus, UserSuspension } from "@fider/models"
 import { AdminBasePage } from "../components/AdminBasePage"
 import IconSearch from "@fider/assets/images/heroicons-search.svg"
 import IconX from "@fider/assets/images/heroicons-x.svg"
 import IconDotsHorizontal from "@fider/assets/images/heroicons-dots-horizontal.svg"
-import { actions, Fider } from "@fider/services"
+import { actions, Fider, Failure, navigator } from "@fider/services"
 import { HStack, VStack } from "@fider/components/layout"
 
 interface ManageMembersPageState {
   query: string
   users: User[]
   visibleUsers: User[]
+  erasing?: User
+  deleteContent: boolean
+  merging?: User
+  mergeTargetID?: number
+  mergeError?: Failure
+  suspensions: UserSuspension[]
+  suspending?: User
+  suspendReason: string
+  suspendDays: number
+  suspendReadOnly: boolean
+  suspendError?: Failure
 }
 
 interface ManageMembersPageProps {
   users: User[]
+  suspensions: UserSuspension[]
 }
 
 interface UserListItemProps {
   user: User
+  suspension?: UserSuspension
   onAction: (actionName: string, user: User) => Promise<void>
 }
 
+const suspensionDaysOptions: SelectOption[] = [
+  { value: "1", label: "1 day" },
+  { value: "3", label: "3 days" },
+  { value: "7", label: "7 days" },
+  { value: "30", label: "30 days" },
+  { value: "0", label: "Until lifted" },
+]
+
 const UserListItem = (props: UserListItemProps) => {
   const admin = props.user.role === UserRole.Administrator && <span>administrator</span>
   const collaborator = props.user.role === UserRole.Collaborator && <span>collaborator</span>
   const blocked = props.user.status === UserStatus.Blocked && <span className="text-red-700">blocked</span>
   const isVisitor = props.user.role === UserRole.Visitor
+  const suspension = props.suspension
+  const suspended = suspension && (
+    <span className="text-red-700">
+      {suspension.readOnly ? "read-only" : "suspended"}
+      {suspension.endsAt && (
+        <>
+          {" "}
+          until <Moment locale={Fider.currentLocale} date={suspension.endsAt} format="full" />
+        </>
+      )}
+    </span>
+  )
+  const isAdministrator = Fider.session.user.isAdministrator
 
   const actionSelected = (actionName: string) => () => {
     props.onAction(actionName, props.user)
@@ -40,19 +74,27 @@ const UserListItem = (props: UserListItemProps) => {
         <VStack spacing={0}>
           <UserName user={props.user} />
           <span className="text-muted">
-            {admin} {collaborator} {blocked}
+            {admin} {collaborator} {blocked} {suspended}
           </span>
         </VStack>
       </HStack>
-      {Fider.session.user.id !== props.user.id && Fider.session.user.isAdministrator && (
+      {Fider.session.user.id !== props.user.id && (
         <Dropdown renderHandle={<Icon sprite={IconDotsHorizontal} width="16" height="16" />}>
-          {!blocked && (!!collaborator || isVisitor) && (
+          {isAdministrator && !blocked && (!!collaborator || isVisitor) && (
             <Dropdown.ListItem onClick={actionSelected("to-administrator")}>Promote to Administrator</Dropdown.ListItem>
           )}
-          {!blocked && (!!admin || isVisitor) && <Dropdown.ListItem onClick={actionSelected("to-collaborator")}>Promote to Collaborator</Dropdown.ListItem>}
-          {!blocked && (!!collaborator || !!admin) && <Dropdown.ListItem onClick={actionSelected("to-visitor")}>Demote to Visitor</Dropdown.ListItem>}
-          {isVisitor && !blocked && <Dropdown.ListItem onClick={actionSelected("block")}>Block User</Dropdown.ListItem>}
-          {isVisitor && !!blocked && <Dropdown.ListItem onClick={actionSelected("unblock")}>Unblock User</Dropdown.ListItem>}
+          {isAdministrator && !blocked && (!!admin || isVisitor) && (
+            <Dropdown.ListItem onClick={actionSelected("to-collaborator")}>Promote to Collaborator</Dropdown.ListItem>
+          )}
+          {isAdministrator && !blocked && (!!collaborator || !!admin) && <Dropdown.ListItem onClick={actionSelected("to-visitor")}>Demote to Visitor</Dropdown.ListItem>}
+          {isVisitor && !blocked && !suspension && <Dropdown.ListItem onClick={actionSelected("suspend")}>Suspend User</Dropdown.ListItem>}
+          {isVisitor && !blocked && !!suspension && <Dropdown.ListItem onClick={actionSelected("lift-suspension")}>Lift Suspension</Dropdown.ListItem>}
+          {isAdministrator && isVisitor && !blocked && <Dropdown.ListItem onClick={actionSelected("block")}>Block User</Dropdown.ListItem>}
+          {isAdministrator && isVisitor && !!blocked && <Dropdown.ListItem onClick={actionSelected("unblock")}>Unblock User</Dropdown.ListItem>}
+          {isAdministrator && <Dropdown.ListItem href={`/admin/members/${props.user.id}/export.zip`}>Export Data</Dropdown.ListItem>}
+          {isAdministrator && !admin && !blocked && <Dropdown.ListItem onClick={actionSelected("impersonate")}>Impersonate User</Dropdown.ListItem>}
+          {isAdministrator && !admin && <Dropdown.ListItem onClick={actionSelected("merge")}>Merge Into Another User</Dropdown.ListItem>}
+          {isAdministrator && !admin && <Dropdown.ListItem onClick={actionSelected("erase")}>Erase User</Dropdown.ListItem>}
         </Dropdown>
       )}
     </HStack>
@@ -74,6 +116,11 @@ export default class ManageMembersPage extends AdminBasePage<ManageMembersPagePr
       query: "",
       users,
       visibleUsers: users.slice(0, 10),
+      deleteContent: false,
+      suspensions: this.props.suspensions || [],
+      suspendReason: "",
+      suspendDays: 7,
+      suspendReadOnly: false,
     }
   }
 
@@ -124,9 +171,206 @@ export default class ManageMembersPage extends AdminBasePage<ManageMembersPagePr
       await changeStatus(UserStatus.Blocked)
     } else if (actionName === "unblock") {
       await changeStatus(UserStatus.Active)
+    } else if (actionName === "suspend") {
+      this.setState({ suspending: user, suspendReason: "", suspendDays: 7, suspendReadOnly: false, suspendError: undefined })
+    } else if (actionName === "lift-suspension") {
+      const result = await actions.liftUserSuspension(user.id)
+      if (result.ok) {
+        this.setState({ suspensions: this.state.suspensions.filter((x) => x.userId !== user.id) })
+      }
+    } else if (actionName === "impersonate") {
+      const result = await actions.impersonateUser(user.id)
+      if (result.ok) {
+        navigator.goHome()
+      }
+    } else if (actionName === "merge") {
+      this.setState({ merging: user, mergeTargetID: undefined, mergeError: undefined })
+    } else if (actionName === "erase") {
+      this.setState({ erasing: user, deleteContent: false })
+    }
+  }
+
+  private closeMergeModal = () => {
+    this.setState({ merging: undefined })
+  }
+
+  private setMergeTarget = (option?: SelectOption) => {
+    this.setState({ mergeTargetID: option ? parseInt(option.value, 10) : undefined })
+  }
+
+  private confirmMerge = async () => {
+    const user = this.state.merging
+    if (!user || !this.state.mergeTargetID) {
+      return
+    }
+
+    const result = await actions.mergeUsers(user.id, this.state.mergeTargetID)
+    if (result.ok) {
+      this.props.users.splice(this.props.users.indexOf(user), 1)
+      this.setState({ merging: undefined })
+      this.handleSearchFilterChanged(this.state.query)
+    } else {
+      this.setState({ mergeError: result.error })
+    }
+  }
+
+  private renderMergeModal() {
+    const source = this.state.merging
+    const options: SelectOption[] = [{ value: "", label: "Select a user..." }].concat(
+      this.props.users
+        .filter((x) => source && x.id !== source.id)
+        .map((x) => ({ value: x.id.toString(), label: x.email ? `${x.name} (${x.email})` : x.name }))
+    )
+
+    return (
+      <Modal.Window isOpen={!!source} center={false} onClose={this.closeMergeModal}>
+        <Modal.Header>Merge user</Modal.Header>
+        <Modal.Content>
+          <p>
+            Posts, comments, votes, subscriptions, notifications and sign in methods of <strong>{source?.name}</strong> will be moved to the selected user.{" "}
+            <strong>{source?.name}</strong> will then be deleted.
+          </p>
+          <Form error={this.state.mergeError}>
+            <Select field="targetUserId" label="Merge into" options={options} onChange={this.setMergeTarget} />
+          </Form>
+          <p>
+            This process is irreversible. <strong>Are you sure?</strong>
+          </p>
+        </Modal.Content>
+        <Modal.Footer>
+          <Button variant="danger" size="small" onClick={this.confirmMerge} disabled={!this.state.mergeTargetID}>
+            Merge
+          </Button>
+          <Button variant="tertiary" size="small" onClick={this.closeMergeModal}>
+            Cancel
+          </Button>
+        </Modal.Footer>
+      </Modal.Window>
+    )
+  }
+
+  private closeSuspendModal = () => {
+    this.setState({ suspending: undefined })
+  }
+
+  private setSuspendReason = (suspendReason: string) => {
+    this.setState({ suspendReason })
+  }
+
+  private setSuspendDays = (option?: SelectOption) => {
+    this.setState({ suspendDays: option ? parseInt(option.value, 10) : 0 })
+  }
+
+  private setSuspendReadOnly = (suspendReadOnly: boolean) => {
+    this.setState({ suspendReadOnly })
+  }
+
+  private confirmSuspend = async () => {
+    const user = this.state.suspending
+    if (!user) {
+      return
+    }
+
+    const { suspendReason, suspendDays, suspendReadOnly } = this.state
+    const result = await actions.suspendUser(user.id, suspendReason, suspendDays, suspendReadOnly)
+    if (result.ok) {
+      const endsAt = suspendDays > 0 ? new Date(Date.now() + suspendDays * 24 * 60 * 60 * 1000).toISOString() : undefined
+      const suspension: UserSuspension = {
+        id: 0,
+        userId: user.id,
+        reason: suspendReason,
+        readOnly: suspendReadOnly,
+        createdAt: new Date().toISOString(),
+        endsAt,
+      }
+      this.setState({
+        suspending: undefined,
+        suspensions: this.state.suspensions.filter((x) => x.userId !== user.id).concat(suspension),
+      })
+    } else {
+      this.setState({ suspendError: result.error })
+    }
+  }
+
+  private renderSuspendModal() {
+    return (
+      <Modal.Window isOpen={!!this.state.suspending} center={false} onClose={this.closeSuspendModal}>
+        <Modal.Header>Suspend user</Modal.Header>
+        <Modal.Content>
+          <p>
+            <strong>{this.state.suspending?.name}</strong> will be unable to post, comment and vote until the suspension ends or is lifted. They will see the
+            reason when they try to do so.
+          </p>
+          <Form error={this.state.suspendError}>
+            <TextArea field="reason" label="Reason" value={this.state.suspendReason} onChange={this.setSuspendReason} />
+            <Select field="days" label="Duration" defaultValue={this.state.suspendDays.toString()} options={suspensionDaysOptions} onChange={this.setSuspendDays} />
+            <Checkbox field="readOnly" checked={this.state.suspendReadOnly} onChange={this.setSuspendReadOnly}>
+              Read-only: still allow this user to vote
+            </Checkbox>
+          </Form>
+        </Modal.Content>
+        <Modal.Footer>
+          <Button variant="danger" size="small" onClick={this.confirmSuspend}>
+            Suspend
+          </Button>
+          <Button variant="tertiary" size="small" onClick={this.closeSuspendModal}>
+            Cancel
+          </Button>
+        </Modal.Footer>
+      </Modal.Window>
+    )
+  }
+
+  private closeEraseModal = () => {
+    this.setState({ erasing: undefined })
+  }
+
+  private setDeleteContent = (deleteContent: boolean) => {
+    this.setState({ deleteContent })
+  }
+
+  private confirmErase = async () => {
+    const user = this.state.erasing
+    if (!user) {
+      return
+    }
+
+    const result = await actions.eraseUser(user.id, this.state.deleteContent)
+    if (result.ok) {
+      this.props.users.splice(this.props.users.indexOf(user), 1)
+      this.setState({ erasing: undefined })
+      this.handleSearchFilterChanged(this.state.query)
     }
   }
 
+  private renderEraseModal() {
+    return (
+      <Modal.Window isOpen={!!this.state.erasing} center={false} onClose={this.closeEraseModal}>
+        <Modal.Header>Erase user</Modal.Header>
+        <Modal.Content>
+          <p>
+            All personal information of <strong>{this.state.erasing?.name}</strong> will be erased forever. By default, the content they have published will
+            remain, but it will be anonymised.
+          </p>
+          <Checkbox field="deleteContent" checked={this.state.deleteContent} onChange={this.setDeleteContent}>
+            Also delete all posts and comments published by this user
+          </Checkbox>
+          <p>
+            This process is irreversible. <strong>Are you sure?</strong>
+          </p>
+        </Modal.Content>
+        <Modal.Footer>
+          <Button variant="danger" size="small" onClick={this.confirmErase}>
+            Erase
+          </Button>
+          <Button variant="tertiary" size="small" onClick={this.closeEraseModal}>
+            Cancel
+          </Button>
+        </Modal.Footer>
+      </Modal.Window>
+    )
+  }
+
   private sortByStaff = (left: User, right: User) => {
     if (right.role === left.role) {
       if (left.name < right.name) {
@@ -146,6 +390,9 @@ export default class ManageMembersPage extends AdminBasePage<ManageMembersPagePr
   public content() {
     return (
       <>
+        {this.renderMergeModal()}
+        {this.renderEraseModal()}
+        {this.renderSuspendModal()}
         <Input
           field="query"
           icon={this.state.query ? IconX : IconSearch}
@@ -157,7 +404,7 @@ export default class ManageMembersPage extends AdminBasePage<ManageMembersPagePr
         <div className="p-2">
           <VStack spacing={2} divide={true}>
             {this.state.visibleUsers.map((user) => (
-              <UserListItem key={user.id} user={user} onAction={this.handleAction} />
+              <UserListItem key={user.id} user={user} suspension={this.state.suspensions.find((x) => x.userId === user.id)} onAction={this.handleAction} />
             ))}
           </VStack>
         </div>
@@ -188,7 +435,22 @@ export default class ManageMembersPage extends AdminBasePage<ManageMembersPagePr
           <li>
             <strong>Blocked</strong> users are unable to log into this site.
           </li>
+          <li>
+            <strong>Suspended</strong> users are unable to post, comment and vote until the suspension ends. <strong>Read-only</strong> users can still vote.
+          </li>
+          <li>
+            <strong>Erased</strong> users have all their personal information removed. Administrators must be demoted before they can be erased.
+          </li>
         </ul>
+        {Fider.session.user.isAdministrator && (
+          <p className="text-muted">
+            Need to add many users at once?{" "}
+            <a className="text-link" href="/admin/import-users">
+              Import them from a CSV file
+            </a>
+            .
+          </p>
+        )}
       </>
     )
   }
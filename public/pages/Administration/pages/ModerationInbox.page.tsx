@@ -0,0 +1,105 @@
+import React, { useEffect, useState } from "react"
+import { Avatar, Button, Markdown, Moment, UserName } from "@fider/components"
+import { isCollaborator, ReportedContent, ReportReason, ReportResolution } from "@fider/models"
+import { actions, Fider } from "@fider/services"
+import { useFider } from "@fider/hooks"
+import { AdminPageContainer } from "../components/AdminBasePage"
+import { HStack, VStack } from "@fider/components/layout"
+
+const reasonLabels: { [key in ReportReason]: string } = {
+  spam: "Spam",
+  abusive: "Abusive",
+  "off-topic": "Off-topic",
+  other: "Other",
+}
+
+interface ReportedContentItemProps {
+  report: ReportedContent
+  onResolved: (report: ReportedContent) => void
+}
+
+const ReportedContentItem = (props: ReportedContentItemProps) => {
+  const fider = useFider()
+  const report = props.report
+  const link = `/posts/${report.postNumber}/${report.postSlug}`
+
+  const resolve = (resolution: ReportResolution) => async () => {
+    const result = await actions.resolveContentReports(report.postNumber, report.commentId, resolution)
+    if (result.ok) {
+      props.onResolved(report)
+    }
+  }
+
+  return (
+    <HStack spacing={4} center={false}>
+      <Avatar user={report.author} />
+      <VStack spacing={2} className="flex-grow">
+        <span>
+          <UserName user={report.author} /> · {report.commentId ? "comment on " : "post "}
+          <a className="text-link" href={link}>
+            {report.postTitle}
+          </a>
+        </span>
+        <div className="bg-gray-50 rounded-md p-2">
+          <Markdown text={report.content} style="plainText" />
+        </div>
+        <span className="text-muted text-sm">
+          <strong>
+            {report.reportsCount} {report.reportsCount === 1 ? "report" : "reports"}
+          </strong>{" "}
+          · {report.reasons.map((r) => reasonLabels[r] || r).join(", ")} · last on <Moment locale={Fider.currentLocale} date={report.lastReportedAt} format="full" />
+        </span>
+        {report.details.length > 0 && (
+          <ul className="text-muted text-sm">
+            {report.details.map((d, i) => (
+              <li key={i}>“{d}”</li>
+            ))}
+          </ul>
+        )}
+        <HStack>
+          <Button size="small" onClick={resolve("dismissed")} disabled={fider.isReadOnly}>
+            Dismiss
+          </Button>
+          <Button size="small" onClick={resolve("hidden")} disabled={fider.isReadOnly}>
+            Hide
+          </Button>
+          <Button size="small" variant="danger" onClick={resolve("deleted")} disabled={fider.isReadOnly}>
+            Delete
+          </Button>
+          {fider.session.user.isAdministrator && !isCollaborator(report.author.role) && (
+            <Button size="small" variant="danger" onClick={resolve("blocked")} disabled={fider.isReadOnly}>
+              Block author
+            </Button>
+          )}
+        </HStack>
+      </VStack>
+    </HStack>
+  )
+}
+
+const ModerationInboxPage = () => {
+  const [reports, setReports] = useState<ReportedContent[]>()
+
+  useEffect(() => {
+    actions.getContentReports().then((result) => {
+      if (result.ok) {
+        setReports(result.data)
+      }
+    })
+  }, [])
+
+  const removeReport = (report: ReportedContent) => {
+    setReports((reports || []).filter((r) => r !== report))
+  }
+
+  return (
+    <AdminPageContainer id="p-admin-moderation" name="moderation" title="Moderation" subtitle="Review posts and comments reported by your users">
+      <VStack spacing={4} divide>
+        {reports && reports.length === 0 && <p className="text-muted">There aren’t any reports waiting for a review.</p>}
+        {reports && reports.map((r) => <ReportedContentItem key={`${r.postId}-${r.commentId || 0}`} report={r} onResolved={removeReport} />)}
+      </VStack>
+    </AdminPageContainer>
+  )
+}
+
+export default ModerationInboxPage
@@ -1,23 +1,34 @@
 import React from "react"
-import { Toggle, Form, Field } from "@fider/components"
-import { actions, notify, Fider } from "@fider/services"
+import { Toggle, Form, Field, TextArea, Select, SelectOption, Button } from "@fider/components"
+import { actions, notify, Fider, Failure } from "@fider/services"
+import { UserRole } from "@fider/models"
 import { AdminBasePage } from "@fider/pages/Administration/components/AdminBasePage"
 
+interface PrivacySettingsPageProps {
+  allowedEmailDomains: string[]
+  autoJoinRole: UserRole
+}
+
 interface PrivacySettingsPageState {
   isPrivate: boolean
+  allowedEmailDomains: string
+  autoJoinRole: UserRole
+  error?: Failure
 }
 
-export default class PrivacySettingsPage extends AdminBasePage<any, PrivacySettingsPageState> {
+export default class PrivacySettingsPage extends AdminBasePage<PrivacySettingsPageProps, PrivacySettingsPageState> {
   public id = "p-admin-privacy"
   public name = "privacy"
   public title = "Privacy"
   public subtitle = "Manage your site privacy"
 
-  constructor(props: any) {
+  constructor(props: PrivacySettingsPageProps) {
     super(props)
 
     this.state = {
       isPrivate: Fider.session.tenant.isPrivate,
+      allowedEmailDomains: (this.props.allowedEmailDomains || []).join("\n"),
+      autoJoinRole: this.props.autoJoinRole || UserRole.Visitor,
     }
   }
 
@@ -35,9 +46,30 @@ export default class PrivacySettingsPage extends AdminBasePage<any, PrivacySetti
     )
   }
 
+  private setAllowedEmailDomains = (allowedEmailDomains: string): void => {
+    this.setState({ allowedEmailDomains })
+  }
+
+  private setAutoJoinRole = (opt?: SelectOption): void => {
+    if (opt) {
+      this.setState({ autoJoinRole: opt.value as UserRole })
+    }
+  }
+
+  private saveEmailDomains = async (): Promise<void> => {
+    const domains = this.state.allowedEmailDomains.split(/[\s,;]+/).filter((x) => !!x)
+    const response = await actions.updateTenantEmailDomains(domains, this.state.autoJoinRole)
+    if (response.ok) {
+      this.setState({ error: undefined })
+      notify.success("Your privacy settings have been saved.")
+    } else {
+      this.setState({ error: response.error })
+    }
+  }
+
   public content() {
     return (
-      <Form>
+      <Form error={this.state.error}>
         <Field label="Private Site">
           <Toggle disabled={!Fider.session.user.isAdministrator} active={this.state.isPrivate} onToggle={this.toggle} />
           <p className="text-muted mt-1">
@@ -45,6 +77,41 @@ export default class PrivacySettingsPage extends AdminBasePage<any, PrivacySetti
             invited users and users from trusted OAuth providers will have access to this site.
           </p>
         </Field>
+
+        <TextArea
+          field="allowedEmailDomains"
+          label="Trusted Email Domains"
+          disabled={!Fider.session.user.isAdministrator}
+          minRows={3}
+          value={this.state.allowedEmailDomains}
+          onChange={this.setAllowedEmailDomains}
+        >
+          <p className="text-muted">
+            One domain per line, for example <strong>yourcompany.com</strong>. On a private site, anyone with a verified email address from these domains
+            can join without an invitation. When set, new users from any other domain are rejected, even when using a trusted OAuth provider.
+          </p>
+        </TextArea>
+
+        <Select
+          label="Default Role"
+          field="autoJoinRole"
+          defaultValue={this.state.autoJoinRole}
+          options={[
+            { value: UserRole.Visitor, label: "Visitor" },
+            { value: UserRole.Collaborator, label: "Collaborator" },
+          ]}
+          onChange={this.setAutoJoinRole}
+        >
+          <p className="text-muted mt-1">The role given to users who join through one of the trusted email domains.</p>
+        </Select>
+
+        {Fider.session.user.isAdministrator && (
+          <div className="field">
+            <Button variant="primary" onClick={this.saveEmailDomains}>
+              Save
+            </Button>
+          </div>
+        )}
       </Form>
     )
   }
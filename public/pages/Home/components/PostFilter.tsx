@@ -37,6 +37,11 @@ export const PostFilter = (props: PostFilterProps) => {
     options.push({ value: "my-votes", label: t({ id: "home.postfilter.option.myvotes", message: "My Votes" }) })
   }
 
+  if (fider.session.isAuthenticated && fider.session.user.isCollaborator) {
+    options.push({ value: "most-valuable", label: t({ id: "home.postfilter.option.mostvaluable", message: "Most Valuable" }) })
+    options.push({ value: "pending-review", label: t({ id: "home.postfilter.option.pendingreview", message: "Pending Review" }) })
+  }
+
   PostStatus.All.filter((s) => s.filterable && props.countPerStatus[s.value]).forEach((s) => {
     const id = `enum.poststatus.${s.value.toString()}`
     options.push({
@@ -3,8 +3,8 @@ import "./PostsContainer.scss"
 import React from "react"
 
 import { Post, Tag, CurrentUser } from "@fider/models"
-import { Loader, Input } from "@fider/components"
-import { actions, navigator, querystring } from "@fider/services"
+import { Loader, Input, Button } from "@fider/components"
+import { actions, navigator, querystring, notify } from "@fider/services"
 import IconSearch from "@fider/assets/images/heroicons-search.svg"
 import IconX from "@fider/assets/images/heroicons-x.svg"
 import { PostFilter } from "./PostFilter"
@@ -26,8 +26,12 @@ interface PostsContainerState {
   tags: string[]
   query: string
   limit?: number
+  followed: boolean
 }
 
+// Views that depend on who is looking at them can't be followed
+const followableViews = ["", "trending", "recent", "most-wanted", "most-discussed", "planned", "started", "completed", "declined", "all"]
+
 export class PostsContainer extends React.Component<PostsContainerProps, PostsContainerState> {
   constructor(props: PostsContainerProps) {
     super(props)
@@ -39,10 +43,12 @@ export class PostsContainer extends React.Component<PostsContainerProps, PostsCo
       query: querystring.get("query"),
       tags: querystring.getArray("tags"),
       limit: querystring.getNumber("limit"),
+      followed: false,
     }
   }
 
   private changeFilterCriteria<K extends keyof PostsContainerState>(obj: Pick<PostsContainerState, K>, reset: boolean): void {
+    this.setState({ followed: false })
     this.setState(obj, () => {
       const query = this.state.query.trim().toLowerCase()
       navigator.replaceState(
@@ -87,6 +93,25 @@ export class PostsContainer extends React.Component<PostsContainerProps, PostsCo
     this.changeFilterCriteria({ query: "" }, true)
   }
 
+  private followSearch = async () => {
+    const view = this.state.view || "trending"
+    const result = await actions.addSavedSearch({
+      name: [view, ...this.state.tags].join(", "),
+      view,
+      tags: this.state.tags,
+      channels: 3,
+    })
+    if (result.ok) {
+      this.setState({ followed: true })
+      notify.success(<Trans id="home.postscontainer.follow.success">You will be notified about posts matching this search.</Trans>)
+    }
+  }
+
+  private canFollow = (): boolean => {
+    const hasFilter = !!this.state.view || this.state.tags.length > 0
+    return !!this.props.user && !this.state.query && hasFilter && followableViews.includes(this.state.view || "")
+  }
+
   private showMore = (event: React.MouseEvent<HTMLElement> | React.TouchEvent<HTMLElement>): void => {
     event.preventDefault()
     this.changeFilterCriteria({ limit: (this.state.limit || 30) + 10 }, false)
@@ -108,6 +133,15 @@ export class PostsContainer extends React.Component<PostsContainerProps, PostsCo
             <div className="c-posts-container__filter-col">
               <PostFilter activeView={this.state.view} viewChanged={this.handleViewChanged} countPerStatus={this.props.countPerStatus} />
               {this.props.tags.length > 0 && <TagsFilter tags={this.props.tags} selectionChanged={this.handleTagsFilterChanged} selected={this.state.tags} />}
+              {this.canFollow() && (
+                <Button size="small" variant="tertiary" disabled={this.state.followed} onClick={this.followSearch}>
+                  {this.state.followed ? (
+                    <Trans id="home.postscontainer.follow.following">Following</Trans>
+                  ) : (
+                    <Trans id="home.postscontainer.follow.action">Follow this search</Trans>
+                  )}
+                </Button>
+              )}
             </div>
           )}
           <div className="c-posts-container__search-col">
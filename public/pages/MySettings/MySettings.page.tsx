@@ -1,17 +1,24 @@
 import React from "react"
 
-import { Modal, Form, Button, PageTitle, Input, Select, SelectOption, ImageUploader, Header } from "@fider/components"
+import { Modal, Form, Button, PageTitle, Input, TextArea, Select, SelectOption, ImageUploader, Header } from "@fider/components"
 
-import { UserSettings, UserAvatarType, ImageUpload } from "@fider/models"
+import { UserSettings, UserAvatarType, ImageUpload, Passkey, PasskeyMode, UserProfile, SavedSearch } from "@fider/models"
 import { Failure, actions, Fider } from "@fider/services"
 import { NotificationSettings } from "./components/NotificationSettings"
 import { APIKeyForm } from "./components/APIKeyForm"
 import { DangerZone } from "./components/DangerZone"
+import { ExportData } from "./components/ExportData"
+import { PasskeySettings } from "./components/PasskeySettings"
+import { SavedSearchSettings } from "./components/SavedSearchSettings"
 import { t, Trans } from "@lingui/macro"
+import locales from "@locale/locales"
 
 interface MySettingsPageState {
   showModal: boolean
   name: string
+  bio: string
+  link: string
+  locale: string
   newEmail: string
   avatar?: ImageUpload
   avatarType: UserAvatarType
@@ -22,6 +29,9 @@ interface MySettingsPageState {
 
 interface MySettingsPageProps {
   userSettings: UserSettings
+  passkeys: Passkey[]
+  profile: UserProfile
+  savedSearches: SavedSearch[]
 }
 
 export default class MySettingsPage extends React.Component<MySettingsPageProps, MySettingsPageState> {
@@ -33,6 +43,9 @@ export default class MySettingsPage extends React.Component<MySettingsPageProps,
       avatarType: Fider.session.user.avatarType,
       newEmail: "",
       name: Fider.session.user.name,
+      bio: this.props.profile.bio,
+      link: this.props.profile.link,
+      locale: Fider.session.user.locale,
       userSettings: this.props.userSettings,
     }
   }
@@ -43,6 +56,9 @@ export default class MySettingsPage extends React.Component<MySettingsPageProps,
       avatarType: this.state.avatarType,
       avatar: this.state.avatar,
       settings: this.state.userSettings,
+      bio: this.state.bio,
+      link: this.state.link,
+      locale: this.state.locale,
     })
     if (result.ok) {
       location.reload()
@@ -86,6 +102,18 @@ export default class MySettingsPage extends React.Component<MySettingsPageProps,
     this.setState({ name })
   }
 
+  private setBio = (bio: string) => {
+    this.setState({ bio })
+  }
+
+  private setLink = (link: string) => {
+    this.setState({ link })
+  }
+
+  private localeChanged = (opt?: SelectOption) => {
+    this.setState({ locale: opt ? opt.value : "" })
+  }
+
   private setNotificationSettings = (userSettings: UserSettings) => {
     this.setState({ userSettings })
   }
@@ -208,6 +236,35 @@ export default class MySettingsPage extends React.Component<MySettingsPageProps,
                 )}
               </Select>
 
+              <TextArea label={t({ id: "label.bio", message: "Bio" })} field="bio" value={this.state.bio} minRows={2} onChange={this.setBio}>
+                <p className="text-muted">
+                  <Trans id="mysettings.message.bio">
+                    Shown on your{" "}
+                    <a className="text-link" href={`/users/${Fider.session.user.id}`}>
+                      public profile
+                    </a>{" "}
+                    along with your posts, comments and votes.
+                  </Trans>
+                </p>
+              </TextArea>
+
+              <Input label={t({ id: "label.link", message: "Link" })} field="link" value={this.state.link} maxLength={300} placeholder="https://" onChange={this.setLink} />
+
+              <Select
+                label={t({ id: "label.language", message: "Language" })}
+                field="locale"
+                defaultValue={this.state.locale}
+                options={[
+                  { label: t({ id: "mysettings.locale.default", message: "Same as this site" }), value: "" },
+                  ...Object.entries(locales).map(([k, v]) => ({ label: v.text, value: k })),
+                ]}
+                onChange={this.localeChanged}
+              >
+                <p className="text-muted">
+                  <Trans id="mysettings.locale.notice">Used on this site and for the emails and notifications you receive.</Trans>
+                </p>
+              </Select>
+
               <NotificationSettings userSettings={this.props.userSettings} settingsChanged={this.setNotificationSettings} />
 
               <Button variant="primary" onClick={this.confirm}>
@@ -215,7 +272,18 @@ export default class MySettingsPage extends React.Component<MySettingsPageProps,
               </Button>
             </Form>
 
+            <div className="mt-8">
+              <SavedSearchSettings savedSearches={this.props.savedSearches} />
+            </div>
+            {Fider.session.tenant.passkeyMode !== PasskeyMode.Disabled && (
+              <div className="mt-8">
+                <PasskeySettings passkeys={this.props.passkeys} />
+              </div>
+            )}
             <div className="mt-8">{Fider.session.user.isCollaborator && <APIKeyForm />}</div>
+            <div className="mt-8">
+              <ExportData />
+            </div>
             <div className="mt-8">
               <DangerZone />
             </div>
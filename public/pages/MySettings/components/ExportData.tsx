@@ -0,0 +1,21 @@
+import React from "react"
+import { Button } from "@fider/components"
+import { Trans } from "@lingui/macro"
+
+export const ExportData = () => {
+  return (
+    <div>
+      <h4 className="text-title mb-1">
+        <Trans id="mysettings.exportdata.title">Download my data</Trans>
+      </h4>
+      <p className="text-muted">
+        <Trans id="mysettings.exportdata.text">
+          Get a copy of everything tied to your account, including your profile, posts, comments, votes, subscriptions, notifications and settings.
+        </Trans>
+      </p>
+      <Button size="small" href="/settings/export.zip">
+        <Trans id="mysettings.exportdata.download">Download</Trans>
+      </Button>
+    </div>
+  )
+}
@@ -1,8 +1,9 @@
-import React, { useState } from "react"
+import React, { useEffect, useState } from "react"
 
 import { UserSettings } from "@fider/models"
-import { Toggle, Field } from "@fider/components"
+import { Toggle, Field, Select, SelectOption, Button } from "@fider/components"
 import { useFider } from "@fider/hooks"
+import { push } from "@fider/services"
 import { HStack, VStack } from "@fider/components/layout"
 import { t, Trans } from "@lingui/macro"
 
@@ -14,10 +15,19 @@ interface NotificationSettingsProps {
 type Channel = number
 const WebChannel: Channel = 1
 const EmailChannel: Channel = 2
+const PushChannel: Channel = 4
+
+const events = ["event_notification_new_post", "event_notification_new_comment", "event_notification_change_status"]
 
 export const NotificationSettings = (props: NotificationSettingsProps) => {
   const fider = useFider()
   const [userSettings, setUserSettings] = useState(props.userSettings)
+  const [isPushSubscribed, setIsPushSubscribed] = useState(false)
+  const isPushSupported = push.isSupported()
+
+  useEffect(() => {
+    push.isSubscribed().then(setIsPushSubscribed)
+  }, [])
 
   const isEnabled = (settingsKey: string, channel: Channel): boolean => {
     if (settingsKey in userSettings) {
@@ -27,6 +37,13 @@ export const NotificationSettings = (props: NotificationSettingsProps) => {
   }
 
   const toggle = async (settingsKey: string, channel: Channel) => {
+    if (channel === PushChannel && !isEnabled(settingsKey, channel) && !isPushSubscribed) {
+      if (!(await push.subscribe())) {
+        return
+      }
+      setIsPushSubscribed(true)
+    }
+
     const nextSettings = {
       ...userSettings,
       [settingsKey]: (parseInt(userSettings[settingsKey], 10) ^ channel).toString(),
@@ -35,12 +52,33 @@ export const NotificationSettings = (props: NotificationSettingsProps) => {
     props.settingsChanged(nextSettings)
   }
 
+  const subscribeThisDevice = async () => {
+    setIsPushSubscribed(await push.subscribe())
+  }
+
+  const changeEmailDelivery = (option?: SelectOption) => {
+    const nextSettings = {
+      ...userSettings,
+      email_delivery: option ? option.value : "immediate",
+    }
+    setUserSettings(nextSettings)
+    props.settingsChanged(nextSettings)
+  }
+
+  const emailDeliveryOptions: SelectOption[] = [
+    { value: "immediate", label: t({ id: "mysettings.notification.delivery.immediate", message: "Send each email immediately" }) },
+    { value: "daily", label: t({ id: "mysettings.notification.delivery.daily", message: "Send a daily digest" }) },
+    { value: "weekly", label: t({ id: "mysettings.notification.delivery.weekly", message: "Send a weekly digest" }) },
+  ]
+
   const labelWeb = t({ id: "mysettings.notification.channelweb", message: "Web" })
   const labelEmail = t({ id: "mysettings.notification.channelemail", message: "Email" })
+  const labelPush = t({ id: "mysettings.notification.channelpush", message: "Push" })
+  const labels: { [channel: number]: string } = { [WebChannel]: labelWeb, [EmailChannel]: labelEmail, [PushChannel]: labelPush }
 
   const icon = (settingsKey: string, channel: Channel) => {
     const active = isEnabled(settingsKey, channel)
-    const label = channel === WebChannel ? labelWeb : labelEmail
+    const label = labels[channel]
     const onToggle = () => toggle(settingsKey, channel)
     return <Toggle key={`${settingsKey}_${channel}`} active={active} label={label} onToggle={onToggle} />
   }
@@ -49,8 +87,17 @@ export const NotificationSettings = (props: NotificationSettingsProps) => {
     const about = fider.session.user.isCollaborator ? aboutForCollaborators : aboutForVisitors
     const webEnabled = isEnabled(settingsKey, WebChannel)
     const emailEnabled = isEnabled(settingsKey, EmailChannel)
+    const pushEnabled = isEnabled(settingsKey, PushChannel)
 
-    if (!webEnabled && !emailEnabled) {
+    if (pushEnabled && !webEnabled && !emailEnabled) {
+      return (
+        <p className="text-muted">
+          <Trans id="mysettings.notification.message.pushonly">
+            You&apos;ll receive <strong>push</strong> notifications about {about}.
+          </Trans>
+        </p>
+      )
+    } else if (!webEnabled && !emailEnabled) {
       return (
         <p className="text-muted">
           <Trans id="mysettings.notification.message.none">
@@ -107,6 +154,7 @@ export const NotificationSettings = (props: NotificationSettingsProps) => {
               <HStack spacing={6}>
                 {icon("event_notification_new_post", WebChannel)}
                 {icon("event_notification_new_post", EmailChannel)}
+                {isPushSupported && icon("event_notification_new_post", PushChannel)}
               </HStack>
             </div>
             <div>
@@ -121,6 +169,7 @@ export const NotificationSettings = (props: NotificationSettingsProps) => {
               <HStack spacing={6}>
                 {icon("event_notification_new_comment", WebChannel)}
                 {icon("event_notification_new_comment", EmailChannel)}
+                {isPushSupported && icon("event_notification_new_comment", PushChannel)}
               </HStack>
             </div>
             <div>
@@ -135,11 +184,27 @@ export const NotificationSettings = (props: NotificationSettingsProps) => {
               <HStack spacing={6}>
                 {icon("event_notification_change_status", WebChannel)}
                 {icon("event_notification_change_status", EmailChannel)}
+                {isPushSupported && icon("event_notification_change_status", PushChannel)}
               </HStack>
             </div>
           </VStack>
         </div>
+        {isPushSupported && !isPushSubscribed && events.some((key) => isEnabled(key, PushChannel)) && (
+          <p className="text-muted mt-2">
+            <Trans id="mysettings.notification.push.thisdevice">This device doesn&apos;t receive push notifications yet.</Trans>{" "}
+            <Button variant="tertiary" size="small" onClick={subscribeThisDevice}>
+              <Trans id="mysettings.notification.push.enable">Enable on this device</Trans>
+            </Button>
+          </p>
+        )}
       </Field>
+      <Select
+        field="emailDelivery"
+        label={t({ id: "mysettings.notification.delivery", message: "Email delivery" })}
+        defaultValue={userSettings.email_delivery || "immediate"}
+        options={emailDeliveryOptions}
+        onChange={changeEmailDelivery}
+      />
     </>
   )
 }
@@ -0,0 +1,91 @@
+import React, { useState } from "react"
+import { Button, Form, Input, Moment } from "@fider/components"
+import { HStack, VStack } from "@fider/components/layout"
+import { Passkey } from "@fider/models"
+import { actions, webauthn, notify, Failure, Fider } from "@fider/services"
+import { t, Trans } from "@lingui/macro"
+
+interface PasskeySettingsProps {
+  passkeys: Passkey[]
+}
+
+export const PasskeySettings = (props: PasskeySettingsProps) => {
+  const [passkeys, setPasskeys] = useState(props.passkeys || [])
+  const [name, setName] = useState("")
+  const [error, setError] = useState<Failure | undefined>(undefined)
+
+  const register = async () => {
+    const options = await actions.getPasskeyRegistrationOptions()
+    if (!options.ok) {
+      return
+    }
+
+    let credential
+    try {
+      credential = await webauthn.create(options.data.options)
+    } catch (err) {
+      // user cancelled the browser prompt
+      return
+    }
+
+    const result = await actions.registerPasskey(name, options.data.token, credential)
+    if (result.ok) {
+      setPasskeys([...passkeys, result.data])
+      setName("")
+      setError(undefined)
+    } else if (result.error) {
+      setError(result.error)
+    }
+  }
+
+  const remove = async (passkey: Passkey) => {
+    const result = await actions.deletePasskey(passkey.id)
+    if (result.ok) {
+      setPasskeys(passkeys.filter((x) => x.id !== passkey.id))
+    } else {
+      notify.error("Failed to remove passkey. Try again later")
+    }
+  }
+
+  return (
+    <div>
+      <h4 className="text-title mb-1">
+        <Trans id="mysettings.passkeys.title">Passkeys</Trans>
+      </h4>
+      <p className="text-muted">
+        <Trans id="mysettings.passkeys.notice">Passkeys let you sign in with your fingerprint, face or device PIN instead of an email link.</Trans>
+      </p>
+      <VStack spacing={2} className="mb-2">
+        {passkeys.map((p) => (
+          <HStack key={p.id} justify="between">
+            <span>
+              <strong>{p.name}</strong>{" "}
+              <span className="text-muted">
+                <Moment locale={Fider.currentLocale} date={p.createdAt} />
+              </span>
+            </span>
+            <Button size="small" variant="tertiary" onClick={() => remove(p)}>
+              <Trans id="action.delete">Delete</Trans>
+            </Button>
+          </HStack>
+        ))}
+      </VStack>
+      {webauthn.isSupported() && (
+        <Form error={error}>
+          <Input
+            field="name"
+            value={name}
+            maxLength={50}
+            onChange={setName}
+            placeholder={t({ id: "mysettings.passkeys.name.placeholder", message: "e.g. My Phone" })}
+            suffix={
+              <Button size="small" disabled={name === ""} onClick={register}>
+                <Trans id="mysettings.passkeys.add">Add passkey</Trans>
+              </Button>
+            }
+          />
+        </Form>
+      )}
+    </div>
+  )
+}
@@ -0,0 +1,74 @@
+import React, { useState } from "react"
+import { Button, Toggle } from "@fider/components"
+import { HStack, VStack } from "@fider/components/layout"
+import { SavedSearch } from "@fider/models"
+import { actions, notify } from "@fider/services"
+import { t, Trans } from "@lingui/macro"
+
+interface SavedSearchSettingsProps {
+  savedSearches: SavedSearch[]
+}
+
+const WebChannel = 1
+const EmailChannel = 2
+const PushChannel = 4
+
+export const SavedSearchSettings = (props: SavedSearchSettingsProps) => {
+  const [savedSearches, setSavedSearches] = useState(props.savedSearches || [])
+
+  const toggle = async (search: SavedSearch, channel: number) => {
+    const channels = search.channels ^ channel
+    if (channels === 0) {
+      return
+    }
+
+    const result = await actions.updateSavedSearch(search.id, channels)
+    if (result.ok) {
+      setSavedSearches(savedSearches.map((x) => (x.id === search.id ? { ...x, channels } : x)))
+    }
+  }
+
+  const remove = async (search: SavedSearch) => {
+    const result = await actions.deleteSavedSearch(search.id)
+    if (result.ok) {
+      setSavedSearches(savedSearches.filter((x) => x.id !== search.id))
+    } else {
+      notify.error("Failed to remove saved search. Try again later")
+    }
+  }
+
+  const labelWeb = t({ id: "mysettings.notification.channelweb", message: "Web" })
+  const labelEmail = t({ id: "mysettings.notification.channelemail", message: "Email" })
+  const labelPush = t({ id: "mysettings.notification.channelpush", message: "Push" })
+
+  return (
+    <div>
+      <h4 className="text-title mb-1">
+        <Trans id="mysettings.savedsearches.title">Followed searches</Trans>
+      </h4>
+      <p className="text-muted">
+        <Trans id="mysettings.savedsearches.notice">You are notified when posts matching these searches are created or change status.</Trans>
+      </p>
+      {savedSearches.length === 0 && (
+        <p className="text-muted">
+          <Trans id="mysettings.savedsearches.empty">Use &quot;Follow this search&quot; on the home page to follow a view or tags.</Trans>
+        </p>
+      )}
+      <VStack spacing={2}>
+        {savedSearches.map((s) => (
+          <HStack key={s.id} justify="between">
+            <strong>{s.name}</strong>
+            <HStack spacing={6}>
+              <Toggle active={(s.channels & WebChannel) > 0} label={labelWeb} onToggle={() => toggle(s, WebChannel)} />
+              <Toggle active={(s.channels & EmailChannel) > 0} label={labelEmail} onToggle={() => toggle(s, EmailChannel)} />
+              <Toggle active={(s.channels & PushChannel) > 0} label={labelPush} onToggle={() => toggle(s, PushChannel)} />
+              <Button size="small" variant="tertiary" onClick={() => remove(s)}>
+                <Trans id="action.delete">Delete</Trans>
+              </Button>
+            </HStack>
+          </HStack>
+        ))}
+      </VStack>
+    </div>
+  )
+}
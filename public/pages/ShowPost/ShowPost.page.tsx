@@ -3,7 +3,7 @@ import "./ShowPost.page.scss"
 import React from "react"
 
 import { Comment, Post, Tag, Vote, ImageUpload, CurrentUser } from "@fider/models"
-import { actions, Failure, Fider, timeAgo } from "@fider/services"
+import { actions, Failure, Fider, realtime, timeAgo } from "@fider/services"
 
 import {
   VoteCounter,
@@ -25,6 +25,7 @@ import { ResponseForm } from "./components/ResponseForm"
 import { TagsPanel } from "./components/TagsPanel"
 import { NotificationsPanel } from "./components/NotificationsPanel"
 import { ModerationPanel } from "./components/ModerationPanel"
+import { ReportPanel } from "./components/ReportPanel"
 import { DiscussionPanel } from "./components/DiscussionPanel"
 import { VotesPanel } from "./components/VotesPanel"
 
@@ -44,6 +45,8 @@ interface ShowPostPageProps {
 }
 
 interface ShowPostPageState {
+  post: Post
+  comments: Comment[]
   editMode: boolean
   newTitle: string
   attachments: ImageUpload[]
@@ -51,6 +54,10 @@ interface ShowPostPageState {
   error?: Failure
 }
 
+interface PostEventData {
+  postNumber: number
+}
+
 const oneHour = 3600
 const canEditPost = (user: CurrentUser, post: Post) => {
   if (user.isCollaborator) {
@@ -65,6 +72,8 @@ export default class ShowPostPage extends React.Component<ShowPostPageProps, Sho
     super(props)
 
     this.state = {
+      post: this.props.post,
+      comments: this.props.comments,
       editMode: false,
       newTitle: this.props.post.title,
       newDescription: this.props.post.description,
@@ -72,8 +81,42 @@ export default class ShowPostPage extends React.Component<ShowPostPageProps, Sho
     }
   }
 
+  private unsubscribe: (() => void)[] = []
+
+  public componentDidMount() {
+    this.unsubscribe = [
+      realtime.subscribe<PostEventData>("comment", this.onPostEvent(this.refreshComments)),
+      realtime.subscribe<PostEventData>("vote", this.onPostEvent(this.refreshPost)),
+      realtime.subscribe<PostEventData>("status", this.onPostEvent(this.refreshPost)),
+    ]
+  }
+
+  public componentWillUnmount() {
+    this.unsubscribe.forEach((unsubscribe) => unsubscribe())
+  }
+
+  private onPostEvent = (refresh: () => Promise<void>) => (data: PostEventData) => {
+    if (data.postNumber === this.props.post.number) {
+      refresh()
+    }
+  }
+
+  private refreshPost = async () => {
+    const result = await actions.getPost(this.props.post.number)
+    if (result.ok) {
+      this.setState({ post: result.data })
+    }
+  }
+
+  private refreshComments = async () => {
+    const result = await actions.listComments(this.props.post.number)
+    if (result.ok) {
+      this.setState({ comments: result.data })
+    }
+  }
+
   private saveChanges = async () => {
-    const result = await actions.updatePost(this.props.post.number, this.state.newTitle, this.state.newDescription, this.state.attachments)
+    const result = await actions.updatePost(this.state.post.number, this.state.newTitle, this.state.newDescription, this.state.attachments)
     if (result.ok) {
       location.reload()
     } else {
@@ -83,6 +126,13 @@ export default class ShowPostPage extends React.Component<ShowPostPageProps, Sho
     }
   }
 
+  private approvePost = async () => {
+    const result = await actions.approvePost(this.state.post.number)
+    if (result.ok) {
+      location.reload()
+    }
+  }
+
   private setNewTitle = (newTitle: string) => {
     this.setState({ newTitle })
   }
@@ -112,7 +162,7 @@ export default class ShowPostPage extends React.Component<ShowPostPageProps, Sho
             <div className="p-show-post__header-col">
               <VStack spacing={4}>
                 <HStack>
-                  <VoteCounter post={this.props.post} />
+                  <VoteCounter post={this.state.post} />
 
                   <div className="flex-grow">
                     {this.state.editMode ? (
@@ -120,14 +170,24 @@ export default class ShowPostPage extends React.Component<ShowPostPageProps, Sho
                         <Input field="title" maxLength={100} value={this.state.newTitle} onChange={this.setNewTitle} />
                       </Form>
                     ) : (
-                      <h1 className="text-display2">{this.props.post.title}</h1>
+                      <h1 className="text-display2">{this.state.post.title}</h1>
                     )}
 
                     <span className="text-muted">
                       <Trans id="showpost.label.author">
-                        Posted by <UserName user={this.props.post.user} /> &middot; <Moment locale={Fider.currentLocale} date={this.props.post.createdAt} />
+                        Posted by <UserName user={this.state.post.user} /> &middot; <Moment locale={Fider.currentLocale} date={this.state.post.createdAt} />
                       </Trans>
                     </span>
+                    {this.state.post.pendingReview && (
+                      <p className="text-sm bg-yellow-100 p-2 rounded mt-2">
+                        <Trans id="showpost.message.pendingreview">This post is pending review and is not visible to other users yet.</Trans>
+                      </p>
+                    )}
+                    {this.state.post.isHidden && (
+                      <p className="text-sm bg-gray-200 p-2 rounded mt-2">
+                        <Trans id="showpost.message.hidden">This post was hidden by a moderator and is only visible to staff members.</Trans>
+                      </p>
+                    )}
                   </div>
                 </HStack>
                 <VStack>
@@ -141,8 +201,8 @@ export default class ShowPostPage extends React.Component<ShowPostPageProps, Sho
                     </Form>
                   ) : (
                     <>
-                      {this.props.post.description && <Markdown className="description" text={this.props.post.description} style="full" />}
-                      {!this.props.post.description && (
+                      {this.state.post.description && <Markdown className="description" text={this.state.post.description} style="full" />}
+                      {!this.state.post.description && (
                         <em className="text-muted">
                           <Trans id="showpost.message.nodescription">No description provided.</Trans>
                         </em>
@@ -153,14 +213,14 @@ export default class ShowPostPage extends React.Component<ShowPostPageProps, Sho
                     </>
                   )}
                 </VStack>
-                <ShowPostResponse status={this.props.post.status} response={this.props.post.response} />
+                <ShowPostResponse status={this.state.post.status} response={this.state.post.response} />
               </VStack>
             </div>
 
             <VStack spacing={4} className="p-show-post__action-col">
-              <VotesPanel post={this.props.post} votes={this.props.votes} />
+              <VotesPanel post={this.state.post} votes={this.props.votes} />
 
-              {Fider.session.isAuthenticated && canEditPost(Fider.session.user, this.props.post) && (
+              {Fider.session.isAuthenticated && canEditPost(Fider.session.user, this.state.post) && (
                 <VStack>
                   <span key={0} className="text-category">
                     <Trans id="label.actions">Actions</Trans>
@@ -188,20 +248,29 @@ export default class ShowPostPage extends React.Component<ShowPostPageProps, Sho
                           <Trans id="action.edit">Edit</Trans>
                         </span>
                       </Button>
-                      {Fider.session.user.isCollaborator && <ResponseForm post={this.props.post} />}
+                      {Fider.session.user.isCollaborator && this.state.post.pendingReview && (
+                        <Button variant="primary" onClick={this.approvePost} disabled={Fider.isReadOnly}>
+                          <Icon sprite={IconCheck} />
+                          <span>
+                            <Trans id="action.approve">Approve</Trans>
+                          </span>
+                        </Button>
+                      )}
+                      {Fider.session.user.isCollaborator && <ResponseForm post={this.state.post} />}
                     </VStack>
                   )}
                 </VStack>
               )}
 
-              <TagsPanel post={this.props.post} tags={this.props.tags} />
-              <NotificationsPanel post={this.props.post} subscribed={this.props.subscribed} />
-              <ModerationPanel post={this.props.post} />
+              <TagsPanel post={this.state.post} tags={this.props.tags} />
+              <NotificationsPanel post={this.state.post} subscribed={this.props.subscribed} />
+              <ModerationPanel post={this.state.post} />
+              <ReportPanel post={this.state.post} />
               <PoweredByFider slot="show-post" />
             </VStack>
 
             <div className="p-show-post__discussion_col">
-              <DiscussionPanel post={this.props.post} comments={this.props.comments} />
+              <DiscussionPanel post={this.state.post} comments={this.state.comments} />
             </div>
           </VStack>
         </div>
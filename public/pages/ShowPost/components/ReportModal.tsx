@@ -0,0 +1,78 @@
+import React, { useState } from "react"
+import { Post, ReportReason } from "@fider/models"
+import { Form, Modal, Button, Select, SelectOption, TextArea } from "@fider/components"
+import { actions, notify, Failure } from "@fider/services"
+import { t, Trans } from "@lingui/macro"
+
+interface ReportModalProps {
+  post: Post
+  commentID?: number
+  isOpen: boolean
+  onClose: () => void
+}
+
+export const ReportModal = (props: ReportModalProps) => {
+  const [reason, setReason] = useState<ReportReason>("spam")
+  const [details, setDetails] = useState("")
+  const [error, setError] = useState<Failure>()
+
+  const options: SelectOption[] = [
+    { value: "spam", label: t({ id: "report.reason.spam", message: "Spam or advertising" }) },
+    { value: "abusive", label: t({ id: "report.reason.abusive", message: "Abusive or harassing" }) },
+    { value: "off-topic", label: t({ id: "report.reason.offtopic", message: "Off-topic" }) },
+    { value: "other", label: t({ id: "report.reason.other", message: "Something else" }) },
+  ]
+
+  const changeReason = (opt?: SelectOption) => {
+    if (opt) {
+      setReason(opt.value as ReportReason)
+    }
+  }
+
+  const close = () => {
+    setDetails("")
+    setError(undefined)
+    props.onClose()
+  }
+
+  const submit = async () => {
+    const response = props.commentID
+      ? await actions.reportComment(props.post.number, props.commentID, reason, details)
+      : await actions.reportPost(props.post.number, reason, details)
+
+    if (response.ok) {
+      close()
+      notify.success(<Trans id="report.message.success">Thanks, the moderators will take a look at it.</Trans>)
+    } else if (response.error) {
+      setError(response.error)
+    }
+  }
+
+  return (
+    <Modal.Window isOpen={props.isOpen} onClose={close} center={false} size="small">
+      <Modal.Header>
+        {props.commentID ? <Trans id="report.comment.header">Report Comment</Trans> : <Trans id="report.post.header">Report Post</Trans>}
+      </Modal.Header>
+      <Modal.Content>
+        <Form error={error}>
+          <Select field="reason" label={t({ id: "report.reason.label", message: "Reason" })} defaultValue={reason} options={options} onChange={changeReason} />
+          <TextArea
+            field="details"
+            onChange={setDetails}
+            value={details}
+            placeholder={t({ id: "report.details.placeholder", message: "Anything else the moderators should know? (optional)" })}
+          />
+        </Form>
+      </Modal.Content>
+
+      <Modal.Footer>
+        <Button variant="danger" onClick={submit}>
+          <Trans id="action.report">Report</Trans>
+        </Button>
+        <Button variant="tertiary" onClick={close}>
+          <Trans id="action.cancel">Cancel</Trans>
+        </Button>
+      </Modal.Footer>
+    </Modal.Window>
+  )
+}
@@ -0,0 +1,28 @@
+import React, { useState } from "react"
+import { Post } from "@fider/models"
+import { Button } from "@fider/components"
+import { useFider } from "@fider/hooks"
+import { ReportModal } from "./ReportModal"
+import { Trans } from "@lingui/macro"
+
+interface ReportPanelProps {
+  post: Post
+}
+
+export const ReportPanel = (props: ReportPanelProps) => {
+  const fider = useFider()
+  const [isOpen, setIsOpen] = useState(false)
+
+  if (!fider.session.isAuthenticated || fider.session.user.id === props.post.user.id) {
+    return null
+  }
+
+  return (
+    <>
+      <ReportModal post={props.post} isOpen={isOpen} onClose={() => setIsOpen(false)} />
+      <Button variant="tertiary" size="small" className="w-full" onClick={() => setIsOpen(true)} disabled={fider.isReadOnly}>
+        <Trans id="action.report">Report</Trans>
+      </Button>
+    </>
+  )
+}
@@ -5,6 +5,7 @@ import { HStack } from "@fider/components/layout"
 import { formatDate, Failure, actions } from "@fider/services"
 import { useFider } from "@fider/hooks"
 import IconDotsHorizontal from "@fider/assets/images/heroicons-dots-horizontal.svg"
+import { ReportModal } from "./ReportModal"
 import { Trans } from "@lingui/macro"
 
 interface ShowCommentProps {
@@ -17,6 +18,7 @@ export const ShowComment = (props: ShowCommentProps) => {
   const [isEditing, setIsEditing] = useState(false)
   const [newContent, setNewContent] = useState("")
   const [isDeleteConfirmationModalOpen, setIsDeleteConfirmationModalOpen] = useState(false)
+  const [isReportModalOpen, setIsReportModalOpen] = useState(false)
   const [attachments, setAttachments] = useState<ImageUpload[]>([])
   const [error, setError] = useState<Failure>()
 
@@ -27,6 +29,10 @@ export const ShowComment = (props: ShowCommentProps) => {
     return false
   }
 
+  const canReportComment = (): boolean => {
+    return fider.session.isAuthenticated && props.comment.user.id !== fider.session.user.id
+  }
+
   const clearError = () => setError(undefined)
 
   const cancelEdit = async () => {
@@ -55,13 +61,24 @@ export const ShowComment = (props: ShowCommentProps) => {
     }
   }
 
+  const approveComment = async () => {
+    const response = await actions.approveComment(props.post.number, props.comment.id)
+    if (response.ok) {
+      location.reload()
+    }
+  }
+
   const onActionSelected = (action: string) => () => {
-    if (action === "edit") {
+    if (action === "approve") {
+      approveComment()
+    } else if (action === "edit") {
       setIsEditing(true)
       setNewContent(props.comment.content)
       clearError()
     } else if (action === "delete") {
       setIsDeleteConfirmationModalOpen(true)
+    } else if (action === "report") {
+      setIsReportModalOpen(true)
     }
   }
 
@@ -100,6 +117,7 @@ export const ShowComment = (props: ShowCommentProps) => {
   return (
     <HStack spacing={2} center={false} className="c-comment flex-items-baseline">
       {modal()}
+      <ReportModal post={props.post} commentID={comment.id} isOpen={isReportModalOpen} onClose={() => setIsReportModalOpen(false)} />
       <div className="pt-4">
         <Avatar user={comment.user} />
       </div>
@@ -111,15 +129,39 @@ export const ShowComment = (props: ShowCommentProps) => {
               <div className="text-xs">
                 · <Moment locale={fider.currentLocale} date={comment.createdAt} /> {editedMetadata}
               </div>
+              {comment.pendingReview && (
+                <span className="text-xs rounded-md bg-yellow-100 px-1">
+                  <Trans id="showpost.comment.pendingreview">Pending review</Trans>
+                </span>
+              )}
+              {comment.isHidden && (
+                <span className="text-xs rounded-md bg-gray-200 px-1">
+                  <Trans id="showpost.comment.hidden">Hidden</Trans>
+                </span>
+              )}
             </HStack>
-            {!isEditing && canEditComment() && (
+            {!isEditing && (canEditComment() || canReportComment()) && (
               <Dropdown position="left" renderHandle={<Icon sprite={IconDotsHorizontal} width="16" height="16" />}>
-                <Dropdown.ListItem onClick={onActionSelected("edit")}>
-                  <Trans id="action.edit">Edit</Trans>
-                </Dropdown.ListItem>
-                <Dropdown.ListItem onClick={onActionSelected("delete")} className="text-red-700">
-                  <Trans id="action.delete">Delete</Trans>
-                </Dropdown.ListItem>
+                {comment.pendingReview && fider.session.user.isCollaborator && (
+                  <Dropdown.ListItem onClick={onActionSelected("approve")}>
+                    <Trans id="action.approve">Approve</Trans>
+                  </Dropdown.ListItem>
+                )}
+                {canEditComment() && (
+                  <>
+                    <Dropdown.ListItem onClick={onActionSelected("edit")}>
+                      <Trans id="action.edit">Edit</Trans>
+                    </Dropdown.ListItem>
+                    <Dropdown.ListItem onClick={onActionSelected("delete")} className="text-red-700">
+                      <Trans id="action.delete">Delete</Trans>
+                    </Dropdown.ListItem>
+                  </>
+                )}
+                {canReportComment() && (
+                  <Dropdown.ListItem onClick={onActionSelected("report")}>
+                    <Trans id="action.report">Report</Trans>
+                  </Dropdown.ListItem>
+                )}
               </Dropdown>
             )}
           </HStack>
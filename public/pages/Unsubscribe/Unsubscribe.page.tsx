@@ -0,0 +1,62 @@
+import React, { useState } from "react"
+import { Button, TenantLogo } from "@fider/components"
+import { actions } from "@fider/services"
+import { Trans } from "@lingui/macro"
+
+interface UnsubscribePageProps {
+  token: string
+  post?: {
+    number: number
+    title: string
+    slug: string
+  }
+  event?: string
+}
+
+const UnsubscribePage = (props: UnsubscribePageProps) => {
+  const [done, setDone] = useState(false)
+
+  const unsubscribe = async () => {
+    const result = await actions.unsubscribe(props.token)
+    if (result.ok) {
+      setDone(true)
+    }
+  }
+
+  return (
+    <div id="p-unsubscribe" className="container page">
+      <div className="w-max-7xl mx-auto text-center mt-8">
+        <div className="h-20 mb-4">
+          <TenantLogo size={100} useFiderIfEmpty={true} />
+        </div>
+        <h1 className="text-display">
+          <Trans id="page.unsubscribe.title">Unsubscribe</Trans>
+        </h1>
+        {done ? (
+          <p>
+            <Trans id="page.unsubscribe.success">You will no longer receive these emails.</Trans>
+          </p>
+        ) : (
+          <>
+            {props.post ? (
+              <p>
+                <Trans id="page.unsubscribe.post">
+                  Stop receiving emails about <strong>{props.post.title}</strong>?
+                </Trans>
+              </p>
+            ) : (
+              <p>
+                <Trans id="page.unsubscribe.newpost">Stop receiving emails about new posts?</Trans>
+              </p>
+            )}
+            <Button variant="primary" onClick={unsubscribe}>
+              <Trans id="label.unsubscribe">Unsubscribe</Trans>
+            </Button>
+          </>
+        )}
+      </div>
+    </div>
+  )
+}
+
+export default UnsubscribePage
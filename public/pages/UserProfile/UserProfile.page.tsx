@@ -0,0 +1,134 @@
+import React, { useState } from "react"
+
+import { Post, UserActivity, UserProfile } from "@fider/models"
+import { Avatar, Button, Header, Markdown, Moment, UserName } from "@fider/components"
+import { actions, Fider } from "@fider/services"
+import { useFider } from "@fider/hooks"
+import { HStack, VStack } from "@fider/components/layout"
+import { t, Trans } from "@lingui/macro"
+
+interface UserProfilePageProps {
+  profile: UserProfile
+  activity: UserActivity
+}
+
+const ListActivityPosts = (props: { posts: Post[]; emptyText: string }) => {
+  if (props.posts.length === 0) {
+    return <p className="text-muted">{props.emptyText}</p>
+  }
+
+  return (
+    <VStack spacing={2}>
+      {props.posts.map((post) => (
+        <div key={post.id}>
+          <a className="text-link" href={`/posts/${post.number}/${post.slug}`}>
+            {post.title}
+          </a>
+          <span className="text-muted text-sm">
+            {" "}
+            · <Moment locale={Fider.currentLocale} date={post.createdAt} />
+          </span>
+        </div>
+      ))}
+    </VStack>
+  )
+}
+
+const UserProfilePage = (props: UserProfilePageProps) => {
+  const fider = useFider()
+  const [isHidden, setIsHidden] = useState(props.profile.isHidden)
+  const user = props.profile.user
+  const canManage = fider.session.isAuthenticated && fider.session.user.isAdministrator && fider.session.user.id !== user.id
+
+  const toggleVisibility = async () => {
+    const action = isHidden ? actions.showUserProfile : actions.hideUserProfile
+    const result = await action(user.id)
+    if (result.ok) {
+      setIsHidden(!isHidden)
+    }
+  }
+
+  return (
+    <>
+      <Header />
+      <div id="p-user-profile" className="page container">
+        <HStack spacing={4} center={false}>
+          <Avatar user={user} />
+          <VStack spacing={2} className="w-full">
+            <HStack justify="between">
+              <UserName user={user} />
+              {canManage && (
+                <Button variant="tertiary" size="small" onClick={toggleVisibility}>
+                  {isHidden ? "Show Profile" : "Hide Profile"}
+                </Button>
+              )}
+            </HStack>
+            {isHidden && (
+              <span className="text-muted text-sm">
+                <Trans id="userprofile.hidden">This profile is hidden from other users.</Trans>
+              </span>
+            )}
+            {props.profile.bio && <p className="text-gray-700">{props.profile.bio}</p>}
+            {props.profile.link && (
+              <a className="text-link" href={props.profile.link} rel="nofollow noopener noreferrer" target="_blank">
+                {props.profile.link}
+              </a>
+            )}
+          </VStack>
+        </HStack>
+
+        <VStack spacing={8} className="mt-8">
+          <VStack spacing={2}>
+            <h2 className="text-display">
+              <Trans id="userprofile.posts.title">Posts</Trans>
+            </h2>
+            <ListActivityPosts
+              posts={props.activity.posts}
+              emptyText={t({ id: "userprofile.posts.empty", message: "No posts yet." })}
+            />
+          </VStack>
+
+          <VStack spacing={2}>
+            <h2 className="text-display">
+              <Trans id="userprofile.comments.title">Comments</Trans>
+            </h2>
+            {props.activity.comments.length === 0 ? (
+              <p className="text-muted">
+                <Trans id="userprofile.comments.empty">No comments yet.</Trans>
+              </p>
+            ) : (
+              <VStack spacing={4} divide={true}>
+                {props.activity.comments.map((comment) => (
+                  <VStack key={comment.id} spacing={1}>
+                    <span>
+                      <a className="text-link" href={`/posts/${comment.postNumber}/${comment.postSlug}`}>
+                        {comment.postTitle}
+                      </a>
+                      <span className="text-muted text-sm">
+                        {" "}
+                        · <Moment locale={Fider.currentLocale} date={comment.createdAt} />
+                      </span>
+                    </span>
+                    <Markdown className="text-gray-600" maxLength={300} text={comment.content} style="plainText" />
+                  </VStack>
+                ))}
+              </VStack>
+            )}
+          </VStack>
+
+          <VStack spacing={2}>
+            <h2 className="text-display">
+              <Trans id="userprofile.votes.title">Votes</Trans>
+            </h2>
+            <ListActivityPosts
+              posts={props.activity.votes}
+              emptyText={t({ id: "userprofile.votes.empty", message: "No votes yet." })}
+            />
+          </VStack>
+        </VStack>
+      </div>
+    </>
+  )
+}
+
+export default UserProfilePage
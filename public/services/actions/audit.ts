@@ -0,0 +1,20 @@
+import { http, Result, querystring } from "@fider/services"
+import { AuditLog } from "@fider/models"
+
+export interface SearchAuditLogsParams {
+  action?: string
+  targetType?: string
+  targetId?: string
+  offset?: number
+}
+
+export const searchAuditLogs = async (params: SearchAuditLogsParams): Promise<Result<AuditLog[]>> => {
+  return await http.get<AuditLog[]>(
+    `/api/v1/audit-logs${querystring.stringify({
+      action: params.action,
+      targetType: params.targetType,
+      targetId: params.targetId,
+      offset: params.offset,
+    })}`
+  )
+}
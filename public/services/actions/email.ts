@@ -0,0 +1,30 @@
+import { http, Result, querystring } from "@fider/services"
+import { EmailTemplatePreview, OutboxEmail } from "@fider/models"
+
+export const saveEmailTemplate = async (kind: string, subject: string, body: string): Promise<Result> => {
+  return await http.put(`/_api/admin/email-templates/${kind}`, { subject, body })
+}
+
+export const resetEmailTemplate = async (kind: string): Promise<Result> => {
+  return await http.delete(`/_api/admin/email-templates/${kind}`)
+}
+
+export const previewEmailTemplate = async (kind: string, subject: string, body: string): Promise<Result<EmailTemplatePreview>> => {
+  return await http.post(`/_api/admin/email-templates/${kind}/preview`, { subject, body })
+}
+
+export const sendTestEmailTemplate = async (kind: string, subject: string, body: string): Promise<Result> => {
+  return await http.post(`/_api/admin/email-templates/${kind}/test`, { subject, body })
+}
+
+export const searchEmailLog = async (status?: string, offset?: number): Promise<Result<OutboxEmail[]>> => {
+  return await http.get<OutboxEmail[]>(`/_api/admin/emails${querystring.stringify({ status, offset })}`)
+}
+
+export const resendEmail = async (id: number): Promise<Result> => {
+  return await http.post(`/_api/admin/emails/${id}/resend`)
+}
+
+export const clearEmailSuppression = async (userId: number): Promise<Result> => {
+  return await http.delete(`/_api/admin/email-suppressions/${userId}`)
+}
@@ -7,3 +7,6 @@ export * from "./invite"
 export * from "./infra"
 export * from "./webhook"
 export * from "./billing"
+export * from "./audit"
+export * from "./report"
+export * from "./email"
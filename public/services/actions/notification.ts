@@ -13,3 +13,7 @@ export const getTotalUnreadNotifications = async (): Promise<Result<number>> =>
 export const markAllAsRead = async (): Promise<Result> => {
   return await http.post("/_api/notifications/read-all")
 }
+
+export const unsubscribe = async (token: string): Promise<Result> => {
+  return await http.post(`/unsubscribe/${token}`)
+}
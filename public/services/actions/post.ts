@@ -1,5 +1,5 @@
 import { http, Result, querystring } from "@fider/services"
-import { Post, Vote, ImageUpload } from "@fider/models"
+import { Post, Vote, Comment, ImageUpload } from "@fider/models"
 
 export const getAllPosts = async (): Promise<Result<Post[]>> => {
   return await http.get<Post[]>("/api/v1/posts")
@@ -23,6 +23,10 @@ export const searchPosts = async (params: SearchPostsParams): Promise<Result<Pos
   )
 }
 
+export const getPost = async (postNumber: number): Promise<Result<Post>> => {
+  return await http.get<Post>(`/api/v1/posts/${postNumber}`)
+}
+
 export const deletePost = async (postNumber: number, text: string): Promise<Result> => {
   return http
     .delete(`/api/v1/posts/${postNumber}`, {
@@ -31,6 +35,10 @@ export const deletePost = async (postNumber: number, text: string): Promise<Resu
     .then(http.event("post", "delete"))
 }
 
+export const approvePost = async (postNumber: number): Promise<Result> => {
+  return http.put(`/api/v1/posts/${postNumber}/approve`).then(http.event("post", "approve"))
+}
+
 export const addVote = async (postNumber: number): Promise<Result> => {
   return http.post(`/api/v1/posts/${postNumber}/votes`).then(http.event("post", "vote"))
 }
@@ -51,6 +59,10 @@ export const listVotes = async (postNumber: number): Promise<Result<Vote[]>> =>
   return http.get<Vote[]>(`/api/v1/posts/${postNumber}/votes`)
 }
 
+export const listComments = async (postNumber: number): Promise<Result<Comment[]>> => {
+  return http.get<Comment[]>(`/api/v1/posts/${postNumber}/comments`)
+}
+
 export const createComment = async (postNumber: number, content: string, attachments: ImageUpload[]): Promise<Result> => {
   return http.post(`/api/v1/posts/${postNumber}/comments`, { content, attachments }).then(http.event("comment", "create"))
 }
@@ -63,6 +75,10 @@ export const deleteComment = async (postNumber: number, commentID: number): Prom
   return http.delete(`/api/v1/posts/${postNumber}/comments/${commentID}`).then(http.event("comment", "delete"))
 }
 
+export const approveComment = async (postNumber: number, commentID: number): Promise<Result> => {
+  return http.put(`/api/v1/posts/${postNumber}/comments/${commentID}/approve`).then(http.event("comment", "approve"))
+}
+
 interface SetResponseInput {
   status: string
   text: string
@@ -0,0 +1,18 @@
+import { http, Result } from "@fider/services"
+import { ReportedContent, ReportReason, ReportResolution } from "@fider/models"
+
+export const reportPost = async (postNumber: number, reason: ReportReason, details: string): Promise<Result> => {
+  return http.post(`/api/v1/posts/${postNumber}/report`, { reason, details }).then(http.event("post", "report"))
+}
+
+export const reportComment = async (postNumber: number, commentID: number, reason: ReportReason, details: string): Promise<Result> => {
+  return http.post(`/api/v1/posts/${postNumber}/comments/${commentID}/report`, { reason, details }).then(http.event("comment", "report"))
+}
+
+export const getContentReports = async (): Promise<Result<ReportedContent[]>> => {
+  return http.get<ReportedContent[]>("/api/v1/reports")
+}
+
+export const resolveContentReports = async (postNumber: number, commentID: number | undefined, resolution: ReportResolution): Promise<Result> => {
+  return http.post("/api/v1/reports/resolve", { postNumber, commentId: commentID, resolution }).then(http.event("reports", "resolve"))
+}
@@ -1,5 +1,5 @@
 import { http, Result } from "@fider/services/http"
-import { UserRole, OAuthConfig, ImageUpload, EmailVerificationKind } from "@fider/models"
+import { UserRole, OAuthConfig, ImageUpload, EmailVerificationKind, PasskeyMode, UserImport, UserImportPreview } from "@fider/models"
 
 export interface CheckAvailabilityResponse {
   message: string
@@ -45,12 +45,33 @@ export const updateTenantPrivacy = async (isPrivate: boolean): Promise<Result> =
   })
 }
 
+export const updateTenantEmailDomains = async (allowedEmailDomains: string[], autoJoinRole: UserRole): Promise<Result> => {
+  return await http.post("/_api/admin/settings/emaildomains", {
+    allowedEmailDomains,
+    autoJoinRole,
+  })
+}
+
 export const updateTenantEmailAuthAllowed = async (isEmailAuthAllowed: boolean): Promise<Result> => {
   return await http.post("/_api/admin/settings/emailauth", {
     isEmailAuthAllowed,
   })
 }
 
+export const updateTenantPasskeyMode = async (passkeyMode: PasskeyMode): Promise<Result> => {
+  return await http.post("/_api/admin/settings/passkey", {
+    passkeyMode,
+  })
+}
+
+export const regenerateSSOSecret = async (): Promise<Result<{ secret: string }>> => {
+  return await http.post<{ secret: string }>("/_api/admin/settings/sso")
+}
+
+export const disableSSO = async (): Promise<Result> => {
+  return await http.delete("/_api/admin/settings/sso")
+}
+
 export const checkAvailability = async (subdomain: string): Promise<Result<CheckAvailabilityResponse>> => {
   return await http.get<CheckAvailabilityResponse>(`/_api/tenants/${subdomain}/availability`)
 }
@@ -61,6 +82,13 @@ export const signIn = async (email: string): Promise<Result> => {
   })
 }
 
+export const signInByLDAP = async (username: string, password: string): Promise<Result> => {
+  return await http.post("/_api/signin/ldap", {
+    username,
+    password,
+  })
+}
+
 export const completeProfile = async (kind: EmailVerificationKind, key: string, name: string): Promise<Result> => {
   return await http.post("/_api/signin/complete", {
     kind,
@@ -83,6 +111,53 @@ export const unblockUser = async (userID: number): Promise<Result> => {
   return await http.delete(`/_api/admin/users/${userID}/block`)
 }
 
+export const suspendUser = async (userID: number, reason: string, days: number, readOnly: boolean): Promise<Result> => {
+  return await http.post(`/_api/admin/users/${userID}/suspension`, { reason, days, readOnly })
+}
+
+export const liftUserSuspension = async (userID: number): Promise<Result> => {
+  return await http.delete(`/_api/admin/users/${userID}/suspension`)
+}
+
+export const mergeUsers = async (sourceUserID: number, targetUserID: number): Promise<Result<{ id: number }>> => {
+  return await http.post<{ id: number }>(`/api/v1/users/${sourceUserID}/merge`, { targetUserId: targetUserID })
+}
+
+export interface ImportUsersRequest {
+  csv: string
+  sendInvites: boolean
+  subject: string
+  message: string
+}
+
+export const previewUserImport = async (request: ImportUsersRequest): Promise<Result<UserImportPreview>> => {
+  return await http.post<UserImportPreview>("/api/v1/user-imports", { ...request, dryRun: true })
+}
+
+export const importUsers = async (request: ImportUsersRequest): Promise<Result<UserImport>> => {
+  return await http.post<UserImport>("/api/v1/user-imports", request)
+}
+
+export const getUserImport = async (importID: number): Promise<Result<UserImport>> => {
+  return await http.get<UserImport>(`/api/v1/user-imports/${importID}`)
+}
+
+export const eraseUser = async (userID: number, deleteContent: boolean): Promise<Result> => {
+  return await http.post(`/_api/admin/users/${userID}/erase`, { deleteContent })
+}
+
+export const hideUserProfile = async (userID: number): Promise<Result> => {
+  return await http.put(`/_api/admin/users/${userID}/profile/hidden`)
+}
+
+export const showUserProfile = async (userID: number): Promise<Result> => {
+  return await http.delete(`/_api/admin/users/${userID}/profile/hidden`)
+}
+
+export const impersonateUser = async (userID: number): Promise<Result> => {
+  return await http.post(`/_api/admin/users/${userID}/impersonate`)
+}
+
 export const getOAuthConfig = async (provider: string): Promise<Result<OAuthConfig>> => {
   return await http.get<OAuthConfig>(`/_api/admin/oauth/${provider}`)
 }
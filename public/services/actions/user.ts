@@ -1,11 +1,14 @@
 import { http, Result } from "@fider/services/http"
-import { UserSettings, UserAvatarType, ImageUpload } from "@fider/models"
+import { UserSettings, UserAvatarType, ImageUpload, Passkey, SavedSearch } from "@fider/models"
 
 interface UpdateUserSettings {
   name: string
   avatar?: ImageUpload
   avatarType: UserAvatarType
   settings: UserSettings
+  bio: string
+  link: string
+  locale: string
 }
 
 export const updateUserSettings = async (request: UpdateUserSettings): Promise<Result> => {
@@ -22,6 +25,69 @@ export const deleteCurrentAccount = async (): Promise<Result> => {
   return await http.delete("/_api/user")
 }
 
+export const stopImpersonation = async (): Promise<Result> => {
+  return await http.post("/_api/impersonation/stop")
+}
+
 export const regenerateAPIKey = async (): Promise<Result<{ apiKey: string }>> => {
   return await http.post<{ apiKey: string }>("/_api/user/regenerate-apikey")
 }
+
+interface PasskeyCeremony {
+  options: any
+  token: string
+}
+
+export const getPasskeyRegistrationOptions = async (): Promise<Result<PasskeyCeremony>> => {
+  return await http.post<PasskeyCeremony>("/_api/user/passkeys/options")
+}
+
+export const registerPasskey = async (name: string, token: string, credential: any): Promise<Result<Passkey>> => {
+  return await http.post<Passkey>("/_api/user/passkeys", {
+    name,
+    token,
+    credential,
+  })
+}
+
+export const deletePasskey = async (id: number): Promise<Result> => {
+  return await http.delete(`/_api/user/passkeys/${id}`)
+}
+
+export const addPushSubscription = async (subscription: PushSubscriptionJSON): Promise<Result> => {
+  return await http.post("/_api/user/push-subscriptions", subscription)
+}
+
+export const deletePushSubscription = async (endpoint: string): Promise<Result> => {
+  return await http.delete("/_api/user/push-subscriptions", { endpoint })
+}
+
+interface AddSavedSearch {
+  name: string
+  view: string
+  tags: string[]
+  channels: number
+}
+
+export const addSavedSearch = async (request: AddSavedSearch): Promise<Result<SavedSearch>> => {
+  return await http.post<SavedSearch>("/_api/user/saved-searches", request)
+}
+
+export const updateSavedSearch = async (id: number, channels: number): Promise<Result> => {
+  return await http.put(`/_api/user/saved-searches/${id}`, { channels })
+}
+
+export const deleteSavedSearch = async (id: number): Promise<Result> => {
+  return await http.delete(`/_api/user/saved-searches/${id}`)
+}
+
+export const getPasskeySignInOptions = async (): Promise<Result<PasskeyCeremony>> => {
+  return await http.post<PasskeyCeremony>("/_api/signin/passkey/options")
+}
+
+export const signInByPasskey = async (token: string, credential: any): Promise<Result> => {
+  return await http.post("/_api/signin/passkey", {
+    token,
+    credential,
+  })
+}
@@ -6,6 +6,7 @@ export class FiderSession {
   private pContextID: string
   private pTenant: Tenant
   private pUser: CurrentUser | undefined
+  private pImpersonator: { id: number; name: string } | undefined
   private pProps: { [key: string]: any } = {}
 
   constructor(data: any) {
@@ -13,6 +14,7 @@ export class FiderSession {
     this.pContextID = data.contextID
     this.pProps = data.props
     this.pUser = data.user
+    this.pImpersonator = data.impersonator
     this.pTenant = data.tenant
   }
 
@@ -40,6 +42,10 @@ export class FiderSession {
   public get isAuthenticated(): boolean {
     return !!this.pUser
   }
+
+  public get impersonator(): { id: number; name: string } | undefined {
+    return this.pImpersonator
+  }
 }
 
 export class FiderImpl {
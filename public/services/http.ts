@@ -29,8 +29,12 @@ async function toResult<T>(response: Response): Promise<Result<T>> {
     notify.error("An unexpected error occurred while processing your request.")
   } else if (response.status === 401) {
     notify.error("You need to be authenticated to perform this operation.")
+  } else if (response.status === 403 && body.errors && body.errors.length > 0) {
+    notify.error(body.errors[0].message)
   } else if (response.status === 403) {
     notify.error("You are not authorized to perform this operation.")
+  } else if (response.status === 429) {
+    notify.error("You are doing this too often. Please try again later.")
   }
 
   return {
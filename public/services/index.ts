@@ -9,6 +9,9 @@ import * as markdown from "./markdown"
 import * as notify from "./notify"
 import * as querystring from "./querystring"
 import * as device from "./device"
+import * as webauthn from "./webauthn"
+import * as realtime from "./realtime"
+import * as push from "./push"
 import * as actions from "./actions"
 import navigator from "./navigator"
-export { actions, querystring, navigator, device, notify, markdown }
+export { actions, querystring, navigator, device, notify, markdown, webauthn, realtime, push }
@@ -0,0 +1,70 @@
+import { Fider } from "./fider"
+import { addPushSubscription, deletePushSubscription } from "./actions"
+
+const fromBase64URL = (value: string): Uint8Array => {
+  const base64 = value.replace(/-/g, "+").replace(/_/g, "/")
+  const padded = base64 + "===".slice((base64.length + 3) % 4)
+  const binary = window.atob(padded)
+  const bytes = new Uint8Array(binary.length)
+  for (let i = 0; i < binary.length; i++) {
+    bytes[i] = binary.charCodeAt(i)
+  }
+  return bytes
+}
+
+const register = (): Promise<ServiceWorkerRegistration> => {
+  return navigator.serviceWorker.register("/service-worker.js")
+}
+
+export const isSupported = (): boolean => {
+  return (
+    typeof window !== "undefined" &&
+    !!Fider.settings.vapidPublicKey &&
+    "serviceWorker" in navigator &&
+    "PushManager" in window &&
+    "Notification" in window
+  )
+}
+
+// isSubscribed returns true if current browser is already receiving push notifications
+export const isSubscribed = async (): Promise<boolean> => {
+  if (!isSupported()) {
+    return false
+  }
+  const registration = await navigator.serviceWorker.getRegistration("/")
+  const subscription = registration ? await registration.pushManager.getSubscription() : null
+  return !!subscription && Notification.permission === "granted"
+}
+
+// subscribe asks for permission and registers current browser to receive push notifications
+// Returns false when the user has denied the permission
+export const subscribe = async (): Promise<boolean> => {
+  if (!isSupported() || (await Notification.requestPermission()) !== "granted") {
+    return false
+  }
+
+  const registration = await register()
+  await navigator.serviceWorker.ready
+  const subscription =
+    (await registration.pushManager.getSubscription()) ||
+    (await registration.pushManager.subscribe({
+      userVisibleOnly: true,
+      applicationServerKey: fromBase64URL(Fider.settings.vapidPublicKey),
+    }))
+
+  const result = await addPushSubscription(subscription.toJSON())
+  return result.ok
+}
+
+// unsubscribe stops sending push notifications to current browser
+export const unsubscribe = async (): Promise<void> => {
+  if (!isSupported()) {
+    return
+  }
+  const registration = await navigator.serviceWorker.getRegistration("/")
+  const subscription = registration ? await registration.pushManager.getSubscription() : null
+  if (subscription) {
+    await deletePushSubscription(subscription.endpoint)
+    await subscription.unsubscribe()
+  }
+}
@@ -0,0 +1,25 @@
+import { Fider } from "./fider"
+
+export type RealtimeEventType = "notification" | "comment" | "vote" | "status"
+
+let source: EventSource | undefined
+
+const connect = (): EventSource | undefined => {
+  if (!source && Fider.session.isAuthenticated && typeof EventSource !== "undefined") {
+    source = new EventSource("/_api/events")
+  }
+  return source
+}
+
+// subscribe listens to real-time events of given type and returns a function to stop listening
+// Events are only streamed to authenticated users on browsers that support Server-Sent Events
+export const subscribe = <T = any>(type: RealtimeEventType, handler: (data: T) => void): (() => void) => {
+  const es = connect()
+  if (!es) {
+    return () => undefined
+  }
+
+  const listener = (e: MessageEvent) => handler(JSON.parse(e.data))
+  es.addEventListener(type, listener)
+  return () => es.removeEventListener(type, listener)
+}
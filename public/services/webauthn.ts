@@ -0,0 +1,79 @@
+const toBase64URL = (buffer: ArrayBuffer): string => {
+  const bytes = new Uint8Array(buffer)
+  let binary = ""
+  for (let i = 0; i < bytes.byteLength; i++) {
+    binary += String.fromCharCode(bytes[i])
+  }
+  return window.btoa(binary).replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "")
+}
+
+const fromBase64URL = (value: string): ArrayBuffer => {
+  const base64 = value.replace(/-/g, "+").replace(/_/g, "/")
+  const padded = base64 + "===".slice((base64.length + 3) % 4)
+  const binary = window.atob(padded)
+  const bytes = new Uint8Array(binary.length)
+  for (let i = 0; i < binary.length; i++) {
+    bytes[i] = binary.charCodeAt(i)
+  }
+  return bytes.buffer
+}
+
+const toDescriptors = (list: any[]): PublicKeyCredentialDescriptor[] => {
+  return (list || []).map((x) => ({ type: x.type, id: fromBase64URL(x.id) }))
+}
+
+export const isSupported = (): boolean => {
+  return typeof window !== "undefined" && !!window.PublicKeyCredential && !!navigator.credentials
+}
+
+export const create = async (options: any): Promise<any> => {
+  const credential = (await navigator.credentials.create({
+    publicKey: {
+      ...options,
+      challenge: fromBase64URL(options.challenge),
+      user: { ...options.user, id: fromBase64URL(options.user.id) },
+      excludeCredentials: toDescriptors(options.excludeCredentials),
+    },
+  })) as PublicKeyCredential | null
+
+  if (!credential) {
+    return undefined
+  }
+
+  const response = credential.response as AuthenticatorAttestationResponse
+  return {
+    id: credential.id,
+    type: credential.type,
+    response: {
+      clientDataJSON: toBase64URL(response.clientDataJSON),
+      attestationObject: toBase64URL(response.attestationObject),
+      transports: response.getTransports ? response.getTransports() : [],
+    },
+  }
+}
+
+export const get = async (options: any): Promise<any> => {
+  const credential = (await navigator.credentials.get({
+    publicKey: {
+      ...options,
+      challenge: fromBase64URL(options.challenge),
+      allowCredentials: toDescriptors(options.allowCredentials),
+    },
+  })) as PublicKeyCredential | null
+
+  if (!credential) {
+    return undefined
+  }
+
+  const response = credential.response as AuthenticatorAssertionResponse
+  return {
+    id: credential.id,
+    type: credential.type,
+    response: {
+      clientDataJSON: toBase64URL(response.clientDataJSON),
+      authenticatorData: toBase64URL(response.authenticatorData),
+      signature: toBase64URL(response.signature),
+      userHandle: response.userHandle ? toBase64URL(response.userHandle) : "",
+    },
+  }
+}
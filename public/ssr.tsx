@@ -2,7 +2,7 @@
 import React from "react"
 import { renderToStaticMarkup } from "react-dom/server"
 import { Fider, FiderContext } from "./services/fider"
-import { DevBanner, ReadOnlyNotice } from "./components"
+import { DevBanner, ReadOnlyNotice, ImpersonationNotice } from "./components"
 
 import { activateI18NSync } from "./services"
 import { I18nProvider } from "@lingui/react"
@@ -32,6 +32,7 @@ const pages: { [key: string]: any } = {
   "SignUp/SignUp.page": require(`./pages/SignUp/SignUp.page`),
   "SignUp/PendingActivation.page": require(`./pages/SignUp/PendingActivation.page`),
   "Legal/Legal.page": require(`./pages/Legal/Legal.page`),
+  "UserProfile/UserProfile.page": require(`./pages/UserProfile/UserProfile.page`),
   "DesignSystem/DesignSystem.page": require(`./pages/DesignSystem/DesignSystem.page`),
   "Error/Maintenance.page": require(`./pages/Error/Maintenance.page`),
   "Error/Error401.page": require(`./pages/Error/Error401.page`),
@@ -57,6 +58,7 @@ function ssrRender(url: string, args: any) {
       <FiderContext.Provider value={fider}>
         <DevBanner />
         <ReadOnlyNotice />
+        <ImpersonationNotice />
         {React.createElement(component, args.props)}
       </FiderContext.Provider>
     </I18nProvider>
@@ -0,0 +1,21 @@
+/* eslint-disable no-undef */
+
+// Displays the Web Push notifications sent by Fider and opens the related page when clicked
+self.addEventListener("push", (event) => {
+  const data = event.data ? event.data.json() : {}
+  event.waitUntil(
+    self.registration.showNotification(data.title || "", {
+      body: data.body,
+      icon: "/static/favicon",
+      data: { url: data.url },
+    })
+  )
+})
+
+self.addEventListener("notificationclick", (event) => {
+  event.notification.close()
+  const url = event.notification.data && event.notification.data.url
+  if (url) {
+    event.waitUntil(clients.openWindow(url))
+  }
+})